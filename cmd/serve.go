@@ -38,6 +38,7 @@ func newServeCmd() *cobra.Command {
 
 	cmd.AddCommand(serve.NewProxyCommand())
 	cmd.AddCommand(serve.NewDecisionCommand())
+	cmd.AddCommand(serve.NewSPOECommand())
 
 	return cmd
 }
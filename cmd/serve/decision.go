@@ -23,10 +23,14 @@ import (
 	"github.com/dadrus/heimdall/internal/config"
 	"github.com/dadrus/heimdall/internal/handler/decision"
 	envoy_extauth "github.com/dadrus/heimdall/internal/handler/envoyextauth/grpcv3"
+	envoy_extauth_http "github.com/dadrus/heimdall/internal/handler/envoyextauth/httpv3"
 	"github.com/dadrus/heimdall/internal/x"
 )
 
-const serveDecisionFlagEnvoyGRPC = "envoy-grpc"
+const (
+	serveDecisionFlagEnvoyGRPC = "envoy-grpc"
+	serveDecisionFlagEnvoyHTTP = "envoy-http"
+)
 
 // NewDecisionCommand represents the "serve decision" command.
 func NewDecisionCommand() *cobra.Command {
@@ -37,11 +41,13 @@ func NewDecisionCommand() *cobra.Command {
 		SilenceUsage: true,
 		RunE: func(cmd *cobra.Command, _ []string) error {
 			useEnvoyExtAuth, _ := cmd.Flags().GetBool(serveDecisionFlagEnvoyGRPC)
+			useEnvoyExtAuthHTTP, _ := cmd.Flags().GetBool(serveDecisionFlagEnvoyHTTP)
 
 			app, err := createApp(
 				cmd,
 				fx.Options(
-					x.IfThenElse(useEnvoyExtAuth, envoy_extauth.Module, decision.Module),
+					x.IfThenElse(useEnvoyExtAuth, envoy_extauth.Module,
+						x.IfThenElse(useEnvoyExtAuthHTTP, envoy_extauth_http.Module, decision.Module)),
 					fx.Supply(config.DecisionMode),
 				),
 			)
@@ -57,6 +63,9 @@ func NewDecisionCommand() *cobra.Command {
 
 	cmd.PersistentFlags().Bool(serveDecisionFlagEnvoyGRPC, false,
 		"If specified, decision mode is started for integration with envoy extauth gRPC service")
+	cmd.PersistentFlags().Bool(serveDecisionFlagEnvoyHTTP, false,
+		"If specified, decision mode is started for integration with envoy extauth HTTP service")
+	cmd.MarkFlagsMutuallyExclusive(serveDecisionFlagEnvoyGRPC, serveDecisionFlagEnvoyHTTP)
 
 	return cmd
 }
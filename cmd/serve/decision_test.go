@@ -55,6 +55,32 @@ func TestRunDecisionModeForEnvoyGRPCRequests(t *testing.T) {
 	time.Sleep(1000 * time.Millisecond)
 }
 
+func TestRunDecisionModeForEnvoyHTTPRequests(t *testing.T) {
+	// this test verifies that all dependencies are resolved
+	// and nothing has been forgotten
+	port1, err := testsupport.GetFreePort()
+	require.NoError(t, err)
+
+	port2, err := testsupport.GetFreePort()
+	require.NoError(t, err)
+
+	t.Setenv("HEIMDALLCFG_SERVE_PORT", strconv.Itoa(port1))
+	t.Setenv("HEIMDALLCFG_MANAGEMENT_PORT", strconv.Itoa(port2))
+
+	cmd := NewDecisionCommand()
+	flags.RegisterGlobalFlags(cmd)
+
+	err = cmd.ParseFlags([]string{"--" + flags.SkipAllSecurityEnforcement, "--" + serveDecisionFlagEnvoyHTTP})
+	require.NoError(t, err)
+
+	go func() {
+		err = cmd.Execute()
+		assert.NoError(t, err)
+	}()
+
+	time.Sleep(1000 * time.Millisecond)
+}
+
 func TestRunDecisionModeForHTTPRequests(t *testing.T) {
 	// this test verifies that all dependencies are resolved
 	// and nothing has been forgotten
@@ -48,7 +48,8 @@ func TestNewServeCmd(t *testing.T) {
 	assert.NotEmpty(t, skipSecureDefaultRuleEnforcementFlag.Usage)
 
 	commands := cmd.Commands()
-	assert.Len(t, commands, 2)
+	assert.Len(t, commands, 3)
 	assert.Contains(t, commands[0].Use, "decision")
 	assert.Contains(t, commands[1].Use, "proxy")
+	assert.Contains(t, commands[2].Use, "spoe")
 }
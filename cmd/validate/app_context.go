@@ -22,6 +22,7 @@ import (
 	"github.com/dadrus/heimdall/internal/config"
 	"github.com/dadrus/heimdall/internal/keyholder"
 	"github.com/dadrus/heimdall/internal/otel/metrics/certificate"
+	"github.com/dadrus/heimdall/internal/session"
 	"github.com/dadrus/heimdall/internal/validation"
 	"github.com/dadrus/heimdall/internal/watcher"
 )
@@ -30,6 +31,7 @@ type appContext struct {
 	w   watcher.Watcher
 	khr keyholder.Registry
 	co  certificate.Observer
+	sm  session.Manager
 	v   validation.Validator
 	l   zerolog.Logger
 	c   *config.Configuration
@@ -38,6 +40,7 @@ type appContext struct {
 func (c *appContext) Watcher() watcher.Watcher                  { return c.w }
 func (c *appContext) KeyHolderRegistry() keyholder.Registry     { return c.khr }
 func (c *appContext) CertificateObserver() certificate.Observer { return c.co }
+func (c *appContext) SessionManager() session.Manager           { return c.sm }
 func (c *appContext) Validator() validation.Validator           { return c.v }
 func (c *appContext) Logger() zerolog.Logger                    { return c.l }
 func (c *appContext) Config() *config.Configuration             { return c.c }
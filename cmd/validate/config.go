@@ -78,6 +78,7 @@ func validateConfig(cmd *cobra.Command, _ []string) error {
 		w:   &watcher.NoopWatcher{},
 		khr: &noopRegistry{},
 		co:  &noopCertificateObserver{},
+		sm:  sessionManager(conf),
 		v:   validator,
 		l:   logger,
 		c:   conf,
@@ -31,8 +31,10 @@ import (
 	"github.com/dadrus/heimdall/internal/otel/metrics/certificate"
 	"github.com/dadrus/heimdall/internal/rules"
 	"github.com/dadrus/heimdall/internal/rules/mechanisms"
+	"github.com/dadrus/heimdall/internal/rules/mechanisms/subject"
 	"github.com/dadrus/heimdall/internal/rules/provider/filesystem"
 	"github.com/dadrus/heimdall/internal/rules/rule"
+	"github.com/dadrus/heimdall/internal/session"
 	"github.com/dadrus/heimdall/internal/validation"
 	"github.com/dadrus/heimdall/internal/watcher"
 )
@@ -97,6 +99,7 @@ func validateRuleSet(cmd *cobra.Command, args []string) error {
 		w:   &watcher.NoopWatcher{},
 		khr: &noopRegistry{},
 		co:  &noopCertificateObserver{},
+		sm:  sessionManager(conf),
 		v:   validator,
 		l:   logger,
 		c:   conf,
@@ -155,3 +158,39 @@ type noopCertificateObserver struct{}
 
 func (*noopCertificateObserver) Add(_ certificate.Supplier) {}
 func (*noopCertificateObserver) Start() error               { return errFunctionNotSupported }
+
+type noopSessionManager struct{}
+
+func (*noopSessionManager) Create(_ context.Context, _ *subject.Subject, _ map[string]string) (*session.Session, error) {
+	return nil, errFunctionNotSupported
+}
+
+func (*noopSessionManager) Load(_ context.Context, _ string) (*session.Session, error) {
+	return nil, errFunctionNotSupported
+}
+
+func (*noopSessionManager) Update(_ context.Context, _ *session.Session) error {
+	return errFunctionNotSupported
+}
+
+func (*noopSessionManager) Rotate(_ context.Context, _ *session.Session) (*session.Session, error) {
+	return nil, errFunctionNotSupported
+}
+
+func (*noopSessionManager) Revoke(_ context.Context, _ string) error {
+	return errFunctionNotSupported
+}
+
+func (*noopSessionManager) RevokeAll(_ context.Context, _ string) error {
+	return errFunctionNotSupported
+}
+
+// sessionManager returns a session manager doing nothing if sessions are configured. That
+// way, the configuration of the mechanisms depending on it can be validated.
+func sessionManager(conf *config.Configuration) session.Manager {
+	if conf.Session == nil {
+		return nil
+	}
+
+	return &noopSessionManager{}
+}
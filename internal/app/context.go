@@ -22,6 +22,7 @@ import (
 	"github.com/dadrus/heimdall/internal/config"
 	"github.com/dadrus/heimdall/internal/keyholder"
 	"github.com/dadrus/heimdall/internal/otel/metrics/certificate"
+	"github.com/dadrus/heimdall/internal/session"
 	"github.com/dadrus/heimdall/internal/validation"
 	"github.com/dadrus/heimdall/internal/watcher"
 )
@@ -32,6 +33,7 @@ type Context interface {
 	Watcher() watcher.Watcher
 	KeyHolderRegistry() keyholder.Registry
 	CertificateObserver() certificate.Observer
+	SessionManager() session.Manager
 	Validator() validation.Validator
 	Logger() zerolog.Logger
 	Config() *config.Configuration
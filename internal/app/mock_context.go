@@ -10,6 +10,8 @@ import (
 
 	mock "github.com/stretchr/testify/mock"
 
+	session "github.com/dadrus/heimdall/internal/session"
+
 	validation "github.com/dadrus/heimdall/internal/validation"
 
 	watcher "github.com/dadrus/heimdall/internal/watcher"
@@ -216,6 +218,53 @@ func (_c *ContextMock_Logger_Call) RunAndReturn(run func() zerolog.Logger) *Cont
 	return _c
 }
 
+// SessionManager provides a mock function with given fields:
+func (_m *ContextMock) SessionManager() session.Manager {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for SessionManager")
+	}
+
+	var r0 session.Manager
+	if rf, ok := ret.Get(0).(func() session.Manager); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(session.Manager)
+		}
+	}
+
+	return r0
+}
+
+// ContextMock_SessionManager_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SessionManager'
+type ContextMock_SessionManager_Call struct {
+	*mock.Call
+}
+
+// SessionManager is a helper method to define mock.On call
+func (_e *ContextMock_Expecter) SessionManager() *ContextMock_SessionManager_Call {
+	return &ContextMock_SessionManager_Call{Call: _e.mock.On("SessionManager")}
+}
+
+func (_c *ContextMock_SessionManager_Call) Run(run func()) *ContextMock_SessionManager_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run()
+	})
+	return _c
+}
+
+func (_c *ContextMock_SessionManager_Call) Return(_a0 session.Manager) *ContextMock_SessionManager_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *ContextMock_SessionManager_Call) RunAndReturn(run func() session.Manager) *ContextMock_SessionManager_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // Validator provides a mock function with given fields:
 func (_m *ContextMock) Validator() validation.Validator {
 	ret := _m.Called()
@@ -29,4 +29,8 @@ type Cache interface {
 
 	Get(ctx context.Context, key string) ([]byte, error)
 	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+	// CompareAndSwap atomically replaces the value stored under the given key with the given
+	// value, if the current value equals the old one. A nil old value requires the key to be
+	// absent. Returns true if the value has been replaced.
+	CompareAndSwap(ctx context.Context, key string, old, value []byte, ttl time.Duration) (bool, error)
 }
@@ -17,8 +17,10 @@
 package memory
 
 import (
+	"bytes"
 	"context"
 	"errors"
+	"sync"
 	"time"
 
 	"github.com/inhies/go-bytesize"
@@ -82,6 +84,9 @@ func NewCache(_ app.Context, conf map[string]any) (cache.Cache, error) {
 
 type Cache struct {
 	c *ttlcache.Cache[string, []byte]
+
+	// casMut serializes the compare and swap operations
+	casMut sync.Mutex
 }
 
 func (c *Cache) Start(_ context.Context) error {
@@ -110,3 +115,22 @@ func (c *Cache) Set(_ context.Context, key string, value []byte, ttl time.Durati
 
 	return nil
 }
+
+func (c *Cache) CompareAndSwap(_ context.Context, key string, old, value []byte, ttl time.Duration) (bool, error) {
+	c.casMut.Lock()
+	defer c.casMut.Unlock()
+
+	item := c.c.Get(key)
+	present := item != nil && !item.IsExpired()
+
+	switch {
+	case old == nil && present:
+		return false, nil
+	case old != nil && (!present || !bytes.Equal(item.Value(), old)):
+		return false, nil
+	}
+
+	c.c.Set(key, value, ttl)
+
+	return true, nil
+}
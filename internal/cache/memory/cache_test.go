@@ -253,3 +253,44 @@ func TestNoDeadlockOnSet(t *testing.T) {
 		t.Fatal("test timed out - deadlock")
 	}
 }
+
+func TestMemoryCacheCompareAndSwap(t *testing.T) {
+	t.Parallel()
+
+	cch, err := NewCache(nil, map[string]any{})
+	require.NoError(t, err)
+
+	// set if absent
+	swapped, err := cch.CompareAndSwap(t.Context(), "foo", nil, []byte("bar"), time.Minute)
+	require.NoError(t, err)
+	assert.True(t, swapped)
+
+	swapped, err = cch.CompareAndSwap(t.Context(), "foo", nil, []byte("baz"), time.Minute)
+	require.NoError(t, err)
+	assert.False(t, swapped)
+
+	// replace the expected value only
+	swapped, err = cch.CompareAndSwap(t.Context(), "foo", []byte("baz"), []byte("zab"), time.Minute)
+	require.NoError(t, err)
+	assert.False(t, swapped)
+
+	swapped, err = cch.CompareAndSwap(t.Context(), "foo", []byte("bar"), []byte("zab"), time.Minute)
+	require.NoError(t, err)
+	assert.True(t, swapped)
+
+	data, err := cch.Get(t.Context(), "foo")
+	require.NoError(t, err)
+	assert.Equal(t, []byte("zab"), data)
+
+	// absent and expired keys are not replaced if a value is expected
+	swapped, err = cch.CompareAndSwap(t.Context(), "bar", []byte("baz"), []byte("zab"), time.Minute)
+	require.NoError(t, err)
+	assert.False(t, swapped)
+
+	require.NoError(t, cch.Set(t.Context(), "baz", []byte("foo"), time.Millisecond))
+	time.Sleep(10 * time.Millisecond)
+
+	swapped, err = cch.CompareAndSwap(t.Context(), "baz", nil, []byte("bar"), time.Minute)
+	require.NoError(t, err)
+	assert.True(t, swapped)
+}
@@ -22,6 +22,62 @@ func (_m *CacheMock) EXPECT() *CacheMock_Expecter {
 	return &CacheMock_Expecter{mock: &_m.Mock}
 }
 
+// CompareAndSwap provides a mock function with given fields: ctx, key, old, value, ttl
+func (_m *CacheMock) CompareAndSwap(ctx context.Context, key string, old []byte, value []byte, ttl time.Duration) (bool, error) {
+	ret := _m.Called(ctx, key, old, value, ttl)
+
+	var r0 bool
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, []byte, []byte, time.Duration) (bool, error)); ok {
+		return rf(ctx, key, old, value, ttl)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, []byte, []byte, time.Duration) bool); ok {
+		r0 = rf(ctx, key, old, value, ttl)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, []byte, []byte, time.Duration) error); ok {
+		r1 = rf(ctx, key, old, value, ttl)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// CacheMock_CompareAndSwap_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'CompareAndSwap'
+type CacheMock_CompareAndSwap_Call struct {
+	*mock.Call
+}
+
+// CompareAndSwap is a helper method to define mock.On call
+//   - ctx context.Context
+//   - key string
+//   - old []byte
+//   - value []byte
+//   - ttl time.Duration
+func (_e *CacheMock_Expecter) CompareAndSwap(ctx interface{}, key interface{}, old interface{}, value interface{}, ttl interface{}) *CacheMock_CompareAndSwap_Call {
+	return &CacheMock_CompareAndSwap_Call{Call: _e.mock.On("CompareAndSwap", ctx, key, old, value, ttl)}
+}
+
+func (_c *CacheMock_CompareAndSwap_Call) Run(run func(ctx context.Context, key string, old []byte, value []byte, ttl time.Duration)) *CacheMock_CompareAndSwap_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].([]byte), args[3].([]byte), args[4].(time.Duration))
+	})
+	return _c
+}
+
+func (_c *CacheMock_CompareAndSwap_Call) Return(_a0 bool, _a1 error) *CacheMock_CompareAndSwap_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *CacheMock_CompareAndSwap_Call) RunAndReturn(run func(context.Context, string, []byte, []byte, time.Duration) (bool, error)) *CacheMock_CompareAndSwap_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // Get provides a mock function with given fields: ctx, key
 func (_m *CacheMock) Get(ctx context.Context, key string) ([]byte, error) {
 	ret := _m.Called(ctx, key)
@@ -22,7 +22,10 @@ import (
 	"time"
 )
 
-var ErrNoCacheEntry = errors.New("no cache entry present")
+var (
+	ErrNoCacheEntry               = errors.New("no cache entry present")
+	ErrCompareAndSwapNotSupported = errors.New("compare and swap not supported")
+)
 
 type Cache struct{}
 
@@ -30,3 +33,7 @@ func (*Cache) Get(_ context.Context, _ string) ([]byte, error)
 func (*Cache) Set(_ context.Context, _ string, _ []byte, _ time.Duration) error { return nil }
 func (*Cache) Start(_ context.Context) error                                    { return nil }
 func (*Cache) Stop(_ context.Context) error                                     { return nil }
+
+func (*Cache) CompareAndSwap(_ context.Context, _ string, _, _ []byte, _ time.Duration) (bool, error) {
+	return false, ErrCompareAndSwapNotSupported
+}
@@ -18,6 +18,7 @@ package redis
 
 import (
 	"context"
+	"strconv"
 	"time"
 
 	"github.com/redis/rueidis"
@@ -28,6 +29,17 @@ import (
 	"github.com/dadrus/heimdall/internal/x/stringx"
 )
 
+// compareAndSwapScript replaces the value of the key given in KEYS[1] with ARGV[2] using the
+// ttl in milliseconds from ARGV[3], if the current value equals ARGV[1]. Returns nil otherwise.
+//
+//nolint:gochecknoglobals
+var compareAndSwapScript = rueidis.NewLuaScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("SET", KEYS[1], ARGV[2], "PX", ARGV[3])
+end
+return false
+`)
+
 type redisCache struct {
 	opts rueidis.ClientOption
 	c    rueidis.Client
@@ -68,3 +80,25 @@ func (c *redisCache) Get(ctx context.Context, key string) ([]byte, error) {
 func (c *redisCache) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
 	return c.c.Do(ctx, c.c.B().Set().Key(key).Value(stringx.ToString(value)).Px(ttl).Build()).Error()
 }
+
+func (c *redisCache) CompareAndSwap(
+	ctx context.Context, key string, old, value []byte, ttl time.Duration,
+) (bool, error) {
+	var err error
+
+	if old == nil {
+		err = c.c.Do(ctx,
+			c.c.B().Set().Key(key).Value(stringx.ToString(value)).Nx().Px(ttl).Build()).Error()
+	} else {
+		err = compareAndSwapScript.Exec(ctx, c.c,
+			[]string{key},
+			[]string{stringx.ToString(old), stringx.ToString(value), strconv.FormatInt(ttl.Milliseconds(), 10)},
+		).Error()
+	}
+
+	if rueidis.IsRedisNil(err) {
+		return false, nil
+	}
+
+	return err == nil, err
+}
@@ -119,3 +119,59 @@ func TestCacheUsage(t *testing.T) {
 		})
 	}
 }
+
+func TestCacheCompareAndSwap(t *testing.T) {
+	t.Parallel()
+
+	validator, err := validation.NewValidator(
+		validation.WithTagValidator(config.EnforcementSettings{}),
+	)
+	require.NoError(t, err)
+
+	appCtx := app.NewContextMock(t)
+	appCtx.EXPECT().Validator().Return(validator)
+
+	db := miniredis.RunT(t)
+	cch, err := NewStandaloneCache(
+		appCtx,
+		map[string]any{
+			"address":      db.Addr(),
+			"client_cache": map[string]any{"disabled": true},
+			"tls":          map[string]any{"disabled": true},
+		},
+	)
+	require.NoError(t, err)
+
+	err = cch.Start(t.Context())
+	require.NoError(t, err)
+
+	defer cch.Stop(t.Context())
+
+	// set if absent
+	swapped, err := cch.CompareAndSwap(t.Context(), "foo", nil, []byte("bar"), time.Minute)
+	require.NoError(t, err)
+	assert.True(t, swapped)
+
+	swapped, err = cch.CompareAndSwap(t.Context(), "foo", nil, []byte("baz"), time.Minute)
+	require.NoError(t, err)
+	assert.False(t, swapped)
+
+	// replace the expected value only
+	swapped, err = cch.CompareAndSwap(t.Context(), "foo", []byte("baz"), []byte("zab"), time.Minute)
+	require.NoError(t, err)
+	assert.False(t, swapped)
+
+	swapped, err = cch.CompareAndSwap(t.Context(), "foo", []byte("bar"), []byte("zab"), time.Minute)
+	require.NoError(t, err)
+	assert.True(t, swapped)
+
+	data, err := cch.Get(t.Context(), "foo")
+	require.NoError(t, err)
+	assert.Equal(t, []byte("zab"), data)
+	assert.Equal(t, time.Minute, db.TTL("foo"))
+
+	// absent keys are not replaced if a value is expected
+	swapped, err = cch.CompareAndSwap(t.Context(), "bar", []byte("baz"), []byte("zab"), time.Minute)
+	require.NoError(t, err)
+	assert.False(t, swapped)
+}
@@ -17,9 +17,10 @@
 package config
 
 type MechanismPrototypes struct {
-	Authenticators  []Mechanism `koanf:"authenticators"`
-	Authorizers     []Mechanism `koanf:"authorizers"`
-	Contextualizers []Mechanism `koanf:"contextualizers"`
-	Finalizers      []Mechanism `koanf:"finalizers"`
-	ErrorHandlers   []Mechanism `koanf:"error_handlers"`
+	Authenticators   []Mechanism `koanf:"authenticators"`
+	Authorizers      []Mechanism `koanf:"authorizers"`
+	Contextualizers  []Mechanism `koanf:"contextualizers"`
+	Finalizers       []Mechanism `koanf:"finalizers"`
+	ErrorHandlers    []Mechanism `koanf:"error_handlers"`
+	ResponseHandlers []Mechanism `koanf:"response_handlers"`
 }
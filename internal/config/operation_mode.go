@@ -21,4 +21,5 @@ type OperationMode int
 const (
 	DecisionMode OperationMode = iota
 	ProxyMode
+	SPOEMode
 )
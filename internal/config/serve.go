@@ -24,19 +24,33 @@ import (
 )
 
 type ServeConfig struct {
-	Host             string           `koanf:"host"`
-	Port             int              `koanf:"port"`
-	Timeout          Timeout          `koanf:"timeout"`
-	BufferLimit      BufferLimit      `koanf:"buffer_limit"`
-	ConnectionsLimit ConnectionsLimit `koanf:"connections_limit"`
-	CORS             *CORS            `koanf:"cors,omitempty"`
-	TLS              *TLS             `koanf:"tls,omitempty"             validate:"enforced=notnil"`
-	TrustedProxies   []string         `koanf:"trusted_proxies,omitempty" validate:"enforced=secure_networks"`
-	Respond          RespondConfig    `koanf:"respond"`
+	Host               string           `koanf:"host"`
+	Port               int              `koanf:"port"`
+	Timeout            Timeout          `koanf:"timeout"`
+	BufferLimit        BufferLimit      `koanf:"buffer_limit"`
+	ConnectionsLimit   ConnectionsLimit `koanf:"connections_limit"`
+	CORS               *CORS            `koanf:"cors,omitempty"`
+	TLS                *TLS             `koanf:"tls,omitempty"                 validate:"enforced=notnil"`
+	TrustedProxies     []string         `koanf:"trusted_proxies,omitempty"     validate:"enforced=secure_networks"`
+	Respond            RespondConfig    `koanf:"respond"`
+	EnvoyExtAuthz      EnvoyExtAuthz    `koanf:"envoy_ext_authz"`
+	UpstreamTLS        UpstreamTLS      `koanf:"upstream_tls"`
+	IntegrationProfile string           `koanf:"integration_profile,omitempty" validate:"omitempty,oneof=generic traefik caddy nginx haproxy"` //nolint:lll
 }
 
 func (c ServeConfig) Address() string { return fmt.Sprintf("%s:%d", c.Host, c.Port) }
 
+// EnvoyExtAuthz holds the settings used if heimdall is operated as envoy HTTP ext_authz service.
+type EnvoyExtAuthz struct {
+	PathPrefix string `koanf:"path_prefix" validate:"omitempty,startswith=/"`
+}
+
+// UpstreamTLS restricts the key material rules may reference to communicate with the upstream
+// services. Used in proxy mode only.
+type UpstreamTLS struct {
+	AllowedPaths []string `koanf:"allowed_paths"`
+}
+
 type BufferLimit struct {
 	Read  bytesize.ByteSize `koanf:"read"  mapstructure:"read"`
 	Write bytesize.ByteSize `koanf:"write" mapstructure:"write"`
@@ -70,12 +84,13 @@ type ResponseOverride struct {
 type RespondConfig struct {
 	Verbose bool `koanf:"verbose"`
 	With    struct {
-		Accepted            ResponseOverride `koanf:"accepted"`
-		ArgumentError       ResponseOverride `koanf:"argument_error"`
-		AuthenticationError ResponseOverride `koanf:"authentication_error"`
-		AuthorizationError  ResponseOverride `koanf:"authorization_error"`
-		CommunicationError  ResponseOverride `koanf:"communication_error"`
-		InternalError       ResponseOverride `koanf:"internal_error"`
-		NoRuleError         ResponseOverride `koanf:"no_rule_error"`
+		Accepted             ResponseOverride `koanf:"accepted"`
+		ArgumentError        ResponseOverride `koanf:"argument_error"`
+		AuthenticationError  ResponseOverride `koanf:"authentication_error"`
+		AuthorizationError   ResponseOverride `koanf:"authorization_error"`
+		CommunicationError   ResponseOverride `koanf:"communication_error"`
+		InternalError        ResponseOverride `koanf:"internal_error"`
+		NoRuleError          ResponseOverride `koanf:"no_rule_error"`
+		TooManyRequestsError ResponseOverride `koanf:"too_many_requests_error"`
 	} `koanf:"with"`
 }
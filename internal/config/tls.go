@@ -56,9 +56,15 @@ type TrustStore struct {
 	Path string `koanf:"path" mapstructure:"path"`
 }
 
+type ClientAuth struct {
+	Required   bool       `koanf:"required"    mapstructure:"required"`
+	TrustStore TrustStore `koanf:"trust_store" mapstructure:"trust_store"`
+}
+
 type TLS struct {
-	KeyStore     KeyStore        `koanf:"key_store"     mapstructure:"key_store"`
-	KeyID        string          `koanf:"key_id"        mapstructure:"key_id"`
-	CipherSuites TLSCipherSuites `koanf:"cipher_suites" mapstructure:"cipher_suites"`
-	MinVersion   TLSMinVersion   `koanf:"min_version"   mapstructure:"min_version"`
+	KeyStore     KeyStore        `koanf:"key_store"             mapstructure:"key_store"`
+	KeyID        string          `koanf:"key_id"                mapstructure:"key_id"`
+	CipherSuites TLSCipherSuites `koanf:"cipher_suites"         mapstructure:"cipher_suites"`
+	MinVersion   TLSMinVersion   `koanf:"min_version"           mapstructure:"min_version"`
+	ClientAuth   *ClientAuth     `koanf:"client_auth,omitempty" mapstructure:"client_auth"`
 }
@@ -0,0 +1,120 @@
+// Copyright 2025 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package decision
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/dadrus/heimdall/internal/handler/middleware/http/errorhandler"
+	"github.com/dadrus/heimdall/internal/handler/requestcontext"
+	"github.com/dadrus/heimdall/internal/heimdall"
+	"github.com/dadrus/heimdall/internal/x/errorchain"
+)
+
+// integrationProfile defines how the original request is derived from the request sent by
+// a specific proxy and how the headers and cookies for the upstream service are returned.
+type integrationProfile struct {
+	requestcontext.Profile
+
+	// required lists the headers the proxy always sends. Each entry holds alternatives, from
+	// which at least one must be present.
+	required [][]string
+	// cookiesAsHeader results in the cookies for the upstream being returned in a Cookie header
+	// instead of Set-Cookie headers, as the proxy copies the configured headers into the
+	// upstream request.
+	cookiesAsHeader bool
+	// lowerCaseHeaders results in header names being sent in lower case.
+	lowerCaseHeaders bool
+}
+
+var integrationProfiles = map[string]integrationProfile{ //nolint:gochecknoglobals
+	"generic": {
+		Profile: requestcontext.DefaultProfile,
+	},
+	"traefik": {
+		Profile:         requestcontext.DefaultProfile,
+		required:        [][]string{{"X-Forwarded-Method"}, {"X-Forwarded-Uri"}},
+		cookiesAsHeader: true,
+	},
+	"caddy": {
+		Profile:         requestcontext.DefaultProfile,
+		required:        [][]string{{"X-Forwarded-Method"}, {"X-Forwarded-Uri"}},
+		cookiesAsHeader: true,
+	},
+	"nginx": {
+		Profile: requestcontext.Profile{
+			MethodHeader:   "X-Original-Method",
+			ProtoHeader:    "X-Forwarded-Proto",
+			HostHeader:     "X-Forwarded-Host",
+			URIHeader:      "X-Original-Uri",
+			URLHeader:      "X-Original-Url",
+			ClientIPHeader: "X-Real-Ip",
+		},
+		required:        [][]string{{"X-Original-Method"}, {"X-Original-Url", "X-Original-Uri"}},
+		cookiesAsHeader: true,
+	},
+	"haproxy": {
+		Profile:          requestcontext.DefaultProfile,
+		required:         [][]string{{"X-Forwarded-Method"}, {"X-Forwarded-Uri"}},
+		cookiesAsHeader:  true,
+		lowerCaseHeaders: true,
+	},
+}
+
+func getIntegrationProfile(name string) integrationProfile {
+	if profile, ok := integrationProfiles[name]; ok {
+		return profile
+	}
+
+	return integrationProfiles["generic"]
+}
+
+// requireHeaders rejects requests, which do not contain the headers the configured
+// proxy is expected to send. Otherwise, the rule matching would happen on the wrong
+// method or url.
+func requireHeaders(name string, required [][]string, eh errorhandler.ErrorHandler) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		if len(required) == 0 {
+			return next
+		}
+
+		return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+			for _, alternatives := range required {
+				if !hasAnyHeader(req, alternatives) {
+					eh.HandleError(rw, req, errorchain.NewWithMessagef(heimdall.ErrArgument,
+						"request is missing the %s header expected by the '%s' integration profile",
+						strings.Join(alternatives, " or "), name))
+
+					return
+				}
+			}
+
+			next.ServeHTTP(rw, req)
+		})
+	}
+}
+
+func hasAnyHeader(req *http.Request, names []string) bool {
+	for _, name := range names {
+		if len(req.Header.Get(name)) != 0 {
+			return true
+		}
+	}
+
+	return false
+}
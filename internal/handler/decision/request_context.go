@@ -18,6 +18,7 @@ package decision
 
 import (
 	"net/http"
+	"strings"
 
 	"github.com/rs/zerolog"
 
@@ -27,11 +28,13 @@ import (
 
 func newContextFactory(
 	responseCode int,
+	profile integrationProfile,
 ) requestcontext.ContextFactory {
 	return requestcontext.FactoryFunc(func(rw http.ResponseWriter, req *http.Request) requestcontext.Context {
 		return &requestContext{
-			RequestContext: requestcontext.New(req),
+			RequestContext: requestcontext.NewWithProfile(req, profile.Profile),
 			responseCode:   responseCode,
+			profile:        profile,
 			rw:             rw,
 		}
 	})
@@ -42,6 +45,7 @@ type requestContext struct {
 
 	rw           http.ResponseWriter
 	responseCode int
+	profile      integrationProfile
 }
 
 func (r *requestContext) Finalize(_ rule.Backend) error {
@@ -49,17 +53,46 @@ func (r *requestContext) Finalize(_ rule.Backend) error {
 		return err
 	}
 
-	zerolog.Ctx(r.Context()).Debug().Msg("Creating response")
+	logger := zerolog.Ctx(r.Context())
+	logger.Debug().Msg("Creating response")
+
+	header := r.rw.Header()
 
 	uh := r.UpstreamHeaders()
 	for name, values := range uh {
+		if r.profile.lowerCaseHeaders {
+			// bypasses canonicalization of the header name
+			name = strings.ToLower(name)
+			header[name] = append(header[name], values...)
+
+			continue
+		}
+
 		for _, value := range values {
-			r.rw.Header().Add(name, value)
+			header.Add(name, value)
 		}
 	}
 
-	for k, v := range r.UpstreamCookies() {
-		http.SetCookie(r.rw, &http.Cookie{Name: k, Value: v})
+	switch {
+	case len(r.UpstreamCookies()) == 0:
+	case r.profile.cookiesAsHeader:
+		header.Set("Cookie", r.UpstreamCookieHeader())
+	default:
+		for k, v := range r.UpstreamCookies() {
+			http.SetCookie(r.rw, &http.Cookie{Name: k, Value: v})
+		}
+	}
+
+	for _, cookie := range r.ClientCookies() {
+		http.SetCookie(r.rw, cookie)
+	}
+
+	if len(r.UpstreamQueryParameters()) != 0 {
+		logger.Warn().Msg("Query parameter mutations are not supported in decision mode. Ignoring.")
+	}
+
+	if len(r.DynamicMetadata()) != 0 {
+		logger.Warn().Msg("Dynamic metadata is not supported in decision mode. Ignoring.")
 	}
 
 	r.rw.WriteHeader(r.responseCode)
@@ -17,11 +17,14 @@
 package decision
 
 import (
+	"bytes"
 	"errors"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 
+	"github.com/rs/zerolog"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
@@ -32,9 +35,10 @@ func TestRequestContextFinalize(t *testing.T) {
 	t.Parallel()
 
 	for uc, tc := range map[string]struct {
-		code   int
-		setup  func(t *testing.T, rc requestcontext.Context)
-		assert func(t *testing.T, err error, rec *httptest.ResponseRecorder)
+		code    int
+		profile string
+		setup   func(t *testing.T, rc requestcontext.Context)
+		assert  func(t *testing.T, err error, rec *httptest.ResponseRecorder)
 	}{
 		"finalize returns error": {
 			setup: func(t *testing.T, rc requestcontext.Context) {
@@ -141,6 +145,68 @@ func TestRequestContextFinalize(t *testing.T) {
 				assert.Equal(t, http.StatusOK, rec.Code)
 			},
 		},
+		"cookies are returned in a cookie header for profiles requiring that": {
+			code:    http.StatusOK,
+			profile: "traefik",
+			setup: func(t *testing.T, rc requestcontext.Context) {
+				t.Helper()
+
+				rc.AddHeaderForUpstream("X-Foo", "bar")
+				rc.AddCookieForUpstream("x-foo", "bar")
+				rc.AddCookieForUpstream("x-bar", "foo")
+			},
+			assert: func(t *testing.T, err error, rec *httptest.ResponseRecorder) {
+				t.Helper()
+
+				require.NoError(t, err)
+
+				assert.Len(t, rec.Header(), 2)
+				assert.Empty(t, rec.Header().Values("Set-Cookie"))
+				assert.ElementsMatch(t, []string{"x-foo=bar", "x-bar=foo"},
+					strings.Split(rec.Header().Get("Cookie"), "; "))
+				assert.Equal(t, "bar", rec.Header().Get("X-Foo"))
+				assert.Equal(t, http.StatusOK, rec.Code)
+			},
+		},
+		"cookies for the client are set": {
+			code:    http.StatusOK,
+			profile: "traefik",
+			setup: func(t *testing.T, rc requestcontext.Context) {
+				t.Helper()
+
+				rc.AddCookieForUpstream("x-foo", "bar")
+				rc.AddCookieForClient(&http.Cookie{Name: "sid", Value: "foo", Path: "/", HttpOnly: true})
+			},
+			assert: func(t *testing.T, err error, rec *httptest.ResponseRecorder) {
+				t.Helper()
+
+				require.NoError(t, err)
+
+				assert.Len(t, rec.Header(), 2)
+				assert.Equal(t, []string{"sid=foo; Path=/; HttpOnly"}, rec.Header().Values("Set-Cookie"))
+				assert.Equal(t, "x-foo=bar", rec.Header().Get("Cookie"))
+				assert.Equal(t, http.StatusOK, rec.Code)
+			},
+		},
+		"header names are returned in lower case for profiles requiring that": {
+			code:    http.StatusOK,
+			profile: "haproxy",
+			setup: func(t *testing.T, rc requestcontext.Context) {
+				t.Helper()
+
+				rc.AddHeaderForUpstream("X-Foo", "bar")
+				rc.AddHeaderForUpstream("X-Foo", "baz")
+			},
+			assert: func(t *testing.T, err error, rec *httptest.ResponseRecorder) {
+				t.Helper()
+
+				require.NoError(t, err)
+
+				assert.Len(t, rec.Header(), 1)
+				assert.Equal(t, []string{"bar", "baz"}, rec.Header()["x-foo"])
+				assert.Equal(t, http.StatusOK, rec.Code)
+			},
+		},
 	} {
 		t.Run(uc, func(t *testing.T) {
 			// GIVEN
@@ -149,7 +215,7 @@ func TestRequestContextFinalize(t *testing.T) {
 			req, err := http.NewRequestWithContext(t.Context(), http.MethodPost, "http://heimdall.local/foo", nil)
 			require.NoError(t, err)
 
-			reqCtx := newContextFactory(tc.code).Create(rw, req)
+			reqCtx := newContextFactory(tc.code, getIntegrationProfile(tc.profile)).Create(rw, req)
 			tc.setup(t, reqCtx)
 
 			// WHEN
@@ -160,3 +226,30 @@ func TestRequestContextFinalize(t *testing.T) {
 		})
 	}
 }
+
+func TestRequestContextFinalizeWithUnsupportedOutputs(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	var logs bytes.Buffer
+
+	rw := httptest.NewRecorder()
+	ctx := zerolog.New(&logs).WithContext(t.Context())
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, "http://heimdall.local/foo", nil)
+	require.NoError(t, err)
+
+	reqCtx := newContextFactory(http.StatusOK, getIntegrationProfile("generic")).Create(rw, req)
+	reqCtx.AddQueryParameterForUpstream("foo", "bar")
+	reqCtx.AddDynamicMetadata("bar", "baz")
+
+	// WHEN
+	err = reqCtx.Finalize(nil)
+
+	// THEN
+	require.NoError(t, err)
+	assert.Empty(t, rw.Header())
+	assert.Equal(t, http.StatusOK, rw.Code)
+	assert.Contains(t, logs.String(), "Query parameter mutations are not supported in decision mode")
+	assert.Contains(t, logs.String(), "Dynamic metadata is not supported in decision mode")
+}
@@ -57,9 +57,11 @@ func newService(
 		errorhandler.WithAuthorizationErrorCode(cfg.Respond.With.AuthorizationError.Code),
 		errorhandler.WithCommunicationErrorCode(cfg.Respond.With.CommunicationError.Code),
 		errorhandler.WithNoRuleErrorCode(cfg.Respond.With.NoRuleError.Code),
+		errorhandler.WithTooManyRequestsErrorCode(cfg.Respond.With.TooManyRequestsError.Code),
 		errorhandler.WithInternalServerErrorCode(cfg.Respond.With.InternalError.Code),
 	)
 	acceptedCode := x.IfThenElse(cfg.Respond.With.Accepted.Code != 0, cfg.Respond.With.Accepted.Code, http.StatusOK)
+	profile := getIntegrationProfile(cfg.IntegrationProfile)
 
 	hc := alice.New(
 		trustedproxy.New(
@@ -82,7 +84,8 @@ func newService(
 		logger.New(log),
 		dump.New(),
 		cachemiddleware.New(cch),
-	).Then(service.NewHandler(newContextFactory(acceptedCode), exec, eh))
+		requireHeaders(cfg.IntegrationProfile, profile.required, eh),
+	).Then(service.NewHandler(newContextFactory(acceptedCode, profile), exec, eh))
 
 	return &http.Server{
 		Handler:        hc,
@@ -530,6 +530,75 @@ func TestHandleDecisionEndpointRequest(t *testing.T) {
 				assert.Equal(t, http.StatusOK, response.StatusCode)
 			},
 		},
+		"successful rule execution - request method and url are taken from the headers " +
+			"defined by the integration profile": {
+			serviceConf: config.ServeConfig{TrustedProxies: []string{"0.0.0.0/0"}, IntegrationProfile: "nginx"},
+			createRequest: func(t *testing.T, host string) *http.Request {
+				t.Helper()
+
+				req, err := http.NewRequestWithContext(
+					t.Context(),
+					http.MethodGet,
+					fmt.Sprintf("http://%s/_auth", host),
+					nil,
+				)
+				require.NoError(t, err)
+
+				req.Header.Set("X-Original-Method", http.MethodPatch)
+				req.Header.Set("X-Original-Uri", "/bar?foo=baz")
+				req.Header.Set("X-Forwarded-Uri", "/baz")
+				req.Header.Set("X-Real-Ip", "192.168.1.1")
+
+				return req
+			},
+			configureMocks: func(t *testing.T, exec *mocks4.ExecutorMock) {
+				t.Helper()
+
+				exec.EXPECT().Execute(
+					mock.MatchedBy(func(ctx heimdall.RequestContext) bool {
+						req := ctx.Request()
+
+						return req.URL.Path == "/bar" &&
+							req.URL.Query().Get("foo") == "baz" &&
+							req.Method == http.MethodPatch &&
+							req.ClientIPAddresses[0] == "192.168.1.1"
+					}),
+				).Return(nil, nil)
+			},
+			assertResponse: func(t *testing.T, err error, response *http.Response) {
+				t.Helper()
+
+				require.NoError(t, err)
+				assert.Equal(t, http.StatusOK, response.StatusCode)
+			},
+		},
+		"request is rejected if headers required by the integration profile are missing": {
+			serviceConf: config.ServeConfig{IntegrationProfile: "traefik"},
+			createRequest: func(t *testing.T, host string) *http.Request {
+				t.Helper()
+
+				req, err := http.NewRequestWithContext(
+					t.Context(),
+					http.MethodGet,
+					fmt.Sprintf("http://%s/foobar", host),
+					nil,
+				)
+				require.NoError(t, err)
+
+				// dropped, as the source is not trusted
+				req.Header.Set("X-Forwarded-Method", http.MethodGet)
+				req.Header.Set("X-Forwarded-Uri", "/bar")
+
+				return req
+			},
+			configureMocks: func(t *testing.T, _ *mocks4.ExecutorMock) { t.Helper() },
+			assertResponse: func(t *testing.T, err error, response *http.Response) {
+				t.Helper()
+
+				require.NoError(t, err)
+				assert.Equal(t, http.StatusBadRequest, response.StatusCode)
+			},
+		},
 	} {
 		t.Run(uc, func(t *testing.T) {
 			// GIVEN
@@ -18,6 +18,8 @@ package grpcv3
 
 import (
 	"context"
+	"crypto/x509"
+	"encoding/pem"
 	"fmt"
 	"net/http"
 	"net/url"
@@ -29,10 +31,12 @@ import (
 	"google.golang.org/genproto/googleapis/rpc/status"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/types/known/structpb"
 
 	"github.com/dadrus/heimdall/internal/heimdall"
 	"github.com/dadrus/heimdall/internal/rules/mechanisms/contenttype"
 	"github.com/dadrus/heimdall/internal/x"
+	"github.com/dadrus/heimdall/internal/x/stringx"
 )
 
 type RequestContext struct {
@@ -43,8 +47,13 @@ type RequestContext struct {
 	reqURL          *url.URL
 	reqBody         string
 	reqRawBody      []byte
+	peerCert        string
+	metadata        map[string]any
 	upstreamHeaders http.Header
 	upstreamCookies map[string]string
+	upstreamQuery   map[string]string
+	dynamicMetadata map[string]any
+	clientCookies   []*http.Cookie
 	err             error
 
 	savedBody any
@@ -73,11 +82,29 @@ func NewRequestContext(ctx context.Context, req *envoy_auth.CheckRequest) *Reque
 			RawQuery: req.GetAttributes().GetRequest().GetHttp().GetQuery(),
 			Fragment: req.GetAttributes().GetRequest().GetHttp().GetFragment(),
 		},
-		reqBody:         req.GetAttributes().GetRequest().GetHttp().GetBody(),
-		reqRawBody:      req.GetAttributes().GetRequest().GetHttp().GetRawBody(),
+		reqBody:    req.GetAttributes().GetRequest().GetHttp().GetBody(),
+		reqRawBody: req.GetAttributes().GetRequest().GetHttp().GetRawBody(),
+		peerCert:   req.GetAttributes().GetSource().GetCertificate(),
+		metadata: map[string]any{
+			"dynamic":            filterMetadata(req.GetAttributes().GetMetadataContext()),
+			"route":              filterMetadata(req.GetAttributes().GetRouteMetadataContext()),
+			"context_extensions": req.GetAttributes().GetContextExtensions(),
+		},
 		upstreamHeaders: make(http.Header),
 		upstreamCookies: make(map[string]string),
+		upstreamQuery:   make(map[string]string),
+		dynamicMetadata: make(map[string]any),
+	}
+}
+
+func filterMetadata(md *envoy_core.Metadata) map[string]any {
+	result := make(map[string]any, len(md.GetFilterMetadata()))
+
+	for namespace, value := range md.GetFilterMetadata() {
+		result[namespace] = value.AsMap()
 	}
+
+	return result
 }
 
 func canonicalizeHeaders(headers map[string]string) map[string]string {
@@ -96,6 +123,7 @@ func (r *RequestContext) Request() *heimdall.Request {
 		Method:            r.reqMethod,
 		URL:               &heimdall.URL{URL: *r.reqURL},
 		ClientIPAddresses: r.ips,
+		Metadata:          r.metadata,
 	}
 }
 
@@ -117,32 +145,55 @@ func (r *RequestContext) Cookie(name string) string {
 	return ""
 }
 
+// RawBody returns the body of the request as is.
+func (r *RequestContext) RawBody() []byte {
+	// envoy sends the body either as string, or, if configured with pack_as_bytes, as raw bytes
+	return x.IfThenElse(len(r.reqRawBody) != 0, r.reqRawBody, stringx.ToBytes(r.reqBody))
+}
+
 func (r *RequestContext) Body() any {
 	if r.savedBody == nil {
-		decoder, err := contenttype.NewDecoder(r.Header("Content-Type"))
-		if err != nil {
-			r.savedBody = string(r.reqRawBody)
+		r.savedBody = contenttype.DecodeBody(r.Header("Content-Type"), r.RawBody())
+	}
 
-			return r.savedBody
-		}
+	return r.savedBody
+}
 
-		data, err := decoder.Decode(r.reqRawBody)
-		if err != nil {
-			r.savedBody = string(r.reqRawBody)
+func (r *RequestContext) ClientCertificates() []*x509.Certificate {
+	if len(r.peerCert) == 0 {
+		return nil
+	}
 
-			return r.savedBody
-		}
+	// envoy forwards the url encoded PEM of the peer certificate
+	data, err := url.PathUnescape(r.peerCert)
+	if err != nil {
+		return nil
+	}
 
-		r.savedBody = data
+	block, _ := pem.Decode([]byte(data))
+	if block == nil {
+		return nil
 	}
 
-	return r.savedBody
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return nil
+	}
+
+	return []*x509.Certificate{cert}
 }
 
 func (r *RequestContext) Context() context.Context                { return r.ctx }
 func (r *RequestContext) SetPipelineError(err error)              { r.err = err }
 func (r *RequestContext) AddHeaderForUpstream(name, value string) { r.upstreamHeaders.Add(name, value) }
 func (r *RequestContext) AddCookieForUpstream(name, value string) { r.upstreamCookies[name] = value }
+func (r *RequestContext) AddQueryParameterForUpstream(name, value string) {
+	r.upstreamQuery[name] = value
+}
+func (r *RequestContext) AddDynamicMetadata(key, value string) { r.dynamicMetadata[key] = value }
+func (r *RequestContext) AddCookieForClient(cookie *http.Cookie) {
+	r.clientCookies = append(r.clientCookies, cookie)
+}
 
 func (r *RequestContext) Outputs() map[string]any {
 	if r.outputs == nil {
@@ -191,10 +242,35 @@ func (r *RequestContext) Finalize() (*envoy_auth.CheckResponse, error) {
 		}
 	}
 
+	queryParams := make([]*envoy_core.QueryParameter, 0, len(r.upstreamQuery))
+	for name, value := range r.upstreamQuery {
+		queryParams = append(queryParams, &envoy_core.QueryParameter{Key: name, Value: value})
+	}
+
+	// the cookies for the client are added by envoy to the response from the upstream service
+	responseHeaders := make([]*envoy_core.HeaderValueOption, len(r.clientCookies))
+	for idx, cookie := range r.clientCookies {
+		responseHeaders[idx] = &envoy_core.HeaderValueOption{
+			Header: &envoy_core.HeaderValue{Key: "Set-Cookie", Value: cookie.String()},
+		}
+	}
+
+	var dynamicMetadata *structpb.Struct
+
+	if len(r.dynamicMetadata) != 0 {
+		// cannot fail as all values are strings
+		dynamicMetadata, _ = structpb.NewStruct(r.dynamicMetadata)
+	}
+
 	return &envoy_auth.CheckResponse{
-		Status: &status.Status{Code: int32(codes.OK)},
+		Status:          &status.Status{Code: int32(codes.OK)},
+		DynamicMetadata: dynamicMetadata,
 		HttpResponse: &envoy_auth.CheckResponse_OkResponse{
-			OkResponse: &envoy_auth.OkHttpResponse{Headers: headers},
+			OkResponse: &envoy_auth.OkHttpResponse{
+				Headers:              headers,
+				QueryParametersToSet: queryParams,
+				ResponseHeadersToAdd: responseHeaders,
+			},
 		},
 	}, nil
 }
@@ -17,10 +17,14 @@
 package grpcv3
 
 import (
+	"crypto/x509"
+	"crypto/x509/pkix"
 	"errors"
 	"net/http"
+	"net/url"
 	"strings"
 	"testing"
+	"time"
 
 	corev3 "github.com/envoyproxy/go-control-plane/envoy/config/core/v3"
 	envoy_auth "github.com/envoyproxy/go-control-plane/envoy/service/auth/v3"
@@ -28,8 +32,11 @@ import (
 	"github.com/stretchr/testify/require"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/types/known/structpb"
 
 	"github.com/dadrus/heimdall/internal/heimdall"
+	"github.com/dadrus/heimdall/internal/x/pkix/pemx"
+	"github.com/dadrus/heimdall/internal/x/testsupport"
 )
 
 func TestNewRequestContext(t *testing.T) {
@@ -56,6 +63,21 @@ func TestNewRequestContext(t *testing.T) {
 			Request: &envoy_auth.AttributeContext_Request{
 				Http: httpReq,
 			},
+			MetadataContext: &corev3.Metadata{
+				FilterMetadata: map[string]*structpb.Struct{
+					"envoy.filters.http.jwt_authn": {
+						Fields: map[string]*structpb.Value{"sub": structpb.NewStringValue("foo")},
+					},
+				},
+			},
+			RouteMetadataContext: &corev3.Metadata{
+				FilterMetadata: map[string]*structpb.Struct{
+					"heimdall": {
+						Fields: map[string]*structpb.Value{"tenant": structpb.NewStringValue("bar")},
+					},
+				},
+			},
+			ContextExtensions: map[string]string{"virtual_host": "baz"},
 		},
 	}
 	md := metadata.New(nil)
@@ -85,6 +107,11 @@ func TestNewRequestContext(t *testing.T) {
 	require.Empty(t, ctx.Request().Cookie("baz"))
 	require.NotNil(t, ctx.Context())
 	assert.Equal(t, []string{"127.0.0.1", "192.168.1.1"}, ctx.Request().ClientIPAddresses)
+	assert.Equal(t, map[string]any{
+		"dynamic":            map[string]any{"envoy.filters.http.jwt_authn": map[string]any{"sub": "foo"}},
+		"route":              map[string]any{"heimdall": map[string]any{"tenant": "bar"}},
+		"context_extensions": map[string]string{"virtual_host": "baz"},
+	}, ctx.Request().Metadata)
 }
 
 func TestFinalizeRequestContext(t *testing.T) {
@@ -212,6 +239,32 @@ func TestFinalizeRequestContext(t *testing.T) {
 				assert.Equal(t, "some-cookie=value-1", header.GetValue())
 			},
 		},
+		"successful with query parameters and dynamic metadata": {
+			updateContext: func(t *testing.T, ctx heimdall.RequestContext) {
+				t.Helper()
+
+				ctx.AddQueryParameterForUpstream("tenant", "foo")
+				ctx.AddQueryParameterForUpstream("tenant", "bar")
+				ctx.AddDynamicMetadata("subject", "baz")
+			},
+			assert: func(t *testing.T, err error, response *envoy_auth.CheckResponse) {
+				t.Helper()
+
+				require.NoError(t, err)
+				require.NotNil(t, response)
+
+				assert.Equal(t, int32(codes.OK), response.GetStatus().GetCode())
+				assert.Equal(t, map[string]any{"subject": "baz"}, response.GetDynamicMetadata().AsMap())
+
+				okResponse := response.GetOkResponse()
+				require.NotNil(t, okResponse)
+
+				assert.Empty(t, okResponse.GetHeaders())
+				require.Len(t, okResponse.GetQueryParametersToSet(), 1)
+				assert.Equal(t, "tenant", okResponse.GetQueryParametersToSet()[0].GetKey())
+				assert.Equal(t, "bar", okResponse.GetQueryParametersToSet()[0].GetValue())
+			},
+		},
 		"erroneous with header and cookie": {
 			updateContext: func(t *testing.T, ctx heimdall.RequestContext) {
 				t.Helper()
@@ -267,13 +320,87 @@ func TestFinalizeRequestContext(t *testing.T) {
 	}
 }
 
+func TestRequestContextClientCertificates(t *testing.T) {
+	t.Parallel()
+
+	ca, err := testsupport.NewRootCA("Test CA", time.Hour)
+	require.NoError(t, err)
+
+	pemBytes, err := pemx.BuildPEM(pemx.WithX509Certificate(ca.Certificate))
+	require.NoError(t, err)
+
+	for uc, tc := range map[string]struct {
+		certificate string
+		assert      func(t *testing.T, certs []*x509.Certificate)
+	}{
+		"no certificate": {
+			assert: func(t *testing.T, certs []*x509.Certificate) {
+				t.Helper()
+
+				assert.Empty(t, certs)
+			},
+		},
+		"not url encoded certificate": {
+			certificate: "%zz",
+			assert: func(t *testing.T, certs []*x509.Certificate) {
+				t.Helper()
+
+				assert.Empty(t, certs)
+			},
+		},
+		"not pem encoded certificate": {
+			certificate: "foo",
+			assert: func(t *testing.T, certs []*x509.Certificate) {
+				t.Helper()
+
+				assert.Empty(t, certs)
+			},
+		},
+		"valid certificate": {
+			certificate: url.PathEscape(string(pemBytes)),
+			assert: func(t *testing.T, certs []*x509.Certificate) {
+				t.Helper()
+
+				require.Len(t, certs, 1)
+				assert.Equal(t, pkix.Name{
+					CommonName:   "Test CA",
+					Organization: []string{"Test"},
+					Country:      []string{"EU"},
+				}.String(), certs[0].Subject.String())
+			},
+		},
+	} {
+		t.Run(uc, func(t *testing.T) {
+			// GIVEN
+			ctx := NewRequestContext(
+				t.Context(),
+				&envoy_auth.CheckRequest{
+					Attributes: &envoy_auth.AttributeContext{
+						Source: &envoy_auth.AttributeContext_Peer{Certificate: tc.certificate},
+						Request: &envoy_auth.AttributeContext_Request{
+							Http: &envoy_auth.AttributeContext_HttpRequest{},
+						},
+					},
+				},
+			)
+
+			// WHEN
+			certs := ctx.Request().ClientCertificates()
+
+			// THEN
+			tc.assert(t, certs)
+		})
+	}
+}
+
 func TestRequestContextBody(t *testing.T) {
 	t.Parallel()
 
 	for uc, tc := range map[string]struct {
-		ct     string
-		body   []byte
-		expect any
+		ct      string
+		body    []byte
+		strBody string
+		expect  any
 	}{
 		"No body": {
 			ct:     "empty",
@@ -310,6 +437,11 @@ func TestRequestContextBody(t *testing.T) {
 			body:   []byte("content=heimdall"),
 			expect: "content=heimdall",
 		},
+		"json encoded sent as string": {
+			ct:      "application/json",
+			strBody: `{ "content": "heimdall" }`,
+			expect:  map[string]any{"content": "heimdall"},
+		},
 	} {
 		t.Run(uc, func(t *testing.T) {
 			// GIVEN
@@ -319,7 +451,7 @@ func TestRequestContextBody(t *testing.T) {
 					Attributes: &envoy_auth.AttributeContext{
 						Request: &envoy_auth.AttributeContext_Request{
 							Http: &envoy_auth.AttributeContext_HttpRequest{
-								RawBody: tc.body, Headers: map[string]string{"content-type": tc.ct},
+								RawBody: tc.body, Body: tc.strBody, Headers: map[string]string{"content-type": tc.ct},
 							},
 						},
 					},
@@ -72,6 +72,7 @@ func newService(
 			errorhandler.WithAuthorizationErrorCode(cfg.Respond.With.AuthorizationError.Code),
 			errorhandler.WithCommunicationErrorCode(cfg.Respond.With.CommunicationError.Code),
 			errorhandler.WithNoRuleErrorCode(cfg.Respond.With.NoRuleError.Code),
+			errorhandler.WithTooManyRequestsErrorCode(cfg.Respond.With.TooManyRequestsError.Code),
 			errorhandler.WithInternalServerErrorCode(cfg.Respond.With.InternalError.Code),
 		),
 		// the accesslogger is used here to have access to the error object
@@ -0,0 +1,97 @@
+// Copyright 2025 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package httpv3
+
+import (
+	"net/http"
+
+	"github.com/rs/zerolog"
+	"golang.org/x/net/http/httpguts"
+
+	"github.com/dadrus/heimdall/internal/handler/requestcontext"
+	"github.com/dadrus/heimdall/internal/rules/rule"
+)
+
+// dynamicMetadataHeaderPrefix namespaces the headers carrying dynamic metadata, so these can
+// neither override the headers for the upstream service, nor be confused with them.
+const dynamicMetadataHeaderPrefix = "X-Heimdall-Metadata-"
+
+func newContextFactory() requestcontext.ContextFactory {
+	return requestcontext.FactoryFunc(func(rw http.ResponseWriter, req *http.Request) requestcontext.Context {
+		return &requestContext{
+			RequestContext: requestcontext.New(req),
+			rw:             rw,
+		}
+	})
+}
+
+type requestContext struct {
+	*requestcontext.RequestContext
+
+	rw http.ResponseWriter
+}
+
+// Finalize creates the response expected by envoy's HTTP ext_authz filter. Envoy treats only
+// responses with 200 status code as allowed. The headers from that response are then added to
+// the upstream request (if allowed by allowed_upstream_headers) or made available as dynamic
+// metadata (if allowed by dynamic_metadata_from_headers). The latter are sent with the
+// X-Heimdall-Metadata- prefix.
+func (r *requestContext) Finalize(_ rule.Backend) error {
+	if err := r.PipelineError(); err != nil {
+		return err
+	}
+
+	logger := zerolog.Ctx(r.Context())
+	logger.Debug().Msg("Creating response")
+
+	for name, values := range r.UpstreamHeaders() {
+		for _, value := range values {
+			r.rw.Header().Add(name, value)
+		}
+	}
+
+	if len(r.UpstreamCookies()) != 0 {
+		// envoy overrides the upstream header with the one from the response,
+		// so the cookies sent by the client must be included
+		r.rw.Header().Set("Cookie", r.UpstreamCookieHeader())
+	}
+
+	// envoy adds these to the response to the client if allowed by allowed_client_headers_on_success
+	for _, cookie := range r.ClientCookies() {
+		http.SetCookie(r.rw, cookie)
+	}
+
+	for key, value := range r.DynamicMetadata() {
+		name := dynamicMetadataHeaderPrefix + key
+		if !httpguts.ValidHeaderFieldName(name) || !httpguts.ValidHeaderFieldValue(value) {
+			logger.Warn().Str("_key", key).
+				Msg("Dynamic metadata entry cannot be represented as HTTP header. Ignoring.")
+
+			continue
+		}
+
+		r.rw.Header().Set(name, value)
+	}
+
+	if len(r.UpstreamQueryParameters()) != 0 {
+		logger.Warn().Msg("Query parameter mutations are not supported by envoy HTTP ext_authz service. Ignoring.")
+	}
+
+	r.rw.WriteHeader(http.StatusOK)
+
+	return nil
+}
@@ -0,0 +1,139 @@
+// Copyright 2025 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package httpv3
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dadrus/heimdall/internal/handler/requestcontext"
+)
+
+func TestRequestContextFinalize(t *testing.T) {
+	t.Parallel()
+
+	for uc, tc := range map[string]struct {
+		setup  func(t *testing.T, rc requestcontext.Context)
+		assert func(t *testing.T, err error, rec *httptest.ResponseRecorder)
+	}{
+		"finalize returns error": {
+			setup: func(t *testing.T, rc requestcontext.Context) {
+				t.Helper()
+
+				rc.SetPipelineError(errors.New("test error"))
+			},
+			assert: func(t *testing.T, err error, _ *httptest.ResponseRecorder) {
+				t.Helper()
+
+				require.Error(t, err)
+			},
+		},
+		"nothing set": {
+			setup: func(t *testing.T, _ requestcontext.Context) { t.Helper() },
+			assert: func(t *testing.T, err error, rec *httptest.ResponseRecorder) {
+				t.Helper()
+
+				require.NoError(t, err)
+
+				assert.Empty(t, rec.Header())
+				assert.Equal(t, http.StatusOK, rec.Code)
+			},
+		},
+		"headers, cookies and dynamic metadata are set": {
+			setup: func(t *testing.T, rc requestcontext.Context) {
+				t.Helper()
+
+				rc.AddHeaderForUpstream("X-Foo", "bar")
+				rc.AddHeaderForUpstream("X-Foo", "baz")
+				rc.AddCookieForUpstream("foo", "bar")
+				rc.AddCookieForUpstream("baz", "foo")
+				rc.AddDynamicMetadata("subject", "alice")
+			},
+			assert: func(t *testing.T, err error, rec *httptest.ResponseRecorder) {
+				t.Helper()
+
+				require.NoError(t, err)
+
+				assert.Len(t, rec.Header(), 3)
+				assert.ElementsMatch(t, []string{"bar", "baz"}, rec.Header().Values("X-Foo"))
+				assert.Equal(t, "alice", rec.Header().Get("X-Heimdall-Metadata-Subject"))
+				assert.ElementsMatch(t,
+					[]string{"bar=foo", "foo=bar", "baz=foo"},
+					strings.Split(rec.Header().Get("Cookie"), "; "))
+				assert.Equal(t, http.StatusOK, rec.Code)
+			},
+		},
+		"dynamic metadata not representable as header is ignored": {
+			setup: func(t *testing.T, rc requestcontext.Context) {
+				t.Helper()
+
+				rc.AddDynamicMetadata("foo bar", "baz")
+				rc.AddDynamicMetadata("foo", "bar\r\nX-Injected: baz")
+				rc.AddDynamicMetadata("bar", "baz")
+			},
+			assert: func(t *testing.T, err error, rec *httptest.ResponseRecorder) {
+				t.Helper()
+
+				require.NoError(t, err)
+
+				assert.Len(t, rec.Header(), 1)
+				assert.Equal(t, "baz", rec.Header().Get("X-Heimdall-Metadata-Bar"))
+				assert.Equal(t, http.StatusOK, rec.Code)
+			},
+		},
+		"query parameters are ignored": {
+			setup: func(t *testing.T, rc requestcontext.Context) {
+				t.Helper()
+
+				rc.AddQueryParameterForUpstream("foo", "bar")
+			},
+			assert: func(t *testing.T, err error, rec *httptest.ResponseRecorder) {
+				t.Helper()
+
+				require.NoError(t, err)
+
+				assert.Empty(t, rec.Header())
+				assert.Equal(t, http.StatusOK, rec.Code)
+			},
+		},
+	} {
+		t.Run(uc, func(t *testing.T) {
+			// GIVEN
+			rw := httptest.NewRecorder()
+
+			req, err := http.NewRequestWithContext(t.Context(), http.MethodPost, "http://heimdall.local/foo", nil)
+			require.NoError(t, err)
+
+			req.Header.Set("Cookie", "foo=baz; bar=foo")
+
+			reqCtx := newContextFactory().Create(rw, req)
+			tc.setup(t, reqCtx)
+
+			// WHEN
+			err = reqCtx.Finalize(nil)
+
+			// THEN
+			tc.assert(t, err, rw)
+		})
+	}
+}
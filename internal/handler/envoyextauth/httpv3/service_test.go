@@ -0,0 +1,175 @@
+// Copyright 2025 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package httpv3
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dadrus/heimdall/internal/cache/mocks"
+	"github.com/dadrus/heimdall/internal/config"
+	"github.com/dadrus/heimdall/internal/handler/listener"
+	"github.com/dadrus/heimdall/internal/heimdall"
+	mocks4 "github.com/dadrus/heimdall/internal/rules/rule/mocks"
+	"github.com/dadrus/heimdall/internal/x/testsupport"
+)
+
+func TestHandleEnvoyHTTPExtAuthzRequest(t *testing.T) {
+	t.Parallel()
+
+	for uc, tc := range map[string]struct {
+		serviceConf    config.ServeConfig
+		path           string
+		configureMocks func(t *testing.T, exec *mocks4.ExecutorMock)
+		assertResponse func(t *testing.T, err error, response *http.Response)
+	}{
+		"no rules configured": {
+			path: "/foo",
+			configureMocks: func(t *testing.T, exec *mocks4.ExecutorMock) {
+				t.Helper()
+
+				exec.EXPECT().Execute(mock.Anything).Return(nil, heimdall.ErrNoRuleFound)
+			},
+			assertResponse: func(t *testing.T, err error, response *http.Response) {
+				t.Helper()
+
+				require.NoError(t, err)
+				assert.Equal(t, http.StatusNotFound, response.StatusCode)
+			},
+		},
+		"rule execution fails with authentication error": {
+			path: "/foo",
+			configureMocks: func(t *testing.T, exec *mocks4.ExecutorMock) {
+				t.Helper()
+
+				exec.EXPECT().Execute(mock.Anything).Return(nil, heimdall.ErrAuthentication)
+			},
+			assertResponse: func(t *testing.T, err error, response *http.Response) {
+				t.Helper()
+
+				require.NoError(t, err)
+				assert.Equal(t, http.StatusUnauthorized, response.StatusCode)
+			},
+		},
+		"successful rule execution without path prefix configured": {
+			path: "/foo",
+			configureMocks: func(t *testing.T, exec *mocks4.ExecutorMock) {
+				t.Helper()
+
+				exec.EXPECT().Execute(
+					mock.MatchedBy(func(ctx heimdall.RequestContext) bool {
+						ctx.AddHeaderForUpstream("X-Foo-Bar", "baz")
+						ctx.AddDynamicMetadata("subject", "foo")
+
+						return ctx.Request().URL.Path == "/foo" && ctx.Request().Method == http.MethodGet
+					}),
+				).Return(nil, nil)
+			},
+			assertResponse: func(t *testing.T, err error, response *http.Response) {
+				t.Helper()
+
+				require.NoError(t, err)
+				assert.Equal(t, http.StatusOK, response.StatusCode)
+				assert.Equal(t, "baz", response.Header.Get("X-Foo-Bar"))
+				assert.Equal(t, "foo", response.Header.Get("X-Heimdall-Metadata-Subject"))
+			},
+		},
+		"successful rule execution with path prefix configured": {
+			serviceConf: config.ServeConfig{EnvoyExtAuthz: config.EnvoyExtAuthz{PathPrefix: "/ext_authz"}},
+			path:        "/ext_authz/foo",
+			configureMocks: func(t *testing.T, exec *mocks4.ExecutorMock) {
+				t.Helper()
+
+				exec.EXPECT().Execute(
+					mock.MatchedBy(func(ctx heimdall.RequestContext) bool {
+						return ctx.Request().URL.Path == "/foo"
+					}),
+				).Return(nil, nil)
+			},
+			assertResponse: func(t *testing.T, err error, response *http.Response) {
+				t.Helper()
+
+				require.NoError(t, err)
+				assert.Equal(t, http.StatusOK, response.StatusCode)
+			},
+		},
+		"request without configured path prefix": {
+			serviceConf: config.ServeConfig{EnvoyExtAuthz: config.EnvoyExtAuthz{PathPrefix: "/ext_authz"}},
+			path:        "/foo",
+			configureMocks: func(t *testing.T, _ *mocks4.ExecutorMock) {
+				t.Helper()
+			},
+			assertResponse: func(t *testing.T, err error, response *http.Response) {
+				t.Helper()
+
+				require.NoError(t, err)
+				assert.Equal(t, http.StatusNotFound, response.StatusCode)
+			},
+		},
+	} {
+		t.Run(uc, func(t *testing.T) {
+			// GIVEN
+			port, err := testsupport.GetFreePort()
+			require.NoError(t, err)
+
+			srvConf := tc.serviceConf
+			srvConf.Host = "127.0.0.1"
+			srvConf.Port = port
+
+			listener, err := listener.New("tcp", "test", srvConf.Address(), srvConf.TLS, nil, nil)
+			require.NoError(t, err)
+
+			conf := &config.Configuration{Serve: srvConf}
+			cch := mocks.NewCacheMock(t)
+			exec := mocks4.NewExecutorMock(t)
+
+			tc.configureMocks(t, exec)
+
+			client := &http.Client{Transport: &http.Transport{}}
+
+			srv := newService(conf, cch, log.Logger, exec)
+			defer srv.Shutdown(t.Context())
+
+			go func() {
+				srv.Serve(listener)
+			}()
+
+			time.Sleep(50 * time.Millisecond)
+
+			req, err := http.NewRequestWithContext(t.Context(), http.MethodGet,
+				fmt.Sprintf("http://%s%s", srvConf.Address(), tc.path), nil)
+			require.NoError(t, err)
+
+			// WHEN
+			resp, err := client.Do(req)
+
+			// THEN
+			if err == nil {
+				defer resp.Body.Close()
+			}
+
+			tc.assertResponse(t, err, resp)
+		})
+	}
+}
@@ -27,12 +27,20 @@ import (
 
 	"github.com/dadrus/heimdall/internal/handler/middleware/http/errorhandler"
 	"github.com/dadrus/heimdall/internal/handler/middleware/http/methodfilter"
+	"github.com/dadrus/heimdall/internal/heimdall"
 	"github.com/dadrus/heimdall/internal/keyholder"
+	"github.com/dadrus/heimdall/internal/session"
+	"github.com/dadrus/heimdall/internal/x/errorchain"
 )
 
-func newManagementHandler(khr keyholder.Registry, eh errorhandler.ErrorHandler) http.Handler {
+func newManagementHandler(
+	khr keyholder.Registry,
+	sm session.Manager,
+	eh errorhandler.ErrorHandler,
+) http.Handler {
 	mh := &handler{
 		khr: khr,
+		sm:  sm,
 		eh:  eh,
 	}
 
@@ -45,14 +53,73 @@ func newManagementHandler(khr keyholder.Registry, eh errorhandler.ErrorHandler)
 		alice.New(methodfilter.New(http.MethodGet)).
 			Then(etag.Handler(http.HandlerFunc(mh.jwks), false)))
 
+	if sm != nil {
+		mux.Handle(EndpointSessions,
+			alice.New(methodfilter.New(http.MethodDelete), requireClientCertificate(eh)).
+				Then(http.HandlerFunc(mh.revokeSubjectSessions)))
+		mux.Handle(EndpointSessions+"/{id}",
+			alice.New(methodfilter.New(http.MethodDelete), requireClientCertificate(eh)).
+				Then(http.HandlerFunc(mh.revokeSession)))
+	}
+
 	return mux
 }
 
+// requireClientCertificate admits only requests of clients, which authenticated using a
+// certificate verified against the trust store configured for client authentication.
+func requireClientCertificate(eh errorhandler.ErrorHandler) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+			if req.TLS == nil || len(req.TLS.VerifiedChains) == 0 {
+				eh.HandleError(rw, req, errorchain.NewWithMessage(heimdall.ErrAuthentication,
+					"no verified client certificate presented"))
+
+				return
+			}
+
+			next.ServeHTTP(rw, req)
+		})
+	}
+}
+
 type handler struct {
 	khr keyholder.Registry
+	sm  session.Manager
 	eh  errorhandler.ErrorHandler
 }
 
+// revokeSession revokes the session referenced by the id in the path.
+func (h *handler) revokeSession(rw http.ResponseWriter, req *http.Request) {
+	if err := h.sm.Revoke(req.Context(), req.PathValue("id")); err != nil {
+		zerolog.Ctx(req.Context()).Error().Err(err).Msg("Failed to revoke session")
+		h.eh.HandleError(rw, req, err)
+
+		return
+	}
+
+	rw.WriteHeader(http.StatusNoContent)
+}
+
+// revokeSubjectSessions revokes all sessions of the subject referenced by the subject query parameter.
+func (h *handler) revokeSubjectSessions(rw http.ResponseWriter, req *http.Request) {
+	subjectID := req.URL.Query().Get("subject")
+	if len(subjectID) == 0 {
+		h.eh.HandleError(rw, req,
+			errorchain.NewWithMessage(heimdall.ErrArgument, "no subject query parameter present"))
+
+		return
+	}
+
+	if err := h.sm.RevokeAll(req.Context(), subjectID); err != nil {
+		zerolog.Ctx(req.Context()).Error().Err(err).Msg("Failed to revoke sessions")
+		h.eh.HandleError(rw, req, err)
+
+		return
+	}
+
+	rw.WriteHeader(http.StatusNoContent)
+}
+
 // jwks implements an endpoint returning JWKS objects according to
 // https://datatracker.ietf.org/doc/html/rfc7517
 func (h *handler) jwks(rw http.ResponseWriter, req *http.Request) {
@@ -22,6 +22,7 @@ import (
 	"go.uber.org/fx"
 
 	"github.com/dadrus/heimdall/internal/app"
+	"github.com/dadrus/heimdall/internal/cache"
 	"github.com/dadrus/heimdall/internal/handler/fxlcm"
 )
 
@@ -33,17 +34,24 @@ var Module = fx.Invoke( // nolint: gochecknoglobals
 	),
 )
 
-func newLifecycleManager(app app.Context) *fxlcm.LifecycleManager {
+func newLifecycleManager(app app.Context, cch cache.Cache) (*fxlcm.LifecycleManager, error) {
 	conf := app.Config()
 	logger := app.Logger()
 	cfg := conf.Management
 
+	srv, err := newService(conf, logger, app.KeyHolderRegistry(), app.SessionManager(), cch)
+	if err != nil {
+		logger.Error().Err(err).Msg("Failed creating management service")
+
+		return nil, err
+	}
+
 	return &fxlcm.LifecycleManager{
 		ServiceName:    "Management",
 		ServiceAddress: cfg.Address(),
-		Server:         newService(conf, logger, app.KeyHolderRegistry()),
+		Server:         srv,
 		Logger:         logger,
 		TLSConf:        cfg.TLS,
 		FileWatcher:    app.Watcher(),
-	}
+	}, nil
 }
@@ -27,16 +27,21 @@ import (
 	"github.com/rs/zerolog"
 	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
 
+	"github.com/dadrus/heimdall/internal/cache"
 	"github.com/dadrus/heimdall/internal/config"
 	"github.com/dadrus/heimdall/internal/handler/middleware/http/accesslog"
+	cachemiddleware "github.com/dadrus/heimdall/internal/handler/middleware/http/cache"
 	"github.com/dadrus/heimdall/internal/handler/middleware/http/dump"
 	errorhandler2 "github.com/dadrus/heimdall/internal/handler/middleware/http/errorhandler"
 	"github.com/dadrus/heimdall/internal/handler/middleware/http/logger"
 	"github.com/dadrus/heimdall/internal/handler/middleware/http/otelmetrics"
 	"github.com/dadrus/heimdall/internal/handler/middleware/http/passthrough"
 	"github.com/dadrus/heimdall/internal/handler/middleware/http/recovery"
+	"github.com/dadrus/heimdall/internal/heimdall"
 	"github.com/dadrus/heimdall/internal/keyholder"
+	"github.com/dadrus/heimdall/internal/session"
 	"github.com/dadrus/heimdall/internal/x"
+	"github.com/dadrus/heimdall/internal/x/errorchain"
 	"github.com/dadrus/heimdall/internal/x/httpx"
 	"github.com/dadrus/heimdall/internal/x/loggeradapter"
 )
@@ -45,8 +50,17 @@ func newService(
 	conf *config.Configuration,
 	log zerolog.Logger,
 	khr keyholder.Registry,
-) *http.Server {
+	sm session.Manager,
+	cch cache.Cache,
+) (*http.Server, error) {
 	cfg := conf.Management
+
+	if sm != nil && (cfg.TLS == nil || cfg.TLS.ClientAuth == nil) {
+		return nil, errorchain.NewWithMessage(heimdall.ErrConfiguration,
+			"session management requires client authentication to be configured for the management "+
+				"service to protect the session revocation endpoints")
+	}
+
 	eh := errorhandler2.New()
 	opFilter := func(req *http.Request) bool { return req.URL.Path != EndpointHealth }
 
@@ -83,7 +97,8 @@ func newService(
 			},
 			func() func(http.Handler) http.Handler { return passthrough.New },
 		),
-	).Then(newManagementHandler(khr, eh))
+		cachemiddleware.New(cch),
+	).Then(newManagementHandler(khr, sm, eh))
 
 	return &http.Server{
 		Handler:        hc,
@@ -92,5 +107,5 @@ func newService(
 		IdleTimeout:    cfg.Timeout.Idle,
 		MaxHeaderBytes: safecast.MustConvert[int](uint64(cfg.BufferLimit.Read)),
 		ErrorLog:       loggeradapter.NewStdLogger(log),
-	}
+	}, nil
 }
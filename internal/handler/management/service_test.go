@@ -21,22 +21,33 @@ import (
 	"crypto/ecdsa"
 	"crypto/elliptic"
 	"crypto/rand"
+	"crypto/tls"
 	"crypto/x509"
 	"crypto/x509/pkix"
 	"io"
+	"net"
 	"net/http"
+	"os"
+	"path/filepath"
 	"testing"
 	"time"
 
 	"github.com/go-jose/go-jose/v4"
 	"github.com/goccy/go-json"
 	"github.com/rs/zerolog/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 	"github.com/stretchr/testify/suite"
 
+	"github.com/dadrus/heimdall/internal/cache/noop"
 	"github.com/dadrus/heimdall/internal/config"
 	"github.com/dadrus/heimdall/internal/handler/listener"
+	"github.com/dadrus/heimdall/internal/heimdall"
 	"github.com/dadrus/heimdall/internal/keyholder/mocks"
 	"github.com/dadrus/heimdall/internal/keystore"
+	sessionmocks "github.com/dadrus/heimdall/internal/session/mocks"
+	"github.com/dadrus/heimdall/internal/x/errorchain"
 	"github.com/dadrus/heimdall/internal/x/pkix/pemx"
 	"github.com/dadrus/heimdall/internal/x/testsupport"
 )
@@ -48,10 +59,14 @@ type ServiceTestSuite struct {
 	ee1     *testsupport.EndEntity
 	ee2     *testsupport.EndEntity
 
+	keyStoreFile   string
+	trustStoreFile string
+
 	srv  *http.Server
 	ks   keystore.KeyStore
 	addr string
 	khr  *mocks.RegistryMock
+	sm   *sessionmocks.ManagerMock
 }
 
 func (suite *ServiceTestSuite) SetupSuite() {
@@ -87,7 +102,8 @@ func (suite *ServiceTestSuite) SetupSuite() {
 		}),
 		testsupport.WithValidity(time.Now(), time.Hour*24),
 		testsupport.WithSubjectPubKey(&ee1PrivKey.PublicKey, x509.ECDSAWithSHA384),
-		testsupport.WithKeyUsage(x509.KeyUsageDigitalSignature))
+		testsupport.WithKeyUsage(x509.KeyUsageDigitalSignature),
+		testsupport.WithIPAddresses([]net.IP{net.ParseIP("127.0.0.1")}))
 	suite.Require().NoError(err)
 	suite.ee1 = &testsupport.EndEntity{Certificate: ee1cert, PrivKey: ee1PrivKey}
 
@@ -106,6 +122,16 @@ func (suite *ServiceTestSuite) SetupSuite() {
 
 	suite.ks, err = keystore.NewKeyStoreFromPEMBytes(pemBytes, "")
 	suite.Require().NoError(err)
+
+	caPEMBytes, err := pemx.BuildPEM(pemx.WithX509Certificate(suite.rootCA1.Certificate))
+	suite.Require().NoError(err)
+
+	testDir := suite.T().TempDir()
+	suite.keyStoreFile = filepath.Join(testDir, "keystore.pem")
+	suite.trustStoreFile = filepath.Join(testDir, "truststore.pem")
+
+	suite.Require().NoError(os.WriteFile(suite.keyStoreFile, pemBytes, 0o600))
+	suite.Require().NoError(os.WriteFile(suite.trustStoreFile, caPEMBytes, 0o600))
 }
 
 func (suite *ServiceTestSuite) SetupTest() {
@@ -117,16 +143,25 @@ func (suite *ServiceTestSuite) SetupTest() {
 			Host: "127.0.0.1",
 			Port: port,
 			CORS: &config.CORS{},
+			TLS: &config.TLS{
+				KeyStore: config.KeyStore{Path: suite.keyStoreFile},
+				KeyID:    "foo",
+				ClientAuth: &config.ClientAuth{
+					TrustStore: config.TrustStore{Path: suite.trustStoreFile},
+				},
+			},
 		},
 		Metrics: config.MetricsConfig{Enabled: true},
 	}
 
 	listener, err := listener.New("tcp", "test", conf.Management.Address(), conf.Management.TLS, nil, nil)
 	suite.Require().NoError(err)
-	suite.addr = "http://" + listener.Addr().String()
+	suite.addr = "https://" + listener.Addr().String()
 
 	suite.khr = mocks.NewRegistryMock(suite.T())
-	suite.srv = newService(conf, log.Logger, suite.khr)
+	suite.sm = sessionmocks.NewManagerMock(suite.T())
+	suite.srv, err = newService(conf, log.Logger, suite.khr, suite.sm, &noop.Cache{})
+	suite.Require().NoError(err)
 
 	go func() {
 		suite.srv.Serve(listener)
@@ -143,6 +178,24 @@ func TestServiceTestSuite(t *testing.T) {
 	suite.Run(t, new(ServiceTestSuite))
 }
 
+// client creates a client trusting the certificate of the management service, which
+// authenticates using a client certificate, if withCertificate is set.
+func (suite *ServiceTestSuite) client(withCertificate bool) *http.Client {
+	pool := x509.NewCertPool()
+	pool.AddCert(suite.rootCA1.Certificate)
+
+	tlsConf := &tls.Config{RootCAs: pool, MinVersion: tls.VersionTLS13}
+
+	if withCertificate {
+		tlsConf.Certificates = []tls.Certificate{{
+			Certificate: [][]byte{suite.ee1.Certificate.Raw, suite.intCA1.Certificate.Raw},
+			PrivateKey:  suite.ee1.PrivKey,
+		}}
+	}
+
+	return &http.Client{Transport: &http.Transport{TLSClientConfig: tlsConf}}
+}
+
 func (suite *ServiceTestSuite) TestJWKSRequestWithoutEtagUsage() {
 	// GIVEN
 	keys := make([]jose.JSONWebKey, len(suite.ks.Entries()))
@@ -153,7 +206,7 @@ func (suite *ServiceTestSuite) TestJWKSRequestWithoutEtagUsage() {
 	suite.khr.EXPECT().Keys().Return(keys)
 
 	// WHEN
-	client := &http.Client{Transport: &http.Transport{}}
+	client := suite.client(false)
 	req, err := http.NewRequestWithContext(suite.T().Context(), http.MethodGet, suite.addr+"/.well-known/jwks", nil)
 	suite.Require().NoError(err)
 
@@ -216,7 +269,7 @@ func (suite *ServiceTestSuite) TestJWKSRequestWithEtagUsage() {
 
 	suite.khr.EXPECT().Keys().Return(keys)
 
-	client := &http.Client{Transport: &http.Transport{}}
+	client := suite.client(false)
 	req, err := http.NewRequestWithContext(suite.T().Context(), http.MethodGet, suite.addr+"/.well-known/jwks", nil)
 	suite.Require().NoError(err)
 
@@ -248,7 +301,7 @@ func (suite *ServiceTestSuite) TestJWKSRequestWithEtagUsage() {
 
 func (suite *ServiceTestSuite) TestHealthRequest() {
 	// GIVEN
-	client := &http.Client{Transport: &http.Transport{}}
+	client := suite.client(false)
 	req, err := http.NewRequestWithContext(suite.T().Context(), http.MethodGet, suite.addr+"/.well-known/health", nil)
 	suite.Require().NoError(err)
 
@@ -266,3 +319,155 @@ func (suite *ServiceTestSuite) TestHealthRequest() {
 
 	suite.JSONEq(`{ "status": "ok"}`, string(rawResp))
 }
+
+func (suite *ServiceTestSuite) TestRevokeSessionRequest() {
+	// GIVEN
+	suite.sm.EXPECT().Revoke(mock.Anything, "foo").Return(nil)
+
+	client := suite.client(true)
+	req, err := http.NewRequestWithContext(suite.T().Context(), http.MethodDelete, suite.addr+"/sessions/foo", nil)
+	suite.Require().NoError(err)
+
+	// WHEN
+	resp, err := client.Do(req)
+
+	// THEN
+	suite.Require().NoError(err)
+
+	defer resp.Body.Close()
+
+	suite.Equal(http.StatusNoContent, resp.StatusCode)
+}
+
+func (suite *ServiceTestSuite) TestRevokeSessionRequestFails() {
+	// GIVEN
+	suite.sm.EXPECT().Revoke(mock.Anything, "foo").
+		Return(errorchain.NewWithMessage(heimdall.ErrInternal, "test error"))
+
+	client := suite.client(true)
+	req, err := http.NewRequestWithContext(suite.T().Context(), http.MethodDelete, suite.addr+"/sessions/foo", nil)
+	suite.Require().NoError(err)
+
+	// WHEN
+	resp, err := client.Do(req)
+
+	// THEN
+	suite.Require().NoError(err)
+
+	defer resp.Body.Close()
+
+	suite.Equal(http.StatusInternalServerError, resp.StatusCode)
+}
+
+func (suite *ServiceTestSuite) TestRevokeSessionRequestWithUnsupportedMethod() {
+	// GIVEN
+	client := suite.client(true)
+	req, err := http.NewRequestWithContext(suite.T().Context(), http.MethodGet, suite.addr+"/sessions/foo", nil)
+	suite.Require().NoError(err)
+
+	// WHEN
+	resp, err := client.Do(req)
+
+	// THEN
+	suite.Require().NoError(err)
+
+	defer resp.Body.Close()
+
+	suite.Equal(http.StatusMethodNotAllowed, resp.StatusCode)
+}
+
+func (suite *ServiceTestSuite) TestRevokeSubjectSessionsRequest() {
+	// GIVEN
+	suite.sm.EXPECT().RevokeAll(mock.Anything, "foo@bar.baz").Return(nil)
+
+	client := suite.client(true)
+	req, err := http.NewRequestWithContext(suite.T().Context(), http.MethodDelete,
+		suite.addr+"/sessions?subject=foo%40bar.baz", nil)
+	suite.Require().NoError(err)
+
+	// WHEN
+	resp, err := client.Do(req)
+
+	// THEN
+	suite.Require().NoError(err)
+
+	defer resp.Body.Close()
+
+	suite.Equal(http.StatusNoContent, resp.StatusCode)
+}
+
+func (suite *ServiceTestSuite) TestRevokeSubjectSessionsRequestWithoutSubject() {
+	// GIVEN
+	client := suite.client(true)
+	req, err := http.NewRequestWithContext(suite.T().Context(), http.MethodDelete, suite.addr+"/sessions", nil)
+	suite.Require().NoError(err)
+
+	// WHEN
+	resp, err := client.Do(req)
+
+	// THEN
+	suite.Require().NoError(err)
+
+	defer resp.Body.Close()
+
+	suite.Equal(http.StatusBadRequest, resp.StatusCode)
+}
+
+func (suite *ServiceTestSuite) TestRevokeSessionRequestWithoutClientCertificate() {
+	for uc, url := range map[string]string{
+		"single session":      suite.addr + "/sessions/foo",
+		"sessions of subject": suite.addr + "/sessions?subject=foo",
+	} {
+		suite.Run(uc, func() {
+			// GIVEN
+			client := suite.client(false)
+			req, err := http.NewRequestWithContext(suite.T().Context(), http.MethodDelete, url, nil)
+			suite.Require().NoError(err)
+
+			// WHEN
+			resp, err := client.Do(req)
+
+			// THEN
+			suite.Require().NoError(err)
+
+			defer resp.Body.Close()
+
+			suite.Equal(http.StatusUnauthorized, resp.StatusCode)
+		})
+	}
+}
+
+func TestNewServiceWithSessionManagementRequiresClientAuthentication(t *testing.T) {
+	t.Parallel()
+
+	for uc, tlsConf := range map[string]*config.TLS{
+		"without TLS":                   nil,
+		"without client authentication": {KeyStore: config.KeyStore{Path: "/some/file.pem"}},
+	} {
+		t.Run(uc, func(t *testing.T) {
+			// GIVEN
+			conf := &config.Configuration{Management: config.ManagementConfig{TLS: tlsConf}}
+
+			// WHEN
+			srv, err := newService(conf, log.Logger, mocks.NewRegistryMock(t), sessionmocks.NewManagerMock(t),
+				&noop.Cache{})
+
+			// THEN
+			require.Error(t, err)
+			require.ErrorIs(t, err, heimdall.ErrConfiguration)
+			require.ErrorContains(t, err, "requires client authentication")
+			assert.Nil(t, srv)
+		})
+	}
+}
+
+func TestNewServiceWithoutSessionManagement(t *testing.T) {
+	t.Parallel()
+
+	// WHEN
+	srv, err := newService(&config.Configuration{}, log.Logger, mocks.NewRegistryMock(t), nil, &noop.Cache{})
+
+	// THEN
+	require.NoError(t, err)
+	assert.NotNil(t, srv)
+}
@@ -19,6 +19,7 @@ package errorhandler
 import (
 	"context"
 	"errors"
+	"net/http"
 
 	envoy_core "github.com/envoyproxy/go-control-plane/envoy/config/core/v3"
 	envoy_auth "github.com/envoyproxy/go-control-plane/envoy/service/auth/v3"
@@ -30,6 +31,7 @@ import (
 
 	"github.com/dadrus/heimdall/internal/accesscontext"
 	"github.com/dadrus/heimdall/internal/heimdall"
+	"github.com/dadrus/heimdall/internal/x"
 )
 
 func New(opts ...Option) grpc.UnaryServerInterceptor {
@@ -69,30 +71,83 @@ func (h *interceptor) intercept(
 		return h.preconditionError(err, h.verboseErrors, acceptType(req))
 	case errors.Is(err, heimdall.ErrNoRuleFound):
 		return h.noRuleError(err, h.verboseErrors, acceptType(req))
+	case errors.Is(err, &heimdall.TooManyRequestsError{}):
+		var tooManyRequestsError *heimdall.TooManyRequestsError
+
+		errors.As(err, &tooManyRequestsError)
+
+		res, err := h.tooManyRequestsError(err, h.verboseErrors, acceptType(req))
+		if resp, ok := res.(*envoy_auth.CheckResponse); ok {
+			deniedResponse := resp.GetDeniedResponse()
+
+			for name, values := range tooManyRequestsError.Headers() {
+				for _, value := range values {
+					deniedResponse.Headers = append(deniedResponse.Headers, &envoy_core.HeaderValueOption{
+						Header: &envoy_core.HeaderValue{Key: name, Value: value},
+					})
+				}
+			}
+		}
+
+		return res, err
 	case errors.Is(err, &heimdall.RedirectError{}):
 		var redirectError *heimdall.RedirectError
 
 		errors.As(err, &redirectError)
 
+		headers := []*envoy_core.HeaderValueOption{
+			{
+				Header: &envoy_core.HeaderValue{
+					Key:   "Location",
+					Value: redirectError.RedirectTo,
+				},
+			},
+		}
+
+		for _, cookie := range redirectError.Cookies {
+			headers = append(headers, &envoy_core.HeaderValueOption{
+				Header: &envoy_core.HeaderValue{Key: "Set-Cookie", Value: cookie.String()},
+			})
+		}
+
 		return &envoy_auth.CheckResponse{
 			Status: &status.Status{Code: int32(codes.FailedPrecondition)},
 			HttpResponse: &envoy_auth.CheckResponse_DeniedResponse{
 				DeniedResponse: &envoy_auth.DeniedHttpResponse{
 					//nolint:gosec
 					// no integer overflow during conversion possible
-					Status: &envoy_type.HttpStatus{Code: envoy_type.StatusCode(redirectError.Code)},
-					Headers: []*envoy_core.HeaderValueOption{
-						{
-							Header: &envoy_core.HeaderValue{
-								Key:   "Location",
-								Value: redirectError.RedirectTo,
-							},
-						},
-					},
+					Status:  &envoy_type.HttpStatus{Code: envoy_type.StatusCode(redirectError.Code)},
+					Headers: headers,
 				},
 			},
 		}, nil
+	case errors.Is(err, &heimdall.ResponseError{}):
+		var responseError *heimdall.ResponseError
+
+		errors.As(err, &responseError)
 
+		var headers []*envoy_core.HeaderValueOption
+
+		for name, values := range responseError.Headers {
+			for _, value := range values {
+				headers = append(headers, &envoy_core.HeaderValueOption{
+					Header: &envoy_core.HeaderValue{Key: name, Value: value},
+				})
+			}
+		}
+
+		return &envoy_auth.CheckResponse{
+			Status: &status.Status{Code: int32(grpcCodeFor(responseError.Code))},
+			HttpResponse: &envoy_auth.CheckResponse_DeniedResponse{
+				DeniedResponse: &envoy_auth.DeniedHttpResponse{
+					//nolint:gosec
+					// no integer overflow during conversion possible
+					Status:  &envoy_type.HttpStatus{Code: envoy_type.StatusCode(responseError.Code)},
+					Headers: headers,
+					Body:    string(responseError.Body),
+				},
+			},
+		}, nil
 	default:
 		logger := zerolog.Ctx(ctx)
 		logger.Error().Err(err).Msg("Internal error occurred")
@@ -101,6 +156,26 @@ func (h *interceptor) intercept(
 	}
 }
 
+// grpcCodeFor maps the given HTTP status code to the gRPC code used for the same error by default.
+func grpcCodeFor(httpCode int) codes.Code {
+	switch httpCode {
+	case http.StatusUnauthorized:
+		return codes.Unauthenticated
+	case http.StatusForbidden:
+		return codes.PermissionDenied
+	case http.StatusBadRequest:
+		return codes.InvalidArgument
+	case http.StatusNotFound:
+		return codes.NotFound
+	case http.StatusTooManyRequests:
+		return codes.ResourceExhausted
+	case http.StatusBadGateway, http.StatusGatewayTimeout:
+		return codes.DeadlineExceeded
+	default:
+		return x.IfThenElse(httpCode < http.StatusInternalServerError, codes.FailedPrecondition, codes.Internal)
+	}
+}
+
 func acceptType(req any) string {
 	if req, ok := req.(*envoy_auth.CheckRequest); ok {
 		return req.GetAttributes().GetRequest().GetHttp().GetHeaders()["accept"]
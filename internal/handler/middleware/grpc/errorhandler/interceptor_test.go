@@ -21,6 +21,7 @@ import (
 	"net"
 	"net/http"
 	"testing"
+	"time"
 
 	envoy_auth "github.com/envoyproxy/go-control-plane/envoy/service/auth/v3"
 	envoy_type "github.com/envoyproxy/go-control-plane/envoy/type/v3"
@@ -46,6 +47,7 @@ func TestErrorInterceptor(t *testing.T) {
 		expGRPCCode codes.Code
 		expHTTPCode envoy_type.StatusCode
 		expBody     string
+		expHeaders  map[string]string
 	}{
 		"no error": {
 			interceptor: New(),
@@ -166,6 +168,31 @@ func TestErrorInterceptor(t *testing.T) {
 			expHTTPCode: http.StatusNotFound,
 			expBody:     "<p>no rule found</p>",
 		},
+		"too many requests error default": {
+			interceptor: New(),
+			err:         &heimdall.TooManyRequestsError{Message: "too many requests", Limit: 10, RetryAfter: time.Second},
+			expGRPCCode: codes.ResourceExhausted,
+			expHTTPCode: http.StatusTooManyRequests,
+			expHeaders: map[string]string{
+				"Retry-After":         "1",
+				"Ratelimit-Limit":     "10",
+				"Ratelimit-Remaining": "0",
+				"Ratelimit-Reset":     "0",
+			},
+		},
+		"too many requests error overridden": {
+			interceptor: New(WithTooManyRequestsErrorCode(http.StatusServiceUnavailable)),
+			err:         &heimdall.TooManyRequestsError{Message: "too many requests", Limit: 10},
+			expGRPCCode: codes.ResourceExhausted,
+			expHTTPCode: http.StatusServiceUnavailable,
+		},
+		"too many requests error verbose": {
+			interceptor: New(WithVerboseErrors(true)),
+			err:         &heimdall.TooManyRequestsError{Message: "too many requests", Limit: 10},
+			expGRPCCode: codes.ResourceExhausted,
+			expHTTPCode: http.StatusTooManyRequests,
+			expBody:     "<p>too many requests</p>",
+		},
 		"redirect error": {
 			interceptor: New(),
 			err:         &heimdall.RedirectError{RedirectTo: "http://foo.local", Code: http.StatusFound},
@@ -178,6 +205,39 @@ func TestErrorInterceptor(t *testing.T) {
 			expGRPCCode: codes.FailedPrecondition,
 			expHTTPCode: http.StatusFound,
 		},
+		"redirect error with cookies": {
+			interceptor: New(),
+			err: &heimdall.RedirectError{
+				RedirectTo: "http://foo.local",
+				Code:       http.StatusFound,
+				Cookies:    []*http.Cookie{{Name: "foo", Value: "bar", Path: "/", HttpOnly: true}},
+			},
+			expGRPCCode: codes.FailedPrecondition,
+			expHTTPCode: http.StatusFound,
+			expHeaders: map[string]string{
+				"Location":   "http://foo.local",
+				"Set-Cookie": "foo=bar; Path=/; HttpOnly",
+			},
+		},
+		"response error": {
+			interceptor: New(),
+			err: &heimdall.ResponseError{
+				Message: "authentication error",
+				Code:    http.StatusUnauthorized,
+				Headers: http.Header{"Content-Type": []string{"application/problem+json"}},
+				Body:    []byte(`{"title":"Unauthorized","status":401}`),
+			},
+			expGRPCCode: codes.Unauthenticated,
+			expHTTPCode: http.StatusUnauthorized,
+			expHeaders:  map[string]string{"Content-Type": "application/problem+json"},
+			expBody:     `{"title":"Unauthorized","status":401}`,
+		},
+		"response error with custom code": {
+			interceptor: New(),
+			err:         &heimdall.ResponseError{Message: "internal error", Code: http.StatusServiceUnavailable},
+			expGRPCCode: codes.Internal,
+			expHTTPCode: http.StatusServiceUnavailable,
+		},
 		"internal error default": {
 			interceptor: New(),
 			err:         heimdall.ErrInternal,
@@ -258,6 +318,15 @@ func TestErrorInterceptor(t *testing.T) {
 				require.NotNil(t, deniedResp)
 				assert.Equal(t, tc.expHTTPCode, deniedResp.GetStatus().GetCode())
 				assert.Equal(t, tc.expBody, deniedResp.GetBody())
+
+				headers := make(map[string]string)
+				for _, hdr := range deniedResp.GetHeaders() {
+					headers[hdr.GetHeader().GetKey()] = hdr.GetHeader().GetValue()
+				}
+
+				for name, value := range tc.expHeaders {
+					assert.Equal(t, value, headers[name])
+				}
 			}
 		})
 	}
@@ -27,6 +27,7 @@ func defaultOptions() *opts {
 	defaults.onCommunicationError = errorWriter(defaults, http.StatusBadGateway)
 	defaults.onPreconditionError = errorWriter(defaults, http.StatusBadRequest)
 	defaults.onNoRuleError = errorWriter(defaults, http.StatusNotFound)
+	defaults.onTooManyRequestsError = errorWriter(defaults, http.StatusTooManyRequests)
 	defaults.onInternalError = errorWriter(defaults, http.StatusInternalServerError)
 
 	return defaults
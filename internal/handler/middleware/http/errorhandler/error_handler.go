@@ -60,15 +60,45 @@ func (h *errorHandler) HandleError(rw http.ResponseWriter, req *http.Request, er
 		h.onPreconditionError(rw, req, err)
 	case errors.Is(err, heimdall.ErrNoRuleFound):
 		h.onNoRuleError(rw, req, err)
+	case errors.Is(err, &heimdall.TooManyRequestsError{}):
+		var tooManyRequestsError *heimdall.TooManyRequestsError
+
+		errors.As(err, &tooManyRequestsError)
+
+		for name, values := range tooManyRequestsError.Headers() {
+			rw.Header()[name] = values
+		}
+
+		h.onTooManyRequestsError(rw, req, err)
 	case errors.Is(err, &heimdall.RedirectError{}):
 		var redirectError *heimdall.RedirectError
 
 		errors.As(err, &redirectError)
 
+		for _, cookie := range redirectError.Cookies {
+			http.SetCookie(rw, cookie)
+		}
+
 		rw.Header().Set("Location", redirectError.RedirectTo)
 		rw.WriteHeader(redirectError.Code)
 
 		return
+	case errors.Is(err, &heimdall.ResponseError{}):
+		var responseError *heimdall.ResponseError
+
+		errors.As(err, &responseError)
+
+		for name, values := range responseError.Headers {
+			rw.Header()[name] = values
+		}
+
+		rw.WriteHeader(responseError.Code)
+
+		if len(responseError.Body) != 0 {
+			// Cannot do anything else here if writing fails
+			//nolint:errcheck
+			rw.Write(responseError.Body)
+		}
 	default:
 		logger := zerolog.Ctx(ctx)
 		logger.Error().Err(err).Msg("Internal error occurred")
@@ -20,6 +20,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 
@@ -31,11 +32,12 @@ func TestHandlerHandle(t *testing.T) {
 	t.Parallel()
 
 	for uc, tc := range map[string]struct {
-		handler ErrorHandler
-		err     error
-		expCode int
-		accept  string
-		expBody string
+		handler    ErrorHandler
+		err        error
+		expCode    int
+		accept     string
+		expBody    string
+		expHeaders map[string]string
 	}{
 		"authentication error default": {
 			handler: New(),
@@ -136,6 +138,36 @@ func TestHandlerHandle(t *testing.T) {
 			expCode: http.StatusNotFound,
 			expBody: "<p>no rule found</p>",
 		},
+		"too many requests error default": {
+			handler: New(),
+			err:     &heimdall.TooManyRequestsError{Message: "too many requests", Limit: 10, RetryAfter: time.Second},
+			expCode: http.StatusTooManyRequests,
+			expHeaders: map[string]string{
+				"Retry-After":         "1",
+				"RateLimit-Limit":     "10",
+				"RateLimit-Remaining": "0",
+				"RateLimit-Reset":     "0",
+			},
+		},
+		"too many requests error overridden": {
+			handler: New(WithTooManyRequestsErrorCode(http.StatusServiceUnavailable)),
+			err: errorchain.New(&heimdall.TooManyRequestsError{
+				Message: "too many requests", Limit: 10, Reset: 1500 * time.Millisecond, RetryAfter: time.Second,
+			}),
+			expCode: http.StatusServiceUnavailable,
+			expHeaders: map[string]string{
+				"Retry-After":         "1",
+				"RateLimit-Limit":     "10",
+				"RateLimit-Remaining": "0",
+				"RateLimit-Reset":     "2",
+			},
+		},
+		"too many requests error verbose without mime type": {
+			handler: New(WithVerboseErrors(true)),
+			err:     errorchain.New(&heimdall.TooManyRequestsError{Message: "too many requests", Limit: 10}),
+			expCode: http.StatusTooManyRequests,
+			expBody: "<p>too many requests</p>",
+		},
 		"redirect error": {
 			handler: New(),
 			err:     &heimdall.RedirectError{RedirectTo: "http://foo.local", Code: http.StatusFound},
@@ -146,6 +178,36 @@ func TestHandlerHandle(t *testing.T) {
 			err:     &heimdall.RedirectError{RedirectTo: "http://foo.local", Code: http.StatusFound},
 			expCode: http.StatusFound,
 		},
+		"redirect error with cookies": {
+			handler: New(),
+			err: &heimdall.RedirectError{
+				RedirectTo: "http://foo.local",
+				Code:       http.StatusFound,
+				Cookies:    []*http.Cookie{{Name: "foo", Value: "bar", Path: "/", HttpOnly: true}},
+			},
+			expCode: http.StatusFound,
+			expHeaders: map[string]string{
+				"Location":   "http://foo.local",
+				"Set-Cookie": "foo=bar; Path=/; HttpOnly",
+			},
+		},
+		"response error": {
+			handler: New(WithVerboseErrors(true)),
+			err: &heimdall.ResponseError{
+				Message: "authentication error",
+				Code:    http.StatusUnauthorized,
+				Headers: http.Header{"Content-Type": []string{"application/problem+json"}},
+				Body:    []byte(`{"title":"Unauthorized","status":401}`),
+			},
+			expCode:    http.StatusUnauthorized,
+			expHeaders: map[string]string{"Content-Type": "application/problem+json"},
+			expBody:    `{"title":"Unauthorized","status":401}`,
+		},
+		"response error without body": {
+			handler: New(),
+			err:     &heimdall.ResponseError{Message: "authorization error", Code: http.StatusForbidden},
+			expCode: http.StatusForbidden,
+		},
 		"internal error default": {
 			handler: New(),
 			err:     errorchain.New(heimdall.ErrInternal),
@@ -176,6 +238,10 @@ func TestHandlerHandle(t *testing.T) {
 
 			assert.Equal(t, tc.expCode, recorder.Code)
 			assert.Equal(t, tc.expBody, recorder.Body.String())
+
+			for name, value := range tc.expHeaders {
+				assert.Equal(t, value, recorder.Header().Get(name))
+			}
 		})
 	}
 }
@@ -21,13 +21,14 @@ import (
 )
 
 type opts struct {
-	verboseErrors         bool
-	onAuthenticationError func(rw http.ResponseWriter, req *http.Request, err error)
-	onAuthorizationError  func(rw http.ResponseWriter, req *http.Request, err error)
-	onCommunicationError  func(rw http.ResponseWriter, req *http.Request, err error)
-	onPreconditionError   func(rw http.ResponseWriter, req *http.Request, err error)
-	onNoRuleError         func(rw http.ResponseWriter, req *http.Request, err error)
-	onInternalError       func(rw http.ResponseWriter, req *http.Request, err error)
+	verboseErrors          bool
+	onAuthenticationError  func(rw http.ResponseWriter, req *http.Request, err error)
+	onAuthorizationError   func(rw http.ResponseWriter, req *http.Request, err error)
+	onCommunicationError   func(rw http.ResponseWriter, req *http.Request, err error)
+	onPreconditionError    func(rw http.ResponseWriter, req *http.Request, err error)
+	onNoRuleError          func(rw http.ResponseWriter, req *http.Request, err error)
+	onTooManyRequestsError func(rw http.ResponseWriter, req *http.Request, err error)
+	onInternalError        func(rw http.ResponseWriter, req *http.Request, err error)
 }
 
 type Option func(*opts)
@@ -80,6 +81,14 @@ func WithNoRuleErrorCode(code int) Option {
 	}
 }
 
+func WithTooManyRequestsErrorCode(code int) Option {
+	return func(o *opts) {
+		if code != 0 {
+			o.onTooManyRequestsError = errorWriter(o, code)
+		}
+	}
+}
+
 func WithVerboseErrors(flag bool) Option {
 	return func(o *opts) {
 		o.verboseErrors = flag
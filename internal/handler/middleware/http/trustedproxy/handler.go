@@ -36,6 +36,11 @@ var untrustedHeader = []string{ //nolint:gochecknoglobals
 	"X-Forwarded-Uri",
 	"X-Forwarded-Path",
 	"X-Forwarded-Method",
+	"X-Forwarded-Client-Cert",
+	"X-Original-Method",
+	"X-Original-Uri",
+	"X-Original-Url",
+	"X-Real-Ip",
 }
 
 type ipHolder interface {
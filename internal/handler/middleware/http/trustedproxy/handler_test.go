@@ -43,13 +43,14 @@ func TestHandlerExecution(t *testing.T) {
 		t.Run(uc, func(t *testing.T) {
 			// GIVEN
 			send := http.Header{
-				"X-Forwarded-Proto": []string{"https"},
-				"X-Forwarded-Host":  []string{"foobar.com"},
-				"X-Forwarded-Path":  []string{"/test"},
-				"X-Forwarded-Uri":   []string{"/test?foo=bar"},
-				"X-Forwarded-For":   []string{"172.17.1.2"},
-				"Forwarded":         []string{"for=172.17.1.2;proto=https"},
-				"X-Foo-Bar":         []string{"foo"},
+				"X-Forwarded-Proto":       []string{"https"},
+				"X-Forwarded-Host":        []string{"foobar.com"},
+				"X-Forwarded-Path":        []string{"/test"},
+				"X-Forwarded-Uri":         []string{"/test?foo=bar"},
+				"X-Forwarded-For":         []string{"172.17.1.2"},
+				"Forwarded":               []string{"for=172.17.1.2;proto=https"},
+				"X-Foo-Bar":               []string{"foo"},
+				"X-Forwarded-Client-Cert": []string{"Hash=a3f1;Subject=\"CN=foo\""},
 			}
 
 			var received http.Header
@@ -84,6 +85,7 @@ func TestHandlerExecution(t *testing.T) {
 				require.Empty(t, received.Get("X-Forwarded-Uri"))
 				require.Empty(t, received.Get("X-Forwarded-For"))
 				require.Empty(t, received.Get("Forwarded"))
+				require.Empty(t, received.Get("X-Forwarded-Client-Cert"))
 				require.Equal(t, "foo", received.Get("X-Foo-Bar"))
 			} else {
 				require.Equal(t, send.Get("X-Forwarded-Proto"), received.Get("X-Forwarded-Proto"))
@@ -92,6 +94,7 @@ func TestHandlerExecution(t *testing.T) {
 				require.Equal(t, send.Get("X-Forwarded-Uri"), received.Get("X-Forwarded-Uri"))
 				require.Equal(t, send.Get("X-Forwarded-For"), received.Get("X-Forwarded-For"))
 				require.Equal(t, send.Get("Forwarded"), received.Get("Forwarded"))
+				require.Equal(t, send.Get("X-Forwarded-Client-Cert"), received.Get("X-Forwarded-Client-Cert"))
 				require.Equal(t, send.Get("X-Foo-Bar"), received.Get("X-Foo-Bar"))
 			}
 		})
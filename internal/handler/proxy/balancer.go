@@ -0,0 +1,483 @@
+// Copyright 2025 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package proxy
+
+import (
+	"context"
+	"errors"
+	"hash/fnv"
+	"net/http"
+	"net/url"
+	"slices"
+	"sort"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/goccy/go-json"
+	"github.com/rs/zerolog"
+
+	"github.com/dadrus/heimdall/internal/rules/config"
+	"github.com/dadrus/heimdall/internal/x/stringx"
+)
+
+const (
+	defaultHealthCheckInterval = 10 * time.Second
+	defaultHealthCheckTimeout  = 2 * time.Second
+	defaultHealthyThreshold    = 1
+	defaultUnhealthyThreshold  = 2
+	defaultConsecutiveFailures = 5
+	defaultEjectionDuration    = 30 * time.Second
+
+	// pools not used for that duration are removed together with their health checks. Pools
+	// of updated or deleted rules are removed immediately.
+	targetPoolIdleTimeout = 10 * time.Minute
+	// number of points on the hash ring per weight unit of a target.
+	virtualNodesPerWeight = 100
+)
+
+var errNoHealthyTarget = errors.New("no healthy target available")
+
+type target struct {
+	host   string
+	weight int
+
+	// number of requests currently forwarded to the target
+	active atomic.Int64
+	// set by active health checks
+	healthy atomic.Bool
+	// set by passive outlier detection
+	consecutiveFailures atomic.Int32
+	ejectedUntil        atomic.Int64
+
+	// used by the round-robin strategy only and guarded by the mutex of the pool
+	currentWeight int
+}
+
+func (t *target) available(now time.Time) bool {
+	return t.healthy.Load() && now.UnixNano() >= t.ejectedUntil.Load()
+}
+
+type ringEntry struct {
+	hash   uint64
+	target *target
+}
+
+type targetPool struct {
+	targets  []*target
+	strategy string
+	ring     []ringEntry
+	outliers *config.OutlierDetection
+	health   *config.HealthCheck
+
+	mut      sync.Mutex
+	lastUsed atomic.Int64
+	stop     chan struct{}
+	stopOnce sync.Once
+}
+
+func newTargetPool(targets []config.Target, lb *config.LoadBalancing) *targetPool {
+	pool := &targetPool{
+		targets:  make([]*target, len(targets)),
+		strategy: config.LoadBalancingRoundRobin,
+		stop:     make(chan struct{}),
+	}
+
+	for idx, tgt := range targets {
+		pool.targets[idx] = &target{host: tgt.Host, weight: max(tgt.Weight, 1)}
+		pool.targets[idx].healthy.Store(true)
+	}
+
+	if lb != nil {
+		if len(lb.Strategy) != 0 {
+			pool.strategy = lb.Strategy
+		}
+
+		if lb.OutlierDetection != nil {
+			pool.outliers = &config.OutlierDetection{
+				ConsecutiveFailures: withDefault(lb.OutlierDetection.ConsecutiveFailures, defaultConsecutiveFailures),
+				EjectionDuration:    withDefault(lb.OutlierDetection.EjectionDuration, defaultEjectionDuration),
+			}
+		}
+
+		if lb.HealthCheck != nil {
+			pool.health = &config.HealthCheck{
+				Path:               lb.HealthCheck.Path,
+				Interval:           withDefault(lb.HealthCheck.Interval, defaultHealthCheckInterval),
+				Timeout:            withDefault(lb.HealthCheck.Timeout, defaultHealthCheckTimeout),
+				HealthyThreshold:   withDefault(lb.HealthCheck.HealthyThreshold, defaultHealthyThreshold),
+				UnhealthyThreshold: withDefault(lb.HealthCheck.UnhealthyThreshold, defaultUnhealthyThreshold),
+			}
+		}
+	}
+
+	if pool.strategy == config.LoadBalancingConsistentHash {
+		for _, tgt := range pool.targets {
+			for idx := range tgt.weight * virtualNodesPerWeight {
+				pool.ring = append(pool.ring, ringEntry{hash: hashOf(strconv.Itoa(idx) + "#" + tgt.host), target: tgt})
+			}
+		}
+
+		slices.SortFunc(pool.ring, func(a, b ringEntry) int {
+			switch {
+			case a.hash < b.hash:
+				return -1
+			case a.hash > b.hash:
+				return 1
+			default:
+				return 0
+			}
+		})
+	}
+
+	pool.lastUsed.Store(time.Now().UnixNano())
+
+	return pool
+}
+
+// pick selects the target to forward the request to. The key is used by the consistent_hash
+// strategy only.
+func (p *targetPool) pick(key string) (*target, error) {
+	now := time.Now()
+
+	p.lastUsed.Store(now.UnixNano())
+
+	var tgt *target
+
+	switch p.strategy {
+	case config.LoadBalancingLeastConnections:
+		tgt = p.leastConnections(now)
+	case config.LoadBalancingConsistentHash:
+		tgt = p.consistentHash(key, now)
+	default:
+		tgt = p.roundRobin(now)
+	}
+
+	if tgt == nil {
+		return nil, errNoHealthyTarget
+	}
+
+	return tgt, nil
+}
+
+// roundRobin implements the smooth weighted round-robin algorithm, which distributes the requests
+// according to the weights of the targets without sending bursts of requests to the same one.
+func (p *targetPool) roundRobin(now time.Time) *target {
+	p.mut.Lock()
+	defer p.mut.Unlock()
+
+	var (
+		selected    *target
+		totalWeight int
+	)
+
+	for _, tgt := range p.targets {
+		if !tgt.available(now) {
+			continue
+		}
+
+		tgt.currentWeight += tgt.weight
+		totalWeight += tgt.weight
+
+		if selected == nil || tgt.currentWeight > selected.currentWeight {
+			selected = tgt
+		}
+	}
+
+	if selected != nil {
+		selected.currentWeight -= totalWeight
+	}
+
+	return selected
+}
+
+func (p *targetPool) leastConnections(now time.Time) *target {
+	var selected *target
+
+	for _, tgt := range p.targets {
+		if !tgt.available(now) {
+			continue
+		}
+
+		// compares active/weight ratios without the need for floating point arithmetic
+		if selected == nil ||
+			tgt.active.Load()*int64(selected.weight) < selected.active.Load()*int64(tgt.weight) {
+			selected = tgt
+		}
+	}
+
+	return selected
+}
+
+// consistentHash selects the first available target following the position of the key on the hash
+// ring. That way, only the requests of the keys mapped to an unavailable target are redistributed.
+func (p *targetPool) consistentHash(key string, now time.Time) *target {
+	hash := hashOf(key)
+	start := sort.Search(len(p.ring), func(idx int) bool { return p.ring[idx].hash >= hash })
+
+	for idx := range len(p.ring) {
+		if entry := p.ring[(start+idx)%len(p.ring)]; entry.target.available(now) {
+			return entry.target
+		}
+	}
+
+	return nil
+}
+
+// report implements the passive outlier detection. Targets, which failed to respond to the
+// configured number of consecutive requests, are not selected for the configured duration.
+func (p *targetPool) report(tgt *target, failed bool) {
+	if p.outliers == nil {
+		return
+	}
+
+	if !failed {
+		tgt.consecutiveFailures.Store(0)
+
+		return
+	}
+
+	if int(tgt.consecutiveFailures.Add(1)) >= p.outliers.ConsecutiveFailures {
+		tgt.consecutiveFailures.Store(0)
+		tgt.ejectedUntil.Store(time.Now().Add(p.outliers.EjectionDuration).UnixNano())
+	}
+}
+
+// checkHealth implements the active health checking of the targets until the pool is closed.
+func (p *targetPool) checkHealth(client *http.Client, scheme string, logger zerolog.Logger) {
+	successes := make([]int, len(p.targets))
+	failures := make([]int, len(p.targets))
+
+	ticker := time.NewTicker(p.health.Interval)
+	defer ticker.Stop()
+
+	for {
+		for idx, tgt := range p.targets {
+			err := p.probe(client, &url.URL{Scheme: scheme, Host: tgt.host, Path: p.health.Path})
+			if err == nil {
+				successes[idx], failures[idx] = successes[idx]+1, 0
+			} else {
+				successes[idx], failures[idx] = 0, failures[idx]+1
+			}
+
+			switch {
+			case !tgt.healthy.Load() && successes[idx] >= p.health.HealthyThreshold:
+				logger.Info().Str("_target", tgt.host).Msg("Upstream target became healthy")
+				tgt.healthy.Store(true)
+			case tgt.healthy.Load() && failures[idx] >= p.health.UnhealthyThreshold:
+				logger.Warn().Err(err).Str("_target", tgt.host).Msg("Upstream target became unhealthy")
+				tgt.healthy.Store(false)
+			}
+		}
+
+		select {
+		case <-p.stop:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (p *targetPool) probe(client *http.Client, endpoint *url.URL) error {
+	ctx, cancel := context.WithTimeout(context.Background(), p.health.Timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint.String(), nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusBadRequest {
+		return errors.New("unexpected response code: " + strconv.Itoa(resp.StatusCode)) //nolint:err113
+	}
+
+	return nil
+}
+
+func (p *targetPool) idle(now time.Time) bool {
+	return now.Sub(time.Unix(0, p.lastUsed.Load())) > targetPoolIdleTimeout
+}
+
+func (p *targetPool) close() { p.stopOnce.Do(func() { close(p.stop) }) }
+
+// targetPools holds the state of the load balanced backends. It is shared by all requests to be
+// able to track the health and the load of the targets.
+type targetPools struct {
+	logger zerolog.Logger
+
+	mut       sync.Mutex
+	pools     map[targetPoolKey]*targetPool
+	lastSweep time.Time
+	closed    bool
+}
+
+// targetPoolKey binds a pool to the rule, the backend configuration belongs to. That way, the pool
+// of a rule is neither shared with nor taken over by other rules, which might e.g. make use of
+// a different tls configuration.
+type targetPoolKey struct {
+	released <-chan struct{}
+	settings string
+}
+
+func newTargetPools(logger zerolog.Logger) *targetPools {
+	return &targetPools{
+		logger:    logger,
+		pools:     make(map[targetPoolKey]*targetPool),
+		lastSweep: time.Now(),
+	}
+}
+
+// get returns the pool for the given configuration. The pool is created and its health checks,
+// which make use of the given transport, are started on first use. These are stopped as soon as
+// the released channel of the rule is closed or the pools are closed.
+func (tp *targetPools) get(
+	scheme string,
+	targets []config.Target,
+	lb *config.LoadBalancing,
+	tc *config.Transport,
+	transport http.RoundTripper,
+	released <-chan struct{},
+) *targetPool {
+	key := targetPoolKey{released: released, settings: poolKey(scheme, targets, lb, tc)}
+	now := time.Now()
+
+	tp.mut.Lock()
+	defer tp.mut.Unlock()
+
+	if now.Sub(tp.lastSweep) > targetPoolIdleTimeout {
+		tp.lastSweep = now
+
+		for key, pool := range tp.pools {
+			if pool.idle(now) {
+				pool.close()
+				delete(tp.pools, key)
+			}
+		}
+	}
+
+	if pool, ok := tp.pools[key]; ok {
+		return pool
+	}
+
+	pool := newTargetPool(targets, lb)
+	if tp.closed {
+		// the proxy is shutting down. The pool is used for the current request only.
+		return pool
+	}
+
+	tp.pools[key] = pool
+
+	if released != nil {
+		go tp.removeOnRelease(key, pool)
+	}
+
+	if pool.health != nil {
+		client := &http.Client{
+			Transport: transport,
+			// the response of the target itself is of interest
+			CheckRedirect: func(_ *http.Request, _ []*http.Request) error { return http.ErrUseLastResponse },
+		}
+
+		go pool.checkHealth(client, scheme, tp.logger)
+	}
+
+	return pool
+}
+
+func (tp *targetPools) removeOnRelease(key targetPoolKey, pool *targetPool) {
+	select {
+	case <-pool.stop:
+		return
+	case <-key.released:
+	}
+
+	tp.mut.Lock()
+	defer tp.mut.Unlock()
+
+	if tp.pools[key] == pool {
+		delete(tp.pools, key)
+	}
+
+	pool.close()
+}
+
+// Close stops the health checks of all pools. It is called on shutdown of the proxy.
+func (tp *targetPools) Close() {
+	tp.mut.Lock()
+	defer tp.mut.Unlock()
+
+	tp.closed = true
+
+	for key, pool := range tp.pools {
+		pool.close()
+		delete(tp.pools, key)
+	}
+}
+
+func poolKey(scheme string, targets []config.Target, lb *config.LoadBalancing, tc *config.Transport) string {
+	// marshalling of these types cannot fail
+	key, _ := json.Marshal(struct {
+		Scheme        string                `json:"scheme"`
+		Targets       []config.Target       `json:"targets"`
+		LoadBalancing *config.LoadBalancing `json:"load_balancing"`
+		Transport     *config.Transport     `json:"transport"`
+	}{Scheme: scheme, Targets: targets, LoadBalancing: lb, Transport: tc})
+
+	return stringx.ToString(key)
+}
+
+func hashOf(value string) uint64 {
+	hash := fnv.New64a()
+	hash.Write(stringx.ToBytes(value))
+
+	return hash.Sum64()
+}
+
+func withDefault[T int | time.Duration](value, defaultValue T) T {
+	if value == 0 {
+		return defaultValue
+	}
+
+	return value
+}
+
+// outlierDetector reports the outcome of the requests forwarded to the selected target
+// to its pool.
+type outlierDetector struct {
+	next   http.RoundTripper
+	pool   *targetPool
+	target *target
+}
+
+func (d *outlierDetector) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := d.next.RoundTrip(req)
+
+	// requests canceled by the client do not say anything about the health of the target
+	if req.Context().Err() == nil {
+		d.pool.report(d.target, err != nil || resp.StatusCode >= http.StatusInternalServerError)
+	}
+
+	return resp, err
+}
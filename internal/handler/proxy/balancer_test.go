@@ -0,0 +1,383 @@
+// Copyright 2025 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package proxy
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dadrus/heimdall/internal/rules/config"
+)
+
+func pickHosts(t *testing.T, pool *targetPool, count int, key func(idx int) string) map[string]int {
+	t.Helper()
+
+	hosts := make(map[string]int)
+
+	for idx := range count {
+		tgt, err := pool.pick(key(idx))
+		require.NoError(t, err)
+
+		hosts[tgt.host]++
+	}
+
+	return hosts
+}
+
+func TestTargetPoolRoundRobin(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	pool := newTargetPool([]config.Target{
+		{Host: "a:80", Weight: 3},
+		{Host: "b:80"},
+		{Host: "c:80", Weight: 1},
+	}, nil)
+
+	// WHEN
+	hosts := pickHosts(t, pool, 50, func(_ int) string { return "" })
+
+	// THEN
+	assert.Equal(t, map[string]int{"a:80": 30, "b:80": 10, "c:80": 10}, hosts)
+
+	// WHEN
+	pool.targets[0].healthy.Store(false)
+	hosts = pickHosts(t, pool, 10, func(_ int) string { return "" })
+
+	// THEN
+	assert.Equal(t, map[string]int{"b:80": 5, "c:80": 5}, hosts)
+}
+
+func TestTargetPoolLeastConnections(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	pool := newTargetPool(
+		[]config.Target{{Host: "a:80", Weight: 2}, {Host: "b:80"}},
+		&config.LoadBalancing{Strategy: config.LoadBalancingLeastConnections},
+	)
+
+	// WHEN
+	for range 3 {
+		tgt, err := pool.pick("")
+		require.NoError(t, err)
+
+		tgt.active.Add(1)
+	}
+
+	// THEN
+	// active connections are distributed according to the weights
+	assert.Equal(t, int64(2), pool.targets[0].active.Load())
+	assert.Equal(t, int64(1), pool.targets[1].active.Load())
+
+	// WHEN
+	pool.targets[0].active.Add(-2)
+
+	tgt, err := pool.pick("")
+
+	// THEN
+	require.NoError(t, err)
+	assert.Equal(t, "a:80", tgt.host)
+}
+
+func TestTargetPoolConsistentHash(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	pool := newTargetPool(
+		[]config.Target{{Host: "a:80"}, {Host: "b:80"}, {Host: "c:80"}},
+		&config.LoadBalancing{Strategy: config.LoadBalancingConsistentHash, HashKey: "{{ .Subject.ID }}"},
+	)
+
+	selected := make(map[string]string)
+
+	// WHEN
+	for idx := range 300 {
+		key := "subject-" + strconv.Itoa(idx)
+
+		tgt, err := pool.pick(key)
+		require.NoError(t, err)
+
+		selected[key] = tgt.host
+	}
+
+	// THEN
+	hosts := make(map[string]int)
+	for _, host := range selected {
+		hosts[host]++
+	}
+
+	// all targets are used
+	require.Len(t, hosts, 3)
+
+	// the same key is always mapped to the same target
+	for key, host := range selected {
+		tgt, err := pool.pick(key)
+		require.NoError(t, err)
+		assert.Equal(t, host, tgt.host)
+	}
+
+	// WHEN
+	pool.targets[1].ejectedUntil.Store(time.Now().Add(time.Minute).UnixNano())
+
+	// THEN
+	// only the keys of the unavailable target are remapped
+	for key, host := range selected {
+		tgt, err := pool.pick(key)
+		require.NoError(t, err)
+
+		if host == "b:80" {
+			assert.NotEqual(t, host, tgt.host)
+		} else {
+			assert.Equal(t, host, tgt.host)
+		}
+	}
+}
+
+func TestTargetPoolWithoutAvailableTargets(t *testing.T) {
+	t.Parallel()
+
+	for _, strategy := range []string{
+		config.LoadBalancingRoundRobin,
+		config.LoadBalancingLeastConnections,
+		config.LoadBalancingConsistentHash,
+	} {
+		t.Run(strategy, func(t *testing.T) {
+			// GIVEN
+			pool := newTargetPool(
+				[]config.Target{{Host: "a:80"}, {Host: "b:80"}},
+				&config.LoadBalancing{Strategy: strategy},
+			)
+
+			for _, tgt := range pool.targets {
+				tgt.healthy.Store(false)
+			}
+
+			// WHEN
+			tgt, err := pool.pick("foo")
+
+			// THEN
+			require.ErrorIs(t, err, errNoHealthyTarget)
+			assert.Nil(t, tgt)
+		})
+	}
+}
+
+func TestTargetPoolOutlierDetection(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	pool := newTargetPool(
+		[]config.Target{{Host: "a:80"}, {Host: "b:80"}},
+		&config.LoadBalancing{OutlierDetection: &config.OutlierDetection{ConsecutiveFailures: 2}},
+	)
+	tgt := pool.targets[0]
+
+	// WHEN
+	pool.report(tgt, true)
+	pool.report(tgt, false)
+	pool.report(tgt, true)
+
+	// THEN
+	// failures must be consecutive
+	assert.True(t, tgt.available(time.Now()))
+
+	// WHEN
+	pool.report(tgt, true)
+
+	// THEN
+	assert.False(t, tgt.available(time.Now()))
+	assert.True(t, tgt.available(time.Now().Add(defaultEjectionDuration)))
+
+	hosts := pickHosts(t, pool, 4, func(_ int) string { return "" })
+	assert.Equal(t, map[string]int{"b:80": 4}, hosts)
+}
+
+func TestTargetPoolHealthCheck(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	var healthy atomic.Bool
+
+	healthy.Store(true)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		assert.Equal(t, "/health", req.URL.Path)
+
+		if healthy.Load() {
+			rw.WriteHeader(http.StatusOK)
+		} else {
+			rw.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer srv.Close()
+
+	srvURL, err := url.Parse(srv.URL)
+	require.NoError(t, err)
+
+	pools := newTargetPools(zerolog.Nop())
+	pool := pools.get("http", []config.Target{{Host: srvURL.Host}}, &config.LoadBalancing{
+		HealthCheck: &config.HealthCheck{
+			Path:               "/health",
+			Interval:           20 * time.Millisecond,
+			UnhealthyThreshold: 2,
+		},
+	}, nil, http.DefaultTransport, nil)
+	defer pool.close()
+
+	tgt := pool.targets[0]
+
+	// WHEN
+	healthy.Store(false)
+
+	// THEN
+	assert.Eventually(t, func() bool { return !tgt.healthy.Load() }, time.Second, 10*time.Millisecond)
+
+	// WHEN
+	healthy.Store(true)
+
+	// THEN
+	assert.Eventually(t, func() bool { return tgt.healthy.Load() }, time.Second, 10*time.Millisecond)
+}
+
+func TestTargetPoolsGet(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	pools := newTargetPools(zerolog.Nop())
+	targets := []config.Target{{Host: "a:80"}, {Host: "b:80"}}
+	lb := &config.LoadBalancing{Strategy: config.LoadBalancingLeastConnections}
+	tc := &config.Transport{EnforceHTTP2: true}
+
+	// WHEN
+	pool1 := pools.get("http", targets, nil, nil, http.DefaultTransport, nil)
+	pool2 := pools.get("http", targets, nil, nil, http.DefaultTransport, nil)
+	pool3 := pools.get("https", targets, nil, nil, http.DefaultTransport, nil)
+	pool4 := pools.get("http", targets, lb, nil, http.DefaultTransport, nil)
+	pool5 := pools.get("http", targets, nil, tc, http.DefaultTransport, nil)
+
+	// THEN
+	assert.Same(t, pool1, pool2)
+	assert.NotSame(t, pool1, pool3)
+	assert.NotSame(t, pool1, pool4)
+	assert.NotSame(t, pool1, pool5)
+
+	// WHEN
+	pool1.lastUsed.Store(time.Now().Add(-2 * targetPoolIdleTimeout).UnixNano())
+	pools.lastSweep = time.Now().Add(-2 * targetPoolIdleTimeout)
+
+	pool6 := pools.get("https", targets, nil, nil, http.DefaultTransport, nil)
+
+	// THEN
+	assert.Same(t, pool3, pool6)
+	assert.NotContains(t, pools.pools, targetPoolKey{settings: poolKey("http", targets, nil, nil)})
+	assert.Len(t, pools.pools, 3)
+
+	select {
+	case <-pool1.stop:
+	default:
+		t.Error("idle pool not closed")
+	}
+}
+
+func TestTargetPoolsGetWithReleasedRule(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	pools := newTargetPools(zerolog.Nop())
+	defer pools.Close()
+
+	targets := []config.Target{{Host: "a:80"}, {Host: "b:80"}}
+	lb := &config.LoadBalancing{HealthCheck: &config.HealthCheck{Interval: time.Hour}}
+	released1 := make(chan struct{})
+	released2 := make(chan struct{})
+
+	defer close(released2)
+
+	pool1 := pools.get("http", targets, lb, nil, http.DefaultTransport, released1)
+	pool2 := pools.get("http", targets, lb, nil, http.DefaultTransport, released2)
+	pool3 := pools.get("http", targets, lb, nil, http.DefaultTransport, released1)
+
+	require.NotSame(t, pool1, pool2)
+	require.Same(t, pool1, pool3)
+
+	// WHEN
+	close(released1)
+
+	// THEN
+	assert.Eventually(t, func() bool {
+		select {
+		case <-pool1.stop:
+			return true
+		default:
+			return false
+		}
+	}, time.Second, 10*time.Millisecond)
+
+	pools.mut.Lock()
+	assert.Len(t, pools.pools, 1)
+	assert.Contains(t, pools.pools, targetPoolKey{released: released2, settings: poolKey("http", targets, lb, nil)})
+	pools.mut.Unlock()
+
+	select {
+	case <-pool2.stop:
+		t.Error("pool of a not released rule closed")
+	default:
+	}
+}
+
+func TestTargetPoolsClose(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	pools := newTargetPools(zerolog.Nop())
+	targets := []config.Target{{Host: "a:80"}, {Host: "b:80"}}
+	lb := &config.LoadBalancing{HealthCheck: &config.HealthCheck{Interval: time.Hour}}
+
+	pool1 := pools.get("http", targets, lb, nil, http.DefaultTransport, nil)
+	pool2 := pools.get("https", targets, lb, nil, http.DefaultTransport, make(chan struct{}))
+
+	// WHEN
+	pools.Close()
+
+	// THEN
+	assert.Empty(t, pools.pools)
+
+	for _, pool := range []*targetPool{pool1, pool2} {
+		select {
+		case <-pool.stop:
+		default:
+			t.Error("pool not closed")
+		}
+	}
+
+	// WHEN
+	pool3 := pools.get("http", targets, lb, nil, http.DefaultTransport, nil)
+
+	// THEN
+	assert.NotSame(t, pool1, pool3)
+	assert.Empty(t, pools.pools)
+}
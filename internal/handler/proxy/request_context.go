@@ -32,6 +32,7 @@ import (
 	"github.com/dadrus/heimdall/internal/config"
 	"github.com/dadrus/heimdall/internal/handler/requestcontext"
 	"github.com/dadrus/heimdall/internal/heimdall"
+	config2 "github.com/dadrus/heimdall/internal/rules/config"
 	"github.com/dadrus/heimdall/internal/rules/rule"
 	"github.com/dadrus/heimdall/internal/x"
 	"github.com/dadrus/heimdall/internal/x/errorchain"
@@ -41,19 +42,20 @@ import (
 type requestContext struct {
 	*requestcontext.RequestContext
 
-	rw        http.ResponseWriter
-	req       *http.Request
-	transport *http.Transport
+	rw         http.ResponseWriter
+	req        *http.Request
+	transports *upstreamTransports
+	pools      *targetPools
 }
 
 func newContextFactory(
 	cfg config.ServeConfig,
 	tlsCfg *tls.Config,
+	pools *targetPools,
 ) requestcontext.ContextFactory {
+	// used for upstreams without own transport or tls settings. tlsCfg is nil, except
+	// in tests, so that the system trust store is used to verify the upstream certificates
 	transport := &http.Transport{
-		// tlsClientConfig used for test purposes only
-		// must be removed as soon as tls configuration
-		// is possible per upstream
 		Proxy: http.ProxyFromEnvironment,
 		DialContext: (&net.Dialer{
 			Timeout:   30 * time.Second, //nolint:mnd
@@ -70,10 +72,13 @@ func newContextFactory(
 		TLSClientConfig:       tlsCfg,
 	}
 
+	transports := newUpstreamTransports(transport)
+
 	return requestcontext.FactoryFunc(func(rw http.ResponseWriter, req *http.Request) requestcontext.Context {
 		return &requestContext{
 			RequestContext: requestcontext.New(req),
-			transport:      transport,
+			transports:     transports,
+			pools:          pools,
 			rw:             rw,
 			req:            req,
 		}
@@ -91,34 +96,220 @@ func (r *requestContext) Finalize(upstream rule.Backend) error {
 		return errorchain.NewWithMessage(heimdall.ErrConfiguration, "No upstream reference defined")
 	}
 
+	transport := r.transports.get(upstream.Transport(), upstream.TLSConfig())
+
+	targetURL := upstream.URL()
+	rw := r.rw
+
+	if targets := upstream.Targets(); len(targets) != 0 {
+		pool := r.pools.get(
+			targetURL.Scheme, targets, upstream.LoadBalancing(), upstream.Transport(), transport, upstream.Released(),
+		)
+
+		key, err := upstream.HashKey()
+		if err != nil {
+			return errorchain.NewWithMessage(heimdall.ErrInternal, "failed to render load balancing hash key").
+				CausedBy(err)
+		}
+
+		tgt, err := pool.pick(key)
+		if err != nil {
+			return errorchain.NewWithMessage(heimdall.ErrCommunication, "Failed to select upstream target").
+				CausedBy(err)
+		}
+
+		tgt.active.Add(1)
+		defer tgt.active.Add(-1)
+
+		targetURL = &url.URL{
+			Scheme:   targetURL.Scheme,
+			Host:     tgt.host,
+			Path:     targetURL.Path,
+			RawPath:  targetURL.RawPath,
+			RawQuery: targetURL.RawQuery,
+		}
+		transport = &outlierDetector{next: transport, pool: pool, target: tgt}
+	}
+
+	if protocol := upgradeType(r.req.Header); len(protocol) != 0 {
+		var err error
+
+		if rw, err = r.interceptUpgrade(protocol, upstream); err != nil {
+			return err
+		}
+	}
+
 	logger.Info().
 		Str("_method", r.Request().Method).
-		Str("_upstream", upstream.URL().String()).
+		Str("_upstream", targetURL.String()).
 		Msg("Forwarding request")
 
 	errHolder := struct{ err error }{}
 
 	proxy := &httputil.ReverseProxy{
 		ErrorHandler: func(_ http.ResponseWriter, _ *http.Request, err error) {
+			if errHolder.err != nil {
+				// set by the response pipeline
+				return
+			}
+
 			logger.Error().Err(err).Msg("Proxying error")
 
 			errHolder.err = errorchain.NewWithMessage(heimdall.ErrCommunication, "Failed to proxy request").
 				CausedBy(err)
 		},
-		Rewrite: r.rewriteRequest(upstream.URL(), upstream.ForwardHostHeader()),
+		ModifyResponse: func(resp *http.Response) error {
+			if err := r.handleResponse(upstream, resp); err != nil {
+				errHolder.err = err
+
+				return err
+			}
+
+			return nil
+		},
+		Rewrite: r.rewriteRequest(targetURL, upstream.ForwardHostHeader()),
 		Transport: otelhttp.NewTransport(
-			httpx.NewTraceRoundTripper(r.transport),
+			httpx.NewTraceRoundTripper(transport),
 			otelhttp.WithSpanNameFormatter(func(_ string, r *http.Request) string {
 				return fmt.Sprintf("%s %s %s @%s", r.Proto, r.Method, r.URL.Path, r.URL.Host)
 			})),
 	}
 
-	proxy.ServeHTTP(r.rw, r.req)
+	proxy.ServeHTTP(rw, r.req)
 
 	// set in the proxy error handler above
 	return errHolder.err
 }
 
+// interceptUpgrade verifies the upgrade to the given protocol is allowed by the rule and returns the
+// response writer to be used to supervise the upgraded connection.
+func (r *requestContext) interceptUpgrade(protocol string, upstream rule.Backend) (http.ResponseWriter, error) {
+	settings := upstream.Upgrade()
+	if settings == nil {
+		// any upgrade is allowed by default and the upgraded connection is not supervised
+		return r.rw, nil
+	}
+
+	if !settings.Allows(protocol) {
+		return nil, errorchain.NewWithMessagef(heimdall.ErrArgument, "upgrade to '%s' is not allowed", protocol)
+	}
+
+	// nil if the framing is unknown. The connection is supervised nevertheless, but cannot be closed cleanly
+	framing := upgradeProtocols[protocol]
+
+	return &hijackInterceptor{
+		ResponseWriter: r.rw,
+		onHijack: func(conn net.Conn) net.Conn {
+			uc := newUpgradedConn(conn, framing)
+
+			go r.superviseUpgradedConn(uc, upstream, settings, framing)
+
+			return uc
+		},
+	}, nil
+}
+
+// superviseUpgradedConn closes the given connection if it has been idle for too long, exceeded
+// its max lifetime, or if the subject, which has been authorized to establish it, is not valid
+// anymore.
+func (r *requestContext) superviseUpgradedConn(
+	conn *upgradedConn,
+	upstream rule.Backend,
+	settings *config2.Upgrade,
+	protocol *upgradeProtocol,
+) {
+	logger := zerolog.Ctx(r.Context())
+
+	var (
+		idle         <-chan time.Time
+		lifetime     <-chan time.Time
+		revalidation <-chan time.Time
+		reason       closeReason
+	)
+
+	var idleTimer *time.Timer
+
+	if settings.IdleTimeout > 0 {
+		idleTimer = time.NewTimer(settings.IdleTimeout)
+		defer idleTimer.Stop()
+
+		idle = idleTimer.C
+	}
+
+	if settings.MaxLifetime > 0 {
+		timer := time.NewTimer(settings.MaxLifetime)
+		defer timer.Stop()
+
+		lifetime = timer.C
+	}
+
+	if settings.RevalidationInterval > 0 {
+		ticker := time.NewTicker(settings.RevalidationInterval)
+		defer ticker.Stop()
+
+		revalidation = ticker.C
+	}
+
+	for closing := false; !closing; {
+		select {
+		case <-conn.done:
+			return
+		case <-idle:
+			if idleFor := conn.idleFor(); idleFor < settings.IdleTimeout {
+				// the timer has fired, so it can be re-armed without draining its channel
+				idleTimer.Reset(settings.IdleTimeout - idleFor)
+
+				continue
+			}
+
+			reason, closing = closeReasonIdleTimeout, true
+		case <-lifetime:
+			reason, closing = closeReasonMaxLifetime, true
+		case <-revalidation:
+			if err := upstream.Revalidate(requestcontext.New(r.req)); err != nil {
+				logger.Info().Err(err).Msg("Subject revalidation failed")
+
+				reason, closing = closeReasonRevalidationFailed, true
+			}
+		}
+	}
+
+	logger.Info().Str("_reason", reason.String()).Msg("Closing upgraded connection")
+
+	if protocol == nil {
+		// without knowing the framing, no close frame can be sent
+		_ = conn.Close()
+
+		return
+	}
+
+	conn.closeGracefully(protocol.closeFrame(reason))
+}
+
+func (r *requestContext) handleResponse(upstream rule.Backend, resp *http.Response) error {
+	response := &heimdall.Response{StatusCode: resp.StatusCode, Header: resp.Header}
+
+	if err := upstream.HandleResponse(response); err != nil {
+		return err
+	}
+
+	// set by the error handlers of the rule if the response pipeline failed
+	if err := r.PipelineError(); err != nil {
+		return err
+	}
+
+	for _, cookie := range r.ClientCookies() {
+		resp.Header.Add("Set-Cookie", cookie.String())
+	}
+
+	if response.StatusCode != resp.StatusCode {
+		resp.StatusCode = response.StatusCode
+		resp.Status = fmt.Sprintf("%d %s", response.StatusCode, http.StatusText(response.StatusCode))
+	}
+
+	return nil
+}
+
 func (r *requestContext) rewriteRequest(targetURL *url.URL, passHostHeader bool) func(req *httputil.ProxyRequest) {
 	return func(proxyReq *httputil.ProxyRequest) {
 		proxyReq.Out.Method = r.Request().Method
@@ -130,8 +321,17 @@ func (r *requestContext) rewriteRequest(targetURL *url.URL, passHostHeader bool)
 		proxyReq.Out.Header.Del("X-Forwarded-Uri")
 		proxyReq.Out.Header.Del("X-Forwarded-Path")
 
+		// the upgrade to h2c requires the HTTP2-Settings header, which is removed by the reverse proxy,
+		// as it is a hop-by-hop header
+		if settings := proxyReq.In.Header.Get("HTTP2-Settings"); len(settings) != 0 &&
+			upgradeType(proxyReq.In.Header) == config2.UpgradeProtocolH2C {
+			proxyReq.Out.Header.Set("Connection", "Upgrade, HTTP2-Settings")
+			proxyReq.Out.Header.Set("HTTP2-Settings", settings)
+		}
+
 		r.addUpstreamHeader(proxyReq.Out)
 		r.addUpstreamCookies(proxyReq.Out)
+		r.setUpstreamQueryParameters(proxyReq.Out)
 		r.rewriteForwardedHeader(proxyReq.In, proxyReq.Out)
 
 		if host := proxyReq.Out.Header.Get("Host"); len(host) != 0 {
@@ -190,6 +390,20 @@ func (r *requestContext) addUpstreamCookies(req *http.Request) {
 	}
 }
 
+func (r *requestContext) setUpstreamQueryParameters(req *http.Request) {
+	params := r.UpstreamQueryParameters()
+	if len(params) == 0 {
+		return
+	}
+
+	query := req.URL.Query()
+	for k, v := range params {
+		query.Set(k, v)
+	}
+
+	req.URL.RawQuery = query.Encode()
+}
+
 func (r *requestContext) addUpstreamHeader(req *http.Request) {
 	// delete those headers which are set by heimdall first
 	// we do this to prevent spoofing
@@ -25,13 +25,18 @@ import (
 	"testing"
 	"time"
 
+	"github.com/rs/zerolog"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 
 	"github.com/dadrus/heimdall/internal/config"
 	"github.com/dadrus/heimdall/internal/handler/requestcontext"
+	"github.com/dadrus/heimdall/internal/heimdall"
+	config2 "github.com/dadrus/heimdall/internal/rules/config"
 	"github.com/dadrus/heimdall/internal/rules/rule"
 	mocks2 "github.com/dadrus/heimdall/internal/rules/rule/mocks"
+	"github.com/dadrus/heimdall/internal/x"
 )
 
 func TestRequestContextFinalize(t *testing.T) {
@@ -43,6 +48,7 @@ func TestRequestContextFinalize(t *testing.T) {
 		headers        http.Header
 		setup          func(*testing.T, requestcontext.Context, *url.URL) rule.Backend
 		assertRequest  func(*testing.T, *http.Request)
+		assertResult   func(*testing.T, error, *httptest.ResponseRecorder)
 	}{
 		"error was present, forwarding aborted": {
 			setup: func(t *testing.T, ctx requestcontext.Context, _ *url.URL) rule.Backend {
@@ -62,7 +68,11 @@ func TestRequestContextFinalize(t *testing.T) {
 
 				backend := mocks2.NewBackendMock(t)
 				backend.EXPECT().URL().Return(upstreamURL)
+				backend.EXPECT().Targets().Return(nil)
+				backend.EXPECT().Transport().Return(nil)
+				backend.EXPECT().TLSConfig().Return(nil)
 				backend.EXPECT().ForwardHostHeader().Return(false)
+				backend.EXPECT().HandleResponse(mock.Anything).Return(nil)
 
 				return backend
 			},
@@ -98,7 +108,11 @@ func TestRequestContextFinalize(t *testing.T) {
 
 				backend := mocks2.NewBackendMock(t)
 				backend.EXPECT().URL().Return(upstreamURL)
+				backend.EXPECT().Targets().Return(nil)
+				backend.EXPECT().Transport().Return(nil)
+				backend.EXPECT().TLSConfig().Return(nil)
 				backend.EXPECT().ForwardHostHeader().Return(false)
+				backend.EXPECT().HandleResponse(mock.Anything).Return(nil)
 
 				return backend
 			},
@@ -128,7 +142,11 @@ func TestRequestContextFinalize(t *testing.T) {
 
 				backend := mocks2.NewBackendMock(t)
 				backend.EXPECT().URL().Return(upstreamURL)
+				backend.EXPECT().Targets().Return(nil)
+				backend.EXPECT().Transport().Return(nil)
+				backend.EXPECT().TLSConfig().Return(nil)
 				backend.EXPECT().ForwardHostHeader().Return(false)
+				backend.EXPECT().HandleResponse(mock.Anything).Return(nil)
 
 				return backend
 			},
@@ -161,10 +179,15 @@ func TestRequestContextFinalize(t *testing.T) {
 				ctx.AddHeaderForUpstream("X-Forwarded-Method", http.MethodDelete)
 				ctx.AddCookieForUpstream("my_cookie_1", "my_value_1")
 				ctx.AddCookieForUpstream("my_cookie_2", "my_value_2")
+				ctx.AddQueryParameterForUpstream("tenant", "foo")
 
 				backend := mocks2.NewBackendMock(t)
 				backend.EXPECT().URL().Return(upstreamURL)
+				backend.EXPECT().Targets().Return(nil)
+				backend.EXPECT().Transport().Return(nil)
+				backend.EXPECT().TLSConfig().Return(nil)
 				backend.EXPECT().ForwardHostHeader().Return(false)
+				backend.EXPECT().HandleResponse(mock.Anything).Return(nil)
 
 				return backend
 			},
@@ -188,6 +211,7 @@ func TestRequestContextFinalize(t *testing.T) {
 				assert.ElementsMatch(t, req.Header.Values("X-Bar"), []string{"bar"})
 				assert.Equal(t, http.MethodDelete, req.Header.Get("X-Forwarded-Method"))
 				assert.Equal(t, "someid", req.Header.Get("X-User-Id"))
+				assert.Equal(t, "foo", req.URL.Query().Get("tenant"))
 			},
 		},
 		"only custom headers and results from rule execution are present (custom header are dropped)": {
@@ -209,7 +233,11 @@ func TestRequestContextFinalize(t *testing.T) {
 
 				backend := mocks2.NewBackendMock(t)
 				backend.EXPECT().URL().Return(upstreamURL)
+				backend.EXPECT().Targets().Return(nil)
+				backend.EXPECT().Transport().Return(nil)
+				backend.EXPECT().TLSConfig().Return(nil)
 				backend.EXPECT().ForwardHostHeader().Return(false)
+				backend.EXPECT().HandleResponse(mock.Anything).Return(nil)
 
 				return backend
 			},
@@ -244,7 +272,11 @@ func TestRequestContextFinalize(t *testing.T) {
 
 				backend := mocks2.NewBackendMock(t)
 				backend.EXPECT().URL().Return(upstreamURL)
+				backend.EXPECT().Targets().Return(nil)
+				backend.EXPECT().Transport().Return(nil)
+				backend.EXPECT().TLSConfig().Return(nil)
 				backend.EXPECT().ForwardHostHeader().Return(false)
+				backend.EXPECT().HandleResponse(mock.Anything).Return(nil)
 
 				return backend
 			},
@@ -273,7 +305,11 @@ func TestRequestContextFinalize(t *testing.T) {
 
 				backend := mocks2.NewBackendMock(t)
 				backend.EXPECT().URL().Return(upstreamURL)
+				backend.EXPECT().Targets().Return(nil)
+				backend.EXPECT().Transport().Return(nil)
+				backend.EXPECT().TLSConfig().Return(nil)
 				backend.EXPECT().ForwardHostHeader().Return(false)
+				backend.EXPECT().HandleResponse(mock.Anything).Return(nil)
 
 				return backend
 			},
@@ -302,7 +338,11 @@ func TestRequestContextFinalize(t *testing.T) {
 
 				backend := mocks2.NewBackendMock(t)
 				backend.EXPECT().URL().Return(upstreamURL)
+				backend.EXPECT().Targets().Return(nil)
+				backend.EXPECT().Transport().Return(nil)
+				backend.EXPECT().TLSConfig().Return(nil)
 				backend.EXPECT().ForwardHostHeader().Return(true)
+				backend.EXPECT().HandleResponse(mock.Anything).Return(nil)
 
 				return backend
 			},
@@ -331,7 +371,11 @@ func TestRequestContextFinalize(t *testing.T) {
 
 				backend := mocks2.NewBackendMock(t)
 				backend.EXPECT().URL().Return(upstreamURL)
+				backend.EXPECT().Targets().Return(nil)
+				backend.EXPECT().Transport().Return(nil)
+				backend.EXPECT().TLSConfig().Return(nil)
 				backend.EXPECT().ForwardHostHeader().Return(false)
+				backend.EXPECT().HandleResponse(mock.Anything).Return(nil)
 
 				return backend
 			},
@@ -350,6 +394,89 @@ func TestRequestContextFinalize(t *testing.T) {
 				assert.Equal(t, "172.2.34.1, 192.0.2.1", req.Header.Get("X-Forwarded-For"))
 			},
 		},
+		"response modified by the response pipeline": {
+			upstreamCalled: true,
+			setup: func(t *testing.T, _ requestcontext.Context, upstreamURL *url.URL) rule.Backend {
+				t.Helper()
+
+				backend := mocks2.NewBackendMock(t)
+				backend.EXPECT().URL().Return(upstreamURL)
+				backend.EXPECT().Targets().Return(nil)
+				backend.EXPECT().Transport().Return(nil)
+				backend.EXPECT().TLSConfig().Return(nil)
+				backend.EXPECT().ForwardHostHeader().Return(false)
+				backend.EXPECT().HandleResponse(mock.Anything).RunAndReturn(func(resp *heimdall.Response) error {
+					assert.Equal(t, http.StatusOK, resp.StatusCode)
+					assert.Equal(t, "bar", resp.Header.Get("X-Internal-Foo"))
+
+					resp.StatusCode = http.StatusAccepted
+					resp.Header.Del("X-Internal-Foo")
+
+					return nil
+				})
+
+				return backend
+			},
+			assertRequest: func(t *testing.T, _ *http.Request) { t.Helper() },
+			assertResult: func(t *testing.T, err error, rw *httptest.ResponseRecorder) {
+				t.Helper()
+
+				require.NoError(t, err)
+				assert.Equal(t, http.StatusAccepted, rw.Code)
+				assert.Empty(t, rw.Header().Get("X-Internal-Foo"))
+			},
+		},
+		"response pipeline fails": {
+			upstreamCalled: true,
+			setup: func(t *testing.T, _ requestcontext.Context, upstreamURL *url.URL) rule.Backend {
+				t.Helper()
+
+				backend := mocks2.NewBackendMock(t)
+				backend.EXPECT().URL().Return(upstreamURL)
+				backend.EXPECT().Targets().Return(nil)
+				backend.EXPECT().Transport().Return(nil)
+				backend.EXPECT().TLSConfig().Return(nil)
+				backend.EXPECT().ForwardHostHeader().Return(false)
+				backend.EXPECT().HandleResponse(mock.Anything).Return(heimdall.ErrAuthorization)
+
+				return backend
+			},
+			assertRequest: func(t *testing.T, _ *http.Request) { t.Helper() },
+			assertResult: func(t *testing.T, err error, rw *httptest.ResponseRecorder) {
+				t.Helper()
+
+				require.ErrorIs(t, err, heimdall.ErrAuthorization)
+				assert.Empty(t, rw.Header().Get("X-Internal-Foo"))
+				assert.Empty(t, rw.Body.String())
+			},
+		},
+		"error set by the error pipeline while handling the response": {
+			upstreamCalled: true,
+			setup: func(t *testing.T, ctx requestcontext.Context, upstreamURL *url.URL) rule.Backend {
+				t.Helper()
+
+				backend := mocks2.NewBackendMock(t)
+				backend.EXPECT().URL().Return(upstreamURL)
+				backend.EXPECT().Targets().Return(nil)
+				backend.EXPECT().Transport().Return(nil)
+				backend.EXPECT().TLSConfig().Return(nil)
+				backend.EXPECT().ForwardHostHeader().Return(false)
+				backend.EXPECT().HandleResponse(mock.Anything).RunAndReturn(func(_ *heimdall.Response) error {
+					ctx.SetPipelineError(heimdall.ErrAuthorization)
+
+					return nil
+				})
+
+				return backend
+			},
+			assertRequest: func(t *testing.T, _ *http.Request) { t.Helper() },
+			assertResult: func(t *testing.T, err error, rw *httptest.ResponseRecorder) {
+				t.Helper()
+
+				require.ErrorIs(t, err, heimdall.ErrAuthorization)
+				assert.Empty(t, rw.Body.String())
+			},
+		},
 	} {
 		t.Run(uc, func(t *testing.T) {
 			// GIVEN
@@ -363,10 +490,14 @@ func TestRequestContextFinalize(t *testing.T) {
 
 			rw := httptest.NewRecorder()
 
-			srv := httptest.NewServer(http.HandlerFunc(func(_ http.ResponseWriter, req *http.Request) {
+			srv := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
 				upstreamCalled = true
 
 				tc.assertRequest(t, req)
+
+				rw.Header().Set("X-Internal-Foo", "bar")
+				rw.WriteHeader(http.StatusOK)
+				_, _ = rw.Write([]byte("Pong"))
 			}))
 			defer srv.Close()
 
@@ -378,7 +509,8 @@ func TestRequestContextFinalize(t *testing.T) {
 				Write: 100 * time.Millisecond,
 				Idle:  1 * time.Second,
 			}
-			ctx := newContextFactory(config.ServeConfig{Timeout: timeouts}, nil).Create(rw, req)
+			ctx := newContextFactory(config.ServeConfig{Timeout: timeouts}, nil, newTargetPools(zerolog.Nop())).
+				Create(rw, req)
 
 			backend := tc.setup(t, ctx, targetURL)
 
@@ -391,6 +523,127 @@ func TestRequestContextFinalize(t *testing.T) {
 			if !tc.upstreamCalled {
 				require.Error(t, err)
 			}
+
+			if tc.assertResult != nil {
+				tc.assertResult(t, err, rw)
+			}
+		})
+	}
+}
+
+func TestRequestContextFinalizeWithMultipleTargets(t *testing.T) {
+	t.Parallel()
+
+	for uc, tc := range map[string]struct {
+		loadBalancing  *config2.LoadBalancing
+		healthy        bool
+		configureMocks func(t *testing.T, backend *mocks2.BackendMock)
+		assert         func(t *testing.T, err error, calls map[string]int)
+	}{
+		"requests are distributed among the targets": {
+			healthy: true,
+			configureMocks: func(t *testing.T, backend *mocks2.BackendMock) {
+				t.Helper()
+
+				backend.EXPECT().HashKey().Return("", nil)
+				backend.EXPECT().ForwardHostHeader().Return(false)
+				backend.EXPECT().HandleResponse(mock.Anything).Return(nil)
+			},
+			assert: func(t *testing.T, err error, calls map[string]int) {
+				t.Helper()
+
+				require.NoError(t, err)
+				assert.Equal(t, map[string]int{"srv1": 2, "srv2": 2}, calls)
+			},
+		},
+		"failing target is ejected": {
+			loadBalancing: &config2.LoadBalancing{
+				OutlierDetection: &config2.OutlierDetection{ConsecutiveFailures: 1},
+			},
+			configureMocks: func(t *testing.T, backend *mocks2.BackendMock) {
+				t.Helper()
+
+				backend.EXPECT().HashKey().Return("", nil)
+				backend.EXPECT().ForwardHostHeader().Return(false)
+				backend.EXPECT().HandleResponse(mock.Anything).Return(nil)
+			},
+			assert: func(t *testing.T, err error, calls map[string]int) {
+				t.Helper()
+
+				require.NoError(t, err)
+				assert.Equal(t, map[string]int{"srv1": 1, "srv2": 3}, calls)
+			},
+		},
+		"hash key rendering fails": {
+			loadBalancing: &config2.LoadBalancing{Strategy: config2.LoadBalancingConsistentHash},
+			configureMocks: func(t *testing.T, backend *mocks2.BackendMock) {
+				t.Helper()
+
+				backend.EXPECT().HashKey().Return("", errors.New("test error"))
+			},
+			assert: func(t *testing.T, err error, calls map[string]int) {
+				t.Helper()
+
+				require.ErrorIs(t, err, heimdall.ErrInternal)
+				require.ErrorContains(t, err, "test error")
+				assert.Empty(t, calls)
+			},
+		},
+	} {
+		t.Run(uc, func(t *testing.T) {
+			// GIVEN
+			calls := make(map[string]int)
+
+			newUpstream := func(name string, status int) *httptest.Server {
+				return httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, _ *http.Request) {
+					calls[name]++
+
+					rw.WriteHeader(status)
+				}))
+			}
+
+			srv1 := newUpstream("srv1", x.IfThenElse(tc.healthy, http.StatusOK, http.StatusBadGateway))
+			defer srv1.Close()
+
+			srv2 := newUpstream("srv2", http.StatusOK)
+			defer srv2.Close()
+
+			srv1URL, err := url.Parse(srv1.URL)
+			require.NoError(t, err)
+
+			srv2URL, err := url.Parse(srv2.URL)
+			require.NoError(t, err)
+
+			pools := newTargetPools(zerolog.Nop())
+			defer pools.Close()
+
+			released := make(chan struct{})
+			defer close(released)
+
+			factory := newContextFactory(config.ServeConfig{Timeout: config.Timeout{Read: time.Second}}, nil, pools)
+
+			// WHEN
+			for range 4 {
+				backend := mocks2.NewBackendMock(t)
+				backend.EXPECT().URL().Return(&url.URL{Scheme: "http", Path: "/test"})
+				backend.EXPECT().Targets().Return([]config2.Target{{Host: srv1URL.Host}, {Host: srv2URL.Host}})
+				backend.EXPECT().LoadBalancing().Return(tc.loadBalancing)
+				backend.EXPECT().Transport().Return(nil)
+				backend.EXPECT().TLSConfig().Return(nil)
+				backend.EXPECT().Released().Return(released)
+				tc.configureMocks(t, backend)
+
+				req := httptest.NewRequest(http.MethodGet, "https://foo.bar/test", nil)
+				ctx := factory.Create(httptest.NewRecorder(), req)
+
+				err = ctx.Finalize(backend)
+				if err != nil {
+					break
+				}
+			}
+
+			// THEN
+			tc.assert(t, err, calls)
 		})
 	}
 }
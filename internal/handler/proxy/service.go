@@ -89,6 +89,7 @@ func newService(
 ) *http.Server {
 	der := &deadlineResetter{}
 	cfg := conf.Serve
+	pools := newTargetPools(log)
 	eh := errorhandler.New(
 		errorhandler.WithVerboseErrors(cfg.Respond.Verbose),
 		errorhandler.WithPreconditionErrorCode(cfg.Respond.With.ArgumentError.Code),
@@ -96,6 +97,7 @@ func newService(
 		errorhandler.WithAuthorizationErrorCode(cfg.Respond.With.AuthorizationError.Code),
 		errorhandler.WithCommunicationErrorCode(cfg.Respond.With.CommunicationError.Code),
 		errorhandler.WithNoRuleErrorCode(cfg.Respond.With.NoRuleError.Code),
+		errorhandler.WithTooManyRequestsErrorCode(cfg.Respond.With.TooManyRequestsError.Code),
 		errorhandler.WithInternalServerErrorCode(cfg.Respond.With.InternalError.Code),
 	)
 
@@ -136,9 +138,9 @@ func newService(
 			func() func(http.Handler) http.Handler { return passthrough.New },
 		),
 		cachemiddleware.New(cch),
-	).Then(service.NewHandler(newContextFactory(cfg, tlsClientConfig), exec, eh))
+	).Then(service.NewHandler(newContextFactory(cfg, tlsClientConfig, pools), exec, eh))
 
-	return &http.Server{
+	srv := &http.Server{
 		Handler:        hc,
 		ReadTimeout:    cfg.Timeout.Read,
 		WriteTimeout:   cfg.Timeout.Write,
@@ -147,4 +149,9 @@ func newService(
 		ErrorLog:       loggeradapter.NewStdLogger(log),
 		ConnContext:    der.contexter,
 	}
+
+	// stops the health checks of the load balanced upstream targets
+	srv.RegisterOnShutdown(pools.Close)
+
+	return srv
 }
@@ -51,6 +51,7 @@ import (
 	"github.com/dadrus/heimdall/internal/config"
 	"github.com/dadrus/heimdall/internal/handler/listener"
 	"github.com/dadrus/heimdall/internal/heimdall"
+	config2 "github.com/dadrus/heimdall/internal/rules/config"
 	mocks4 "github.com/dadrus/heimdall/internal/rules/rule/mocks"
 	"github.com/dadrus/heimdall/internal/x"
 	"github.com/dadrus/heimdall/internal/x/pkix/pemx"
@@ -290,12 +291,16 @@ func TestProxyService(t *testing.T) {
 				t.Helper()
 
 				backend := mocks4.NewBackendMock(t)
+				backend.EXPECT().Targets().Return(nil)
+				backend.EXPECT().Transport().Return(nil)
+				backend.EXPECT().TLSConfig().Return(nil)
 				backend.EXPECT().URL().Return(&url.URL{
 					Scheme: upstreamURL.Scheme,
 					Host:   upstreamURL.Host,
 					Path:   "/foobar",
 				})
 				backend.EXPECT().ForwardHostHeader().Return(true)
+				backend.EXPECT().HandleResponse(mock.Anything).Return(nil).Maybe()
 
 				exec.EXPECT().Execute(
 					mock.MatchedBy(func(ctx heimdall.RequestContext) bool {
@@ -372,12 +377,16 @@ func TestProxyService(t *testing.T) {
 				t.Helper()
 
 				backend := mocks4.NewBackendMock(t)
+				backend.EXPECT().Targets().Return(nil)
+				backend.EXPECT().Transport().Return(nil)
+				backend.EXPECT().TLSConfig().Return(nil)
 				backend.EXPECT().URL().Return(&url.URL{
 					Scheme: upstreamURL.Scheme,
 					Host:   upstreamURL.Host,
 					Path:   "/[id]/foobar",
 				})
 				backend.EXPECT().ForwardHostHeader().Return(true)
+				backend.EXPECT().HandleResponse(mock.Anything).Return(nil).Maybe()
 
 				exec.EXPECT().Execute(
 					mock.MatchedBy(func(ctx heimdall.RequestContext) bool {
@@ -453,12 +462,16 @@ func TestProxyService(t *testing.T) {
 				t.Helper()
 
 				backend := mocks4.NewBackendMock(t)
+				backend.EXPECT().Targets().Return(nil)
+				backend.EXPECT().Transport().Return(nil)
+				backend.EXPECT().TLSConfig().Return(nil)
 				backend.EXPECT().URL().Return(&url.URL{
 					Scheme: upstreamURL.Scheme,
 					Host:   upstreamURL.Host,
 					Path:   "/[barfoo]",
 				})
 				backend.EXPECT().ForwardHostHeader().Return(true)
+				backend.EXPECT().HandleResponse(mock.Anything).Return(nil).Maybe()
 
 				exec.EXPECT().Execute(
 					mock.MatchedBy(func(ctx heimdall.RequestContext) bool {
@@ -541,12 +554,16 @@ func TestProxyService(t *testing.T) {
 				t.Helper()
 
 				backend := mocks4.NewBackendMock(t)
+				backend.EXPECT().Targets().Return(nil)
+				backend.EXPECT().Transport().Return(nil)
+				backend.EXPECT().TLSConfig().Return(nil)
 				backend.EXPECT().URL().Return(&url.URL{
 					Scheme: upstreamURL.Scheme,
 					Host:   upstreamURL.Host,
 					Path:   "/bar",
 				})
 				backend.EXPECT().ForwardHostHeader().Return(true)
+				backend.EXPECT().HandleResponse(mock.Anything).Return(nil).Maybe()
 
 				exec.EXPECT().Execute(
 					mock.MatchedBy(func(ctx heimdall.RequestContext) bool {
@@ -715,12 +732,16 @@ func TestProxyService(t *testing.T) {
 				t.Helper()
 
 				backend := mocks4.NewBackendMock(t)
+				backend.EXPECT().Targets().Return(nil)
+				backend.EXPECT().Transport().Return(nil)
+				backend.EXPECT().TLSConfig().Return(nil)
 				backend.EXPECT().URL().Return(&url.URL{
 					Scheme: upstreamURL.Scheme,
 					Host:   upstreamURL.Host,
 					Path:   "/bar",
 				})
 				backend.EXPECT().ForwardHostHeader().Return(false)
+				backend.EXPECT().HandleResponse(mock.Anything).Return(nil).Maybe()
 
 				exec.EXPECT().Execute(
 					mock.MatchedBy(func(ctx heimdall.RequestContext) bool {
@@ -810,12 +831,16 @@ func TestProxyService(t *testing.T) {
 				t.Helper()
 
 				backend := mocks4.NewBackendMock(t)
+				backend.EXPECT().Targets().Return(nil)
+				backend.EXPECT().Transport().Return(nil)
+				backend.EXPECT().TLSConfig().Return(nil)
 				backend.EXPECT().URL().Return(&url.URL{
 					Scheme: upstreamURL.Scheme,
 					Host:   upstreamURL.Host,
 					Path:   "/bar",
 				})
 				backend.EXPECT().ForwardHostHeader().Return(true)
+				backend.EXPECT().HandleResponse(mock.Anything).Return(nil).Maybe()
 
 				exec.EXPECT().Execute(
 					mock.MatchedBy(func(ctx heimdall.RequestContext) bool {
@@ -1005,12 +1030,17 @@ func TestWebSocketSupport(t *testing.T) {
 
 	exec := mocks4.NewExecutorMock(t)
 	backend := mocks4.NewBackendMock(t)
+	backend.EXPECT().Targets().Return(nil)
+	backend.EXPECT().Transport().Return(nil)
+	backend.EXPECT().TLSConfig().Return(nil)
 	backend.EXPECT().URL().Return(&url.URL{
 		Scheme: upstreamURL.Scheme,
 		Host:   upstreamURL.Host,
 		Path:   "/bar",
 	})
 	backend.EXPECT().ForwardHostHeader().Return(true)
+	backend.EXPECT().HandleResponse(mock.Anything).Return(nil).Maybe()
+	backend.EXPECT().Upgrade().Return(nil)
 
 	exec.EXPECT().Execute(
 		mock.MatchedBy(func(ctx heimdall.RequestContext) bool {
@@ -1072,6 +1102,178 @@ func TestWebSocketSupport(t *testing.T) {
 	require.NoError(t, err)
 }
 
+func TestWebSocketSupervision(t *testing.T) {
+	t.Parallel()
+
+	for uc, tc := range map[string]struct {
+		upgrade        *config2.Upgrade
+		configureMocks func(t *testing.T, backend *mocks4.BackendMock)
+		assert         func(t *testing.T, resp *http.Response, con *websocket.Conn, err error)
+	}{
+		"upgrade not allowed": {
+			upgrade: &config2.Upgrade{Protocols: []string{config2.UpgradeProtocolH2C}},
+			assert: func(t *testing.T, resp *http.Response, _ *websocket.Conn, err error) {
+				t.Helper()
+
+				require.ErrorIs(t, err, websocket.ErrBadHandshake)
+				assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+			},
+		},
+		"closed on max lifetime": {
+			upgrade: &config2.Upgrade{
+				Protocols:   []string{config2.UpgradeProtocolWebSocket},
+				MaxLifetime: 200 * time.Millisecond,
+			},
+			assert: func(t *testing.T, _ *http.Response, con *websocket.Conn, err error) {
+				t.Helper()
+
+				require.NoError(t, err)
+
+				_, _, err = con.ReadMessage()
+
+				var closeErr *websocket.CloseError
+				require.ErrorAs(t, err, &closeErr)
+				assert.Equal(t, websocket.CloseGoingAway, closeErr.Code)
+				assert.Equal(t, "max lifetime exceeded", closeErr.Text)
+			},
+		},
+		"closed on idle timeout": {
+			upgrade: &config2.Upgrade{
+				Protocols:   []string{config2.UpgradeProtocolWebSocket},
+				IdleTimeout: 200 * time.Millisecond,
+			},
+			assert: func(t *testing.T, _ *http.Response, con *websocket.Conn, err error) {
+				t.Helper()
+
+				require.NoError(t, err)
+
+				// activity defers the closing
+				for range 3 {
+					time.Sleep(100 * time.Millisecond)
+
+					require.NoError(t, con.WriteMessage(websocket.TextMessage, []byte("ping")))
+
+					_, message, err := con.ReadMessage()
+					require.NoError(t, err)
+					assert.Equal(t, []byte("ping"), message)
+				}
+
+				_, _, err = con.ReadMessage()
+
+				var closeErr *websocket.CloseError
+				require.ErrorAs(t, err, &closeErr)
+				assert.Equal(t, websocket.CloseGoingAway, closeErr.Code)
+				assert.Equal(t, "idle timeout", closeErr.Text)
+			},
+		},
+		"closed on failed subject revalidation": {
+			upgrade: &config2.Upgrade{
+				Protocols:            []string{config2.UpgradeProtocolWebSocket},
+				RevalidationInterval: 100 * time.Millisecond,
+			},
+			configureMocks: func(t *testing.T, backend *mocks4.BackendMock) {
+				t.Helper()
+
+				backend.EXPECT().Revalidate(mock.Anything).Return(nil).Once()
+				backend.EXPECT().Revalidate(mock.Anything).Return(heimdall.ErrAuthentication).Once()
+			},
+			assert: func(t *testing.T, _ *http.Response, con *websocket.Conn, err error) {
+				t.Helper()
+
+				require.NoError(t, err)
+
+				_, _, err = con.ReadMessage()
+
+				var closeErr *websocket.CloseError
+				require.ErrorAs(t, err, &closeErr)
+				assert.Equal(t, websocket.ClosePolicyViolation, closeErr.Code)
+				assert.Equal(t, "subject revalidation failed", closeErr.Text)
+			},
+		},
+	} {
+		t.Run(uc, func(t *testing.T) {
+			port, err := testsupport.GetFreePort()
+			require.NoError(t, err)
+
+			upstreamSrv := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+				upgrader := websocket.Upgrader{CheckOrigin: func(_ *http.Request) bool { return true }}
+
+				con, err := upgrader.Upgrade(rw, req, nil)
+				if err != nil {
+					return
+				}
+
+				defer con.Close()
+
+				for {
+					mt, message, err := con.ReadMessage()
+					if err != nil {
+						return
+					}
+
+					if err = con.WriteMessage(mt, message); err != nil {
+						return
+					}
+				}
+			}))
+			defer upstreamSrv.Close()
+
+			upstreamURL, err := url.Parse(upstreamSrv.URL)
+			require.NoError(t, err)
+
+			configureMocks := x.IfThenElse(tc.configureMocks != nil,
+				tc.configureMocks,
+				func(t *testing.T, _ *mocks4.BackendMock) { t.Helper() })
+
+			exec := mocks4.NewExecutorMock(t)
+			backend := mocks4.NewBackendMock(t)
+			backend.EXPECT().Targets().Return(nil).Maybe()
+			backend.EXPECT().Transport().Return(nil).Maybe()
+			backend.EXPECT().TLSConfig().Return(nil).Maybe()
+			backend.EXPECT().URL().Return(&url.URL{Scheme: upstreamURL.Scheme, Host: upstreamURL.Host}).Maybe()
+			backend.EXPECT().ForwardHostHeader().Return(true).Maybe()
+			backend.EXPECT().HandleResponse(mock.Anything).Return(nil).Maybe()
+			backend.EXPECT().Upgrade().Return(tc.upgrade)
+			configureMocks(t, backend)
+
+			exec.EXPECT().Execute(mock.Anything).Return(backend, nil)
+
+			conf := &config.Configuration{
+				Serve: config.ServeConfig{
+					Timeout: config.Timeout{Read: 1 * time.Second, Write: 1 * time.Second, Idle: 1 * time.Second},
+					Host:    "127.0.0.1",
+					Port:    port,
+				},
+			}
+
+			proxy := newService(conf, mocks.NewCacheMock(t), log.Logger, exec)
+
+			defer proxy.Shutdown(t.Context())
+
+			lstnr, err := listener.New("tcp", "test", conf.Serve.Address(), conf.Serve.TLS, nil, nil)
+			require.NoError(t, err)
+
+			go func() {
+				proxy.Serve(lstnr)
+			}()
+
+			time.Sleep(50 * time.Millisecond)
+
+			wsURL := url.URL{Scheme: "ws", Host: conf.Serve.Address(), Path: "/foo"}
+			con, resp, err := websocket.DefaultDialer.Dial(wsURL.String(), nil)
+			if resp != nil {
+				defer resp.Body.Close()
+			}
+
+			if con != nil {
+				defer con.Close()
+			}
+
+			tc.assert(t, resp, con, err)
+		})
+	}
+}
+
 func TestServerSentEventsSupport(t *testing.T) {
 	t.Parallel()
 
@@ -1104,12 +1306,16 @@ func TestServerSentEventsSupport(t *testing.T) {
 	exec := mocks4.NewExecutorMock(t)
 
 	backend := mocks4.NewBackendMock(t)
+	backend.EXPECT().Targets().Return(nil)
+	backend.EXPECT().Transport().Return(nil)
+	backend.EXPECT().TLSConfig().Return(nil)
 	backend.EXPECT().URL().Return(&url.URL{
 		Scheme: upstreamURL.Scheme,
 		Host:   upstreamURL.Host,
 		Path:   "/bar",
 	})
 	backend.EXPECT().ForwardHostHeader().Return(true)
+	backend.EXPECT().HandleResponse(mock.Anything).Return(nil).Maybe()
 
 	exec.EXPECT().Execute(
 		mock.MatchedBy(func(ctx heimdall.RequestContext) bool {
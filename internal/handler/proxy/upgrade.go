@@ -0,0 +1,366 @@
+// Copyright 2025 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package proxy
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/dadrus/heimdall/internal/rules/config"
+)
+
+// time given to complete the frame currently sent to the client before the
+// connection is closed without sending a close frame.
+const closeGracePeriod = 5 * time.Second
+
+type closeReason int
+
+const (
+	closeReasonIdleTimeout closeReason = iota
+	closeReasonMaxLifetime
+	closeReasonRevalidationFailed
+)
+
+func (r closeReason) String() string {
+	switch r {
+	case closeReasonIdleTimeout:
+		return "idle timeout"
+	case closeReasonMaxLifetime:
+		return "max lifetime exceeded"
+	default:
+		return "subject revalidation failed"
+	}
+}
+
+// upgradeProtocol describes the framing of the data sent by the upstream service to the client
+// after the connection has been upgraded. It is required to be able to close the connection
+// cleanly by sending a protocol specific close frame without corrupting the data stream.
+type upgradeProtocol struct {
+	// headerSize returns the size of the frame header, or 0 if it cannot be determined yet
+	headerSize    func(header []byte) int
+	payloadLength func(header []byte) uint64
+	closeFrame    func(reason closeReason) []byte
+}
+
+var upgradeProtocols = map[string]*upgradeProtocol{ //nolint:gochecknoglobals
+	config.UpgradeProtocolWebSocket: {
+		headerSize:    webSocketHeaderSize,
+		payloadLength: webSocketPayloadLength,
+		closeFrame:    webSocketCloseFrame,
+	},
+	config.UpgradeProtocolH2C: {
+		headerSize:    http2HeaderSize,
+		payloadLength: http2PayloadLength,
+		closeFrame:    http2GoAwayFrame,
+	},
+}
+
+// upgradeType returns the protocol, the client requested to upgrade the connection to,
+// or an empty string, if the request is not an upgrade request.
+func upgradeType(header http.Header) string {
+	for _, value := range header.Values("Connection") {
+		for _, token := range strings.Split(value, ",") {
+			if strings.EqualFold(strings.TrimSpace(token), "upgrade") {
+				return strings.ToLower(strings.TrimSpace(header.Get("Upgrade")))
+			}
+		}
+	}
+
+	return ""
+}
+
+// see RFC 6455, section 5.2.
+func webSocketHeaderSize(header []byte) int {
+	const (
+		minHeaderSize    = 2
+		maskKeySize      = 4
+		len16Marker      = 126
+		len64Marker      = 127
+		payloadLenMask   = 0x7f
+		maskedMarkerMask = 0x80
+	)
+
+	if len(header) < minHeaderSize {
+		return 0
+	}
+
+	size := minHeaderSize
+
+	switch header[1] & payloadLenMask {
+	case len16Marker:
+		size += 2
+	case len64Marker:
+		size += 8
+	}
+
+	if header[1]&maskedMarkerMask != 0 {
+		size += maskKeySize
+	}
+
+	return size
+}
+
+func webSocketPayloadLength(header []byte) uint64 {
+	const payloadLenMask = 0x7f
+
+	switch length := header[1] & payloadLenMask; length {
+	case 126: //nolint:mnd
+		return uint64(binary.BigEndian.Uint16(header[2:4]))
+	case 127: //nolint:mnd
+		return binary.BigEndian.Uint64(header[2:10])
+	default:
+		return uint64(length)
+	}
+}
+
+func webSocketCloseFrame(reason closeReason) []byte {
+	const (
+		finCloseOpcode      = 0x88
+		statusGoingAway     = 1001
+		statusPolicyViolate = 1008
+	)
+
+	status := uint16(statusGoingAway)
+	if reason == closeReasonRevalidationFailed {
+		status = statusPolicyViolate
+	}
+
+	payload := binary.BigEndian.AppendUint16(nil, status)
+	payload = append(payload, reason.String()...)
+
+	return append([]byte{finCloseOpcode, byte(len(payload))}, payload...)
+}
+
+// see RFC 9113, section 4.1.
+func http2HeaderSize(_ []byte) int { return 9 } //nolint:mnd
+
+func http2PayloadLength(header []byte) uint64 {
+	return uint64(header[0])<<16 | uint64(header[1])<<8 | uint64(header[2])
+}
+
+// see RFC 9113, section 6.8. As heimdall does not know, which streams have been processed
+// by the upstream service, the highest possible stream id is used as last stream id to
+// prevent clients from retrying requests, which might have been processed already.
+func http2GoAwayFrame(reason closeReason) []byte {
+	const (
+		typeGoAway   = 0x7
+		lastStreamID = 1<<31 - 1
+		noError      = 0x0
+	)
+
+	debugData := reason.String()
+	length := 8 + len(debugData) //nolint:mnd
+
+	frame := []byte{byte(length >> 16), byte(length >> 8), byte(length), typeGoAway, 0, 0, 0, 0, 0} //nolint:mnd,gosec
+	frame = binary.BigEndian.AppendUint32(frame, lastStreamID)
+	frame = binary.BigEndian.AppendUint32(frame, noError)
+
+	return append(frame, debugData...)
+}
+
+// frameTracker keeps track of the frame boundaries in the data sent to the client.
+type frameTracker struct {
+	protocol *upgradeProtocol
+	header   []byte
+	payload  uint64
+}
+
+func (t *frameTracker) atBoundary() bool { return len(t.header) == 0 && t.payload == 0 }
+
+// consume processes the given data and returns the number of processed bytes. If stopAtBoundary
+// is set, processing stops at the first frame boundary.
+func (t *frameTracker) consume(data []byte, stopAtBoundary bool) int {
+	if t.protocol == nil {
+		// the data cannot be split into frames without knowing the framing
+		return len(data)
+	}
+
+	pos := 0
+
+	for pos < len(data) {
+		if stopAtBoundary && t.atBoundary() {
+			break
+		}
+
+		if t.payload != 0 {
+			n := min(uint64(len(data)-pos), t.payload) //nolint:gosec
+
+			t.payload -= n
+			pos += int(n) //nolint:gosec
+
+			continue
+		}
+
+		t.header = append(t.header, data[pos])
+		pos++
+
+		if size := t.protocol.headerSize(t.header); size != 0 && len(t.header) == size {
+			t.payload = t.protocol.payloadLength(t.header)
+			t.header = t.header[:0]
+		}
+	}
+
+	return pos
+}
+
+// upgradedConn wraps the hijacked client connection to track its activity and to be able
+// to close it cleanly.
+type upgradedConn struct {
+	net.Conn
+
+	lastActivity atomic.Int64
+	done         chan struct{}
+	closeOnce    sync.Once
+
+	mut        sync.Mutex
+	frames     *frameTracker
+	closeFrame []byte
+}
+
+func newUpgradedConn(conn net.Conn, protocol *upgradeProtocol) *upgradedConn {
+	uc := &upgradedConn{
+		Conn:   conn,
+		done:   make(chan struct{}),
+		frames: &frameTracker{protocol: protocol},
+	}
+
+	uc.touch()
+
+	return uc
+}
+
+func (c *upgradedConn) Read(data []byte) (int, error) {
+	n, err := c.Conn.Read(data)
+	if n > 0 {
+		c.touch()
+	}
+
+	return n, err
+}
+
+func (c *upgradedConn) Write(data []byte) (int, error) {
+	c.mut.Lock()
+	defer c.mut.Unlock()
+
+	if c.closeFrame == nil {
+		n, err := c.Conn.Write(data)
+		if n > 0 {
+			c.frames.consume(data[:n], false)
+			c.touch()
+		}
+
+		return n, err
+	}
+
+	if c.frames.atBoundary() {
+		// the close frame has been sent already
+		return 0, net.ErrClosed
+	}
+
+	// closing has been requested while a frame was being sent; only that frame is completed
+	n, err := c.Conn.Write(data[:c.frames.consume(data, true)])
+	if err != nil {
+		return n, err
+	}
+
+	if c.frames.atBoundary() {
+		c.sendCloseFrame()
+	}
+
+	return n, net.ErrClosed
+}
+
+func (c *upgradedConn) CloseWrite() error {
+	if cw, ok := c.Conn.(interface{ CloseWrite() error }); ok {
+		return cw.CloseWrite()
+	}
+
+	return errors.ErrUnsupported
+}
+
+func (c *upgradedConn) Close() error {
+	var err error
+
+	c.closeOnce.Do(func() {
+		close(c.done)
+
+		err = c.Conn.Close()
+	})
+
+	return err
+}
+
+// closeGracefully sends the given close frame to the client as soon as the frame currently being
+// sent is complete and closes the connection afterward.
+func (c *upgradedConn) closeGracefully(frame []byte) {
+	// unblocks pending writes if the client does not read anymore
+	timer := time.AfterFunc(closeGracePeriod, func() { _ = c.Close() })
+
+	c.mut.Lock()
+	defer c.mut.Unlock()
+
+	if c.closeFrame != nil {
+		timer.Stop()
+
+		return
+	}
+
+	c.closeFrame = frame
+
+	if c.frames.atBoundary() {
+		timer.Stop()
+		c.sendCloseFrame()
+	}
+}
+
+func (c *upgradedConn) sendCloseFrame() {
+	_ = c.Conn.SetWriteDeadline(time.Now().Add(closeGracePeriod))
+	_, _ = c.Conn.Write(c.closeFrame)
+	_ = c.Close()
+}
+
+func (c *upgradedConn) touch() { c.lastActivity.Store(time.Now().UnixNano()) }
+
+func (c *upgradedConn) idleFor() time.Duration {
+	return time.Since(time.Unix(0, c.lastActivity.Load()))
+}
+
+// hijackInterceptor allows wrapping the client connection hijacked by the
+// reverse proxy on successful protocol upgrade.
+type hijackInterceptor struct {
+	http.ResponseWriter
+
+	onHijack func(conn net.Conn) net.Conn
+}
+
+func (h *hijackInterceptor) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	conn, brw, err := http.NewResponseController(h.ResponseWriter).Hijack()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return h.onHijack(conn), brw, nil
+}
+
+func (h *hijackInterceptor) Unwrap() http.ResponseWriter { return h.ResponseWriter }
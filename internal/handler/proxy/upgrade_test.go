@@ -0,0 +1,294 @@
+// Copyright 2025 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package proxy
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dadrus/heimdall/internal/handler/requestcontext"
+	"github.com/dadrus/heimdall/internal/rules/config"
+)
+
+func TestUpgradeType(t *testing.T) {
+	t.Parallel()
+
+	for uc, tc := range map[string]struct {
+		header   http.Header
+		expected string
+	}{
+		"no upgrade request": {
+			header: http.Header{"Upgrade": []string{"websocket"}},
+		},
+		"websocket upgrade": {
+			header:   http.Header{"Connection": []string{"Upgrade"}, "Upgrade": []string{"websocket"}},
+			expected: "websocket",
+		},
+		"h2c upgrade with multiple connection tokens": {
+			header:   http.Header{"Connection": []string{"keep-alive, Upgrade, HTTP2-Settings"}, "Upgrade": []string{"H2C"}},
+			expected: "h2c",
+		},
+	} {
+		t.Run(uc, func(t *testing.T) {
+			assert.Equal(t, tc.expected, upgradeType(tc.header))
+		})
+	}
+}
+
+func webSocketFrame(payloadLen int, masked bool) []byte {
+	frame := []byte{0x82}
+	maskBit := byte(0)
+
+	if masked {
+		maskBit = 0x80
+	}
+
+	switch {
+	case payloadLen < 126:
+		frame = append(frame, maskBit|byte(payloadLen))
+	case payloadLen <= 0xffff:
+		frame = append(frame, maskBit|126)
+		frame = binary.BigEndian.AppendUint16(frame, uint16(payloadLen))
+	default:
+		frame = append(frame, maskBit|127)
+		frame = binary.BigEndian.AppendUint64(frame, uint64(payloadLen))
+	}
+
+	if masked {
+		frame = append(frame, 1, 2, 3, 4)
+	}
+
+	return append(frame, bytes.Repeat([]byte{'a'}, payloadLen)...)
+}
+
+func http2Frame(payloadLen int) []byte {
+	frame := []byte{byte(payloadLen >> 16), byte(payloadLen >> 8), byte(payloadLen), 0x0, 0x1, 0, 0, 0, 1}
+
+	return append(frame, bytes.Repeat([]byte{'a'}, payloadLen)...)
+}
+
+func TestFrameTrackerConsume(t *testing.T) {
+	t.Parallel()
+
+	for uc, tc := range map[string]struct {
+		protocol string
+		frame    []byte
+	}{
+		"websocket frame with 7 bit length": {
+			protocol: config.UpgradeProtocolWebSocket,
+			frame:    webSocketFrame(10, false),
+		},
+		"masked websocket frame with 16 bit length": {
+			protocol: config.UpgradeProtocolWebSocket,
+			frame:    webSocketFrame(300, true),
+		},
+		"websocket frame with 64 bit length": {
+			protocol: config.UpgradeProtocolWebSocket,
+			frame:    webSocketFrame(70000, false),
+		},
+		"empty websocket frame": {
+			protocol: config.UpgradeProtocolWebSocket,
+			frame:    webSocketFrame(0, false),
+		},
+		"http2 frame": {
+			protocol: config.UpgradeProtocolH2C,
+			frame:    http2Frame(100),
+		},
+	} {
+		t.Run(uc, func(t *testing.T) {
+			// consume the frame followed by the first byte of the next one in small chunks
+			data := append(tc.frame, tc.frame[0]) //nolint:gocritic
+			tracker := &frameTracker{protocol: upgradeProtocols[tc.protocol]}
+
+			for pos := 0; pos < len(tc.frame); {
+				end := min(pos+3, len(tc.frame))
+
+				assert.False(t, pos != 0 && tracker.atBoundary())
+
+				pos += tracker.consume(data[pos:end], false)
+			}
+
+			assert.True(t, tracker.atBoundary())
+
+			// nothing is consumed at the boundary if requested to stop there
+			assert.Zero(t, tracker.consume(data[len(tc.frame):], true))
+			assert.Equal(t, 1, tracker.consume(data[len(tc.frame):], false))
+			assert.False(t, tracker.atBoundary())
+		})
+	}
+}
+
+func TestFrameTrackerConsumeWithoutKnownFraming(t *testing.T) {
+	t.Parallel()
+
+	tracker := &frameTracker{}
+	frame := webSocketFrame(7, false)
+
+	assert.Len(t, frame, tracker.consume(frame, false))
+	assert.Len(t, frame, tracker.consume(frame, true))
+	assert.True(t, tracker.atBoundary())
+}
+
+func TestCloseFrames(t *testing.T) {
+	t.Parallel()
+
+	for uc, tc := range map[string]struct {
+		protocol string
+		reason   closeReason
+		assert   func(t *testing.T, frame []byte)
+	}{
+		"websocket close frame on max lifetime": {
+			protocol: config.UpgradeProtocolWebSocket,
+			reason:   closeReasonMaxLifetime,
+			assert: func(t *testing.T, frame []byte) {
+				t.Helper()
+
+				assert.Equal(t, byte(0x88), frame[0])
+				assert.Equal(t, len(frame)-2, int(frame[1]))
+				assert.Equal(t, uint16(1001), binary.BigEndian.Uint16(frame[2:4]))
+				assert.Equal(t, "max lifetime exceeded", string(frame[4:]))
+			},
+		},
+		"websocket close frame on failed revalidation": {
+			protocol: config.UpgradeProtocolWebSocket,
+			reason:   closeReasonRevalidationFailed,
+			assert: func(t *testing.T, frame []byte) {
+				t.Helper()
+
+				assert.Equal(t, uint16(1008), binary.BigEndian.Uint16(frame[2:4]))
+				assert.Equal(t, "subject revalidation failed", string(frame[4:]))
+			},
+		},
+		"http2 goaway frame": {
+			protocol: config.UpgradeProtocolH2C,
+			reason:   closeReasonIdleTimeout,
+			assert: func(t *testing.T, frame []byte) {
+				t.Helper()
+
+				assert.Equal(t, uint64(len(frame)-9), http2PayloadLength(frame))
+				assert.Equal(t, byte(0x7), frame[3])
+				assert.Equal(t, uint32(1<<31-1), binary.BigEndian.Uint32(frame[9:13]))
+				assert.Zero(t, binary.BigEndian.Uint32(frame[13:17]))
+				assert.Equal(t, "idle timeout", string(frame[17:]))
+			},
+		},
+	} {
+		t.Run(uc, func(t *testing.T) {
+			tc.assert(t, upgradeProtocols[tc.protocol].closeFrame(tc.reason))
+		})
+	}
+}
+
+func TestUpgradedConnCloseGracefully(t *testing.T) {
+	t.Parallel()
+
+	for uc, tc := range map[string]struct {
+		written  []byte
+		pending  []byte
+		expected []byte
+	}{
+		"at frame boundary": {
+			written: webSocketFrame(5, false),
+		},
+		"in the middle of a frame": {
+			written: webSocketFrame(20, false)[:10],
+			pending: append(webSocketFrame(20, false)[10:], webSocketFrame(3, false)...),
+			// the pending frame is completed, the next one is not sent anymore
+			expected: webSocketFrame(20, false)[10:],
+		},
+	} {
+		t.Run(uc, func(t *testing.T) {
+			client, server := net.Pipe()
+			defer client.Close()
+
+			received := make(chan []byte)
+
+			go func() {
+				data, _ := io.ReadAll(client)
+				received <- data
+			}()
+
+			conn := newUpgradedConn(server, upgradeProtocols[config.UpgradeProtocolWebSocket])
+			closeFrame := webSocketCloseFrame(closeReasonMaxLifetime)
+
+			n, err := conn.Write(tc.written)
+			require.NoError(t, err)
+			require.Len(t, tc.written, n)
+
+			conn.closeGracefully(closeFrame)
+
+			if len(tc.pending) != 0 {
+				n, err = conn.Write(tc.pending)
+				require.ErrorIs(t, err, net.ErrClosed)
+				assert.Len(t, tc.expected, n)
+			}
+
+			_, err = conn.Write(webSocketFrame(1, false))
+			require.ErrorIs(t, err, net.ErrClosed)
+
+			select {
+			case <-conn.done:
+			case <-time.After(time.Second):
+				t.Fatal("connection not closed")
+			}
+
+			expected := append(append(tc.written, tc.expected...), closeFrame...) //nolint:gocritic
+			assert.Equal(t, expected, <-received)
+		})
+	}
+}
+
+func TestSuperviseUpgradedConnWithoutKnownFraming(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	client, server := net.Pipe()
+	defer client.Close()
+
+	received := make(chan []byte)
+
+	go func() {
+		data, _ := io.ReadAll(client)
+		received <- data
+	}()
+
+	req := httptest.NewRequest(http.MethodGet, "http://heimdall.local/foo", nil)
+	rctx := &requestContext{RequestContext: requestcontext.New(req), req: req}
+	conn := newUpgradedConn(server, nil)
+
+	// WHEN
+	go rctx.superviseUpgradedConn(conn, nil, &config.Upgrade{MaxLifetime: 50 * time.Millisecond}, nil)
+
+	// THEN
+	select {
+	case <-conn.done:
+	case <-time.After(time.Second):
+		t.Fatal("connection not closed")
+	}
+
+	// the connection is closed without sending a close frame
+	assert.Empty(t, <-received)
+}
@@ -0,0 +1,236 @@
+// Copyright 2025 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package proxy
+
+import (
+	"crypto/tls"
+	"io"
+	"net"
+	"net/http"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/goccy/go-json"
+
+	config2 "github.com/dadrus/heimdall/internal/rules/config"
+	"github.com/dadrus/heimdall/internal/x/stringx"
+)
+
+const (
+	defaultRetryAttempts = 2
+	defaultRetryBackoff  = 100 * time.Millisecond
+	// transports not used for that duration are removed and their idle connections are closed,
+	// which happens e.g. if the corresponding rule has been updated or deleted.
+	upstreamTransportIdleTimeout = 10 * time.Minute
+)
+
+type upstreamTransport struct {
+	http.RoundTripper
+
+	transport *http.Transport
+	lastUsed  atomic.Int64
+}
+
+// upstreamTransports holds the transports used to communicate with the upstream services. The
+// transports configured on the rule level are created on first use and shared by all requests
+// using the same settings to be able to reuse connections.
+type upstreamTransports struct {
+	defaultTransport *http.Transport
+
+	mut        sync.Mutex
+	transports map[upstreamTransportKey]*upstreamTransport
+	lastSweep  time.Time
+}
+
+// upstreamTransportKey identifies a transport by its settings and the TLS configuration, which
+// is created by the rule on load and thus changes with each update of the rule.
+type upstreamTransportKey struct {
+	settings  string
+	tlsConfig *tls.Config
+}
+
+func newUpstreamTransports(defaultTransport *http.Transport) *upstreamTransports {
+	return &upstreamTransports{
+		defaultTransport: defaultTransport,
+		transports:       make(map[upstreamTransportKey]*upstreamTransport),
+		lastSweep:        time.Now(),
+	}
+}
+
+// get returns the transport for the given settings, or the default one if no settings are given.
+func (ut *upstreamTransports) get(conf *config2.Transport, tlsCfg *tls.Config) http.RoundTripper {
+	if conf == nil {
+		return ut.defaultTransport
+	}
+
+	key := transportKey(conf, tlsCfg)
+	now := time.Now()
+
+	ut.mut.Lock()
+	defer ut.mut.Unlock()
+
+	if now.Sub(ut.lastSweep) > upstreamTransportIdleTimeout {
+		ut.lastSweep = now
+
+		for key, entry := range ut.transports {
+			if now.Sub(time.Unix(0, entry.lastUsed.Load())) > upstreamTransportIdleTimeout {
+				entry.transport.CloseIdleConnections()
+				delete(ut.transports, key)
+			}
+		}
+	}
+
+	entry, ok := ut.transports[key]
+	if !ok {
+		transport := ut.create(conf, tlsCfg)
+
+		entry = &upstreamTransport{RoundTripper: transport, transport: transport}
+
+		if conf.Retry != nil {
+			entry.RoundTripper = &retryingTransport{
+				next:        transport,
+				attempts:    withDefault(conf.Retry.Attempts, defaultRetryAttempts),
+				backoff:     withDefault(conf.Retry.Backoff, defaultRetryBackoff),
+				statusCodes: conf.Retry.StatusCodes,
+			}
+		}
+
+		ut.transports[key] = entry
+	}
+
+	entry.lastUsed.Store(now.UnixNano())
+
+	return entry.RoundTripper
+}
+
+func transportKey(conf *config2.Transport, tlsCfg *tls.Config) upstreamTransportKey {
+	// marshalling of these types cannot fail
+	raw, _ := json.Marshal(conf)
+
+	return upstreamTransportKey{settings: stringx.ToString(raw), tlsConfig: tlsCfg}
+}
+
+func (ut *upstreamTransports) create(conf *config2.Transport, tlsCfg *tls.Config) *http.Transport {
+	transport := ut.defaultTransport.Clone()
+
+	if timeouts := conf.Timeouts; timeouts != nil {
+		if timeouts.Connect != 0 {
+			transport.DialContext = (&net.Dialer{
+				Timeout:   timeouts.Connect,
+				KeepAlive: 30 * time.Second, //nolint:mnd
+			}).DialContext
+		}
+
+		if timeouts.ResponseHeader != 0 {
+			transport.ResponseHeaderTimeout = timeouts.ResponseHeader
+		}
+
+		if timeouts.Idle != 0 {
+			transport.IdleConnTimeout = timeouts.Idle
+		}
+	}
+
+	if limit := conf.ConnectionsLimit; limit != nil {
+		transport.MaxIdleConns = limit.MaxIdle
+		transport.MaxIdleConnsPerHost = limit.MaxIdlePerHost
+		transport.MaxConnsPerHost = limit.MaxPerHost
+	}
+
+	if tlsCfg != nil {
+		transport.TLSClientConfig = tlsCfg
+	}
+
+	if conf.EnforceHTTP2 {
+		// HTTP/2 over TLS, respectively with prior knowledge (h2c) for plain HTTP upstreams
+		protocols := new(http.Protocols)
+		protocols.SetHTTP2(true)
+		protocols.SetUnencryptedHTTP2(true)
+
+		transport.Protocols = protocols
+	}
+
+	return transport
+}
+
+// retryingTransport retries requests, which failed due to communication errors or were answered
+// with one of the configured status codes. As the body of a proxied request cannot be replayed,
+// only requests with idempotent methods and without a body are retried.
+type retryingTransport struct {
+	next        http.RoundTripper
+	attempts    int
+	backoff     time.Duration
+	statusCodes []int
+}
+
+func (t *retryingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if !isRetryable(req) {
+		return t.next.RoundTrip(req)
+	}
+
+	backoff := t.backoff
+
+	for attempt := 0; ; attempt++ {
+		resp, err := t.next.RoundTrip(req)
+		if attempt == t.attempts || req.Context().Err() != nil || !t.shouldRetry(resp, err) {
+			return resp, err
+		}
+
+		if resp != nil {
+			_, _ = io.Copy(io.Discard, resp.Body)
+			_ = resp.Body.Close()
+		}
+
+		timer := time.NewTimer(backoff)
+
+		select {
+		case <-req.Context().Done():
+			timer.Stop()
+
+			return nil, req.Context().Err()
+		case <-timer.C:
+		}
+
+		backoff *= 2
+	}
+}
+
+func (t *retryingTransport) shouldRetry(resp *http.Response, err error) bool {
+	if err != nil {
+		return true
+	}
+
+	return slices.Contains(t.statusCodes, resp.StatusCode)
+}
+
+func isRetryable(req *http.Request) bool {
+	if req.Body != nil && req.Body != http.NoBody {
+		return false
+	}
+
+	if len(req.Header.Get("Upgrade")) != 0 {
+		return false
+	}
+
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace, http.MethodPut, http.MethodDelete:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,185 @@
+// Copyright 2025 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package proxy
+
+import (
+	"bytes"
+	"crypto/tls"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	config2 "github.com/dadrus/heimdall/internal/rules/config"
+)
+
+func TestUpstreamTransportsGet(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	defaultTransport := &http.Transport{ForceAttemptHTTP2: true}
+	transports := newUpstreamTransports(defaultTransport)
+
+	conf := &config2.Transport{
+		Timeouts: &config2.TransportTimeouts{
+			Connect:        time.Second,
+			ResponseHeader: 2 * time.Second,
+			Idle:           3 * time.Second,
+		},
+		ConnectionsLimit: &config2.ConnectionsLimit{MaxPerHost: 10, MaxIdle: 20, MaxIdlePerHost: 5},
+		EnforceHTTP2:     true,
+	}
+
+	tlsCfg := &tls.Config{MinVersion: tls.VersionTLS12}
+
+	// WHEN
+	rt1 := transports.get(nil, nil)
+	rt2 := transports.get(conf, nil)
+	rt3 := transports.get(&config2.Transport{
+		Timeouts:         conf.Timeouts,
+		ConnectionsLimit: conf.ConnectionsLimit,
+		EnforceHTTP2:     true,
+	}, nil)
+	rt4 := transports.get(&config2.Transport{Retry: &config2.RetryPolicy{}}, nil)
+	rt6 := transports.get(conf, tlsCfg)
+
+	// THEN
+	assert.Same(t, defaultTransport, rt1)
+	assert.Same(t, rt2, rt3)
+	assert.NotSame(t, rt2, rt4)
+	assert.NotSame(t, rt2, rt6)
+
+	transport, ok := rt2.(*http.Transport)
+	require.True(t, ok)
+	assert.NotNil(t, transport.DialContext)
+	assert.Equal(t, 2*time.Second, transport.ResponseHeaderTimeout)
+	assert.Equal(t, 3*time.Second, transport.IdleConnTimeout)
+	assert.Equal(t, 10, transport.MaxConnsPerHost)
+	assert.Equal(t, 20, transport.MaxIdleConns)
+	assert.Equal(t, 5, transport.MaxIdleConnsPerHost)
+	require.NotNil(t, transport.Protocols)
+	assert.True(t, transport.Protocols.HTTP2())
+	assert.True(t, transport.Protocols.UnencryptedHTTP2())
+	assert.False(t, transport.Protocols.HTTP1())
+
+	retrying, ok := rt4.(*retryingTransport)
+	require.True(t, ok)
+	assert.Equal(t, defaultRetryAttempts, retrying.attempts)
+	assert.Equal(t, defaultRetryBackoff, retrying.backoff)
+
+	transport, ok = rt6.(*http.Transport)
+	require.True(t, ok)
+	assert.Same(t, tlsCfg, transport.TLSClientConfig)
+
+	// WHEN
+	transports.transports[transportKey(conf, nil)].lastUsed.Store(time.Now().Add(-2 * upstreamTransportIdleTimeout).UnixNano())
+	transports.lastSweep = time.Now().Add(-2 * upstreamTransportIdleTimeout)
+
+	rt5 := transports.get(conf, nil)
+
+	// THEN
+	assert.NotSame(t, rt2, rt5)
+}
+
+func TestRetryingTransport(t *testing.T) {
+	t.Parallel()
+
+	for uc, tc := range map[string]struct {
+		method        string
+		body          []byte
+		failures      int
+		expStatusCode int
+		expCalls      int
+	}{
+		"succeeds after retries": {
+			method:        http.MethodGet,
+			failures:      2,
+			expStatusCode: http.StatusOK,
+			expCalls:      3,
+		},
+		"gives up after configured attempts": {
+			method:        http.MethodGet,
+			failures:      5,
+			expStatusCode: http.StatusServiceUnavailable,
+			expCalls:      3,
+		},
+		"request with body is not retried": {
+			method:        http.MethodPut,
+			body:          []byte("foo"),
+			failures:      1,
+			expStatusCode: http.StatusServiceUnavailable,
+			expCalls:      1,
+		},
+		"non idempotent request is not retried": {
+			method:        http.MethodPost,
+			failures:      1,
+			expStatusCode: http.StatusServiceUnavailable,
+			expCalls:      1,
+		},
+	} {
+		t.Run(uc, func(t *testing.T) {
+			// GIVEN
+			calls := 0
+
+			srv := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, _ *http.Request) {
+				calls++
+
+				if calls <= tc.failures {
+					rw.WriteHeader(http.StatusServiceUnavailable)
+
+					return
+				}
+
+				rw.WriteHeader(http.StatusOK)
+			}))
+			defer srv.Close()
+
+			transports := newUpstreamTransports(&http.Transport{})
+
+			rt := transports.get(&config2.Transport{
+				Retry: &config2.RetryPolicy{
+					Attempts:    2,
+					Backoff:     time.Millisecond,
+					StatusCodes: []int{http.StatusServiceUnavailable},
+				},
+			}, nil)
+
+			var body io.Reader
+			if tc.body != nil {
+				body = bytes.NewReader(tc.body)
+			}
+
+			req, err := http.NewRequestWithContext(t.Context(), tc.method, srv.URL, body)
+			require.NoError(t, err)
+
+			// WHEN
+			resp, err := rt.RoundTrip(req)
+
+			// THEN
+			require.NoError(t, err)
+
+			defer resp.Body.Close()
+
+			assert.Equal(t, tc.expStatusCode, resp.StatusCode)
+			assert.Equal(t, tc.expCalls, calls)
+		})
+	}
+}
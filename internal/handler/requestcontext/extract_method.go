@@ -20,8 +20,8 @@ import (
 	"net/http"
 )
 
-func extractMethod(req *http.Request) string {
-	if val := req.Header.Get("X-Forwarded-Method"); len(val) != 0 {
+func extractMethod(req *http.Request, profile Profile) string {
+	if val := headerValue(req, profile.MethodHeader); len(val) != 0 {
 		return val
 	}
 
@@ -50,7 +50,7 @@ func TestExtractMethod(t *testing.T) {
 			tc.modify(t, req.Header)
 
 			// WHEN
-			method := extractMethod(req)
+			method := extractMethod(req, DefaultProfile)
 
 			// THEN
 			assert.Equal(t, tc.expect, method)
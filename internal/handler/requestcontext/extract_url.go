@@ -23,24 +23,36 @@ import (
 	"github.com/dadrus/heimdall/internal/x"
 )
 
-func extractURL(req *http.Request) *url.URL {
+func extractURL(req *http.Request, profile Profile) *url.URL {
 	var (
 		rawPath string
 		path    string
 		query   string
 	)
 
-	proto := req.Header.Get("X-Forwarded-Proto")
+	if val := headerValue(req, profile.URLHeader); len(val) != 0 {
+		if forwardedURL, err := url.Parse(val); err == nil && len(forwardedURL.Scheme) != 0 {
+			return &url.URL{
+				Scheme:   forwardedURL.Scheme,
+				Host:     forwardedURL.Host,
+				Path:     forwardedURL.Path,
+				RawPath:  forwardedURL.EscapedPath(),
+				RawQuery: forwardedURL.Query().Encode(),
+			}
+		}
+	}
+
+	proto := headerValue(req, profile.ProtoHeader)
 	if len(proto) == 0 {
 		proto = x.IfThenElse(req.TLS == nil, "http", "https")
 	}
 
-	host := req.Header.Get("X-Forwarded-Host")
+	host := headerValue(req, profile.HostHeader)
 	if len(host) == 0 {
 		host = req.Host
 	}
 
-	if val := req.Header.Get("X-Forwarded-Uri"); len(val) != 0 {
+	if val := headerValue(req, profile.URIHeader); len(val) != 0 {
 		if forwardedURI, err := url.Parse(val); err == nil {
 			rawPath = forwardedURI.EscapedPath()
 			query = forwardedURI.Query().Encode()
@@ -65,3 +77,11 @@ func extractURL(req *http.Request) *url.URL {
 		RawQuery: query,
 	}
 }
+
+func headerValue(req *http.Request, name string) string {
+	if len(name) == 0 {
+		return ""
+	}
+
+	return req.Header.Get(name)
+}
@@ -110,10 +110,71 @@ func TestExtractURL(t *testing.T) {
 			tc.configureRequest(t, req)
 
 			// WHEN
-			extracted := extractURL(req)
+			extracted := extractURL(req, DefaultProfile)
 
 			// THEN
 			tc.assert(t, extracted)
 		})
 	}
 }
+
+func TestExtractURLUsingProfile(t *testing.T) {
+	t.Parallel()
+
+	profile := Profile{
+		ProtoHeader: "X-Forwarded-Proto",
+		HostHeader:  "X-Forwarded-Host",
+		URIHeader:   "X-Original-Uri",
+		URLHeader:   "X-Original-Url",
+	}
+
+	for uc, tc := range map[string]struct {
+		headers map[string]string
+		expURL  string
+	}{
+		"no headers set": {
+			expURL: "http://heimdall.test.local/test?foo=bar",
+		},
+		"X-Forwarded-Uri is ignored": {
+			headers: map[string]string{"X-Forwarded-Uri": "/foo"},
+			expURL:  "http://heimdall.test.local/test?foo=bar",
+		},
+		"uri header set": {
+			headers: map[string]string{"X-Original-Uri": "/foo?bar=baz", "X-Forwarded-Host": "example.com"},
+			expURL:  "http://example.com/foo?bar=baz",
+		},
+		"url header set": {
+			headers: map[string]string{
+				"X-Original-Url":   "https://example.com/bar?baz=foo",
+				"X-Original-Uri":   "/foo?bar=baz",
+				"X-Forwarded-Host": "foo.bar",
+			},
+			expURL: "https://example.com/bar?baz=foo",
+		},
+		"url header without scheme is ignored": {
+			headers: map[string]string{"X-Original-Url": "/bar", "X-Original-Uri": "/foo"},
+			expURL:  "http://heimdall.test.local/foo?foo=bar",
+		},
+	} {
+		t.Run(uc, func(t *testing.T) {
+			// GIVEN
+			req, err := http.NewRequestWithContext(
+				t.Context(),
+				http.MethodGet,
+				"http://heimdall.test.local/test?foo=bar",
+				nil,
+			)
+			require.NoError(t, err)
+
+			for name, value := range tc.headers {
+				req.Header.Set(name, value)
+			}
+
+			// WHEN
+			extracted := extractURL(req, profile)
+
+			// THEN
+			assert.Equal(t, tc.expURL, extracted.String())
+		})
+	}
+}
@@ -6,6 +6,8 @@ import (
 	context "context"
 
 	heimdall "github.com/dadrus/heimdall/internal/heimdall"
+	http "net/http"
+
 	mock "github.com/stretchr/testify/mock"
 
 	rule "github.com/dadrus/heimdall/internal/rules/rule"
@@ -24,6 +26,39 @@ func (_m *ContextMock) EXPECT() *ContextMock_Expecter {
 	return &ContextMock_Expecter{mock: &_m.Mock}
 }
 
+// AddCookieForClient provides a mock function with given fields: cookie
+func (_m *ContextMock) AddCookieForClient(cookie *http.Cookie) {
+	_m.Called(cookie)
+}
+
+// ContextMock_AddCookieForClient_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'AddCookieForClient'
+type ContextMock_AddCookieForClient_Call struct {
+	*mock.Call
+}
+
+// AddCookieForClient is a helper method to define mock.On call
+//   - cookie *http.Cookie
+func (_e *ContextMock_Expecter) AddCookieForClient(cookie interface{}) *ContextMock_AddCookieForClient_Call {
+	return &ContextMock_AddCookieForClient_Call{Call: _e.mock.On("AddCookieForClient", cookie)}
+}
+
+func (_c *ContextMock_AddCookieForClient_Call) Run(run func(cookie *http.Cookie)) *ContextMock_AddCookieForClient_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(*http.Cookie))
+	})
+	return _c
+}
+
+func (_c *ContextMock_AddCookieForClient_Call) Return() *ContextMock_AddCookieForClient_Call {
+	_c.Call.Return()
+	return _c
+}
+
+func (_c *ContextMock_AddCookieForClient_Call) RunAndReturn(run func(*http.Cookie)) *ContextMock_AddCookieForClient_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // AddCookieForUpstream provides a mock function with given fields: name, value
 func (_m *ContextMock) AddCookieForUpstream(name string, value string) {
 	_m.Called(name, value)
@@ -58,6 +93,40 @@ func (_c *ContextMock_AddCookieForUpstream_Call) RunAndReturn(run func(string, s
 	return _c
 }
 
+// AddDynamicMetadata provides a mock function with given fields: key, value
+func (_m *ContextMock) AddDynamicMetadata(key string, value string) {
+	_m.Called(key, value)
+}
+
+// ContextMock_AddDynamicMetadata_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'AddDynamicMetadata'
+type ContextMock_AddDynamicMetadata_Call struct {
+	*mock.Call
+}
+
+// AddDynamicMetadata is a helper method to define mock.On call
+//   - key string
+//   - value string
+func (_e *ContextMock_Expecter) AddDynamicMetadata(key interface{}, value interface{}) *ContextMock_AddDynamicMetadata_Call {
+	return &ContextMock_AddDynamicMetadata_Call{Call: _e.mock.On("AddDynamicMetadata", key, value)}
+}
+
+func (_c *ContextMock_AddDynamicMetadata_Call) Run(run func(key string, value string)) *ContextMock_AddDynamicMetadata_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *ContextMock_AddDynamicMetadata_Call) Return() *ContextMock_AddDynamicMetadata_Call {
+	_c.Call.Return()
+	return _c
+}
+
+func (_c *ContextMock_AddDynamicMetadata_Call) RunAndReturn(run func(string, string)) *ContextMock_AddDynamicMetadata_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // AddHeaderForUpstream provides a mock function with given fields: name, value
 func (_m *ContextMock) AddHeaderForUpstream(name string, value string) {
 	_m.Called(name, value)
@@ -92,6 +161,40 @@ func (_c *ContextMock_AddHeaderForUpstream_Call) RunAndReturn(run func(string, s
 	return _c
 }
 
+// AddQueryParameterForUpstream provides a mock function with given fields: name, value
+func (_m *ContextMock) AddQueryParameterForUpstream(name string, value string) {
+	_m.Called(name, value)
+}
+
+// ContextMock_AddQueryParameterForUpstream_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'AddQueryParameterForUpstream'
+type ContextMock_AddQueryParameterForUpstream_Call struct {
+	*mock.Call
+}
+
+// AddQueryParameterForUpstream is a helper method to define mock.On call
+//   - name string
+//   - value string
+func (_e *ContextMock_Expecter) AddQueryParameterForUpstream(name interface{}, value interface{}) *ContextMock_AddQueryParameterForUpstream_Call {
+	return &ContextMock_AddQueryParameterForUpstream_Call{Call: _e.mock.On("AddQueryParameterForUpstream", name, value)}
+}
+
+func (_c *ContextMock_AddQueryParameterForUpstream_Call) Run(run func(name string, value string)) *ContextMock_AddQueryParameterForUpstream_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *ContextMock_AddQueryParameterForUpstream_Call) Return() *ContextMock_AddQueryParameterForUpstream_Call {
+	_c.Call.Return()
+	return _c
+}
+
+func (_c *ContextMock_AddQueryParameterForUpstream_Call) RunAndReturn(run func(string, string)) *ContextMock_AddQueryParameterForUpstream_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // Context provides a mock function with given fields:
 func (_m *ContextMock) Context() context.Context {
 	ret := _m.Called()
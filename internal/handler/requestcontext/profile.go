@@ -0,0 +1,43 @@
+// Copyright 2025 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package requestcontext
+
+// Profile defines the headers, the integrating proxy uses to signal the original request.
+type Profile struct {
+	// MethodHeader holds the name of the header with the original HTTP method
+	MethodHeader string
+	// ProtoHeader holds the name of the header with the original scheme
+	ProtoHeader string
+	// HostHeader holds the name of the header with the original host
+	HostHeader string
+	// URIHeader holds the name of the header with the original path and query
+	URIHeader string
+	// URLHeader holds the name of the header with the full original URL. If present,
+	// it takes precedence over the ProtoHeader, HostHeader and URIHeader.
+	URLHeader string
+	// ClientIPHeader holds the name of the header with the ip of the ultimate client.
+	// If not set, Forwarded and X-Forwarded-For headers are used.
+	ClientIPHeader string
+}
+
+// DefaultProfile makes use of the X-Forwarded-* headers.
+var DefaultProfile = Profile{ //nolint:gochecknoglobals
+	MethodHeader: "X-Forwarded-Method",
+	ProtoHeader:  "X-Forwarded-Proto",
+	HostHeader:   "X-Forwarded-Host",
+	URIHeader:    "X-Forwarded-Uri",
+}
@@ -19,6 +19,7 @@ package requestcontext
 import (
 	"bytes"
 	"context"
+	"crypto/x509"
 	"io"
 	"net/http"
 	"net/textproto"
@@ -36,11 +37,16 @@ type RequestContext struct {
 	reqURL          *url.URL
 	upstreamHeaders http.Header
 	upstreamCookies map[string]string
+	upstreamQuery   map[string]string
+	dynamicMetadata map[string]string
+	clientCookies   []*http.Cookie
 	req             *http.Request
+	profile         Profile
 	err             error
 
 	// the following properties are created lazy and cached
 
+	rawBody   []byte
 	savedBody any
 	hmdlReq   *heimdall.Request
 	headers   map[string]string
@@ -48,11 +54,20 @@ type RequestContext struct {
 }
 
 func New(req *http.Request) *RequestContext {
+	return NewWithProfile(req, DefaultProfile)
+}
+
+// NewWithProfile creates a request context deriving the information about the original
+// request by making use of the headers defined by the given profile.
+func NewWithProfile(req *http.Request, profile Profile) *RequestContext {
 	return &RequestContext{
-		reqMethod:       extractMethod(req),
-		reqURL:          extractURL(req),
+		reqMethod:       extractMethod(req, profile),
+		reqURL:          extractURL(req, profile),
+		profile:         profile,
 		upstreamHeaders: make(http.Header),
 		upstreamCookies: make(map[string]string),
+		upstreamQuery:   make(map[string]string),
+		dynamicMetadata: make(map[string]string),
 		req:             req,
 	}
 }
@@ -87,45 +102,72 @@ func (r *RequestContext) Headers() map[string]string {
 	return r.headers
 }
 
-func (r *RequestContext) Body() any {
+// BodySize returns the size of the request body by reading at most limit+1 bytes of it. The
+// read contents are preserved, so the body can still be consumed afterward.
+func (r *RequestContext) BodySize(limit int64) (int64, error) {
 	if r.req.Body == nil || r.req.Body == http.NoBody {
-		return ""
+		return 0, nil
 	}
 
-	if r.savedBody == nil {
-		// drain body by reading its contents into memory and preserving
-		var buf bytes.Buffer
-		if _, err := buf.ReadFrom(r.req.Body); err != nil {
-			return ""
-		}
+	body := r.req.Body
 
-		if err := r.req.Body.Close(); err != nil {
-			return ""
-		}
+	data, err := io.ReadAll(io.LimitReader(body, limit+1))
 
-		body := buf.Bytes()
-		r.req.Body = io.NopCloser(bytes.NewReader(body))
+	r.req.Body = struct {
+		io.Reader
+		io.Closer
+	}{
+		Reader: io.MultiReader(bytes.NewReader(data), body),
+		Closer: body,
+	}
 
-		decoder, err := contenttype.NewDecoder(r.Header("Content-Type"))
-		if err != nil {
-			r.savedBody = string(body)
+	return int64(len(data)), err
+}
 
-			return r.savedBody
-		}
+// RawBody returns the body of the request as is. It is read into memory and preserved for
+// the upstream service. Returns nil, if there is no body, or it cannot be read.
+func (r *RequestContext) RawBody() []byte {
+	if r.rawBody != nil || r.req.Body == nil || r.req.Body == http.NoBody {
+		return r.rawBody
+	}
 
-		data, err := decoder.Decode(body)
-		if err != nil {
-			r.savedBody = string(body)
+	// drain body by reading its contents into memory and preserving
+	var buf bytes.Buffer
+	if _, err := buf.ReadFrom(r.req.Body); err != nil {
+		return nil
+	}
+
+	if err := r.req.Body.Close(); err != nil {
+		return nil
+	}
 
-			return r.savedBody
+	r.rawBody = buf.Bytes()
+	r.req.Body = io.NopCloser(bytes.NewReader(r.rawBody))
+
+	return r.rawBody
+}
+
+func (r *RequestContext) Body() any {
+	if r.savedBody == nil {
+		body := r.RawBody()
+		if body == nil {
+			return ""
 		}
 
-		r.savedBody = data
+		r.savedBody = contenttype.DecodeBody(r.Header("Content-Type"), body)
 	}
 
 	return r.savedBody
 }
 
+func (r *RequestContext) ClientCertificates() []*x509.Certificate {
+	if r.req.TLS == nil {
+		return nil
+	}
+
+	return r.req.TLS.PeerCertificates
+}
+
 func (r *RequestContext) Request() *heimdall.Request {
 	if r.hmdlReq == nil {
 		r.hmdlReq = &heimdall.Request{
@@ -142,7 +184,11 @@ func (r *RequestContext) Request() *heimdall.Request {
 func (r *RequestContext) requestClientIPs() []string {
 	var ips []string
 
-	if forwarded := r.req.Header.Get("Forwarded"); len(forwarded) != 0 {
+	if clientIP := headerValue(r.req, r.profile.ClientIPHeader); len(clientIP) != 0 {
+		ips = []string{strings.TrimSpace(clientIP)}
+	}
+
+	if forwarded := r.req.Header.Get("Forwarded"); ips == nil && len(forwarded) != 0 {
 		values := strings.Split(forwarded, ",")
 		ips = make([]string, len(values))
 
@@ -166,13 +212,42 @@ func (r *RequestContext) requestClientIPs() []string {
 	return ips
 }
 
+// UpstreamCookieHeader returns the value for the Cookie header to be used in the upstream
+// request. It contains the cookies sent by the client and the cookies set by the pipeline,
+// with latter replacing the former if these have the same name.
+func (r *RequestContext) UpstreamCookieHeader() string {
+	values := make([]string, 0, len(r.upstreamCookies))
+
+	for _, cookie := range r.req.Cookies() {
+		if _, present := r.upstreamCookies[cookie.Name]; !present {
+			values = append(values, cookie.String())
+		}
+	}
+
+	for name, value := range r.upstreamCookies {
+		values = append(values, (&http.Cookie{Name: name, Value: value}).String())
+	}
+
+	return strings.Join(values, "; ")
+}
+
 func (r *RequestContext) AddHeaderForUpstream(name, value string) { r.upstreamHeaders.Add(name, value) }
 func (r *RequestContext) UpstreamHeaders() http.Header            { return r.upstreamHeaders }
 func (r *RequestContext) AddCookieForUpstream(name, value string) { r.upstreamCookies[name] = value }
 func (r *RequestContext) UpstreamCookies() map[string]string      { return r.upstreamCookies }
-func (r *RequestContext) Context() context.Context                { return r.req.Context() }
-func (r *RequestContext) SetPipelineError(err error)              { r.err = err }
-func (r *RequestContext) PipelineError() error                    { return r.err }
+func (r *RequestContext) AddQueryParameterForUpstream(name, value string) {
+	r.upstreamQuery[name] = value
+}
+func (r *RequestContext) UpstreamQueryParameters() map[string]string { return r.upstreamQuery }
+func (r *RequestContext) AddDynamicMetadata(key, value string)       { r.dynamicMetadata[key] = value }
+func (r *RequestContext) DynamicMetadata() map[string]string         { return r.dynamicMetadata }
+func (r *RequestContext) AddCookieForClient(cookie *http.Cookie) {
+	r.clientCookies = append(r.clientCookies, cookie)
+}
+func (r *RequestContext) ClientCookies() []*http.Cookie { return r.clientCookies }
+func (r *RequestContext) Context() context.Context      { return r.req.Context() }
+func (r *RequestContext) SetPipelineError(err error)    { r.err = err }
+func (r *RequestContext) PipelineError() error          { return r.err }
 func (r *RequestContext) Outputs() map[string]any {
 	if r.outputs == nil {
 		r.outputs = make(map[string]any)
@@ -18,9 +18,12 @@ package requestcontext
 
 import (
 	"bytes"
+	"crypto/tls"
+	"crypto/x509"
 	"io"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -109,6 +112,60 @@ func TestRequestContextRequestClientIPs(t *testing.T) {
 	}
 }
 
+func TestRequestContextRequestClientIPsUsingProfile(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	req := httptest.NewRequest(http.MethodHead, "https://foo.bar/test", nil)
+	req.Header.Set("X-Real-Ip", "192.168.1.1")
+	req.Header.Set("X-Forwarded-For", "10.10.10.10")
+
+	ctx := NewWithProfile(req, Profile{ClientIPHeader: "X-Real-Ip"})
+
+	// WHEN
+	ips := ctx.Request().ClientIPAddresses
+
+	// THEN
+	assert.Equal(t, []string{"192.168.1.1", "192.0.2.1"}, ips)
+}
+
+func TestRequestContextUpstreamCookieHeader(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	req := httptest.NewRequest(http.MethodHead, "https://foo.bar/test", nil)
+	req.Header.Set("Cookie", "foo=bar; bar=baz")
+
+	ctx := New(req)
+	ctx.AddCookieForUpstream("bar", "foo")
+	ctx.AddCookieForUpstream("baz", "zab")
+
+	// WHEN
+	value := ctx.UpstreamCookieHeader()
+
+	// THEN
+	assert.ElementsMatch(t, []string{"foo=bar", "bar=foo", "baz=zab"}, strings.Split(value, "; "))
+}
+
+func TestRequestContextClientCookies(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	req := httptest.NewRequest(http.MethodHead, "https://foo.bar/test", nil)
+	cookie1 := &http.Cookie{Name: "foo", Value: "bar"}
+	cookie2 := &http.Cookie{Name: "bar", Value: "baz"}
+
+	ctx := New(req)
+	ctx.AddCookieForClient(cookie1)
+	ctx.AddCookieForClient(cookie2)
+
+	// WHEN
+	cookies := ctx.ClientCookies()
+
+	// THEN
+	assert.Equal(t, []*http.Cookie{cookie1, cookie2}, cookies)
+}
+
 func TestRequestContextHeaders(t *testing.T) {
 	t.Parallel()
 
@@ -168,6 +225,26 @@ func TestRequestContextCookie(t *testing.T) {
 	assert.Empty(t, value2)
 }
 
+func TestRequestContextClientCertificates(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	cert := &x509.Certificate{}
+
+	plainReq := httptest.NewRequest(http.MethodGet, "http://foo.bar/test", nil)
+	tlsReq := httptest.NewRequest(http.MethodGet, "https://foo.bar/test", nil)
+	tlsReq.TLS = &tls.ConnectionState{PeerCertificates: []*x509.Certificate{cert}}
+
+	// WHEN
+	plainCerts := New(plainReq).Request().ClientCertificates()
+	tlsCerts := New(tlsReq).Request().ClientCertificates()
+
+	// THEN
+	assert.Empty(t, plainCerts)
+	require.Len(t, tlsCerts, 1)
+	assert.Same(t, cert, tlsCerts[0])
+}
+
 func TestRequestContextBody(t *testing.T) {
 	t.Parallel()
 
@@ -227,3 +304,33 @@ func TestRequestContextBody(t *testing.T) {
 		})
 	}
 }
+
+func TestRequestContextBodySize(t *testing.T) {
+	t.Parallel()
+
+	for uc, tc := range map[string]struct {
+		body   string
+		expect int64
+	}{
+		"No body":                  {expect: 0},
+		"Body within the limit":    {body: "foo", expect: 3},
+		"Body exceeding the limit": {body: "foobar", expect: 5},
+	} {
+		t.Run(uc, func(t *testing.T) {
+			// GIVEN
+			req := httptest.NewRequest(http.MethodPost, "https://foo.bar/test", strings.NewReader(tc.body))
+			req.Header.Set("Content-Type", "text/plain")
+
+			ctx := New(req)
+
+			// WHEN
+			size, err := ctx.BodySize(4)
+
+			// THEN
+			require.NoError(t, err)
+			assert.Equal(t, tc.expect, size)
+			// the body is still available in full
+			assert.Equal(t, tc.body, ctx.Request().Body())
+		})
+	}
+}
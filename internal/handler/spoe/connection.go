@@ -0,0 +1,251 @@
+// Copyright 2025 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package spoe
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"net"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dadrus/heimdall/internal/x"
+)
+
+const (
+	keySupportedVersions = "supported-versions"
+	keyVersion           = "version"
+	keyMaxFrameSize      = "max-frame-size"
+	keyCapabilities      = "capabilities"
+	keyHealthcheck       = "healthcheck"
+	keyStatusCode        = "status-code"
+	keyMessage           = "message"
+
+	capabilityPipelining = "pipelining"
+	protocolVersion      = "2.0"
+)
+
+type connection struct {
+	srv          *server
+	conn         net.Conn
+	maxFrameSize uint32
+	pipelining   bool
+
+	writeMut sync.Mutex
+	inFlight sync.WaitGroup
+	closing  bool
+	mut      sync.Mutex
+}
+
+func (c *connection) serve() {
+	defer c.conn.Close()
+
+	rd := bufio.NewReader(c.conn)
+
+	if ok := c.handshake(rd); !ok {
+		return
+	}
+
+	for {
+		c.setReadDeadline()
+
+		frm, err := readFrame(rd, c.maxFrameSize)
+		if err != nil {
+			c.inFlight.Wait()
+
+			switch {
+			case c.isClosing():
+				c.disconnect(statusNormal, "agent is shutting down")
+			case errors.Is(err, errFrameTooBig):
+				c.disconnect(statusFrameTooBig, err.Error())
+			case !errors.Is(err, io.EOF) && !errors.Is(err, net.ErrClosed):
+				c.srv.logger.Debug().Err(err).Msg("Failed reading SPOE frame")
+				c.disconnect(statusIO, err.Error())
+			}
+
+			return
+		}
+
+		switch frm.typ {
+		case frameTypeNotify:
+			if frm.flags&frameFlagFin == 0 {
+				c.inFlight.Wait()
+				c.disconnect(statusInvalidFrame, "fragmented frames are not supported")
+
+				return
+			}
+
+			c.inFlight.Add(1)
+
+			if c.pipelining {
+				go c.handleNotify(frm)
+			} else {
+				c.handleNotify(frm)
+			}
+		case frameTypeHAProxyDisconnect:
+			c.inFlight.Wait()
+			c.disconnect(statusNormal, "")
+
+			return
+		default:
+			c.inFlight.Wait()
+			c.disconnect(statusInvalidFrame, "unexpected frame type")
+
+			return
+		}
+	}
+}
+
+func (c *connection) handshake(rd io.Reader) bool {
+	c.setReadDeadline()
+
+	frm, err := readFrame(rd, c.maxFrameSize)
+	if err != nil {
+		c.srv.logger.Debug().Err(err).Msg("Failed reading SPOE hello frame")
+
+		return false
+	}
+
+	if frm.typ != frameTypeHAProxyHello {
+		c.disconnect(statusInvalidFrame, "expected HAPROXY-HELLO frame")
+
+		return false
+	}
+
+	kv, err := readKVList(frm.payload)
+	if err != nil {
+		c.disconnect(statusInvalidFrame, err.Error())
+
+		return false
+	}
+
+	versions, _ := kv[keySupportedVersions].(string)
+	if !slices.ContainsFunc(strings.Split(versions, ","), func(version string) bool {
+		return strings.HasPrefix(strings.TrimSpace(version), "2.")
+	}) {
+		c.disconnect(statusBadVersion, "unsupported version")
+
+		return false
+	}
+
+	if size, ok := kv[keyMaxFrameSize].(uint64); ok && size < uint64(c.maxFrameSize) {
+		c.maxFrameSize = uint32(size) //nolint:gosec
+	}
+
+	capabilities, _ := kv[keyCapabilities].(string)
+	c.pipelining = slices.ContainsFunc(strings.Split(capabilities, ","), func(capability string) bool {
+		return strings.TrimSpace(capability) == capabilityPipelining
+	})
+
+	var payload []byte
+
+	payload = appendKV(payload, keyVersion, protocolVersion)
+	payload = appendKV(payload, keyMaxFrameSize, c.maxFrameSize)
+	payload = appendKV(payload, keyCapabilities, x.IfThenElse(c.pipelining, capabilityPipelining, ""))
+
+	if err = c.write(&frame{typ: frameTypeAgentHello, flags: frameFlagFin, payload: payload}); err != nil {
+		return false
+	}
+
+	// health checks close the connection after having received the AGENT-HELLO frame
+	healthcheck, _ := kv[keyHealthcheck].(bool)
+
+	return !healthcheck
+}
+
+func (c *connection) handleNotify(frm *frame) {
+	defer c.inFlight.Done()
+
+	messages, err := readMessages(frm.payload)
+	if err != nil {
+		c.srv.logger.Debug().Err(err).Msg("Failed decoding SPOE messages")
+
+		_ = c.write(&frame{
+			typ:      frameTypeAck,
+			flags:    frameFlagFin | frameFlagAbort,
+			streamID: frm.streamID,
+			frameID:  frm.frameID,
+		})
+
+		return
+	}
+
+	rw := c.srv.process(c.srv.ctx, messages, c.conn)
+
+	ack := &frame{
+		typ:      frameTypeAck,
+		flags:    frameFlagFin,
+		streamID: frm.streamID,
+		frameID:  frm.frameID,
+		payload:  rw.actions(),
+	}
+
+	if len(ack.encode())-frameLengthSize > int(c.maxFrameSize) {
+		c.srv.logger.Warn().Msg("SPOE actions exceed the max frame size. Only the decision is sent.")
+
+		ack.payload = rw.decisionActions()
+	}
+
+	_ = c.write(ack)
+}
+
+func (c *connection) disconnect(status int, msg string) {
+	var payload []byte
+
+	payload = appendKV(payload, keyStatusCode, uint32(status)) //nolint:gosec
+	payload = appendKV(payload, keyMessage, msg)
+
+	_ = c.write(&frame{typ: frameTypeAgentDisconnect, flags: frameFlagFin, payload: payload})
+}
+
+func (c *connection) write(frm *frame) error {
+	c.writeMut.Lock()
+	defer c.writeMut.Unlock()
+
+	_, err := c.conn.Write(frm.encode())
+	if err != nil {
+		c.srv.logger.Debug().Err(err).Msg("Failed writing SPOE frame")
+	}
+
+	return err
+}
+
+func (c *connection) setReadDeadline() {
+	if c.srv.idleTimeout != 0 {
+		_ = c.conn.SetReadDeadline(time.Now().Add(c.srv.idleTimeout))
+	}
+}
+
+// shutdown interrupts waiting for new frames. The connection is then closed after all
+// pending messages have been processed.
+func (c *connection) shutdown() {
+	c.mut.Lock()
+	c.closing = true
+	c.mut.Unlock()
+
+	_ = c.conn.SetReadDeadline(time.Now())
+}
+
+func (c *connection) isClosing() bool {
+	c.mut.Lock()
+	defer c.mut.Unlock()
+
+	return c.closing
+}
@@ -0,0 +1,99 @@
+// Copyright 2025 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package spoe
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+const (
+	frameLengthSize = 4
+	frameFlagsSize  = 4
+)
+
+var errFrameTooBig = errors.New("frame too big")
+
+type frame struct {
+	typ      frameType
+	flags    uint32
+	streamID uint64
+	frameID  uint64
+	payload  []byte
+}
+
+func readFrame(rd io.Reader, maxSize uint32) (*frame, error) {
+	var header [frameLengthSize]byte
+
+	if _, err := io.ReadFull(rd, header[:]); err != nil {
+		return nil, err
+	}
+
+	length := binary.BigEndian.Uint32(header[:])
+	if length > maxSize {
+		return nil, fmt.Errorf("%w: %d bytes", errFrameTooBig, length)
+	}
+
+	buf := make([]byte, length)
+	if _, err := io.ReadFull(rd, buf); err != nil {
+		return nil, err
+	}
+
+	return decodeFrame(buf)
+}
+
+func decodeFrame(buf []byte) (*frame, error) {
+	if len(buf) < 1+frameFlagsSize {
+		return nil, errBufferTooShort
+	}
+
+	var (
+		frm frame
+		err error
+	)
+
+	frm.typ = frameType(buf[0])
+	frm.flags = binary.BigEndian.Uint32(buf[1 : 1+frameFlagsSize])
+	buf = buf[1+frameFlagsSize:]
+
+	if frm.streamID, buf, err = readVarint(buf); err != nil {
+		return nil, err
+	}
+
+	if frm.frameID, buf, err = readVarint(buf); err != nil {
+		return nil, err
+	}
+
+	frm.payload = buf
+
+	return &frm, nil
+}
+
+func (f *frame) encode() []byte {
+	buf := make([]byte, frameLengthSize, frameLengthSize+1+frameFlagsSize+len(f.payload)+16) //nolint:mnd
+	buf = append(buf, byte(f.typ))
+	buf = putUint32(buf, f.flags)
+	buf = appendVarint(buf, f.streamID)
+	buf = appendVarint(buf, f.frameID)
+	buf = append(buf, f.payload...)
+
+	binary.BigEndian.PutUint32(buf, uint32(len(buf)-frameLengthSize)) //nolint:gosec
+
+	return buf
+}
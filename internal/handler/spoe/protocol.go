@@ -0,0 +1,346 @@
+// Copyright 2025 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package spoe
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"net"
+)
+
+// The implementation follows the SPOP specification (version 2.0) as described in
+// https://github.com/haproxy/haproxy/blob/master/doc/SPOE.txt
+
+type frameType byte
+
+const (
+	frameTypeHAProxyHello      frameType = 1
+	frameTypeHAProxyDisconnect frameType = 2
+	frameTypeNotify            frameType = 3
+	frameTypeAgentHello        frameType = 101
+	frameTypeAgentDisconnect   frameType = 102
+	frameTypeAck               frameType = 103
+)
+
+const (
+	frameFlagFin   uint32 = 0x01
+	frameFlagAbort uint32 = 0x02
+)
+
+type dataType byte
+
+const (
+	dataTypeNull   dataType = 0
+	dataTypeBool   dataType = 1
+	dataTypeInt32  dataType = 2
+	dataTypeUint32 dataType = 3
+	dataTypeInt64  dataType = 4
+	dataTypeUint64 dataType = 5
+	dataTypeIPv4   dataType = 6
+	dataTypeIPv6   dataType = 7
+	dataTypeString dataType = 8
+	dataTypeBinary dataType = 9
+
+	dataTypeMask     = 0x0f
+	dataFlagBoolTrue = 0x10
+)
+
+const (
+	actionSetVar byte = 1
+
+	varScopeTransaction byte = 2
+)
+
+const (
+	statusNormal        = 0
+	statusIO            = 1
+	statusInvalidFrame  = 3
+	statusFrameTooBig   = 7
+	statusBadVersion    = 5
+	statusUnknown       = 99
+	varintOneByteLimit  = 240
+	varintContinuation  = 128
+	varintFirstByteBits = 4
+	varintNextByteBits  = 7
+)
+
+var (
+	errBufferTooShort  = errors.New("buffer too short")
+	errUnsupportedType = errors.New("unsupported data type")
+)
+
+// appendVarint encodes the given value using the variable-length integer encoding of SPOP.
+func appendVarint(buf []byte, val uint64) []byte {
+	if val < varintOneByteLimit {
+		return append(buf, byte(val))
+	}
+
+	buf = append(buf, byte(val)|varintOneByteLimit)
+	val = (val - varintOneByteLimit) >> varintFirstByteBits
+
+	for val >= varintContinuation {
+		buf = append(buf, byte(val)|varintContinuation)
+		val = (val - varintContinuation) >> varintNextByteBits
+	}
+
+	return append(buf, byte(val))
+}
+
+func readVarint(buf []byte) (uint64, []byte, error) {
+	if len(buf) == 0 {
+		return 0, nil, errBufferTooShort
+	}
+
+	val := uint64(buf[0])
+	buf = buf[1:]
+
+	if val < varintOneByteLimit {
+		return val, buf, nil
+	}
+
+	for shift := varintFirstByteBits; ; shift += varintNextByteBits {
+		if len(buf) == 0 || shift > 63 {
+			return 0, nil, errBufferTooShort
+		}
+
+		current := buf[0]
+		buf = buf[1:]
+		val += uint64(current) << shift
+
+		if current < varintContinuation {
+			return val, buf, nil
+		}
+	}
+}
+
+func appendString(buf []byte, val string) []byte {
+	buf = appendVarint(buf, uint64(len(val)))
+
+	return append(buf, val...)
+}
+
+func readBytes(buf []byte) ([]byte, []byte, error) {
+	length, buf, err := readVarint(buf)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if uint64(len(buf)) < length {
+		return nil, nil, errBufferTooShort
+	}
+
+	return buf[:length], buf[length:], nil
+}
+
+func readString(buf []byte) (string, []byte, error) {
+	val, buf, err := readBytes(buf)
+
+	return string(val), buf, err
+}
+
+// readTypedData decodes a single value. Integers are returned as int64, respectively
+// uint64, ip addresses as net.IP, strings as string and binary data as []byte.
+func readTypedData(buf []byte) (any, []byte, error) {
+	if len(buf) == 0 {
+		return nil, nil, errBufferTooShort
+	}
+
+	typ := dataType(buf[0] & dataTypeMask)
+	flags := buf[0] &^ dataTypeMask
+	buf = buf[1:]
+
+	switch typ {
+	case dataTypeNull:
+		return nil, buf, nil
+	case dataTypeBool:
+		return flags&dataFlagBoolTrue != 0, buf, nil
+	case dataTypeInt32, dataTypeInt64:
+		val, rest, err := readVarint(buf)
+
+		return int64(val), rest, err //nolint:gosec
+	case dataTypeUint32, dataTypeUint64:
+		return readVarint(buf)
+	case dataTypeIPv4:
+		if len(buf) < net.IPv4len {
+			return nil, nil, errBufferTooShort
+		}
+
+		return net.IP(buf[:net.IPv4len]), buf[net.IPv4len:], nil
+	case dataTypeIPv6:
+		if len(buf) < net.IPv6len {
+			return nil, nil, errBufferTooShort
+		}
+
+		return net.IP(buf[:net.IPv6len]), buf[net.IPv6len:], nil
+	case dataTypeString:
+		return readString(buf)
+	case dataTypeBinary:
+		return readBytes(buf)
+	default:
+		return nil, nil, fmt.Errorf("%w: %d", errUnsupportedType, typ)
+	}
+}
+
+// appendTypedData encodes the given value. Only the types required by the agent are supported.
+func appendTypedData(buf []byte, val any) []byte {
+	switch val := val.(type) {
+	case bool:
+		if val {
+			return append(buf, byte(dataTypeBool)|dataFlagBoolTrue)
+		}
+
+		return append(buf, byte(dataTypeBool))
+	case int:
+		if val >= 0 && val <= math.MaxUint32 {
+			return appendVarint(append(buf, byte(dataTypeUint32)), uint64(val))
+		}
+
+		return appendVarint(append(buf, byte(dataTypeInt64)), uint64(val)) //nolint:gosec
+	case uint32:
+		return appendVarint(append(buf, byte(dataTypeUint32)), uint64(val))
+	case string:
+		return appendString(append(buf, byte(dataTypeString)), val)
+	case []byte:
+		return append(appendVarint(append(buf, byte(dataTypeBinary)), uint64(len(val))), val...)
+	case net.IP:
+		if ip := val.To4(); ip != nil {
+			return append(append(buf, byte(dataTypeIPv4)), ip...)
+		}
+
+		return append(append(buf, byte(dataTypeIPv6)), val.To16()...)
+	default:
+		return append(buf, byte(dataTypeNull))
+	}
+}
+
+// readKVList decodes a list of key/value pairs until the end of the buffer.
+func readKVList(buf []byte) (map[string]any, error) {
+	result := make(map[string]any)
+
+	for len(buf) != 0 {
+		var (
+			key string
+			val any
+			err error
+		)
+
+		if key, buf, err = readString(buf); err != nil {
+			return nil, err
+		}
+
+		if val, buf, err = readTypedData(buf); err != nil {
+			return nil, err
+		}
+
+		result[key] = val
+	}
+
+	return result, nil
+}
+
+func appendKV(buf []byte, key string, val any) []byte {
+	return appendTypedData(appendString(buf, key), val)
+}
+
+// message is a SPOE message sent by HAProxy in a NOTIFY frame.
+type message struct {
+	name string
+	args map[string]any
+}
+
+func readMessages(buf []byte) ([]message, error) {
+	var messages []message
+
+	for len(buf) != 0 {
+		var (
+			msg message
+			err error
+		)
+
+		if msg.name, buf, err = readString(buf); err != nil {
+			return nil, err
+		}
+
+		if len(buf) == 0 {
+			return nil, errBufferTooShort
+		}
+
+		count := int(buf[0])
+		buf = buf[1:]
+		msg.args = make(map[string]any, count)
+
+		for range count {
+			var (
+				key string
+				val any
+			)
+
+			if key, buf, err = readString(buf); err != nil {
+				return nil, err
+			}
+
+			if val, buf, err = readTypedData(buf); err != nil {
+				return nil, err
+			}
+
+			msg.args[key] = val
+		}
+
+		messages = append(messages, msg)
+	}
+
+	return messages, nil
+}
+
+// appendSetVar encodes a set-var action for the transaction scope.
+func appendSetVar(buf []byte, name string, val any) []byte {
+	buf = append(buf, actionSetVar, 3, varScopeTransaction) //nolint:mnd
+
+	return appendTypedData(appendString(buf, name), val)
+}
+
+// readHeaders decodes the headers in the format used by HAProxy's req.hdrs_bin sample fetch.
+func readHeaders(buf []byte) ([][2]string, error) {
+	var headers [][2]string
+
+	for {
+		var (
+			name, value string
+			err         error
+		)
+
+		if name, buf, err = readString(buf); err != nil {
+			return nil, err
+		}
+
+		if value, buf, err = readString(buf); err != nil {
+			return nil, err
+		}
+
+		if len(name) == 0 && len(value) == 0 {
+			return headers, nil
+		}
+
+		headers = append(headers, [2]string{name, value})
+	}
+}
+
+func putUint32(buf []byte, val uint32) []byte {
+	return binary.BigEndian.AppendUint32(buf, val)
+}
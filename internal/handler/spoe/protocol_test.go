@@ -0,0 +1,199 @@
+// Copyright 2025 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package spoe
+
+import (
+	"math"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVarintEncoding(t *testing.T) {
+	t.Parallel()
+
+	for uc, tc := range map[string]struct {
+		value   uint64
+		encoded []byte
+	}{
+		"zero":                  {value: 0, encoded: []byte{0x00}},
+		"max one byte value":    {value: 239, encoded: []byte{0xef}},
+		"min two bytes value":   {value: 240, encoded: []byte{0xf0, 0x00}},
+		"max two bytes value":   {value: 2287, encoded: []byte{0xff, 0x7f}},
+		"min three bytes value": {value: 2288, encoded: []byte{0xf0, 0x80, 0x00}},
+		"max uint64 value":      {value: math.MaxUint64},
+	} {
+		t.Run(uc, func(t *testing.T) {
+			t.Parallel()
+
+			// WHEN
+			encoded := appendVarint(nil, tc.value)
+			decoded, rest, err := readVarint(append(encoded, 0x01))
+
+			// THEN
+			require.NoError(t, err)
+
+			if tc.encoded != nil {
+				assert.Equal(t, tc.encoded, encoded)
+			}
+
+			assert.Equal(t, tc.value, decoded)
+			assert.Equal(t, []byte{0x01}, rest)
+		})
+	}
+}
+
+func TestReadVarintFromTruncatedBuffer(t *testing.T) {
+	t.Parallel()
+
+	_, _, err := readVarint([]byte{0xf0, 0x80})
+	require.ErrorIs(t, err, errBufferTooShort)
+
+	_, _, err = readVarint(nil)
+	require.ErrorIs(t, err, errBufferTooShort)
+}
+
+func TestTypedDataEncoding(t *testing.T) {
+	t.Parallel()
+
+	for uc, tc := range map[string]struct {
+		value    any
+		expected any
+	}{
+		"null":           {value: nil, expected: nil},
+		"true":           {value: true, expected: true},
+		"false":          {value: false, expected: false},
+		"positive int":   {value: 403, expected: uint64(403)},
+		"negative int":   {value: -1, expected: int64(-1)},
+		"uint32":         {value: uint32(16384), expected: uint64(16384)},
+		"string":         {value: "foo", expected: "foo"},
+		"empty string":   {value: "", expected: ""},
+		"binary":         {value: []byte{0x01, 0x02}, expected: []byte{0x01, 0x02}},
+		"unsupported":    {value: 1.5, expected: nil},
+		"large positive": {value: math.MaxInt64, expected: int64(math.MaxInt64)},
+	} {
+		t.Run(uc, func(t *testing.T) {
+			t.Parallel()
+
+			// WHEN
+			decoded, rest, err := readTypedData(appendTypedData(nil, tc.value))
+
+			// THEN
+			require.NoError(t, err)
+			assert.Empty(t, rest)
+			assert.Equal(t, tc.expected, decoded)
+		})
+	}
+}
+
+func TestReadTypedDataIPAddresses(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	buf := append([]byte{byte(dataTypeIPv4)}, net.ParseIP("192.168.1.1").To4()...)
+	buf = append(buf, byte(dataTypeIPv6))
+	buf = append(buf, net.ParseIP("2001:db8::1")...)
+
+	// WHEN
+	ipv4, rest, err := readTypedData(buf)
+	require.NoError(t, err)
+
+	ipv6, rest, err := readTypedData(rest)
+	require.NoError(t, err)
+
+	// THEN
+	assert.Empty(t, rest)
+	assert.Equal(t, "192.168.1.1", ipv4.(net.IP).String())
+	assert.Equal(t, "2001:db8::1", ipv6.(net.IP).String())
+}
+
+func TestReadTypedDataWithUnsupportedType(t *testing.T) {
+	t.Parallel()
+
+	_, _, err := readTypedData([]byte{0x0a})
+	require.ErrorIs(t, err, errUnsupportedType)
+}
+
+func TestReadMessages(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	buf := appendString(nil, "check-request")
+	buf = append(buf, 2)
+	buf = appendKV(buf, "method", "GET")
+	buf = appendKV(buf, "ssl", true)
+	buf = appendString(buf, "other")
+	buf = append(buf, 0)
+
+	// WHEN
+	messages, err := readMessages(buf)
+
+	// THEN
+	require.NoError(t, err)
+	require.Len(t, messages, 2)
+	assert.Equal(t, "check-request", messages[0].name)
+	assert.Equal(t, map[string]any{"method": "GET", "ssl": true}, messages[0].args)
+	assert.Equal(t, "other", messages[1].name)
+	assert.Empty(t, messages[1].args)
+
+	_, err = readMessages(buf[:len(buf)-1])
+	require.Error(t, err)
+}
+
+func TestReadHeaders(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	buf := appendString(nil, "host")
+	buf = appendString(buf, "example.com")
+	buf = appendString(buf, "x-foo")
+	buf = appendString(buf, "bar")
+
+	// WHEN
+	headers, err := readHeaders(append(buf, 0x00, 0x00))
+
+	// THEN
+	require.NoError(t, err)
+	assert.Equal(t, [][2]string{{"host", "example.com"}, {"x-foo", "bar"}}, headers)
+
+	_, err = readHeaders(buf)
+	require.Error(t, err)
+}
+
+func TestFrameEncoding(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	frm := &frame{
+		typ:      frameTypeAck,
+		flags:    frameFlagFin,
+		streamID: 4711,
+		frameID:  42,
+		payload:  []byte{0x01, 0x02},
+	}
+
+	// WHEN
+	encoded := frm.encode()
+	decoded, err := decodeFrame(encoded[frameLengthSize:])
+
+	// THEN
+	require.NoError(t, err)
+	assert.Equal(t, frm, decoded)
+	assert.Equal(t, []byte{0x00, 0x00, 0x00, byte(len(encoded) - frameLengthSize)}, encoded[:frameLengthSize])
+}
@@ -0,0 +1,133 @@
+// Copyright 2025 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package spoe
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+
+	"github.com/dadrus/heimdall/internal/heimdall"
+	"github.com/dadrus/heimdall/internal/x/errorchain"
+)
+
+// Names of the message arguments, heimdall makes use of.
+const (
+	argMethod  = "method"
+	argPath    = "path"
+	argQuery   = "query"
+	argHost    = "host"
+	argSSL     = "ssl"
+	argHeaders = "headers"
+	argBody    = "body"
+	argIP      = "ip"
+)
+
+type (
+	resultKey   struct{}
+	clientIPKey struct{}
+)
+
+// result holds the information emitted by the pipeline, which cannot be represented in
+// the http response.
+type result struct {
+	queryParameters map[string]string
+	dynamicMetadata map[string]string
+	clientCookies   []*http.Cookie
+}
+
+// newRequest creates an HTTP request from the arguments of the given message.
+func newRequest(ctx context.Context, msg message, remoteAddr net.Addr) (*http.Request, error) {
+	method, _ := msg.args[argMethod].(string)
+	path, _ := msg.args[argPath].(string)
+
+	if len(method) == 0 || len(path) == 0 {
+		return nil, errorchain.NewWithMessagef(heimdall.ErrArgument,
+			"message '%s' must contain the '%s' and '%s' arguments", msg.name, argMethod, argPath)
+	}
+
+	query, _ := msg.args[argQuery].(string)
+	uri := &url.URL{Path: path, RawQuery: query}
+
+	if ip := clientIP(msg); len(ip) != 0 {
+		ctx = context.WithValue(ctx, clientIPKey{}, ip)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, uri.RequestURI(), nil)
+	if err != nil {
+		return nil, errorchain.NewWithMessagef(heimdall.ErrArgument,
+			"failed to create request from message '%s'", msg.name).CausedBy(err)
+	}
+
+	if raw, ok := msg.args[argHeaders].([]byte); ok {
+		headers, err := readHeaders(raw)
+		if err != nil {
+			return nil, errorchain.NewWithMessagef(heimdall.ErrArgument,
+				"failed to decode the '%s' argument of message '%s'", argHeaders, msg.name).CausedBy(err)
+		}
+
+		for _, header := range headers {
+			req.Header.Add(header[0], header[1])
+		}
+	}
+
+	req.Host = req.Header.Get("Host")
+	if host, ok := msg.args[argHost].(string); ok && len(host) != 0 {
+		req.Host = host
+	}
+
+	req.URL.Host = req.Host
+
+	if ssl, _ := msg.args[argSSL].(bool); ssl {
+		req.TLS = &tls.ConnectionState{}
+		req.URL.Scheme = "https"
+	} else {
+		req.URL.Scheme = "http"
+	}
+
+	switch body := msg.args[argBody].(type) {
+	case []byte:
+		req.Body = io.NopCloser(bytes.NewReader(body))
+		req.ContentLength = int64(len(body))
+	case string:
+		req.Body = io.NopCloser(bytes.NewReader([]byte(body)))
+		req.ContentLength = int64(len(body))
+	}
+
+	// the address of HAProxy is the one, the trusted proxies are verified against. The address
+	// of the client, HAProxy has seen, is made available to the pipeline only.
+	req.RemoteAddr = remoteAddr.String()
+
+	return req, nil
+}
+
+// clientIP returns the ip address of the client from the arguments of the given message,
+// or an empty string, if not present.
+func clientIP(msg message) string {
+	switch val := msg.args[argIP].(type) {
+	case net.IP:
+		return val.String()
+	case string:
+		return val
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,86 @@
+// Copyright 2025 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package spoe
+
+import (
+	"net/http"
+
+	"github.com/rs/zerolog"
+
+	"github.com/dadrus/heimdall/internal/handler/requestcontext"
+	"github.com/dadrus/heimdall/internal/heimdall"
+	"github.com/dadrus/heimdall/internal/rules/rule"
+)
+
+func newContextFactory() requestcontext.ContextFactory {
+	return requestcontext.FactoryFunc(func(rw http.ResponseWriter, req *http.Request) requestcontext.Context {
+		return &requestContext{
+			// the message arguments are authoritative, so no headers, which could have been
+			// set by the client, are used to derive the original request
+			RequestContext: requestcontext.NewWithProfile(req, requestcontext.Profile{}),
+			rw:             rw,
+		}
+	})
+}
+
+type requestContext struct {
+	*requestcontext.RequestContext
+
+	rw http.ResponseWriter
+}
+
+// Request returns the request with the last client ip address being the one of the client, HAProxy
+// has seen, if available. That way the address of HAProxy itself is not used for that.
+func (r *requestContext) Request() *heimdall.Request {
+	req := r.RequestContext.Request()
+
+	if ip, ok := r.Context().Value(clientIPKey{}).(string); ok && len(req.ClientIPAddresses) != 0 {
+		req.ClientIPAddresses[len(req.ClientIPAddresses)-1] = ip
+	}
+
+	return req
+}
+
+func (r *requestContext) Finalize(_ rule.Backend) error {
+	if err := r.PipelineError(); err != nil {
+		return err
+	}
+
+	zerolog.Ctx(r.Context()).Debug().Msg("Creating response")
+
+	for name, values := range r.UpstreamHeaders() {
+		for _, value := range values {
+			r.rw.Header().Add(name, value)
+		}
+	}
+
+	if len(r.UpstreamCookies()) != 0 {
+		// HAProxy replaces the header of the request to the upstream,
+		// so the cookies sent by the client must be included
+		r.rw.Header().Set("Cookie", r.UpstreamCookieHeader())
+	}
+
+	if res, ok := r.Context().Value(resultKey{}).(*result); ok {
+		res.queryParameters = r.UpstreamQueryParameters()
+		res.dynamicMetadata = r.DynamicMetadata()
+		res.clientCookies = r.ClientCookies()
+	}
+
+	r.rw.WriteHeader(http.StatusOK)
+
+	return nil
+}
@@ -0,0 +1,132 @@
+// Copyright 2025 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package spoe
+
+import (
+	"bytes"
+	"net/http"
+	"strings"
+)
+
+// Names, respectively prefixes of the variables set by heimdall. HAProxy prepends these
+// with the scope and the var-prefix configured for the agent, e.g. txn.heimdall.allow.
+const (
+	varAllow          = "allow"
+	varStatus         = "status"
+	varBody           = "body"
+	varUpstreamHeader = "hdr_"
+	varResponseHeader = "resp_hdr_"
+	varQueryParameter = "qp_"
+	varMetadata       = "md_"
+	varClientCookie   = "set_cookie_"
+)
+
+// responseWriter records the response created by the handler chain to translate it
+// into set-var actions afterward.
+type responseWriter struct {
+	header http.Header
+	body   bytes.Buffer
+	code   int
+	res    *result
+}
+
+func newResponseWriter(res *result) *responseWriter {
+	return &responseWriter{header: make(http.Header), res: res}
+}
+
+func (w *responseWriter) Header() http.Header { return w.header }
+
+func (w *responseWriter) Write(data []byte) (int, error) {
+	if w.code == 0 {
+		w.code = http.StatusOK
+	}
+
+	return w.body.Write(data)
+}
+
+func (w *responseWriter) WriteHeader(code int) {
+	if w.code == 0 {
+		w.code = code
+	}
+}
+
+func (w *responseWriter) status() int {
+	if w.code == 0 {
+		return http.StatusOK
+	}
+
+	return w.code
+}
+
+func (w *responseWriter) allowed() bool {
+	return w.status() >= http.StatusOK && w.status() < http.StatusMultipleChoices
+}
+
+// decisionActions returns the actions required to enforce the decision only.
+func (w *responseWriter) decisionActions() []byte {
+	buf := appendSetVar(nil, varAllow, w.allowed())
+
+	return appendSetVar(buf, varStatus, w.status())
+}
+
+// actions returns the actions for the decision and all the information created by the pipeline.
+func (w *responseWriter) actions() []byte {
+	allow := w.allowed()
+	buf := w.decisionActions()
+
+	prefix := varUpstreamHeader
+	if !allow {
+		prefix = varResponseHeader
+	}
+
+	for name, values := range w.header {
+		buf = appendSetVar(buf, prefix+varName(name), strings.Join(values, ","))
+	}
+
+	if allow {
+		for name, value := range w.res.queryParameters {
+			buf = appendSetVar(buf, varQueryParameter+varName(name), value)
+		}
+
+		for key, value := range w.res.dynamicMetadata {
+			buf = appendSetVar(buf, varMetadata+varName(key), value)
+		}
+
+		for _, cookie := range w.res.clientCookies {
+			buf = appendSetVar(buf, varClientCookie+varName(cookie.Name), cookie.String())
+		}
+	} else if w.body.Len() != 0 {
+		buf = appendSetVar(buf, varBody, w.body.String())
+	}
+
+	return buf
+}
+
+// varName converts the given name to a valid HAProxy variable name, which may only
+// contain alphanumeric characters, '_' and '.'.
+func varName(name string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9', r == '_', r == '.':
+			return r
+		case r >= 'A' && r <= 'Z':
+			return r + ('a' - 'A')
+		default:
+			return '_'
+		}
+	}, name)
+}
@@ -0,0 +1,188 @@
+// Copyright 2025 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package spoe
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/rs/zerolog"
+
+	"github.com/dadrus/heimdall/internal/handler/middleware/http/errorhandler"
+)
+
+// defaultMaxFrameSize corresponds to the default buffer size used by HAProxy.
+const defaultMaxFrameSize = 16384
+
+// server implements a SPOE agent, which translates the received messages into HTTP requests
+// and lets these be processed by the given handler.
+type server struct {
+	handler      http.Handler
+	eh           errorhandler.ErrorHandler
+	logger       zerolog.Logger
+	idleTimeout  time.Duration
+	maxFrameSize uint32
+
+	// ctx is the base context for the processing of all messages. It is canceled if
+	// the graceful shutdown does not complete in time.
+	ctx    context.Context //nolint:containedctx
+	cancel context.CancelFunc
+
+	mut      sync.Mutex
+	listener net.Listener
+	conns    map[*connection]struct{}
+	closed   atomic.Bool
+	wg       sync.WaitGroup
+}
+
+func (s *server) Serve(ln net.Listener) error {
+	s.mut.Lock()
+	s.listener = ln
+	s.mut.Unlock()
+
+	for {
+		conn, err := ln.Accept()
+		if err != nil {
+			if s.closed.Load() {
+				return http.ErrServerClosed
+			}
+
+			var netErr net.Error
+			if errors.As(err, &netErr) && netErr.Timeout() {
+				continue
+			}
+
+			return err
+		}
+
+		c := &connection{srv: s, conn: conn, maxFrameSize: s.maxFrameSize}
+
+		if !s.track(c) {
+			_ = conn.Close()
+
+			return http.ErrServerClosed
+		}
+
+		go func() {
+			defer s.untrack(c)
+
+			c.serve()
+		}()
+	}
+}
+
+func (s *server) Shutdown(ctx context.Context) error {
+	s.closed.Store(true)
+
+	s.mut.Lock()
+	if s.listener != nil {
+		_ = s.listener.Close()
+	}
+
+	for c := range s.conns {
+		c.shutdown()
+	}
+	s.mut.Unlock()
+
+	done := make(chan struct{})
+
+	go func() {
+		s.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		s.cancel()
+
+		return nil
+	case <-ctx.Done():
+		s.cancel()
+
+		s.mut.Lock()
+		for c := range s.conns {
+			_ = c.conn.Close()
+		}
+		s.mut.Unlock()
+
+		return ctx.Err()
+	}
+}
+
+func (s *server) track(c *connection) bool {
+	s.mut.Lock()
+	defer s.mut.Unlock()
+
+	if s.closed.Load() {
+		return false
+	}
+
+	if s.conns == nil {
+		s.conns = make(map[*connection]struct{})
+	}
+
+	s.conns[c] = struct{}{}
+	s.wg.Add(1)
+
+	return true
+}
+
+func (s *server) untrack(c *connection) {
+	s.mut.Lock()
+	delete(s.conns, c)
+	s.mut.Unlock()
+
+	s.wg.Done()
+}
+
+// process evaluates the first message and returns the resulting actions. HAProxy is expected
+// to send exactly one message per request.
+func (s *server) process(ctx context.Context, messages []message, conn net.Conn) *responseWriter {
+	res := &result{}
+	rw := newResponseWriter(res)
+
+	if len(messages) == 0 {
+		return rw
+	}
+
+	if len(messages) > 1 {
+		s.logger.Warn().Msg("Received multiple SPOE messages. Only the first one is evaluated.")
+	}
+
+	ctx = context.WithValue(ctx, http.LocalAddrContextKey, conn.LocalAddr())
+	ctx = context.WithValue(ctx, resultKey{}, res)
+
+	req, err := newRequest(ctx, messages[0], conn.RemoteAddr())
+	if err != nil {
+		// used only to let the error handler create the response
+		req, _ = http.NewRequestWithContext(ctx, http.MethodGet, "/", nil)
+		req.RemoteAddr = conn.RemoteAddr().String()
+
+		s.eh.HandleError(rw, req, err)
+
+		return rw
+	}
+
+	s.handler.ServeHTTP(rw, req)
+
+	return rw
+}
@@ -0,0 +1,504 @@
+// Copyright 2025 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package spoe
+
+import (
+	"bufio"
+	"context"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dadrus/heimdall/internal/cache/mocks"
+	"github.com/dadrus/heimdall/internal/config"
+	"github.com/dadrus/heimdall/internal/heimdall"
+	mocks4 "github.com/dadrus/heimdall/internal/rules/rule/mocks"
+)
+
+type haproxy struct {
+	t    *testing.T
+	conn net.Conn
+	rd   *bufio.Reader
+}
+
+func (h *haproxy) send(frm *frame) {
+	h.t.Helper()
+
+	_, err := h.conn.Write(frm.encode())
+	require.NoError(h.t, err)
+}
+
+func (h *haproxy) receive() *frame {
+	h.t.Helper()
+
+	require.NoError(h.t, h.conn.SetReadDeadline(time.Now().Add(2*time.Second)))
+
+	frm, err := readFrame(h.rd, defaultMaxFrameSize)
+	require.NoError(h.t, err)
+
+	return frm
+}
+
+func (h *haproxy) hello(healthcheck bool) map[string]any {
+	h.t.Helper()
+
+	var payload []byte
+
+	payload = appendKV(payload, keySupportedVersions, "2.0")
+	payload = appendKV(payload, keyMaxFrameSize, uint32(16380))
+	payload = appendKV(payload, keyCapabilities, "pipelining")
+	payload = appendKV(payload, keyHealthcheck, healthcheck)
+
+	h.send(&frame{typ: frameTypeHAProxyHello, flags: frameFlagFin, payload: payload})
+
+	frm := h.receive()
+	require.Equal(h.t, frameTypeAgentHello, frm.typ)
+
+	kv, err := readKVList(frm.payload)
+	require.NoError(h.t, err)
+
+	return kv
+}
+
+func (h *haproxy) notify(streamID uint64, args map[string]any) map[string]any {
+	h.t.Helper()
+
+	payload := appendString(nil, "check-request")
+	payload = append(payload, byte(len(args)))
+
+	for key, value := range args {
+		payload = appendKV(payload, key, value)
+	}
+
+	h.send(&frame{typ: frameTypeNotify, flags: frameFlagFin, streamID: streamID, frameID: 1, payload: payload})
+
+	frm := h.receive()
+	require.Equal(h.t, frameTypeAck, frm.typ)
+	require.Equal(h.t, streamID, frm.streamID)
+	require.Equal(h.t, uint64(1), frm.frameID)
+
+	return readActions(h.t, frm.payload)
+}
+
+func readActions(t *testing.T, buf []byte) map[string]any {
+	t.Helper()
+
+	vars := make(map[string]any)
+
+	for len(buf) != 0 {
+		require.Equal(t, []byte{actionSetVar, 3, varScopeTransaction}, buf[:3])
+
+		name, rest, err := readString(buf[3:])
+		require.NoError(t, err)
+
+		value, rest, err := readTypedData(rest)
+		require.NoError(t, err)
+
+		vars[name] = value
+		buf = rest
+	}
+
+	return vars
+}
+
+func encodeHeaders(headers ...string) []byte {
+	var buf []byte
+
+	for _, val := range headers {
+		buf = appendString(buf, val)
+	}
+
+	return append(buf, 0x00, 0x00)
+}
+
+func startServer(t *testing.T, exec *mocks4.ExecutorMock) *haproxy {
+	t.Helper()
+
+	// HAProxy is usually configured as a trusted proxy
+	return startServerWithTrustedProxies(t, exec, "127.0.0.0/8")
+}
+
+func startServerWithTrustedProxies(t *testing.T, exec *mocks4.ExecutorMock, proxies ...string) *haproxy {
+	t.Helper()
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	conf := &config.Configuration{Serve: config.ServeConfig{TrustedProxies: proxies}}
+	srv := newService(conf, mocks.NewCacheMock(t), log.Logger, exec)
+
+	go func() {
+		_ = srv.Serve(ln)
+	}()
+
+	t.Cleanup(func() {
+		_ = srv.Shutdown(t.Context())
+	})
+
+	conn, err := net.Dial("tcp", ln.Addr().String())
+	require.NoError(t, err)
+
+	t.Cleanup(func() { _ = conn.Close() })
+
+	return &haproxy{t: t, conn: conn, rd: bufio.NewReader(conn)}
+}
+
+func TestSPOEHandshake(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	proxy := startServer(t, mocks4.NewExecutorMock(t))
+
+	// WHEN
+	kv := proxy.hello(true)
+
+	// THEN
+	assert.Equal(t, protocolVersion, kv[keyVersion])
+	assert.Equal(t, uint64(16380), kv[keyMaxFrameSize])
+	assert.Equal(t, capabilityPipelining, kv[keyCapabilities])
+
+	// connection is closed after a health check
+	_, err := readFrame(proxy.rd, defaultMaxFrameSize)
+	require.Error(t, err)
+}
+
+func TestSPOEHandshakeWithUnsupportedVersion(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	proxy := startServer(t, mocks4.NewExecutorMock(t))
+
+	// WHEN
+	proxy.send(&frame{
+		typ:     frameTypeHAProxyHello,
+		flags:   frameFlagFin,
+		payload: appendKV(nil, keySupportedVersions, "1.0"),
+	})
+
+	// THEN
+	frm := proxy.receive()
+	require.Equal(t, frameTypeAgentDisconnect, frm.typ)
+
+	kv, err := readKVList(frm.payload)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(statusBadVersion), kv[keyStatusCode])
+}
+
+func TestSPOEDisconnect(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	proxy := startServer(t, mocks4.NewExecutorMock(t))
+	proxy.hello(false)
+
+	// WHEN
+	proxy.send(&frame{typ: frameTypeHAProxyDisconnect, flags: frameFlagFin})
+
+	// THEN
+	frm := proxy.receive()
+	require.Equal(t, frameTypeAgentDisconnect, frm.typ)
+
+	kv, err := readKVList(frm.payload)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(statusNormal), kv[keyStatusCode])
+}
+
+func TestSPOENotify(t *testing.T) {
+	t.Parallel()
+
+	for uc, tc := range map[string]struct {
+		args           map[string]any
+		configureMocks func(t *testing.T, exec *mocks4.ExecutorMock)
+		assert         func(t *testing.T, vars map[string]any)
+	}{
+		"message without required arguments": {
+			args:           map[string]any{"path": "/foo"},
+			configureMocks: func(t *testing.T, _ *mocks4.ExecutorMock) { t.Helper() },
+			assert: func(t *testing.T, vars map[string]any) {
+				t.Helper()
+
+				assert.Equal(t, false, vars[varAllow])
+				assert.Equal(t, uint64(http.StatusBadRequest), vars[varStatus])
+			},
+		},
+		"rule execution fails with authentication error": {
+			args: map[string]any{"method": "GET", "path": "/foo"},
+			configureMocks: func(t *testing.T, exec *mocks4.ExecutorMock) {
+				t.Helper()
+
+				exec.EXPECT().Execute(mock.Anything).Return(nil, heimdall.ErrAuthentication)
+			},
+			assert: func(t *testing.T, vars map[string]any) {
+				t.Helper()
+
+				assert.Equal(t, false, vars[varAllow])
+				assert.Equal(t, uint64(http.StatusUnauthorized), vars[varStatus])
+			},
+		},
+		"rule execution results in a redirect": {
+			args: map[string]any{"method": "GET", "path": "/foo"},
+			configureMocks: func(t *testing.T, exec *mocks4.ExecutorMock) {
+				t.Helper()
+
+				exec.EXPECT().Execute(mock.Anything).Return(nil, &heimdall.RedirectError{
+					Message:    "redirect",
+					Code:       http.StatusFound,
+					RedirectTo: "https://example.com/login",
+				})
+			},
+			assert: func(t *testing.T, vars map[string]any) {
+				t.Helper()
+
+				assert.Equal(t, false, vars[varAllow])
+				assert.Equal(t, uint64(http.StatusFound), vars[varStatus])
+				assert.Equal(t, "https://example.com/login", vars[varResponseHeader+"location"])
+			},
+		},
+		"spoofed X-Forwarded-* headers are ignored": {
+			args: map[string]any{
+				"method": "GET",
+				"path":   "/public",
+				"host":   "example.com",
+				"headers": encodeHeaders(
+					"x-forwarded-method", "DELETE",
+					"x-forwarded-uri", "/admin",
+					"x-forwarded-host", "admin.example.com",
+					"x-forwarded-proto", "https",
+				),
+			},
+			configureMocks: func(t *testing.T, exec *mocks4.ExecutorMock) {
+				t.Helper()
+
+				exec.EXPECT().Execute(
+					mock.MatchedBy(func(ctx heimdall.RequestContext) bool {
+						req := ctx.Request()
+
+						return req.Method == http.MethodGet &&
+							req.URL.String() == "http://example.com/public"
+					}),
+				).Return(nil, nil)
+			},
+			assert: func(t *testing.T, vars map[string]any) {
+				t.Helper()
+
+				assert.Equal(t, true, vars[varAllow])
+			},
+		},
+		"forwarded headers are accepted from trusted HAProxy": {
+			args: map[string]any{
+				"method":  "GET",
+				"path":    "/foo",
+				"ip":      net.ParseIP("10.10.10.10").To4(),
+				"headers": encodeHeaders("host", "example.com", "x-forwarded-for", "192.168.1.1"),
+			},
+			configureMocks: func(t *testing.T, exec *mocks4.ExecutorMock) {
+				t.Helper()
+
+				exec.EXPECT().Execute(
+					mock.MatchedBy(func(ctx heimdall.RequestContext) bool {
+						return assert.Equal(t, []string{"192.168.1.1", "10.10.10.10"}, ctx.Request().ClientIPAddresses)
+					}),
+				).Return(nil, nil)
+			},
+			assert: func(t *testing.T, vars map[string]any) {
+				t.Helper()
+
+				assert.Equal(t, true, vars[varAllow])
+			},
+		},
+		"successful rule execution": {
+			args: map[string]any{
+				"method":  "POST",
+				"path":    "/foo/bar",
+				"query":   "baz=zab",
+				"ssl":     true,
+				"ip":      net.ParseIP("10.10.10.10").To4(),
+				"headers": encodeHeaders("host", "example.com", "cookie", "foo=bar", "content-type", "application/json"),
+				"body":    []byte(`{"foo":"bar"}`),
+			},
+			configureMocks: func(t *testing.T, exec *mocks4.ExecutorMock) {
+				t.Helper()
+
+				exec.EXPECT().Execute(
+					mock.MatchedBy(func(ctx heimdall.RequestContext) bool {
+						ctx.AddHeaderForUpstream("X-User-Id", "alice")
+						ctx.AddCookieForUpstream("session", "123")
+						ctx.AddQueryParameterForUpstream("tenant", "foo")
+						ctx.AddDynamicMetadata("subject", "alice")
+
+						req := ctx.Request()
+
+						return req.Method == http.MethodPost &&
+							req.URL.String() == "https://example.com/foo/bar?baz=zab" &&
+							req.ClientIPAddresses[0] == "10.10.10.10" &&
+							req.Cookie("foo") == "bar" &&
+							req.Body().(map[string]any)["foo"] == "bar"
+					}),
+				).Return(nil, nil)
+			},
+			assert: func(t *testing.T, vars map[string]any) {
+				t.Helper()
+
+				assert.Equal(t, true, vars[varAllow])
+				assert.Equal(t, uint64(http.StatusOK), vars[varStatus])
+				assert.Equal(t, "alice", vars[varUpstreamHeader+"x_user_id"])
+				assert.Contains(t, vars[varUpstreamHeader+"cookie"], "session=123")
+				assert.Contains(t, vars[varUpstreamHeader+"cookie"], "foo=bar")
+				assert.Equal(t, "foo", vars[varQueryParameter+"tenant"])
+				assert.Equal(t, "alice", vars[varMetadata+"subject"])
+			},
+		},
+	} {
+		t.Run(uc, func(t *testing.T) {
+			t.Parallel()
+
+			// GIVEN
+			exec := mocks4.NewExecutorMock(t)
+			tc.configureMocks(t, exec)
+
+			proxy := startServer(t, exec)
+			proxy.hello(false)
+
+			// WHEN
+			vars := proxy.notify(1, tc.args)
+
+			// THEN
+			tc.assert(t, vars)
+		})
+	}
+}
+
+func TestSPOETrustedProxiesAreVerifiedAgainstHAProxy(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	exec := mocks4.NewExecutorMock(t)
+	exec.EXPECT().Execute(
+		mock.MatchedBy(func(ctx heimdall.RequestContext) bool {
+			return assert.Equal(t, []string{"10.10.10.10"}, ctx.Request().ClientIPAddresses)
+		}),
+	).Return(nil, nil)
+
+	// the address of the client is trusted, but not the one of HAProxy
+	proxy := startServerWithTrustedProxies(t, exec, "10.0.0.0/8")
+	proxy.hello(false)
+
+	// WHEN
+	vars := proxy.notify(1, map[string]any{
+		"method":  "GET",
+		"path":    "/foo",
+		"ip":      net.ParseIP("10.10.10.10").To4(),
+		"headers": encodeHeaders("host", "example.com", "x-forwarded-for", "192.168.1.1"),
+	})
+
+	// THEN
+	assert.Equal(t, true, vars[varAllow])
+}
+
+func TestSPOEShutdown(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	srv := newService(&config.Configuration{}, mocks.NewCacheMock(t), log.Logger, mocks4.NewExecutorMock(t))
+
+	served := make(chan error, 1)
+
+	go func() { served <- srv.Serve(ln) }()
+
+	conn, err := net.Dial("tcp", ln.Addr().String())
+	require.NoError(t, err)
+
+	defer conn.Close()
+
+	proxy := &haproxy{t: t, conn: conn, rd: bufio.NewReader(conn)}
+	proxy.hello(false)
+
+	// WHEN
+	err = srv.Shutdown(t.Context())
+
+	// THEN
+	require.NoError(t, err)
+	require.ErrorIs(t, <-served, http.ErrServerClosed)
+
+	frm := proxy.receive()
+	require.Equal(t, frameTypeAgentDisconnect, frm.typ)
+}
+
+func TestSPOEShutdownCancelsInFlightMessages(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	started := make(chan struct{})
+	canceled := make(chan struct{})
+
+	exec := mocks4.NewExecutorMock(t)
+	exec.EXPECT().Execute(mock.Anything).Run(func(ctx heimdall.RequestContext) {
+		close(started)
+
+		<-ctx.Context().Done()
+
+		close(canceled)
+	}).Return(nil, context.Canceled)
+
+	srv := newService(&config.Configuration{}, mocks.NewCacheMock(t), log.Logger, exec)
+
+	go func() { _ = srv.Serve(ln) }()
+
+	conn, err := net.Dial("tcp", ln.Addr().String())
+	require.NoError(t, err)
+
+	defer conn.Close()
+
+	proxy := &haproxy{t: t, conn: conn, rd: bufio.NewReader(conn)}
+	proxy.hello(false)
+
+	payload := appendString(nil, "check-request")
+	payload = append(payload, 2)
+	payload = appendKV(payload, "method", "GET")
+	payload = appendKV(payload, "path", "/foo")
+
+	proxy.send(&frame{typ: frameTypeNotify, flags: frameFlagFin, streamID: 1, frameID: 1, payload: payload})
+
+	<-started
+
+	ctx, cancel := context.WithTimeout(t.Context(), 50*time.Millisecond)
+	defer cancel()
+
+	// WHEN
+	err = srv.Shutdown(ctx)
+
+	// THEN
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+
+	select {
+	case <-canceled:
+	case <-time.After(2 * time.Second):
+		t.Fatal("in-flight message processing has not been canceled")
+	}
+}
@@ -18,7 +18,11 @@ package heimdall
 
 import (
 	"errors"
+	"math"
+	"net/http"
 	"reflect"
+	"strconv"
+	"time"
 )
 
 var (
@@ -36,8 +40,53 @@ type RedirectError struct {
 	Message    string
 	Code       int
 	RedirectTo string
+	// Cookies holds the cookies to be set in the client along with the redirect.
+	Cookies []*http.Cookie
 }
 
 func (e *RedirectError) Error() string { return e.Message }
 
 func (e *RedirectError) Is(target error) bool { return reflect.TypeOf(e) == reflect.TypeOf(target) }
+
+// ResponseError is used to respond to the client with a custom response, like a problem
+// document or a branded error page, instead of the default error response.
+type ResponseError struct {
+	Message string
+	Code    int
+	Headers http.Header
+	Body    []byte
+}
+
+func (e *ResponseError) Error() string { return e.Message }
+
+func (e *ResponseError) Is(target error) bool { return reflect.TypeOf(e) == reflect.TypeOf(target) }
+
+type TooManyRequestsError struct {
+	Message    string
+	Limit      int
+	Remaining  int
+	Reset      time.Duration
+	RetryAfter time.Duration
+}
+
+func (e *TooManyRequestsError) Error() string { return e.Message }
+
+func (e *TooManyRequestsError) Is(target error) bool {
+	return reflect.TypeOf(e) == reflect.TypeOf(target)
+}
+
+// Headers returns the Retry-After and RateLimit-* headers describing the state of the limit.
+func (e *TooManyRequestsError) Headers() http.Header {
+	headers := make(http.Header, 4) //nolint:mnd
+
+	headers.Set("Retry-After", strconv.Itoa(toSeconds(e.RetryAfter)))
+	headers.Set("RateLimit-Limit", strconv.Itoa(e.Limit))
+	headers.Set("RateLimit-Remaining", strconv.Itoa(e.Remaining))
+	headers.Set("RateLimit-Reset", strconv.Itoa(toSeconds(e.Reset)))
+
+	return headers
+}
+
+func toSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
@@ -0,0 +1,284 @@
+// Copyright 2025 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package heimdall
+
+import (
+	"mime"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/goccy/go-json"
+	"github.com/vektah/gqlparser/v2/ast"
+	"github.com/vektah/gqlparser/v2/parser"
+
+	"github.com/dadrus/heimdall/internal/x/errorchain"
+)
+
+const graphQLMaxTokens = 15000
+
+// graphQLRequestParameters are the parameters of a GraphQL request. The presence of any of
+// these marks a request as a GraphQL request.
+var graphQLRequestParameters = []string{"query", "operationName", "variables", "extensions"} //nolint:gochecknoglobals
+
+// GraphQLOperation describes a GraphQL operation requested by the client.
+type GraphQLOperation struct {
+	// Name is the name of the operation. Is empty for anonymous operations.
+	Name string
+	// Type is the type of the operation, which is one of query, mutation or subscription.
+	// Is empty if the request is not a GraphQL request.
+	Type string
+	// Fields holds the names (not the aliases) of the top-level fields selected by the operation,
+	// including the fields selected via fragments.
+	Fields []string
+}
+
+type graphQLResult struct {
+	operations []GraphQLOperation
+	err        error
+}
+
+type graphQLPayload struct {
+	query         string
+	operationName string
+}
+
+// GraphQL returns the details about the GraphQL operation requested by the client. If the
+// request is not a GraphQL request, the zero value is returned. An error is returned if the
+// request looks like a GraphQL request, but cannot be interpreted, or if it is a batch of
+// multiple operations. Use GraphQLOperations for the latter case.
+func (r *Request) GraphQL() (GraphQLOperation, error) {
+	operations, err := r.GraphQLOperations()
+	if err != nil {
+		return GraphQLOperation{}, err
+	}
+
+	switch len(operations) {
+	case 0:
+		return GraphQLOperation{}, nil
+	case 1:
+		return operations[0], nil
+	default:
+		return GraphQLOperation{}, errorchain.NewWithMessagef(ErrArgument,
+			"request contains a batch of %d graphql operations", len(operations))
+	}
+}
+
+// GraphQLOperations returns the details about all GraphQL operations requested by the client.
+// That is a single operation for regular requests and all operations of a batch for batched
+// requests. If the request is not a GraphQL request, nil is returned. An error is returned if
+// the request looks like a GraphQL request, but cannot be interpreted. The result is computed
+// on the first call only.
+func (r *Request) GraphQLOperations() ([]GraphQLOperation, error) {
+	if r.graphQL == nil {
+		r.graphQL = r.parseGraphQL()
+	}
+
+	return r.graphQL.operations, r.graphQL.err
+}
+
+func (r *Request) parseGraphQL() *graphQLResult {
+	payloads, err := r.graphQLPayloads()
+	if err != nil {
+		return &graphQLResult{err: err}
+	}
+
+	operations := make([]GraphQLOperation, len(payloads))
+
+	for idx, payload := range payloads {
+		op, err := parseGraphQLOperation(payload)
+		if err != nil {
+			return &graphQLResult{err: err}
+		}
+
+		operations[idx] = op
+	}
+
+	return &graphQLResult{operations: operations}
+}
+
+func (r *Request) graphQLPayloads() ([]graphQLPayload, error) {
+	if r.RequestFunctions == nil {
+		return nil, nil
+	}
+
+	if r.Method == http.MethodGet {
+		if r.URL == nil {
+			return nil, nil
+		}
+
+		params := r.URL.Query()
+		if !slices.ContainsFunc(graphQLRequestParameters, params.Has) {
+			return nil, nil
+		}
+
+		if !params.Has("query") {
+			return nil, errMissingGraphQLQuery()
+		}
+
+		return []graphQLPayload{{query: params.Get("query"), operationName: params.Get("operationName")}}, nil
+	}
+
+	switch body := r.Body().(type) {
+	case map[string]any:
+		if !slices.ContainsFunc(graphQLRequestParameters, func(name string) bool {
+			_, ok := body[name]
+
+			return ok
+		}) {
+			return nil, nil
+		}
+
+		payload, err := newGraphQLPayload(body)
+		if err != nil {
+			return nil, err
+		}
+
+		return []graphQLPayload{payload}, nil
+	case string:
+		return r.graphQLPayloadsFromRawBody(body)
+	default:
+		return nil, nil
+	}
+}
+
+func (r *Request) graphQLPayloadsFromRawBody(body string) ([]graphQLPayload, error) {
+	if len(body) == 0 {
+		return nil, nil
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(r.Header("Content-Type"))
+
+	switch {
+	case mediaType == "application/graphql":
+		return []graphQLPayload{{query: body}}, nil
+	case mediaType == "application/json" || strings.HasSuffix(mediaType, "+json"):
+		// the body could not be decoded into an object, which is the case for batches,
+		// or if the body is malformed
+		var entries []map[string]any
+		if err := json.Unmarshal([]byte(body), &entries); err != nil {
+			return nil, errorchain.NewWithMessage(ErrArgument, "malformed graphql request body").
+				CausedBy(err)
+		}
+
+		if len(entries) == 0 {
+			return nil, errorchain.NewWithMessage(ErrArgument, "empty graphql batch")
+		}
+
+		payloads := make([]graphQLPayload, len(entries))
+
+		for idx, entry := range entries {
+			payload, err := newGraphQLPayload(entry)
+			if err != nil {
+				return nil, err
+			}
+
+			payloads[idx] = payload
+		}
+
+		return payloads, nil
+	default:
+		return nil, nil
+	}
+}
+
+func newGraphQLPayload(data map[string]any) (graphQLPayload, error) {
+	value, present := data["query"]
+	if !present {
+		return graphQLPayload{}, errMissingGraphQLQuery()
+	}
+
+	query, ok := value.(string)
+	if !ok {
+		return graphQLPayload{}, errorchain.NewWithMessage(ErrArgument, "graphql query is not a string")
+	}
+
+	var operationName string
+
+	switch value := data["operationName"].(type) {
+	case nil:
+	case string:
+		operationName = value
+	default:
+		return graphQLPayload{}, errorchain.NewWithMessage(ErrArgument, "graphql operation name is not a string")
+	}
+
+	return graphQLPayload{query: query, operationName: operationName}, nil
+}
+
+// errMissingGraphQLQuery is raised for e.g. persisted queries, which only reference the query
+// to execute, so that the requested operation cannot be determined.
+func errMissingGraphQLQuery() error {
+	return errorchain.NewWithMessage(ErrArgument, "graphql request does not contain a query")
+}
+
+func parseGraphQLOperation(payload graphQLPayload) (GraphQLOperation, error) {
+	doc, err := parser.ParseQueryWithTokenLimit(&ast.Source{Input: payload.query}, graphQLMaxTokens)
+	if err != nil {
+		return GraphQLOperation{}, errorchain.NewWithMessage(ErrArgument, "failed parsing graphql query").
+			CausedBy(err)
+	}
+
+	var op *ast.OperationDefinition
+
+	switch {
+	case len(payload.operationName) != 0:
+		op = doc.Operations.ForName(payload.operationName)
+		if op == nil {
+			return GraphQLOperation{}, errorchain.NewWithMessagef(ErrArgument,
+				"graphql operation '%s' is not defined", payload.operationName)
+		}
+	case len(doc.Operations) == 1:
+		op = doc.Operations[0]
+	default:
+		return GraphQLOperation{}, errorchain.NewWithMessagef(ErrArgument,
+			"graphql query defines %d operations, but no operation name is specified", len(doc.Operations))
+	}
+
+	return GraphQLOperation{
+		Name:   op.Name,
+		Type:   string(op.Operation),
+		Fields: collectGraphQLFields(doc, op.SelectionSet, nil, map[string]bool{}),
+	}, nil
+}
+
+func collectGraphQLFields(
+	doc *ast.QueryDocument, selections ast.SelectionSet, fields []string, visited map[string]bool,
+) []string {
+	for _, selection := range selections {
+		switch sel := selection.(type) {
+		case *ast.Field:
+			if !slices.Contains(fields, sel.Name) {
+				fields = append(fields, sel.Name)
+			}
+		case *ast.InlineFragment:
+			fields = collectGraphQLFields(doc, sel.SelectionSet, fields, visited)
+		case *ast.FragmentSpread:
+			if visited[sel.Name] {
+				continue
+			}
+
+			visited[sel.Name] = true
+
+			if fragment := doc.Fragments.ForName(sel.Name); fragment != nil {
+				fields = collectGraphQLFields(doc, fragment.SelectionSet, fields, visited)
+			}
+		}
+	}
+
+	return fields
+}
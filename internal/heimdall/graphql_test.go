@@ -0,0 +1,342 @@
+// Copyright 2025 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package heimdall
+
+import (
+	"crypto/x509"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dadrus/heimdall/internal/x"
+)
+
+type testRequestFunctions struct {
+	headers map[string]string
+	body    any
+	calls   *int
+}
+
+func (f testRequestFunctions) Header(name string) string  { return f.headers[name] }
+func (f testRequestFunctions) Cookie(_ string) string     { return "" }
+func (f testRequestFunctions) Headers() map[string]string { return f.headers }
+
+func (f testRequestFunctions) Body() any {
+	*f.calls++
+
+	return f.body
+}
+
+func (f testRequestFunctions) ClientCertificates() []*x509.Certificate { return nil }
+
+func TestRequestGraphQLOperations(t *testing.T) {
+	t.Parallel()
+
+	for uc, tc := range map[string]struct {
+		method  string
+		rawURL  string
+		headers map[string]string
+		body    any
+		assert  func(t *testing.T, ops []GraphQLOperation, err error)
+	}{
+		"no body": {
+			method: http.MethodPost,
+			body:   "",
+			assert: func(t *testing.T, ops []GraphQLOperation, err error) {
+				t.Helper()
+
+				require.NoError(t, err)
+				assert.Empty(t, ops)
+			},
+		},
+		"json body without graphql parameters": {
+			method: http.MethodPost,
+			body:   map[string]any{"foo": "bar"},
+			assert: func(t *testing.T, ops []GraphQLOperation, err error) {
+				t.Helper()
+
+				require.NoError(t, err)
+				assert.Empty(t, ops)
+			},
+		},
+		"text body not of graphql content type": {
+			method:  http.MethodPost,
+			headers: map[string]string{"Content-Type": "text/plain"},
+			body:    "{ user { id } }",
+			assert: func(t *testing.T, ops []GraphQLOperation, err error) {
+				t.Helper()
+
+				require.NoError(t, err)
+				assert.Empty(t, ops)
+			},
+		},
+		"get request without graphql parameters": {
+			method: http.MethodGet,
+			rawURL: "https://foo.bar/graphql?foo=bar",
+			assert: func(t *testing.T, ops []GraphQLOperation, err error) {
+				t.Helper()
+
+				require.NoError(t, err)
+				assert.Empty(t, ops)
+			},
+		},
+		"persisted query without query": {
+			method: http.MethodPost,
+			body: map[string]any{
+				"operationName": "Foo",
+				"extensions":    map[string]any{"persistedQuery": map[string]any{"sha256Hash": "abc"}},
+			},
+			assert: func(t *testing.T, _ []GraphQLOperation, err error) {
+				t.Helper()
+
+				require.ErrorIs(t, err, ErrArgument)
+				require.ErrorContains(t, err, "does not contain a query")
+			},
+		},
+		"query of wrong type": {
+			method: http.MethodPost,
+			body:   map[string]any{"query": 1},
+			assert: func(t *testing.T, _ []GraphQLOperation, err error) {
+				t.Helper()
+
+				require.ErrorIs(t, err, ErrArgument)
+				require.ErrorContains(t, err, "not a string")
+			},
+		},
+		"invalid query": {
+			method: http.MethodPost,
+			body:   map[string]any{"query": "query { user "},
+			assert: func(t *testing.T, _ []GraphQLOperation, err error) {
+				t.Helper()
+
+				require.ErrorIs(t, err, ErrArgument)
+				require.ErrorContains(t, err, "failed parsing")
+			},
+		},
+		"query exceeding the token limit": {
+			method: http.MethodPost,
+			body:   map[string]any{"query": "{ " + strings.Repeat("a ", graphQLMaxTokens) + "}"},
+			assert: func(t *testing.T, _ []GraphQLOperation, err error) {
+				t.Helper()
+
+				require.ErrorIs(t, err, ErrArgument)
+				require.ErrorContains(t, err, "failed parsing")
+			},
+		},
+		"multiple operations without operation name": {
+			method: http.MethodPost,
+			body:   map[string]any{"query": "query A { a } mutation B { b }"},
+			assert: func(t *testing.T, _ []GraphQLOperation, err error) {
+				t.Helper()
+
+				require.ErrorIs(t, err, ErrArgument)
+				require.ErrorContains(t, err, "defines 2 operations")
+			},
+		},
+		"unknown operation name": {
+			method: http.MethodPost,
+			body:   map[string]any{"query": "query A { a }", "operationName": "B"},
+			assert: func(t *testing.T, _ []GraphQLOperation, err error) {
+				t.Helper()
+
+				require.ErrorIs(t, err, ErrArgument)
+				require.ErrorContains(t, err, "'B' is not defined")
+			},
+		},
+		"malformed json body": {
+			method:  http.MethodPost,
+			headers: map[string]string{"Content-Type": "application/json"},
+			body:    `{"query": "mutation { deleteUser(id: 1) { id } }"`,
+			assert: func(t *testing.T, _ []GraphQLOperation, err error) {
+				t.Helper()
+
+				require.ErrorIs(t, err, ErrArgument)
+				require.ErrorContains(t, err, "malformed")
+			},
+		},
+		"anonymous query shorthand in json body": {
+			method: http.MethodPost,
+			body:   map[string]any{"query": "{ user { id } posts { title } }"},
+			assert: func(t *testing.T, ops []GraphQLOperation, err error) {
+				t.Helper()
+
+				require.NoError(t, err)
+				require.Len(t, ops, 1)
+				assert.Empty(t, ops[0].Name)
+				assert.Equal(t, "query", ops[0].Type)
+				assert.Equal(t, []string{"user", "posts"}, ops[0].Fields)
+			},
+		},
+		"selected mutation with aliases in json body": {
+			method: http.MethodPost,
+			body: map[string]any{
+				"query": `query GetUser { user { id } }
+mutation DeleteUser($id: ID!) { removed: deleteUser(id: $id) { id } again: deleteUser(id: $id) { id } }`,
+				"operationName": "DeleteUser",
+			},
+			assert: func(t *testing.T, ops []GraphQLOperation, err error) {
+				t.Helper()
+
+				require.NoError(t, err)
+				assert.Equal(t, []GraphQLOperation{
+					{Name: "DeleteUser", Type: "mutation", Fields: []string{"deleteUser"}},
+				}, ops)
+			},
+		},
+		"fields selected via fragments": {
+			method:  http.MethodPost,
+			headers: map[string]string{"Content-Type": "application/graphql; charset=utf-8"},
+			body: `subscription OnEvent { ...Events ... on Subscription { alerts { id } } }
+fragment Events on Subscription { events { id } ...More }
+fragment More on Subscription { logs { id } ...Events }`,
+			assert: func(t *testing.T, ops []GraphQLOperation, err error) {
+				t.Helper()
+
+				require.NoError(t, err)
+				assert.Equal(t, []GraphQLOperation{
+					{Name: "OnEvent", Type: "subscription", Fields: []string{"events", "logs", "alerts"}},
+				}, ops)
+			},
+		},
+		"batched request": {
+			method:  http.MethodPost,
+			headers: map[string]string{"Content-Type": "application/json"},
+			body: `[{"query": "query GetUser { user { id } }"},
+{"query": "mutation DeleteUser { deleteUser(id: 1) { id } }", "operationName": "DeleteUser"}]`,
+			assert: func(t *testing.T, ops []GraphQLOperation, err error) {
+				t.Helper()
+
+				require.NoError(t, err)
+				assert.Equal(t, []GraphQLOperation{
+					{Name: "GetUser", Type: "query", Fields: []string{"user"}},
+					{Name: "DeleteUser", Type: "mutation", Fields: []string{"deleteUser"}},
+				}, ops)
+			},
+		},
+		"batched request with an invalid entry": {
+			method:  http.MethodPost,
+			headers: map[string]string{"Content-Type": "application/json"},
+			body:    `[{"query": "query GetUser { user { id } }"}, {"query": "mutation { "}]`,
+			assert: func(t *testing.T, _ []GraphQLOperation, err error) {
+				t.Helper()
+
+				require.ErrorIs(t, err, ErrArgument)
+			},
+		},
+		"query in url": {
+			method: http.MethodGet,
+			rawURL: "https://foo.bar/graphql?query=query+Foo+%7B+user+%7B+id+%7D+%7D&operationName=Foo",
+			assert: func(t *testing.T, ops []GraphQLOperation, err error) {
+				t.Helper()
+
+				require.NoError(t, err)
+				assert.Equal(t, []GraphQLOperation{{Name: "Foo", Type: "query", Fields: []string{"user"}}}, ops)
+			},
+		},
+	} {
+		t.Run(uc, func(t *testing.T) {
+			t.Parallel()
+
+			rawURL := x.IfThenElse(len(tc.rawURL) != 0, tc.rawURL, "https://foo.bar/graphql")
+			reqURL, err := url.Parse(rawURL)
+			require.NoError(t, err)
+
+			calls := 0
+			req := &Request{
+				RequestFunctions: testRequestFunctions{headers: tc.headers, body: tc.body, calls: &calls},
+				Method:           tc.method,
+				URL:              &URL{URL: *reqURL},
+			}
+
+			ops, err := req.GraphQLOperations()
+			tc.assert(t, ops, err)
+
+			// the result is memoized
+			ops2, err2 := req.GraphQLOperations()
+			assert.Equal(t, ops, ops2)
+			assert.Equal(t, err, err2)
+			assert.LessOrEqual(t, calls, 1)
+		})
+	}
+}
+
+func TestRequestGraphQL(t *testing.T) {
+	t.Parallel()
+
+	for uc, tc := range map[string]struct {
+		body   any
+		assert func(t *testing.T, op GraphQLOperation, err error)
+	}{
+		"not a graphql request": {
+			body: map[string]any{"foo": "bar"},
+			assert: func(t *testing.T, op GraphQLOperation, err error) {
+				t.Helper()
+
+				require.NoError(t, err)
+				assert.Equal(t, GraphQLOperation{}, op)
+			},
+		},
+		"single operation": {
+			body: map[string]any{"query": "mutation Foo { deleteUser(id: 1) { id } }"},
+			assert: func(t *testing.T, op GraphQLOperation, err error) {
+				t.Helper()
+
+				require.NoError(t, err)
+				assert.Equal(t, GraphQLOperation{Name: "Foo", Type: "mutation", Fields: []string{"deleteUser"}}, op)
+			},
+		},
+		"batch with multiple operations": {
+			body: `[{"query": "query A { a }"}, {"query": "query B { b }"}]`,
+			assert: func(t *testing.T, _ GraphQLOperation, err error) {
+				t.Helper()
+
+				require.ErrorIs(t, err, ErrArgument)
+				require.ErrorContains(t, err, "batch of 2")
+			},
+		},
+		"unparsable request": {
+			body: map[string]any{"query": "query {"},
+			assert: func(t *testing.T, _ GraphQLOperation, err error) {
+				t.Helper()
+
+				require.ErrorIs(t, err, ErrArgument)
+			},
+		},
+	} {
+		t.Run(uc, func(t *testing.T) {
+			t.Parallel()
+
+			calls := 0
+			req := &Request{
+				RequestFunctions: testRequestFunctions{
+					headers: map[string]string{"Content-Type": "application/json"},
+					body:    tc.body,
+					calls:   &calls,
+				},
+				Method: http.MethodPost,
+				URL:    &URL{URL: url.URL{Scheme: "https", Host: "foo.bar", Path: "/graphql"}},
+			}
+
+			op, err := req.GraphQL()
+			tc.assert(t, op, err)
+		})
+	}
+}
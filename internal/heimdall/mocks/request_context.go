@@ -6,6 +6,8 @@ import (
 	context "context"
 
 	heimdall "github.com/dadrus/heimdall/internal/heimdall"
+	http "net/http"
+
 	mock "github.com/stretchr/testify/mock"
 )
 
@@ -22,6 +24,39 @@ func (_m *RequestContextMock) EXPECT() *RequestContextMock_Expecter {
 	return &RequestContextMock_Expecter{mock: &_m.Mock}
 }
 
+// AddCookieForClient provides a mock function with given fields: cookie
+func (_m *RequestContextMock) AddCookieForClient(cookie *http.Cookie) {
+	_m.Called(cookie)
+}
+
+// RequestContextMock_AddCookieForClient_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'AddCookieForClient'
+type RequestContextMock_AddCookieForClient_Call struct {
+	*mock.Call
+}
+
+// AddCookieForClient is a helper method to define mock.On call
+//   - cookie *http.Cookie
+func (_e *RequestContextMock_Expecter) AddCookieForClient(cookie interface{}) *RequestContextMock_AddCookieForClient_Call {
+	return &RequestContextMock_AddCookieForClient_Call{Call: _e.mock.On("AddCookieForClient", cookie)}
+}
+
+func (_c *RequestContextMock_AddCookieForClient_Call) Run(run func(cookie *http.Cookie)) *RequestContextMock_AddCookieForClient_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(*http.Cookie))
+	})
+	return _c
+}
+
+func (_c *RequestContextMock_AddCookieForClient_Call) Return() *RequestContextMock_AddCookieForClient_Call {
+	_c.Call.Return()
+	return _c
+}
+
+func (_c *RequestContextMock_AddCookieForClient_Call) RunAndReturn(run func(*http.Cookie)) *RequestContextMock_AddCookieForClient_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // AddCookieForUpstream provides a mock function with given fields: name, value
 func (_m *RequestContextMock) AddCookieForUpstream(name string, value string) {
 	_m.Called(name, value)
@@ -56,6 +91,40 @@ func (_c *RequestContextMock_AddCookieForUpstream_Call) RunAndReturn(run func(st
 	return _c
 }
 
+// AddDynamicMetadata provides a mock function with given fields: key, value
+func (_m *RequestContextMock) AddDynamicMetadata(key string, value string) {
+	_m.Called(key, value)
+}
+
+// RequestContextMock_AddDynamicMetadata_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'AddDynamicMetadata'
+type RequestContextMock_AddDynamicMetadata_Call struct {
+	*mock.Call
+}
+
+// AddDynamicMetadata is a helper method to define mock.On call
+//   - key string
+//   - value string
+func (_e *RequestContextMock_Expecter) AddDynamicMetadata(key interface{}, value interface{}) *RequestContextMock_AddDynamicMetadata_Call {
+	return &RequestContextMock_AddDynamicMetadata_Call{Call: _e.mock.On("AddDynamicMetadata", key, value)}
+}
+
+func (_c *RequestContextMock_AddDynamicMetadata_Call) Run(run func(key string, value string)) *RequestContextMock_AddDynamicMetadata_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *RequestContextMock_AddDynamicMetadata_Call) Return() *RequestContextMock_AddDynamicMetadata_Call {
+	_c.Call.Return()
+	return _c
+}
+
+func (_c *RequestContextMock_AddDynamicMetadata_Call) RunAndReturn(run func(string, string)) *RequestContextMock_AddDynamicMetadata_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // AddHeaderForUpstream provides a mock function with given fields: name, value
 func (_m *RequestContextMock) AddHeaderForUpstream(name string, value string) {
 	_m.Called(name, value)
@@ -90,6 +159,40 @@ func (_c *RequestContextMock_AddHeaderForUpstream_Call) RunAndReturn(run func(st
 	return _c
 }
 
+// AddQueryParameterForUpstream provides a mock function with given fields: name, value
+func (_m *RequestContextMock) AddQueryParameterForUpstream(name string, value string) {
+	_m.Called(name, value)
+}
+
+// RequestContextMock_AddQueryParameterForUpstream_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'AddQueryParameterForUpstream'
+type RequestContextMock_AddQueryParameterForUpstream_Call struct {
+	*mock.Call
+}
+
+// AddQueryParameterForUpstream is a helper method to define mock.On call
+//   - name string
+//   - value string
+func (_e *RequestContextMock_Expecter) AddQueryParameterForUpstream(name interface{}, value interface{}) *RequestContextMock_AddQueryParameterForUpstream_Call {
+	return &RequestContextMock_AddQueryParameterForUpstream_Call{Call: _e.mock.On("AddQueryParameterForUpstream", name, value)}
+}
+
+func (_c *RequestContextMock_AddQueryParameterForUpstream_Call) Run(run func(name string, value string)) *RequestContextMock_AddQueryParameterForUpstream_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *RequestContextMock_AddQueryParameterForUpstream_Call) Return() *RequestContextMock_AddQueryParameterForUpstream_Call {
+	_c.Call.Return()
+	return _c
+}
+
+func (_c *RequestContextMock_AddQueryParameterForUpstream_Call) RunAndReturn(run func(string, string)) *RequestContextMock_AddQueryParameterForUpstream_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // Context provides a mock function with given fields:
 func (_m *RequestContextMock) Context() context.Context {
 	ret := _m.Called()
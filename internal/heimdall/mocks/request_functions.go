@@ -2,7 +2,11 @@
 
 package mocks
 
-import mock "github.com/stretchr/testify/mock"
+import (
+	x509 "crypto/x509"
+
+	mock "github.com/stretchr/testify/mock"
+)
 
 // RequestFunctionsMock is an autogenerated mock type for the RequestFunctions type
 type RequestFunctionsMock struct {
@@ -64,6 +68,53 @@ func (_c *RequestFunctionsMock_Body_Call) RunAndReturn(run func() interface{}) *
 	return _c
 }
 
+// ClientCertificates provides a mock function with given fields:
+func (_m *RequestFunctionsMock) ClientCertificates() []*x509.Certificate {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for ClientCertificates")
+	}
+
+	var r0 []*x509.Certificate
+	if rf, ok := ret.Get(0).(func() []*x509.Certificate); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*x509.Certificate)
+		}
+	}
+
+	return r0
+}
+
+// RequestFunctionsMock_ClientCertificates_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ClientCertificates'
+type RequestFunctionsMock_ClientCertificates_Call struct {
+	*mock.Call
+}
+
+// ClientCertificates is a helper method to define mock.On call
+func (_e *RequestFunctionsMock_Expecter) ClientCertificates() *RequestFunctionsMock_ClientCertificates_Call {
+	return &RequestFunctionsMock_ClientCertificates_Call{Call: _e.mock.On("ClientCertificates")}
+}
+
+func (_c *RequestFunctionsMock_ClientCertificates_Call) Run(run func()) *RequestFunctionsMock_ClientCertificates_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run()
+	})
+	return _c
+}
+
+func (_c *RequestFunctionsMock_ClientCertificates_Call) Return(_a0 []*x509.Certificate) *RequestFunctionsMock_ClientCertificates_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *RequestFunctionsMock_ClientCertificates_Call) RunAndReturn(run func() []*x509.Certificate) *RequestFunctionsMock_ClientCertificates_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // Cookie provides a mock function with given fields: name
 func (_m *RequestFunctionsMock) Cookie(name string) string {
 	ret := _m.Called(name)
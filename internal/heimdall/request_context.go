@@ -18,6 +18,8 @@ package heimdall
 
 import (
 	"context"
+	"crypto/x509"
+	"net/http"
 	"net/url"
 )
 
@@ -28,6 +30,11 @@ type RequestContext interface {
 
 	AddHeaderForUpstream(name, value string)
 	AddCookieForUpstream(name, value string)
+	AddQueryParameterForUpstream(name, value string)
+	AddDynamicMetadata(key, value string)
+	// AddCookieForClient adds a cookie to be set in the client along with the response to the
+	// original request.
+	AddCookieForClient(cookie *http.Cookie)
 
 	Context() context.Context
 
@@ -43,6 +50,7 @@ type RequestFunctions interface {
 	Cookie(name string) string
 	Headers() map[string]string
 	Body() any
+	ClientCertificates() []*x509.Certificate
 }
 
 type URL struct {
@@ -57,4 +65,10 @@ type Request struct {
 	Method            string
 	URL               *URL
 	ClientIPAddresses []string
+	// Metadata holds the metadata the request has been sent with, like the dynamic and route
+	// metadata forwarded by envoy. Is only set if provided by the integrating system.
+	Metadata map[string]any
+
+	// graphQL caches the result of parsing the request as a GraphQL request
+	graphQL *graphQLResult
 }
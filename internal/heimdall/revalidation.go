@@ -0,0 +1,35 @@
+// Copyright 2025 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package heimdall
+
+import "context"
+
+type revalidationKey struct{}
+
+// WithRevalidation returns a copy of ctx marking the pipeline execution as a revalidation of a subject,
+// which has already been authenticated and authorized for the same request. Mechanisms with side effects,
+// like consuming a rate limit or remembering a proof of possession, must not repeat these on revalidation.
+func WithRevalidation(ctx context.Context) context.Context {
+	return context.WithValue(ctx, revalidationKey{}, true)
+}
+
+// IsRevalidation returns true if the pipeline is executed to revalidate a subject.
+func IsRevalidation(ctx context.Context) bool {
+	revalidation, _ := ctx.Value(revalidationKey{}).(bool)
+
+	return revalidation
+}
@@ -0,0 +1,37 @@
+// Copyright 2025 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package heimdall
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRevalidation(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	ctx := t.Context()
+
+	// WHEN
+	revalidationCtx := WithRevalidation(ctx)
+
+	// THEN
+	assert.False(t, IsRevalidation(ctx))
+	assert.True(t, IsRevalidation(revalidationCtx))
+}
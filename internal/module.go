@@ -31,6 +31,8 @@ import (
 	"github.com/dadrus/heimdall/internal/otel/metrics/certificate"
 	"github.com/dadrus/heimdall/internal/rules"
 	"github.com/dadrus/heimdall/internal/rules/mechanisms"
+	"github.com/dadrus/heimdall/internal/session"
+	sessionmodule "github.com/dadrus/heimdall/internal/session/module"
 	"github.com/dadrus/heimdall/internal/validation"
 	"github.com/dadrus/heimdall/internal/watcher"
 )
@@ -39,6 +41,7 @@ type appContext struct {
 	w   watcher.Watcher
 	khr keyholder.Registry
 	co  certificate.Observer
+	sm  session.Manager
 	v   validation.Validator
 	l   zerolog.Logger
 	c   *config.Configuration
@@ -47,6 +50,7 @@ type appContext struct {
 func (c *appContext) Watcher() watcher.Watcher                  { return c.w }
 func (c *appContext) KeyHolderRegistry() keyholder.Registry     { return c.khr }
 func (c *appContext) CertificateObserver() certificate.Observer { return c.co }
+func (c *appContext) SessionManager() session.Manager           { return c.sm }
 func (c *appContext) Validator() validation.Validator           { return c.v }
 func (c *appContext) Logger() zerolog.Logger                    { return c.l }
 func (c *appContext) Config() *config.Configuration             { return c.c }
@@ -54,10 +58,12 @@ func (c *appContext) Config() *config.Configuration             { return c.c }
 var Module = fx.Options( //nolint:gochecknoglobals
 	watcher.Module,
 	keyholder.Module,
+	sessionmodule.Module,
 	fx.Provide(func(
 		watcher watcher.Watcher,
 		khr keyholder.Registry,
 		observer certificate.Observer,
+		sm session.Manager,
 		validator validation.Validator,
 		logger zerolog.Logger,
 		conf *config.Configuration,
@@ -66,6 +72,7 @@ var Module = fx.Options( //nolint:gochecknoglobals
 			w:   watcher,
 			khr: khr,
 			co:  observer,
+			sm:  sm,
 			v:   validator,
 			l:   logger,
 			c:   conf,
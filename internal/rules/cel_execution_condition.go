@@ -55,6 +55,22 @@ func (c *celExecutionCondition) CanExecuteOnError(ctx heimdall.RequestContext, c
 	return true, nil
 }
 
+func (c *celExecutionCondition) CanExecuteOnResponse(
+	ctx heimdall.RequestContext,
+	sub *subject.Subject,
+	resp *heimdall.Response,
+) (bool, error) {
+	if err := c.e.Eval(map[string]any{"Request": ctx.Request(), "Subject": sub, "Response": resp}); err != nil {
+		if errors.Is(err, &cellib.EvalError{}) {
+			return false, nil
+		}
+
+		return false, err
+	}
+
+	return true, nil
+}
+
 func newCelExecutionCondition(expression string) (*celExecutionCondition, error) {
 	env, err := cel.NewEnv(cellib.Library())
 	if err != nil {
@@ -176,3 +176,52 @@ func TestCelExecutionConditionCanExecuteOnError(t *testing.T) {
 		})
 	}
 }
+
+func TestCelExecutionConditionCanExecuteOnResponse(t *testing.T) {
+	t.Parallel()
+
+	sub := &subject.Subject{ID: "foobar", Attributes: map[string]any{"labels": []string{"public"}}}
+
+	for uc, tc := range map[string]struct {
+		expression string
+		expected   bool
+	}{
+		"expression on response status code evaluating to true": {
+			expression: `Response.StatusCode == 200`,
+			expected:   true,
+		},
+		"expression on response header evaluating to false": {
+			expression: `Response.Header("X-Label") in Subject.Attributes.labels`,
+			expected:   false,
+		},
+		"expression on request and subject evaluating to true": {
+			expression: `Subject.ID == "foobar" && Request.Method == "GET"`,
+			expected:   true,
+		},
+	} {
+		t.Run(uc, func(t *testing.T) {
+			// GIVEN
+			ctx := mocks.NewRequestContextMock(t)
+
+			ctx.EXPECT().Request().Return(&heimdall.Request{
+				Method: http.MethodGet,
+				URL:    &heimdall.URL{URL: url.URL{Scheme: "http", Host: "localhost", Path: "/test"}},
+			})
+
+			resp := &heimdall.Response{
+				StatusCode: http.StatusOK,
+				Header:     http.Header{"X-Label": []string{"internal"}},
+			}
+
+			condition, err := newCelExecutionCondition(tc.expression)
+			require.NoError(t, err)
+
+			// WHEN
+			can, err := condition.CanExecuteOnResponse(ctx, sub, resp)
+
+			// THEN
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, can)
+		})
+	}
+}
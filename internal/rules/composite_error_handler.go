@@ -22,16 +22,17 @@ import (
 	"github.com/rs/zerolog"
 
 	"github.com/dadrus/heimdall/internal/heimdall"
+	"github.com/dadrus/heimdall/internal/rules/mechanisms/subject"
 )
 
 type compositeErrorHandler []errorHandler
 
-func (eh compositeErrorHandler) Execute(ctx heimdall.RequestContext, exErr error) error {
+func (eh compositeErrorHandler) Execute(ctx heimdall.RequestContext, sub *subject.Subject, exErr error) error {
 	logger := zerolog.Ctx(ctx.Context())
 	logger.Debug().Msg("Handling pipeline error")
 
 	for _, handler := range eh {
-		if err := handler.Execute(ctx, exErr); err != nil {
+		if err := handler.Execute(ctx, sub, exErr); err != nil {
 			if errors.Is(err, errErrorHandlerNotApplicable) {
 				continue
 			}
@@ -23,6 +23,7 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/dadrus/heimdall/internal/heimdall/mocks"
+	"github.com/dadrus/heimdall/internal/rules/mechanisms/subject"
 	rulemocks "github.com/dadrus/heimdall/internal/rules/mocks"
 )
 
@@ -31,20 +32,21 @@ func TestCompositeErrorHandlerExecutionWithFallback(t *testing.T) {
 
 	// GIVEN
 	testErr := errors.New("test error")
+	sub := &subject.Subject{ID: "foo"}
 
 	ctx := mocks.NewRequestContextMock(t)
 	ctx.EXPECT().Context().Return(t.Context())
 
 	eh1 := rulemocks.NewErrorHandlerMock(t)
-	eh1.EXPECT().Execute(ctx, testErr).Return(errErrorHandlerNotApplicable)
+	eh1.EXPECT().Execute(ctx, sub, testErr).Return(errErrorHandlerNotApplicable)
 
 	eh2 := rulemocks.NewErrorHandlerMock(t)
-	eh2.EXPECT().Execute(ctx, testErr).Return(nil)
+	eh2.EXPECT().Execute(ctx, sub, testErr).Return(nil)
 
 	eh := compositeErrorHandler{eh1, eh2}
 
 	// WHEN
-	err := eh.Execute(ctx, testErr)
+	err := eh.Execute(ctx, sub, testErr)
 
 	// THEN
 	require.NoError(t, err)
@@ -55,19 +57,20 @@ func TestCompositeErrorHandlerExecutionWithoutFallback(t *testing.T) {
 
 	// GIVEN
 	testErr := errors.New("test error")
+	sub := &subject.Subject{ID: "foo"}
 
 	ctx := mocks.NewRequestContextMock(t)
 	ctx.EXPECT().Context().Return(t.Context())
 
 	eh1 := rulemocks.NewErrorHandlerMock(t)
-	eh1.EXPECT().Execute(ctx, testErr).Return(nil)
+	eh1.EXPECT().Execute(ctx, sub, testErr).Return(nil)
 
 	eh2 := rulemocks.NewErrorHandlerMock(t)
 
 	eh := compositeErrorHandler{eh1, eh2}
 
 	// WHEN
-	err := eh.Execute(ctx, testErr)
+	err := eh.Execute(ctx, sub, testErr)
 
 	// THEN
 	require.NoError(t, err)
@@ -78,20 +81,21 @@ func TestCompositeErrorHandlerExecutionWithNoApplicableErrorHandler(t *testing.T
 
 	// GIVEN
 	testErr := errors.New("test error")
+	sub := &subject.Subject{ID: "foo"}
 
 	ctx := mocks.NewRequestContextMock(t)
 	ctx.EXPECT().Context().Return(t.Context())
 
 	eh1 := rulemocks.NewErrorHandlerMock(t)
-	eh1.EXPECT().Execute(ctx, testErr).Return(errErrorHandlerNotApplicable)
+	eh1.EXPECT().Execute(ctx, sub, testErr).Return(errErrorHandlerNotApplicable)
 
 	eh2 := rulemocks.NewErrorHandlerMock(t)
-	eh2.EXPECT().Execute(ctx, testErr).Return(errErrorHandlerNotApplicable)
+	eh2.EXPECT().Execute(ctx, sub, testErr).Return(errErrorHandlerNotApplicable)
 
 	eh := compositeErrorHandler{eh1, eh2}
 
 	// WHEN
-	err := eh.Execute(ctx, testErr)
+	err := eh.Execute(ctx, sub, testErr)
 
 	// THEN
 	require.Error(t, err)
@@ -0,0 +1,108 @@
+// Copyright 2025 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package rules
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dadrus/heimdall/internal/heimdall"
+	"github.com/dadrus/heimdall/internal/heimdall/mocks"
+	"github.com/dadrus/heimdall/internal/rules/mechanisms/subject"
+	rulemocks "github.com/dadrus/heimdall/internal/rules/mocks"
+)
+
+func TestCompositeResponseHandlerExecution(t *testing.T) {
+	t.Parallel()
+
+	for uc, tc := range map[string]struct {
+		configureMocks func(t *testing.T, ctx heimdall.RequestContext, first *rulemocks.ResponseHandlerMock,
+			second *rulemocks.ResponseHandlerMock, sub *subject.Subject, resp *heimdall.Response)
+		assert func(t *testing.T, err error)
+	}{
+		"All succeeded": {
+			configureMocks: func(t *testing.T, ctx heimdall.RequestContext, first *rulemocks.ResponseHandlerMock,
+				second *rulemocks.ResponseHandlerMock, sub *subject.Subject, resp *heimdall.Response,
+			) {
+				t.Helper()
+
+				first.EXPECT().Execute(ctx, sub, resp).Return(nil)
+				second.EXPECT().Execute(ctx, sub, resp).Return(nil)
+			},
+			assert: func(t *testing.T, err error) {
+				t.Helper()
+
+				require.NoError(t, err)
+			},
+		},
+		"First fails": {
+			configureMocks: func(t *testing.T, ctx heimdall.RequestContext, first *rulemocks.ResponseHandlerMock,
+				_ *rulemocks.ResponseHandlerMock, sub *subject.Subject, resp *heimdall.Response,
+			) {
+				t.Helper()
+
+				first.EXPECT().Execute(ctx, sub, resp).Return(errors.New("first fails"))
+			},
+			assert: func(t *testing.T, err error) {
+				t.Helper()
+
+				require.Error(t, err)
+				assert.Equal(t, "first fails", err.Error())
+			},
+		},
+		"Second fails": {
+			configureMocks: func(t *testing.T, ctx heimdall.RequestContext, first *rulemocks.ResponseHandlerMock,
+				second *rulemocks.ResponseHandlerMock, sub *subject.Subject, resp *heimdall.Response,
+			) {
+				t.Helper()
+
+				first.EXPECT().Execute(ctx, sub, resp).Return(nil)
+				second.EXPECT().Execute(ctx, sub, resp).Return(errors.New("second fails"))
+			},
+			assert: func(t *testing.T, err error) {
+				t.Helper()
+
+				require.Error(t, err)
+				assert.Equal(t, "second fails", err.Error())
+			},
+		},
+	} {
+		t.Run(uc, func(t *testing.T) {
+			// GIVEN
+			sub := &subject.Subject{ID: "foo"}
+			resp := &heimdall.Response{StatusCode: 200}
+
+			ctx := mocks.NewRequestContextMock(t)
+			ctx.EXPECT().Context().Return(t.Context())
+
+			handler1 := rulemocks.NewResponseHandlerMock(t)
+			handler2 := rulemocks.NewResponseHandlerMock(t)
+			tc.configureMocks(t, ctx, handler1, handler2, sub, resp)
+
+			handler := compositeResponseHandler{handler1, handler2}
+
+			// WHEN
+			err := handler.Execute(ctx, sub, resp)
+
+			// THEN
+			tc.assert(t, err)
+		})
+	}
+}
@@ -22,6 +22,7 @@ import (
 	"github.com/rs/zerolog"
 
 	"github.com/dadrus/heimdall/internal/heimdall"
+	"github.com/dadrus/heimdall/internal/rules/mechanisms/subject"
 )
 
 var errErrorHandlerNotApplicable = errors.New("error handler not applicable")
@@ -31,7 +32,7 @@ type conditionalErrorHandler struct {
 	c executionCondition
 }
 
-func (h *conditionalErrorHandler) Execute(ctx heimdall.RequestContext, causeErr error) error {
+func (h *conditionalErrorHandler) Execute(ctx heimdall.RequestContext, sub *subject.Subject, causeErr error) error {
 	logger := zerolog.Ctx(ctx.Context())
 
 	logger.Debug().Str("_id", h.h.ID()).Msg("Checking error handler execution condition")
@@ -39,7 +40,7 @@ func (h *conditionalErrorHandler) Execute(ctx heimdall.RequestContext, causeErr
 	if canExecute, err := h.c.CanExecuteOnError(ctx, causeErr); err != nil {
 		return err
 	} else if canExecute {
-		return h.h.Execute(ctx, causeErr)
+		return h.h.Execute(ctx, sub, causeErr)
 	}
 
 	logger.Debug().Str("_id", h.h.ID()).Msg("Error handler not applicable")
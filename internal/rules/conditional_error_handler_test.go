@@ -40,7 +40,7 @@ func TestConditionalErrorHandlerExecute(t *testing.T) {
 				t.Helper()
 
 				c.EXPECT().CanExecuteOnError(mock.Anything, mock.Anything).Return(true, nil)
-				h.EXPECT().Execute(mock.Anything, mock.Anything).Return(nil)
+				h.EXPECT().Execute(mock.Anything, mock.Anything, mock.Anything).Return(nil)
 				h.EXPECT().ID().Return("test")
 			},
 			assert: func(t *testing.T, err error) {
@@ -91,7 +91,7 @@ func TestConditionalErrorHandlerExecute(t *testing.T) {
 			tc.configureMocks(t, condition, handler)
 
 			// WHEN
-			err := decorator.Execute(ctx, errors.New("test error"))
+			err := decorator.Execute(ctx, nil, errors.New("test error"))
 
 			// THEN
 			tc.assert(t, err)
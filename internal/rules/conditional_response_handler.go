@@ -0,0 +1,51 @@
+// Copyright 2025 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package rules
+
+import (
+	"github.com/rs/zerolog"
+
+	"github.com/dadrus/heimdall/internal/heimdall"
+	"github.com/dadrus/heimdall/internal/rules/mechanisms/subject"
+)
+
+type conditionalResponseHandler struct {
+	h responseHandler
+	c executionCondition
+}
+
+func (h *conditionalResponseHandler) Execute(
+	ctx heimdall.RequestContext,
+	sub *subject.Subject,
+	resp *heimdall.Response,
+) error {
+	logger := zerolog.Ctx(ctx.Context())
+
+	logger.Debug().Str("_id", h.h.ID()).Msg("Checking response handler execution condition")
+
+	if canExecute, err := h.c.CanExecuteOnResponse(ctx, sub, resp); err != nil {
+		return err
+	} else if canExecute {
+		return h.h.Execute(ctx, sub, resp)
+	}
+
+	logger.Debug().Str("_id", h.h.ID()).Msg("Execution skipped")
+
+	return nil
+}
+
+func (h *conditionalResponseHandler) ID() string { return h.h.ID() }
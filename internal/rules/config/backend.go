@@ -18,14 +18,21 @@ package config
 
 import (
 	"net/url"
+	"slices"
 )
 
 type Backend struct {
-	Host              string       `json:"host"                yaml:"host"             validate:"required"` //nolint:tagalign,lll
-	ForwardHostHeader *bool        `json:"forward_host_header" yaml:"forward_host_header"`
-	URLRewriter       *URLRewriter `json:"rewrite"             yaml:"rewrite"          validate:"omitnil"` //nolint:tagalign,lll
+	Host              string         `json:"host"                yaml:"host"             validate:"required_without=Targets,excluded_with=Targets"` //nolint:tagalign,lll
+	Targets           []Target       `json:"targets"             yaml:"targets"          validate:"omitempty,dive"`                                 //nolint:tagalign,lll
+	LoadBalancing     *LoadBalancing `json:"load_balancing"      yaml:"load_balancing"   validate:"omitnil"`                                        //nolint:tagalign,lll
+	ForwardHostHeader *bool          `json:"forward_host_header" yaml:"forward_host_header"`
+	URLRewriter       *URLRewriter   `json:"rewrite"             yaml:"rewrite"          validate:"omitnil"` //nolint:tagalign,lll
+	Upgrade           *Upgrade       `json:"upgrade"             yaml:"upgrade"          validate:"omitnil"` //nolint:tagalign,lll
+	Transport         *Transport     `json:"transport"           yaml:"transport"        validate:"omitnil"` //nolint:tagalign,lll
 }
 
+// CreateURL creates the URL to forward the request to. If multiple targets are configured,
+// the host part is left empty and is set to the selected target while forwarding the request.
 func (b *Backend) CreateURL(value *url.URL) *url.URL {
 	upstreamURL := &url.URL{
 		Scheme:   value.Scheme,
@@ -54,6 +61,31 @@ func (b *Backend) DeepCopyInto(out *Backend) {
 	if b.URLRewriter != nil {
 		b.URLRewriter.DeepCopyInto(out.URLRewriter)
 	}
+
+	if b.Targets != nil {
+		out.Targets = slices.Clone(b.Targets)
+	}
+
+	if b.LoadBalancing != nil {
+		in, out := b.LoadBalancing, &out.LoadBalancing
+
+		*out = new(LoadBalancing)
+		in.DeepCopyInto(*out)
+	}
+
+	if b.Upgrade != nil {
+		in, out := b.Upgrade, &out.Upgrade
+
+		*out = new(Upgrade)
+		in.DeepCopyInto(*out)
+	}
+
+	if b.Transport != nil {
+		in, out := b.Transport, &out.Transport
+
+		*out = new(Transport)
+		in.DeepCopyInto(*out)
+	}
 }
 
 func (b *Backend) IsInsecure() bool {
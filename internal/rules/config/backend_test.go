@@ -19,6 +19,7 @@ package config
 import (
 	"net/url"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -79,6 +80,21 @@ func TestBackendDeepCopyInto(t *testing.T) {
 			PathPrefixToAdd:     "/baz",
 			QueryParamsToRemove: QueryParamsRemover{"foo", "bar"},
 		},
+		Upgrade: &Upgrade{
+			Protocols:   []string{UpgradeProtocolWebSocket},
+			IdleTimeout: time.Minute,
+		},
+		Targets: []Target{{Host: "foo.bar", Weight: 2}},
+		LoadBalancing: &LoadBalancing{
+			Strategy:         LoadBalancingRoundRobin,
+			HealthCheck:      &HealthCheck{Path: "/health"},
+			OutlierDetection: &OutlierDetection{ConsecutiveFailures: 2},
+		},
+		Transport: &Transport{
+			Timeouts: &TransportTimeouts{Connect: time.Second},
+			TLS:      &UpstreamTLS{TrustStore: &TrustStore{Path: "/foo.pem"}},
+			Retry:    &RetryPolicy{Attempts: 1, StatusCodes: []int{503}},
+		},
 	}
 
 	// WHEN
@@ -86,6 +102,15 @@ func TestBackendDeepCopyInto(t *testing.T) {
 
 	// THEN
 	require.Equal(t, in, out)
+	assert.NotSame(t, in.Upgrade, out.Upgrade)
+	assert.NotSame(t, in.LoadBalancing, out.LoadBalancing)
+	assert.NotSame(t, in.LoadBalancing.HealthCheck, out.LoadBalancing.HealthCheck)
+	assert.NotSame(t, in.LoadBalancing.OutlierDetection, out.LoadBalancing.OutlierDetection)
+	assert.NotSame(t, &in.Targets[0], &out.Targets[0])
+	assert.NotSame(t, in.Transport, out.Transport)
+	assert.NotSame(t, in.Transport.Timeouts, out.Transport.Timeouts)
+	assert.NotSame(t, in.Transport.TLS.TrustStore, out.Transport.TLS.TrustStore)
+	assert.NotSame(t, &in.Transport.Retry.StatusCodes[0], &out.Transport.Retry.StatusCodes[0])
 }
 
 func TestBackendIsInsecure(t *testing.T) {
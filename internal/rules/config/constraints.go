@@ -0,0 +1,45 @@
+// Copyright 2025 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package config
+
+import (
+	"slices"
+
+	"github.com/inhies/go-bytesize"
+)
+
+type Constraints struct {
+	MaxBodySize     bytesize.ByteSize          `json:"max_body_size"    yaml:"max_body_size"`
+	ContentTypes    []string                   `json:"content_types"    yaml:"content_types"    validate:"omitempty,dive,required"` //nolint:lll,tagalign
+	RequiredHeaders []string                   `json:"required_headers" yaml:"required_headers" validate:"omitempty,dive,required"` //nolint:lll,tagalign
+	QueryParams     []QueryParameterConstraint `json:"query_params"     yaml:"query_params"     validate:"omitempty,dive"`          //nolint:lll,tagalign
+}
+
+type QueryParameterConstraint struct {
+	Name     string `json:"name"     yaml:"name"     validate:"required"`                         //nolint:tagalign
+	Required bool   `json:"required" yaml:"required"`                                             //nolint:tagalign
+	Type     string `json:"type"     yaml:"type"     validate:"omitempty,oneof=exact glob regex"` //nolint:tagalign
+	Value    string `json:"value"    yaml:"value"    validate:"required_with=Type"`               //nolint:tagalign
+}
+
+func (c *Constraints) DeepCopyInto(out *Constraints) {
+	*out = *c
+
+	out.ContentTypes = slices.Clone(c.ContentTypes)
+	out.RequiredHeaders = slices.Clone(c.RequiredHeaders)
+	out.QueryParams = slices.Clone(c.QueryParams)
+}
@@ -0,0 +1,91 @@
+// Copyright 2025 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package config
+
+import (
+	"reflect"
+	"time"
+
+	"github.com/goccy/go-json"
+
+	"github.com/dadrus/heimdall/internal/heimdall"
+	"github.com/dadrus/heimdall/internal/x/errorchain"
+)
+
+// duration allows durations to be specified in JSON the same way as in YAML, like 5m.
+type duration time.Duration
+
+func (d *duration) UnmarshalJSON(data []byte) error {
+	var value any
+	if err := json.Unmarshal(data, &value); err != nil {
+		return err
+	}
+
+	switch val := value.(type) {
+	case float64:
+		*d = duration(val)
+	case string:
+		parsed, err := time.ParseDuration(val)
+		if err != nil {
+			return err
+		}
+
+		*d = duration(parsed)
+	case nil:
+		*d = 0
+	default:
+		return errorchain.NewWithMessagef(heimdall.ErrConfiguration, "unexpected duration value %s", data)
+	}
+
+	return nil
+}
+
+func durationString(value time.Duration) string {
+	if value == 0 {
+		return ""
+	}
+
+	return value.String()
+}
+
+// unmarshalJSONWithDurations decodes the given JSON data into target, which must be a pointer
+// to a struct. It is required as rules are also loaded from JSON, e.g. by the kubernetes provider,
+// and the time.Duration fields of target shall be specified the same way as in YAML, like 5s.
+func unmarshalJSONWithDurations(data []byte, target any) error {
+	value := reflect.ValueOf(target).Elem()
+	typ := value.Type()
+
+	fields := make([]reflect.StructField, typ.NumField())
+	for idx := range fields {
+		fields[idx] = typ.Field(idx)
+		if fields[idx].Type == reflect.TypeFor[time.Duration]() {
+			fields[idx].Type = reflect.TypeFor[duration]()
+		}
+	}
+
+	// the created type does not have the UnmarshalJSON method of target
+	raw := reflect.New(reflect.StructOf(fields)).Elem()
+	if err := json.Unmarshal(data, raw.Addr().Interface()); err != nil {
+		return err
+	}
+
+	for idx := range fields {
+		value.Field(idx).Set(raw.Field(idx).Convert(typ.Field(idx).Type))
+	}
+
+	return nil
+}
@@ -0,0 +1,116 @@
+// Copyright 2025 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package config
+
+import (
+	"time"
+
+	"github.com/goccy/go-json"
+)
+
+const (
+	LoadBalancingRoundRobin       = "round_robin"
+	LoadBalancingLeastConnections = "least_connections"
+	LoadBalancingConsistentHash   = "consistent_hash"
+)
+
+// Target is one of the instances of the upstream service, the requests can be forwarded to.
+type Target struct {
+	Host   string `json:"host"   yaml:"host"   validate:"required"`
+	Weight int    `json:"weight" yaml:"weight" validate:"gte=0"`
+}
+
+// LoadBalancing defines how the requests are distributed among the configured targets and how
+// unhealthy targets are detected. Used in proxy mode only.
+type LoadBalancing struct {
+	Strategy         string            `json:"strategy"          yaml:"strategy"          validate:"omitempty,oneof=round_robin least_connections consistent_hash"` //nolint:lll,tagalign
+	HashKey          string            `json:"hash_key"          yaml:"hash_key"          validate:"required_if=Strategy consistent_hash"`                          //nolint:lll,tagalign
+	HealthCheck      *HealthCheck      `json:"health_check"      yaml:"health_check"      validate:"omitnil"`                                                       //nolint:lll,tagalign
+	OutlierDetection *OutlierDetection `json:"outlier_detection" yaml:"outlier_detection" validate:"omitnil"`                                                       //nolint:lll,tagalign
+}
+
+func (l *LoadBalancing) DeepCopyInto(out *LoadBalancing) {
+	*out = *l
+
+	if l.HealthCheck != nil {
+		in, out := l.HealthCheck, &out.HealthCheck
+
+		*out = new(HealthCheck)
+		**out = *in
+	}
+
+	if l.OutlierDetection != nil {
+		in, out := l.OutlierDetection, &out.OutlierDetection
+
+		*out = new(OutlierDetection)
+		**out = *in
+	}
+}
+
+// HealthCheck configures the active health checking of the targets.
+type HealthCheck struct {
+	Path               string        `json:"path"                yaml:"path"                validate:"required,startswith=/"` //nolint:tagalign
+	Interval           time.Duration `json:"interval"            yaml:"interval"            validate:"gte=0"`                 //nolint:tagalign
+	Timeout            time.Duration `json:"timeout"             yaml:"timeout"             validate:"gte=0"`                 //nolint:tagalign
+	HealthyThreshold   int           `json:"healthy_threshold"   yaml:"healthy_threshold"   validate:"gte=0"`                 //nolint:tagalign
+	UnhealthyThreshold int           `json:"unhealthy_threshold" yaml:"unhealthy_threshold" validate:"gte=0"`                 //nolint:tagalign
+}
+
+func (h *HealthCheck) UnmarshalJSON(data []byte) error {
+	return unmarshalJSONWithDurations(data, h)
+}
+
+func (h HealthCheck) MarshalJSON() ([]byte, error) {
+	type raw struct {
+		Path               string `json:"path"`
+		Interval           string `json:"interval,omitempty"`
+		Timeout            string `json:"timeout,omitempty"`
+		HealthyThreshold   int    `json:"healthy_threshold,omitempty"`
+		UnhealthyThreshold int    `json:"unhealthy_threshold,omitempty"`
+	}
+
+	return json.Marshal(raw{
+		Path:               h.Path,
+		Interval:           durationString(h.Interval),
+		Timeout:            durationString(h.Timeout),
+		HealthyThreshold:   h.HealthyThreshold,
+		UnhealthyThreshold: h.UnhealthyThreshold,
+	})
+}
+
+// OutlierDetection configures the passive health checking of the targets based on the
+// outcome of the forwarded requests.
+type OutlierDetection struct {
+	ConsecutiveFailures int           `json:"consecutive_failures" yaml:"consecutive_failures" validate:"gte=0"`
+	EjectionDuration    time.Duration `json:"ejection_duration"    yaml:"ejection_duration"    validate:"gte=0"`
+}
+
+func (o *OutlierDetection) UnmarshalJSON(data []byte) error {
+	return unmarshalJSONWithDurations(data, o)
+}
+
+func (o OutlierDetection) MarshalJSON() ([]byte, error) {
+	type raw struct {
+		ConsecutiveFailures int    `json:"consecutive_failures,omitempty"`
+		EjectionDuration    string `json:"ejection_duration,omitempty"`
+	}
+
+	return json.Marshal(raw{
+		ConsecutiveFailures: o.ConsecutiveFailures,
+		EjectionDuration:    durationString(o.EjectionDuration),
+	})
+}
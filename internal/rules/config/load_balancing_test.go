@@ -0,0 +1,81 @@
+// Copyright 2025 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package config
+
+import (
+	"testing"
+	"time"
+
+	"github.com/goccy/go-json"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadBalancingJSONRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	data := `{
+  "strategy": "least_connections",
+  "health_check": { "path": "/health", "interval": "5s", "timeout": 1000000000, "unhealthy_threshold": 3 },
+  "outlier_detection": { "consecutive_failures": 2, "ejection_duration": "1m" }
+}`
+
+	var lb LoadBalancing
+
+	// WHEN
+	err := json.Unmarshal([]byte(data), &lb)
+
+	// THEN
+	require.NoError(t, err)
+	assert.Equal(t, LoadBalancing{
+		Strategy: LoadBalancingLeastConnections,
+		HealthCheck: &HealthCheck{
+			Path:               "/health",
+			Interval:           5 * time.Second,
+			Timeout:            time.Second,
+			UnhealthyThreshold: 3,
+		},
+		OutlierDetection: &OutlierDetection{ConsecutiveFailures: 2, EjectionDuration: time.Minute},
+	}, lb)
+
+	// WHEN
+	marshalled, err := json.Marshal(lb)
+
+	// THEN
+	require.NoError(t, err)
+
+	var out LoadBalancing
+
+	require.NoError(t, json.Unmarshal(marshalled, &out))
+	assert.Equal(t, lb, out)
+}
+
+func TestLoadBalancingUnmarshalJSONWithMalformedDuration(t *testing.T) {
+	t.Parallel()
+
+	for uc, data := range map[string]string{
+		"health check":      `{"health_check": {"path": "/health", "interval": "foo"}}`,
+		"outlier detection": `{"outlier_detection": {"ejection_duration": true}}`,
+	} {
+		t.Run(uc, func(t *testing.T) {
+			var lb LoadBalancing
+
+			require.Error(t, json.Unmarshal([]byte(data), &lb))
+		})
+	}
+}
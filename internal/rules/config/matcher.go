@@ -19,11 +19,12 @@ package config
 import "slices"
 
 type Matcher struct {
-	Routes              []Route       `json:"routes"               yaml:"routes"               validate:"required,dive"`              //nolint:lll,tagalign
-	BacktrackingEnabled *bool         `json:"backtracking_enabled" yaml:"backtracking_enabled"`                                       //nolint:lll,tagalign
-	Scheme              string        `json:"scheme"               yaml:"scheme"               validate:"omitempty,oneof=http https"` //nolint:lll,tagalign
-	Methods             []string      `json:"methods"              yaml:"methods"              validate:"omitempty,dive,required"`    //nolint:lll,tagalign
-	Hosts               []HostMatcher `json:"hosts"                yaml:"hosts"                validate:"omitempty,dive,required"`    //nolint:lll,tagalign
+	Routes              []Route         `json:"routes"               yaml:"routes"               validate:"required,dive"`              //nolint:lll,tagalign
+	BacktrackingEnabled *bool           `json:"backtracking_enabled" yaml:"backtracking_enabled"`                                       //nolint:lll,tagalign
+	Scheme              string          `json:"scheme"               yaml:"scheme"               validate:"omitempty,oneof=http https"` //nolint:lll,tagalign
+	Methods             []string        `json:"methods"              yaml:"methods"              validate:"omitempty,dive,required"`    //nolint:lll,tagalign
+	Hosts               []HostMatcher   `json:"hosts"                yaml:"hosts"                validate:"omitempty,dive,required"`    //nolint:lll,tagalign
+	GraphQL             *GraphQLMatcher `json:"graphql"              yaml:"graphql"`                                                    //nolint:lll,tagalign
 }
 
 type Route struct {
@@ -48,6 +49,16 @@ type HostMatcher struct {
 	Type  string `json:"type"  yaml:"type"  validate:"required,oneof=exact glob regex"` //nolint:tagalign
 }
 
+type GraphQLMatcher struct {
+	OperationTypes []string `json:"operation_types" yaml:"operation_types" validate:"omitempty,dive,oneof=query mutation subscription"` //nolint:lll,tagalign
+	OperationNames []string `json:"operation_names" yaml:"operation_names" validate:"omitempty,dive,required"`                          //nolint:lll,tagalign
+}
+
+func (m *GraphQLMatcher) DeepCopyInto(out *GraphQLMatcher) {
+	out.OperationTypes = slices.Clone(m.OperationTypes)
+	out.OperationNames = slices.Clone(m.OperationNames)
+}
+
 func (m *Matcher) DeepCopyInto(out *Matcher) {
 	var withBacktracking *bool
 
@@ -61,6 +72,11 @@ func (m *Matcher) DeepCopyInto(out *Matcher) {
 	out.Methods = slices.Clone(m.Methods)
 	out.Hosts = slices.Clone(m.Hosts)
 
+	if m.GraphQL != nil {
+		out.GraphQL = new(GraphQLMatcher)
+		m.GraphQL.DeepCopyInto(out.GraphQL)
+	}
+
 	out.Routes = make([]Route, len(m.Routes))
 	for i, route := range m.Routes {
 		route.DeepCopyInto(&out.Routes[i])
@@ -65,6 +65,14 @@ func TestMatcherDeepCopyInto(t *testing.T) {
 			},
 			Methods: []string{"GET", "POST"},
 		},
+		"single route with graphql constraints": {
+			Routes:  []Route{{Path: "/graphql"}},
+			Methods: []string{"POST"},
+			GraphQL: &GraphQLMatcher{
+				OperationTypes: []string{"query"},
+				OperationNames: []string{"GetUser", "ListUsers"},
+			},
+		},
 	} {
 		t.Run(uc, func(t *testing.T) {
 			out := new(Matcher)
@@ -19,7 +19,9 @@ package config
 import (
 	"bytes"
 	"testing"
+	"time"
 
+	"github.com/inhies/go-bytesize"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
@@ -276,6 +278,74 @@ rules:
 				assert.Equal(t, "test", rul.Execute[0]["authenticator"])
 			},
 		},
+		"yaml rule set with constraints": {
+			contentType: "application/yaml",
+			content: []byte(`
+version: "1"
+name: foo
+rules:
+- id: bar
+  match:
+    routes:
+      - path: /foo
+  constraints:
+    max_body_size: 1MB
+    content_types:
+      - application/json
+    required_headers:
+      - X-Request-ID
+    query_params:
+      - name: page
+        required: true
+        type: regex
+        value: "^[0-9]+$"
+  execute:
+    - authenticator: test
+`),
+			assert: func(t *testing.T, err error, ruleSet *RuleSet) {
+				t.Helper()
+
+				require.NoError(t, err)
+				require.NotNil(t, ruleSet)
+				assert.Len(t, ruleSet.Rules, 1)
+
+				constraints := ruleSet.Rules[0].Constraints
+				require.NotNil(t, constraints)
+				assert.Equal(t, bytesize.MB, constraints.MaxBodySize)
+				assert.Equal(t, []string{"application/json"}, constraints.ContentTypes)
+				assert.Equal(t, []string{"X-Request-ID"}, constraints.RequiredHeaders)
+				assert.Equal(t, []QueryParameterConstraint{
+					{Name: "page", Required: true, Type: "regex", Value: "^[0-9]+$"},
+				}, constraints.QueryParams)
+			},
+		},
+		"yaml rule set with invalid query parameter constraint": {
+			contentType: "application/yaml",
+			content: []byte(`
+version: "1"
+name: foo
+rules:
+- id: bar
+  match:
+    routes:
+      - path: /foo
+  constraints:
+    query_params:
+      - name: page
+        type: foo
+  execute:
+    - authenticator: test
+`),
+			assert: func(t *testing.T, err error, ruleSet *RuleSet) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrConfiguration)
+				require.ErrorContains(t, err, "'rules'[0].'constraints'.'query_params'[0].'type' must be one of [exact glob regex]")
+				require.ErrorContains(t, err, "'rules'[0].'constraints'.'query_params'[0].'value'")
+				require.Nil(t, ruleSet)
+			},
+		},
 		"yaml content type and validation error due to missing properties": {
 			contentType: "application/yaml",
 			content: []byte(`
@@ -553,6 +623,253 @@ rules:
 				assert.Equal(t, "http", urlRewriter.Scheme)
 			},
 		},
+		"ruleset with unsupported upgrade protocol": {
+			conf: []byte(`
+version: "1"
+name: foo
+rules:
+- id: bar
+  match:
+    routes:
+      - path: foo
+  forward_to:
+    host: foo.bar
+    upgrade:
+      protocols: [ foo ]
+  execute:
+    - authenticator: test
+`),
+			assert: func(t *testing.T, err error, _ *RuleSet) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrConfiguration)
+				require.ErrorContains(t, err, "'rules'[0].'forward_to'.'upgrade'.'protocols'[0] must be one of [websocket h2c]")
+			},
+		},
+		"ruleset with host and targets": {
+			conf: []byte(`
+version: "1"
+name: foo
+rules:
+- id: bar
+  match:
+    routes:
+      - path: foo
+  forward_to:
+    host: foo.bar
+    targets:
+      - host: bar.foo
+  execute:
+    - authenticator: test
+`),
+			assert: func(t *testing.T, err error, _ *RuleSet) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrConfiguration)
+				require.ErrorContains(t, err, "'rules'[0].'forward_to'.'host'")
+			},
+		},
+		"ruleset with consistent hash load balancing without hash key": {
+			conf: []byte(`
+version: "1"
+name: foo
+rules:
+- id: bar
+  match:
+    routes:
+      - path: foo
+  forward_to:
+    targets:
+      - host: foo.bar
+      - host: bar.foo
+    load_balancing:
+      strategy: consistent_hash
+  execute:
+    - authenticator: test
+`),
+			assert: func(t *testing.T, err error, _ *RuleSet) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrConfiguration)
+				require.ErrorContains(t, err, "'rules'[0].'forward_to'.'load_balancing'.'hash_key'")
+			},
+		},
+		"valid ruleset with load balanced targets": {
+			conf: []byte(`
+version: "1"
+name: foo
+rules:
+- id: bar
+  match:
+    routes:
+      - path: foo
+  forward_to:
+    targets:
+      - host: foo.bar:8080
+        weight: 2
+      - host: bar.foo:8080
+    load_balancing:
+      strategy: consistent_hash
+      hash_key: "{{ .Subject.ID }}"
+      health_check:
+        path: /health
+        interval: 5s
+      outlier_detection:
+        consecutive_failures: 3
+        ejection_duration: 1m
+  execute:
+    - authenticator: test
+`),
+			assert: func(t *testing.T, err error, ruleSet *RuleSet) {
+				t.Helper()
+
+				require.NoError(t, err)
+				require.Len(t, ruleSet.Rules, 1)
+
+				be := ruleSet.Rules[0].Backend
+				require.NotNil(t, be)
+				assert.Empty(t, be.Host)
+				assert.Equal(t, []Target{{Host: "foo.bar:8080", Weight: 2}, {Host: "bar.foo:8080"}}, be.Targets)
+
+				lb := be.LoadBalancing
+				require.NotNil(t, lb)
+				assert.Equal(t, LoadBalancingConsistentHash, lb.Strategy)
+				assert.Equal(t, "{{ .Subject.ID }}", lb.HashKey)
+				assert.Equal(t, &HealthCheck{Path: "/health", Interval: 5 * time.Second}, lb.HealthCheck)
+				assert.Equal(t, &OutlierDetection{ConsecutiveFailures: 3, EjectionDuration: time.Minute}, lb.OutlierDetection)
+			},
+		},
+		"valid ruleset with upgrade settings": {
+			conf: []byte(`
+version: "1"
+name: foo
+rules:
+- id: bar
+  match:
+    routes:
+      - path: foo
+  forward_to:
+    host: foo.bar
+    upgrade:
+      protocols: [ websocket, h2c ]
+      idle_timeout: 5m
+      max_lifetime: 1h
+      revalidation_interval: 30s
+  execute:
+    - authenticator: test
+`),
+			assert: func(t *testing.T, err error, ruleSet *RuleSet) {
+				t.Helper()
+
+				require.NoError(t, err)
+				require.Len(t, ruleSet.Rules, 1)
+
+				upgrade := ruleSet.Rules[0].Backend.Upgrade
+				require.NotNil(t, upgrade)
+				assert.Equal(t, []string{"websocket", "h2c"}, upgrade.Protocols)
+				assert.Equal(t, 5*time.Minute, upgrade.IdleTimeout)
+				assert.Equal(t, time.Hour, upgrade.MaxLifetime)
+				assert.Equal(t, 30*time.Second, upgrade.RevalidationInterval)
+			},
+		},
+		"ruleset with invalid retry status code": {
+			conf: []byte(`
+version: "1"
+name: foo
+rules:
+- id: bar
+  match:
+    routes:
+      - path: foo
+  forward_to:
+    host: foo.bar
+    transport:
+      retry:
+        status_codes: [ 404 ]
+  execute:
+    - authenticator: test
+`),
+			assert: func(t *testing.T, err error, _ *RuleSet) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrConfiguration)
+				require.ErrorContains(t, err, "'rules'[0].'forward_to'.'transport'.'retry'.'status_codes'[0]")
+			},
+		},
+		"ruleset with shadow pipeline without mechanisms": {
+			conf: []byte(`
+version: "1"
+name: foo
+rules:
+- id: bar
+  match:
+    routes:
+      - path: foo
+  execute:
+    - authenticator: test
+  shadow:
+    execute: []
+`),
+			assert: func(t *testing.T, err error, _ *RuleSet) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrConfiguration)
+				require.ErrorContains(t, err, "'rules'[0].'shadow'.'execute' must contain more than 0 items")
+			},
+		},
+		"valid ruleset with transport settings": {
+			conf: []byte(`
+version: "1"
+name: foo
+rules:
+- id: bar
+  match:
+    routes:
+      - path: foo
+  forward_to:
+    host: foo.bar
+    transport:
+      timeouts:
+        connect: 2s
+        response_header: 10s
+      connections_limit:
+        max_per_host: 20
+      tls:
+        key_store:
+          path: /etc/heimdall/keystore.pem
+        trust_store:
+          path: /etc/heimdall/truststore.pem
+      enforce_http2: true
+      retry:
+        attempts: 3
+        backoff: 50ms
+        status_codes: [ 502, 503 ]
+  execute:
+    - authenticator: test
+`),
+			assert: func(t *testing.T, err error, ruleSet *RuleSet) {
+				t.Helper()
+
+				require.NoError(t, err)
+				require.Len(t, ruleSet.Rules, 1)
+
+				tr := ruleSet.Rules[0].Backend.Transport
+				require.NotNil(t, tr)
+				assert.Equal(t, &TransportTimeouts{Connect: 2 * time.Second, ResponseHeader: 10 * time.Second}, tr.Timeouts)
+				assert.Equal(t, &ConnectionsLimit{MaxPerHost: 20}, tr.ConnectionsLimit)
+				assert.Equal(t, &UpstreamTLS{
+					KeyStore:   &KeyStore{Path: "/etc/heimdall/keystore.pem"},
+					TrustStore: &TrustStore{Path: "/etc/heimdall/truststore.pem"},
+				}, tr.TLS)
+				assert.True(t, tr.EnforceHTTP2)
+				assert.Equal(t, &RetryPolicy{Attempts: 3, Backoff: 50 * time.Millisecond, StatusCodes: []int{502, 503}}, tr.Retry)
+			},
+		},
 	} {
 		t.Run(uc, func(t *testing.T) {
 			// GIVEN
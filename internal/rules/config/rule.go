@@ -30,9 +30,12 @@ type Rule struct {
 	ID                     string                   `json:"id"                    yaml:"id"                    validate:"required"`                         //nolint:lll,tagalign
 	EncodedSlashesHandling EncodedSlashesHandling   `json:"allow_encoded_slashes" yaml:"allow_encoded_slashes" validate:"omitempty,oneof=off on no_decode"` //nolint:lll,tagalign
 	Matcher                Matcher                  `json:"match"                 yaml:"match"                 validate:"required"`                         //nolint:lll,tagalign
+	Constraints            *Constraints             `json:"constraints"           yaml:"constraints"           validate:"omitnil"`                          //nolint:lll,tagalign
 	Backend                *Backend                 `json:"forward_to"            yaml:"forward_to"            validate:"omitnil"`                          //nolint:lll,tagalign
 	Execute                []config.MechanismConfig `json:"execute"               yaml:"execute"               validate:"gt=0,dive,required"`               //nolint:lll,tagalign
 	ErrorHandler           []config.MechanismConfig `json:"on_error"              yaml:"on_error"`
+	ResponseHandler        []config.MechanismConfig `json:"on_response"           yaml:"on_response"`
+	Shadow                 *Shadow                  `json:"shadow"                yaml:"shadow"                validate:"omitnil"` //nolint:lll,tagalign
 }
 
 func (r *Rule) Hash() ([]byte, error) {
@@ -53,6 +56,13 @@ func (r *Rule) DeepCopyInto(out *Rule) {
 	inm, outm := &r.Matcher, &out.Matcher
 	inm.DeepCopyInto(outm)
 
+	if r.Constraints != nil {
+		in, out := r.Constraints, &out.Constraints
+
+		*out = new(Constraints)
+		in.DeepCopyInto(*out)
+	}
+
 	if r.Backend != nil {
 		in, out := r.Backend, out.Backend
 
@@ -76,6 +86,22 @@ func (r *Rule) DeepCopyInto(out *Rule) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+
+	if r.ResponseHandler != nil {
+		in, out := &r.ResponseHandler, &out.ResponseHandler
+
+		*out = make([]config.MechanismConfig, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+
+	if r.Shadow != nil {
+		in, out := r.Shadow, &out.Shadow
+
+		*out = new(Shadow)
+		in.DeepCopyInto(*out)
+	}
 }
 
 func (r *Rule) DeepCopy() *Rule {
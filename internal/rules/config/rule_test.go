@@ -58,6 +58,14 @@ func TestRuleConfigDeepCopyInto(t *testing.T) {
 			},
 			Methods: []string{"GET", "PATCH"},
 		},
+		Constraints: &Constraints{
+			MaxBodySize:     1024,
+			ContentTypes:    []string{"application/json"},
+			RequiredHeaders: []string{"X-Foo"},
+			QueryParams: []QueryParameterConstraint{
+				{Name: "page", Required: true, Type: "regex", Value: "^[0-9]+$"},
+			},
+		},
 		Backend: &Backend{
 			Host: "baz",
 			URLRewriter: &URLRewriter{
@@ -67,8 +75,10 @@ func TestRuleConfigDeepCopyInto(t *testing.T) {
 				QueryParamsToRemove: []string{"baz"},
 			},
 		},
-		Execute:      []config.MechanismConfig{{"foo": "bar"}},
-		ErrorHandler: []config.MechanismConfig{{"bar": "foo"}},
+		Execute:         []config.MechanismConfig{{"foo": "bar"}},
+		ErrorHandler:    []config.MechanismConfig{{"bar": "foo"}},
+		ResponseHandler: []config.MechanismConfig{{"baz": "foo"}},
+		Shadow:          &Shadow{Execute: []config.MechanismConfig{{"authorizer": "baz"}}},
 	}
 
 	// WHEN
@@ -76,6 +86,9 @@ func TestRuleConfigDeepCopyInto(t *testing.T) {
 
 	// THEN
 	assert.Equal(t, in, out)
+	assert.NotSame(t, in.Constraints, out.Constraints)
+	assert.NotSame(t, in.Shadow, out.Shadow)
+	assert.NotSame(t, &in.Shadow.Execute[0], &out.Shadow.Execute[0])
 }
 
 func TestRuleConfigDeepCopy(t *testing.T) {
@@ -106,6 +119,14 @@ func TestRuleConfigDeepCopy(t *testing.T) {
 			},
 			Methods: []string{"GET", "PATCH"},
 		},
+		Constraints: &Constraints{
+			MaxBodySize:     1024,
+			ContentTypes:    []string{"application/json"},
+			RequiredHeaders: []string{"X-Foo"},
+			QueryParams: []QueryParameterConstraint{
+				{Name: "page", Required: true, Type: "regex", Value: "^[0-9]+$"},
+			},
+		},
 		Backend: &Backend{
 			Host: "baz",
 			URLRewriter: &URLRewriter{
@@ -115,8 +136,9 @@ func TestRuleConfigDeepCopy(t *testing.T) {
 				QueryParamsToRemove: []string{"baz"},
 			},
 		},
-		Execute:      []config.MechanismConfig{{"foo": "bar"}},
-		ErrorHandler: []config.MechanismConfig{{"bar": "foo"}},
+		Execute:         []config.MechanismConfig{{"foo": "bar"}},
+		ErrorHandler:    []config.MechanismConfig{{"bar": "foo"}},
+		ResponseHandler: []config.MechanismConfig{{"baz": "foo"}},
 	}
 
 	// WHEN
@@ -0,0 +1,58 @@
+// Copyright 2025 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package config
+
+import (
+	"time"
+
+	"github.com/goccy/go-json"
+
+	"github.com/dadrus/heimdall/internal/config"
+)
+
+// Shadow defines a candidate pipeline, which is evaluated alongside the execute pipeline of
+// a rule. Its decision is only reported and never affects the handling of the request.
+type Shadow struct {
+	Execute []config.MechanismConfig `json:"execute" yaml:"execute" validate:"gt=0,dive,required"`
+	Timeout time.Duration            `json:"timeout" yaml:"timeout" validate:"gte=0"`
+}
+
+func (s *Shadow) UnmarshalJSON(data []byte) error {
+	return unmarshalJSONWithDurations(data, s)
+}
+
+func (s Shadow) MarshalJSON() ([]byte, error) {
+	type raw struct {
+		Execute []config.MechanismConfig `json:"execute"`
+		Timeout string                   `json:"timeout,omitempty"`
+	}
+
+	return json.Marshal(raw{Execute: s.Execute, Timeout: durationString(s.Timeout)})
+}
+
+func (s *Shadow) DeepCopyInto(out *Shadow) {
+	*out = *s
+
+	if s.Execute != nil {
+		in, out := &s.Execute, &out.Execute
+
+		*out = make([]config.MechanismConfig, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
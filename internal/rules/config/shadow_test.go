@@ -0,0 +1,66 @@
+// Copyright 2025 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package config
+
+import (
+	"testing"
+	"time"
+
+	"github.com/goccy/go-json"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dadrus/heimdall/internal/config"
+)
+
+func TestShadowJSONRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	data := `{ "execute": [ { "authorizer": "foo" } ], "timeout": "2s" }`
+
+	var shadow Shadow
+
+	// WHEN
+	err := json.Unmarshal([]byte(data), &shadow)
+
+	// THEN
+	require.NoError(t, err)
+	assert.Equal(t, Shadow{
+		Execute: []config.MechanismConfig{{"authorizer": "foo"}},
+		Timeout: 2 * time.Second,
+	}, shadow)
+
+	// WHEN
+	marshalled, err := json.Marshal(shadow)
+
+	// THEN
+	require.NoError(t, err)
+
+	var out Shadow
+
+	require.NoError(t, json.Unmarshal(marshalled, &out))
+	assert.Equal(t, shadow, out)
+}
+
+func TestShadowUnmarshalJSONWithMalformedTimeout(t *testing.T) {
+	t.Parallel()
+
+	var shadow Shadow
+
+	require.Error(t, json.Unmarshal([]byte(`{"execute": [{"authorizer": "foo"}], "timeout": "foo"}`), &shadow))
+}
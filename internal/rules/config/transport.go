@@ -0,0 +1,164 @@
+// Copyright 2025 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package config
+
+import (
+	"slices"
+	"time"
+
+	"github.com/goccy/go-json"
+)
+
+// Transport configures the communication with the upstream service. Used in proxy mode only.
+type Transport struct {
+	Timeouts         *TransportTimeouts `json:"timeouts"          yaml:"timeouts"          validate:"omitnil"` //nolint:tagalign
+	ConnectionsLimit *ConnectionsLimit  `json:"connections_limit" yaml:"connections_limit" validate:"omitnil"` //nolint:tagalign
+	TLS              *UpstreamTLS       `json:"tls"               yaml:"tls"               validate:"omitnil"` //nolint:tagalign
+	EnforceHTTP2     bool               `json:"enforce_http2"     yaml:"enforce_http2"`                        //nolint:tagalign
+	Retry            *RetryPolicy       `json:"retry"             yaml:"retry"             validate:"omitnil"` //nolint:tagalign
+}
+
+func (t *Transport) DeepCopyInto(out *Transport) {
+	*out = *t
+
+	if t.Timeouts != nil {
+		in, out := t.Timeouts, &out.Timeouts
+
+		*out = new(TransportTimeouts)
+		**out = *in
+	}
+
+	if t.ConnectionsLimit != nil {
+		in, out := t.ConnectionsLimit, &out.ConnectionsLimit
+
+		*out = new(ConnectionsLimit)
+		**out = *in
+	}
+
+	if t.TLS != nil {
+		in, out := t.TLS, &out.TLS
+
+		*out = new(UpstreamTLS)
+		in.DeepCopyInto(*out)
+	}
+
+	if t.Retry != nil {
+		in, out := t.Retry, &out.Retry
+
+		*out = new(RetryPolicy)
+		in.DeepCopyInto(*out)
+	}
+}
+
+type TransportTimeouts struct {
+	Connect        time.Duration `json:"connect"         yaml:"connect"         validate:"gte=0"`
+	ResponseHeader time.Duration `json:"response_header" yaml:"response_header" validate:"gte=0"`
+	Idle           time.Duration `json:"idle"            yaml:"idle"            validate:"gte=0"`
+}
+
+func (t *TransportTimeouts) UnmarshalJSON(data []byte) error {
+	return unmarshalJSONWithDurations(data, t)
+}
+
+func (t TransportTimeouts) MarshalJSON() ([]byte, error) {
+	type raw struct {
+		Connect        string `json:"connect,omitempty"`
+		ResponseHeader string `json:"response_header,omitempty"`
+		Idle           string `json:"idle,omitempty"`
+	}
+
+	return json.Marshal(raw{
+		Connect:        durationString(t.Connect),
+		ResponseHeader: durationString(t.ResponseHeader),
+		Idle:           durationString(t.Idle),
+	})
+}
+
+type ConnectionsLimit struct {
+	MaxPerHost     int `json:"max_per_host"      yaml:"max_per_host"      validate:"gte=0"`
+	MaxIdle        int `json:"max_idle"          yaml:"max_idle"          validate:"gte=0"`
+	MaxIdlePerHost int `json:"max_idle_per_host" yaml:"max_idle_per_host" validate:"gte=0"`
+}
+
+// UpstreamTLS configures the TLS client side of the communication with the upstream service.
+type UpstreamTLS struct {
+	KeyStore   *KeyStore   `json:"key_store"   yaml:"key_store"   validate:"omitnil"`
+	KeyID      string      `json:"key_id"      yaml:"key_id"`
+	TrustStore *TrustStore `json:"trust_store" yaml:"trust_store" validate:"omitnil"`
+}
+
+func (t *UpstreamTLS) DeepCopyInto(out *UpstreamTLS) {
+	*out = *t
+
+	if t.KeyStore != nil {
+		in, out := t.KeyStore, &out.KeyStore
+
+		*out = new(KeyStore)
+		**out = *in
+	}
+
+	if t.TrustStore != nil {
+		in, out := t.TrustStore, &out.TrustStore
+
+		*out = new(TrustStore)
+		**out = *in
+	}
+}
+
+type KeyStore struct {
+	Path     string `json:"path"     yaml:"path"     validate:"required"`
+	Password string `json:"password" yaml:"password"`
+}
+
+type TrustStore struct {
+	Path string `json:"path" yaml:"path" validate:"required"`
+}
+
+// RetryPolicy configures the retries of requests, which failed due to communication errors,
+// or were answered with one of the configured status codes. Only requests with idempotent
+// methods and without a body are retried.
+type RetryPolicy struct {
+	Attempts    int           `json:"attempts"     yaml:"attempts"     validate:"gte=0"`
+	Backoff     time.Duration `json:"backoff"      yaml:"backoff"      validate:"gte=0"`
+	StatusCodes []int         `json:"status_codes" yaml:"status_codes" validate:"dive,gte=500,lte=599"`
+}
+
+func (r *RetryPolicy) DeepCopyInto(out *RetryPolicy) {
+	*out = *r
+
+	if r.StatusCodes != nil {
+		out.StatusCodes = slices.Clone(r.StatusCodes)
+	}
+}
+
+func (r *RetryPolicy) UnmarshalJSON(data []byte) error {
+	return unmarshalJSONWithDurations(data, r)
+}
+
+func (r RetryPolicy) MarshalJSON() ([]byte, error) {
+	type raw struct {
+		Attempts    int    `json:"attempts,omitempty"`
+		Backoff     string `json:"backoff,omitempty"`
+		StatusCodes []int  `json:"status_codes,omitempty"`
+	}
+
+	return json.Marshal(raw{
+		Attempts:    r.Attempts,
+		Backoff:     durationString(r.Backoff),
+		StatusCodes: r.StatusCodes,
+	})
+}
@@ -0,0 +1,87 @@
+// Copyright 2025 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package config
+
+import (
+	"testing"
+	"time"
+
+	"github.com/goccy/go-json"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTransportJSONRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	data := `{
+  "timeouts": { "connect": "1s", "response_header": "30s", "idle": 90000000000 },
+  "connections_limit": { "max_per_host": 10, "max_idle_per_host": 5 },
+  "tls": { "key_store": { "path": "/keystore.pem" }, "trust_store": { "path": "/truststore.pem" } },
+  "enforce_http2": true,
+  "retry": { "attempts": 3, "backoff": "50ms", "status_codes": [ 502, 503 ] }
+}`
+
+	var tr Transport
+
+	// WHEN
+	err := json.Unmarshal([]byte(data), &tr)
+
+	// THEN
+	require.NoError(t, err)
+	assert.Equal(t, Transport{
+		Timeouts: &TransportTimeouts{
+			Connect:        time.Second,
+			ResponseHeader: 30 * time.Second,
+			Idle:           90 * time.Second,
+		},
+		ConnectionsLimit: &ConnectionsLimit{MaxPerHost: 10, MaxIdlePerHost: 5},
+		TLS: &UpstreamTLS{
+			KeyStore:   &KeyStore{Path: "/keystore.pem"},
+			TrustStore: &TrustStore{Path: "/truststore.pem"},
+		},
+		EnforceHTTP2: true,
+		Retry:        &RetryPolicy{Attempts: 3, Backoff: 50 * time.Millisecond, StatusCodes: []int{502, 503}},
+	}, tr)
+
+	// WHEN
+	marshalled, err := json.Marshal(tr)
+
+	// THEN
+	require.NoError(t, err)
+
+	var out Transport
+
+	require.NoError(t, json.Unmarshal(marshalled, &out))
+	assert.Equal(t, tr, out)
+}
+
+func TestTransportUnmarshalJSONWithMalformedDuration(t *testing.T) {
+	t.Parallel()
+
+	for uc, data := range map[string]string{
+		"timeouts": `{"timeouts": {"connect": "foo"}}`,
+		"retry":    `{"retry": {"backoff": true}}`,
+	} {
+		t.Run(uc, func(t *testing.T) {
+			var tr Transport
+
+			require.Error(t, json.Unmarshal([]byte(data), &tr))
+		})
+	}
+}
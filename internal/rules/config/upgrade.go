@@ -0,0 +1,75 @@
+// Copyright 2025 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package config
+
+import (
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/goccy/go-json"
+)
+
+const (
+	UpgradeProtocolWebSocket = "websocket"
+	UpgradeProtocolH2C       = "h2c"
+)
+
+// Upgrade defines whether and how connections upgraded to another protocol, like WebSocket,
+// are proxied to the upstream service. Used in proxy mode only.
+type Upgrade struct {
+	Protocols            []string      `json:"protocols"             yaml:"protocols"             validate:"required,gt=0,dive,oneof=websocket h2c"` //nolint:lll,tagalign
+	IdleTimeout          time.Duration `json:"idle_timeout"          yaml:"idle_timeout"          validate:"gte=0"`                                  //nolint:lll,tagalign
+	MaxLifetime          time.Duration `json:"max_lifetime"          yaml:"max_lifetime"          validate:"gte=0"`                                  //nolint:lll,tagalign
+	RevalidationInterval time.Duration `json:"revalidation_interval" yaml:"revalidation_interval" validate:"gte=0"`                                  //nolint:lll,tagalign
+}
+
+// Allows reports whether the upgrade to the given protocol is allowed. If no upgrade settings are
+// configured, any upgrade is allowed and the upgraded connection is not supervised.
+func (u *Upgrade) Allows(protocol string) bool {
+	return u == nil || slices.ContainsFunc(u.Protocols, func(allowed string) bool {
+		return strings.EqualFold(allowed, protocol)
+	})
+}
+
+func (u *Upgrade) DeepCopyInto(out *Upgrade) {
+	*out = *u
+
+	if u.Protocols != nil {
+		out.Protocols = slices.Clone(u.Protocols)
+	}
+}
+
+func (u *Upgrade) UnmarshalJSON(data []byte) error {
+	return unmarshalJSONWithDurations(data, u)
+}
+
+func (u Upgrade) MarshalJSON() ([]byte, error) {
+	type raw struct {
+		Protocols            []string `json:"protocols"`
+		IdleTimeout          string   `json:"idle_timeout,omitempty"`
+		MaxLifetime          string   `json:"max_lifetime,omitempty"`
+		RevalidationInterval string   `json:"revalidation_interval,omitempty"`
+	}
+
+	return json.Marshal(raw{
+		Protocols:            u.Protocols,
+		IdleTimeout:          durationString(u.IdleTimeout),
+		MaxLifetime:          durationString(u.MaxLifetime),
+		RevalidationInterval: durationString(u.RevalidationInterval),
+	})
+}
@@ -0,0 +1,162 @@
+// Copyright 2025 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package config
+
+import (
+	"testing"
+	"time"
+
+	"github.com/goccy/go-json"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dadrus/heimdall/internal/heimdall"
+)
+
+func TestUpgradeAllows(t *testing.T) {
+	t.Parallel()
+
+	for uc, tc := range map[string]struct {
+		upgrade  *Upgrade
+		protocol string
+		allowed  bool
+	}{
+		"not configured": {
+			protocol: UpgradeProtocolH2C,
+			allowed:  true,
+		},
+		"configured protocol": {
+			upgrade:  &Upgrade{Protocols: []string{UpgradeProtocolWebSocket}},
+			protocol: "WebSocket",
+			allowed:  true,
+		},
+		"not configured protocol": {
+			upgrade:  &Upgrade{Protocols: []string{UpgradeProtocolWebSocket}},
+			protocol: UpgradeProtocolH2C,
+		},
+	} {
+		t.Run(uc, func(t *testing.T) {
+			assert.Equal(t, tc.allowed, tc.upgrade.Allows(tc.protocol))
+		})
+	}
+}
+
+func TestUpgradeDeepCopyInto(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	var out Upgrade
+
+	in := Upgrade{
+		Protocols:            []string{UpgradeProtocolWebSocket},
+		IdleTimeout:          time.Minute,
+		MaxLifetime:          time.Hour,
+		RevalidationInterval: time.Second,
+	}
+
+	// WHEN
+	in.DeepCopyInto(&out)
+
+	// THEN
+	require.Equal(t, in, out)
+
+	out.Protocols[0] = UpgradeProtocolH2C
+	assert.Equal(t, UpgradeProtocolWebSocket, in.Protocols[0])
+}
+
+func TestUpgradeUnmarshalJSON(t *testing.T) {
+	t.Parallel()
+
+	for uc, tc := range map[string]struct {
+		data   string
+		assert func(t *testing.T, err error, upgrade *Upgrade)
+	}{
+		"durations as strings": {
+			data: `{"protocols":["websocket"],"idle_timeout":"5m","max_lifetime":"1h","revalidation_interval":"30s"}`,
+			assert: func(t *testing.T, err error, upgrade *Upgrade) {
+				t.Helper()
+
+				require.NoError(t, err)
+				assert.Equal(t, []string{"websocket"}, upgrade.Protocols)
+				assert.Equal(t, 5*time.Minute, upgrade.IdleTimeout)
+				assert.Equal(t, time.Hour, upgrade.MaxLifetime)
+				assert.Equal(t, 30*time.Second, upgrade.RevalidationInterval)
+			},
+		},
+		"durations as numbers and null": {
+			data: `{"protocols":["h2c"],"idle_timeout":1000,"max_lifetime":null}`,
+			assert: func(t *testing.T, err error, upgrade *Upgrade) {
+				t.Helper()
+
+				require.NoError(t, err)
+				assert.Equal(t, time.Microsecond, upgrade.IdleTimeout)
+				assert.Zero(t, upgrade.MaxLifetime)
+				assert.Zero(t, upgrade.RevalidationInterval)
+			},
+		},
+		"malformed duration": {
+			data: `{"protocols":["h2c"],"idle_timeout":"foo"}`,
+			assert: func(t *testing.T, err error, _ *Upgrade) {
+				t.Helper()
+
+				require.Error(t, err)
+			},
+		},
+		"unexpected duration type": {
+			data: `{"protocols":["h2c"],"idle_timeout":true}`,
+			assert: func(t *testing.T, err error, _ *Upgrade) {
+				t.Helper()
+
+				require.ErrorIs(t, err, heimdall.ErrConfiguration)
+				require.ErrorContains(t, err, "unexpected duration value")
+			},
+		},
+	} {
+		t.Run(uc, func(t *testing.T) {
+			// GIVEN
+			var upgrade Upgrade
+
+			// WHEN
+			err := json.Unmarshal([]byte(tc.data), &upgrade)
+
+			// THEN
+			tc.assert(t, err, &upgrade)
+		})
+	}
+}
+
+func TestUpgradeMarshalJSON(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	in := Upgrade{
+		Protocols:   []string{UpgradeProtocolWebSocket},
+		MaxLifetime: 90 * time.Minute,
+	}
+
+	// WHEN
+	data, err := json.Marshal(in)
+
+	// THEN
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"protocols":["websocket"],"max_lifetime":"1h30m0s"}`, string(data))
+
+	var out Upgrade
+
+	require.NoError(t, json.Unmarshal(data, &out))
+	assert.Equal(t, in, out)
+}
@@ -30,3 +30,9 @@ func (c defaultExecutionCondition) CanExecuteOnSubject(_ heimdall.RequestContext
 func (c defaultExecutionCondition) CanExecuteOnError(_ heimdall.RequestContext, _ error) (bool, error) {
 	return true, nil
 }
+
+func (c defaultExecutionCondition) CanExecuteOnResponse(
+	_ heimdall.RequestContext, _ *subject.Subject, _ *heimdall.Response,
+) (bool, error) {
+	return true, nil
+}
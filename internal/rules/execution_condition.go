@@ -26,4 +26,5 @@ import (
 type executionCondition interface {
 	CanExecuteOnSubject(ctx heimdall.RequestContext, sub *subject.Subject) (bool, error)
 	CanExecuteOnError(ctx heimdall.RequestContext, err error) (bool, error)
+	CanExecuteOnResponse(ctx heimdall.RequestContext, sub *subject.Subject, resp *heimdall.Response) (bool, error)
 }
@@ -30,8 +30,8 @@ import (
 func TestCreateAuthenticatorPrototype(t *testing.T) {
 	t.Parallel()
 
-	// there are seven authenticators implemented, which should have been registered
-	require.Len(t, authenticatorTypeFactories, 6)
+	// there are ten authenticators implemented, which should have been registered
+	require.Len(t, authenticatorTypeFactories, 13)
 
 	for uc, tc := range map[string]struct {
 		typ    string
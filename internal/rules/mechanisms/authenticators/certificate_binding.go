@@ -0,0 +1,64 @@
+// Copyright 2025 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package authenticators
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+
+	"github.com/dadrus/heimdall/internal/heimdall"
+	"github.com/dadrus/heimdall/internal/rules/mechanisms/oauth2"
+	"github.com/dadrus/heimdall/internal/x/errorchain"
+)
+
+// CertificateBinding configures the verification of certificate-bound access tokens as defined in RFC 8705.
+type CertificateBinding struct {
+	Required                 bool `mapstructure:"required"`
+	AllowForwardedClientCert bool `mapstructure:"allow_forwarded_client_cert"`
+}
+
+// Verify ensures the certificate thumbprint from the confirmation claim of the access token matches the
+// certificate the client presented. Tokens not bound to a certificate are only accepted, if binding is
+// not required.
+func (b *CertificateBinding) Verify(ctx heimdall.RequestContext, cnf *oauth2.Confirmation, errCtx any) error {
+	expected := cnf.X5T()
+	if len(expected) == 0 {
+		if b.Required {
+			return errorchain.NewWithMessage(heimdall.ErrAuthentication, "access token is not certificate-bound").
+				WithErrorContext(errCtx)
+		}
+
+		return nil
+	}
+
+	certs, err := clientCertificates(ctx, b.AllowForwardedClientCert, errCtx)
+	if err != nil {
+		return err
+	}
+
+	digest := sha256.Sum256(certs[0].Raw)
+	actual := base64.RawURLEncoding.EncodeToString(digest[:])
+
+	if subtle.ConstantTimeCompare([]byte(actual), []byte(expected)) != 1 {
+		return errorchain.NewWithMessage(heimdall.ErrAuthentication,
+			"access token is not bound to the presented client certificate").
+			WithErrorContext(errCtx)
+	}
+
+	return nil
+}
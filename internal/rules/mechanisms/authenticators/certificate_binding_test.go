@@ -0,0 +1,159 @@
+// Copyright 2025 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package authenticators
+
+import (
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dadrus/heimdall/internal/heimdall"
+	"github.com/dadrus/heimdall/internal/heimdall/mocks"
+	"github.com/dadrus/heimdall/internal/rules/mechanisms/oauth2"
+)
+
+func TestCertificateBindingVerify(t *testing.T) {
+	t.Parallel()
+
+	pki := createX509TestPKI(t)
+	digest := sha256.Sum256(pki.clientCert.Raw)
+	thumbprint := base64.RawURLEncoding.EncodeToString(digest[:])
+
+	for uc, tc := range map[string]struct {
+		binding   CertificateBinding
+		cnf       *oauth2.Confirmation
+		configure func(t *testing.T, fnt *mocks.RequestFunctionsMock)
+		assert    func(t *testing.T, err error)
+	}{
+		"token not bound and binding not required": {
+			configure: func(t *testing.T, _ *mocks.RequestFunctionsMock) { t.Helper() },
+			assert: func(t *testing.T, err error) {
+				t.Helper()
+
+				require.NoError(t, err)
+			},
+		},
+		"token not bound, but binding required": {
+			binding:   CertificateBinding{Required: true},
+			cnf:       &oauth2.Confirmation{JWKThumbprint: "foo"},
+			configure: func(t *testing.T, _ *mocks.RequestFunctionsMock) { t.Helper() },
+			assert: func(t *testing.T, err error) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrAuthentication)
+				assert.Contains(t, err.Error(), "not certificate-bound")
+			},
+		},
+		"bound token without client certificate": {
+			cnf: &oauth2.Confirmation{X509Thumbprint: thumbprint},
+			configure: func(t *testing.T, fnt *mocks.RequestFunctionsMock) {
+				t.Helper()
+
+				fnt.EXPECT().ClientCertificates().Return(nil)
+			},
+			assert: func(t *testing.T, err error) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrAuthentication)
+				assert.Contains(t, err.Error(), "no client certificate present")
+			},
+		},
+		"bound token with other client certificate": {
+			cnf: &oauth2.Confirmation{X509Thumbprint: thumbprint},
+			configure: func(t *testing.T, fnt *mocks.RequestFunctionsMock) {
+				t.Helper()
+
+				fnt.EXPECT().ClientCertificates().Return([]*x509.Certificate{pki.untrustedCert})
+			},
+			assert: func(t *testing.T, err error) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrAuthentication)
+				assert.Contains(t, err.Error(), "not bound to the presented client certificate")
+			},
+		},
+		"bound token with matching client certificate from tls connection": {
+			binding: CertificateBinding{Required: true},
+			cnf:     &oauth2.Confirmation{X509Thumbprint: thumbprint},
+			configure: func(t *testing.T, fnt *mocks.RequestFunctionsMock) {
+				t.Helper()
+
+				fnt.EXPECT().ClientCertificates().Return([]*x509.Certificate{pki.clientCert, pki.intCACert})
+			},
+			assert: func(t *testing.T, err error) {
+				t.Helper()
+
+				require.NoError(t, err)
+			},
+		},
+		"bound token with forwarded client certificate, which is not allowed": {
+			cnf: &oauth2.Confirmation{X509Thumbprint: thumbprint},
+			configure: func(t *testing.T, fnt *mocks.RequestFunctionsMock) {
+				t.Helper()
+
+				fnt.EXPECT().ClientCertificates().Return(nil)
+				fnt.EXPECT().Header("X-Forwarded-Client-Cert").Maybe().
+					Return(`Cert="` + urlEncodedPEM(t, pki.clientCert) + `"`)
+			},
+			assert: func(t *testing.T, err error) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrAuthentication)
+				assert.Contains(t, err.Error(), "no client certificate present")
+			},
+		},
+		"bound token with matching forwarded client certificate": {
+			binding: CertificateBinding{AllowForwardedClientCert: true},
+			cnf:     &oauth2.Confirmation{X509Thumbprint: thumbprint},
+			configure: func(t *testing.T, fnt *mocks.RequestFunctionsMock) {
+				t.Helper()
+
+				fnt.EXPECT().ClientCertificates().Return(nil)
+				fnt.EXPECT().Header("X-Forwarded-Client-Cert").
+					Return(`Hash=abc;Cert="` + urlEncodedPEM(t, pki.clientCert) + `"`)
+			},
+			assert: func(t *testing.T, err error) {
+				t.Helper()
+
+				require.NoError(t, err)
+			},
+		},
+	} {
+		t.Run(uc, func(t *testing.T) {
+			// GIVEN
+			fnt := mocks.NewRequestFunctionsMock(t)
+			tc.configure(t, fnt)
+
+			ctx := mocks.NewRequestContextMock(t)
+			ctx.EXPECT().Request().Maybe().Return(&heimdall.Request{RequestFunctions: fnt})
+
+			// WHEN
+			err := tc.binding.Verify(ctx, tc.cnf, nil)
+
+			// THEN
+			tc.assert(t, err)
+		})
+	}
+}
@@ -23,4 +23,11 @@ const (
 	AuthenticatorOAuth2Introspection = "oauth2_introspection"
 	AuthenticatorJwt                 = "jwt"
 	AuthenticatorGeneric             = "generic"
+	AuthenticatorX509                = "x509"
+	AuthenticatorHtpasswd            = "htpasswd"
+	AuthenticatorOIDC                = "oidc"
+	AuthenticatorSession             = "session"
+	AuthenticatorPASETO              = "paseto"
+	AuthenticatorMacaroon            = "macaroon"
+	AuthenticatorLDAP                = "ldap"
 )
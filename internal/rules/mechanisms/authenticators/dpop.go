@@ -0,0 +1,90 @@
+// Copyright 2025 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package authenticators
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/dadrus/heimdall/internal/app"
+	"github.com/dadrus/heimdall/internal/heimdall"
+	"github.com/dadrus/heimdall/internal/rules/mechanisms/oauth2"
+	"github.com/dadrus/heimdall/internal/x"
+	"github.com/dadrus/heimdall/internal/x/errorchain"
+)
+
+const dpopScheme = "DPoP"
+
+// checkDPoPReplayProtection ensures a cache is available to keep track of the DPoP proofs already used.
+// Otherwise, replayed proofs could not be detected.
+func checkDPoPReplayProtection(app app.Context, conf *oauth2.DPoP, typ, id string) error {
+	if conf == nil || app.Config().Cache.Type != "noop" {
+		return nil
+	}
+
+	return errorchain.NewWithMessagef(heimdall.ErrConfiguration,
+		"dpop in %s authenticator '%s' requires a cache, but the noop cache is configured", typ, id)
+}
+
+// verifyDPoPBinding ensures DPoP-bound tokens (RFC 9449) are presented with the DPoP scheme and a valid
+// proof of possession of the key, the token is bound to. Tokens presented with the Bearer scheme are
+// only accepted, if they are not bound to a key and DPoP is not required.
+func verifyDPoPBinding(
+	ctx heimdall.RequestContext,
+	conf *oauth2.DPoP,
+	accessToken string,
+	cnf *oauth2.Confirmation,
+	errCtx any,
+) error {
+	req := ctx.Request()
+	usesDPoP := strings.HasPrefix(req.Header("Authorization"), dpopScheme+" ")
+
+	switch {
+	case !usesDPoP && len(cnf.JKT()) == 0 && !conf.Required:
+		return nil
+	case !usesDPoP:
+		return errorchain.NewWithMessage(heimdall.ErrAuthentication, "DPoP-bound access token expected").
+			WithErrorContext(errCtx)
+	case len(cnf.JKT()) == 0:
+		return errorchain.NewWithMessage(heimdall.ErrAuthentication, "access token is not DPoP-bound").
+			WithErrorContext(errCtx)
+	}
+
+	if heimdall.IsRevalidation(ctx.Context()) {
+		// the proof has already been verified, when the subject has been authenticated initially.
+		// It is neither fresh anymore, nor can it be used a second time.
+		return nil
+	}
+
+	jkt, err := conf.VerifyProof(ctx.Context(), req.Header("DPoP"), req.Method, &req.URL.URL, accessToken)
+	if err != nil {
+		return errorchain.NewWithMessage(
+			x.IfThenElse(errors.Is(err, heimdall.ErrCommunication), heimdall.ErrCommunication, heimdall.ErrAuthentication),
+			"DPoP proof verification failed").
+			WithErrorContext(errCtx).
+			CausedBy(err)
+	}
+
+	if jkt != cnf.JKT() {
+		return errorchain.NewWithMessage(heimdall.ErrAuthentication,
+			"DPoP proof is not signed with the key the access token is bound to").
+			WithErrorContext(errCtx).
+			CausedBy(oauth2.ErrInvalidDPoPProof)
+	}
+
+	return nil
+}
@@ -0,0 +1,225 @@
+// Copyright 2025 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package authenticators
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/go-jose/go-jose/v4"
+	"github.com/go-jose/go-jose/v4/jwt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dadrus/heimdall/internal/cache"
+	"github.com/dadrus/heimdall/internal/cache/memory"
+	"github.com/dadrus/heimdall/internal/cache/noop"
+	"github.com/dadrus/heimdall/internal/heimdall"
+	"github.com/dadrus/heimdall/internal/heimdall/mocks"
+	"github.com/dadrus/heimdall/internal/rules/mechanisms/oauth2"
+)
+
+func TestVerifyDPoPBinding(t *testing.T) {
+	t.Parallel()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	jwk := jose.JSONWebKey{Key: key.Public()}
+	thumbprint, err := jwk.Thumbprint(crypto.SHA256)
+	require.NoError(t, err)
+
+	jkt := base64.RawURLEncoding.EncodeToString(thumbprint)
+	accessToken := "foo.bar.baz"
+
+	createProof := func(t *testing.T) string {
+		t.Helper()
+
+		signer, err := jose.NewSigner(
+			jose.SigningKey{Algorithm: jose.ES256, Key: key},
+			(&jose.SignerOptions{EmbedJWK: true}).WithType("dpop+jwt"),
+		)
+		require.NoError(t, err)
+
+		hash := sha256.Sum256([]byte(accessToken))
+
+		proof, err := jwt.Signed(signer).Claims(map[string]any{
+			"jti": "foo",
+			"htm": "GET",
+			"htu": "https://foo.bar/baz",
+			"iat": time.Now().Unix(),
+			"ath": base64.RawURLEncoding.EncodeToString(hash[:]),
+		}).Serialize()
+		require.NoError(t, err)
+
+		return proof
+	}
+
+	for uc, tc := range map[string]struct {
+		conf         oauth2.DPoP
+		authz        string
+		withProof    bool
+		cnf          *oauth2.Confirmation
+		cache        cache.Cache
+		revalidation bool
+		assert       func(t *testing.T, err error)
+	}{
+		"unbound bearer token with optional DPoP": {
+			authz: "Bearer " + accessToken,
+			assert: func(t *testing.T, err error) {
+				t.Helper()
+
+				require.NoError(t, err)
+			},
+		},
+		"unbound bearer token with required DPoP": {
+			conf:  oauth2.DPoP{Required: true},
+			authz: "Bearer " + accessToken,
+			assert: func(t *testing.T, err error) {
+				t.Helper()
+
+				require.ErrorIs(t, err, heimdall.ErrAuthentication)
+				assert.Contains(t, err.Error(), "DPoP-bound access token expected")
+			},
+		},
+		"bound token presented as bearer token": {
+			authz: "Bearer " + accessToken,
+			cnf:   &oauth2.Confirmation{JWKThumbprint: jkt},
+			assert: func(t *testing.T, err error) {
+				t.Helper()
+
+				require.ErrorIs(t, err, heimdall.ErrAuthentication)
+				assert.Contains(t, err.Error(), "DPoP-bound access token expected")
+			},
+		},
+		"unbound token presented with DPoP scheme": {
+			authz:     "DPoP " + accessToken,
+			withProof: true,
+			assert: func(t *testing.T, err error) {
+				t.Helper()
+
+				require.ErrorIs(t, err, heimdall.ErrAuthentication)
+				assert.Contains(t, err.Error(), "not DPoP-bound")
+			},
+		},
+		"bound token without proof": {
+			authz: "DPoP " + accessToken,
+			cnf:   &oauth2.Confirmation{JWKThumbprint: jkt},
+			assert: func(t *testing.T, err error) {
+				t.Helper()
+
+				require.ErrorIs(t, err, heimdall.ErrAuthentication)
+				require.ErrorIs(t, err, oauth2.ErrInvalidDPoPProof)
+				assert.Contains(t, err.Error(), "no DPoP proof present")
+			},
+		},
+		"bound to another key": {
+			authz:     "DPoP " + accessToken,
+			withProof: true,
+			cnf:       &oauth2.Confirmation{JWKThumbprint: "foo"},
+			assert: func(t *testing.T, err error) {
+				t.Helper()
+
+				require.ErrorIs(t, err, heimdall.ErrAuthentication)
+				require.ErrorIs(t, err, oauth2.ErrInvalidDPoPProof)
+				assert.Contains(t, err.Error(), "not signed with the key")
+			},
+		},
+		"failing replay check": {
+			authz:     "DPoP " + accessToken,
+			withProof: true,
+			cnf:       &oauth2.Confirmation{JWKThumbprint: jkt},
+			cache:     &noop.Cache{},
+			assert: func(t *testing.T, err error) {
+				t.Helper()
+
+				require.ErrorIs(t, err, heimdall.ErrCommunication)
+				require.NotErrorIs(t, err, heimdall.ErrAuthentication)
+				assert.Contains(t, err.Error(), "failed to check DPoP proof for replays")
+			},
+		},
+		"bound token on revalidation": {
+			authz:        "DPoP " + accessToken,
+			withProof:    true,
+			cnf:          &oauth2.Confirmation{JWKThumbprint: jkt},
+			cache:        &noop.Cache{},
+			revalidation: true,
+			assert: func(t *testing.T, err error) {
+				t.Helper()
+
+				require.NoError(t, err)
+			},
+		},
+		"valid proof for a bound token": {
+			conf:      oauth2.DPoP{Required: true},
+			authz:     "DPoP " + accessToken,
+			withProof: true,
+			cnf:       &oauth2.Confirmation{JWKThumbprint: jkt},
+			assert: func(t *testing.T, err error) {
+				t.Helper()
+
+				require.NoError(t, err)
+			},
+		},
+	} {
+		t.Run(uc, func(t *testing.T) {
+			// GIVEN
+			var err error
+
+			cch := tc.cache
+			if cch == nil {
+				cch, err = memory.NewCache(nil, nil)
+				require.NoError(t, err)
+			}
+
+			var proof string
+			if tc.withProof {
+				proof = createProof(t)
+			}
+
+			reqf := mocks.NewRequestFunctionsMock(t)
+			reqf.EXPECT().Header("Authorization").Return(tc.authz)
+			reqf.EXPECT().Header("DPoP").Maybe().Return(proof)
+
+			stdCtx := cache.WithContext(t.Context(), cch)
+			if tc.revalidation {
+				stdCtx = heimdall.WithRevalidation(stdCtx)
+			}
+
+			ctx := mocks.NewRequestContextMock(t)
+			ctx.EXPECT().Context().Maybe().Return(stdCtx)
+			ctx.EXPECT().Request().Return(&heimdall.Request{
+				RequestFunctions: reqf,
+				Method:           "GET",
+				URL:              &heimdall.URL{URL: url.URL{Scheme: "https", Host: "foo.bar", Path: "/baz"}},
+			})
+
+			// WHEN
+			err = verifyDPoPBinding(ctx, &tc.conf, accessToken, tc.cnf, "test")
+
+			// THEN
+			tc.assert(t, err)
+		})
+	}
+}
@@ -0,0 +1,78 @@
+// Copyright 2025 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package extractors
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/tidwall/gjson"
+
+	"github.com/dadrus/heimdall/internal/heimdall"
+	"github.com/dadrus/heimdall/internal/x/errorchain"
+	"github.com/dadrus/heimdall/internal/x/stringx"
+)
+
+// JSONPathExtractStrategy extracts the value addressed by a GJSON path expression
+// from the decoded request body.
+type JSONPathExtractStrategy struct {
+	Path string
+}
+
+func (es JSONPathExtractStrategy) GetAuthData(ctx heimdall.RequestContext) (string, error) {
+	var rawData []byte
+
+	switch body := ctx.Request().Body().(type) {
+	case map[string]any:
+		data, err := json.Marshal(body)
+		if err != nil {
+			return "", errorchain.NewWithMessage(heimdall.ErrArgument, "no usable body present").
+				CausedBy(err)
+		}
+
+		rawData = data
+	case string:
+		rawData = stringx.ToBytes(body)
+	}
+
+	if len(rawData) == 0 || !gjson.ValidBytes(rawData) {
+		return "", errorchain.NewWithMessage(heimdall.ErrArgument, "no usable body present")
+	}
+
+	result := gjson.GetBytes(rawData, es.Path)
+	if result.IsArray() {
+		values := result.Array()
+		if len(values) > 1 {
+			return "", errorchain.NewWithMessagef(heimdall.ErrArgument,
+				"'%s' request body path addresses multiple values", es.Path)
+		} else if len(values) == 1 {
+			result = values[0]
+		}
+	}
+
+	if !result.Exists() || result.Type == gjson.Null || result.IsArray() {
+		return "", errorchain.NewWithMessagef(heimdall.ErrArgument,
+			"no value present in request body at '%s'", es.Path)
+	}
+
+	if result.IsObject() {
+		return "", errorchain.NewWithMessagef(heimdall.ErrArgument,
+			"unexpected type for value at '%s' request body path", es.Path)
+	}
+
+	return strings.TrimSpace(result.String()), nil
+}
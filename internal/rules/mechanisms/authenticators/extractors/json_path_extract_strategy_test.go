@@ -0,0 +1,174 @@
+// Copyright 2025 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package extractors
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dadrus/heimdall/internal/heimdall"
+	"github.com/dadrus/heimdall/internal/heimdall/mocks"
+)
+
+func TestExtractJSONPath(t *testing.T) {
+	t.Parallel()
+
+	for uc, tc := range map[string]struct {
+		path   string
+		body   any
+		assert func(t *testing.T, err error, authData string)
+	}{
+		"body is not json": {
+			path: "foo",
+			body: "foobar=foo",
+			assert: func(t *testing.T, err error, _ string) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrArgument)
+				assert.Contains(t, err.Error(), "no usable body present")
+			},
+		},
+		"body does not contain the addressed value": {
+			path: "foo.bar",
+			body: map[string]any{"foo": map[string]any{"baz": "zab"}},
+			assert: func(t *testing.T, err error, _ string) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrArgument)
+				assert.Contains(t, err.Error(), "no value present in request body at 'foo.bar'")
+			},
+		},
+		"addressed value is null": {
+			path: "foo",
+			body: map[string]any{"foo": nil},
+			assert: func(t *testing.T, err error, _ string) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrArgument)
+				assert.Contains(t, err.Error(), "no value present")
+			},
+		},
+		"addressed value is an object": {
+			path: "foo",
+			body: map[string]any{"foo": map[string]any{"bar": "baz"}},
+			assert: func(t *testing.T, err error, _ string) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrArgument)
+				assert.Contains(t, err.Error(), "unexpected type")
+			},
+		},
+		"path addresses multiple values": {
+			path: "foo",
+			body: map[string]any{"foo": []any{"bar", "baz"}},
+			assert: func(t *testing.T, err error, _ string) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrArgument)
+				assert.Contains(t, err.Error(), "multiple values")
+			},
+		},
+		"path addresses an empty array": {
+			path: "foo",
+			body: map[string]any{"foo": []any{}},
+			assert: func(t *testing.T, err error, _ string) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrArgument)
+				assert.Contains(t, err.Error(), "no value present")
+			},
+		},
+		"nested value in json body": {
+			path: "variables.auth.token",
+			body: map[string]any{
+				"query":     "query { foo }",
+				"variables": map[string]any{"auth": map[string]any{"token": " foo "}},
+			},
+			assert: func(t *testing.T, err error, authData string) {
+				t.Helper()
+
+				require.NoError(t, err)
+				assert.Equal(t, "foo", authData)
+			},
+		},
+		"value from a single valued form field": {
+			path: "SAMLResponse",
+			body: map[string]any{"SAMLResponse": []string{"PHNhbWxwOlJlc3BvbnNlPg=="}},
+			assert: func(t *testing.T, err error, authData string) {
+				t.Helper()
+
+				require.NoError(t, err)
+				assert.Equal(t, "PHNhbWxwOlJlc3BvbnNlPg==", authData)
+			},
+		},
+		"value from a json multipart part": {
+			path: "metadata.0.token",
+			body: map[string]any{"metadata": []any{map[string]any{"token": "bar"}}},
+			assert: func(t *testing.T, err error, authData string) {
+				t.Helper()
+
+				require.NoError(t, err)
+				assert.Equal(t, "bar", authData)
+			},
+		},
+		"value from a json array body using a query": {
+			path: `#(type=="bearer").token`,
+			body: `[{"type":"basic","token":"foo"},{"type":"bearer","token":"bar"}]`,
+			assert: func(t *testing.T, err error, authData string) {
+				t.Helper()
+
+				require.NoError(t, err)
+				assert.Equal(t, "bar", authData)
+			},
+		},
+		"numeric value": {
+			path: "foo",
+			body: map[string]any{"foo": 42},
+			assert: func(t *testing.T, err error, authData string) {
+				t.Helper()
+
+				require.NoError(t, err)
+				assert.Equal(t, "42", authData)
+			},
+		},
+	} {
+		t.Run(uc, func(t *testing.T) {
+			// GIVEN
+			fnt := mocks.NewRequestFunctionsMock(t)
+			fnt.EXPECT().Body().Return(tc.body)
+
+			ctx := mocks.NewRequestContextMock(t)
+			ctx.EXPECT().Request().Return(&heimdall.Request{RequestFunctions: fnt})
+
+			strategy := JSONPathExtractStrategy{Path: tc.path}
+
+			// WHEN
+			authData, err := strategy.GetAuthData(ctx)
+
+			// THEN
+			tc.assert(t, err, authData)
+		})
+	}
+}
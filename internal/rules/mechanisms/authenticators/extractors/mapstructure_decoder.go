@@ -18,6 +18,7 @@ package extractors
 
 import (
 	"reflect"
+	"regexp"
 
 	"github.com/go-viper/mapstructure/v2"
 
@@ -77,6 +78,32 @@ func DecodeCompositeExtractStrategyHookFunc() mapstructure.DecodeHookFunc {
 }
 
 func createStrategy(data map[string]string) (AuthDataExtractStrategy, error) {
+	strategy, err := createSourceStrategy(data)
+	if err != nil {
+		return nil, err
+	}
+
+	prefix := data["prefix"]
+	pattern, hasPattern := data["pattern"]
+
+	if len(prefix) == 0 && !hasPattern {
+		return strategy, nil
+	}
+
+	transformer := &ValueTransformExtractStrategy{Strategy: strategy, Prefix: prefix}
+
+	if hasPattern {
+		if transformer.Pattern, err = regexp.Compile(pattern); err != nil {
+			return nil, errorchain.
+				NewWithMessagef(heimdall.ErrConfiguration, "failed to compile '%s' pattern", pattern).
+				CausedBy(err)
+		}
+	}
+
+	return transformer, nil
+}
+
+func createSourceStrategy(data map[string]string) (AuthDataExtractStrategy, error) {
 	if value, ok := data["header"]; ok { // nolint: nestif
 		var scheme string
 		if p, ok := data["scheme"]; ok {
@@ -90,6 +117,8 @@ func createStrategy(data map[string]string) (AuthDataExtractStrategy, error) {
 		return &QueryParameterExtractStrategy{Name: value}, nil
 	} else if value, ok := data["body_parameter"]; ok {
 		return &BodyParameterExtractStrategy{Name: value}, nil
+	} else if value, ok := data["json_path"]; ok {
+		return &JSONPathExtractStrategy{Path: value}, nil
 	}
 
 	return nil, errorchain.
@@ -42,6 +42,10 @@ authentication_data_source:
     scheme: hfoo
   - query_parameter: foo_qparam
   - body_parameter: foo_bparam
+  - json_path: foo.bar
+  - header: X-Token
+    prefix: Token
+    pattern: "^v1\\.(.+)$"
 `)
 
 	parser := koanf.New(".")
@@ -61,7 +65,7 @@ authentication_data_source:
 
 	err = dec.Decode(settings["authentication_data_source"])
 	require.NoError(t, err)
-	assert.Len(t, ces, 4)
+	assert.Len(t, ces, 6)
 
 	ce, ok := ces[0].(*CookieValueExtractStrategy)
 	require.True(t, ok)
@@ -79,4 +83,36 @@ authentication_data_source:
 	be, ok := ces[3].(*BodyParameterExtractStrategy)
 	require.True(t, ok)
 	assert.Equal(t, "foo_bparam", be.Name)
+
+	je, ok := ces[4].(*JSONPathExtractStrategy)
+	require.True(t, ok)
+	assert.Equal(t, "foo.bar", je.Path)
+
+	te, ok := ces[5].(*ValueTransformExtractStrategy)
+	require.True(t, ok)
+	assert.Equal(t, "Token", te.Prefix)
+	require.NotNil(t, te.Pattern)
+	assert.Equal(t, `^v1\.(.+)$`, te.Pattern.String())
+
+	he, ok = te.Strategy.(*HeaderValueExtractStrategy)
+	require.True(t, ok)
+	assert.Equal(t, "X-Token", he.Name)
+}
+
+func TestUnmarshalAuthenticationDataSourceWithInvalidPattern(t *testing.T) {
+	t.Parallel()
+
+	var ces CompositeExtractStrategy
+
+	dec, err := mapstructure.NewDecoder(&mapstructure.DecoderConfig{
+		DecodeHook: mapstructure.ComposeDecodeHookFunc(
+			DecodeCompositeExtractStrategyHookFunc(),
+		),
+		Result: &ces,
+	})
+	require.NoError(t, err)
+
+	err = dec.Decode([]any{map[string]any{"header": "X-Token", "pattern": "(foo"}})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to compile '(foo' pattern")
 }
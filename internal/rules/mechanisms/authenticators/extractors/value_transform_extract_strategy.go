@@ -0,0 +1,68 @@
+// Copyright 2025 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package extractors
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/dadrus/heimdall/internal/heimdall"
+	"github.com/dadrus/heimdall/internal/x/errorchain"
+)
+
+// ValueTransformExtractStrategy post-processes the value retrieved by the wrapped strategy.
+// If Prefix is set, it must be present in the value and is stripped. If Pattern is set, the
+// value must match it and is replaced by the first capture group, or by the whole match if
+// the pattern does not define any groups.
+type ValueTransformExtractStrategy struct {
+	Strategy AuthDataExtractStrategy
+	Prefix   string
+	Pattern  *regexp.Regexp
+}
+
+func (es ValueTransformExtractStrategy) GetAuthData(ctx heimdall.RequestContext) (string, error) {
+	value, err := es.Strategy.GetAuthData(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	if len(es.Prefix) != 0 {
+		if !strings.HasPrefix(value, es.Prefix) {
+			return "", errorchain.NewWithMessagef(heimdall.ErrArgument,
+				"value present, but without required '%s' prefix", es.Prefix)
+		}
+
+		value = strings.TrimSpace(strings.TrimPrefix(value, es.Prefix))
+	}
+
+	if es.Pattern != nil {
+		match := es.Pattern.FindStringSubmatch(value)
+		if match == nil {
+			return "", errorchain.NewWithMessagef(heimdall.ErrArgument,
+				"value present, but not matching '%s' pattern", es.Pattern.String())
+		}
+
+		// the first capture group, if defined, holds the value of interest
+		if len(match) > 1 {
+			value = match[1]
+		} else {
+			value = match[0]
+		}
+	}
+
+	return value, nil
+}
@@ -0,0 +1,123 @@
+// Copyright 2025 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package extractors
+
+import (
+	"errors"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dadrus/heimdall/internal/heimdall"
+	"github.com/dadrus/heimdall/internal/heimdall/mocks"
+	extractormocks "github.com/dadrus/heimdall/internal/rules/mechanisms/authenticators/extractors/mocks"
+)
+
+func TestValueTransformExtractStrategy(t *testing.T) {
+	t.Parallel()
+
+	errTest := errors.New("test error")
+
+	for uc, tc := range map[string]struct {
+		value   string
+		err     error
+		prefix  string
+		pattern *regexp.Regexp
+		assert  func(t *testing.T, err error, authData string)
+	}{
+		"wrapped strategy fails": {
+			err: errTest,
+			assert: func(t *testing.T, err error, _ string) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, errTest)
+			},
+		},
+		"required prefix not present": {
+			value:  "Bearer foo",
+			prefix: "Token",
+			assert: func(t *testing.T, err error, _ string) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrArgument)
+				assert.Contains(t, err.Error(), "without required 'Token' prefix")
+			},
+		},
+		"prefix is stripped": {
+			value:  "Token foo",
+			prefix: "Token",
+			assert: func(t *testing.T, err error, authData string) {
+				t.Helper()
+
+				require.NoError(t, err)
+				assert.Equal(t, "foo", authData)
+			},
+		},
+		"value does not match pattern": {
+			value:   "foo",
+			pattern: regexp.MustCompile(`^token=(\w+)$`),
+			assert: func(t *testing.T, err error, _ string) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrArgument)
+				assert.Contains(t, err.Error(), "not matching")
+			},
+		},
+		"pattern without capture groups": {
+			value:   "v1.foo.bar",
+			pattern: regexp.MustCompile(`foo\.\w+`),
+			assert: func(t *testing.T, err error, authData string) {
+				t.Helper()
+
+				require.NoError(t, err)
+				assert.Equal(t, "foo.bar", authData)
+			},
+		},
+		"prefix and pattern with capture groups": {
+			value:   "Session id=foo; expires=bar",
+			prefix:  "Session",
+			pattern: regexp.MustCompile(`id=(\w+);\s*expires=(\w+)`),
+			assert: func(t *testing.T, err error, authData string) {
+				t.Helper()
+
+				require.NoError(t, err)
+				assert.Equal(t, "foo", authData)
+			},
+		},
+	} {
+		t.Run(uc, func(t *testing.T) {
+			// GIVEN
+			ctx := mocks.NewRequestContextMock(t)
+
+			wrapped := extractormocks.NewAuthDataExtractStrategyMock(t)
+			wrapped.EXPECT().GetAuthData(ctx).Return(tc.value, tc.err)
+
+			strategy := ValueTransformExtractStrategy{Strategy: wrapped, Prefix: tc.prefix, Pattern: tc.pattern}
+
+			// WHEN
+			authData, err := strategy.GetAuthData(ctx)
+
+			// THEN
+			tc.assert(t, err, authData)
+		})
+	}
+}
@@ -0,0 +1,139 @@
+// Copyright 2025 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package authenticators
+
+import (
+	"encoding/base64"
+	"maps"
+	"strings"
+
+	"github.com/rs/zerolog"
+
+	"github.com/dadrus/heimdall/internal/app"
+	"github.com/dadrus/heimdall/internal/heimdall"
+	"github.com/dadrus/heimdall/internal/rules/mechanisms/authenticators/extractors"
+	"github.com/dadrus/heimdall/internal/rules/mechanisms/subject"
+	"github.com/dadrus/heimdall/internal/x/errorchain"
+)
+
+// by intention. Used only during application bootstrap
+//
+//nolint:gochecknoinits
+func init() {
+	registerTypeFactory(
+		func(app app.Context, id string, typ string, conf map[string]any) (bool, Authenticator, error) {
+			if typ != AuthenticatorHtpasswd {
+				return false, nil, nil
+			}
+
+			auth, err := newHtpasswdAuthenticator(app, id, conf)
+
+			return true, auth, err
+		})
+}
+
+type htpasswdAuthenticator struct {
+	id    string
+	users *htpasswdFile
+}
+
+func newHtpasswdAuthenticator(
+	app app.Context,
+	id string,
+	rawConfig map[string]any,
+) (*htpasswdAuthenticator, error) {
+	logger := app.Logger()
+	logger.Info().Str("_id", id).Msg("Creating htpasswd authenticator")
+
+	type Config struct {
+		Path string `mapstructure:"path" validate:"required"`
+	}
+
+	var conf Config
+	if err := decodeConfig(app, rawConfig, &conf); err != nil {
+		return nil, errorchain.NewWithMessagef(heimdall.ErrConfiguration,
+			"failed decoding config for htpasswd authenticator '%s'", id).CausedBy(err)
+	}
+
+	users, err := newHtpasswdFile(conf.Path, app.Watcher())
+	if err != nil {
+		return nil, errorchain.NewWithMessagef(heimdall.ErrConfiguration,
+			"failed loading users for htpasswd authenticator '%s'", id).CausedBy(err)
+	}
+
+	return &htpasswdAuthenticator{
+		id:    id,
+		users: users,
+	}, nil
+}
+
+func (a *htpasswdAuthenticator) Execute(ctx heimdall.RequestContext) (*subject.Subject, error) {
+	logger := zerolog.Ctx(ctx.Context())
+	logger.Debug().Str("_id", a.id).Msg("Authenticating using htpasswd authenticator")
+
+	strategy := extractors.HeaderValueExtractStrategy{Name: "Authorization", Scheme: "Basic"}
+
+	authData, err := strategy.GetAuthData(ctx)
+	if err != nil {
+		return nil, errorchain.
+			NewWithMessage(heimdall.ErrAuthentication, "expected header not present in request").
+			WithErrorContext(a).
+			CausedBy(err)
+	}
+
+	res, err := base64.StdEncoding.DecodeString(authData)
+	if err != nil {
+		return nil, errorchain.
+			NewWithMessage(heimdall.ErrAuthentication, "failed to decode received credentials value").
+			WithErrorContext(a)
+	}
+
+	// as defined by RFC 7617, the user-id must not contain a colon, but the password may
+	userID, password, found := strings.Cut(string(res), ":")
+	if !found {
+		return nil, errorchain.
+			NewWithMessage(heimdall.ErrAuthentication, "malformed user-id - password scheme").
+			WithErrorContext(a)
+	}
+
+	user, known := a.users.user(userID)
+	if !known {
+		// to not leak the existence of users by the response time
+		a.users.dummyVerifier().verify(password)
+
+		return nil, errorchain.
+			NewWithMessage(heimdall.ErrAuthentication, "invalid user credentials").
+			WithErrorContext(a)
+	}
+
+	if !user.verifier.verify(password) {
+		return nil, errorchain.
+			NewWithMessage(heimdall.ErrAuthentication, "invalid user credentials").
+			WithErrorContext(a)
+	}
+
+	return &subject.Subject{ID: userID, Attributes: maps.Clone(user.attributes)}, nil
+}
+
+func (a *htpasswdAuthenticator) WithConfig(_ map[string]any) (Authenticator, error) {
+	// nothing can be reconfigured
+	return a, nil
+}
+
+func (a *htpasswdAuthenticator) IsInsecure() bool { return false }
+
+func (a *htpasswdAuthenticator) ID() string { return a.id }
@@ -0,0 +1,509 @@
+// Copyright 2025 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package authenticators
+
+import (
+	"encoding/base64"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/rs/zerolog/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+
+	"github.com/dadrus/heimdall/internal/app"
+	"github.com/dadrus/heimdall/internal/heimdall"
+	"github.com/dadrus/heimdall/internal/heimdall/mocks"
+	"github.com/dadrus/heimdall/internal/rules/mechanisms/subject"
+	"github.com/dadrus/heimdall/internal/validation"
+	mocks2 "github.com/dadrus/heimdall/internal/watcher/mocks"
+	"github.com/dadrus/heimdall/internal/x/testsupport"
+)
+
+func writeHtpasswdFile(t *testing.T, path, contents string) {
+	t.Helper()
+
+	err := os.WriteFile(path, []byte(contents), 0o600)
+	require.NoError(t, err)
+}
+
+func TestCreateHtpasswdAuthenticator(t *testing.T) {
+	t.Parallel()
+
+	testDir := t.TempDir()
+
+	bcryptHash, err := bcrypt.GenerateFromPassword([]byte("secret"), bcrypt.MinCost)
+	require.NoError(t, err)
+
+	validFile := filepath.Join(testDir, "valid.htpasswd")
+	writeHtpasswdFile(t, validFile, `
+# service accounts
+foo:`+string(bcryptHash)+`
+bar:`+argon2idHash("secret")+`:{"groups":["admin","dev"],"email":"bar@example.com"}
+`)
+
+	for uc, tc := range map[string]struct {
+		config    []byte
+		configure func(t *testing.T, wm *mocks2.WatcherMock)
+		assert    func(t *testing.T, err error, auth *htpasswdAuthenticator)
+	}{
+		"without configuration": {
+			assert: func(t *testing.T, err error, _ *htpasswdAuthenticator) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrConfiguration)
+				assert.Contains(t, err.Error(), "'path' is a required field")
+			},
+		},
+		"with unsupported attributes": {
+			config: []byte(`
+path: ` + validFile + `
+foo: bar
+`),
+			assert: func(t *testing.T, err error, _ *htpasswdAuthenticator) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrConfiguration)
+				assert.Contains(t, err.Error(), "failed decoding")
+			},
+		},
+		"with not existing file": {
+			config: []byte(`path: /no/such/file`),
+			assert: func(t *testing.T, err error, _ *htpasswdAuthenticator) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrConfiguration)
+				assert.Contains(t, err.Error(), "failed loading users")
+			},
+		},
+		"with file without password hash": {
+			config: func() []byte {
+				path := filepath.Join(testDir, "no-hash.htpasswd")
+				writeHtpasswdFile(t, path, "foo\n")
+
+				return []byte(`path: ` + path)
+			}(),
+			assert: func(t *testing.T, err error, _ *htpasswdAuthenticator) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrConfiguration)
+				assert.Contains(t, err.Error(), "failed to parse line 1")
+			},
+		},
+		"with file containing unsupported hash": {
+			config: func() []byte {
+				path := filepath.Join(testDir, "md5.htpasswd")
+				writeHtpasswdFile(t, path, "foo:"+string(bcryptHash)+"\nbar:$apr1$salt$hash\n")
+
+				return []byte(`path: ` + path)
+			}(),
+			assert: func(t *testing.T, err error, _ *htpasswdAuthenticator) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrConfiguration)
+				require.ErrorIs(t, err, ErrUnsupportedPasswordHash)
+				assert.Contains(t, err.Error(), "failed to parse line 2")
+			},
+		},
+		"with file containing malformed attributes": {
+			config: func() []byte {
+				path := filepath.Join(testDir, "attributes.htpasswd")
+				writeHtpasswdFile(t, path, "foo:"+string(bcryptHash)+":[\"admin\"]\n")
+
+				return []byte(`path: ` + path)
+			}(),
+			assert: func(t *testing.T, err error, _ *htpasswdAuthenticator) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrConfiguration)
+				assert.Contains(t, err.Error(), "attributes must be a JSON object")
+			},
+		},
+		"with file containing duplicate users": {
+			config: func() []byte {
+				path := filepath.Join(testDir, "duplicates.htpasswd")
+				writeHtpasswdFile(t, path, "foo:"+string(bcryptHash)+"\nfoo:"+string(bcryptHash)+"\n")
+
+				return []byte(`path: ` + path)
+			}(),
+			assert: func(t *testing.T, err error, _ *htpasswdAuthenticator) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrConfiguration)
+				assert.Contains(t, err.Error(), "user 'foo' defined in line 2")
+			},
+		},
+		"fails registering file for updates": {
+			config: []byte(`path: ` + validFile),
+			configure: func(t *testing.T, wm *mocks2.WatcherMock) {
+				t.Helper()
+
+				wm.EXPECT().Add(validFile, mock.Anything).Return(errors.New("test error"))
+			},
+			assert: func(t *testing.T, err error, _ *htpasswdAuthenticator) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrConfiguration)
+				assert.Contains(t, err.Error(), "test error")
+			},
+		},
+		"with valid configuration": {
+			config: []byte(`path: ` + validFile),
+			configure: func(t *testing.T, wm *mocks2.WatcherMock) {
+				t.Helper()
+
+				wm.EXPECT().Add(validFile, mock.Anything).Return(nil)
+			},
+			assert: func(t *testing.T, err error, auth *htpasswdAuthenticator) {
+				t.Helper()
+
+				require.NoError(t, err)
+				assert.Equal(t, "with valid configuration", auth.ID())
+				assert.False(t, auth.IsInsecure())
+
+				foo, ok := auth.users.user("foo")
+				require.True(t, ok)
+				assert.IsType(t, &bcryptVerifier{}, foo.verifier)
+				assert.Empty(t, foo.attributes)
+
+				bar, ok := auth.users.user("bar")
+				require.True(t, ok)
+				assert.IsType(t, &argon2Verifier{}, bar.verifier)
+				assert.Equal(t, map[string]any{
+					"groups": []any{"admin", "dev"},
+					"email":  "bar@example.com",
+				}, bar.attributes)
+			},
+		},
+	} {
+		t.Run(uc, func(t *testing.T) {
+			// GIVEN
+			conf, err := testsupport.DecodeTestConfig(tc.config)
+			require.NoError(t, err)
+
+			validator, err := validation.NewValidator()
+			require.NoError(t, err)
+
+			wm := mocks2.NewWatcherMock(t)
+			if tc.configure != nil {
+				tc.configure(t, wm)
+			}
+
+			appCtx := app.NewContextMock(t)
+			appCtx.EXPECT().Validator().Maybe().Return(validator)
+			appCtx.EXPECT().Logger().Return(log.Logger)
+			appCtx.EXPECT().Watcher().Maybe().Return(wm)
+
+			// WHEN
+			auth, err := newHtpasswdAuthenticator(appCtx, uc, conf)
+
+			// THEN
+			tc.assert(t, err, auth)
+		})
+	}
+}
+
+func TestCreateHtpasswdAuthenticatorFromPrototype(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	path := filepath.Join(t.TempDir(), "users.htpasswd")
+	writeHtpasswdFile(t, path, "foo:{SHA}"+base64.StdEncoding.EncodeToString([]byte("01234567890123456789"))+"\n")
+
+	conf, err := testsupport.DecodeTestConfig([]byte(`path: ` + path))
+	require.NoError(t, err)
+
+	validator, err := validation.NewValidator()
+	require.NoError(t, err)
+
+	wm := mocks2.NewWatcherMock(t)
+	wm.EXPECT().Add(path, mock.Anything).Return(nil)
+
+	appCtx := app.NewContextMock(t)
+	appCtx.EXPECT().Validator().Return(validator)
+	appCtx.EXPECT().Logger().Return(log.Logger)
+	appCtx.EXPECT().Watcher().Return(wm)
+
+	prototype, err := newHtpasswdAuthenticator(appCtx, "foo", conf)
+	require.NoError(t, err)
+
+	// WHEN
+	auth1, err1 := prototype.WithConfig(nil)
+	auth2, err2 := prototype.WithConfig(map[string]any{"path": "/foo/bar"})
+
+	// THEN
+	require.NoError(t, err1)
+	require.NoError(t, err2)
+	assert.Equal(t, prototype, auth1)
+	assert.Equal(t, prototype, auth2)
+}
+
+func TestHtpasswdAuthenticatorExecute(t *testing.T) {
+	t.Parallel()
+
+	bcryptHash, err := bcrypt.GenerateFromPassword([]byte("secret"), bcrypt.MinCost)
+	require.NoError(t, err)
+
+	path := filepath.Join(t.TempDir(), "users.htpasswd")
+	writeHtpasswdFile(t, path, `
+foo:`+string(bcryptHash)+`
+bar:$5$saltstring$5B8vYYiY.CVt1RlTTf8KbXBH3hsxY/GNooZaBBGWEc5:{"groups":["admin"]}
+`)
+
+	conf, err := testsupport.DecodeTestConfig([]byte(`path: ` + path))
+	require.NoError(t, err)
+
+	validator, err := validation.NewValidator()
+	require.NoError(t, err)
+
+	wm := mocks2.NewWatcherMock(t)
+	wm.EXPECT().Add(path, mock.Anything).Return(nil)
+
+	appCtx := app.NewContextMock(t)
+	appCtx.EXPECT().Validator().Return(validator)
+	appCtx.EXPECT().Logger().Return(log.Logger)
+	appCtx.EXPECT().Watcher().Return(wm)
+
+	auth, err := newHtpasswdAuthenticator(appCtx, "htpasswd", conf)
+	require.NoError(t, err)
+
+	basicAuth := func(userID, password string) string {
+		return "Basic " + base64.StdEncoding.EncodeToString([]byte(userID+":"+password))
+	}
+
+	for uc, tc := range map[string]struct {
+		authorization string
+		assert        func(t *testing.T, err error, sub *subject.Subject)
+	}{
+		"no authorization header": {
+			assert: func(t *testing.T, err error, _ *subject.Subject) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrAuthentication)
+				assert.Contains(t, err.Error(), "expected header not present")
+
+				var identifier interface{ ID() string }
+				require.ErrorAs(t, err, &identifier)
+				assert.Equal(t, "htpasswd", identifier.ID())
+			},
+		},
+		"not base64 encoded credentials": {
+			authorization: "Basic foo:bar",
+			assert: func(t *testing.T, err error, _ *subject.Subject) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrAuthentication)
+				assert.Contains(t, err.Error(), "failed to decode")
+			},
+		},
+		"malformed credentials": {
+			authorization: "Basic " + base64.StdEncoding.EncodeToString([]byte("foo")),
+			assert: func(t *testing.T, err error, _ *subject.Subject) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrAuthentication)
+				assert.Contains(t, err.Error(), "malformed user-id - password scheme")
+			},
+		},
+		"unknown user": {
+			authorization: basicAuth("baz", "secret"),
+			assert: func(t *testing.T, err error, _ *subject.Subject) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrAuthentication)
+				assert.Contains(t, err.Error(), "invalid user credentials")
+			},
+		},
+		"wrong password": {
+			authorization: basicAuth("foo", "Secret"),
+			assert: func(t *testing.T, err error, _ *subject.Subject) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrAuthentication)
+				assert.Contains(t, err.Error(), "invalid user credentials")
+			},
+		},
+		"valid credentials of user without attributes": {
+			authorization: basicAuth("foo", "secret"),
+			assert: func(t *testing.T, err error, sub *subject.Subject) {
+				t.Helper()
+
+				require.NoError(t, err)
+				assert.Equal(t, &subject.Subject{ID: "foo", Attributes: map[string]any{}}, sub)
+			},
+		},
+		"valid credentials of user with attributes": {
+			authorization: basicAuth("bar", "Hello world!"),
+			assert: func(t *testing.T, err error, sub *subject.Subject) {
+				t.Helper()
+
+				require.NoError(t, err)
+				assert.Equal(t, &subject.Subject{
+					ID:         "bar",
+					Attributes: map[string]any{"groups": []any{"admin"}},
+				}, sub)
+			},
+		},
+	} {
+		t.Run(uc, func(t *testing.T) {
+			// GIVEN
+			fnt := mocks.NewRequestFunctionsMock(t)
+			fnt.EXPECT().Header("Authorization").Return(tc.authorization)
+
+			ctx := mocks.NewRequestContextMock(t)
+			ctx.EXPECT().Context().Return(t.Context())
+			ctx.EXPECT().Request().Return(&heimdall.Request{RequestFunctions: fnt})
+
+			// WHEN
+			sub, err := auth.Execute(ctx)
+
+			// THEN
+			tc.assert(t, err, sub)
+		})
+	}
+}
+
+func TestHtpasswdFileReload(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	bcryptHash, err := bcrypt.GenerateFromPassword([]byte("secret"), bcrypt.MinCost)
+	require.NoError(t, err)
+
+	path := filepath.Join(t.TempDir(), "users.htpasswd")
+	writeHtpasswdFile(t, path, "foo:"+string(bcryptHash)+"\n")
+
+	wm := mocks2.NewWatcherMock(t)
+	wm.EXPECT().Add(path, mock.Anything).Return(nil)
+
+	file, err := newHtpasswdFile(path, wm)
+	require.NoError(t, err)
+
+	_, fooPresent := file.user("foo")
+	_, barPresent := file.user("bar")
+
+	require.True(t, fooPresent)
+	require.False(t, barPresent)
+
+	// WHEN
+	writeHtpasswdFile(t, path, "bar:"+string(bcryptHash)+"\n")
+	file.OnChanged(log.Logger)
+
+	// THEN
+	_, fooPresent = file.user("foo")
+	_, barPresent = file.user("bar")
+
+	require.False(t, fooPresent)
+	require.True(t, barPresent)
+
+	// WHEN
+	writeHtpasswdFile(t, path, "baz\n")
+	file.OnChanged(log.Logger)
+
+	// THEN
+	_, barPresent = file.user("bar")
+	require.True(t, barPresent)
+}
+
+func TestHtpasswdFileDummyVerifier(t *testing.T) {
+	t.Parallel()
+
+	bcryptHash, err := bcrypt.GenerateFromPassword([]byte("secret"), bcrypt.MinCost)
+	require.NoError(t, err)
+
+	testDir := t.TempDir()
+
+	for uc, tc := range map[string]struct {
+		contents string
+		assert   func(t *testing.T, dummy passwordVerifier)
+	}{
+		"without users": {
+			contents: "# no users\n",
+			assert: func(t *testing.T, dummy passwordVerifier) {
+				t.Helper()
+
+				require.IsType(t, &bcryptVerifier{}, dummy)
+				assert.Equal(t, []byte(htpasswdDummyHash), dummy.(*bcryptVerifier).hash) //nolint: forcetypeassert
+			},
+		},
+		"with one scheme only": {
+			contents: "foo:" + argon2idHash("secret") + "\n",
+			assert: func(t *testing.T, dummy passwordVerifier) {
+				t.Helper()
+
+				assert.IsType(t, &argon2Verifier{}, dummy)
+			},
+		},
+		"with the most common scheme following another one": {
+			contents: "foo:" + string(bcryptHash) + "\n" +
+				"bar:" + argon2idHash("secret") + "\n" +
+				"baz:" + argon2idHash("secret") + "\n",
+			assert: func(t *testing.T, dummy passwordVerifier) {
+				t.Helper()
+
+				assert.IsType(t, &argon2Verifier{}, dummy)
+			},
+		},
+		"with the most common scheme preceding another one": {
+			contents: "foo:" + string(bcryptHash) + "\n" +
+				"bar:" + string(bcryptHash) + "\n" +
+				"baz:" + argon2idHash("secret") + "\n",
+			assert: func(t *testing.T, dummy passwordVerifier) {
+				t.Helper()
+
+				assert.IsType(t, &bcryptVerifier{}, dummy)
+			},
+		},
+	} {
+		t.Run(uc, func(t *testing.T) {
+			// GIVEN
+			path := filepath.Join(testDir, uc+".htpasswd")
+			writeHtpasswdFile(t, path, tc.contents)
+
+			wm := mocks2.NewWatcherMock(t)
+			wm.EXPECT().Add(path, mock.Anything).Return(nil)
+
+			// WHEN
+			file, err := newHtpasswdFile(path, wm)
+
+			// THEN
+			require.NoError(t, err)
+
+			dummy := file.dummyVerifier()
+			require.NotNil(t, dummy)
+			assert.False(t, dummy.verify("foo"))
+			tc.assert(t, dummy)
+		})
+	}
+}
@@ -0,0 +1,193 @@
+// Copyright 2025 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package authenticators
+
+import (
+	"bufio"
+	"bytes"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/goccy/go-json"
+	"github.com/rs/zerolog"
+
+	"github.com/dadrus/heimdall/internal/heimdall"
+	"github.com/dadrus/heimdall/internal/watcher"
+	"github.com/dadrus/heimdall/internal/x/errorchain"
+)
+
+// used to verify the password of not existing users if the htpasswd file does not
+// define any users.
+const htpasswdDummyHash = "$2a$10$bfJlo8XqRGR0mJOhUfdVpu8f1Uv0Z/EyLLZd7159Adldgh2WDvXdC"
+
+type htpasswdUser struct {
+	scheme     string
+	verifier   passwordVerifier
+	attributes map[string]any
+}
+
+// htpasswdFile holds the users defined in an htpasswd file. Each line has the form
+// <user>:<password hash>[:<attributes>], with attributes being an optional JSON object.
+// Empty lines and lines starting with # are ignored. The file is reloaded on changes.
+type htpasswdFile struct {
+	path string
+
+	mut   sync.RWMutex
+	users map[string]*htpasswdUser
+	dummy passwordVerifier
+}
+
+func newHtpasswdFile(path string, fw watcher.Watcher) (*htpasswdFile, error) {
+	file := &htpasswdFile{path: path}
+
+	if err := file.load(); err != nil {
+		return nil, err
+	}
+
+	if err := fw.Add(file.path, file); err != nil {
+		return nil, errorchain.NewWithMessage(heimdall.ErrInternal, "failed registering htpasswd file for updates").
+			CausedBy(err)
+	}
+
+	return file, nil
+}
+
+func (f *htpasswdFile) OnChanged(logger zerolog.Logger) {
+	if err := f.load(); err != nil {
+		logger.Warn().Err(err).
+			Str("_file", f.path).
+			Msg("htpasswd file reload failed")
+	} else {
+		logger.Info().
+			Str("_file", f.path).
+			Msg("htpasswd file reloaded")
+	}
+}
+
+func (f *htpasswdFile) user(name string) (*htpasswdUser, bool) {
+	f.mut.RLock()
+	defer f.mut.RUnlock()
+
+	user, ok := f.users[name]
+
+	return user, ok
+}
+
+// dummyVerifier returns a verifier to be used for not existing users. It makes use of the
+// most common password hash scheme in the file, so that verifying the password of a not
+// existing user takes as long as verifying the password of an existing one.
+func (f *htpasswdFile) dummyVerifier() passwordVerifier {
+	f.mut.RLock()
+	defer f.mut.RUnlock()
+
+	return f.dummy
+}
+
+func (f *htpasswdFile) load() error {
+	contents, err := os.ReadFile(f.path)
+	if err != nil {
+		return errorchain.NewWithMessagef(heimdall.ErrConfiguration, "failed to read %s", f.path).
+			CausedBy(err)
+	}
+
+	var (
+		dummy       passwordVerifier
+		dummyScheme string
+	)
+
+	users := make(map[string]*htpasswdUser)
+	schemes := make(map[string]int)
+	scanner := bufio.NewScanner(bytes.NewReader(contents))
+
+	for lineNo := 1; scanner.Scan(); lineNo++ {
+		line := strings.TrimSpace(scanner.Text())
+		if len(line) == 0 || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		name, user, err := parseHtpasswdLine(line)
+		if err != nil {
+			return errorchain.NewWithMessagef(heimdall.ErrConfiguration,
+				"failed to parse line %d in %s", lineNo, f.path).CausedBy(err)
+		}
+
+		if _, ok := users[name]; ok {
+			return errorchain.NewWithMessagef(heimdall.ErrConfiguration,
+				"user '%s' defined in line %d in %s is not unique", name, lineNo, f.path)
+		}
+
+		users[name] = user
+
+		// the hash of an existing user is used, as it also has the cost parameters in use
+		schemes[user.scheme]++
+		if dummy == nil || schemes[user.scheme] > schemes[dummyScheme] {
+			dummy, dummyScheme = user.verifier, user.scheme
+		}
+	}
+
+	if err = scanner.Err(); err != nil {
+		return errorchain.NewWithMessagef(heimdall.ErrConfiguration, "failed to read %s", f.path).
+			CausedBy(err)
+	}
+
+	if dummy == nil {
+		dummy, _ = newBcryptVerifier(htpasswdDummyHash)
+	}
+
+	f.mut.Lock()
+	defer f.mut.Unlock()
+
+	f.users = users
+	f.dummy = dummy
+
+	return nil
+}
+
+func parseHtpasswdLine(line string) (string, *htpasswdUser, error) {
+	name, rest, found := strings.Cut(line, ":")
+	if !found || len(name) == 0 {
+		return "", nil, errorchain.NewWithMessage(heimdall.ErrArgument, "expected <user>:<password hash>")
+	}
+
+	// none of the supported hash formats make use of ':'
+	encodedHash, rawAttributes, _ := strings.Cut(rest, ":")
+
+	verifier, err := newPasswordVerifier(encodedHash)
+	if err != nil {
+		return "", nil, err
+	}
+
+	var attributes map[string]any
+
+	if len(rawAttributes) != 0 {
+		if err = json.Unmarshal([]byte(rawAttributes), &attributes); err != nil {
+			return "", nil, errorchain.NewWithMessage(heimdall.ErrArgument,
+				"attributes must be a JSON object").CausedBy(err)
+		}
+	}
+
+	if attributes == nil {
+		attributes = make(map[string]any)
+	}
+
+	return name, &htpasswdUser{
+		scheme:     passwordHashScheme(encodedHash),
+		verifier:   verifier,
+		attributes: attributes,
+	}, nil
+}
@@ -76,6 +76,9 @@ type jwtAuthenticator struct {
 	allowFallbackOnError bool
 	trustStore           truststore.TrustStore
 	validateJWKCert      bool
+	dpop                 *oauth2.DPoP
+	certBinding          *CertificateBinding
+	decrypter            *jwtDecrypter
 }
 
 // nolint: funlen, cyclop
@@ -97,6 +100,9 @@ func newJwtAuthenticator(
 		AllowFallbackOnError bool                                `mapstructure:"allow_fallback_on_error"`
 		ValidateJWK          *bool                               `mapstructure:"validate_jwk"`
 		TrustStore           truststore.TrustStore               `mapstructure:"trust_store"`
+		DPoP                 *oauth2.DPoP                        `mapstructure:"dpop"`
+		CertificateBinding   *CertificateBinding                 `mapstructure:"certificate_binding"`
+		Decryption           *DecryptionConfig                   `mapstructure:"decryption"`
 	}
 
 	var conf Config
@@ -109,6 +115,16 @@ func newJwtAuthenticator(
 		logger.Warn().Str("_id", id).Msg("Usage of allow_fallback_on_error is deprecated and has no effect")
 	}
 
+	if err := checkDPoPReplayProtection(app, conf.DPoP, "jwt", id); err != nil {
+		return nil, err
+	}
+
+	if conf.CertificateBinding != nil && conf.CertificateBinding.AllowForwardedClientCert {
+		logger.Warn().Str("_id", id).
+			Msg("jwt authenticator accepts client certificates forwarded by proxies. " +
+				"Make sure, heimdall is configured to trust only those proxies, which are expected to forward them.")
+	}
+
 	if conf.JWKSEndpoint != nil {
 		if len(conf.Assertions.TrustedIssuers) == 0 {
 			return nil, errorchain.
@@ -153,6 +169,24 @@ func newJwtAuthenticator(
 		func() extractors.CompositeExtractStrategy { return conf.AuthDataSource },
 	)
 
+	if conf.DPoP != nil && conf.AuthDataSource == nil {
+		// DPoP-bound tokens are sent using the DPoP authorization scheme
+		ads = append(extractors.CompositeExtractStrategy{
+			extractors.HeaderValueExtractStrategy{Name: "Authorization", Scheme: dpopScheme},
+		}, ads...)
+	}
+
+	var decrypter *jwtDecrypter
+
+	if conf.Decryption != nil {
+		var err error
+
+		decrypter, err = newJWTDecrypter(conf.Decryption, app.Watcher())
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	resolver := x.IfThenElseExec(conf.MetadataEndpoint != nil,
 		func() oauth2.ServerMetadataResolver { return conf.MetadataEndpoint },
 		func() oauth2.ServerMetadataResolver {
@@ -189,6 +223,9 @@ func newJwtAuthenticator(
 		allowFallbackOnError: conf.AllowFallbackOnError,
 		validateJWKCert:      validateJWKCert,
 		trustStore:           conf.TrustStore,
+		dpop:                 conf.DPoP,
+		certBinding:          conf.CertificateBinding,
+		decrypter:            decrypter,
 	}, nil
 }
 
@@ -204,7 +241,19 @@ func (a *jwtAuthenticator) Execute(ctx heimdall.RequestContext) (*subject.Subjec
 			CausedBy(err)
 	}
 
-	token, err := jwt.ParseSigned(jwtAd, supportedAlgorithms())
+	rawJWT := jwtAd
+
+	if a.decrypter != nil {
+		// nested JWTs (RFC 7519, section 5.2) are decrypted before their signature is verified
+		if rawJWT, err = a.decrypter.Decrypt(jwtAd); err != nil {
+			return nil, errorchain.
+				NewWithMessage(heimdall.ErrAuthentication, "failed to decrypt JWT").
+				WithErrorContext(a).
+				CausedBy(err)
+		}
+	}
+
+	token, err := jwt.ParseSigned(rawJWT, supportedAlgorithms())
 	if err != nil {
 		return nil, errorchain.
 			NewWithMessage(heimdall.ErrAuthentication, "failed to parse JWT").
@@ -218,6 +267,17 @@ func (a *jwtAuthenticator) Execute(ctx heimdall.RequestContext) (*subject.Subjec
 		return nil, err
 	}
 
+	if a.dpop != nil || a.certBinding != nil {
+		var claims oauth2.Claims
+
+		// the claims have already been deserialized successfully during the verification
+		_ = json.Unmarshal(rawClaims, &claims)
+
+		if err = a.verifyBinding(ctx, jwtAd, claims.Confirmation); err != nil {
+			return nil, err
+		}
+	}
+
 	sub, err := a.sf.CreateSubject(rawClaims)
 	if err != nil {
 		return nil, errorchain.
@@ -265,9 +325,30 @@ func (a *jwtAuthenticator) WithConfig(config map[string]any) (Authenticator, err
 			func() bool { return a.allowFallbackOnError }),
 		validateJWKCert: a.validateJWKCert,
 		trustStore:      a.trustStore,
+		dpop:            a.dpop,
+		certBinding:     a.certBinding,
+		decrypter:       a.decrypter,
 	}, nil
 }
 
+func (a *jwtAuthenticator) verifyBinding(
+	ctx heimdall.RequestContext,
+	accessToken string,
+	cnf *oauth2.Confirmation,
+) error {
+	if a.dpop != nil {
+		if err := verifyDPoPBinding(ctx, a.dpop, accessToken, cnf, a); err != nil {
+			return err
+		}
+	}
+
+	if a.certBinding != nil {
+		return a.certBinding.Verify(ctx, cnf, a)
+	}
+
+	return nil
+}
+
 func (a *jwtAuthenticator) ID() string {
 	return a.id
 }
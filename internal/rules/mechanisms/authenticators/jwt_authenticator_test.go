@@ -56,6 +56,7 @@ import (
 	"github.com/dadrus/heimdall/internal/rules/oauth2/clientcredentials"
 	"github.com/dadrus/heimdall/internal/truststore"
 	"github.com/dadrus/heimdall/internal/validation"
+	watchermocks "github.com/dadrus/heimdall/internal/watcher/mocks"
 	"github.com/dadrus/heimdall/internal/x"
 	"github.com/dadrus/heimdall/internal/x/pkix/pemx"
 	"github.com/dadrus/heimdall/internal/x/testsupport"
@@ -87,6 +88,7 @@ func TestJwtAuthenticatorCreate(t *testing.T) {
 
 	for uc, tc := range map[string]struct {
 		enforceTLS bool
+		cacheType  string
 		config     []byte
 		assert     func(t *testing.T, err error, a *jwtAuthenticator)
 	}{
@@ -550,6 +552,95 @@ cache_ttl: 5s`),
 				assert.Equal(t, "auth1", auth.ID())
 			},
 		},
+		"with dpop configured": {
+			config: []byte(`
+jwks_endpoint:
+  url: https://foo.bar
+assertions:
+  issuers:
+    - foobar
+dpop:
+  required: true
+  proof_max_age: 30s
+  allowed_algorithms:
+    - ES256
+`),
+			assert: func(t *testing.T, err error, auth *jwtAuthenticator) {
+				t.Helper()
+
+				require.NoError(t, err)
+
+				require.NotNil(t, auth.dpop)
+				assert.True(t, auth.dpop.Required)
+				assert.Equal(t, 30*time.Second, auth.dpop.ProofMaxAge)
+				assert.Equal(t, []string{"ES256"}, auth.dpop.AllowedAlgorithms)
+
+				assert.IsType(t, extractors.CompositeExtractStrategy{}, auth.ads)
+				assert.Len(t, auth.ads, 4)
+				assert.Equal(t, extractors.HeaderValueExtractStrategy{Name: "Authorization", Scheme: "DPoP"},
+					auth.ads.(extractors.CompositeExtractStrategy)[0])
+			},
+		},
+		"with dpop configured, but noop cache in use": {
+			cacheType: "noop",
+			config: []byte(`
+jwks_endpoint:
+  url: https://foo.bar
+assertions:
+  issuers:
+    - foobar
+dpop:
+  required: true
+`),
+			assert: func(t *testing.T, err error, _ *jwtAuthenticator) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrConfiguration)
+				require.ErrorContains(t, err, "requires a cache")
+			},
+		},
+		"with decryption configured, but not existing key store": {
+			config: []byte(`
+jwks_endpoint:
+  url: https://foo.bar
+assertions:
+  issuers:
+    - foobar
+decryption:
+  key_store:
+    path: /does/not/exist.pem
+`),
+			assert: func(t *testing.T, err error, _ *jwtAuthenticator) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrConfiguration)
+				require.ErrorContains(t, err, "failed loading decryption key store")
+			},
+		},
+		"with certificate binding configured": {
+			config: []byte(`
+jwks_endpoint:
+  url: https://foo.bar
+assertions:
+  issuers:
+    - foobar
+certificate_binding:
+  required: true
+  allow_forwarded_client_cert: true
+`),
+			assert: func(t *testing.T, err error, auth *jwtAuthenticator) {
+				t.Helper()
+
+				require.NoError(t, err)
+
+				require.NotNil(t, auth.certBinding)
+				assert.True(t, auth.certBinding.Required)
+				assert.True(t, auth.certBinding.AllowForwardedClientCert)
+				assert.Nil(t, auth.dpop)
+			},
+		},
 	} {
 		t.Run(uc, func(t *testing.T) {
 			// GIVEN
@@ -566,6 +657,10 @@ cache_ttl: 5s`),
 			appCtx := app.NewContextMock(t)
 			appCtx.EXPECT().Validator().Maybe().Return(validator)
 			appCtx.EXPECT().Logger().Return(log.Logger)
+			appCtx.EXPECT().Watcher().Maybe().Return(watchermocks.NewWatcherMock(t))
+			appCtx.EXPECT().Config().Maybe().Return(&config.Configuration{
+				Cache: config.CacheConfig{Type: x.IfThenElse(len(tc.cacheType) != 0, tc.cacheType, "in-memory")},
+			})
 
 			// WHEN
 			a, err := newJwtAuthenticator(appCtx, "auth1", conf)
@@ -981,6 +1076,35 @@ func TestJwtAuthenticatorExecute(t *testing.T) {
 	jwtSignedWithKeyAndCertJWK := createJWT(t, keyAndCertEntry, subjectID, issuer, audience, true)
 	jwtWithoutKIDSignedWithKeyAndCertJWK := createJWT(t, keyAndCertEntry, subjectID, issuer, audience, false)
 
+	encPrivKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	encKeyPEM, err := pemx.BuildPEM(pemx.WithECDSAPrivateKey(encPrivKey, pemx.WithHeader("X-Key-ID", "enc")))
+	require.NoError(t, err)
+
+	encKeyFile, err := os.CreateTemp(t.TempDir(), "test-jwt-authenticator-decryption-*")
+	require.NoError(t, err)
+
+	_, err = encKeyFile.Write(encKeyPEM)
+	require.NoError(t, err)
+
+	wm := watchermocks.NewWatcherMock(t)
+	wm.EXPECT().Add(encKeyFile.Name(), mock.Anything).Return(nil)
+
+	decrypter, err := newJWTDecrypter(&DecryptionConfig{KeyStore: KeyStore{Path: encKeyFile.Name()}}, wm)
+	require.NoError(t, err)
+
+	encrypter, err := jose.NewEncrypter(jose.A256GCM,
+		jose.Recipient{Algorithm: jose.ECDH_ES_A256KW, Key: &encPrivKey.PublicKey, KeyID: "enc"},
+		(&jose.EncrypterOptions{}).WithContentType("JWT"))
+	require.NoError(t, err)
+
+	encryptedJWT, err := encrypter.Encrypt([]byte(jwtSignedWithKeyOnlyJWK))
+	require.NoError(t, err)
+
+	jweWithNestedJWT, err := encryptedJWT.CompactSerialize()
+	require.NoError(t, err)
+
 	jwksSrv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		jwksEndpointCalled = true
 
@@ -1696,6 +1820,92 @@ func TestJwtAuthenticatorExecute(t *testing.T) {
 				assert.Equal(t, subjectID, sub.Attributes["sub"])
 			},
 		},
+		"with JWE, which cannot be decrypted": {
+			authenticator: &jwtAuthenticator{id: "auth3", decrypter: decrypter},
+			configureMocks: func(t *testing.T,
+				ctx *heimdallmocks.RequestContextMock,
+				_ *mocks.CacheMock,
+				ads *mocks2.AuthDataExtractStrategyMock,
+				_ *jwtAuthenticator,
+			) {
+				t.Helper()
+
+				ads.EXPECT().GetAuthData(ctx).Return("foo.bar.baz.bam.bar", nil)
+			},
+			assert: func(t *testing.T, err error, _ *subject.Subject) {
+				t.Helper()
+
+				assert.False(t, jwksEndpointCalled)
+				assert.False(t, metadataEndpointCalled)
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrAuthentication)
+				require.ErrorIs(t, err, heimdall.ErrArgument)
+				require.ErrorContains(t, err, "failed to decrypt JWT")
+
+				var identifier HandlerIdentifier
+				require.ErrorAs(t, err, &identifier)
+				assert.Equal(t, "auth3", identifier.ID())
+			},
+		},
+		"successful with nested JWE and positive cache hit": {
+			authenticator: &jwtAuthenticator{
+				r: oauth2.ResolverAdapterFunc(func(_ context.Context, _ map[string]any) (oauth2.ServerMetadata, error) {
+					return oauth2.ServerMetadata{
+						JWKSEndpoint: &endpoint.Endpoint{
+							URL:     jwksSrv.URL,
+							Headers: map[string]string{"Accept": "application/json"},
+						},
+					}, nil
+				}),
+				a: oauth2.Expectation{
+					AllowedAlgorithms: []string{"ES384"},
+					TrustedIssuers:    []string{issuer},
+					ScopesMatcher:     oauth2.ExactScopeStrategyMatcher{},
+				},
+				sf:        &SubjectInfo{IDFrom: "sub"},
+				ttl:       &tenSecondsTTL,
+				decrypter: decrypter,
+			},
+			configureMocks: func(t *testing.T,
+				ctx *heimdallmocks.RequestContextMock,
+				cch *mocks.CacheMock,
+				ads *mocks2.AuthDataExtractStrategyMock,
+				auth *jwtAuthenticator,
+			) {
+				t.Helper()
+
+				ep := &endpoint.Endpoint{
+					URL:     jwksSrv.URL,
+					Headers: map[string]string{"Accept": "application/json"},
+				}
+				cacheKey := auth.calculateCacheKey(ep, jwksSrv.URL, kidKeyWithoutCert)
+
+				var jwks jose.JSONWebKeySet
+				err := json.Unmarshal(jwksWithOneKeyOnlyEntry, &jwks)
+				require.NoError(t, err)
+
+				keys := jwks.Key(kidKeyWithoutCert)
+
+				rawKey, err := json.Marshal(&keys[0])
+				require.NoError(t, err)
+
+				ads.EXPECT().GetAuthData(ctx).Return(jweWithNestedJWT, nil)
+				cch.EXPECT().Get(mock.Anything, cacheKey).Return(rawKey, nil)
+			},
+			assert: func(t *testing.T, err error, sub *subject.Subject) {
+				t.Helper()
+
+				assert.False(t, jwksEndpointCalled)
+				assert.False(t, metadataEndpointCalled)
+
+				require.NoError(t, err)
+
+				require.NotNil(t, sub)
+				assert.Equal(t, subjectID, sub.ID)
+				assert.Equal(t, issuer, sub.Attributes["iss"])
+			},
+		},
 		"successful without cache hit using key only": {
 			authenticator: &jwtAuthenticator{
 				r: oauth2.ResolverAdapterFunc(func(_ context.Context, _ map[string]any) (oauth2.ServerMetadata, error) {
@@ -0,0 +1,159 @@
+// Copyright 2025 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package authenticators
+
+import (
+	"strings"
+	"sync"
+
+	"github.com/go-jose/go-jose/v4"
+	"github.com/rs/zerolog"
+
+	"github.com/dadrus/heimdall/internal/heimdall"
+	"github.com/dadrus/heimdall/internal/keystore"
+	"github.com/dadrus/heimdall/internal/watcher"
+	"github.com/dadrus/heimdall/internal/x/errorchain"
+)
+
+// jweCompactSegments is the number of segments of a JWE in compact serialization (RFC 7516, section 7.1).
+const jweCompactSegments = 5
+
+type KeyStore struct {
+	Path     string `mapstructure:"path"     validate:"required"`
+	Password string `mapstructure:"password"`
+}
+
+type DecryptionConfig struct {
+	KeyStore                    KeyStore `mapstructure:"key_store"                     validate:"required"`
+	KeyAlgorithms               []string `mapstructure:"key_algorithms"`
+	ContentEncryptionAlgorithms []string `mapstructure:"content_encryption_algorithms"`
+}
+
+type jwtDecrypter struct {
+	path     string
+	password string
+	keyAlgs  []jose.KeyAlgorithm
+	encAlgs  []jose.ContentEncryption
+
+	mut sync.RWMutex
+	ks  keystore.KeyStore
+}
+
+func newJWTDecrypter(conf *DecryptionConfig, fw watcher.Watcher) (*jwtDecrypter, error) {
+	keyAlgs := []jose.KeyAlgorithm{
+		jose.RSA_OAEP, jose.RSA_OAEP_256,
+		jose.ECDH_ES, jose.ECDH_ES_A128KW, jose.ECDH_ES_A192KW, jose.ECDH_ES_A256KW,
+	}
+	if len(conf.KeyAlgorithms) != 0 {
+		keyAlgs = make([]jose.KeyAlgorithm, len(conf.KeyAlgorithms))
+		for idx, alg := range conf.KeyAlgorithms {
+			keyAlgs[idx] = jose.KeyAlgorithm(alg)
+		}
+	}
+
+	encAlgs := []jose.ContentEncryption{
+		jose.A128GCM, jose.A192GCM, jose.A256GCM,
+		jose.A128CBC_HS256, jose.A192CBC_HS384, jose.A256CBC_HS512,
+	}
+	if len(conf.ContentEncryptionAlgorithms) != 0 {
+		encAlgs = make([]jose.ContentEncryption, len(conf.ContentEncryptionAlgorithms))
+		for idx, alg := range conf.ContentEncryptionAlgorithms {
+			encAlgs[idx] = jose.ContentEncryption(alg)
+		}
+	}
+
+	decrypter := &jwtDecrypter{
+		path:     conf.KeyStore.Path,
+		password: conf.KeyStore.Password,
+		keyAlgs:  keyAlgs,
+		encAlgs:  encAlgs,
+	}
+
+	if err := decrypter.load(); err != nil {
+		return nil, err
+	}
+
+	if err := fw.Add(decrypter.path, decrypter); err != nil {
+		return nil, errorchain.NewWithMessage(heimdall.ErrInternal, "failed registering jwt decrypter for updates").
+			CausedBy(err)
+	}
+
+	return decrypter, nil
+}
+
+func (d *jwtDecrypter) OnChanged(logger zerolog.Logger) {
+	err := d.load()
+	if err != nil {
+		logger.Warn().Err(err).
+			Str("_file", d.path).
+			Msg("Decryption key store reload failed")
+	} else {
+		logger.Info().
+			Str("_file", d.path).
+			Msg("Decryption key store reloaded")
+	}
+}
+
+func (d *jwtDecrypter) load() error {
+	ks, err := keystore.NewKeyStoreFromPEMFile(d.path, d.password)
+	if err != nil {
+		return errorchain.NewWithMessage(heimdall.ErrConfiguration, "failed loading decryption key store").
+			CausedBy(err)
+	}
+
+	d.mut.Lock()
+	d.ks = ks
+	d.mut.Unlock()
+
+	return nil
+}
+
+// Decrypt returns the nested JWT, if the given token is a JWE. Any other token is returned unchanged.
+func (d *jwtDecrypter) Decrypt(token string) (string, error) {
+	if strings.Count(token, ".") != jweCompactSegments-1 {
+		return token, nil
+	}
+
+	jwe, err := jose.ParseEncrypted(token, d.keyAlgs, d.encAlgs)
+	if err != nil {
+		return "", errorchain.NewWithMessage(heimdall.ErrArgument, "failed to parse JWE").CausedBy(err)
+	}
+
+	d.mut.RLock()
+	ks := d.ks
+	d.mut.RUnlock()
+
+	entries := ks.Entries()
+
+	if kid := jwe.Header.KeyID; len(kid) != 0 {
+		entry, err := ks.GetKey(kid)
+		if err != nil {
+			return "", errorchain.NewWithMessagef(heimdall.ErrArgument,
+				"no decryption key found for the keyID='%s' referenced in the JWE", kid).CausedBy(err)
+		}
+
+		entries = []*keystore.Entry{entry}
+	}
+
+	for _, entry := range entries {
+		if plaintext, err := jwe.Decrypt(entry.PrivateKey); err == nil {
+			return string(plaintext), nil
+		}
+	}
+
+	return "", errorchain.NewWithMessage(heimdall.ErrArgument, "failed to decrypt JWE")
+}
@@ -0,0 +1,297 @@
+// Copyright 2025 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package authenticators
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"os"
+	"testing"
+
+	"github.com/go-jose/go-jose/v4"
+	"github.com/rs/zerolog/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dadrus/heimdall/internal/heimdall"
+	"github.com/dadrus/heimdall/internal/watcher/mocks"
+	"github.com/dadrus/heimdall/internal/x/pkix/pemx"
+)
+
+func TestNewJWTDecrypter(t *testing.T) {
+	t.Parallel()
+
+	privKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	pemBytes, err := pemx.BuildPEM(pemx.WithECDSAPrivateKey(privKey, pemx.WithHeader("X-Key-ID", "enc")))
+	require.NoError(t, err)
+
+	keyFile, err := os.CreateTemp(t.TempDir(), "test-jwt-decrypter-*")
+	require.NoError(t, err)
+
+	_, err = keyFile.Write(pemBytes)
+	require.NoError(t, err)
+
+	for uc, tc := range map[string]struct {
+		config    *DecryptionConfig
+		configure func(t *testing.T, wm *mocks.WatcherMock)
+		assert    func(t *testing.T, err error, decrypter *jwtDecrypter)
+	}{
+		"with not existing key store": {
+			config:    &DecryptionConfig{KeyStore: KeyStore{Path: "/does/not/exist"}},
+			configure: func(t *testing.T, _ *mocks.WatcherMock) { t.Helper() },
+			assert: func(t *testing.T, err error, _ *jwtDecrypter) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrConfiguration)
+				require.ErrorContains(t, err, "failed loading decryption key store")
+			},
+		},
+		"with error while registering for updates": {
+			config: &DecryptionConfig{KeyStore: KeyStore{Path: keyFile.Name()}},
+			configure: func(t *testing.T, wm *mocks.WatcherMock) {
+				t.Helper()
+
+				wm.EXPECT().Add(keyFile.Name(), mock.Anything).Return(assert.AnError)
+			},
+			assert: func(t *testing.T, err error, _ *jwtDecrypter) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrInternal)
+				require.ErrorContains(t, err, "failed registering jwt decrypter")
+			},
+		},
+		"with default algorithms": {
+			config: &DecryptionConfig{KeyStore: KeyStore{Path: keyFile.Name()}},
+			configure: func(t *testing.T, wm *mocks.WatcherMock) {
+				t.Helper()
+
+				wm.EXPECT().Add(keyFile.Name(), mock.Anything).Return(nil)
+			},
+			assert: func(t *testing.T, err error, decrypter *jwtDecrypter) {
+				t.Helper()
+
+				require.NoError(t, err)
+				require.NotNil(t, decrypter.ks)
+				assert.Len(t, decrypter.ks.Entries(), 1)
+				assert.Contains(t, decrypter.keyAlgs, jose.RSA_OAEP_256)
+				assert.Contains(t, decrypter.keyAlgs, jose.ECDH_ES_A256KW)
+				assert.Contains(t, decrypter.encAlgs, jose.A256GCM)
+			},
+		},
+		"with configured algorithms": {
+			config: &DecryptionConfig{
+				KeyStore:                    KeyStore{Path: keyFile.Name()},
+				KeyAlgorithms:               []string{"ECDH-ES"},
+				ContentEncryptionAlgorithms: []string{"A128GCM"},
+			},
+			configure: func(t *testing.T, wm *mocks.WatcherMock) {
+				t.Helper()
+
+				wm.EXPECT().Add(keyFile.Name(), mock.Anything).Return(nil)
+			},
+			assert: func(t *testing.T, err error, decrypter *jwtDecrypter) {
+				t.Helper()
+
+				require.NoError(t, err)
+				assert.Equal(t, []jose.KeyAlgorithm{jose.ECDH_ES}, decrypter.keyAlgs)
+				assert.Equal(t, []jose.ContentEncryption{jose.A128GCM}, decrypter.encAlgs)
+			},
+		},
+	} {
+		t.Run(uc, func(t *testing.T) {
+			// GIVEN
+			wm := mocks.NewWatcherMock(t)
+			tc.configure(t, wm)
+
+			// WHEN
+			decrypter, err := newJWTDecrypter(tc.config, wm)
+
+			// THEN
+			tc.assert(t, err, decrypter)
+		})
+	}
+}
+
+func TestJWTDecrypterDecrypt(t *testing.T) {
+	t.Parallel()
+
+	ecPrivKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	rsaPrivKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	otherPrivKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	pemBytes, err := pemx.BuildPEM(
+		pemx.WithECDSAPrivateKey(ecPrivKey, pemx.WithHeader("X-Key-ID", "ec")),
+		pemx.WithRSAPrivateKey(rsaPrivKey, pemx.WithHeader("X-Key-ID", "rsa")),
+	)
+	require.NoError(t, err)
+
+	keyFile, err := os.CreateTemp(t.TempDir(), "test-jwt-decrypter-*")
+	require.NoError(t, err)
+
+	_, err = keyFile.Write(pemBytes)
+	require.NoError(t, err)
+
+	wm := mocks.NewWatcherMock(t)
+	wm.EXPECT().Add(keyFile.Name(), mock.Anything).Return(nil)
+
+	decrypter, err := newJWTDecrypter(&DecryptionConfig{
+		KeyStore:      KeyStore{Path: keyFile.Name()},
+		KeyAlgorithms: []string{string(jose.ECDH_ES_A256KW), string(jose.RSA_OAEP_256)},
+	}, wm)
+	require.NoError(t, err)
+
+	encrypt := func(t *testing.T, alg jose.KeyAlgorithm, key any, kid string) string {
+		t.Helper()
+
+		encrypter, err := jose.NewEncrypter(jose.A256GCM,
+			jose.Recipient{Algorithm: alg, Key: key, KeyID: kid},
+			(&jose.EncrypterOptions{}).WithContentType("JWT"))
+		require.NoError(t, err)
+
+		obj, err := encrypter.Encrypt([]byte("header.payload.signature"))
+		require.NoError(t, err)
+
+		token, err := obj.CompactSerialize()
+		require.NoError(t, err)
+
+		return token
+	}
+
+	for uc, tc := range map[string]struct {
+		token  func(t *testing.T) string
+		assert func(t *testing.T, err error, result string)
+	}{
+		"not a JWE": {
+			token: func(t *testing.T) string {
+				t.Helper()
+
+				return "header.payload.signature"
+			},
+			assert: func(t *testing.T, err error, result string) {
+				t.Helper()
+
+				require.NoError(t, err)
+				assert.Equal(t, "header.payload.signature", result)
+			},
+		},
+		"malformed JWE": {
+			token: func(t *testing.T) string {
+				t.Helper()
+
+				return "a.b.c.d.e"
+			},
+			assert: func(t *testing.T, err error, _ string) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrArgument)
+				require.ErrorContains(t, err, "failed to parse JWE")
+			},
+		},
+		"JWE using not allowed key algorithm": {
+			token: func(t *testing.T) string {
+				t.Helper()
+
+				return encrypt(t, jose.RSA_OAEP, &rsaPrivKey.PublicKey, "rsa")
+			},
+			assert: func(t *testing.T, err error, _ string) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorContains(t, err, "failed to parse JWE")
+			},
+		},
+		"JWE referencing unknown key": {
+			token: func(t *testing.T) string {
+				t.Helper()
+
+				return encrypt(t, jose.ECDH_ES_A256KW, &otherPrivKey.PublicKey, "foo")
+			},
+			assert: func(t *testing.T, err error, _ string) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrArgument)
+				require.ErrorContains(t, err, "no decryption key found for the keyID='foo'")
+			},
+		},
+		"JWE encrypted for other key without key id": {
+			token: func(t *testing.T) string {
+				t.Helper()
+
+				return encrypt(t, jose.ECDH_ES_A256KW, &otherPrivKey.PublicKey, "")
+			},
+			assert: func(t *testing.T, err error, _ string) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrArgument)
+				require.ErrorContains(t, err, "failed to decrypt JWE")
+			},
+		},
+		"JWE encrypted with ECDH-ES and key id": {
+			token: func(t *testing.T) string {
+				t.Helper()
+
+				return encrypt(t, jose.ECDH_ES_A256KW, &ecPrivKey.PublicKey, "ec")
+			},
+			assert: func(t *testing.T, err error, result string) {
+				t.Helper()
+
+				require.NoError(t, err)
+				assert.Equal(t, "header.payload.signature", result)
+			},
+		},
+		"JWE encrypted with RSA-OAEP-256 without key id": {
+			token: func(t *testing.T) string {
+				t.Helper()
+
+				return encrypt(t, jose.RSA_OAEP_256, &rsaPrivKey.PublicKey, "")
+			},
+			assert: func(t *testing.T, err error, result string) {
+				t.Helper()
+
+				require.NoError(t, err)
+				assert.Equal(t, "header.payload.signature", result)
+			},
+		},
+	} {
+		t.Run(uc, func(t *testing.T) {
+			// WHEN
+			result, err := decrypter.Decrypt(tc.token(t))
+
+			// THEN
+			tc.assert(t, err, result)
+		})
+	}
+
+	// reload keeps the decrypter operational
+	decrypter.OnChanged(log.Logger)
+	assert.Len(t, decrypter.ks.Entries(), 2)
+}
@@ -0,0 +1,213 @@
+// Copyright 2025 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package authenticators
+
+import (
+	"context"
+	"encoding/hex"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/go-jose/go-jose/v4"
+	"github.com/goccy/go-json"
+	"github.com/rs/zerolog"
+
+	"github.com/dadrus/heimdall/internal/cache"
+	"github.com/dadrus/heimdall/internal/heimdall"
+	"github.com/dadrus/heimdall/internal/rules/endpoint"
+	"github.com/dadrus/heimdall/internal/watcher"
+	"github.com/dadrus/heimdall/internal/x/errorchain"
+)
+
+const defaultKeySetTTL = 10 * time.Minute
+
+// keySet provides the keys used to verify tokens, which are not JWTs, like PASETO tokens or macaroons.
+type keySet interface {
+	Keys(ctx context.Context, ttl time.Duration) ([]jose.JSONWebKey, error)
+}
+
+// newKeySet creates a key set either from a local JWKS file, or from a JWKS endpoint. Key sets
+// holding secret keys, like the symmetric root keys of macaroons, are cached in process memory
+// only and are never written to the cache, which might be shared with other instances.
+func newKeySet(path string, ep *endpoint.Endpoint, fw watcher.Watcher, secret bool) (keySet, error) {
+	if len(path) != 0 {
+		return newFileKeySet(path, fw)
+	}
+
+	if ep.Headers == nil {
+		ep.Headers = make(map[string]string)
+	}
+
+	if _, ok := ep.Headers["Accept"]; !ok {
+		ep.Headers["Accept"] = "application/json"
+	}
+
+	if len(ep.Method) == 0 {
+		ep.Method = http.MethodGet
+	}
+
+	return &endpointKeySet{ep: ep, secret: secret}, nil
+}
+
+// fileKeySet holds the keys from a JWKS file. The file is reloaded on changes.
+type fileKeySet struct {
+	path string
+
+	mut  sync.RWMutex
+	keys []jose.JSONWebKey
+}
+
+func newFileKeySet(path string, fw watcher.Watcher) (*fileKeySet, error) {
+	ks := &fileKeySet{path: path}
+
+	if err := ks.load(); err != nil {
+		return nil, err
+	}
+
+	if err := fw.Add(ks.path, ks); err != nil {
+		return nil, errorchain.NewWithMessage(heimdall.ErrInternal, "failed registering jwks file for updates").
+			CausedBy(err)
+	}
+
+	return ks, nil
+}
+
+func (s *fileKeySet) OnChanged(logger zerolog.Logger) {
+	if err := s.load(); err != nil {
+		logger.Warn().Err(err).
+			Str("_file", s.path).
+			Msg("JWKS file reload failed")
+	} else {
+		logger.Info().
+			Str("_file", s.path).
+			Msg("JWKS file reloaded")
+	}
+}
+
+func (s *fileKeySet) Keys(_ context.Context, _ time.Duration) ([]jose.JSONWebKey, error) {
+	s.mut.RLock()
+	defer s.mut.RUnlock()
+
+	return s.keys, nil
+}
+
+func (s *fileKeySet) load() error {
+	contents, err := os.ReadFile(s.path)
+	if err != nil {
+		return errorchain.NewWithMessagef(heimdall.ErrConfiguration, "failed to read %s", s.path).
+			CausedBy(err)
+	}
+
+	var jwks jose.JSONWebKeySet
+	if err = json.Unmarshal(contents, &jwks); err != nil {
+		return errorchain.NewWithMessagef(heimdall.ErrConfiguration, "failed to parse %s", s.path).
+			CausedBy(err)
+	}
+
+	s.mut.Lock()
+	s.keys = jwks.Keys
+	s.mut.Unlock()
+
+	return nil
+}
+
+// endpointKeySet retrieves the keys from a JWKS endpoint. Retrieved key sets are cached.
+type endpointKeySet struct {
+	ep     *endpoint.Endpoint
+	secret bool
+
+	// used for secret key sets only
+	mut       sync.Mutex
+	keys      []jose.JSONWebKey
+	fetchedAt time.Time
+}
+
+func (s *endpointKeySet) Keys(ctx context.Context, ttl time.Duration) ([]jose.JSONWebKey, error) {
+	if s.secret {
+		return s.keysFromMemory(ctx, ttl)
+	}
+
+	logger := zerolog.Ctx(ctx)
+	cch := cache.Ctx(ctx)
+	cacheKey := hex.EncodeToString(s.ep.Hash())
+
+	if ttl > 0 {
+		if entry, err := cch.Get(ctx, cacheKey); err == nil {
+			var jwks jose.JSONWebKeySet
+
+			if err = json.Unmarshal(entry, &jwks); err == nil {
+				logger.Debug().Msg("Reusing JWKS from cache")
+
+				return jwks.Keys, nil
+			}
+		}
+	}
+
+	rawJWKS, keys, err := s.fetch(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if ttl > 0 {
+		if err = cch.Set(ctx, cacheKey, rawJWKS, ttl); err != nil {
+			logger.Warn().Err(err).Msg("Failed to cache JWKS")
+		}
+	}
+
+	return keys, nil
+}
+
+func (s *endpointKeySet) keysFromMemory(ctx context.Context, ttl time.Duration) ([]jose.JSONWebKey, error) {
+	s.mut.Lock()
+	defer s.mut.Unlock()
+
+	if ttl > 0 && s.keys != nil && time.Since(s.fetchedAt) < ttl {
+		zerolog.Ctx(ctx).Debug().Msg("Reusing JWKS from memory")
+
+		return s.keys, nil
+	}
+
+	_, keys, err := s.fetch(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if ttl > 0 {
+		s.keys, s.fetchedAt = keys, time.Now()
+	}
+
+	return keys, nil
+}
+
+func (s *endpointKeySet) fetch(ctx context.Context) ([]byte, []jose.JSONWebKey, error) {
+	zerolog.Ctx(ctx).Debug().Msg("Retrieving JWKS from configured endpoint")
+
+	rawJWKS, err := s.ep.SendRequest(ctx, nil, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var jwks jose.JSONWebKeySet
+	if err = json.Unmarshal(rawJWKS, &jwks); err != nil {
+		return nil, nil, errorchain.NewWithMessage(heimdall.ErrInternal, "failed to unmarshal received jwks").
+			CausedBy(err)
+	}
+
+	return rawJWKS, jwks.Keys, nil
+}
@@ -0,0 +1,381 @@
+// Copyright 2025 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package authenticators
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/go-jose/go-jose/v4"
+	"github.com/goccy/go-json"
+	"github.com/rs/zerolog/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dadrus/heimdall/internal/cache"
+	"github.com/dadrus/heimdall/internal/cache/mocks"
+	"github.com/dadrus/heimdall/internal/heimdall"
+	"github.com/dadrus/heimdall/internal/rules/endpoint"
+	mocks2 "github.com/dadrus/heimdall/internal/watcher/mocks"
+)
+
+func writeJWKSFile(t *testing.T, path string, jwks jose.JSONWebKeySet) {
+	t.Helper()
+
+	data, err := json.Marshal(jwks)
+	require.NoError(t, err)
+
+	err = os.WriteFile(path, data, 0o600)
+	require.NoError(t, err)
+}
+
+func TestFileKeySet(t *testing.T) {
+	t.Parallel()
+
+	testDir := t.TempDir()
+
+	pubKey, _, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	validFile := filepath.Join(testDir, "jwks.json")
+	writeJWKSFile(t, validFile, jose.JSONWebKeySet{Keys: []jose.JSONWebKey{
+		{KeyID: "foo", Key: pubKey},
+		{KeyID: "bar", Key: []byte("secret")},
+	}})
+
+	malformedFile := filepath.Join(testDir, "malformed.json")
+	err = os.WriteFile(malformedFile, []byte("foo"), 0o600)
+	require.NoError(t, err)
+
+	for uc, tc := range map[string]struct {
+		path      string
+		configure func(t *testing.T, wm *mocks2.WatcherMock)
+		assert    func(t *testing.T, err error, ks keySet)
+	}{
+		"not existing file": {
+			path: "/no/such/file",
+			assert: func(t *testing.T, err error, _ keySet) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrConfiguration)
+				require.ErrorContains(t, err, "failed to read")
+			},
+		},
+		"malformed file": {
+			path: malformedFile,
+			assert: func(t *testing.T, err error, _ keySet) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrConfiguration)
+				require.ErrorContains(t, err, "failed to parse")
+			},
+		},
+		"fails registering file for updates": {
+			path: validFile,
+			configure: func(t *testing.T, wm *mocks2.WatcherMock) {
+				t.Helper()
+
+				wm.EXPECT().Add(validFile, mock.Anything).Return(errors.New("test error"))
+			},
+			assert: func(t *testing.T, err error, _ keySet) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrInternal)
+				require.ErrorContains(t, err, "test error")
+			},
+		},
+		"valid file": {
+			path: validFile,
+			configure: func(t *testing.T, wm *mocks2.WatcherMock) {
+				t.Helper()
+
+				wm.EXPECT().Add(validFile, mock.Anything).Return(nil)
+			},
+			assert: func(t *testing.T, err error, ks keySet) {
+				t.Helper()
+
+				require.NoError(t, err)
+
+				keys, err := ks.Keys(t.Context(), 0)
+				require.NoError(t, err)
+				require.Len(t, keys, 2)
+				assert.Equal(t, "foo", keys[0].KeyID)
+				assert.Equal(t, pubKey, keys[0].Key)
+				assert.Equal(t, "bar", keys[1].KeyID)
+				assert.Equal(t, []byte("secret"), keys[1].Key)
+			},
+		},
+	} {
+		t.Run(uc, func(t *testing.T) {
+			// GIVEN
+			wm := mocks2.NewWatcherMock(t)
+			if tc.configure != nil {
+				tc.configure(t, wm)
+			}
+
+			// WHEN
+			ks, err := newKeySet(tc.path, nil, wm, false)
+
+			// THEN
+			tc.assert(t, err, ks)
+		})
+	}
+}
+
+func TestFileKeySetReload(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	writeJWKSFile(t, path, jose.JSONWebKeySet{Keys: []jose.JSONWebKey{{KeyID: "foo", Key: []byte("foo")}}})
+
+	wm := mocks2.NewWatcherMock(t)
+	wm.EXPECT().Add(path, mock.Anything).Return(nil)
+
+	ks, err := newFileKeySet(path, wm)
+	require.NoError(t, err)
+
+	// WHEN
+	writeJWKSFile(t, path, jose.JSONWebKeySet{Keys: []jose.JSONWebKey{{KeyID: "bar", Key: []byte("bar")}}})
+	ks.OnChanged(log.Logger)
+
+	// THEN
+	keys, err := ks.Keys(t.Context(), 0)
+	require.NoError(t, err)
+	require.Len(t, keys, 1)
+	assert.Equal(t, "bar", keys[0].KeyID)
+
+	// WHEN
+	err = os.WriteFile(path, []byte("foo"), 0o600)
+	require.NoError(t, err)
+	ks.OnChanged(log.Logger)
+
+	// THEN
+	keys, err = ks.Keys(t.Context(), 0)
+	require.NoError(t, err)
+	require.Len(t, keys, 1)
+	assert.Equal(t, "bar", keys[0].KeyID)
+}
+
+func TestEndpointKeySetKeys(t *testing.T) {
+	t.Parallel()
+
+	rawJWKS, err := json.Marshal(jose.JSONWebKeySet{Keys: []jose.JSONWebKey{{KeyID: "foo", Key: []byte("secret")}}})
+	require.NoError(t, err)
+
+	var (
+		endpointCalled bool
+		responseCode   int
+		responseBody   []byte
+	)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		endpointCalled = true
+
+		assert.Equal(t, http.MethodGet, r.Method)
+		assert.Equal(t, "application/json", r.Header.Get("Accept"))
+
+		if responseBody == nil {
+			w.WriteHeader(responseCode)
+
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_, err := w.Write(responseBody)
+		assert.NoError(t, err)
+	}))
+	defer srv.Close()
+
+	ks, err := newKeySet("", &endpoint.Endpoint{URL: srv.URL}, nil, false)
+	require.NoError(t, err)
+
+	for uc, tc := range map[string]struct {
+		ttl       time.Duration
+		configure func(t *testing.T, cch *mocks.CacheMock)
+		assert    func(t *testing.T, err error, keys []jose.JSONWebKey)
+	}{
+		"with error while retrieving jwks": {
+			ttl: 10 * time.Second,
+			configure: func(t *testing.T, cch *mocks.CacheMock) {
+				t.Helper()
+
+				responseCode = http.StatusBadGateway
+
+				cch.EXPECT().Get(mock.Anything, mock.Anything).Return(nil, errors.New("no cache entry"))
+			},
+			assert: func(t *testing.T, err error, _ []jose.JSONWebKey) {
+				t.Helper()
+
+				assert.True(t, endpointCalled)
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrCommunication)
+			},
+		},
+		"with malformed jwks": {
+			configure: func(t *testing.T, _ *mocks.CacheMock) {
+				t.Helper()
+
+				responseBody = []byte("foo")
+			},
+			assert: func(t *testing.T, err error, _ []jose.JSONWebKey) {
+				t.Helper()
+
+				assert.True(t, endpointCalled)
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrInternal)
+				require.ErrorContains(t, err, "failed to unmarshal")
+			},
+		},
+		"with disabled cache": {
+			configure: func(t *testing.T, _ *mocks.CacheMock) {
+				t.Helper()
+
+				responseBody = rawJWKS
+			},
+			assert: func(t *testing.T, err error, keys []jose.JSONWebKey) {
+				t.Helper()
+
+				assert.True(t, endpointCalled)
+
+				require.NoError(t, err)
+				require.Len(t, keys, 1)
+				assert.Equal(t, "foo", keys[0].KeyID)
+			},
+		},
+		"with enabled cache and cache miss": {
+			ttl: 10 * time.Second,
+			configure: func(t *testing.T, cch *mocks.CacheMock) {
+				t.Helper()
+
+				responseBody = rawJWKS
+
+				cch.EXPECT().Get(mock.Anything, mock.Anything).Return(nil, errors.New("no cache entry"))
+				cch.EXPECT().Set(mock.Anything, mock.Anything, rawJWKS, 10*time.Second).Return(nil)
+			},
+			assert: func(t *testing.T, err error, keys []jose.JSONWebKey) {
+				t.Helper()
+
+				assert.True(t, endpointCalled)
+
+				require.NoError(t, err)
+				require.Len(t, keys, 1)
+				assert.Equal(t, "foo", keys[0].KeyID)
+			},
+		},
+		"with enabled cache and cache hit": {
+			ttl: 10 * time.Second,
+			configure: func(t *testing.T, cch *mocks.CacheMock) {
+				t.Helper()
+
+				cch.EXPECT().Get(mock.Anything, mock.Anything).Return(rawJWKS, nil)
+			},
+			assert: func(t *testing.T, err error, keys []jose.JSONWebKey) {
+				t.Helper()
+
+				assert.False(t, endpointCalled)
+
+				require.NoError(t, err)
+				require.Len(t, keys, 1)
+				assert.Equal(t, []byte("secret"), keys[0].Key)
+			},
+		},
+	} {
+		t.Run(uc, func(t *testing.T) {
+			// GIVEN
+			endpointCalled = false
+			responseCode = http.StatusOK
+			responseBody = nil
+
+			cch := mocks.NewCacheMock(t)
+			tc.configure(t, cch)
+
+			// WHEN
+			keys, err := ks.Keys(cache.WithContext(t.Context(), cch), tc.ttl)
+
+			// THEN
+			tc.assert(t, err, keys)
+		})
+	}
+}
+
+func TestSecretEndpointKeySetKeys(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	rawJWKS, err := json.Marshal(jose.JSONWebKeySet{Keys: []jose.JSONWebKey{{KeyID: "foo", Key: []byte("secret")}}})
+	require.NoError(t, err)
+
+	var calls atomic.Int32
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		calls.Add(1)
+
+		w.Header().Set("Content-Type", "application/json")
+		_, err := w.Write(rawJWKS)
+		assert.NoError(t, err)
+	}))
+	defer srv.Close()
+
+	ks, err := newKeySet("", &endpoint.Endpoint{URL: srv.URL}, nil, true)
+	require.NoError(t, err)
+
+	// the shared cache must never be used
+	ctx := cache.WithContext(t.Context(), mocks.NewCacheMock(t))
+
+	// WHEN
+	keys1, err1 := ks.Keys(ctx, 100*time.Millisecond)
+	keys2, err2 := ks.Keys(ctx, 100*time.Millisecond)
+
+	// THEN
+	require.NoError(t, err1)
+	require.NoError(t, err2)
+	assert.Equal(t, keys1, keys2)
+	require.Len(t, keys1, 1)
+	assert.Equal(t, []byte("secret"), keys1[0].Key)
+	assert.Equal(t, int32(1), calls.Load())
+
+	// WHEN
+	time.Sleep(150 * time.Millisecond)
+
+	_, err = ks.Keys(ctx, 100*time.Millisecond)
+
+	// THEN
+	require.NoError(t, err)
+	assert.Equal(t, int32(2), calls.Load())
+
+	// WHEN
+	_, err = ks.Keys(ctx, 0)
+
+	// THEN
+	require.NoError(t, err)
+	assert.Equal(t, int32(3), calls.Load())
+}
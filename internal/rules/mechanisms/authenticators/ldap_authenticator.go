@@ -0,0 +1,316 @@
+// Copyright 2025 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package authenticators
+
+import (
+	"encoding/base64"
+	"errors"
+	"slices"
+	"strings"
+
+	ldapv3 "github.com/go-ldap/ldap/v3"
+	"github.com/rs/zerolog"
+
+	"github.com/dadrus/heimdall/internal/app"
+	"github.com/dadrus/heimdall/internal/heimdall"
+	"github.com/dadrus/heimdall/internal/rules/mechanisms/authenticators/extractors"
+	"github.com/dadrus/heimdall/internal/rules/mechanisms/ldap"
+	"github.com/dadrus/heimdall/internal/rules/mechanisms/subject"
+	"github.com/dadrus/heimdall/internal/rules/mechanisms/template"
+	"github.com/dadrus/heimdall/internal/x/errorchain"
+)
+
+// the size limit used for user searches. A limit of 1 would not allow
+// detecting ambiguous search results.
+const ldapUserSearchSizeLimit = 2
+
+var errLDAPUserNotFound = errors.New("user not found")
+
+// by intention. Used only during application bootstrap
+//
+//nolint:gochecknoinits
+func init() {
+	registerTypeFactory(
+		func(app app.Context, id string, typ string, conf map[string]any) (bool, Authenticator, error) {
+			if typ != AuthenticatorLDAP {
+				return false, nil, nil
+			}
+
+			auth, err := newLDAPAuthenticator(app, id, conf)
+
+			return true, auth, err
+		})
+}
+
+type ldapUser struct {
+	DN          template.Template `mapstructure:"dn"           validate:"required_without=Search,excluded_with=Search"`
+	Search      *ldap.Search      `mapstructure:"search"       validate:"required_without=DN,excluded_with=DN"`
+	Attributes  []string          `mapstructure:"attributes"`
+	IDAttribute string            `mapstructure:"id_attribute"`
+}
+
+type ldapAuthenticator struct {
+	id     string
+	client *ldap.Client
+	user   ldapUser
+	groups *ldap.Groups
+}
+
+func newLDAPAuthenticator(app app.Context, id string, rawConfig map[string]any) (*ldapAuthenticator, error) {
+	logger := app.Logger()
+	logger.Info().Str("_id", id).Msg("Creating ldap authenticator")
+
+	type Config struct {
+		Server ldap.Server  `mapstructure:"server" validate:"required"`
+		User   ldapUser     `mapstructure:"user"   validate:"required"`
+		Groups *ldap.Groups `mapstructure:"groups"`
+	}
+
+	var conf Config
+	if err := decodeConfig(app, rawConfig, &conf); err != nil {
+		return nil, errorchain.NewWithMessagef(heimdall.ErrConfiguration,
+			"failed decoding config for ldap authenticator '%s'", id).CausedBy(err)
+	}
+
+	client, err := ldap.NewClient(conf.Server)
+	if err != nil {
+		return nil, errorchain.NewWithMessagef(heimdall.ErrConfiguration,
+			"failed creating ldap client for ldap authenticator '%s'", id).CausedBy(err)
+	}
+
+	if !client.IsSecure() {
+		logger.Warn().Str("_id", id).
+			Msg("No TLS configured for the communication with the ldap server. " +
+				"User credentials will be transmitted in plain text. NEVER DO IT IN PRODUCTION!!!")
+	}
+
+	return &ldapAuthenticator{
+		id:     id,
+		client: client,
+		user:   conf.User,
+		groups: conf.Groups,
+	}, nil
+}
+
+func (a *ldapAuthenticator) Execute(ctx heimdall.RequestContext) (*subject.Subject, error) {
+	logger := zerolog.Ctx(ctx.Context())
+	logger.Debug().Str("_id", a.id).Msg("Authenticating using ldap authenticator")
+
+	strategy := extractors.HeaderValueExtractStrategy{Name: "Authorization", Scheme: "Basic"}
+
+	authData, err := strategy.GetAuthData(ctx)
+	if err != nil {
+		return nil, errorchain.
+			NewWithMessage(heimdall.ErrAuthentication, "expected header not present in request").
+			WithErrorContext(a).
+			CausedBy(err)
+	}
+
+	res, err := base64.StdEncoding.DecodeString(authData)
+	if err != nil {
+		return nil, errorchain.
+			NewWithMessage(heimdall.ErrAuthentication, "failed to decode received credentials value").
+			WithErrorContext(a)
+	}
+
+	// as defined by RFC 7617, the user-id must not contain a colon, but the password may
+	userID, password, found := strings.Cut(string(res), ":")
+	if !found {
+		return nil, errorchain.
+			NewWithMessage(heimdall.ErrAuthentication, "malformed user-id - password scheme").
+			WithErrorContext(a)
+	}
+
+	var sub *subject.Subject
+
+	err = a.client.Do(func(conn *ldap.Conn) error {
+		sub, err = a.authenticate(conn, userID, password)
+
+		return err
+	})
+	if err != nil {
+		return nil, a.mapError(err)
+	}
+
+	return sub, nil
+}
+
+func (a *ldapAuthenticator) authenticate(conn *ldap.Conn, userID, password string) (*subject.Subject, error) {
+	var (
+		entry ldap.Entry
+		err   error
+	)
+
+	if a.user.Search != nil {
+		entry, err = a.searchUser(conn, userID)
+		if err != nil {
+			return nil, err
+		}
+
+		if err = conn.Bind(entry.DN, password); err != nil {
+			return nil, err
+		}
+	} else {
+		entry, err = a.bindUser(conn, userID, password)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	attributes := entry.AttributeMap()
+	attributes["dn"] = entry.DN
+
+	if a.groups != nil {
+		groups, err := a.groups.MemberOf(conn, entry.DN)
+		if err != nil {
+			return nil, err
+		}
+
+		attributes["groups"] = ldap.ToAnySlice(groups)
+	}
+
+	subjectID, err := a.subjectID(entry)
+	if err != nil {
+		return nil, err
+	}
+
+	return &subject.Subject{ID: subjectID, Attributes: attributes}, nil
+}
+
+// subjectID returns the canonical identifier of the user, which is either the dn of the
+// entry, or the value of the configured id attribute. The user-id provided by the client
+// is not used, as different user-ids may refer to the same entry, e.g. because of case
+// insensitive matching by the ldap server.
+func (a *ldapAuthenticator) subjectID(entry ldap.Entry) (string, error) {
+	if len(a.user.IDAttribute) == 0 {
+		return entry.DN, nil
+	}
+
+	values := entry.Values(a.user.IDAttribute)
+	if len(values) != 1 || len(values[0]) == 0 {
+		return "", errorchain.NewWithMessagef(heimdall.ErrInternal,
+			"user entry has no unique value for the '%s' id attribute", a.user.IDAttribute)
+	}
+
+	return values[0], nil
+}
+
+func (a *ldapAuthenticator) searchUser(conn *ldap.Conn, userID string) (ldap.Entry, error) {
+	req, err := a.user.Search.Request(
+		map[string]any{"Username": ldapv3.EscapeFilter(userID)},
+		a.requestedAttributes(),
+	)
+	if err != nil {
+		return ldap.Entry{}, err
+	}
+
+	req.SizeLimit = ldapUserSearchSizeLimit
+
+	entries, err := conn.Search(req)
+	if err != nil {
+		return ldap.Entry{}, err
+	}
+
+	// an ambiguous result is treated the same way as a not existing user
+	if len(entries) != 1 {
+		return ldap.Entry{}, errLDAPUserNotFound
+	}
+
+	return entries[0], nil
+}
+
+func (a *ldapAuthenticator) bindUser(conn *ldap.Conn, userID, password string) (ldap.Entry, error) {
+	dn, err := a.user.DN.Render(map[string]any{"Username": ldapv3.EscapeDN(userID)})
+	if err != nil {
+		return ldap.Entry{}, errorchain.NewWithMessage(heimdall.ErrInternal, "failed to render user dn").
+			CausedBy(err)
+	}
+
+	if err = conn.Bind(dn, password); err != nil {
+		return ldap.Entry{}, err
+	}
+
+	attributes := a.userAttributes()
+	if len(attributes) == 0 {
+		return ldap.Entry{DN: dn}, nil
+	}
+
+	entries, err := conn.Search(ldap.SearchRequest{
+		BaseDN:     dn,
+		Scope:      ldap.ScopeBase,
+		Filter:     "(objectClass=*)",
+		Attributes: attributes,
+	})
+	if err != nil {
+		return ldap.Entry{}, err
+	}
+
+	if len(entries) == 0 {
+		return ldap.Entry{DN: dn}, nil
+	}
+
+	return entries[0], nil
+}
+
+func (a *ldapAuthenticator) requestedAttributes() []string {
+	if attributes := a.userAttributes(); len(attributes) != 0 {
+		return attributes
+	}
+
+	return []string{ldap.NoAttributes}
+}
+
+// userAttributes returns the attributes to retrieve for the user entry, which include the
+// id attribute, if configured.
+func (a *ldapAuthenticator) userAttributes() []string {
+	if len(a.user.IDAttribute) == 0 ||
+		slices.ContainsFunc(a.user.Attributes, func(attr string) bool {
+			return strings.EqualFold(attr, a.user.IDAttribute)
+		}) {
+		return a.user.Attributes
+	}
+
+	return append(slices.Clone(a.user.Attributes), a.user.IDAttribute)
+}
+
+func (a *ldapAuthenticator) mapError(err error) error {
+	if errors.Is(err, ldap.ErrInvalidCredentials) || errors.Is(err, errLDAPUserNotFound) {
+		return errorchain.
+			NewWithMessage(heimdall.ErrAuthentication, "invalid user credentials").
+			WithErrorContext(a)
+	}
+
+	var chain *errorchain.ErrorChain
+	if errors.As(err, &chain) {
+		return chain.WithErrorContext(a)
+	}
+
+	return errorchain.New(heimdall.ErrInternal).WithErrorContext(a).CausedBy(err)
+}
+
+func (a *ldapAuthenticator) WithConfig(rawConfig map[string]any) (Authenticator, error) {
+	if len(rawConfig) == 0 {
+		return a, nil
+	}
+
+	return nil, errorchain.NewWithMessagef(heimdall.ErrConfiguration,
+		"reconfiguration of the ldap authenticator '%s' is not supported", a.id)
+}
+
+func (a *ldapAuthenticator) IsInsecure() bool { return false }
+
+func (a *ldapAuthenticator) ID() string { return a.id }
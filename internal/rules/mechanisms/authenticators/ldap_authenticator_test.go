@@ -0,0 +1,620 @@
+// Copyright 2025 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package authenticators
+
+import (
+	"encoding/base64"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/rs/zerolog/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dadrus/heimdall/internal/app"
+	"github.com/dadrus/heimdall/internal/heimdall"
+	"github.com/dadrus/heimdall/internal/heimdall/mocks"
+	"github.com/dadrus/heimdall/internal/rules/mechanisms/ldap/ldaptest"
+	"github.com/dadrus/heimdall/internal/rules/mechanisms/subject"
+	"github.com/dadrus/heimdall/internal/validation"
+	"github.com/dadrus/heimdall/internal/x/testsupport"
+)
+
+func ldapTestEntries() []ldaptest.Entry {
+	return []ldaptest.Entry{
+		{
+			DN:       "cn=service,dc=example,dc=org",
+			Password: "secret",
+		},
+		{
+			DN:       "uid=alice,ou=people,dc=example,dc=org",
+			Password: "alice-pw",
+			Attributes: map[string][]string{
+				"uid":  {"alice"},
+				"mail": {"alice@example.org"},
+			},
+		},
+		{
+			DN:       "uid=bob,ou=people,dc=example,dc=org",
+			Password: "bob-pw",
+			Attributes: map[string][]string{
+				"uid":  {"bob"},
+				"mail": {"bob@example.org"},
+			},
+		},
+		{
+			DN: "cn=admins,ou=groups,dc=example,dc=org",
+			Attributes: map[string][]string{
+				"cn":     {"admins"},
+				"member": {"uid=alice,ou=people,dc=example,dc=org"},
+			},
+		},
+		{
+			DN: "cn=devs,ou=groups,dc=example,dc=org",
+			Attributes: map[string][]string{
+				"cn": {"devs"},
+				"member": {
+					"uid=alice,ou=people,dc=example,dc=org",
+					"uid=bob,ou=people,dc=example,dc=org",
+				},
+			},
+		},
+	}
+}
+
+func TestCreateLDAPAuthenticator(t *testing.T) {
+	t.Parallel()
+
+	for uc, tc := range map[string]struct {
+		config []byte
+		assert func(t *testing.T, err error, auth *ldapAuthenticator)
+	}{
+		"without configuration": {
+			assert: func(t *testing.T, err error, _ *ldapAuthenticator) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrConfiguration)
+				assert.Contains(t, err.Error(), "'server' is a required field")
+			},
+		},
+		"with unsupported attributes": {
+			config: []byte(`
+server:
+  url: ldap://localhost:389
+user:
+  dn: "uid={{ .Username }},ou=people,dc=example,dc=org"
+foo: bar
+`),
+			assert: func(t *testing.T, err error, _ *ldapAuthenticator) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrConfiguration)
+				assert.Contains(t, err.Error(), "failed decoding")
+			},
+		},
+		"without user dn and search": {
+			config: []byte(`
+server:
+  url: ldap://localhost:389
+user:
+  attributes: [ mail ]
+`),
+			assert: func(t *testing.T, err error, _ *ldapAuthenticator) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrConfiguration)
+				assert.Contains(t, err.Error(), "'user'.'dn' is a required field")
+			},
+		},
+		"with both user dn and search": {
+			config: []byte(`
+server:
+  url: ldap://localhost:389
+user:
+  dn: "uid={{ .Username }},ou=people,dc=example,dc=org"
+  search:
+    base_dn: ou=people,dc=example,dc=org
+    filter: "(uid={{ .Username }})"
+`),
+			assert: func(t *testing.T, err error, _ *ldapAuthenticator) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrConfiguration)
+				assert.Contains(t, err.Error(), "'user'.'dn' is an excluded field")
+			},
+		},
+		"with user search without filter": {
+			config: []byte(`
+server:
+  url: ldap://localhost:389
+user:
+  search:
+    base_dn: ou=people,dc=example,dc=org
+`),
+			assert: func(t *testing.T, err error, _ *ldapAuthenticator) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrConfiguration)
+				assert.Contains(t, err.Error(), "'user'.'search'.'filter' is a required field")
+			},
+		},
+		"with bind dn, but without bind password": {
+			config: []byte(`
+server:
+  url: ldap://localhost:389
+  bind_dn: cn=service,dc=example,dc=org
+user:
+  dn: "uid={{ .Username }},ou=people,dc=example,dc=org"
+`),
+			assert: func(t *testing.T, err error, _ *ldapAuthenticator) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrConfiguration)
+				assert.Contains(t, err.Error(), "'server'.'bind_password' is a required field")
+			},
+		},
+		"with unsupported server url scheme": {
+			config: []byte(`
+server:
+  url: http://localhost:389
+user:
+  dn: "uid={{ .Username }},ou=people,dc=example,dc=org"
+`),
+			assert: func(t *testing.T, err error, _ *ldapAuthenticator) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrConfiguration)
+				assert.Contains(t, err.Error(), "failed creating ldap client")
+			},
+		},
+		"with valid configuration using user dn": {
+			config: []byte(`
+server:
+  url: ldap://localhost:389
+user:
+  dn: "uid={{ .Username }},ou=people,dc=example,dc=org"
+  attributes: [ mail ]
+`),
+			assert: func(t *testing.T, err error, auth *ldapAuthenticator) {
+				t.Helper()
+
+				require.NoError(t, err)
+				require.NotNil(t, auth)
+				assert.Equal(t, "with valid configuration using user dn", auth.ID())
+				assert.NotNil(t, auth.client)
+				assert.NotNil(t, auth.user.DN)
+				assert.Nil(t, auth.user.Search)
+				assert.Equal(t, []string{"mail"}, auth.user.Attributes)
+				assert.Nil(t, auth.groups)
+				assert.False(t, auth.IsInsecure())
+			},
+		},
+		"with valid configuration using user search and groups": {
+			config: []byte(`
+server:
+  url: ldap://localhost:389
+  start_tls: true
+  bind_dn: cn=service,dc=example,dc=org
+  bind_password: secret
+user:
+  search:
+    base_dn: ou=people,dc=example,dc=org
+    filter: "(uid={{ .Username }})"
+    scope: one
+groups:
+  base_dn: ou=groups,dc=example,dc=org
+  filter: "(member={{ .DN }})"
+  attribute: cn
+`),
+			assert: func(t *testing.T, err error, auth *ldapAuthenticator) {
+				t.Helper()
+
+				require.NoError(t, err)
+				require.NotNil(t, auth)
+				assert.True(t, auth.client.IsSecure())
+				assert.Nil(t, auth.user.DN)
+				require.NotNil(t, auth.user.Search)
+				assert.Equal(t, "ou=people,dc=example,dc=org", auth.user.Search.BaseDN)
+				require.NotNil(t, auth.groups)
+				assert.Equal(t, "cn", auth.groups.NameAttribute())
+			},
+		},
+	} {
+		t.Run(uc, func(t *testing.T) {
+			// GIVEN
+			conf, err := testsupport.DecodeTestConfig(tc.config)
+			require.NoError(t, err)
+
+			validator, err := validation.NewValidator()
+			require.NoError(t, err)
+
+			appCtx := app.NewContextMock(t)
+			appCtx.EXPECT().Validator().Maybe().Return(validator)
+			appCtx.EXPECT().Logger().Return(log.Logger)
+
+			// WHEN
+			auth, err := newLDAPAuthenticator(appCtx, uc, conf)
+
+			// THEN
+			tc.assert(t, err, auth)
+		})
+	}
+}
+
+func TestCreateLDAPAuthenticatorFromPrototype(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	conf, err := testsupport.DecodeTestConfig([]byte(`
+server:
+  url: ldap://localhost:389
+user:
+  dn: "uid={{ .Username }},ou=people,dc=example,dc=org"
+`))
+	require.NoError(t, err)
+
+	validator, err := validation.NewValidator()
+	require.NoError(t, err)
+
+	appCtx := app.NewContextMock(t)
+	appCtx.EXPECT().Validator().Return(validator)
+	appCtx.EXPECT().Logger().Return(log.Logger)
+
+	prototype, err := newLDAPAuthenticator(appCtx, "foo", conf)
+	require.NoError(t, err)
+
+	// WHEN
+	auth1, err1 := prototype.WithConfig(nil)
+	_, err2 := prototype.WithConfig(map[string]any{"user": map[string]any{"attributes": []string{"mail"}}})
+
+	// THEN
+	require.NoError(t, err1)
+	assert.Equal(t, prototype, auth1)
+
+	require.Error(t, err2)
+	require.ErrorIs(t, err2, heimdall.ErrConfiguration)
+	assert.Contains(t, err2.Error(), "reconfiguration of the ldap authenticator 'foo' is not supported")
+}
+
+func TestLDAPAuthenticatorExecute(t *testing.T) {
+	t.Parallel()
+
+	srv := ldaptest.NewServer(t, ldaptest.Plain, ldapTestEntries()...)
+
+	basicAuth := func(userID, password string) string {
+		return "Basic " + base64.StdEncoding.EncodeToString([]byte(userID+":"+password))
+	}
+
+	dnConfig := `
+server:
+  url: ` + srv.URL + `
+user:
+  dn: "uid={{ .Username }},ou=people,dc=example,dc=org"
+`
+
+	searchConfig := `
+server:
+  url: ` + srv.URL + `
+  bind_dn: cn=service,dc=example,dc=org
+  bind_password: secret
+user:
+  search:
+    base_dn: ou=people,dc=example,dc=org
+    filter: "(uid={{ .Username }})"
+  attributes: [ mail ]
+groups:
+  base_dn: ou=groups,dc=example,dc=org
+  filter: "(member={{ .DN }})"
+`
+
+	for uc, tc := range map[string]struct {
+		config        string
+		authorization string
+		assert        func(t *testing.T, err error, sub *subject.Subject)
+	}{
+		"no authorization header": {
+			config: dnConfig,
+			assert: func(t *testing.T, err error, _ *subject.Subject) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrAuthentication)
+				assert.Contains(t, err.Error(), "expected header not present")
+
+				var identifier interface{ ID() string }
+				require.ErrorAs(t, err, &identifier)
+				assert.Equal(t, "ldap", identifier.ID())
+			},
+		},
+		"not base64 encoded credentials": {
+			config:        dnConfig,
+			authorization: "Basic foo:bar",
+			assert: func(t *testing.T, err error, _ *subject.Subject) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrAuthentication)
+				assert.Contains(t, err.Error(), "failed to decode")
+			},
+		},
+		"malformed credentials": {
+			config:        dnConfig,
+			authorization: "Basic " + base64.StdEncoding.EncodeToString([]byte("alice")),
+			assert: func(t *testing.T, err error, _ *subject.Subject) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrAuthentication)
+				assert.Contains(t, err.Error(), "malformed user-id - password scheme")
+			},
+		},
+		"wrong password using user dn": {
+			config:        dnConfig,
+			authorization: basicAuth("alice", "bob-pw"),
+			assert: func(t *testing.T, err error, _ *subject.Subject) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrAuthentication)
+				assert.Contains(t, err.Error(), "invalid user credentials")
+
+				var identifier interface{ ID() string }
+				require.ErrorAs(t, err, &identifier)
+				assert.Equal(t, "ldap", identifier.ID())
+			},
+		},
+		"empty password using user dn": {
+			config:        dnConfig,
+			authorization: basicAuth("alice", ""),
+			assert: func(t *testing.T, err error, _ *subject.Subject) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrAuthentication)
+				assert.Contains(t, err.Error(), "invalid user credentials")
+			},
+		},
+		"user name trying to inject a dn component": {
+			config:        dnConfig,
+			authorization: basicAuth("alice,ou=people", "alice-pw"),
+			assert: func(t *testing.T, err error, _ *subject.Subject) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrAuthentication)
+				assert.Contains(t, err.Error(), "invalid user credentials")
+			},
+		},
+		"valid credentials using user dn": {
+			config:        dnConfig,
+			authorization: basicAuth("alice", "alice-pw"),
+			assert: func(t *testing.T, err error, sub *subject.Subject) {
+				t.Helper()
+
+				require.NoError(t, err)
+				assert.Equal(t, &subject.Subject{
+					ID:         "uid=alice,ou=people,dc=example,dc=org",
+					Attributes: map[string]any{"dn": "uid=alice,ou=people,dc=example,dc=org"},
+				}, sub)
+			},
+		},
+		"unknown user using user search": {
+			config:        searchConfig,
+			authorization: basicAuth("carol", "carol-pw"),
+			assert: func(t *testing.T, err error, _ *subject.Subject) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrAuthentication)
+				assert.Contains(t, err.Error(), "invalid user credentials")
+			},
+		},
+		"user name trying to inject a filter using user search": {
+			config:        searchConfig,
+			authorization: basicAuth("*", "alice-pw"),
+			assert: func(t *testing.T, err error, _ *subject.Subject) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrAuthentication)
+				assert.Contains(t, err.Error(), "invalid user credentials")
+			},
+		},
+		"wrong password using user search": {
+			config:        searchConfig,
+			authorization: basicAuth("bob", "alice-pw"),
+			assert: func(t *testing.T, err error, _ *subject.Subject) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrAuthentication)
+				assert.Contains(t, err.Error(), "invalid user credentials")
+			},
+		},
+		"valid credentials using user search": {
+			config:        searchConfig,
+			authorization: basicAuth("alice", "alice-pw"),
+			assert: func(t *testing.T, err error, sub *subject.Subject) {
+				t.Helper()
+
+				require.NoError(t, err)
+				require.NotNil(t, sub)
+				assert.Equal(t, "uid=alice,ou=people,dc=example,dc=org", sub.ID)
+				assert.Equal(t, "uid=alice,ou=people,dc=example,dc=org", sub.Attributes["dn"])
+				assert.Equal(t, []any{"alice@example.org"}, sub.Attributes["mail"])
+				assert.ElementsMatch(t, []any{"admins", "devs"}, sub.Attributes["groups"])
+			},
+		},
+		"valid credentials of another user using user search": {
+			config:        searchConfig,
+			authorization: basicAuth("bob", "bob-pw"),
+			assert: func(t *testing.T, err error, sub *subject.Subject) {
+				t.Helper()
+
+				require.NoError(t, err)
+				require.NotNil(t, sub)
+				assert.Equal(t, "uid=bob,ou=people,dc=example,dc=org", sub.ID)
+				assert.Equal(t, []any{"bob@example.org"}, sub.Attributes["mail"])
+				assert.Equal(t, []any{"devs"}, sub.Attributes["groups"])
+			},
+		},
+		"valid credentials using user dn and attributes": {
+			config:        dnConfig + "  attributes: [ mail, uid ]\n",
+			authorization: basicAuth("bob", "bob-pw"),
+			assert: func(t *testing.T, err error, sub *subject.Subject) {
+				t.Helper()
+
+				require.NoError(t, err)
+				assert.Equal(t, &subject.Subject{
+					ID: "uid=bob,ou=people,dc=example,dc=org",
+					Attributes: map[string]any{
+						"dn":   "uid=bob,ou=people,dc=example,dc=org",
+						"mail": []any{"bob@example.org"},
+						"uid":  []any{"bob"},
+					},
+				}, sub)
+			},
+		},
+		"valid credentials using user dn and id attribute": {
+			config:        dnConfig + "  id_attribute: mail\n",
+			authorization: basicAuth("bob", "bob-pw"),
+			assert: func(t *testing.T, err error, sub *subject.Subject) {
+				t.Helper()
+
+				require.NoError(t, err)
+				assert.Equal(t, &subject.Subject{
+					ID: "bob@example.org",
+					Attributes: map[string]any{
+						"dn":   "uid=bob,ou=people,dc=example,dc=org",
+						"mail": []any{"bob@example.org"},
+					},
+				}, sub)
+			},
+		},
+		"valid credentials using user search and id attribute": {
+			config:        strings.Replace(searchConfig, "  attributes: [ mail ]\n", "  id_attribute: uid\n", 1),
+			authorization: basicAuth("alice", "alice-pw"),
+			assert: func(t *testing.T, err error, sub *subject.Subject) {
+				t.Helper()
+
+				require.NoError(t, err)
+				require.NotNil(t, sub)
+				assert.Equal(t, "alice", sub.ID)
+				assert.Equal(t, []any{"alice"}, sub.Attributes["uid"])
+				assert.NotContains(t, sub.Attributes, "mail")
+			},
+		},
+		"user entry without the configured id attribute": {
+			config:        dnConfig + "  id_attribute: employeeNumber\n",
+			authorization: basicAuth("alice", "alice-pw"),
+			assert: func(t *testing.T, err error, _ *subject.Subject) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrInternal)
+				assert.Contains(t, err.Error(), "no unique value for the 'employeeNumber' id attribute")
+
+				var identifier interface{ ID() string }
+				require.ErrorAs(t, err, &identifier)
+				assert.Equal(t, "ldap", identifier.ID())
+			},
+		},
+	} {
+		t.Run(uc, func(t *testing.T) {
+			// GIVEN
+			conf, err := testsupport.DecodeTestConfig([]byte(tc.config))
+			require.NoError(t, err)
+
+			validator, err := validation.NewValidator()
+			require.NoError(t, err)
+
+			appCtx := app.NewContextMock(t)
+			appCtx.EXPECT().Validator().Return(validator)
+			appCtx.EXPECT().Logger().Return(log.Logger)
+
+			auth, err := newLDAPAuthenticator(appCtx, "ldap", conf)
+			require.NoError(t, err)
+
+			fnt := mocks.NewRequestFunctionsMock(t)
+			fnt.EXPECT().Header("Authorization").Return(tc.authorization)
+
+			ctx := mocks.NewRequestContextMock(t)
+			ctx.EXPECT().Context().Return(t.Context())
+			ctx.EXPECT().Request().Return(&heimdall.Request{RequestFunctions: fnt})
+
+			// WHEN
+			sub, err := auth.Execute(ctx)
+
+			// THEN
+			tc.assert(t, err, sub)
+		})
+	}
+}
+
+func TestLDAPAuthenticatorExecuteWithUnreachableServer(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	port, err := testsupport.GetFreePort()
+	require.NoError(t, err)
+
+	conf, err := testsupport.DecodeTestConfig([]byte(`
+server:
+  url: ldap://127.0.0.1:` + strconv.Itoa(port) + `
+  timeout: 1s
+user:
+  dn: "uid={{ .Username }},ou=people,dc=example,dc=org"
+`))
+	require.NoError(t, err)
+
+	validator, err := validation.NewValidator()
+	require.NoError(t, err)
+
+	appCtx := app.NewContextMock(t)
+	appCtx.EXPECT().Validator().Return(validator)
+	appCtx.EXPECT().Logger().Return(log.Logger)
+
+	auth, err := newLDAPAuthenticator(appCtx, "ldap", conf)
+	require.NoError(t, err)
+
+	fnt := mocks.NewRequestFunctionsMock(t)
+	fnt.EXPECT().Header("Authorization").
+		Return("Basic " + base64.StdEncoding.EncodeToString([]byte("alice:alice-pw")))
+
+	ctx := mocks.NewRequestContextMock(t)
+	ctx.EXPECT().Context().Return(t.Context())
+	ctx.EXPECT().Request().Return(&heimdall.Request{RequestFunctions: fnt})
+
+	// WHEN
+	_, err = auth.Execute(ctx)
+
+	// THEN
+	require.Error(t, err)
+	require.ErrorIs(t, err, heimdall.ErrCommunication)
+
+	var identifier interface{ ID() string }
+	require.ErrorAs(t, err, &identifier)
+	assert.Equal(t, "ldap", identifier.ID())
+}
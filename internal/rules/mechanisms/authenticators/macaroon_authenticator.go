@@ -0,0 +1,333 @@
+// Copyright 2025 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package authenticators
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/go-jose/go-jose/v4"
+	"github.com/goccy/go-json"
+	"github.com/rs/zerolog"
+
+	"github.com/dadrus/heimdall/internal/app"
+	"github.com/dadrus/heimdall/internal/heimdall"
+	"github.com/dadrus/heimdall/internal/rules/endpoint"
+	"github.com/dadrus/heimdall/internal/rules/mechanisms/authenticators/extractors"
+	"github.com/dadrus/heimdall/internal/rules/mechanisms/subject"
+	"github.com/dadrus/heimdall/internal/x"
+	"github.com/dadrus/heimdall/internal/x/errorchain"
+	"github.com/dadrus/heimdall/internal/x/macaroon"
+)
+
+var (
+	errCaveatNotSatisfied = errors.New("caveat not satisfied")
+	errCaveatConflict     = errors.New("conflicting declarations")
+)
+
+// by intention. Used only during application bootstrap
+//
+//nolint:gochecknoinits
+func init() {
+	registerTypeFactory(
+		func(app app.Context, id string, typ string, conf map[string]any) (bool, Authenticator, error) {
+			if typ != AuthenticatorMacaroon {
+				return false, nil, nil
+			}
+
+			auth, err := newMacaroonAuthenticator(app, id, conf)
+
+			return true, auth, err
+		})
+}
+
+type macaroonAuthenticator struct {
+	id               string
+	app              app.Context
+	ks               keySet
+	satisfiedCaveats []string
+	declaredKeys     []string
+	ttl              *time.Duration
+	sf               SubjectFactory
+	ads              extractors.AuthDataExtractStrategy
+}
+
+func newMacaroonAuthenticator(app app.Context, id string, rawConfig map[string]any) (*macaroonAuthenticator, error) {
+	logger := app.Logger()
+	logger.Info().Str("_id", id).Msg("Creating macaroon authenticator")
+
+	type Config struct {
+		JWKSFile         string                              `mapstructure:"jwks_file"               validate:"required_without=JWKSEndpoint,excluded_with=JWKSEndpoint"` //nolint:lll,tagalign
+		JWKSEndpoint     *endpoint.Endpoint                  `mapstructure:"jwks_endpoint"           validate:"required_without=JWKSFile,excluded_with=JWKSFile"`         //nolint:lll,tagalign
+		SatisfiedCaveats []string                            `mapstructure:"satisfied_caveats"`
+		DeclaredKeys     []string                            `mapstructure:"declared_keys"`
+		SubjectInfo      SubjectInfo                         `mapstructure:"subject"                 validate:"-"`
+		AuthDataSource   extractors.CompositeExtractStrategy `mapstructure:"token_source"`
+		CacheTTL         *time.Duration                      `mapstructure:"cache_ttl"`
+	}
+
+	var conf Config
+	if err := decodeConfig(app, rawConfig, &conf); err != nil {
+		return nil, errorchain.NewWithMessagef(heimdall.ErrConfiguration,
+			"failed decoding config for macaroon authenticator '%s'", id).CausedBy(err)
+	}
+
+	if conf.JWKSEndpoint != nil && strings.HasPrefix(conf.JWKSEndpoint.URL, "http://") {
+		logger.Warn().Str("_id", id).
+			Msg("No TLS configured for the jwks endpoint used in macaroon authenticator")
+	}
+
+	if len(conf.SubjectInfo.IDFrom) == 0 {
+		conf.SubjectInfo.IDFrom = "identifier"
+	}
+
+	// the root keys are symmetric and must not leave the process
+	ks, err := newKeySet(conf.JWKSFile, conf.JWKSEndpoint, app.Watcher(), true)
+	if err != nil {
+		return nil, errorchain.NewWithMessagef(heimdall.ErrConfiguration,
+			"failed to create key set for macaroon authenticator '%s'", id).CausedBy(err)
+	}
+
+	ads := x.IfThenElseExec(conf.AuthDataSource == nil,
+		func() extractors.CompositeExtractStrategy {
+			return extractors.CompositeExtractStrategy{
+				extractors.HeaderValueExtractStrategy{Name: "Authorization", Scheme: "Macaroon"},
+				extractors.HeaderValueExtractStrategy{Name: "Authorization", Scheme: "Bearer"},
+			}
+		},
+		func() extractors.CompositeExtractStrategy { return conf.AuthDataSource },
+	)
+
+	return &macaroonAuthenticator{
+		id:               id,
+		app:              app,
+		ks:               ks,
+		satisfiedCaveats: conf.SatisfiedCaveats,
+		declaredKeys:     conf.DeclaredKeys,
+		ttl:              conf.CacheTTL,
+		sf:               &conf.SubjectInfo,
+		ads:              ads,
+	}, nil
+}
+
+func (a *macaroonAuthenticator) Execute(ctx heimdall.RequestContext) (*subject.Subject, error) {
+	logger := zerolog.Ctx(ctx.Context())
+	logger.Debug().Str("_id", a.id).Msg("Authenticating using macaroon authenticator")
+
+	rawToken, err := a.ads.GetAuthData(ctx)
+	if err != nil {
+		return nil, errorchain.
+			NewWithMessage(heimdall.ErrAuthentication, "no macaroon present").
+			WithErrorContext(a).
+			CausedBy(err)
+	}
+
+	mac, err := macaroon.Parse(rawToken)
+	if err != nil {
+		return nil, errorchain.
+			NewWithMessage(heimdall.ErrAuthentication, "failed to parse macaroon").
+			WithErrorContext(a).
+			CausedBy(heimdall.ErrArgument).
+			CausedBy(err)
+	}
+
+	declared, err := a.verifyMacaroon(ctx, mac)
+	if err != nil {
+		return nil, err
+	}
+
+	caveats := make([]string, len(mac.Caveats()))
+	for idx, caveat := range mac.Caveats() {
+		caveats[idx] = string(caveat.ID)
+	}
+
+	rawData, err := json.Marshal(map[string]any{
+		"location":   mac.Location(),
+		"identifier": string(mac.ID()),
+		"caveats":    caveats,
+		"declared":   declared,
+	})
+	if err != nil {
+		return nil, errorchain.
+			NewWithMessage(heimdall.ErrInternal, "failed to marshal macaroon data").
+			WithErrorContext(a).
+			CausedBy(err)
+	}
+
+	sub, err := a.sf.CreateSubject(rawData)
+	if err != nil {
+		return nil, errorchain.
+			NewWithMessage(heimdall.ErrInternal, "failed to extract subject information from macaroon").
+			WithErrorContext(a).
+			CausedBy(err)
+	}
+
+	return sub, nil
+}
+
+func (a *macaroonAuthenticator) WithConfig(config map[string]any) (Authenticator, error) {
+	// this authenticator allows satisfied caveats and ttl to be redefined on the rule level
+	if len(config) == 0 {
+		return a, nil
+	}
+
+	type Config struct {
+		SatisfiedCaveats []string       `mapstructure:"satisfied_caveats"`
+		CacheTTL         *time.Duration `mapstructure:"cache_ttl"`
+	}
+
+	var conf Config
+	if err := decodeConfig(a.app, config, &conf); err != nil {
+		return nil, errorchain.NewWithMessagef(heimdall.ErrConfiguration,
+			"failed decoding config for macaroon authenticator '%s'", a.id).CausedBy(err)
+	}
+
+	return &macaroonAuthenticator{
+		id:  a.id,
+		app: a.app,
+		ks:  a.ks,
+		satisfiedCaveats: x.IfThenElse(conf.SatisfiedCaveats != nil,
+			conf.SatisfiedCaveats, a.satisfiedCaveats),
+		declaredKeys: a.declaredKeys,
+		ttl:          x.IfThenElse(conf.CacheTTL != nil, conf.CacheTTL, a.ttl),
+		sf:           a.sf,
+		ads:          a.ads,
+	}, nil
+}
+
+func (a *macaroonAuthenticator) ID() string {
+	return a.id
+}
+
+func (a *macaroonAuthenticator) IsInsecure() bool { return false }
+
+func (a *macaroonAuthenticator) verifyMacaroon(
+	ctx heimdall.RequestContext,
+	mac *macaroon.Macaroon,
+) (map[string]string, error) {
+	logger := zerolog.Ctx(ctx.Context())
+
+	keys, err := a.ks.Keys(ctx.Context(), x.IfThenElseExec(a.ttl != nil,
+		func() time.Duration { return *a.ttl },
+		func() time.Duration { return defaultKeySetTTL }))
+	if err != nil {
+		return nil, errorchain.
+			NewWithMessage(heimdall.ErrInternal, "failed to retrieve root keys for macaroon verification").
+			WithErrorContext(a).
+			CausedBy(err)
+	}
+
+	// a root key with a key id equal to the macaroon identifier is tried first
+	keys = slices.Clone(keys)
+	rank := func(key jose.JSONWebKey) int { return x.IfThenElse(key.KeyID == string(mac.ID()), 0, 1) }
+	slices.SortStableFunc(keys, func(a, b jose.JSONWebKey) int { return cmp.Compare(rank(a), rank(b)) })
+
+	for _, key := range keys {
+		rootKey, ok := key.Key.([]byte)
+		if !ok {
+			continue
+		}
+
+		declared := make(map[string]string)
+
+		err = mac.Verify(rootKey, func(caveat string) error { return a.checkCaveat(caveat, declared) })
+		if err == nil {
+			return declared, a.checkDeclarations(declared)
+		}
+
+		if !errors.Is(err, macaroon.ErrInvalidSignature) {
+			return nil, errorchain.
+				NewWithMessage(heimdall.ErrAuthentication, "macaroon caveats are not satisfied").
+				WithErrorContext(a).
+				CausedBy(err)
+		}
+
+		logger.Debug().Str("_key_id", key.KeyID).Msg("Macaroon signature does not match the root key")
+	}
+
+	return nil, errorchain.
+		NewWithMessage(heimdall.ErrAuthentication, "none of the available root keys could be used to verify the macaroon").
+		WithErrorContext(a)
+}
+
+// checkDeclarations ensures all expected keys have been declared. As anyone holding a macaroon can add
+// first-party caveats, the values of declared keys can only be trusted if the issuer declares these in
+// every macaroon. Any further declaration of such a key by the holder is a conflict and rejected.
+func (a *macaroonAuthenticator) checkDeclarations(declared map[string]string) error {
+	for _, key := range a.declaredKeys {
+		if _, ok := declared[key]; !ok {
+			return errorchain.
+				NewWithMessagef(heimdall.ErrAuthentication, "macaroon does not declare '%s'", key).
+				WithErrorContext(a).
+				CausedBy(errCaveatNotSatisfied)
+		}
+	}
+
+	return nil
+}
+
+// checkCaveat checks first-party caveats. Supported are "time-before <RFC3339 time>" and "time < <RFC3339 time>"
+// caveats, "declared <key> <value>" caveats with keys listed in the declared keys, the values of which are made
+// available to the subject, as well as any caveat listed in the satisfied caveats.
+func (a *macaroonAuthenticator) checkCaveat(caveat string, declared map[string]string) error {
+	if slices.Contains(a.satisfiedCaveats, caveat) {
+		return nil
+	}
+
+	condition, arg, _ := strings.Cut(caveat, " ")
+
+	switch condition {
+	case "time-before", "time":
+		if condition == "time" {
+			var found bool
+
+			if arg, found = strings.CutPrefix(arg, "< "); !found {
+				break
+			}
+		}
+
+		deadline, err := time.Parse(time.RFC3339, strings.TrimSpace(arg))
+		if err != nil {
+			return fmt.Errorf("%w: '%s': %w", errCaveatNotSatisfied, caveat, err)
+		}
+
+		if !time.Now().Before(deadline) {
+			return fmt.Errorf("%w: '%s': macaroon expired", errCaveatNotSatisfied, caveat)
+		}
+
+		return nil
+	case "declared":
+		key, value, found := strings.Cut(arg, " ")
+		if !found || !slices.Contains(a.declaredKeys, key) {
+			break
+		}
+
+		if existing, ok := declared[key]; ok && existing != value {
+			return fmt.Errorf("%w: '%s'", errCaveatConflict, key)
+		}
+
+		declared[key] = value
+
+		return nil
+	}
+
+	return fmt.Errorf("%w: '%s'", errCaveatNotSatisfied, caveat)
+}
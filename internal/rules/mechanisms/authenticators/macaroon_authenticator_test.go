@@ -0,0 +1,438 @@
+// Copyright 2025 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package authenticators
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-jose/go-jose/v4"
+	"github.com/rs/zerolog/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dadrus/heimdall/internal/app"
+	"github.com/dadrus/heimdall/internal/config"
+	"github.com/dadrus/heimdall/internal/heimdall"
+	"github.com/dadrus/heimdall/internal/heimdall/mocks"
+	"github.com/dadrus/heimdall/internal/rules/mechanisms/subject"
+	"github.com/dadrus/heimdall/internal/validation"
+	mocks2 "github.com/dadrus/heimdall/internal/watcher/mocks"
+	"github.com/dadrus/heimdall/internal/x/macaroon"
+	"github.com/dadrus/heimdall/internal/x/testsupport"
+)
+
+func TestCreateMacaroonAuthenticator(t *testing.T) {
+	t.Parallel()
+
+	jwksFile := filepath.Join(t.TempDir(), "jwks.json")
+	writeJWKSFile(t, jwksFile, jose.JSONWebKeySet{Keys: []jose.JSONWebKey{{KeyID: "foo", Key: []byte("secret")}}})
+
+	for uc, tc := range map[string]struct {
+		config []byte
+		assert func(t *testing.T, err error, auth *macaroonAuthenticator)
+	}{
+		"without key source": {
+			assert: func(t *testing.T, err error, _ *macaroonAuthenticator) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrConfiguration)
+				require.ErrorContains(t, err, "'jwks_file' is a required field")
+			},
+		},
+		"with unsupported attributes": {
+			config: []byte(`
+jwks_file: ` + jwksFile + `
+foo: bar
+`),
+			assert: func(t *testing.T, err error, _ *macaroonAuthenticator) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrConfiguration)
+				require.ErrorContains(t, err, "failed decoding")
+			},
+		},
+		"with not existing jwks file": {
+			config: []byte(`jwks_file: /no/such/file`),
+			assert: func(t *testing.T, err error, _ *macaroonAuthenticator) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrConfiguration)
+				require.ErrorContains(t, err, "failed to create key set")
+			},
+		},
+		"with minimal configuration": {
+			config: []byte(`jwks_file: ` + jwksFile),
+			assert: func(t *testing.T, err error, auth *macaroonAuthenticator) {
+				t.Helper()
+
+				require.NoError(t, err)
+				assert.Equal(t, "with minimal configuration", auth.ID())
+				assert.False(t, auth.IsInsecure())
+				assert.IsType(t, &fileKeySet{}, auth.ks)
+				assert.Empty(t, auth.satisfiedCaveats)
+				assert.Empty(t, auth.declaredKeys)
+				assert.Nil(t, auth.ttl)
+				assert.Equal(t, &SubjectInfo{IDFrom: "identifier"}, auth.sf)
+				assert.Len(t, auth.ads, 2)
+			},
+		},
+		"with full configuration": {
+			config: []byte(`
+jwks_endpoint:
+  url: https://foo.bar
+satisfied_caveats:
+  - account = 3735928559
+declared_keys:
+  - user
+subject:
+  id: declared.user
+token_source:
+  - header: X-Macaroon
+cache_ttl: 5s
+`),
+			assert: func(t *testing.T, err error, auth *macaroonAuthenticator) {
+				t.Helper()
+
+				require.NoError(t, err)
+				assert.IsType(t, &endpointKeySet{}, auth.ks)
+				assert.Equal(t, []string{"account = 3735928559"}, auth.satisfiedCaveats)
+				assert.Equal(t, []string{"user"}, auth.declaredKeys)
+				assert.Equal(t, 5*time.Second, *auth.ttl)
+				assert.Equal(t, &SubjectInfo{IDFrom: "declared.user"}, auth.sf)
+				assert.Len(t, auth.ads, 1)
+			},
+		},
+	} {
+		t.Run(uc, func(t *testing.T) {
+			// GIVEN
+			conf, err := testsupport.DecodeTestConfig(tc.config)
+			require.NoError(t, err)
+
+			validator, err := validation.NewValidator(
+				validation.WithTagValidator(config.EnforcementSettings{}),
+			)
+			require.NoError(t, err)
+
+			wm := mocks2.NewWatcherMock(t)
+			wm.EXPECT().Add(jwksFile, mock.Anything).Maybe().Return(nil)
+
+			appCtx := app.NewContextMock(t)
+			appCtx.EXPECT().Validator().Maybe().Return(validator)
+			appCtx.EXPECT().Logger().Return(log.Logger)
+			appCtx.EXPECT().Watcher().Maybe().Return(wm)
+
+			// WHEN
+			auth, err := newMacaroonAuthenticator(appCtx, uc, conf)
+
+			// THEN
+			tc.assert(t, err, auth)
+		})
+	}
+}
+
+func TestCreateMacaroonAuthenticatorFromPrototype(t *testing.T) {
+	t.Parallel()
+
+	jwksFile := filepath.Join(t.TempDir(), "jwks.json")
+	writeJWKSFile(t, jwksFile, jose.JSONWebKeySet{Keys: []jose.JSONWebKey{{KeyID: "foo", Key: []byte("secret")}}})
+
+	for uc, tc := range map[string]struct {
+		stepConfig []byte
+		assert     func(t *testing.T, err error, prototype, configured *macaroonAuthenticator)
+	}{
+		"without step config": {
+			assert: func(t *testing.T, err error, prototype, configured *macaroonAuthenticator) {
+				t.Helper()
+
+				require.NoError(t, err)
+				assert.Equal(t, prototype, configured)
+			},
+		},
+		"with unsupported attributes": {
+			stepConfig: []byte(`subject: { id: foo }`),
+			assert: func(t *testing.T, err error, _, _ *macaroonAuthenticator) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrConfiguration)
+				require.ErrorContains(t, err, "failed decoding")
+			},
+		},
+		"with overridden satisfied caveats and cache ttl": {
+			stepConfig: []byte(`
+satisfied_caveats:
+  - op = write
+cache_ttl: 1s
+`),
+			assert: func(t *testing.T, err error, prototype, configured *macaroonAuthenticator) {
+				t.Helper()
+
+				require.NoError(t, err)
+				assert.NotEqual(t, prototype, configured)
+				assert.Equal(t, prototype.id, configured.ID())
+				assert.Equal(t, prototype.ks, configured.ks)
+				assert.Equal(t, prototype.sf, configured.sf)
+				assert.Equal(t, prototype.ads, configured.ads)
+				assert.Equal(t, []string{"op = read"}, prototype.satisfiedCaveats)
+				assert.Equal(t, []string{"op = write"}, configured.satisfiedCaveats)
+				assert.Equal(t, time.Second, *configured.ttl)
+			},
+		},
+	} {
+		t.Run(uc, func(t *testing.T) {
+			// GIVEN
+			pc, err := testsupport.DecodeTestConfig([]byte(`
+jwks_file: ` + jwksFile + `
+satisfied_caveats:
+  - op = read
+declared_keys:
+  - user
+`))
+			require.NoError(t, err)
+
+			sc, err := testsupport.DecodeTestConfig(tc.stepConfig)
+			require.NoError(t, err)
+
+			validator, err := validation.NewValidator()
+			require.NoError(t, err)
+
+			wm := mocks2.NewWatcherMock(t)
+			wm.EXPECT().Add(jwksFile, mock.Anything).Return(nil)
+
+			appCtx := app.NewContextMock(t)
+			appCtx.EXPECT().Validator().Maybe().Return(validator)
+			appCtx.EXPECT().Logger().Return(log.Logger)
+			appCtx.EXPECT().Watcher().Return(wm)
+
+			prototype, err := newMacaroonAuthenticator(appCtx, uc, pc)
+			require.NoError(t, err)
+
+			// WHEN
+			auth, err := prototype.WithConfig(sc)
+
+			// THEN
+			var configured *macaroonAuthenticator
+			if err == nil {
+				configured = auth.(*macaroonAuthenticator) // nolint: forcetypeassert
+			}
+
+			tc.assert(t, err, prototype, configured)
+		})
+	}
+}
+
+func TestMacaroonAuthenticatorExecute(t *testing.T) {
+	t.Parallel()
+
+	jwksFile := filepath.Join(t.TempDir(), "jwks.json")
+	writeJWKSFile(t, jwksFile, jose.JSONWebKeySet{Keys: []jose.JSONWebKey{
+		{KeyID: "foo", Key: []byte("foo-secret")},
+		{KeyID: "bar", Key: []byte("bar-secret")},
+	}})
+
+	conf, err := testsupport.DecodeTestConfig([]byte(`
+jwks_file: ` + jwksFile + `
+satisfied_caveats:
+  - op = read
+declared_keys:
+  - user
+`))
+	require.NoError(t, err)
+
+	validator, err := validation.NewValidator()
+	require.NoError(t, err)
+
+	wm := mocks2.NewWatcherMock(t)
+	wm.EXPECT().Add(jwksFile, mock.Anything).Return(nil)
+
+	appCtx := app.NewContextMock(t)
+	appCtx.EXPECT().Validator().Return(validator)
+	appCtx.EXPECT().Logger().Return(log.Logger)
+	appCtx.EXPECT().Watcher().Return(wm)
+
+	auth, err := newMacaroonAuthenticator(appCtx, "macaroon", conf)
+	require.NoError(t, err)
+
+	newMacaroon := func(rootKey, id string, caveats ...string) string {
+		mac := macaroon.New([]byte(rootKey), []byte(id), "https://heimdall.local")
+		for _, caveat := range caveats {
+			mac.AddFirstPartyCaveat(caveat)
+		}
+
+		return mac.String()
+	}
+
+	validUntil := "time < " + time.Now().Add(time.Hour).UTC().Format(time.RFC3339)
+
+	for uc, tc := range map[string]struct {
+		authorization string
+		assert        func(t *testing.T, err error, sub *subject.Subject)
+	}{
+		"no macaroon present": {
+			assert: func(t *testing.T, err error, _ *subject.Subject) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrAuthentication)
+				require.ErrorContains(t, err, "no macaroon present")
+
+				var identifier interface{ ID() string }
+				require.ErrorAs(t, err, &identifier)
+				assert.Equal(t, "macaroon", identifier.ID())
+			},
+		},
+		"malformed macaroon": {
+			authorization: "Macaroon Zm9vYmFy",
+			assert: func(t *testing.T, err error, _ *subject.Subject) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrAuthentication)
+				require.ErrorIs(t, err, heimdall.ErrArgument)
+				require.ErrorIs(t, err, macaroon.ErrMalformedMacaroon)
+			},
+		},
+		"macaroon minted with unknown root key": {
+			authorization: "Macaroon " + newMacaroon("baz-secret", "foo"),
+			assert: func(t *testing.T, err error, _ *subject.Subject) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrAuthentication)
+				require.ErrorContains(t, err, "none of the available root keys")
+			},
+		},
+		"macaroon with expired time caveat": {
+			authorization: "Macaroon " + newMacaroon("foo-secret", "foo",
+				"time-before "+time.Now().Add(-time.Hour).UTC().Format(time.RFC3339)),
+			assert: func(t *testing.T, err error, _ *subject.Subject) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrAuthentication)
+				require.ErrorIs(t, err, macaroon.ErrCaveatNotSatisfied)
+				require.ErrorContains(t, err, "macaroon expired")
+			},
+		},
+		"macaroon with malformed time caveat": {
+			authorization: "Macaroon " + newMacaroon("foo-secret", "foo", "time < tomorrow"),
+			assert: func(t *testing.T, err error, _ *subject.Subject) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrAuthentication)
+				require.ErrorIs(t, err, errCaveatNotSatisfied)
+			},
+		},
+		"macaroon with unknown caveat": {
+			authorization: "Macaroon " + newMacaroon("foo-secret", "foo", "op = write"),
+			assert: func(t *testing.T, err error, _ *subject.Subject) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrAuthentication)
+				require.ErrorIs(t, err, errCaveatNotSatisfied)
+				require.ErrorContains(t, err, "'op = write'")
+			},
+		},
+		"macaroon with conflicting declarations": {
+			authorization: "Macaroon " + newMacaroon("foo-secret", "foo", "declared user alice", "declared user bob"),
+			assert: func(t *testing.T, err error, _ *subject.Subject) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrAuthentication)
+				require.ErrorIs(t, err, errCaveatConflict)
+			},
+		},
+		"macaroon with unexpected declaration": {
+			authorization: "Macaroon " + newMacaroon("foo-secret", "foo", "declared user alice", "declared role admin"),
+			assert: func(t *testing.T, err error, _ *subject.Subject) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrAuthentication)
+				require.ErrorIs(t, err, errCaveatNotSatisfied)
+				require.ErrorContains(t, err, "'declared role admin'")
+			},
+		},
+		"macaroon without expected declaration": {
+			authorization: "Macaroon " + newMacaroon("foo-secret", "foo", "op = read"),
+			assert: func(t *testing.T, err error, _ *subject.Subject) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrAuthentication)
+				require.ErrorIs(t, err, errCaveatNotSatisfied)
+				require.ErrorContains(t, err, "does not declare 'user'")
+
+				var identifier interface{ ID() string }
+				require.ErrorAs(t, err, &identifier)
+				assert.Equal(t, "macaroon", identifier.ID())
+			},
+		},
+		"valid macaroon": {
+			authorization: "Macaroon " + newMacaroon("bar-secret", "foo",
+				"op = read", validUntil, "declared user alice", "declared user alice"),
+			assert: func(t *testing.T, err error, sub *subject.Subject) {
+				t.Helper()
+
+				require.NoError(t, err)
+				assert.Equal(t, "foo", sub.ID)
+				assert.Equal(t, map[string]any{
+					"location":   "https://heimdall.local",
+					"identifier": "foo",
+					"caveats": []any{
+						"op = read", validUntil, "declared user alice", "declared user alice",
+					},
+					"declared": map[string]any{"user": "alice"},
+				}, sub.Attributes)
+			},
+		},
+		"valid macaroon using bearer scheme": {
+			authorization: "Bearer " + newMacaroon("foo-secret", "bar", "declared user bob"),
+			assert: func(t *testing.T, err error, sub *subject.Subject) {
+				t.Helper()
+
+				require.NoError(t, err)
+				assert.Equal(t, "bar", sub.ID)
+			},
+		},
+	} {
+		t.Run(uc, func(t *testing.T) {
+			// GIVEN
+			fnt := mocks.NewRequestFunctionsMock(t)
+			fnt.EXPECT().Header("Authorization").Return(tc.authorization)
+
+			ctx := mocks.NewRequestContextMock(t)
+			ctx.EXPECT().Context().Return(t.Context())
+			ctx.EXPECT().Request().Return(&heimdall.Request{RequestFunctions: fnt})
+
+			// WHEN
+			sub, err := auth.Execute(ctx)
+
+			// THEN
+			tc.assert(t, err, sub)
+		})
+	}
+}
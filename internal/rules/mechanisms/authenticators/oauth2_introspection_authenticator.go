@@ -70,6 +70,8 @@ type oauth2IntrospectionAuthenticator struct {
 	ads                  extractors.AuthDataExtractStrategy
 	ttl                  *time.Duration
 	allowFallbackOnError bool
+	dpop                 *oauth2.DPoP
+	certBinding          *CertificateBinding
 }
 
 // nolint: funlen, cyclop
@@ -89,6 +91,8 @@ func newOAuth2IntrospectionAuthenticator(
 		AuthDataSource        extractors.CompositeExtractStrategy `mapstructure:"token_source"`
 		CacheTTL              *time.Duration                      `mapstructure:"cache_ttl"`
 		AllowFallbackOnError  bool                                `mapstructure:"allow_fallback_on_error"`
+		DPoP                  *oauth2.DPoP                        `mapstructure:"dpop"`
+		CertificateBinding    *CertificateBinding                 `mapstructure:"certificate_binding"`
 	}
 
 	var conf Config
@@ -101,6 +105,16 @@ func newOAuth2IntrospectionAuthenticator(
 		logger.Warn().Str("_id", id).Msg("Usage of allow_fallback_on_error is deprecated and has no effect")
 	}
 
+	if err := checkDPoPReplayProtection(app, conf.DPoP, "oauth2_introspection", id); err != nil {
+		return nil, err
+	}
+
+	if conf.CertificateBinding != nil && conf.CertificateBinding.AllowForwardedClientCert {
+		logger.Warn().Str("_id", id).
+			Msg("oauth2_introspection authenticator accepts client certificates forwarded by proxies. " +
+				"Make sure, heimdall is configured to trust only those proxies, which are expected to forward them.")
+	}
+
 	if conf.IntrospectionEndpoint != nil && strings.HasPrefix(conf.IntrospectionEndpoint.URL, "http://") {
 		logger.Warn().Str("_id", id).
 			Msg("No TLS configured for the introspection endpoint used in oauth2_introspection authenticator")
@@ -134,6 +148,13 @@ func newOAuth2IntrospectionAuthenticator(
 		func() extractors.CompositeExtractStrategy { return conf.AuthDataSource },
 	)
 
+	if conf.DPoP != nil && conf.AuthDataSource == nil {
+		// DPoP-bound tokens are sent using the DPoP authorization scheme
+		ads = append(extractors.CompositeExtractStrategy{
+			extractors.HeaderValueExtractStrategy{Name: "Authorization", Scheme: dpopScheme},
+		}, ads...)
+	}
+
 	resolver := x.IfThenElseExec(conf.MetadataEndpoint != nil,
 		func() oauth2.ServerMetadataResolver { return conf.MetadataEndpoint },
 		func() oauth2.ServerMetadataResolver {
@@ -172,6 +193,8 @@ func newOAuth2IntrospectionAuthenticator(
 		sf:                   &conf.SubjectInfo,
 		ttl:                  conf.CacheTTL,
 		allowFallbackOnError: conf.AllowFallbackOnError,
+		dpop:                 conf.DPoP,
+		certBinding:          conf.CertificateBinding,
 	}, nil
 }
 
@@ -192,6 +215,17 @@ func (a *oauth2IntrospectionAuthenticator) Execute(ctx heimdall.RequestContext)
 		return nil, err
 	}
 
+	if a.dpop != nil || a.certBinding != nil {
+		var resp oauth2.IntrospectionResponse
+
+		// the response has already been deserialized successfully while fetching it
+		_ = json.Unmarshal(rawResp, &resp)
+
+		if err = a.verifyBinding(ctx, accessToken, resp.Confirmation); err != nil {
+			return nil, err
+		}
+	}
+
 	sub, err := a.sf.CreateSubject(rawResp)
 	if err != nil {
 		return nil, errorchain.
@@ -238,9 +272,29 @@ func (a *oauth2IntrospectionAuthenticator) WithConfig(rawConfig map[string]any)
 		allowFallbackOnError: x.IfThenElseExec(conf.AllowFallbackOnError != nil,
 			func() bool { return *conf.AllowFallbackOnError },
 			func() bool { return a.allowFallbackOnError }),
+		dpop:        a.dpop,
+		certBinding: a.certBinding,
 	}, nil
 }
 
+func (a *oauth2IntrospectionAuthenticator) verifyBinding(
+	ctx heimdall.RequestContext,
+	accessToken string,
+	cnf *oauth2.Confirmation,
+) error {
+	if a.dpop != nil {
+		if err := verifyDPoPBinding(ctx, a.dpop, accessToken, cnf, a); err != nil {
+			return err
+		}
+	}
+
+	if a.certBinding != nil {
+		return a.certBinding.Verify(ctx, cnf, a)
+	}
+
+	return nil
+}
+
 func (a *oauth2IntrospectionAuthenticator) ID() string {
 	return a.id
 }
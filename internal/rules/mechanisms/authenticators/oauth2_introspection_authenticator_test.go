@@ -55,9 +55,29 @@ func TestOAuth2IntrospectionAuthenticatorCreate(t *testing.T) {
 
 	for uc, tc := range map[string]struct {
 		enforceTLS bool
+		cacheType  string
 		config     []byte
 		assert     func(t *testing.T, err error, a *oauth2IntrospectionAuthenticator)
 	}{
+		"with dpop configured, but noop cache in use": {
+			cacheType: "noop",
+			config: []byte(`
+introspection_endpoint:
+  url: https://foo.bar
+assertions:
+  issuers:
+    - foobar
+dpop:
+  required: true
+`),
+			assert: func(t *testing.T, err error, _ *oauth2IntrospectionAuthenticator) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrConfiguration)
+				require.ErrorContains(t, err, "requires a cache")
+			},
+		},
 		"with unsupported fields": {
 			config: []byte(`
 assertions:
@@ -402,6 +422,9 @@ metadata_endpoint:
 			appCtx := app.NewContextMock(t)
 			appCtx.EXPECT().Validator().Maybe().Return(validator)
 			appCtx.EXPECT().Logger().Return(log.Logger)
+			appCtx.EXPECT().Config().Maybe().Return(&config.Configuration{
+				Cache: config.CacheConfig{Type: x.IfThenElse(len(tc.cacheType) != 0, tc.cacheType, "in-memory")},
+			})
 
 			// WHEN
 			a, err := newOAuth2IntrospectionAuthenticator(appCtx, uc, conf)
@@ -1958,6 +1981,104 @@ func TestOauth2IntrospectionAuthenticatorExecute(t *testing.T) {
 				assert.NotEmpty(t, sub.Attributes["exp"])
 			},
 		},
+		"with DPoP-bound token from cache presented as bearer token": {
+			authenticator: &oauth2IntrospectionAuthenticator{
+				id: "auth3",
+				r: oauth2.ResolverAdapterFunc(func(_ context.Context, _ map[string]any) (oauth2.ServerMetadata, error) {
+					return oauth2.ServerMetadata{IntrospectionEndpoint: &endpoint.Endpoint{URL: srv.URL}}, nil
+				}),
+				a:    oauth2.Expectation{ScopesMatcher: oauth2.NoopMatcher{}},
+				sf:   &SubjectInfo{IDFrom: "sub"},
+				dpop: &oauth2.DPoP{},
+			},
+			configureMocks: func(t *testing.T,
+				ctx *heimdallmocks.RequestContextMock,
+				cch *mocks.CacheMock,
+				ads *mocks2.AuthDataExtractStrategyMock,
+				_ *oauth2IntrospectionAuthenticator,
+			) {
+				t.Helper()
+
+				ads.EXPECT().GetAuthData(ctx).Return("test_access_token", nil)
+
+				rawIntrospectResponse, err := json.Marshal(map[string]any{
+					"active": true,
+					"sub":    "foo",
+					"exp":    time.Now().Unix() + 30,
+					"cnf":    map[string]any{"jkt": "0ZcOCORZNYy-DWpqq30jZyJGHTN0d2HglBV3uiguA4I"},
+				})
+				require.NoError(t, err)
+
+				cch.EXPECT().Get(mock.Anything, mock.Anything).Return(rawIntrospectResponse, nil)
+
+				reqf := heimdallmocks.NewRequestFunctionsMock(t)
+				reqf.EXPECT().Header("Authorization").Return("Bearer test_access_token")
+
+				ctx.EXPECT().Request().Return(&heimdall.Request{RequestFunctions: reqf})
+			},
+			assert: func(t *testing.T, err error, _ *subject.Subject) {
+				t.Helper()
+
+				assert.False(t, introspectionEndpointCalled)
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrAuthentication)
+				assert.Contains(t, err.Error(), "DPoP-bound access token expected")
+
+				var identifier HandlerIdentifier
+				require.ErrorAs(t, err, &identifier)
+				assert.Equal(t, "auth3", identifier.ID())
+			},
+		},
+		"with certificate-bound token from cache presented without client certificate": {
+			authenticator: &oauth2IntrospectionAuthenticator{
+				id: "auth3",
+				r: oauth2.ResolverAdapterFunc(func(_ context.Context, _ map[string]any) (oauth2.ServerMetadata, error) {
+					return oauth2.ServerMetadata{IntrospectionEndpoint: &endpoint.Endpoint{URL: srv.URL}}, nil
+				}),
+				a:           oauth2.Expectation{ScopesMatcher: oauth2.NoopMatcher{}},
+				sf:          &SubjectInfo{IDFrom: "sub"},
+				certBinding: &CertificateBinding{},
+			},
+			configureMocks: func(t *testing.T,
+				ctx *heimdallmocks.RequestContextMock,
+				cch *mocks.CacheMock,
+				ads *mocks2.AuthDataExtractStrategyMock,
+				_ *oauth2IntrospectionAuthenticator,
+			) {
+				t.Helper()
+
+				ads.EXPECT().GetAuthData(ctx).Return("test_access_token", nil)
+
+				rawIntrospectResponse, err := json.Marshal(map[string]any{
+					"active": true,
+					"sub":    "foo",
+					"exp":    time.Now().Unix() + 30,
+					"cnf":    map[string]any{"x5t#S256": "bwcK0esc3ACC3DB2Y5_lESsXE8o9ltc05O89jdN-dg2"},
+				})
+				require.NoError(t, err)
+
+				cch.EXPECT().Get(mock.Anything, mock.Anything).Return(rawIntrospectResponse, nil)
+
+				reqf := heimdallmocks.NewRequestFunctionsMock(t)
+				reqf.EXPECT().ClientCertificates().Return(nil)
+
+				ctx.EXPECT().Request().Return(&heimdall.Request{RequestFunctions: reqf})
+			},
+			assert: func(t *testing.T, err error, _ *subject.Subject) {
+				t.Helper()
+
+				assert.False(t, introspectionEndpointCalled)
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrAuthentication)
+				assert.Contains(t, err.Error(), "no client certificate present")
+
+				var identifier HandlerIdentifier
+				require.ErrorAs(t, err, &identifier)
+				assert.Equal(t, "auth3", identifier.ID())
+			},
+		},
 	} {
 		t.Run(uc, func(t *testing.T) {
 			// GIVEN
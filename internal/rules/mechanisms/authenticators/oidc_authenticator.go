@@ -0,0 +1,562 @@
+// Copyright 2025 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package authenticators
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/go-jose/go-jose/v4/jwt"
+	"github.com/goccy/go-json"
+	"github.com/rs/zerolog"
+	"github.com/tidwall/gjson"
+
+	"github.com/dadrus/heimdall/internal/app"
+	"github.com/dadrus/heimdall/internal/heimdall"
+	"github.com/dadrus/heimdall/internal/rules/endpoint"
+	"github.com/dadrus/heimdall/internal/rules/mechanisms/oauth2"
+	"github.com/dadrus/heimdall/internal/rules/mechanisms/subject"
+	"github.com/dadrus/heimdall/internal/rules/oauth2/clientcredentials"
+	"github.com/dadrus/heimdall/internal/session"
+	"github.com/dadrus/heimdall/internal/x"
+	"github.com/dadrus/heimdall/internal/x/errorchain"
+	"github.com/dadrus/heimdall/internal/x/stringx"
+)
+
+// tokenExpiryLeeway defines how long before the expiry of the tokens the session is refreshed.
+const tokenExpiryLeeway = 10 * time.Second
+
+// by intention. Used only during application bootstrap
+//
+//nolint:gochecknoinits
+func init() {
+	registerTypeFactory(
+		func(app app.Context, id string, typ string, conf map[string]any) (bool, Authenticator, error) {
+			if typ != AuthenticatorOIDC {
+				return false, nil, nil
+			}
+
+			auth, err := newOIDCAuthenticator(app, id, conf)
+
+			return true, auth, err
+		})
+}
+
+type oidcLogoutConfig struct {
+	Path                  string `mapstructure:"path"                     validate:"required,startswith=/"`
+	PostLogoutRedirectURI string `mapstructure:"post_logout_redirect_uri" validate:"omitempty,url"`
+}
+
+type oidcTokenResponse struct {
+	AccessToken  string `json:"access_token"`
+	TokenType    string `json:"token_type"`
+	RefreshToken string `json:"refresh_token"`
+	ExpiresIn    int64  `json:"expires_in"`
+	IDToken      string `json:"id_token"`
+}
+
+func (r *oidcTokenResponse) expiry() time.Time {
+	if r.ExpiresIn <= 0 {
+		return time.Time{}
+	}
+
+	return time.Now().Add(time.Duration(r.ExpiresIn) * time.Second)
+}
+
+type oidcAuthenticator struct {
+	id          string
+	r           oauth2.ServerMetadataResolver
+	clientID    string
+	clientAuth  endpoint.AuthenticationStrategy
+	redirectURI *url.URL
+	scopes      []string
+	logout      *oidcLogoutConfig
+	sessions    *oidcSessions
+	sf          SubjectFactory
+	idt         *jwtAuthenticator
+}
+
+func newOIDCAuthenticator(app app.Context, id string, rawConfig map[string]any) (*oidcAuthenticator, error) {
+	logger := app.Logger()
+	logger.Info().Str("_id", id).Msg("Creating oidc authenticator")
+
+	type Config struct {
+		MetadataEndpoint *oauth2.MetadataEndpoint     `mapstructure:"metadata_endpoint" validate:"required"`
+		ClientID         string                       `mapstructure:"client_id"         validate:"required"`
+		ClientSecret     string                       `mapstructure:"client_secret"`
+		AuthMethod       clientcredentials.AuthMethod `mapstructure:"auth_method"       validate:"omitempty,oneof=basic_auth request_body"` //nolint:lll
+		RedirectURI      string                       `mapstructure:"redirect_uri"      validate:"required,url,enforced=istls"`             //nolint:lll
+		Scopes           []string                     `mapstructure:"scopes"`
+		Assertions       oauth2.Expectation           `mapstructure:"assertions"`
+		SubjectInfo      SubjectInfo                  `mapstructure:"subject"           validate:"-"`
+		Session          oidcSessionConfig            `mapstructure:"session"`
+		Logout           *oidcLogoutConfig            `mapstructure:"logout"`
+	}
+
+	var conf Config
+	if err := decodeConfig(app, rawConfig, &conf); err != nil {
+		return nil, errorchain.NewWithMessagef(heimdall.ErrConfiguration,
+			"failed decoding config for oidc authenticator '%s'", id).CausedBy(err)
+	}
+
+	if strings.HasPrefix(conf.MetadataEndpoint.URL, "http://") {
+		logger.Warn().Str("_id", id).
+			Msg("No TLS configured for the metadata endpoint used in oidc authenticator")
+	}
+
+	redirectURI, err := url.Parse(conf.RedirectURI)
+	if err != nil {
+		return nil, errorchain.NewWithMessage(heimdall.ErrConfiguration, "failed parsing redirect_uri").
+			CausedBy(err)
+	}
+
+	if conf.Logout != nil && conf.Logout.Path == redirectURI.Path {
+		return nil, errorchain.NewWithMessage(heimdall.ErrConfiguration,
+			"logout path must differ from the path of the redirect_uri")
+	}
+
+	sm := app.SessionManager()
+	if sm == nil {
+		return nil, errorchain.NewWithMessagef(heimdall.ErrConfiguration,
+			"oidc authenticator '%s' requires session management to be configured", id)
+	}
+
+	if len(conf.Assertions.AllowedAlgorithms) == 0 {
+		conf.Assertions.AllowedAlgorithms = defaultAllowedAlgorithms()
+	}
+
+	if len(conf.Assertions.Audiences) == 0 {
+		conf.Assertions.Audiences = []string{conf.ClientID}
+	}
+
+	if conf.Assertions.ScopesMatcher == nil {
+		conf.Assertions.ScopesMatcher = oauth2.NoopMatcher{}
+	}
+
+	if len(conf.SubjectInfo.IDFrom) == 0 {
+		conf.SubjectInfo.IDFrom = "sub"
+	}
+
+	if !slices.Contains(conf.Scopes, "openid") {
+		conf.Scopes = append([]string{"openid"}, conf.Scopes...)
+	}
+
+	return &oidcAuthenticator{
+		id:       id,
+		r:        conf.MetadataEndpoint,
+		clientID: conf.ClientID,
+		clientAuth: x.IfThenElseExec(len(conf.ClientSecret) != 0,
+			func() endpoint.AuthenticationStrategy {
+				return &clientcredentials.Config{
+					ClientID:     conf.ClientID,
+					ClientSecret: conf.ClientSecret,
+					AuthMethod:   conf.AuthMethod,
+				}
+			},
+			func() endpoint.AuthenticationStrategy { return nil }),
+		redirectURI: redirectURI,
+		scopes:      conf.Scopes,
+		logout:      conf.Logout,
+		sessions:    newOIDCSessions(id, sm, conf.Session, redirectURI.Scheme == "https"),
+		sf:          &conf.SubjectInfo,
+		idt: &jwtAuthenticator{
+			id:              id,
+			app:             app,
+			r:               conf.MetadataEndpoint,
+			a:               conf.Assertions,
+			validateJWKCert: true,
+		},
+	}, nil
+}
+
+func (a *oidcAuthenticator) Execute(ctx heimdall.RequestContext) (*subject.Subject, error) {
+	logger := zerolog.Ctx(ctx.Context())
+	logger.Debug().Str("_id", a.id).Msg("Authenticating using OIDC authenticator")
+
+	req := ctx.Request()
+
+	switch {
+	case req.URL.Path == a.redirectURI.Path:
+		return nil, a.handleCallback(ctx)
+	case a.logout != nil && req.URL.Path == a.logout.Path:
+		return nil, a.handleLogout(ctx)
+	}
+
+	sess, err := a.sessions.load(ctx)
+	if err != nil {
+		return nil, errorchain.NewWithMessage(heimdall.ErrAuthentication, "no valid session present").
+			WithErrorContext(a).
+			CausedBy(err)
+	}
+
+	if expiry := tokenExpiry(sess); !expiry.IsZero() && time.Now().Add(tokenExpiryLeeway).After(expiry) {
+		if err = a.refreshSession(ctx, sess); err != nil {
+			return nil, err
+		}
+	}
+
+	return sess.Subject, nil
+}
+
+// LoginRedirect creates the redirect to the authorization endpoint of the OpenID Provider starting
+// the authorization code flow. It is used by the oidc error handler. Returns nil if the login flow
+// cannot be started for the given request, which is the case for the callback and logout requests.
+func (a *oidcAuthenticator) LoginRedirect(ctx heimdall.RequestContext) (*heimdall.RedirectError, error) {
+	req := ctx.Request()
+	if req.URL.Path == a.redirectURI.Path || (a.logout != nil && req.URL.Path == a.logout.Path) {
+		return nil, nil //nolint:nilnil
+	}
+
+	metadata, err := a.serverMetadata(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(metadata.AuthorizationEndpoint) == 0 {
+		return nil, errorchain.NewWithMessage(heimdall.ErrInternal,
+			"received server metadata does not contain the required authorization_endpoint").
+			WithErrorContext(a)
+	}
+
+	authURL, err := url.Parse(metadata.AuthorizationEndpoint)
+	if err != nil {
+		return nil, errorchain.NewWithMessage(heimdall.ErrInternal, "failed parsing authorization_endpoint").
+			WithErrorContext(a).
+			CausedBy(err)
+	}
+
+	state := &oidcLoginState{RedirectTo: req.URL.String(), ExpiresAt: time.Now().Add(oidcLoginStateLifespan)}
+
+	for _, val := range []*string{&state.State, &state.Nonce, &state.CodeVerifier} {
+		if *val, err = randomString(oidcRandomValueLength); err != nil {
+			return nil, err
+		}
+	}
+
+	cookie, err := a.sessions.saveLoginState(ctx, state)
+	if err != nil {
+		return nil, errorchain.New(heimdall.ErrInternal).WithErrorContext(a).CausedBy(err)
+	}
+
+	challenge := sha256.Sum256(stringx.ToBytes(state.CodeVerifier))
+
+	query := authURL.Query()
+	query.Set("response_type", "code")
+	query.Set("client_id", a.clientID)
+	query.Set("redirect_uri", a.redirectURI.String())
+	query.Set("scope", strings.Join(a.scopes, " "))
+	query.Set("state", state.State)
+	query.Set("nonce", state.Nonce)
+	query.Set("code_challenge", base64.RawURLEncoding.EncodeToString(challenge[:]))
+	query.Set("code_challenge_method", "S256")
+	authURL.RawQuery = query.Encode()
+
+	return &heimdall.RedirectError{
+		Message:    "login required",
+		Code:       http.StatusFound,
+		RedirectTo: authURL.String(),
+		Cookies:    []*http.Cookie{cookie},
+	}, nil
+}
+
+func (a *oidcAuthenticator) WithConfig(config map[string]any) (Authenticator, error) {
+	if len(config) == 0 {
+		return a, nil
+	}
+
+	return nil, errorchain.NewWithMessagef(heimdall.ErrConfiguration,
+		"reconfiguration of the oidc authenticator '%s' is not supported", a.id)
+}
+
+func (a *oidcAuthenticator) ID() string { return a.id }
+
+func (a *oidcAuthenticator) IsInsecure() bool { return false }
+
+func (a *oidcAuthenticator) handleCallback(ctx heimdall.RequestContext) error {
+	logger := zerolog.Ctx(ctx.Context())
+	logger.Debug().Str("_id", a.id).Msg("Handling authorization code flow callback")
+
+	query := ctx.Request().URL.Query()
+
+	state, err := a.sessions.loginState(ctx)
+	if err != nil {
+		return errorchain.NewWithMessage(heimdall.ErrAuthentication, "invalid callback request").
+			WithErrorContext(a).
+			CausedBy(err)
+	}
+
+	if errCode := query.Get("error"); len(errCode) != 0 {
+		return errorchain.NewWithMessagef(heimdall.ErrAuthentication,
+			"authorization request failed: %s %s", errCode, query.Get("error_description")).
+			WithErrorContext(a)
+	}
+
+	if query.Get("state") != state.State {
+		return errorchain.NewWithMessage(heimdall.ErrAuthentication, "state parameter does not match").
+			WithErrorContext(a)
+	}
+
+	code := query.Get("code")
+	if len(code) == 0 {
+		return errorchain.NewWithMessage(heimdall.ErrAuthentication, "no authorization code present").
+			WithErrorContext(a)
+	}
+
+	tokens, err := a.fetchTokens(ctx, url.Values{
+		"grant_type":    []string{"authorization_code"},
+		"code":          []string{code},
+		"redirect_uri":  []string{a.redirectURI.String()},
+		"code_verifier": []string{state.CodeVerifier},
+	})
+	if err != nil {
+		return err
+	}
+
+	if len(tokens.IDToken) == 0 {
+		return errorchain.NewWithMessage(heimdall.ErrAuthentication, "token response does not contain an id token").
+			WithErrorContext(a)
+	}
+
+	sub, err := a.subjectFromIDToken(ctx, tokens.IDToken, state.Nonce)
+	if err != nil {
+		return err
+	}
+
+	cookie, err := a.sessions.create(ctx, sub, tokens)
+	if err != nil {
+		return errorchain.New(heimdall.ErrInternal).WithErrorContext(a).CausedBy(err)
+	}
+
+	return &heimdall.RedirectError{
+		Message:    "login completed",
+		Code:       http.StatusSeeOther,
+		RedirectTo: state.RedirectTo,
+		Cookies:    []*http.Cookie{cookie, a.sessions.expiredCookie(a.sessions.stateCookieName())},
+	}
+}
+
+func (a *oidcAuthenticator) handleLogout(ctx heimdall.RequestContext) error {
+	logger := zerolog.Ctx(ctx.Context())
+	logger.Debug().Str("_id", a.id).Msg("Handling logout request")
+
+	// an invalid or expired session does not prevent the logout
+	sess, _ := a.sessions.load(ctx)
+	cookie := a.sessions.remove(ctx)
+
+	metadata, err := a.serverMetadata(ctx)
+	if err != nil {
+		return err
+	}
+
+	target := a.logout.PostLogoutRedirectURI
+
+	if len(metadata.EndSessionEndpoint) != 0 {
+		logoutURL, err := url.Parse(metadata.EndSessionEndpoint)
+		if err != nil {
+			return errorchain.NewWithMessage(heimdall.ErrInternal, "failed parsing end_session_endpoint").
+				WithErrorContext(a).
+				CausedBy(err)
+		}
+
+		query := logoutURL.Query()
+		query.Set("client_id", a.clientID)
+
+		if sess != nil && len(sess.RefreshMaterial[oidcIDTokenKey]) != 0 {
+			query.Set("id_token_hint", sess.RefreshMaterial[oidcIDTokenKey])
+		}
+
+		if len(a.logout.PostLogoutRedirectURI) != 0 {
+			query.Set("post_logout_redirect_uri", a.logout.PostLogoutRedirectURI)
+		}
+
+		logoutURL.RawQuery = query.Encode()
+		target = logoutURL.String()
+	}
+
+	return &heimdall.RedirectError{
+		Message:    "logged out",
+		Code:       http.StatusFound,
+		RedirectTo: x.IfThenElse(len(target) != 0, target, "/"),
+		Cookies:    []*http.Cookie{cookie},
+	}
+}
+
+func (a *oidcAuthenticator) refreshSession(ctx heimdall.RequestContext, sess *session.Session) error {
+	logger := zerolog.Ctx(ctx.Context())
+	logger.Debug().Str("_id", a.id).Msg("Refreshing session")
+
+	refreshToken := sess.RefreshMaterial[oidcRefreshTokenKey]
+	if len(refreshToken) == 0 {
+		return errorchain.NewWithMessage(heimdall.ErrAuthentication, "session tokens expired").
+			WithErrorContext(a)
+	}
+
+	tokens, err := a.fetchTokens(ctx, url.Values{
+		"grant_type":    []string{"refresh_token"},
+		"refresh_token": []string{refreshToken},
+	})
+	if err != nil {
+		return errorchain.NewWithMessage(heimdall.ErrAuthentication, "failed to refresh session").
+			WithErrorContext(a).
+			CausedBy(err)
+	}
+
+	if len(tokens.IDToken) != 0 {
+		sub, err := a.subjectFromIDToken(ctx, tokens.IDToken, "")
+		if err != nil {
+			return err
+		}
+
+		if sub.ID != sess.Subject.ID {
+			return errorchain.NewWithMessage(heimdall.ErrAuthentication,
+				"subject of the refreshed id token does not match the subject of the session").
+				WithErrorContext(a)
+		}
+
+		sess.Subject = sub
+	}
+
+	updateRefreshMaterial(sess.RefreshMaterial, tokens)
+
+	// a session revoked in the meantime results in an authentication error
+	if err = a.sessions.sm.Update(ctx.Context(), sess); err != nil {
+		return errorchain.NewWithMessage(
+			x.IfThenElse(errors.Is(err, session.ErrNoSession), heimdall.ErrAuthentication, heimdall.ErrInternal),
+			"failed to update session").
+			WithErrorContext(a).
+			CausedBy(err)
+	}
+
+	return nil
+}
+
+func (a *oidcAuthenticator) serverMetadata(ctx heimdall.RequestContext) (oauth2.ServerMetadata, error) {
+	metadata, err := a.r.Get(ctx.Context(), map[string]any{})
+	if err != nil {
+		return oauth2.ServerMetadata{}, errorchain.NewWithMessage(heimdall.ErrInternal,
+			"failed retrieving oauth2 server metadata").CausedBy(err).WithErrorContext(a)
+	}
+
+	return metadata, nil
+}
+
+func (a *oidcAuthenticator) fetchTokens(ctx heimdall.RequestContext, data url.Values) (*oidcTokenResponse, error) {
+	metadata, err := a.serverMetadata(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if metadata.TokenEndpoint == nil {
+		return nil, errorchain.NewWithMessage(heimdall.ErrInternal,
+			"received server metadata does not contain the required token_endpoint").
+			WithErrorContext(a)
+	}
+
+	ept := *metadata.TokenEndpoint
+	if a.clientAuth != nil {
+		ept.AuthStrategy = a.clientAuth
+	} else if ept.AuthStrategy == nil {
+		// public client
+		data.Set("client_id", a.clientID)
+	}
+
+	rawData, err := ept.SendRequest(ctx.Context(), strings.NewReader(data.Encode()), nil,
+		func(resp *http.Response) ([]byte, error) {
+			rawData, err := io.ReadAll(resp.Body)
+			if err != nil {
+				return nil, errorchain.NewWithMessage(heimdall.ErrInternal, "failed to read response").
+					CausedBy(err)
+			}
+
+			if resp.StatusCode == http.StatusOK {
+				return rawData, nil
+			}
+
+			var ter clientcredentials.TokenErrorResponse
+			if resp.StatusCode != http.StatusBadRequest || json.Unmarshal(rawData, &ter) != nil {
+				return nil, errorchain.NewWithMessagef(heimdall.ErrCommunication,
+					"unexpected response code: %v", resp.StatusCode)
+			}
+
+			return nil, errorchain.New(heimdall.ErrAuthentication).CausedBy(&ter)
+		})
+	if err != nil {
+		return nil, errorchain.NewWithMessage(heimdall.ErrAuthentication, "token request failed").
+			WithErrorContext(a).
+			CausedBy(err)
+	}
+
+	var resp oidcTokenResponse
+	if err = json.Unmarshal(rawData, &resp); err != nil {
+		return nil, errorchain.NewWithMessage(heimdall.ErrInternal, "failed to unmarshal token response").
+			WithErrorContext(a).
+			CausedBy(err)
+	}
+
+	return &resp, nil
+}
+
+func (a *oidcAuthenticator) verifyIDToken(
+	ctx heimdall.RequestContext, rawToken, nonce string,
+) (json.RawMessage, error) {
+	token, err := jwt.ParseSigned(rawToken, supportedAlgorithms())
+	if err != nil {
+		return nil, errorchain.NewWithMessage(heimdall.ErrAuthentication, "failed to parse id token").
+			WithErrorContext(a).
+			CausedBy(err)
+	}
+
+	claims, err := a.idt.verifyToken(ctx, token)
+	if err != nil {
+		return nil, errorchain.NewWithMessage(heimdall.ErrAuthentication, "id token verification failed").
+			WithErrorContext(a).
+			CausedBy(err)
+	}
+
+	if len(nonce) != 0 && gjson.GetBytes(claims, "nonce").String() != nonce {
+		return nil, errorchain.NewWithMessage(heimdall.ErrAuthentication, "nonce in the id token does not match").
+			WithErrorContext(a)
+	}
+
+	return claims, nil
+}
+
+func (a *oidcAuthenticator) subjectFromIDToken(
+	ctx heimdall.RequestContext, rawToken, nonce string,
+) (*subject.Subject, error) {
+	claims, err := a.verifyIDToken(ctx, rawToken, nonce)
+	if err != nil {
+		return nil, err
+	}
+
+	sub, err := a.sf.CreateSubject(claims)
+	if err != nil {
+		return nil, errorchain.
+			NewWithMessage(heimdall.ErrInternal, "failed to extract subject information from id token").
+			WithErrorContext(a).
+			CausedBy(err)
+	}
+
+	return sub, nil
+}
@@ -0,0 +1,921 @@
+// Copyright 2025 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package authenticators
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/go-jose/go-jose/v4"
+	"github.com/go-jose/go-jose/v4/jwt"
+	"github.com/goccy/go-json"
+	"github.com/rs/zerolog/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dadrus/heimdall/internal/app"
+	"github.com/dadrus/heimdall/internal/cache"
+	"github.com/dadrus/heimdall/internal/cache/memory"
+	"github.com/dadrus/heimdall/internal/config"
+	"github.com/dadrus/heimdall/internal/heimdall"
+	heimdallmocks "github.com/dadrus/heimdall/internal/heimdall/mocks"
+	"github.com/dadrus/heimdall/internal/rules/mechanisms/subject"
+	"github.com/dadrus/heimdall/internal/rules/oauth2/clientcredentials"
+	"github.com/dadrus/heimdall/internal/session"
+	"github.com/dadrus/heimdall/internal/session/cached"
+	sessionmocks "github.com/dadrus/heimdall/internal/session/mocks"
+	"github.com/dadrus/heimdall/internal/validation"
+	"github.com/dadrus/heimdall/internal/watcher"
+	"github.com/dadrus/heimdall/internal/x/pkix/pemx"
+	"github.com/dadrus/heimdall/internal/x/testsupport"
+)
+
+type testOpenIDProvider struct {
+	srv         *httptest.Server
+	key         *ecdsa.PrivateKey
+	handleToken func(t *testing.T, w http.ResponseWriter, r *http.Request)
+}
+
+func newTestOpenIDProvider(t *testing.T) *testOpenIDProvider {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	op := &testOpenIDProvider{key: key}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		err := json.NewEncoder(w).Encode(map[string]any{
+			"issuer":                 op.srv.URL,
+			"jwks_uri":               op.srv.URL + "/jwks",
+			"token_endpoint":         op.srv.URL + "/token",
+			"authorization_endpoint": op.srv.URL + "/authorize",
+			"end_session_endpoint":   op.srv.URL + "/logout",
+		})
+		assert.NoError(t, err)
+	})
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		err := json.NewEncoder(w).Encode(jose.JSONWebKeySet{Keys: []jose.JSONWebKey{
+			{Key: &op.key.PublicKey, KeyID: "op", Algorithm: string(jose.ES256), Use: "sig"},
+		}})
+		assert.NoError(t, err)
+	})
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		op.handleToken(t, w, r)
+	})
+
+	op.srv = httptest.NewServer(mux)
+	t.Cleanup(op.srv.Close)
+
+	return op
+}
+
+func (op *testOpenIDProvider) idToken(t *testing.T, subject, audience, nonce string) string {
+	t.Helper()
+
+	signer, err := jose.NewSigner(
+		jose.SigningKey{Algorithm: jose.ES256, Key: op.key},
+		(&jose.SignerOptions{}).WithType("JWT").WithHeader("kid", "op"))
+	require.NoError(t, err)
+
+	claims := map[string]any{
+		"sub":   subject,
+		"iss":   op.srv.URL,
+		"aud":   []string{audience},
+		"iat":   time.Now().Unix() - 1,
+		"nbf":   time.Now().Unix() - 1,
+		"exp":   time.Now().Unix() + 60,
+		"email": subject + "@example.com",
+	}
+
+	if len(nonce) != 0 {
+		claims["nonce"] = nonce
+	}
+
+	token, err := jwt.Signed(signer).Claims(claims).Serialize()
+	require.NoError(t, err)
+
+	return token
+}
+
+func (op *testOpenIDProvider) writeTokens(t *testing.T, w http.ResponseWriter, tokens map[string]any) {
+	t.Helper()
+
+	w.Header().Set("Content-Type", "application/json")
+
+	err := json.NewEncoder(w).Encode(tokens)
+	assert.NoError(t, err)
+}
+
+func newOIDCTestRequestContext(
+	t *testing.T, cch cache.Cache, method, rawURL string, cookies ...*http.Cookie,
+) *heimdallmocks.RequestContextMock {
+	t.Helper()
+
+	reqURL, err := url.Parse(rawURL)
+	require.NoError(t, err)
+
+	fnt := heimdallmocks.NewRequestFunctionsMock(t)
+	fnt.EXPECT().Cookie(mock.Anything).RunAndReturn(func(name string) string {
+		for _, cookie := range cookies {
+			if cookie.Name == name {
+				return cookie.Value
+			}
+		}
+
+		return ""
+	}).Maybe()
+
+	ctx := heimdallmocks.NewRequestContextMock(t)
+	ctx.EXPECT().Context().Maybe().Return(cache.WithContext(t.Context(), cch))
+	ctx.EXPECT().Request().Maybe().Return(&heimdall.Request{
+		RequestFunctions: fnt,
+		Method:           method,
+		URL:              &heimdall.URL{URL: *reqURL},
+	})
+
+	return ctx
+}
+
+func newOIDCTestSessionManager(t *testing.T) session.Manager {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	pemBytes, err := pemx.BuildPEM(pemx.WithECDSAPrivateKey(key, pemx.WithHeader("X-Key-ID", "session")))
+	require.NoError(t, err)
+
+	path := filepath.Join(t.TempDir(), "keystore.pem")
+	require.NoError(t, os.WriteFile(path, pemBytes, 0o600))
+
+	sm, err := cached.NewManager(&config.SessionConfig{KeyStore: config.KeyStore{Path: path}}, &watcher.NoopWatcher{})
+	require.NoError(t, err)
+
+	return sm
+}
+
+func newOIDCTestAuthenticator(t *testing.T, sm session.Manager, conf map[string]any) *oidcAuthenticator {
+	t.Helper()
+
+	validator, err := validation.NewValidator(
+		validation.WithTagValidator(config.EnforcementSettings{}),
+	)
+	require.NoError(t, err)
+
+	appCtx := app.NewContextMock(t)
+	appCtx.EXPECT().Validator().Maybe().Return(validator)
+	appCtx.EXPECT().Logger().Return(log.Logger)
+	appCtx.EXPECT().SessionManager().Return(sm)
+
+	auth, err := newOIDCAuthenticator(appCtx, "oidc", conf)
+	require.NoError(t, err)
+
+	return auth
+}
+
+func TestOIDCAuthenticatorCreate(t *testing.T) {
+	t.Parallel()
+
+	for uc, tc := range map[string]struct {
+		enforceTLS               bool
+		withoutSessionManagement bool
+		config                   []byte
+		assert                   func(t *testing.T, err error, auth *oidcAuthenticator)
+	}{
+		"without required properties": {
+			config: []byte(`scopes: [ profile ]`),
+			assert: func(t *testing.T, err error, _ *oidcAuthenticator) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrConfiguration)
+				require.ErrorContains(t, err, "'metadata_endpoint' is a required field")
+				require.ErrorContains(t, err, "'client_id' is a required field")
+				require.ErrorContains(t, err, "'redirect_uri' is a required field")
+			},
+		},
+		"without session management configured": {
+			withoutSessionManagement: true,
+			config: []byte(`
+metadata_endpoint: { url: https://idp.example.com/.well-known/openid-configuration }
+client_id: foo
+redirect_uri: https://app.example.com/callback
+`),
+			assert: func(t *testing.T, err error, _ *oidcAuthenticator) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrConfiguration)
+				require.ErrorContains(t, err, "requires session management to be configured")
+			},
+		},
+		"with redirect_uri not using TLS while TLS is enforced": {
+			enforceTLS: true,
+			config: []byte(`
+metadata_endpoint: { url: https://idp.example.com/.well-known/openid-configuration }
+client_id: foo
+redirect_uri: http://app.example.com/callback
+`),
+			assert: func(t *testing.T, err error, _ *oidcAuthenticator) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrConfiguration)
+				require.ErrorContains(t, err, "'redirect_uri' scheme must be https")
+			},
+		},
+		"with logout path equal to the callback path": {
+			config: []byte(`
+metadata_endpoint: { url: https://idp.example.com/.well-known/openid-configuration }
+client_id: foo
+redirect_uri: https://app.example.com/callback
+logout: { path: /callback }
+`),
+			assert: func(t *testing.T, err error, _ *oidcAuthenticator) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrConfiguration)
+				require.ErrorContains(t, err, "logout path must differ")
+			},
+		},
+		"with minimal valid configuration": {
+			config: []byte(`
+metadata_endpoint: { url: https://idp.example.com/.well-known/openid-configuration }
+client_id: foo
+redirect_uri: https://app.example.com/callback
+scopes: [ profile ]
+`),
+			assert: func(t *testing.T, err error, auth *oidcAuthenticator) {
+				t.Helper()
+
+				require.NoError(t, err)
+				assert.Equal(t, "with minimal valid configuration", auth.ID())
+				assert.False(t, auth.IsInsecure())
+				assert.Equal(t, "foo", auth.clientID)
+				assert.Nil(t, auth.clientAuth)
+				assert.Equal(t, "/callback", auth.redirectURI.Path)
+				assert.Equal(t, []string{"openid", "profile"}, auth.scopes)
+				assert.Nil(t, auth.logout)
+				assert.Equal(t, &SubjectInfo{IDFrom: "sub"}, auth.sf)
+				assert.NotNil(t, auth.sessions.sm)
+				assert.Equal(t, defaultSessionCookieName, auth.sessions.cookieName)
+				assert.True(t, auth.sessions.secure)
+				assert.Equal(t, []string{"foo"}, auth.idt.a.Audiences)
+				assert.Equal(t, defaultAllowedAlgorithms(), auth.idt.a.AllowedAlgorithms)
+			},
+		},
+		"with full valid configuration": {
+			config: []byte(`
+metadata_endpoint: { url: https://idp.example.com/.well-known/openid-configuration }
+client_id: foo
+client_secret: bar
+auth_method: request_body
+redirect_uri: https://app.example.com/callback
+scopes: [ openid, email ]
+assertions:
+  audience: [ baz ]
+  allowed_algorithms: [ RS256 ]
+subject:
+  id: email
+session:
+  cookie_name: my_session
+  domain: example.com
+logout:
+  path: /logout
+  post_logout_redirect_uri: https://app.example.com/
+`),
+			assert: func(t *testing.T, err error, auth *oidcAuthenticator) {
+				t.Helper()
+
+				require.NoError(t, err)
+				assert.Equal(t, &clientcredentials.Config{
+					ClientID:     "foo",
+					ClientSecret: "bar",
+					AuthMethod:   clientcredentials.AuthMethodRequestBody,
+				}, auth.clientAuth)
+				assert.Equal(t, []string{"openid", "email"}, auth.scopes)
+				assert.Equal(t, "/logout", auth.logout.Path)
+				assert.Equal(t, "https://app.example.com/", auth.logout.PostLogoutRedirectURI)
+				assert.Equal(t, &SubjectInfo{IDFrom: "email"}, auth.sf)
+				assert.Equal(t, "my_session", auth.sessions.cookieName)
+				assert.Equal(t, "my_session_state", auth.sessions.stateCookieName())
+				assert.Equal(t, "example.com", auth.sessions.domain)
+				assert.Equal(t, []string{"baz"}, auth.idt.a.Audiences)
+				assert.Equal(t, []string{"RS256"}, auth.idt.a.AllowedAlgorithms)
+			},
+		},
+	} {
+		t.Run(uc, func(t *testing.T) {
+			// GIVEN
+			conf, err := testsupport.DecodeTestConfig(tc.config)
+			require.NoError(t, err)
+
+			es := config.EnforcementSettings{EnforceEgressTLS: tc.enforceTLS}
+			validator, err := validation.NewValidator(
+				validation.WithTagValidator(es),
+				validation.WithErrorTranslator(es),
+			)
+			require.NoError(t, err)
+
+			appCtx := app.NewContextMock(t)
+			appCtx.EXPECT().Validator().Maybe().Return(validator)
+			appCtx.EXPECT().Logger().Return(log.Logger)
+
+			if tc.withoutSessionManagement {
+				appCtx.EXPECT().SessionManager().Return(nil)
+			} else {
+				appCtx.EXPECT().SessionManager().Maybe().Return(sessionmocks.NewManagerMock(t))
+			}
+
+			// WHEN
+			auth, err := newOIDCAuthenticator(appCtx, uc, conf)
+
+			// THEN
+			tc.assert(t, err, auth)
+		})
+	}
+}
+
+func TestOIDCAuthenticatorWithConfig(t *testing.T) {
+	t.Parallel()
+
+	auth := newOIDCTestAuthenticator(t, sessionmocks.NewManagerMock(t), map[string]any{
+		"metadata_endpoint": map[string]any{"url": "https://idp.example.com/.well-known/openid-configuration"},
+		"client_id":         "foo",
+		"redirect_uri":      "https://app.example.com/callback",
+	})
+
+	// without config
+	configured, err := auth.WithConfig(nil)
+	require.NoError(t, err)
+	assert.Equal(t, auth, configured)
+
+	// with config
+	_, err = auth.WithConfig(map[string]any{"scopes": []string{"foo"}})
+	require.Error(t, err)
+	require.ErrorIs(t, err, heimdall.ErrConfiguration)
+}
+
+func TestOIDCAuthenticatorLoginFlow(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	op := newTestOpenIDProvider(t)
+	cch, err := memory.NewCache(nil, nil)
+	require.NoError(t, err)
+
+	sm := newOIDCTestSessionManager(t)
+	auth := newOIDCTestAuthenticator(t, sm, map[string]any{
+		"metadata_endpoint": map[string]any{"url": op.srv.URL + "/.well-known/openid-configuration"},
+		"client_id":         "foo",
+		"client_secret":     "bar",
+		"redirect_uri":      "https://app.example.com/callback",
+		"assertions":        map[string]any{"allowed_algorithms": []string{"ES256"}},
+	})
+
+	// WHEN - the user is not authenticated
+	_, err = auth.Execute(newOIDCTestRequestContext(t, cch, http.MethodGet, "https://app.example.com/foo?bar=baz"))
+
+	// THEN
+	require.ErrorIs(t, err, heimdall.ErrAuthentication)
+
+	var redirector interface {
+		LoginRedirect(ctx heimdall.RequestContext) (*heimdall.RedirectError, error)
+	}
+
+	require.ErrorAs(t, err, &redirector)
+
+	// WHEN - the login flow is started
+	redirect, err := redirector.LoginRedirect(
+		newOIDCTestRequestContext(t, cch, http.MethodGet, "https://app.example.com/foo?bar=baz"))
+
+	// THEN
+	require.NoError(t, err)
+	require.NotNil(t, redirect)
+	assert.Equal(t, http.StatusFound, redirect.Code)
+	require.Len(t, redirect.Cookies, 1)
+	assert.Equal(t, "heimdall_sid_state", redirect.Cookies[0].Name)
+	assert.True(t, redirect.Cookies[0].HttpOnly)
+	assert.True(t, redirect.Cookies[0].Secure)
+
+	authURL, err := url.Parse(redirect.RedirectTo)
+	require.NoError(t, err)
+	assert.Equal(t, op.srv.URL+"/authorize", authURL.Scheme+"://"+authURL.Host+authURL.Path)
+
+	query := authURL.Query()
+	assert.Equal(t, "code", query.Get("response_type"))
+	assert.Equal(t, "foo", query.Get("client_id"))
+	assert.Equal(t, "https://app.example.com/callback", query.Get("redirect_uri"))
+	assert.Equal(t, "openid", query.Get("scope"))
+	assert.Equal(t, "S256", query.Get("code_challenge_method"))
+	assert.NotEmpty(t, query.Get("state"))
+	assert.NotEmpty(t, query.Get("nonce"))
+	assert.NotEmpty(t, query.Get("code_challenge"))
+
+	// WHEN - the user returns with the authorization code
+	op.handleToken = func(t *testing.T, w http.ResponseWriter, r *http.Request) {
+		t.Helper()
+
+		clientID, clientSecret, ok := r.BasicAuth()
+		assert.True(t, ok)
+		assert.Equal(t, "foo", clientID)
+		assert.Equal(t, "bar", clientSecret)
+
+		require.NoError(t, r.ParseForm())
+		assert.Equal(t, "authorization_code", r.PostForm.Get("grant_type"))
+		assert.Equal(t, "the-code", r.PostForm.Get("code"))
+		assert.Equal(t, "https://app.example.com/callback", r.PostForm.Get("redirect_uri"))
+
+		challenge := sha256.Sum256([]byte(r.PostForm.Get("code_verifier")))
+		assert.Equal(t, query.Get("code_challenge"), base64.RawURLEncoding.EncodeToString(challenge[:]))
+
+		op.writeTokens(t, w, map[string]any{
+			"access_token":  "access",
+			"token_type":    "Bearer",
+			"refresh_token": "refresh",
+			"expires_in":    300,
+			"id_token":      op.idToken(t, "alice", "foo", query.Get("nonce")),
+		})
+	}
+
+	callbackURL := "https://app.example.com/callback?code=the-code&state=" + query.Get("state")
+
+	_, err = auth.Execute(newOIDCTestRequestContext(t, cch, http.MethodGet, callbackURL, redirect.Cookies[0]))
+
+	// THEN
+	var completed *heimdall.RedirectError
+
+	require.ErrorAs(t, err, &completed)
+	assert.Equal(t, http.StatusSeeOther, completed.Code)
+	assert.Equal(t, "https://app.example.com/foo?bar=baz", completed.RedirectTo)
+	require.Len(t, completed.Cookies, 2)
+	assert.Equal(t, "heimdall_sid", completed.Cookies[0].Name)
+	assert.NotEmpty(t, completed.Cookies[0].Value)
+	assert.Equal(t, "heimdall_sid_state", completed.Cookies[1].Name)
+	assert.Equal(t, -1, completed.Cookies[1].MaxAge)
+
+	// WHEN - the callback is replayed
+	_, err = auth.Execute(newOIDCTestRequestContext(t, cch, http.MethodGet, callbackURL, redirect.Cookies[0]))
+
+	// THEN
+	require.ErrorIs(t, err, heimdall.ErrAuthentication)
+	require.ErrorContains(t, err, "unknown login state")
+
+	// WHEN - the user accesses the resource with the session
+	sub, err := auth.Execute(newOIDCTestRequestContext(t, cch, http.MethodGet,
+		"https://app.example.com/foo?bar=baz", completed.Cookies[0]))
+
+	// THEN
+	require.NoError(t, err)
+	assert.Equal(t, "alice", sub.ID)
+	assert.Equal(t, "alice@example.com", sub.Attributes["email"])
+
+	// WHEN - the sessions of the user are revoked
+	require.NoError(t, sm.RevokeAll(cache.WithContext(t.Context(), cch), "alice"))
+
+	_, err = auth.Execute(newOIDCTestRequestContext(t, cch, http.MethodGet,
+		"https://app.example.com/foo?bar=baz", completed.Cookies[0]))
+
+	// THEN
+	require.ErrorIs(t, err, heimdall.ErrAuthentication)
+	require.ErrorContains(t, err, "no valid session present")
+}
+
+func TestOIDCAuthenticatorCallback(t *testing.T) {
+	t.Parallel()
+
+	op := newTestOpenIDProvider(t)
+	cch, err := memory.NewCache(nil, nil)
+	require.NoError(t, err)
+
+	auth := newOIDCTestAuthenticator(t, newOIDCTestSessionManager(t), map[string]any{
+		"metadata_endpoint": map[string]any{"url": op.srv.URL + "/.well-known/openid-configuration"},
+		"client_id":         "foo",
+		"redirect_uri":      "https://app.example.com/callback",
+		"assertions":        map[string]any{"allowed_algorithms": []string{"ES256"}},
+	})
+
+	validState := &oidcLoginState{
+		State:        "state",
+		Nonce:        "nonce",
+		CodeVerifier: "verifier",
+		RedirectTo:   "https://app.example.com/foo",
+		ExpiresAt:    time.Now().Add(time.Minute),
+	}
+
+	for uc, tc := range map[string]struct {
+		url         string
+		state       *oidcLoginState
+		stateRef    string
+		handleToken func(t *testing.T, w http.ResponseWriter, r *http.Request)
+		assert      func(t *testing.T, err error)
+	}{
+		"without login state cookie": {
+			url: "https://app.example.com/callback?code=foo&state=state",
+			assert: func(t *testing.T, err error) {
+				t.Helper()
+
+				require.ErrorIs(t, err, heimdall.ErrAuthentication)
+				require.ErrorContains(t, err, "no login state cookie present")
+
+				// must not result in a new login flow
+				var redirector interface {
+					LoginRedirect(ctx heimdall.RequestContext) (*heimdall.RedirectError, error)
+				}
+
+				require.ErrorAs(t, err, &redirector)
+
+				redirect, err := redirector.LoginRedirect(newOIDCTestRequestContext(t, cch, http.MethodGet,
+					"https://app.example.com/callback?code=foo&state=state"))
+				require.NoError(t, err)
+				assert.Nil(t, redirect)
+			},
+		},
+		"with unknown login state": {
+			url:      "https://app.example.com/callback?code=foo&state=state",
+			stateRef: "foo",
+			assert: func(t *testing.T, err error) {
+				t.Helper()
+
+				require.ErrorIs(t, err, heimdall.ErrAuthentication)
+				require.ErrorContains(t, err, "unknown login state")
+			},
+		},
+		"with expired login state": {
+			url:   "https://app.example.com/callback?code=foo&state=state",
+			state: &oidcLoginState{State: "state", ExpiresAt: time.Now().Add(-time.Minute)},
+			assert: func(t *testing.T, err error) {
+				t.Helper()
+
+				require.ErrorIs(t, err, heimdall.ErrAuthentication)
+				require.ErrorContains(t, err, "login state expired")
+			},
+		},
+		"with error from the authorization endpoint": {
+			url:   "https://app.example.com/callback?error=access_denied&state=state",
+			state: validState,
+			assert: func(t *testing.T, err error) {
+				t.Helper()
+
+				require.ErrorIs(t, err, heimdall.ErrAuthentication)
+				require.ErrorContains(t, err, "access_denied")
+			},
+		},
+		"with not matching state": {
+			url:   "https://app.example.com/callback?code=foo&state=bar",
+			state: validState,
+			assert: func(t *testing.T, err error) {
+				t.Helper()
+
+				require.ErrorIs(t, err, heimdall.ErrAuthentication)
+				require.ErrorContains(t, err, "state parameter does not match")
+			},
+		},
+		"without authorization code": {
+			url:   "https://app.example.com/callback?state=state",
+			state: validState,
+			assert: func(t *testing.T, err error) {
+				t.Helper()
+
+				require.ErrorIs(t, err, heimdall.ErrAuthentication)
+				require.ErrorContains(t, err, "no authorization code present")
+			},
+		},
+		"with error from the token endpoint": {
+			url:   "https://app.example.com/callback?code=foo&state=state",
+			state: validState,
+			handleToken: func(t *testing.T, w http.ResponseWriter, r *http.Request) {
+				t.Helper()
+
+				require.NoError(t, r.ParseForm())
+				assert.Equal(t, "foo", r.PostForm.Get("client_id"))
+				assert.Equal(t, "verifier", r.PostForm.Get("code_verifier"))
+
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(http.StatusBadRequest)
+				_, err := w.Write([]byte(`{"error":"invalid_grant"}`))
+				assert.NoError(t, err)
+			},
+			assert: func(t *testing.T, err error) {
+				t.Helper()
+
+				require.ErrorIs(t, err, heimdall.ErrAuthentication)
+				require.ErrorContains(t, err, "invalid_grant")
+			},
+		},
+		"without id token in the token response": {
+			url:   "https://app.example.com/callback?code=foo&state=state",
+			state: validState,
+			handleToken: func(t *testing.T, w http.ResponseWriter, _ *http.Request) {
+				t.Helper()
+
+				op.writeTokens(t, w, map[string]any{"access_token": "foo", "token_type": "Bearer"})
+			},
+			assert: func(t *testing.T, err error) {
+				t.Helper()
+
+				require.ErrorIs(t, err, heimdall.ErrAuthentication)
+				require.ErrorContains(t, err, "does not contain an id token")
+			},
+		},
+		"with id token for another audience": {
+			url:   "https://app.example.com/callback?code=foo&state=state",
+			state: validState,
+			handleToken: func(t *testing.T, w http.ResponseWriter, _ *http.Request) {
+				t.Helper()
+
+				op.writeTokens(t, w, map[string]any{"id_token": op.idToken(t, "alice", "bar", "nonce")})
+			},
+			assert: func(t *testing.T, err error) {
+				t.Helper()
+
+				require.ErrorIs(t, err, heimdall.ErrAuthentication)
+				require.ErrorContains(t, err, "id token verification failed")
+			},
+		},
+		"with not matching nonce": {
+			url:   "https://app.example.com/callback?code=foo&state=state",
+			state: validState,
+			handleToken: func(t *testing.T, w http.ResponseWriter, _ *http.Request) {
+				t.Helper()
+
+				op.writeTokens(t, w, map[string]any{"id_token": op.idToken(t, "alice", "foo", "bar")})
+			},
+			assert: func(t *testing.T, err error) {
+				t.Helper()
+
+				require.ErrorIs(t, err, heimdall.ErrAuthentication)
+				require.ErrorContains(t, err, "nonce in the id token does not match")
+			},
+		},
+	} {
+		t.Run(uc, func(t *testing.T) {
+			// GIVEN
+			op.handleToken = tc.handleToken
+
+			var cookies []*http.Cookie
+
+			switch {
+			case tc.state != nil:
+				// the login state can be used only once
+				cookie, err := auth.sessions.saveLoginState(
+					newOIDCTestRequestContext(t, cch, http.MethodGet, tc.url), tc.state)
+				require.NoError(t, err)
+
+				cookies = append(cookies, cookie)
+			case len(tc.stateRef) != 0:
+				cookies = append(cookies, &http.Cookie{Name: auth.sessions.stateCookieName(), Value: tc.stateRef})
+			}
+
+			// WHEN
+			_, err := auth.Execute(newOIDCTestRequestContext(t, cch, http.MethodGet, tc.url, cookies...))
+
+			// THEN
+			tc.assert(t, err)
+		})
+	}
+}
+
+func TestOIDCAuthenticatorSessionRefresh(t *testing.T) {
+	t.Parallel()
+
+	for uc, tc := range map[string]struct {
+		refreshToken string
+		revoke       bool
+		handleToken  func(t *testing.T, op *testOpenIDProvider, w http.ResponseWriter, r *http.Request)
+		assert       func(t *testing.T, err error, sess *session.Session)
+	}{
+		"without refresh token": {
+			assert: func(t *testing.T, err error, _ *session.Session) {
+				t.Helper()
+
+				require.ErrorIs(t, err, heimdall.ErrAuthentication)
+				require.ErrorContains(t, err, "session tokens expired")
+			},
+		},
+		"refresh fails": {
+			refreshToken: "refresh",
+			handleToken: func(t *testing.T, _ *testOpenIDProvider, w http.ResponseWriter, _ *http.Request) {
+				t.Helper()
+
+				w.WriteHeader(http.StatusInternalServerError)
+			},
+			assert: func(t *testing.T, err error, _ *session.Session) {
+				t.Helper()
+
+				require.ErrorIs(t, err, heimdall.ErrAuthentication)
+				require.ErrorContains(t, err, "failed to refresh session")
+			},
+		},
+		"refreshed id token for another subject": {
+			refreshToken: "refresh",
+			handleToken: func(t *testing.T, op *testOpenIDProvider, w http.ResponseWriter, _ *http.Request) {
+				t.Helper()
+
+				op.writeTokens(t, w, map[string]any{"id_token": op.idToken(t, "bob", "foo", "")})
+			},
+			assert: func(t *testing.T, err error, _ *session.Session) {
+				t.Helper()
+
+				require.ErrorIs(t, err, heimdall.ErrAuthentication)
+				require.ErrorContains(t, err, "does not match the subject of the session")
+			},
+		},
+		"session revoked while being refreshed": {
+			refreshToken: "refresh",
+			revoke:       true,
+			handleToken: func(t *testing.T, op *testOpenIDProvider, w http.ResponseWriter, _ *http.Request) {
+				t.Helper()
+
+				op.writeTokens(t, w, map[string]any{"refresh_token": "new-refresh", "expires_in": 300})
+			},
+			assert: func(t *testing.T, err error, sess *session.Session) {
+				t.Helper()
+
+				require.ErrorIs(t, err, heimdall.ErrAuthentication)
+				require.ErrorContains(t, err, "failed to update session")
+				assert.Nil(t, sess)
+			},
+		},
+		"successful refresh with new id token": {
+			refreshToken: "refresh",
+			handleToken: func(t *testing.T, op *testOpenIDProvider, w http.ResponseWriter, r *http.Request) {
+				t.Helper()
+
+				require.NoError(t, r.ParseForm())
+				assert.Equal(t, "refresh_token", r.PostForm.Get("grant_type"))
+				assert.Equal(t, "refresh", r.PostForm.Get("refresh_token"))
+
+				op.writeTokens(t, w, map[string]any{
+					"access_token":  "access",
+					"refresh_token": "new-refresh",
+					"expires_in":    300,
+					"id_token":      op.idToken(t, "alice", "foo", ""),
+				})
+			},
+			assert: func(t *testing.T, err error, sess *session.Session) {
+				t.Helper()
+
+				require.NoError(t, err)
+				require.NotNil(t, sess)
+				assert.Equal(t, "new-refresh", sess.RefreshMaterial[oidcRefreshTokenKey])
+				assert.NotEmpty(t, sess.RefreshMaterial[oidcIDTokenKey])
+				assert.True(t, tokenExpiry(sess).After(time.Now()))
+				assert.Equal(t, "alice@example.com", sess.Subject.Attributes["email"])
+			},
+		},
+		"successful refresh without new tokens": {
+			refreshToken: "refresh",
+			handleToken: func(t *testing.T, op *testOpenIDProvider, w http.ResponseWriter, _ *http.Request) {
+				t.Helper()
+
+				op.writeTokens(t, w, map[string]any{"access_token": "access"})
+			},
+			assert: func(t *testing.T, err error, sess *session.Session) {
+				t.Helper()
+
+				require.NoError(t, err)
+				require.NotNil(t, sess)
+				assert.Equal(t, "refresh", sess.RefreshMaterial[oidcRefreshTokenKey])
+				assert.True(t, tokenExpiry(sess).IsZero())
+			},
+		},
+	} {
+		t.Run(uc, func(t *testing.T) {
+			t.Parallel()
+
+			// GIVEN
+			op := newTestOpenIDProvider(t)
+			cch, err := memory.NewCache(nil, nil)
+			require.NoError(t, err)
+
+			ctx := cache.WithContext(t.Context(), cch)
+			sm := newOIDCTestSessionManager(t)
+
+			op.handleToken = func(t *testing.T, w http.ResponseWriter, r *http.Request) {
+				t.Helper()
+
+				if tc.revoke {
+					require.NoError(t, sm.RevokeAll(ctx, "alice"))
+				}
+
+				tc.handleToken(t, op, w, r)
+			}
+
+			auth := newOIDCTestAuthenticator(t, sm, map[string]any{
+				"metadata_endpoint": map[string]any{"url": op.srv.URL + "/.well-known/openid-configuration"},
+				"client_id":         "foo",
+				"redirect_uri":      "https://app.example.com/callback",
+				"assertions":        map[string]any{"allowed_algorithms": []string{"ES256"}},
+			})
+
+			refreshMaterial := map[string]string{
+				oidcTokenExpiryKey: strconv.FormatInt(time.Now().Add(-time.Minute).Unix(), 10),
+			}
+
+			if len(tc.refreshToken) != 0 {
+				refreshMaterial[oidcRefreshTokenKey] = tc.refreshToken
+			}
+
+			sess, err := sm.Create(ctx, &subject.Subject{ID: "alice"}, refreshMaterial)
+			require.NoError(t, err)
+
+			// WHEN
+			sub, err := auth.Execute(newOIDCTestRequestContext(t, cch, http.MethodGet,
+				"https://app.example.com/foo", &http.Cookie{Name: defaultSessionCookieName, Value: sess.ID}))
+
+			// THEN
+			if err == nil {
+				assert.Equal(t, "alice", sub.ID)
+			}
+
+			sess, _ = sm.Load(ctx, sess.ID)
+			tc.assert(t, err, sess)
+		})
+	}
+}
+
+func TestOIDCAuthenticatorLogout(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	op := newTestOpenIDProvider(t)
+	cch, err := memory.NewCache(nil, nil)
+	require.NoError(t, err)
+
+	sm := newOIDCTestSessionManager(t)
+	auth := newOIDCTestAuthenticator(t, sm, map[string]any{
+		"metadata_endpoint": map[string]any{"url": op.srv.URL + "/.well-known/openid-configuration"},
+		"client_id":         "foo",
+		"redirect_uri":      "https://app.example.com/callback",
+		"logout": map[string]any{
+			"path":                     "/logout",
+			"post_logout_redirect_uri": "https://app.example.com/",
+		},
+	})
+
+	sess, err := sm.Create(cache.WithContext(t.Context(), cch), &subject.Subject{ID: "alice"},
+		map[string]string{oidcIDTokenKey: "id-token"})
+	require.NoError(t, err)
+
+	sessionCookie := &http.Cookie{Name: defaultSessionCookieName, Value: sess.ID}
+
+	// WHEN
+	_, err = auth.Execute(newOIDCTestRequestContext(t, cch, http.MethodGet,
+		"https://app.example.com/logout", sessionCookie))
+
+	// THEN
+	var redirect *heimdall.RedirectError
+
+	require.ErrorAs(t, err, &redirect)
+	assert.Equal(t, http.StatusFound, redirect.Code)
+	require.Len(t, redirect.Cookies, 1)
+	assert.Equal(t, defaultSessionCookieName, redirect.Cookies[0].Name)
+	assert.Equal(t, -1, redirect.Cookies[0].MaxAge)
+
+	logoutURL, err := url.Parse(redirect.RedirectTo)
+	require.NoError(t, err)
+	assert.Equal(t, "/logout", logoutURL.Path)
+	assert.Equal(t, "foo", logoutURL.Query().Get("client_id"))
+	assert.Equal(t, "id-token", logoutURL.Query().Get("id_token_hint"))
+	assert.Equal(t, "https://app.example.com/", logoutURL.Query().Get("post_logout_redirect_uri"))
+
+	// the session is not usable anymore
+	_, err = auth.Execute(newOIDCTestRequestContext(t, cch, http.MethodGet,
+		"https://app.example.com/foo", sessionCookie))
+	require.ErrorIs(t, err, heimdall.ErrAuthentication)
+	require.NotErrorIs(t, err, &heimdall.RedirectError{})
+}
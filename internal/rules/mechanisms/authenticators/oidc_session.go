@@ -0,0 +1,259 @@
+// Copyright 2025 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package authenticators
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/goccy/go-json"
+	"github.com/rs/zerolog"
+
+	"github.com/dadrus/heimdall/internal/cache"
+	"github.com/dadrus/heimdall/internal/heimdall"
+	"github.com/dadrus/heimdall/internal/rules/mechanisms/subject"
+	"github.com/dadrus/heimdall/internal/session"
+	"github.com/dadrus/heimdall/internal/x"
+	"github.com/dadrus/heimdall/internal/x/errorchain"
+	"github.com/dadrus/heimdall/internal/x/stringx"
+)
+
+const (
+	oidcLoginStateLifespan = 10 * time.Minute
+	oidcRandomValueLength  = 32
+
+	// keys of the refresh material of the sessions created by the oidc authenticator.
+	oidcIDTokenKey      = "id_token"
+	oidcRefreshTokenKey = "refresh_token"
+	oidcTokenExpiryKey  = "token_expiry"
+)
+
+type oidcSessionConfig struct {
+	CookieName string `mapstructure:"cookie_name"`
+	Domain     string `mapstructure:"domain"`
+}
+
+// oidcLoginState holds the information about an ongoing login flow.
+type oidcLoginState struct {
+	State        string    `json:"state"`
+	Nonce        string    `json:"nonce"`
+	CodeVerifier string    `json:"code_verifier"`
+	RedirectTo   string    `json:"redirect_to"`
+	ExpiresAt    time.Time `json:"expires_at"`
+}
+
+// oidcSessions manages the sessions of the users authenticated by the oidc authenticator using
+// the configured session management. That way these sessions can be revoked like any other session.
+// The state of ongoing login flows is kept in the cache with the cookie holding a reference to it.
+type oidcSessions struct {
+	id         string
+	sm         session.Manager
+	cookieName string
+	domain     string
+	secure     bool
+}
+
+func newOIDCSessions(id string, sm session.Manager, conf oidcSessionConfig, secure bool) *oidcSessions {
+	return &oidcSessions{
+		id:         id,
+		sm:         sm,
+		cookieName: x.IfThenElse(len(conf.CookieName) != 0, conf.CookieName, defaultSessionCookieName),
+		domain:     conf.Domain,
+		secure:     secure,
+	}
+}
+
+func (s *oidcSessions) stateCookieName() string { return s.cookieName + "_state" }
+
+// load returns the session referenced by the session cookie. Returns an error if there is no or
+// no valid session.
+func (s *oidcSessions) load(ctx heimdall.RequestContext) (*session.Session, error) {
+	sid := ctx.Request().Cookie(s.cookieName)
+	if len(sid) == 0 {
+		return nil, errorchain.NewWithMessage(heimdall.ErrAuthentication, "no session cookie present")
+	}
+
+	sess, err := s.sm.Load(ctx.Context(), sid)
+	if err != nil {
+		return nil, errorchain.NewWithMessage(heimdall.ErrAuthentication, "unknown session").CausedBy(err)
+	}
+
+	return sess, nil
+}
+
+// create creates a new session for the given subject and returns the cookie to be set in the client.
+func (s *oidcSessions) create(
+	ctx heimdall.RequestContext, sub *subject.Subject, tokens *oidcTokenResponse,
+) (*http.Cookie, error) {
+	refreshMaterial := map[string]string{}
+	updateRefreshMaterial(refreshMaterial, tokens)
+
+	sess, err := s.sm.Create(ctx.Context(), sub, refreshMaterial)
+	if err != nil {
+		return nil, errorchain.NewWithMessage(heimdall.ErrInternal, "failed to create session").CausedBy(err)
+	}
+
+	return s.cookie(s.cookieName, sess.ID, sess.ExpiresAt), nil
+}
+
+// remove revokes the session referenced by the session cookie and returns the cookie removing the
+// session cookie from the client.
+func (s *oidcSessions) remove(ctx heimdall.RequestContext) *http.Cookie {
+	if sid := ctx.Request().Cookie(s.cookieName); len(sid) != 0 {
+		if err := s.sm.Revoke(ctx.Context(), sid); err != nil {
+			logger := zerolog.Ctx(ctx.Context())
+			logger.Warn().Err(err).Str("_id", s.id).Msg("Failed to revoke session")
+		}
+	}
+
+	return s.expiredCookie(s.cookieName)
+}
+
+// saveLoginState stores the given state in the cache and returns the cookie referencing it.
+func (s *oidcSessions) saveLoginState(ctx heimdall.RequestContext, state *oidcLoginState) (*http.Cookie, error) {
+	ref, err := randomString(oidcRandomValueLength)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := json.Marshal(state)
+	if err != nil {
+		return nil, errorchain.NewWithMessage(heimdall.ErrInternal, "failed to marshal login state").
+			CausedBy(err)
+	}
+
+	if err = cache.Ctx(ctx.Context()).Set(ctx.Context(), s.loginStateKey(ref), data,
+		oidcLoginStateLifespan); err != nil {
+		return nil, errorchain.NewWithMessage(heimdall.ErrInternal, "failed to store login state").
+			CausedBy(err)
+	}
+
+	return s.cookie(s.stateCookieName(), ref, state.ExpiresAt), nil
+}
+
+// loginState returns the state of the login flow referenced by the login state cookie. The state
+// can be used only once.
+func (s *oidcSessions) loginState(ctx heimdall.RequestContext) (*oidcLoginState, error) {
+	ref := ctx.Request().Cookie(s.stateCookieName())
+	if len(ref) == 0 {
+		return nil, errorchain.NewWithMessage(heimdall.ErrAuthentication, "no login state cookie present")
+	}
+
+	cch := cache.Ctx(ctx.Context())
+
+	data, err := cch.Get(ctx.Context(), s.loginStateKey(ref))
+	if err != nil || len(data) == 0 {
+		return nil, errorchain.NewWithMessage(heimdall.ErrAuthentication, "unknown login state")
+	}
+
+	// there is no explicit delete operation. Overwriting the entry with a short ttl
+	// makes it unusable as it is not a valid state anymore
+	if err = cch.Set(ctx.Context(), s.loginStateKey(ref), []byte{}, time.Second); err != nil {
+		return nil, errorchain.NewWithMessage(heimdall.ErrInternal, "failed to invalidate login state").
+			CausedBy(err)
+	}
+
+	var state oidcLoginState
+	if err = json.Unmarshal(data, &state); err != nil {
+		return nil, errorchain.NewWithMessage(heimdall.ErrAuthentication, "invalid login state").CausedBy(err)
+	}
+
+	if time.Now().After(state.ExpiresAt) {
+		return nil, errorchain.NewWithMessage(heimdall.ErrAuthentication, "login state expired")
+	}
+
+	return &state, nil
+}
+
+func (s *oidcSessions) cookie(name, value string, expiresAt time.Time) *http.Cookie {
+	return &http.Cookie{
+		Name:     name,
+		Value:    value,
+		Path:     "/",
+		Domain:   s.domain,
+		Expires:  expiresAt,
+		MaxAge:   int(time.Until(expiresAt).Seconds()),
+		Secure:   s.secure,
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+	}
+}
+
+func (s *oidcSessions) expiredCookie(name string) *http.Cookie {
+	return &http.Cookie{
+		Name:     name,
+		Path:     "/",
+		Domain:   s.domain,
+		MaxAge:   -1,
+		Secure:   s.secure,
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+	}
+}
+
+func (s *oidcSessions) loginStateKey(ref string) string {
+	digest := sha256.New()
+	digest.Write(stringx.ToBytes("oidc_login_state"))
+	digest.Write(stringx.ToBytes(s.id))
+	digest.Write(stringx.ToBytes(ref))
+
+	return hex.EncodeToString(digest.Sum(nil))
+}
+
+// updateRefreshMaterial sets the tokens from the given response in the refresh material of a session.
+// A refresh token is only replaced if the response contains a new one.
+func updateRefreshMaterial(refreshMaterial map[string]string, tokens *oidcTokenResponse) {
+	if len(tokens.IDToken) != 0 {
+		refreshMaterial[oidcIDTokenKey] = tokens.IDToken
+	}
+
+	if len(tokens.RefreshToken) != 0 {
+		refreshMaterial[oidcRefreshTokenKey] = tokens.RefreshToken
+	}
+
+	if expiry := tokens.expiry(); !expiry.IsZero() {
+		refreshMaterial[oidcTokenExpiryKey] = strconv.FormatInt(expiry.Unix(), 10)
+	} else {
+		delete(refreshMaterial, oidcTokenExpiryKey)
+	}
+}
+
+// tokenExpiry returns the expiry of the tokens held in the given session, or the zero time if unknown.
+func tokenExpiry(sess *session.Session) time.Time {
+	expiry, err := strconv.ParseInt(sess.RefreshMaterial[oidcTokenExpiryKey], 10, 64)
+	if err != nil {
+		return time.Time{}
+	}
+
+	return time.Unix(expiry, 0)
+}
+
+func randomString(length int) (string, error) {
+	buf := make([]byte, length)
+
+	if _, err := rand.Read(buf); err != nil {
+		return "", errorchain.NewWithMessage(heimdall.ErrInternal, "failed to generate random value").
+			CausedBy(err)
+	}
+
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
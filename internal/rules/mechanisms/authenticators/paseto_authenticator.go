@@ -0,0 +1,288 @@
+// Copyright 2025 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package authenticators
+
+import (
+	"crypto/ed25519"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/goccy/go-json"
+	"github.com/rs/zerolog"
+
+	"github.com/dadrus/heimdall/internal/app"
+	"github.com/dadrus/heimdall/internal/heimdall"
+	"github.com/dadrus/heimdall/internal/rules/endpoint"
+	"github.com/dadrus/heimdall/internal/rules/mechanisms/authenticators/extractors"
+	"github.com/dadrus/heimdall/internal/rules/mechanisms/oauth2"
+	"github.com/dadrus/heimdall/internal/rules/mechanisms/subject"
+	"github.com/dadrus/heimdall/internal/x"
+	"github.com/dadrus/heimdall/internal/x/errorchain"
+	"github.com/dadrus/heimdall/internal/x/paseto"
+)
+
+// by intention. Used only during application bootstrap
+//
+//nolint:gochecknoinits
+func init() {
+	registerTypeFactory(
+		func(app app.Context, id string, typ string, conf map[string]any) (bool, Authenticator, error) {
+			if typ != AuthenticatorPASETO {
+				return false, nil, nil
+			}
+
+			auth, err := newPasetoAuthenticator(app, id, conf)
+
+			return true, auth, err
+		})
+}
+
+// pasetoClaims represents the registered claims of a PASETO token. In contrast to JWTs, the time
+// related claims are encoded as RFC 3339 strings.
+type pasetoClaims struct {
+	Issuer    string          `json:"iss,omitempty"`
+	Audience  oauth2.Audience `json:"aud,omitempty"`
+	Scp       oauth2.Scopes   `json:"scp,omitempty"`
+	Scope     oauth2.Scopes   `json:"scope,omitempty"`
+	Expiry    *time.Time      `json:"exp,omitempty"`
+	NotBefore *time.Time      `json:"nbf,omitempty"`
+	IssuedAt  *time.Time      `json:"iat,omitempty"`
+}
+
+func (c pasetoClaims) Validate(exp oauth2.Expectation) error {
+	if err := exp.AssertIssuer(c.Issuer); err != nil {
+		return err
+	}
+
+	if err := exp.AssertAudience(c.Audience); err != nil {
+		return err
+	}
+
+	if err := exp.AssertValidity(timeOrZero(c.NotBefore), timeOrZero(c.Expiry)); err != nil {
+		return err
+	}
+
+	if err := exp.AssertIssuanceTime(timeOrZero(c.IssuedAt)); err != nil {
+		return err
+	}
+
+	return exp.AssertScopes(x.IfThenElse(len(c.Scp) != 0, c.Scp, c.Scope))
+}
+
+func timeOrZero(t *time.Time) time.Time {
+	if t == nil {
+		return time.Time{}
+	}
+
+	return *t
+}
+
+type pasetoAuthenticator struct {
+	id  string
+	app app.Context
+	ks  keySet
+	a   oauth2.Expectation
+	ttl *time.Duration
+	sf  SubjectFactory
+	ads extractors.AuthDataExtractStrategy
+}
+
+func newPasetoAuthenticator(app app.Context, id string, rawConfig map[string]any) (*pasetoAuthenticator, error) {
+	logger := app.Logger()
+	logger.Info().Str("_id", id).Msg("Creating paseto authenticator")
+
+	type Config struct {
+		JWKSFile       string                              `mapstructure:"jwks_file"               validate:"required_without=JWKSEndpoint,excluded_with=JWKSEndpoint"` //nolint:lll,tagalign
+		JWKSEndpoint   *endpoint.Endpoint                  `mapstructure:"jwks_endpoint"           validate:"required_without=JWKSFile,excluded_with=JWKSFile"`         //nolint:lll,tagalign
+		Assertions     oauth2.Expectation                  `mapstructure:"assertions"`
+		SubjectInfo    SubjectInfo                         `mapstructure:"subject"                 validate:"-"`
+		AuthDataSource extractors.CompositeExtractStrategy `mapstructure:"token_source"`
+		CacheTTL       *time.Duration                      `mapstructure:"cache_ttl"`
+	}
+
+	var conf Config
+	if err := decodeConfig(app, rawConfig, &conf); err != nil {
+		return nil, errorchain.NewWithMessagef(heimdall.ErrConfiguration,
+			"failed decoding config for paseto authenticator '%s'", id).CausedBy(err)
+	}
+
+	if conf.JWKSEndpoint != nil && strings.HasPrefix(conf.JWKSEndpoint.URL, "http://") {
+		logger.Warn().Str("_id", id).
+			Msg("No TLS configured for the jwks endpoint used in paseto authenticator")
+	}
+
+	if conf.Assertions.ScopesMatcher == nil {
+		conf.Assertions.ScopesMatcher = oauth2.NoopMatcher{}
+	}
+
+	if len(conf.SubjectInfo.IDFrom) == 0 {
+		conf.SubjectInfo.IDFrom = "sub"
+	}
+
+	ks, err := newKeySet(conf.JWKSFile, conf.JWKSEndpoint, app.Watcher(), false)
+	if err != nil {
+		return nil, errorchain.NewWithMessagef(heimdall.ErrConfiguration,
+			"failed to create key set for paseto authenticator '%s'", id).CausedBy(err)
+	}
+
+	ads := x.IfThenElseExec(conf.AuthDataSource == nil,
+		func() extractors.CompositeExtractStrategy {
+			return extractors.CompositeExtractStrategy{
+				extractors.HeaderValueExtractStrategy{Name: "Authorization", Scheme: "Bearer"},
+			}
+		},
+		func() extractors.CompositeExtractStrategy { return conf.AuthDataSource },
+	)
+
+	return &pasetoAuthenticator{
+		id:  id,
+		app: app,
+		ks:  ks,
+		a:   conf.Assertions,
+		ttl: conf.CacheTTL,
+		sf:  &conf.SubjectInfo,
+		ads: ads,
+	}, nil
+}
+
+func (a *pasetoAuthenticator) Execute(ctx heimdall.RequestContext) (*subject.Subject, error) {
+	logger := zerolog.Ctx(ctx.Context())
+	logger.Debug().Str("_id", a.id).Msg("Authenticating using paseto authenticator")
+
+	rawToken, err := a.ads.GetAuthData(ctx)
+	if err != nil {
+		return nil, errorchain.
+			NewWithMessage(heimdall.ErrAuthentication, "no PASETO token present").
+			WithErrorContext(a).
+			CausedBy(err)
+	}
+
+	token, err := paseto.Parse(rawToken)
+	if err != nil {
+		return nil, errorchain.
+			NewWithMessage(heimdall.ErrAuthentication, "failed to parse PASETO token").
+			WithErrorContext(a).
+			CausedBy(heimdall.ErrArgument).
+			CausedBy(err)
+	}
+
+	payload, err := a.verifyToken(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+
+	var claims pasetoClaims
+	if err = json.Unmarshal(payload, &claims); err != nil {
+		return nil, errorchain.
+			NewWithMessage(heimdall.ErrAuthentication, "failed to deserialize PASETO token claims").
+			WithErrorContext(a).
+			CausedBy(err)
+	}
+
+	if err = claims.Validate(a.a); err != nil {
+		return nil, errorchain.
+			NewWithMessage(heimdall.ErrAuthentication, "PASETO token does not satisfy assertion conditions").
+			WithErrorContext(a).
+			CausedBy(err)
+	}
+
+	sub, err := a.sf.CreateSubject(payload)
+	if err != nil {
+		return nil, errorchain.
+			NewWithMessage(heimdall.ErrInternal, "failed to extract subject information from PASETO token").
+			WithErrorContext(a).
+			CausedBy(err)
+	}
+
+	return sub, nil
+}
+
+func (a *pasetoAuthenticator) WithConfig(config map[string]any) (Authenticator, error) {
+	// this authenticator allows assertions and ttl to be redefined on the rule level
+	if len(config) == 0 {
+		return a, nil
+	}
+
+	type Config struct {
+		Assertions oauth2.Expectation `mapstructure:"assertions"`
+		CacheTTL   *time.Duration     `mapstructure:"cache_ttl"`
+	}
+
+	var conf Config
+	if err := decodeConfig(a.app, config, &conf); err != nil {
+		return nil, errorchain.NewWithMessagef(heimdall.ErrConfiguration,
+			"failed decoding config for paseto authenticator '%s'", a.id).CausedBy(err)
+	}
+
+	return &pasetoAuthenticator{
+		id:  a.id,
+		app: a.app,
+		ks:  a.ks,
+		a:   conf.Assertions.Merge(a.a),
+		ttl: x.IfThenElse(conf.CacheTTL != nil, conf.CacheTTL, a.ttl),
+		sf:  a.sf,
+		ads: a.ads,
+	}, nil
+}
+
+func (a *pasetoAuthenticator) ID() string {
+	return a.id
+}
+
+func (a *pasetoAuthenticator) IsInsecure() bool { return false }
+
+func (a *pasetoAuthenticator) verifyToken(ctx heimdall.RequestContext, token *paseto.Token) ([]byte, error) {
+	logger := zerolog.Ctx(ctx.Context())
+
+	// the footer is optional. If present and a JSON object, it may reference the key used to sign the token
+	var footer struct {
+		KeyID string `json:"kid"`
+	}
+
+	_ = json.Unmarshal(token.Footer(), &footer)
+
+	keys, err := a.ks.Keys(ctx.Context(), x.IfThenElseExec(a.ttl != nil,
+		func() time.Duration { return *a.ttl },
+		func() time.Duration { return defaultKeySetTTL }))
+	if err != nil {
+		return nil, errorchain.
+			NewWithMessage(heimdall.ErrInternal, "failed to retrieve keys for PASETO token verification").
+			WithErrorContext(a).
+			CausedBy(err)
+	}
+
+	for _, key := range keys {
+		pubKey, ok := key.Key.(ed25519.PublicKey)
+		if !ok || (len(footer.KeyID) != 0 && key.KeyID != footer.KeyID) {
+			continue
+		}
+
+		payload, err := token.Verify(pubKey, nil)
+		if err == nil {
+			return payload, nil
+		}
+
+		if !errors.Is(err, paseto.ErrInvalidSignature) {
+			logger.Info().Err(err).Str("_key_id", key.KeyID).Msg("Failed to verify PASETO token")
+		}
+	}
+
+	return nil, errorchain.
+		NewWithMessage(heimdall.ErrAuthentication, "none of the available keys could be used to verify the PASETO token").
+		WithErrorContext(a)
+}
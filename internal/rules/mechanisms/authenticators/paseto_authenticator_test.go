@@ -0,0 +1,453 @@
+// Copyright 2025 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package authenticators
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-jose/go-jose/v4"
+	"github.com/rs/zerolog/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dadrus/heimdall/internal/app"
+	"github.com/dadrus/heimdall/internal/config"
+	"github.com/dadrus/heimdall/internal/heimdall"
+	"github.com/dadrus/heimdall/internal/heimdall/mocks"
+	"github.com/dadrus/heimdall/internal/rules/mechanisms/oauth2"
+	"github.com/dadrus/heimdall/internal/rules/mechanisms/subject"
+	"github.com/dadrus/heimdall/internal/validation"
+	mocks2 "github.com/dadrus/heimdall/internal/watcher/mocks"
+	"github.com/dadrus/heimdall/internal/x/paseto"
+	"github.com/dadrus/heimdall/internal/x/testsupport"
+)
+
+func TestCreatePasetoAuthenticator(t *testing.T) {
+	t.Parallel()
+
+	pubKey, _, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	jwksFile := filepath.Join(t.TempDir(), "jwks.json")
+	writeJWKSFile(t, jwksFile, jose.JSONWebKeySet{Keys: []jose.JSONWebKey{{KeyID: "foo", Key: pubKey}}})
+
+	for uc, tc := range map[string]struct {
+		config []byte
+		assert func(t *testing.T, err error, auth *pasetoAuthenticator)
+	}{
+		"without key source": {
+			assert: func(t *testing.T, err error, _ *pasetoAuthenticator) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrConfiguration)
+				require.ErrorContains(t, err, "'jwks_file' is a required field")
+			},
+		},
+		"with both key sources": {
+			config: []byte(`
+jwks_file: ` + jwksFile + `
+jwks_endpoint:
+  url: https://foo.bar
+`),
+			assert: func(t *testing.T, err error, _ *pasetoAuthenticator) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrConfiguration)
+				require.ErrorContains(t, err, "'jwks_file' is an excluded field")
+			},
+		},
+		"with unsupported attributes": {
+			config: []byte(`
+jwks_file: ` + jwksFile + `
+foo: bar
+`),
+			assert: func(t *testing.T, err error, _ *pasetoAuthenticator) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrConfiguration)
+				require.ErrorContains(t, err, "failed decoding")
+			},
+		},
+		"with not existing jwks file": {
+			config: []byte(`jwks_file: /no/such/file`),
+			assert: func(t *testing.T, err error, _ *pasetoAuthenticator) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrConfiguration)
+				require.ErrorContains(t, err, "failed to create key set")
+			},
+		},
+		"with minimal jwks file based configuration": {
+			config: []byte(`jwks_file: ` + jwksFile),
+			assert: func(t *testing.T, err error, auth *pasetoAuthenticator) {
+				t.Helper()
+
+				require.NoError(t, err)
+				assert.Equal(t, "with minimal jwks file based configuration", auth.ID())
+				assert.False(t, auth.IsInsecure())
+				assert.IsType(t, &fileKeySet{}, auth.ks)
+				assert.Equal(t, oauth2.NoopMatcher{}, auth.a.ScopesMatcher)
+				assert.Nil(t, auth.ttl)
+				assert.Equal(t, &SubjectInfo{IDFrom: "sub"}, auth.sf)
+				assert.Len(t, auth.ads, 1)
+			},
+		},
+		"with full jwks endpoint based configuration": {
+			config: []byte(`
+jwks_endpoint:
+  url: http://foo.bar
+assertions:
+  issuers:
+    - foobar
+  audience:
+    - baz
+  scopes:
+    - foo
+subject:
+  id: some_claim
+  attributes: some_other_claim
+token_source:
+  - cookie: foo
+cache_ttl: 5s
+`),
+			assert: func(t *testing.T, err error, auth *pasetoAuthenticator) {
+				t.Helper()
+
+				require.NoError(t, err)
+
+				eks, ok := auth.ks.(*endpointKeySet)
+				require.True(t, ok)
+				assert.Equal(t, "http://foo.bar", eks.ep.URL)
+				assert.Equal(t, "GET", eks.ep.Method)
+				assert.Equal(t, "application/json", eks.ep.Headers["Accept"])
+				assert.Equal(t, []string{"foobar"}, auth.a.TrustedIssuers)
+				assert.Equal(t, []string{"baz"}, auth.a.Audiences)
+				assert.Equal(t, oauth2.ExactScopeStrategyMatcher{"foo"}, auth.a.ScopesMatcher)
+				assert.Equal(t, 5*time.Second, *auth.ttl)
+				assert.Equal(t, &SubjectInfo{IDFrom: "some_claim", AttributesFrom: "some_other_claim"}, auth.sf)
+				assert.Len(t, auth.ads, 1)
+			},
+		},
+	} {
+		t.Run(uc, func(t *testing.T) {
+			// GIVEN
+			conf, err := testsupport.DecodeTestConfig(tc.config)
+			require.NoError(t, err)
+
+			validator, err := validation.NewValidator(
+				validation.WithTagValidator(config.EnforcementSettings{}),
+			)
+			require.NoError(t, err)
+
+			wm := mocks2.NewWatcherMock(t)
+			wm.EXPECT().Add(jwksFile, mock.Anything).Maybe().Return(nil)
+
+			appCtx := app.NewContextMock(t)
+			appCtx.EXPECT().Validator().Maybe().Return(validator)
+			appCtx.EXPECT().Logger().Return(log.Logger)
+			appCtx.EXPECT().Watcher().Maybe().Return(wm)
+
+			// WHEN
+			auth, err := newPasetoAuthenticator(appCtx, uc, conf)
+
+			// THEN
+			tc.assert(t, err, auth)
+		})
+	}
+}
+
+func TestCreatePasetoAuthenticatorFromPrototype(t *testing.T) {
+	t.Parallel()
+
+	pubKey, _, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	jwksFile := filepath.Join(t.TempDir(), "jwks.json")
+	writeJWKSFile(t, jwksFile, jose.JSONWebKeySet{Keys: []jose.JSONWebKey{{KeyID: "foo", Key: pubKey}}})
+
+	for uc, tc := range map[string]struct {
+		stepConfig []byte
+		assert     func(t *testing.T, err error, prototype, configured *pasetoAuthenticator)
+	}{
+		"without step config": {
+			assert: func(t *testing.T, err error, prototype, configured *pasetoAuthenticator) {
+				t.Helper()
+
+				require.NoError(t, err)
+				assert.Equal(t, prototype, configured)
+			},
+		},
+		"with unsupported attributes": {
+			stepConfig: []byte(`jwks_file: /foo/bar`),
+			assert: func(t *testing.T, err error, _, _ *pasetoAuthenticator) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrConfiguration)
+				require.ErrorContains(t, err, "failed decoding")
+			},
+		},
+		"with overridden assertions and cache ttl": {
+			stepConfig: []byte(`
+assertions:
+  audience:
+    - bar
+cache_ttl: 1s
+`),
+			assert: func(t *testing.T, err error, prototype, configured *pasetoAuthenticator) {
+				t.Helper()
+
+				require.NoError(t, err)
+				assert.NotEqual(t, prototype, configured)
+				assert.Equal(t, prototype.id, configured.ID())
+				assert.Equal(t, prototype.ks, configured.ks)
+				assert.Equal(t, prototype.sf, configured.sf)
+				assert.Equal(t, prototype.ads, configured.ads)
+				assert.Equal(t, []string{"foo"}, configured.a.TrustedIssuers)
+				assert.Equal(t, []string{"bar"}, configured.a.Audiences)
+				assert.Equal(t, time.Second, *configured.ttl)
+			},
+		},
+	} {
+		t.Run(uc, func(t *testing.T) {
+			// GIVEN
+			pc, err := testsupport.DecodeTestConfig([]byte(`
+jwks_file: ` + jwksFile + `
+assertions:
+  issuers:
+    - foo
+`))
+			require.NoError(t, err)
+
+			sc, err := testsupport.DecodeTestConfig(tc.stepConfig)
+			require.NoError(t, err)
+
+			validator, err := validation.NewValidator()
+			require.NoError(t, err)
+
+			wm := mocks2.NewWatcherMock(t)
+			wm.EXPECT().Add(jwksFile, mock.Anything).Return(nil)
+
+			appCtx := app.NewContextMock(t)
+			appCtx.EXPECT().Validator().Maybe().Return(validator)
+			appCtx.EXPECT().Logger().Return(log.Logger)
+			appCtx.EXPECT().Watcher().Return(wm)
+
+			prototype, err := newPasetoAuthenticator(appCtx, uc, pc)
+			require.NoError(t, err)
+
+			// WHEN
+			auth, err := prototype.WithConfig(sc)
+
+			// THEN
+			var configured *pasetoAuthenticator
+			if err == nil {
+				configured = auth.(*pasetoAuthenticator) // nolint: forcetypeassert
+			}
+
+			tc.assert(t, err, prototype, configured)
+		})
+	}
+}
+
+func TestPasetoAuthenticatorExecute(t *testing.T) {
+	t.Parallel()
+
+	pubKey, privKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	otherPubKey, otherPrivKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	jwksFile := filepath.Join(t.TempDir(), "jwks.json")
+	writeJWKSFile(t, jwksFile, jose.JSONWebKeySet{Keys: []jose.JSONWebKey{
+		{KeyID: "sym", Key: []byte("secret")},
+		{KeyID: "foo", Key: pubKey},
+		{KeyID: "bar", Key: otherPubKey},
+	}})
+
+	conf, err := testsupport.DecodeTestConfig([]byte(`
+jwks_file: ` + jwksFile + `
+assertions:
+  issuers:
+    - foobar
+  audience:
+    - baz
+`))
+	require.NoError(t, err)
+
+	validator, err := validation.NewValidator()
+	require.NoError(t, err)
+
+	wm := mocks2.NewWatcherMock(t)
+	wm.EXPECT().Add(jwksFile, mock.Anything).Return(nil)
+
+	appCtx := app.NewContextMock(t)
+	appCtx.EXPECT().Validator().Return(validator)
+	appCtx.EXPECT().Logger().Return(log.Logger)
+	appCtx.EXPECT().Watcher().Return(wm)
+
+	auth, err := newPasetoAuthenticator(appCtx, "paseto", conf)
+	require.NoError(t, err)
+
+	now := time.Now().UTC()
+	validClaims := `{"sub":"foo","iss":"foobar","aud":"baz","exp":"` + now.Add(time.Hour).Format(time.RFC3339) +
+		`","iat":"` + now.Format(time.RFC3339) + `","role":"admin"}`
+
+	for uc, tc := range map[string]struct {
+		authorization string
+		assert        func(t *testing.T, err error, sub *subject.Subject)
+	}{
+		"no token present": {
+			assert: func(t *testing.T, err error, _ *subject.Subject) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrAuthentication)
+				require.ErrorContains(t, err, "no PASETO token present")
+
+				var identifier interface{ ID() string }
+				require.ErrorAs(t, err, &identifier)
+				assert.Equal(t, "paseto", identifier.ID())
+			},
+		},
+		"unsupported token": {
+			authorization: "Bearer v4.local.foo",
+			assert: func(t *testing.T, err error, _ *subject.Subject) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrAuthentication)
+				require.ErrorIs(t, err, heimdall.ErrArgument)
+				require.ErrorIs(t, err, paseto.ErrUnsupportedToken)
+			},
+		},
+		"token signed with unknown key": {
+			authorization: func() string {
+				_, unknownKey, err := ed25519.GenerateKey(rand.Reader)
+				require.NoError(t, err)
+
+				return "Bearer " + paseto.Sign(unknownKey, []byte(validClaims), nil, nil)
+			}(),
+			assert: func(t *testing.T, err error, _ *subject.Subject) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrAuthentication)
+				require.ErrorContains(t, err, "none of the available keys")
+			},
+		},
+		"token referencing other key in footer": {
+			authorization: "Bearer " + paseto.Sign(privKey, []byte(validClaims), []byte(`{"kid":"bar"}`), nil),
+			assert: func(t *testing.T, err error, _ *subject.Subject) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrAuthentication)
+				require.ErrorContains(t, err, "none of the available keys")
+			},
+		},
+		"token with malformed claims": {
+			authorization: "Bearer " + paseto.Sign(privKey, []byte(`{"exp":"yesterday"}`), nil, nil),
+			assert: func(t *testing.T, err error, _ *subject.Subject) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrAuthentication)
+				require.ErrorContains(t, err, "failed to deserialize")
+			},
+		},
+		"expired token": {
+			authorization: "Bearer " + paseto.Sign(privKey, []byte(`{"sub":"foo","iss":"foobar","aud":"baz","exp":"`+
+				now.Add(-time.Hour).Format(time.RFC3339)+`"}`), nil, nil),
+			assert: func(t *testing.T, err error, _ *subject.Subject) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrAuthentication)
+				require.ErrorIs(t, err, oauth2.ErrAssertion)
+				require.ErrorContains(t, err, "expired")
+			},
+		},
+		"token from untrusted issuer": {
+			authorization: "Bearer " + paseto.Sign(privKey, []byte(`{"sub":"foo","iss":"barfoo","aud":"baz"}`), nil, nil),
+			assert: func(t *testing.T, err error, _ *subject.Subject) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrAuthentication)
+				require.ErrorIs(t, err, oauth2.ErrAssertion)
+				require.ErrorContains(t, err, "issuer barfoo is not trusted")
+			},
+		},
+		"token without subject claim": {
+			authorization: "Bearer " + paseto.Sign(privKey, []byte(`{"iss":"foobar","aud":"baz"}`), nil, nil),
+			assert: func(t *testing.T, err error, _ *subject.Subject) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrInternal)
+				require.ErrorContains(t, err, "failed to extract subject")
+			},
+		},
+		"valid token without key reference": {
+			authorization: "Bearer " + paseto.Sign(otherPrivKey, []byte(validClaims), nil, nil),
+			assert: func(t *testing.T, err error, sub *subject.Subject) {
+				t.Helper()
+
+				require.NoError(t, err)
+				assert.Equal(t, "foo", sub.ID)
+				assert.Equal(t, "admin", sub.Attributes["role"])
+			},
+		},
+		"valid token referencing key in footer": {
+			authorization: "Bearer " + paseto.Sign(privKey, []byte(validClaims), []byte(`{"kid":"foo"}`), nil),
+			assert: func(t *testing.T, err error, sub *subject.Subject) {
+				t.Helper()
+
+				require.NoError(t, err)
+				assert.Equal(t, "foo", sub.ID)
+				assert.Equal(t, "foobar", sub.Attributes["iss"])
+			},
+		},
+	} {
+		t.Run(uc, func(t *testing.T) {
+			// GIVEN
+			fnt := mocks.NewRequestFunctionsMock(t)
+			fnt.EXPECT().Header("Authorization").Return(tc.authorization)
+
+			ctx := mocks.NewRequestContextMock(t)
+			ctx.EXPECT().Context().Return(t.Context())
+			ctx.EXPECT().Request().Return(&heimdall.Request{RequestFunctions: fnt})
+
+			// WHEN
+			sub, err := auth.Execute(ctx)
+
+			// THEN
+			tc.assert(t, err, sub)
+		})
+	}
+}
@@ -0,0 +1,392 @@
+// Copyright 2025 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package authenticators
+
+import (
+	"bytes"
+	"crypto/sha1" //nolint:gosec
+	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"hash"
+	"strconv"
+	"strings"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+
+	"github.com/dadrus/heimdall/internal/x/stringx"
+)
+
+var (
+	ErrUnsupportedPasswordHash = errors.New("unsupported password hash")
+	ErrMalformedPasswordHash   = errors.New("malformed password hash")
+)
+
+// passwordVerifier verifies a plain text password against a password hash
+// loaded from e.g. an htpasswd file.
+type passwordVerifier interface {
+	verify(password string) bool
+}
+
+func newPasswordVerifier(encoded string) (passwordVerifier, error) {
+	switch {
+	case strings.HasPrefix(encoded, "$2a$"),
+		strings.HasPrefix(encoded, "$2b$"),
+		strings.HasPrefix(encoded, "$2y$"):
+		return newBcryptVerifier(encoded)
+	case strings.HasPrefix(encoded, "$argon2id$"),
+		strings.HasPrefix(encoded, "$argon2i$"):
+		return newArgon2Verifier(encoded)
+	case strings.HasPrefix(encoded, "$5$"):
+		return newSHACryptVerifier(encoded, sha256.New, sha256CryptPermutation)
+	case strings.HasPrefix(encoded, "$6$"):
+		return newSHACryptVerifier(encoded, sha512.New, sha512CryptPermutation)
+	case strings.HasPrefix(encoded, "{SHA}"):
+		return newSHA1Verifier(strings.TrimPrefix(encoded, "{SHA}"), false)
+	case strings.HasPrefix(encoded, "{SSHA}"):
+		return newSHA1Verifier(strings.TrimPrefix(encoded, "{SSHA}"), true)
+	default:
+		return nil, ErrUnsupportedPasswordHash
+	}
+}
+
+// passwordHashScheme returns the identifier of the scheme, the given password hash
+// has been created with, like 2y for bcrypt, or SSHA for salted SHA-1.
+func passwordHashScheme(encoded string) string {
+	if strings.HasPrefix(encoded, "{") {
+		scheme, _, _ := strings.Cut(encoded[1:], "}")
+
+		return scheme
+	}
+
+	scheme, _, _ := strings.Cut(strings.TrimPrefix(encoded, "$"), "$")
+
+	return scheme
+}
+
+type bcryptVerifier struct {
+	hash []byte
+}
+
+func newBcryptVerifier(encoded string) (*bcryptVerifier, error) {
+	hash := []byte(encoded)
+
+	if _, err := bcrypt.Cost(hash); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrMalformedPasswordHash, err)
+	}
+
+	return &bcryptVerifier{hash: hash}, nil
+}
+
+func (v *bcryptVerifier) verify(password string) bool {
+	return bcrypt.CompareHashAndPassword(v.hash, stringx.ToBytes(password)) == nil
+}
+
+// argon2Verifier supports hashes encoded in the PHC string format, like
+// $argon2id$v=19$m=65536,t=3,p=4$<salt>$<hash>.
+type argon2Verifier struct {
+	variant string
+	memory  uint32
+	time    uint32
+	threads uint8
+	salt    []byte
+	key     []byte
+}
+
+func newArgon2Verifier(encoded string) (*argon2Verifier, error) {
+	const phcParts = 6
+
+	parts := strings.Split(encoded, "$")
+	if len(parts) != phcParts {
+		return nil, ErrMalformedPasswordHash
+	}
+
+	var (
+		version int
+		params  argon2Verifier
+	)
+
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil || version != argon2.Version {
+		return nil, fmt.Errorf("%w: unsupported argon2 version", ErrMalformedPasswordHash)
+	}
+
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &params.memory, &params.time, &params.threads); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrMalformedPasswordHash, err)
+	}
+
+	salt, err := base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrMalformedPasswordHash, err)
+	}
+
+	key, err := base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil || len(key) == 0 {
+		return nil, fmt.Errorf("%w: invalid argon2 key", ErrMalformedPasswordHash)
+	}
+
+	params.variant = parts[1]
+	params.salt = salt
+	params.key = key
+
+	return &params, nil
+}
+
+func (v *argon2Verifier) verify(password string) bool {
+	var key []byte
+
+	//nolint:gosec
+	// no integer overflow during conversion possible, as the length of the key is limited
+	keyLen := uint32(len(v.key))
+
+	if v.variant == "argon2id" {
+		key = argon2.IDKey(stringx.ToBytes(password), v.salt, v.time, v.memory, v.threads, keyLen)
+	} else {
+		key = argon2.Key(stringx.ToBytes(password), v.salt, v.time, v.memory, v.threads, keyLen)
+	}
+
+	return subtle.ConstantTimeCompare(key, v.key) == 1
+}
+
+// sha1Verifier supports the {SHA} scheme as used by apache htpasswd and its salted
+// {SSHA} variant known from LDAP.
+type sha1Verifier struct {
+	digest []byte
+	salt   []byte
+}
+
+func newSHA1Verifier(encoded string, salted bool) (*sha1Verifier, error) {
+	data, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrMalformedPasswordHash, err)
+	}
+
+	if len(data) < sha1.Size || (!salted && len(data) != sha1.Size) {
+		return nil, fmt.Errorf("%w: invalid digest length", ErrMalformedPasswordHash)
+	}
+
+	return &sha1Verifier{digest: data[:sha1.Size], salt: data[sha1.Size:]}, nil
+}
+
+func (v *sha1Verifier) verify(password string) bool {
+	md := sha1.New() //nolint:gosec
+	md.Write(stringx.ToBytes(password))
+	md.Write(v.salt)
+
+	return subtle.ConstantTimeCompare(md.Sum(nil), v.digest) == 1
+}
+
+const (
+	shaCryptDefaultRounds = 5000
+	shaCryptMinRounds     = 1000
+	shaCryptMaxRounds     = 999999999
+	shaCryptMaxSaltLen    = 16
+	shaCryptRoundsPrefix  = "rounds="
+	shaCryptAlphabet      = "./0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz"
+)
+
+// byte triples used to encode the final digest as defined by the SHA-crypt specification.
+var (
+	sha256CryptPermutation = [][3]int{ //nolint:gochecknoglobals
+		{0, 10, 20}, {21, 1, 11}, {12, 22, 2}, {3, 13, 23}, {24, 4, 14},
+		{15, 25, 5}, {6, 16, 26}, {27, 7, 17}, {18, 28, 8}, {9, 19, 29},
+	}
+	sha512CryptPermutation = [][3]int{ //nolint:gochecknoglobals
+		{0, 21, 42}, {22, 43, 1}, {44, 2, 23}, {3, 24, 45}, {25, 46, 4},
+		{47, 5, 26}, {6, 27, 48}, {28, 49, 7}, {50, 8, 29}, {9, 30, 51},
+		{31, 52, 10}, {53, 11, 32}, {12, 33, 54}, {34, 55, 13}, {56, 14, 35},
+		{15, 36, 57}, {37, 58, 16}, {59, 17, 38}, {18, 39, 60}, {40, 61, 19},
+		{62, 20, 41},
+	}
+)
+
+// shaCryptVerifier supports the SHA-256 ($5$) and SHA-512 ($6$) based crypt schemes
+// as specified in https://www.akkadia.org/drepper/SHA-crypt.txt.
+type shaCryptVerifier struct {
+	encoded     string
+	prefix      string
+	salt        []byte
+	rounds      int
+	roundsSet   bool
+	newHash     func() hash.Hash
+	permutation [][3]int
+}
+
+func newSHACryptVerifier(encoded string, newHash func() hash.Hash, permutation [][3]int) (*shaCryptVerifier, error) {
+	const prefixLen = 3
+
+	verifier := &shaCryptVerifier{
+		encoded:     encoded,
+		prefix:      encoded[:prefixLen],
+		rounds:      shaCryptDefaultRounds,
+		newHash:     newHash,
+		permutation: permutation,
+	}
+
+	rest := encoded[prefixLen:]
+	if strings.HasPrefix(rest, shaCryptRoundsPrefix) {
+		value, remaining, found := strings.Cut(strings.TrimPrefix(rest, shaCryptRoundsPrefix), "$")
+		if !found {
+			return nil, ErrMalformedPasswordHash
+		}
+
+		rounds, err := strconv.Atoi(value)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrMalformedPasswordHash, err)
+		}
+
+		verifier.rounds = min(max(rounds, shaCryptMinRounds), shaCryptMaxRounds)
+		verifier.roundsSet = true
+		rest = remaining
+	}
+
+	salt, _, found := strings.Cut(rest, "$")
+	if !found {
+		return nil, ErrMalformedPasswordHash
+	}
+
+	verifier.salt = []byte(salt[:min(len(salt), shaCryptMaxSaltLen)])
+
+	return verifier, nil
+}
+
+func (v *shaCryptVerifier) verify(password string) bool {
+	return subtle.ConstantTimeCompare(
+		stringx.ToBytes(v.crypt(stringx.ToBytes(password))),
+		stringx.ToBytes(v.encoded),
+	) == 1
+}
+
+//nolint:cyclop
+func (v *shaCryptVerifier) crypt(password []byte) string {
+	// digest B
+	md := v.newHash()
+	md.Write(password)
+	md.Write(v.salt)
+	md.Write(password)
+	digestB := md.Sum(nil)
+
+	// digest A
+	md.Reset()
+	md.Write(password)
+	md.Write(v.salt)
+	md.Write(repeatToLength(digestB, len(password)))
+
+	for length := len(password); length > 0; length >>= 1 {
+		if length&1 != 0 {
+			md.Write(digestB)
+		} else {
+			md.Write(password)
+		}
+	}
+
+	digestA := md.Sum(nil)
+
+	// byte sequence P
+	md.Reset()
+
+	for range password {
+		md.Write(password)
+	}
+
+	seqP := repeatToLength(md.Sum(nil), len(password))
+
+	// byte sequence S
+	md.Reset()
+
+	for range 16 + int(digestA[0]) {
+		md.Write(v.salt)
+	}
+
+	seqS := repeatToLength(md.Sum(nil), len(v.salt))
+
+	digestC := digestA
+	for round := range v.rounds {
+		md.Reset()
+
+		if round%2 != 0 {
+			md.Write(seqP)
+		} else {
+			md.Write(digestC)
+		}
+
+		if round%3 != 0 {
+			md.Write(seqS)
+		}
+
+		if round%7 != 0 {
+			md.Write(seqP)
+		}
+
+		if round%2 != 0 {
+			md.Write(digestC)
+		} else {
+			md.Write(seqP)
+		}
+
+		digestC = md.Sum(nil)
+	}
+
+	var buf bytes.Buffer
+
+	buf.WriteString(v.prefix)
+
+	if v.roundsSet {
+		buf.WriteString(shaCryptRoundsPrefix)
+		buf.WriteString(strconv.Itoa(v.rounds))
+		buf.WriteByte('$')
+	}
+
+	buf.Write(v.salt)
+	buf.WriteByte('$')
+
+	for _, triple := range v.permutation {
+		encode24Bit(&buf, digestC[triple[0]], digestC[triple[1]], digestC[triple[2]], 4) //nolint:mnd
+	}
+
+	if len(digestC) == sha256.Size {
+		encode24Bit(&buf, 0, digestC[31], digestC[30], 3) //nolint:mnd
+	} else {
+		encode24Bit(&buf, 0, 0, digestC[63], 2) //nolint:mnd
+	}
+
+	return buf.String()
+}
+
+func repeatToLength(data []byte, length int) []byte {
+	result := make([]byte, 0, length)
+
+	for len(result) < length {
+		result = append(result, data[:min(len(data), length-len(result))]...)
+	}
+
+	return result
+}
+
+func encode24Bit(buf *bytes.Buffer, b2, b1, b0 byte, count int) {
+	const sixBitMask = 0x3f
+
+	value := uint(b2)<<16 | uint(b1)<<8 | uint(b0)
+
+	for range count {
+		buf.WriteByte(shaCryptAlphabet[value&sixBitMask])
+		value >>= 6
+	}
+}
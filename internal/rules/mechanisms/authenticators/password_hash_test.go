@@ -0,0 +1,109 @@
+// Copyright 2025 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package authenticators
+
+import (
+	"crypto/sha1" //nolint:gosec
+	"encoding/base64"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func argon2idHash(password string) string {
+	salt := []byte("somesaltvalue")
+	key := argon2.IDKey([]byte(password), salt, 1, 64, 1, 32)
+
+	return fmt.Sprintf("$argon2id$v=%d$m=64,t=1,p=1$%s$%s", argon2.Version,
+		base64.RawStdEncoding.EncodeToString(salt), base64.RawStdEncoding.EncodeToString(key))
+}
+
+func TestPasswordVerifier(t *testing.T) {
+	t.Parallel()
+
+	bcryptHash, err := bcrypt.GenerateFromPassword([]byte("Hello world!"), bcrypt.MinCost)
+	require.NoError(t, err)
+
+	sha1Digest := sha1.Sum([]byte("Hello world!"))      //nolint:gosec
+	ssha1Digest := sha1.Sum([]byte("Hello world!salt")) //nolint:gosec
+
+	argon2iKey := argon2.Key([]byte("Hello world!"), []byte("somesaltvalue"), 1, 64, 1, 16)
+	argon2iHash := fmt.Sprintf("$argon2i$v=19$m=64,t=1,p=1$%s$%s",
+		base64.RawStdEncoding.EncodeToString([]byte("somesaltvalue")),
+		base64.RawStdEncoding.EncodeToString(argon2iKey))
+
+	for uc, tc := range map[string]struct {
+		hash string
+		err  error
+	}{
+		"bcrypt":                      {hash: string(bcryptHash)},
+		"argon2id":                    {hash: argon2idHash("Hello world!")},
+		"argon2i":                     {hash: argon2iHash},
+		"sha256 crypt":                {hash: "$5$saltstring$5B8vYYiY.CVt1RlTTf8KbXBH3hsxY/GNooZaBBGWEc5"},
+		"sha512 crypt":                {hash: "$6$saltstring$svn8UoSVapNtMuq1ukKS4tPQd8iKwSMHWjl/O817G3uBnIFNjnQJuesI68u4OTLiBFdcbYEdFCoEOfaS35inz1"},                    //nolint:lll
+		"sha256 crypt with rounds":    {hash: "$5$rounds=10000$saltstringsaltst$3xv.VbSHBb41AL9AvLeujZkZRBAwqFMz2.opqey6IcA"},                                            //nolint:lll
+		"sha512 crypt with rounds":    {hash: "$6$rounds=10000$saltstringsaltst$OW1/O6BYHV6BcXZu8QVeXbDWra3Oeqh0sbHbbMCVNSnCM/UrjmM0Dp8vOuZeHBy/YTBmSK6H9qs/y3RnOaw5v."}, //nolint:lll
+		"sha1":                        {hash: "{SHA}" + base64.StdEncoding.EncodeToString(sha1Digest[:])},
+		"salted sha1":                 {hash: "{SSHA}" + base64.StdEncoding.EncodeToString(append(ssha1Digest[:], []byte("salt")...))},
+		"md5 is not supported":        {hash: "$apr1$salt$hash", err: ErrUnsupportedPasswordHash},
+		"plain text is not supported": {hash: "Hello world!", err: ErrUnsupportedPasswordHash},
+		"malformed bcrypt":            {hash: "$2y$10$foo", err: ErrMalformedPasswordHash},
+		"malformed argon2":            {hash: "$argon2id$v=19$m=64,t=1,p=1$foo", err: ErrMalformedPasswordHash},
+		"unsupported argon2 version":  {hash: "$argon2id$v=16$m=64,t=1,p=1$Zm9v$YmFy", err: ErrMalformedPasswordHash},
+		"malformed sha crypt":         {hash: "$5$rounds=foo$salt$hash", err: ErrMalformedPasswordHash},
+		"malformed sha1":              {hash: "{SHA}Zm9v", err: ErrMalformedPasswordHash},
+	} {
+		t.Run(uc, func(t *testing.T) {
+			// WHEN
+			verifier, err := newPasswordVerifier(tc.hash)
+
+			// THEN
+			if tc.err != nil {
+				require.ErrorIs(t, err, tc.err)
+
+				return
+			}
+
+			require.NoError(t, err)
+			assert.True(t, verifier.verify("Hello world!"))
+			assert.False(t, verifier.verify("Hello world"))
+			assert.False(t, verifier.verify(""))
+		})
+	}
+}
+
+func TestPasswordHashScheme(t *testing.T) {
+	t.Parallel()
+
+	for hash, scheme := range map[string]string{
+		"$2y$10$foo":                      "2y",
+		"$argon2id$v=19$m=64,t=1,p=1$foo": "argon2id",
+		"$5$rounds=10000$saltstring$hash": "5",
+		"$6$saltstring$hash":              "6",
+		"{SHA}Zm9v":                       "SHA",
+		"{SSHA}Zm9v":                      "SSHA",
+		"Hello world!":                    "Hello world!",
+	} {
+		t.Run(hash, func(t *testing.T) {
+			assert.Equal(t, scheme, passwordHashScheme(hash))
+		})
+	}
+}
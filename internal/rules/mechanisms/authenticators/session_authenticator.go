@@ -0,0 +1,120 @@
+// Copyright 2025 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package authenticators
+
+import (
+	"github.com/rs/zerolog"
+
+	"github.com/dadrus/heimdall/internal/app"
+	"github.com/dadrus/heimdall/internal/heimdall"
+	"github.com/dadrus/heimdall/internal/rules/mechanisms/subject"
+	"github.com/dadrus/heimdall/internal/session"
+	"github.com/dadrus/heimdall/internal/x"
+	"github.com/dadrus/heimdall/internal/x/errorchain"
+)
+
+const defaultSessionCookieName = "heimdall_sid"
+
+// by intention. Used only during application bootstrap.
+func init() { // nolint: gochecknoinits
+	registerTypeFactory(
+		func(app app.Context, id string, typ string, conf map[string]any) (bool, Authenticator, error) {
+			if typ != AuthenticatorSession {
+				return false, nil, nil
+			}
+
+			auth, err := newSessionAuthenticator(app, id, conf)
+
+			return true, auth, err
+		})
+}
+
+type sessionAuthenticator struct {
+	id         string
+	app        app.Context
+	cookieName string
+	sm         session.Manager
+}
+
+func newSessionAuthenticator(
+	app app.Context,
+	id string,
+	rawConfig map[string]any,
+) (*sessionAuthenticator, error) {
+	logger := app.Logger()
+	logger.Info().Str("_id", id).Msg("Creating session authenticator")
+
+	type Config struct {
+		CookieName string `mapstructure:"cookie_name"`
+	}
+
+	var conf Config
+	if err := decodeConfig(app, rawConfig, &conf); err != nil {
+		return nil, errorchain.NewWithMessagef(heimdall.ErrConfiguration,
+			"failed decoding config for session authenticator '%s'", id).CausedBy(err)
+	}
+
+	sm := app.SessionManager()
+	if sm == nil {
+		return nil, errorchain.NewWithMessagef(heimdall.ErrConfiguration,
+			"session authenticator '%s' requires session management to be configured", id)
+	}
+
+	return &sessionAuthenticator{
+		id:         id,
+		app:        app,
+		cookieName: x.IfThenElse(len(conf.CookieName) != 0, conf.CookieName, defaultSessionCookieName),
+		sm:         sm,
+	}, nil
+}
+
+func (a *sessionAuthenticator) Execute(ctx heimdall.RequestContext) (*subject.Subject, error) {
+	logger := zerolog.Ctx(ctx.Context())
+	logger.Debug().Str("_id", a.id).Msg("Authenticating using session authenticator")
+
+	sid := ctx.Request().Cookie(a.cookieName)
+	if len(sid) == 0 {
+		return nil, errorchain.NewWithMessage(heimdall.ErrAuthentication, "no session cookie present").
+			WithErrorContext(a)
+	}
+
+	sess, err := a.sm.Load(ctx.Context(), sid)
+	if err != nil {
+		return nil, errorchain.NewWithMessage(heimdall.ErrAuthentication, "no valid session present").
+			WithErrorContext(a).
+			CausedBy(err)
+	}
+
+	// makes the refresh material available to the subsequent mechanisms
+	if len(sess.RefreshMaterial) != 0 {
+		ctx.Outputs()[a.id] = sess.RefreshMaterial
+	}
+
+	return sess.Subject, nil
+}
+
+func (a *sessionAuthenticator) WithConfig(config map[string]any) (Authenticator, error) {
+	if len(config) == 0 {
+		return a, nil
+	}
+
+	return newSessionAuthenticator(a.app, a.id, config)
+}
+
+func (a *sessionAuthenticator) ID() string { return a.id }
+
+func (a *sessionAuthenticator) IsInsecure() bool { return false }
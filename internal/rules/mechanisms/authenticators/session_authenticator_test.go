@@ -0,0 +1,269 @@
+// Copyright 2025 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package authenticators
+
+import (
+	"testing"
+
+	"github.com/rs/zerolog/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dadrus/heimdall/internal/app"
+	"github.com/dadrus/heimdall/internal/heimdall"
+	"github.com/dadrus/heimdall/internal/heimdall/mocks"
+	"github.com/dadrus/heimdall/internal/rules/mechanisms/subject"
+	"github.com/dadrus/heimdall/internal/session"
+	sessionmocks "github.com/dadrus/heimdall/internal/session/mocks"
+	"github.com/dadrus/heimdall/internal/validation"
+	"github.com/dadrus/heimdall/internal/x/testsupport"
+)
+
+func TestCreateSessionAuthenticator(t *testing.T) {
+	t.Parallel()
+
+	for uc, tc := range map[string]struct {
+		config       []byte
+		withoutStore bool
+		assert       func(t *testing.T, err error, auth *sessionAuthenticator)
+	}{
+		"without session management": {
+			withoutStore: true,
+			assert: func(t *testing.T, err error, _ *sessionAuthenticator) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrConfiguration)
+				assert.Contains(t, err.Error(), "requires session management")
+			},
+		},
+		"unsupported attributes": {
+			config: []byte("foo: bar"),
+			assert: func(t *testing.T, err error, _ *sessionAuthenticator) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrConfiguration)
+				assert.Contains(t, err.Error(), "failed decoding")
+			},
+		},
+		"default cookie name": {
+			assert: func(t *testing.T, err error, auth *sessionAuthenticator) {
+				t.Helper()
+
+				require.NoError(t, err)
+				assert.Equal(t, "heimdall_sid", auth.cookieName)
+				assert.Equal(t, "default cookie name", auth.ID())
+				assert.NotNil(t, auth.sm)
+				assert.False(t, auth.IsInsecure())
+			},
+		},
+		"custom cookie name": {
+			config: []byte("cookie_name: foo"),
+			assert: func(t *testing.T, err error, auth *sessionAuthenticator) {
+				t.Helper()
+
+				require.NoError(t, err)
+				assert.Equal(t, "foo", auth.cookieName)
+			},
+		},
+	} {
+		t.Run(uc, func(t *testing.T) {
+			// GIVEN
+			conf, err := testsupport.DecodeTestConfig(tc.config)
+			require.NoError(t, err)
+
+			validator, err := validation.NewValidator()
+			require.NoError(t, err)
+
+			appCtx := app.NewContextMock(t)
+			appCtx.EXPECT().Validator().Maybe().Return(validator)
+			appCtx.EXPECT().Logger().Return(log.Logger)
+
+			if tc.withoutStore {
+				appCtx.EXPECT().SessionManager().Return(nil)
+			} else {
+				appCtx.EXPECT().SessionManager().Maybe().Return(sessionmocks.NewManagerMock(t))
+			}
+
+			// WHEN
+			auth, err := newSessionAuthenticator(appCtx, uc, conf)
+
+			// THEN
+			tc.assert(t, err, auth)
+		})
+	}
+}
+
+func TestCreateSessionAuthenticatorFromPrototype(t *testing.T) {
+	t.Parallel()
+
+	for uc, tc := range map[string]struct {
+		config []byte
+		assert func(t *testing.T, err error, prototype *sessionAuthenticator, configured *sessionAuthenticator)
+	}{
+		"no new configuration for the configured authenticator": {
+			assert: func(t *testing.T, err error, prototype *sessionAuthenticator, configured *sessionAuthenticator) {
+				t.Helper()
+
+				require.NoError(t, err)
+				assert.Equal(t, prototype, configured)
+			},
+		},
+		"new cookie name for the configured authenticator": {
+			config: []byte("cookie_name: foo"),
+			assert: func(t *testing.T, err error, prototype *sessionAuthenticator, configured *sessionAuthenticator) {
+				t.Helper()
+
+				require.NoError(t, err)
+				assert.NotEqual(t, prototype, configured)
+				assert.Equal(t, prototype.ID(), configured.ID())
+				assert.Equal(t, "heimdall_sid", prototype.cookieName)
+				assert.Equal(t, "foo", configured.cookieName)
+			},
+		},
+	} {
+		t.Run(uc, func(t *testing.T) {
+			// GIVEN
+			conf, err := testsupport.DecodeTestConfig(tc.config)
+			require.NoError(t, err)
+
+			validator, err := validation.NewValidator()
+			require.NoError(t, err)
+
+			appCtx := app.NewContextMock(t)
+			appCtx.EXPECT().Validator().Maybe().Return(validator)
+			appCtx.EXPECT().Logger().Return(log.Logger)
+			appCtx.EXPECT().SessionManager().Return(sessionmocks.NewManagerMock(t))
+
+			prototype, err := newSessionAuthenticator(appCtx, uc, nil)
+			require.NoError(t, err)
+
+			// WHEN
+			auth, err := prototype.WithConfig(conf)
+
+			// THEN
+			sa, ok := auth.(*sessionAuthenticator)
+			require.True(t, ok)
+
+			tc.assert(t, err, prototype, sa)
+		})
+	}
+}
+
+func TestSessionAuthenticatorExecute(t *testing.T) {
+	t.Parallel()
+
+	for uc, tc := range map[string]struct {
+		cookie string
+		setup  func(t *testing.T, sm *sessionmocks.ManagerMock)
+		assert func(t *testing.T, err error, sub *subject.Subject, outputs map[string]any)
+	}{
+		"no session cookie": {
+			assert: func(t *testing.T, err error, _ *subject.Subject, _ map[string]any) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrAuthentication)
+				assert.Contains(t, err.Error(), "no session cookie")
+			},
+		},
+		"no valid session": {
+			cookie: "foo",
+			setup: func(t *testing.T, sm *sessionmocks.ManagerMock) {
+				t.Helper()
+
+				sm.EXPECT().Load(mock.Anything, "foo").Return(nil, session.ErrNoSession)
+			},
+			assert: func(t *testing.T, err error, _ *subject.Subject, _ map[string]any) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrAuthentication)
+				require.ErrorIs(t, err, session.ErrNoSession)
+
+				var identifier interface{ ID() string }
+				require.ErrorAs(t, err, &identifier)
+				assert.Equal(t, "sess", identifier.ID())
+			},
+		},
+		"valid session without refresh material": {
+			cookie: "foo",
+			setup: func(t *testing.T, sm *sessionmocks.ManagerMock) {
+				t.Helper()
+
+				sm.EXPECT().Load(mock.Anything, "foo").Return(&session.Session{
+					ID:      "foo",
+					Subject: &subject.Subject{ID: "bar", Attributes: map[string]any{"baz": "zab"}},
+				}, nil)
+			},
+			assert: func(t *testing.T, err error, sub *subject.Subject, outputs map[string]any) {
+				t.Helper()
+
+				require.NoError(t, err)
+				assert.Equal(t, "bar", sub.ID)
+				assert.Equal(t, map[string]any{"baz": "zab"}, sub.Attributes)
+				assert.Empty(t, outputs)
+			},
+		},
+		"valid session with refresh material": {
+			cookie: "foo",
+			setup: func(t *testing.T, sm *sessionmocks.ManagerMock) {
+				t.Helper()
+
+				sm.EXPECT().Load(mock.Anything, "foo").Return(&session.Session{
+					ID:              "foo",
+					Subject:         &subject.Subject{ID: "bar"},
+					RefreshMaterial: map[string]string{"refresh_token": "baz"},
+				}, nil)
+			},
+			assert: func(t *testing.T, err error, sub *subject.Subject, outputs map[string]any) {
+				t.Helper()
+
+				require.NoError(t, err)
+				assert.Equal(t, "bar", sub.ID)
+				assert.Equal(t, map[string]string{"refresh_token": "baz"}, outputs["sess"])
+			},
+		},
+	} {
+		t.Run(uc, func(t *testing.T) {
+			// GIVEN
+			sm := sessionmocks.NewManagerMock(t)
+			if tc.setup != nil {
+				tc.setup(t, sm)
+			}
+
+			auth := &sessionAuthenticator{id: "sess", cookieName: "sid", sm: sm}
+			outputs := map[string]any{}
+
+			fnt := mocks.NewRequestFunctionsMock(t)
+			fnt.EXPECT().Cookie("sid").Return(tc.cookie)
+
+			ctx := mocks.NewRequestContextMock(t)
+			ctx.EXPECT().Context().Return(t.Context())
+			ctx.EXPECT().Request().Return(&heimdall.Request{RequestFunctions: fnt})
+			ctx.EXPECT().Outputs().Maybe().Return(outputs)
+
+			// WHEN
+			sub, err := auth.Execute(ctx)
+
+			// THEN
+			tc.assert(t, err, sub, outputs)
+		})
+	}
+}
@@ -0,0 +1,329 @@
+// Copyright 2025 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package authenticators
+
+import (
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/pem"
+	"net/url"
+	"strings"
+
+	"github.com/goccy/go-json"
+	"github.com/rs/zerolog"
+
+	"github.com/dadrus/heimdall/internal/app"
+	"github.com/dadrus/heimdall/internal/heimdall"
+	"github.com/dadrus/heimdall/internal/rules/mechanisms/subject"
+	"github.com/dadrus/heimdall/internal/truststore"
+	"github.com/dadrus/heimdall/internal/x"
+	"github.com/dadrus/heimdall/internal/x/errorchain"
+	"github.com/dadrus/heimdall/internal/x/pkix"
+)
+
+const (
+	forwardedClientCertHeader = "X-Forwarded-Client-Cert"
+	spiffeScheme              = "spiffe"
+)
+
+// by intention. Used only during application bootstrap
+//
+//nolint:gochecknoinits
+func init() {
+	registerTypeFactory(
+		func(app app.Context, id string, typ string, conf map[string]any) (bool, Authenticator, error) {
+			if typ != AuthenticatorX509 {
+				return false, nil, nil
+			}
+
+			auth, err := newX509Authenticator(app, id, conf)
+
+			return true, auth, err
+		})
+}
+
+type x509Authenticator struct {
+	id                       string
+	app                      app.Context
+	trustStore               truststore.TrustStore
+	allowForwardedClientCert bool
+	sf                       SubjectFactory
+}
+
+func newX509Authenticator(app app.Context, id string, rawConfig map[string]any) (*x509Authenticator, error) {
+	logger := app.Logger()
+	logger.Info().Str("_id", id).Msg("Creating x509 authenticator")
+
+	type Config struct {
+		TrustStore               truststore.TrustStore `mapstructure:"trust_store"                 validate:"required"`
+		AllowForwardedClientCert bool                  `mapstructure:"allow_forwarded_client_cert"`
+		SubjectInfo              SubjectInfo           `mapstructure:"subject"                     validate:"-"`
+	}
+
+	var conf Config
+	if err := decodeConfig(app, rawConfig, &conf); err != nil {
+		return nil, errorchain.NewWithMessagef(heimdall.ErrConfiguration,
+			"failed decoding config for x509 authenticator '%s'", id).CausedBy(err)
+	}
+
+	if len(conf.SubjectInfo.IDFrom) == 0 {
+		conf.SubjectInfo.IDFrom = "subject"
+	}
+
+	if conf.AllowForwardedClientCert {
+		logger.Warn().Str("_id", id).
+			Msg("x509 authenticator accepts client certificates forwarded by proxies. " +
+				"Make sure, heimdall is configured to trust only those proxies, which are expected to forward them.")
+	}
+
+	return &x509Authenticator{
+		id:                       id,
+		app:                      app,
+		trustStore:               conf.TrustStore,
+		allowForwardedClientCert: conf.AllowForwardedClientCert,
+		sf:                       &conf.SubjectInfo,
+	}, nil
+}
+
+func (a *x509Authenticator) Execute(ctx heimdall.RequestContext) (*subject.Subject, error) {
+	logger := zerolog.Ctx(ctx.Context())
+	logger.Debug().Str("_id", a.id).Msg("Authenticating using x509 authenticator")
+
+	certs, err := clientCertificates(ctx, a.allowForwardedClientCert, a)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = pkix.ValidateCertificate(certs[0],
+		pkix.WithIntermediateCACertificates(certs[1:]),
+		pkix.WithRootCACertificates(a.trustStore),
+		pkix.WithExtendedKeyUsage(x509.ExtKeyUsageClientAuth),
+	); err != nil {
+		return nil, errorchain.
+			NewWithMessage(heimdall.ErrAuthentication, "client certificate validation failed").
+			WithErrorContext(a).
+			CausedBy(err)
+	}
+
+	rawData, err := json.Marshal(certificateAttributes(certs[0]))
+	if err != nil {
+		return nil, errorchain.
+			NewWithMessage(heimdall.ErrInternal, "failed to marshal client certificate information").
+			WithErrorContext(a).
+			CausedBy(err)
+	}
+
+	sub, err := a.sf.CreateSubject(rawData)
+	if err != nil {
+		return nil, errorchain.
+			NewWithMessage(heimdall.ErrInternal, "failed to extract subject information from client certificate").
+			WithErrorContext(a).
+			CausedBy(err)
+	}
+
+	return sub, nil
+}
+
+func (a *x509Authenticator) WithConfig(rawConfig map[string]any) (Authenticator, error) {
+	// this authenticator allows subject to be redefined on the rule level
+	if len(rawConfig) == 0 {
+		return a, nil
+	}
+
+	type Config struct {
+		SubjectInfo *SubjectInfo `mapstructure:"subject" validate:"-"`
+	}
+
+	var conf Config
+	if err := decodeConfig(a.app, rawConfig, &conf); err != nil {
+		return nil, errorchain.NewWithMessagef(heimdall.ErrConfiguration,
+			"failed decoding config for x509 authenticator '%s'", a.id).CausedBy(err)
+	}
+
+	if conf.SubjectInfo != nil && len(conf.SubjectInfo.IDFrom) == 0 {
+		conf.SubjectInfo.IDFrom = "subject"
+	}
+
+	return &x509Authenticator{
+		id:                       a.id,
+		app:                      a.app,
+		trustStore:               a.trustStore,
+		allowForwardedClientCert: a.allowForwardedClientCert,
+		sf: x.IfThenElseExec(conf.SubjectInfo != nil,
+			func() SubjectFactory { return conf.SubjectInfo },
+			func() SubjectFactory { return a.sf }),
+	}, nil
+}
+
+func (a *x509Authenticator) IsInsecure() bool { return false }
+
+func (a *x509Authenticator) ID() string { return a.id }
+
+// clientCertificates returns the certificate chain presented by the client, either via the TLS connection
+// (or the peer certificate reported by envoy) or, if allowed, via the X-Forwarded-Client-Cert header.
+func clientCertificates(
+	ctx heimdall.RequestContext,
+	allowForwardedClientCert bool,
+	errCtx any,
+) ([]*x509.Certificate, error) {
+	req := ctx.Request()
+
+	if certs := req.ClientCertificates(); len(certs) != 0 {
+		return certs, nil
+	}
+
+	if allowForwardedClientCert {
+		if value := req.Header(forwardedClientCertHeader); len(value) != 0 {
+			certs, err := parseForwardedClientCert(value)
+			if err != nil {
+				return nil, errorchain.
+					NewWithMessagef(heimdall.ErrAuthentication, "failed to parse %s header", forwardedClientCertHeader).
+					WithErrorContext(errCtx).
+					CausedBy(err)
+			}
+
+			return certs, nil
+		}
+	}
+
+	return nil, errorchain.
+		NewWithMessage(heimdall.ErrAuthentication, "no client certificate present").
+		WithErrorContext(errCtx)
+}
+
+func certificateAttributes(cert *x509.Certificate) map[string]any {
+	fingerprint := sha256.Sum256(cert.Raw)
+
+	ipAddresses := make([]string, len(cert.IPAddresses))
+	for idx, ip := range cert.IPAddresses {
+		ipAddresses[idx] = ip.String()
+	}
+
+	uris := make([]string, len(cert.URIs))
+	for idx, uri := range cert.URIs {
+		uris[idx] = uri.String()
+	}
+
+	attributes := map[string]any{
+		"subject":         cert.Subject.String(),
+		"issuer":          cert.Issuer.String(),
+		"common_name":     cert.Subject.CommonName,
+		"serial_number":   cert.SerialNumber.String(),
+		"fingerprint":     hex.EncodeToString(fingerprint[:]),
+		"dns_names":       x.IfThenElse(cert.DNSNames != nil, cert.DNSNames, []string{}),
+		"email_addresses": x.IfThenElse(cert.EmailAddresses != nil, cert.EmailAddresses, []string{}),
+		"ip_addresses":    ipAddresses,
+		"uris":            uris,
+	}
+
+	// as defined by the SPIFFE X509-SVID specification, there must be exactly one URI SAN
+	// holding the SPIFFE ID
+	for _, uri := range cert.URIs {
+		if uri.Scheme == spiffeScheme {
+			attributes["spiffe_id"] = uri.String()
+
+			break
+		}
+	}
+
+	return attributes
+}
+
+// parseForwardedClientCert extracts the client certificate chain from the X-Forwarded-Client-Cert
+// header as set by e.g. envoy. If the header has multiple elements, the last one is used, as it
+// has been added by the proxy closest to heimdall. The Chain key takes precedence over the Cert key.
+func parseForwardedClientCert(value string) ([]*x509.Certificate, error) {
+	elements := splitQuoted(value, ',')
+
+	var chain, cert string
+
+	for _, pair := range splitQuoted(elements[len(elements)-1], ';') {
+		key, val, found := strings.Cut(strings.TrimSpace(pair), "=")
+		if !found {
+			continue
+		}
+
+		val = strings.ReplaceAll(strings.Trim(val, `"`), `\"`, `"`)
+
+		switch strings.ToLower(key) {
+		case "chain":
+			chain = val
+		case "cert":
+			cert = val
+		}
+	}
+
+	encoded := x.IfThenElse(len(chain) != 0, chain, cert)
+	if len(encoded) == 0 {
+		return nil, errorchain.NewWithMessage(heimdall.ErrArgument, "no certificate present")
+	}
+
+	decoded, err := url.PathUnescape(encoded)
+	if err != nil {
+		return nil, err
+	}
+
+	var certs []*x509.Certificate
+
+	for rest := []byte(decoded); ; {
+		var block *pem.Block
+
+		block, rest = pem.Decode(rest)
+		if block == nil {
+			break
+		}
+
+		parsed, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+
+		certs = append(certs, parsed)
+	}
+
+	if len(certs) == 0 {
+		return nil, errorchain.NewWithMessage(heimdall.ErrArgument, "no PEM encoded certificate present")
+	}
+
+	return certs, nil
+}
+
+func splitQuoted(value string, sep rune) []string {
+	var (
+		parts   []string
+		start   int
+		quoted  bool
+		escaped bool
+	)
+
+	for idx, chr := range value {
+		switch {
+		case escaped:
+			escaped = false
+		case chr == '\\' && quoted:
+			escaped = true
+		case chr == '"':
+			quoted = !quoted
+		case chr == sep && !quoted:
+			parts = append(parts, value[start:idx])
+			start = idx + 1
+		}
+	}
+
+	return append(parts, value[start:])
+}
@@ -0,0 +1,551 @@
+// Copyright 2025 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package authenticators
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"net"
+	"net/url"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dadrus/heimdall/internal/app"
+	"github.com/dadrus/heimdall/internal/heimdall"
+	"github.com/dadrus/heimdall/internal/heimdall/mocks"
+	"github.com/dadrus/heimdall/internal/rules/mechanisms/subject"
+	"github.com/dadrus/heimdall/internal/validation"
+	"github.com/dadrus/heimdall/internal/x/pkix/pemx"
+	"github.com/dadrus/heimdall/internal/x/testsupport"
+)
+
+type x509TestPKI struct {
+	trustStorePath string
+	intCACert      *x509.Certificate
+	clientCert     *x509.Certificate
+	serverCert     *x509.Certificate
+	untrustedCert  *x509.Certificate
+}
+
+func createX509TestPKI(t *testing.T) x509TestPKI {
+	t.Helper()
+
+	rootCA, err := testsupport.NewRootCA("Test Root CA 1", time.Hour*24)
+	require.NoError(t, err)
+
+	intCAPrivKey, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	require.NoError(t, err)
+
+	intCACert, err := rootCA.IssueCertificate(
+		testsupport.WithSubject(pkix.Name{
+			CommonName:   "Test Int CA 1",
+			Organization: []string{"Test"},
+			Country:      []string{"EU"},
+		}),
+		testsupport.WithIsCA(),
+		testsupport.WithValidity(time.Now(), time.Hour*24),
+		testsupport.WithSubjectPubKey(&intCAPrivKey.PublicKey, x509.ECDSAWithSHA384))
+	require.NoError(t, err)
+
+	intCA := testsupport.NewCA(intCAPrivKey, intCACert)
+
+	eePrivKey, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	require.NoError(t, err)
+
+	clientCert, err := intCA.IssueCertificate(
+		testsupport.WithSubject(pkix.Name{
+			CommonName:   "foo",
+			Organization: []string{"Test"},
+			Country:      []string{"EU"},
+		}),
+		testsupport.WithValidity(time.Now(), time.Hour*24),
+		testsupport.WithSubjectPubKey(&eePrivKey.PublicKey, x509.ECDSAWithSHA384),
+		testsupport.WithKeyUsage(x509.KeyUsageDigitalSignature),
+		testsupport.WithExtendedKeyUsage(x509.ExtKeyUsageClientAuth),
+		testsupport.WithDNSNames([]string{"foo.example.com"}),
+		testsupport.WithEMailAddresses([]string{"foo@example.com"}),
+		testsupport.WithIPAddresses([]net.IP{net.ParseIP("127.0.0.1")}),
+		testsupport.WithURIs([]*url.URL{{Scheme: "spiffe", Host: "example.com", Path: "/ns/default/sa/foo"}}))
+	require.NoError(t, err)
+
+	serverCert, err := intCA.IssueCertificate(
+		testsupport.WithSubject(pkix.Name{CommonName: "bar"}),
+		testsupport.WithValidity(time.Now(), time.Hour*24),
+		testsupport.WithSubjectPubKey(&eePrivKey.PublicKey, x509.ECDSAWithSHA384),
+		testsupport.WithExtendedKeyUsage(x509.ExtKeyUsageServerAuth))
+	require.NoError(t, err)
+
+	otherCA, err := testsupport.NewRootCA("Test Root CA 2", time.Hour*24)
+	require.NoError(t, err)
+
+	untrustedCert, err := otherCA.IssueCertificate(
+		testsupport.WithSubject(pkix.Name{CommonName: "baz"}),
+		testsupport.WithValidity(time.Now(), time.Hour*24),
+		testsupport.WithSubjectPubKey(&eePrivKey.PublicKey, x509.ECDSAWithSHA384),
+		testsupport.WithExtendedKeyUsage(x509.ExtKeyUsageClientAuth))
+	require.NoError(t, err)
+
+	pemBytes, err := pemx.BuildPEM(pemx.WithX509Certificate(rootCA.Certificate))
+	require.NoError(t, err)
+
+	file, err := os.CreateTemp(t.TempDir(), "test-x509-authenticator-*")
+	require.NoError(t, err)
+
+	_, err = file.Write(pemBytes)
+	require.NoError(t, err)
+
+	return x509TestPKI{
+		trustStorePath: file.Name(),
+		intCACert:      intCACert,
+		clientCert:     clientCert,
+		serverCert:     serverCert,
+		untrustedCert:  untrustedCert,
+	}
+}
+
+func urlEncodedPEM(t *testing.T, certs ...*x509.Certificate) string {
+	t.Helper()
+
+	opts := make([]pemx.EntryOption, len(certs))
+	for idx, cert := range certs {
+		opts[idx] = pemx.WithX509Certificate(cert)
+	}
+
+	pemBytes, err := pemx.BuildPEM(opts...)
+	require.NoError(t, err)
+
+	return url.PathEscape(string(pemBytes))
+}
+
+func TestCreateX509Authenticator(t *testing.T) {
+	t.Parallel()
+
+	pki := createX509TestPKI(t)
+
+	for uc, tc := range map[string]struct {
+		config []byte
+		assert func(t *testing.T, err error, auth *x509Authenticator)
+	}{
+		"without configuration": {
+			assert: func(t *testing.T, err error, _ *x509Authenticator) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrConfiguration)
+				assert.Contains(t, err.Error(), "'trust_store' is a required field")
+			},
+		},
+		"with not existing trust store": {
+			config: []byte(`trust_store: /no/such/file.pem`),
+			assert: func(t *testing.T, err error, _ *x509Authenticator) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrConfiguration)
+				assert.Contains(t, err.Error(), "failed decoding")
+			},
+		},
+		"with unsupported attributes": {
+			config: []byte(`
+trust_store: ` + pki.trustStorePath + `
+foo: bar
+`),
+			assert: func(t *testing.T, err error, _ *x509Authenticator) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrConfiguration)
+				assert.Contains(t, err.Error(), "failed decoding")
+			},
+		},
+		"with minimal valid configuration": {
+			config: []byte(`trust_store: ` + pki.trustStorePath),
+			assert: func(t *testing.T, err error, auth *x509Authenticator) {
+				t.Helper()
+
+				require.NoError(t, err)
+				assert.Equal(t, "with minimal valid configuration", auth.ID())
+				assert.Len(t, auth.trustStore, 1)
+				assert.False(t, auth.allowForwardedClientCert)
+				assert.Equal(t, &SubjectInfo{IDFrom: "subject"}, auth.sf)
+				assert.False(t, auth.IsInsecure())
+			},
+		},
+		"with full configuration": {
+			config: []byte(`
+trust_store: ` + pki.trustStorePath + `
+allow_forwarded_client_cert: true
+subject:
+  id: spiffe_id
+  attributes: "{subject: subject, emails: email_addresses}"
+`),
+			assert: func(t *testing.T, err error, auth *x509Authenticator) {
+				t.Helper()
+
+				require.NoError(t, err)
+				assert.True(t, auth.allowForwardedClientCert)
+				assert.Equal(t, &SubjectInfo{
+					IDFrom:         "spiffe_id",
+					AttributesFrom: "{subject: subject, emails: email_addresses}",
+				}, auth.sf)
+			},
+		},
+	} {
+		t.Run(uc, func(t *testing.T) {
+			// GIVEN
+			conf, err := testsupport.DecodeTestConfig(tc.config)
+			require.NoError(t, err)
+
+			validator, err := validation.NewValidator()
+			require.NoError(t, err)
+
+			appCtx := app.NewContextMock(t)
+			appCtx.EXPECT().Validator().Maybe().Return(validator)
+			appCtx.EXPECT().Logger().Return(log.Logger)
+
+			// WHEN
+			auth, err := newX509Authenticator(appCtx, uc, conf)
+
+			// THEN
+			tc.assert(t, err, auth)
+		})
+	}
+}
+
+func TestCreateX509AuthenticatorFromPrototype(t *testing.T) {
+	t.Parallel()
+
+	pki := createX509TestPKI(t)
+
+	for uc, tc := range map[string]struct {
+		config []byte
+		assert func(t *testing.T, err error, prototype *x509Authenticator, configured *x509Authenticator)
+	}{
+		"no new configuration provided": {
+			assert: func(t *testing.T, err error, prototype *x509Authenticator, configured *x509Authenticator) {
+				t.Helper()
+
+				require.NoError(t, err)
+				assert.Equal(t, prototype, configured)
+			},
+		},
+		"trust store cannot be redefined": {
+			config: []byte(`trust_store: ` + pki.trustStorePath),
+			assert: func(t *testing.T, err error, _ *x509Authenticator, _ *x509Authenticator) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrConfiguration)
+				assert.Contains(t, err.Error(), "failed decoding")
+			},
+		},
+		"with subject redefined": {
+			config: []byte(`
+subject:
+  attributes: "{cn: common_name}"
+`),
+			assert: func(t *testing.T, err error, prototype *x509Authenticator, configured *x509Authenticator) {
+				t.Helper()
+
+				require.NoError(t, err)
+				assert.NotEqual(t, prototype, configured)
+				assert.Equal(t, prototype.ID(), configured.ID())
+				assert.Equal(t, prototype.trustStore, configured.trustStore)
+				assert.Equal(t, prototype.allowForwardedClientCert, configured.allowForwardedClientCert)
+				assert.Equal(t, &SubjectInfo{IDFrom: "subject", AttributesFrom: "{cn: common_name}"}, configured.sf)
+			},
+		},
+	} {
+		t.Run(uc, func(t *testing.T) {
+			// GIVEN
+			pc, err := testsupport.DecodeTestConfig([]byte(`trust_store: ` + pki.trustStorePath))
+			require.NoError(t, err)
+
+			conf, err := testsupport.DecodeTestConfig(tc.config)
+			require.NoError(t, err)
+
+			validator, err := validation.NewValidator()
+			require.NoError(t, err)
+
+			appCtx := app.NewContextMock(t)
+			appCtx.EXPECT().Validator().Maybe().Return(validator)
+			appCtx.EXPECT().Logger().Return(log.Logger)
+
+			prototype, err := newX509Authenticator(appCtx, uc, pc)
+			require.NoError(t, err)
+
+			// WHEN
+			auth, err := prototype.WithConfig(conf)
+
+			// THEN
+			var configured *x509Authenticator
+			if err == nil {
+				var ok bool
+
+				configured, ok = auth.(*x509Authenticator)
+				require.True(t, ok)
+			}
+
+			tc.assert(t, err, prototype, configured)
+		})
+	}
+}
+
+func TestX509AuthenticatorExecute(t *testing.T) {
+	t.Parallel()
+
+	pki := createX509TestPKI(t)
+
+	for uc, tc := range map[string]struct {
+		config    []byte
+		configure func(t *testing.T, fnt *mocks.RequestFunctionsMock)
+		assert    func(t *testing.T, err error, sub *subject.Subject)
+	}{
+		"no client certificate present": {
+			config: []byte(`trust_store: ` + pki.trustStorePath),
+			configure: func(t *testing.T, fnt *mocks.RequestFunctionsMock) {
+				t.Helper()
+
+				fnt.EXPECT().ClientCertificates().Return(nil)
+			},
+			assert: func(t *testing.T, err error, _ *subject.Subject) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrAuthentication)
+				assert.Contains(t, err.Error(), "no client certificate present")
+
+				var identifier interface{ ID() string }
+				require.ErrorAs(t, err, &identifier)
+				assert.Equal(t, "no client certificate present", identifier.ID())
+			},
+		},
+		"forwarded client certificate is ignored if not allowed": {
+			config: []byte(`trust_store: ` + pki.trustStorePath),
+			configure: func(t *testing.T, fnt *mocks.RequestFunctionsMock) {
+				t.Helper()
+
+				fnt.EXPECT().ClientCertificates().Return(nil)
+				fnt.EXPECT().Header("X-Forwarded-Client-Cert").Maybe().
+					Return(`Chain="` + urlEncodedPEM(t, pki.clientCert, pki.intCACert) + `"`)
+			},
+			assert: func(t *testing.T, err error, _ *subject.Subject) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrAuthentication)
+				assert.Contains(t, err.Error(), "no client certificate present")
+			},
+		},
+		"client certificate issued by untrusted ca": {
+			config: []byte(`trust_store: ` + pki.trustStorePath),
+			configure: func(t *testing.T, fnt *mocks.RequestFunctionsMock) {
+				t.Helper()
+
+				fnt.EXPECT().ClientCertificates().Return([]*x509.Certificate{pki.untrustedCert})
+			},
+			assert: func(t *testing.T, err error, _ *subject.Subject) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrAuthentication)
+				assert.Contains(t, err.Error(), "client certificate validation failed")
+			},
+		},
+		"certificate not allowed for client authentication": {
+			config: []byte(`trust_store: ` + pki.trustStorePath),
+			configure: func(t *testing.T, fnt *mocks.RequestFunctionsMock) {
+				t.Helper()
+
+				fnt.EXPECT().ClientCertificates().Return([]*x509.Certificate{pki.serverCert, pki.intCACert})
+			},
+			assert: func(t *testing.T, err error, _ *subject.Subject) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrAuthentication)
+				assert.Contains(t, err.Error(), "client certificate validation failed")
+			},
+		},
+		"subject id cannot be extracted": {
+			config: []byte(`
+trust_store: ` + pki.trustStorePath + `
+subject:
+  id: foo
+`),
+			configure: func(t *testing.T, fnt *mocks.RequestFunctionsMock) {
+				t.Helper()
+
+				fnt.EXPECT().ClientCertificates().Return([]*x509.Certificate{pki.clientCert, pki.intCACert})
+			},
+			assert: func(t *testing.T, err error, _ *subject.Subject) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrInternal)
+				assert.Contains(t, err.Error(), "failed to extract subject information")
+			},
+		},
+		"valid client certificate from tls connection": {
+			config: []byte(`trust_store: ` + pki.trustStorePath),
+			configure: func(t *testing.T, fnt *mocks.RequestFunctionsMock) {
+				t.Helper()
+
+				fnt.EXPECT().ClientCertificates().Return([]*x509.Certificate{pki.clientCert, pki.intCACert})
+			},
+			assert: func(t *testing.T, err error, sub *subject.Subject) {
+				t.Helper()
+
+				require.NoError(t, err)
+				require.NotNil(t, sub)
+
+				assert.Equal(t, pki.clientCert.Subject.String(), sub.ID)
+				assert.Equal(t, pki.clientCert.Subject.String(), sub.Attributes["subject"])
+				assert.Equal(t, pki.intCACert.Subject.String(), sub.Attributes["issuer"])
+				assert.Equal(t, "foo", sub.Attributes["common_name"])
+				assert.Equal(t, pki.clientCert.SerialNumber.String(), sub.Attributes["serial_number"])
+				assert.Len(t, sub.Attributes["fingerprint"], 64)
+				assert.Equal(t, []any{"foo.example.com"}, sub.Attributes["dns_names"])
+				assert.Equal(t, []any{"foo@example.com"}, sub.Attributes["email_addresses"])
+				assert.Equal(t, []any{"127.0.0.1"}, sub.Attributes["ip_addresses"])
+				assert.Equal(t, []any{"spiffe://example.com/ns/default/sa/foo"}, sub.Attributes["uris"])
+				assert.Equal(t, "spiffe://example.com/ns/default/sa/foo", sub.Attributes["spiffe_id"])
+			},
+		},
+		"valid client certificate chain from forwarded client cert header": {
+			config: []byte(`
+trust_store: ` + pki.trustStorePath + `
+allow_forwarded_client_cert: true
+subject:
+  id: spiffe_id
+`),
+			configure: func(t *testing.T, fnt *mocks.RequestFunctionsMock) {
+				t.Helper()
+
+				fnt.EXPECT().ClientCertificates().Return(nil)
+				fnt.EXPECT().Header("X-Forwarded-Client-Cert").Return(
+					`By=spiffe://example.com/proxy;Hash=abc;Subject="CN=bar,O=Test";URI=,` +
+						`By=spiffe://example.com/heimdall;Hash=def;Subject="CN=foo,O=Test,C=EU";` +
+						`Chain="` + urlEncodedPEM(t, pki.clientCert, pki.intCACert) + `"`)
+			},
+			assert: func(t *testing.T, err error, sub *subject.Subject) {
+				t.Helper()
+
+				require.NoError(t, err)
+				require.NotNil(t, sub)
+
+				assert.Equal(t, "spiffe://example.com/ns/default/sa/foo", sub.ID)
+				assert.Equal(t, "foo", sub.Attributes["common_name"])
+			},
+		},
+		"forwarded client cert header without intermediate ca certificate": {
+			config: []byte(`
+trust_store: ` + pki.trustStorePath + `
+allow_forwarded_client_cert: true
+`),
+			configure: func(t *testing.T, fnt *mocks.RequestFunctionsMock) {
+				t.Helper()
+
+				fnt.EXPECT().ClientCertificates().Return(nil)
+				fnt.EXPECT().Header("X-Forwarded-Client-Cert").Return(
+					`Hash=def;Cert="` + urlEncodedPEM(t, pki.clientCert) + `"`)
+			},
+			assert: func(t *testing.T, err error, _ *subject.Subject) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrAuthentication)
+				assert.Contains(t, err.Error(), "client certificate validation failed")
+			},
+		},
+		"forwarded client cert header without certificate": {
+			config: []byte(`
+trust_store: ` + pki.trustStorePath + `
+allow_forwarded_client_cert: true
+`),
+			configure: func(t *testing.T, fnt *mocks.RequestFunctionsMock) {
+				t.Helper()
+
+				fnt.EXPECT().ClientCertificates().Return(nil)
+				fnt.EXPECT().Header("X-Forwarded-Client-Cert").Return(`Hash=def;Subject="CN=foo"`)
+			},
+			assert: func(t *testing.T, err error, _ *subject.Subject) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrAuthentication)
+				assert.Contains(t, err.Error(), "failed to parse X-Forwarded-Client-Cert header")
+			},
+		},
+		"forwarded client cert header with malformed certificate": {
+			config: []byte(`
+trust_store: ` + pki.trustStorePath + `
+allow_forwarded_client_cert: true
+`),
+			configure: func(t *testing.T, fnt *mocks.RequestFunctionsMock) {
+				t.Helper()
+
+				fnt.EXPECT().ClientCertificates().Return(nil)
+				fnt.EXPECT().Header("X-Forwarded-Client-Cert").Return(`Cert="foo%zz"`)
+			},
+			assert: func(t *testing.T, err error, _ *subject.Subject) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrAuthentication)
+				assert.Contains(t, err.Error(), "failed to parse X-Forwarded-Client-Cert header")
+			},
+		},
+	} {
+		t.Run(uc, func(t *testing.T) {
+			// GIVEN
+			conf, err := testsupport.DecodeTestConfig(tc.config)
+			require.NoError(t, err)
+
+			validator, err := validation.NewValidator()
+			require.NoError(t, err)
+
+			appCtx := app.NewContextMock(t)
+			appCtx.EXPECT().Validator().Maybe().Return(validator)
+			appCtx.EXPECT().Logger().Return(log.Logger)
+
+			fnt := mocks.NewRequestFunctionsMock(t)
+			tc.configure(t, fnt)
+
+			ctx := mocks.NewRequestContextMock(t)
+			ctx.EXPECT().Context().Return(t.Context())
+			ctx.EXPECT().Request().Return(&heimdall.Request{RequestFunctions: fnt})
+
+			auth, err := newX509Authenticator(appCtx, uc, conf)
+			require.NoError(t, err)
+
+			// WHEN
+			sub, err := auth.Execute(ctx)
+
+			// THEN
+			tc.assert(t, err, sub)
+		})
+	}
+}
@@ -30,8 +30,8 @@ import (
 func TestCreateAuthorizerPrototypeUsingKnowType(t *testing.T) {
 	t.Parallel()
 
-	// there are 5 authorizers implemented, which should have been registered
-	require.Len(t, authorizerTypeFactories, 4)
+	// there are 6 authorizers implemented, which should have been registered
+	require.Len(t, authorizerTypeFactories, 6)
 
 	for uc, tc := range map[string]struct {
 		typ    string
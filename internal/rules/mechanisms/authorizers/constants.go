@@ -17,9 +17,11 @@
 package authorizers
 
 const (
-	AuthorizerAllow  = "allow"
-	AuthorizerDeny   = "deny"
-	AuthorizerLocal  = "local"
-	AuthorizerCEL    = "cel"
-	AuthorizerRemote = "remote"
+	AuthorizerAllow     = "allow"
+	AuthorizerDeny      = "deny"
+	AuthorizerLocal     = "local"
+	AuthorizerCEL       = "cel"
+	AuthorizerRemote    = "remote"
+	AuthorizerRateLimit = "rate_limit"
+	AuthorizerOPA       = "opa"
 )
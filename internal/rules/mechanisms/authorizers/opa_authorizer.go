@@ -0,0 +1,355 @@
+// Copyright 2025 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package authorizers
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"net/http"
+	"time"
+
+	"github.com/goccy/go-json"
+	"github.com/google/cel-go/cel"
+	"github.com/open-policy-agent/opa/v1/bundle"
+	"github.com/open-policy-agent/opa/v1/rego"
+	"github.com/rs/zerolog"
+
+	"github.com/dadrus/heimdall/internal/app"
+	"github.com/dadrus/heimdall/internal/cache"
+	"github.com/dadrus/heimdall/internal/heimdall"
+	"github.com/dadrus/heimdall/internal/rules/endpoint"
+	"github.com/dadrus/heimdall/internal/rules/mechanisms/cellib"
+	"github.com/dadrus/heimdall/internal/rules/mechanisms/subject"
+	"github.com/dadrus/heimdall/internal/x"
+	"github.com/dadrus/heimdall/internal/x/errorchain"
+	"github.com/dadrus/heimdall/internal/x/stringx"
+)
+
+const opaBundleLoadTimeout = 30 * time.Second
+
+// by intention. Used only during application bootstrap
+//
+//nolint:gochecknoinits
+func init() {
+	registerTypeFactory(
+		func(app app.Context, id string, typ string, conf map[string]any) (bool, Authorizer, error) {
+			if typ != AuthorizerOPA {
+				return false, nil, nil
+			}
+
+			auth, err := newOPAAuthorizer(app, id, conf)
+
+			return true, auth, err
+		})
+}
+
+type opaAuthorizer struct {
+	id          string
+	app         app.Context
+	query       string
+	policy      []func(r *rego.Rego)
+	prepared    rego.PreparedEvalQuery
+	expressions compiledExpressions
+	ttl         time.Duration
+	celEnv      *cel.Env
+}
+
+func newOPAAuthorizer(app app.Context, id string, rawConfig map[string]any) (*opaAuthorizer, error) {
+	logger := app.Logger()
+	logger.Info().Str("_id", id).Msg("Creating opa authorizer")
+
+	type Config struct {
+		Query       string             `mapstructure:"query"       validate:"required"`
+		Modules     []string           `mapstructure:"modules"     validate:"required_without=Bundle"`
+		Data        []string           `mapstructure:"data"`
+		Bundle      *endpoint.Endpoint `mapstructure:"bundle"      validate:"required_without=Modules"`
+		Expressions []Expression       `mapstructure:"expressions" validate:"dive"`
+		CacheTTL    time.Duration      `mapstructure:"cache_ttl"`
+	}
+
+	var conf Config
+	if err := decodeConfig(app, rawConfig, &conf); err != nil {
+		return nil, errorchain.NewWithMessagef(heimdall.ErrConfiguration,
+			"failed decoding config for opa authorizer '%s'", id).CausedBy(err)
+	}
+
+	env, err := cel.NewEnv(cellib.Library())
+	if err != nil {
+		return nil, errorchain.NewWithMessage(heimdall.ErrInternal, "failed creating CEL environment").
+			CausedBy(err)
+	}
+
+	expressions, err := compileExpressions(conf.Expressions, env)
+	if err != nil {
+		return nil, err
+	}
+
+	var policy []func(r *rego.Rego)
+
+	if len(conf.Modules) != 0 || len(conf.Data) != 0 {
+		policy = append(policy, rego.Load(append(conf.Modules, conf.Data...), nil))
+	}
+
+	if conf.Bundle != nil {
+		bndl, err := loadOPABundle(conf.Bundle)
+		if err != nil {
+			return nil, errorchain.NewWithMessagef(heimdall.ErrConfiguration,
+				"failed loading bundle for opa authorizer '%s'", id).CausedBy(err)
+		}
+
+		policy = append(policy, rego.ParsedBundle(id, bndl))
+	}
+
+	prepared, err := prepareOPAQuery(conf.Query, policy)
+	if err != nil {
+		return nil, errorchain.NewWithMessagef(heimdall.ErrConfiguration,
+			"failed preparing query for opa authorizer '%s'", id).CausedBy(err)
+	}
+
+	return &opaAuthorizer{
+		id:          id,
+		app:         app,
+		query:       conf.Query,
+		policy:      policy,
+		prepared:    prepared,
+		expressions: expressions,
+		ttl:         conf.CacheTTL,
+		celEnv:      env,
+	}, nil
+}
+
+func (a *opaAuthorizer) Execute(ctx heimdall.RequestContext, sub *subject.Subject) error {
+	logger := zerolog.Ctx(ctx.Context())
+	logger.Debug().Str("_id", a.id).Msg("Authorizing using opa authorizer")
+
+	if sub == nil {
+		return errorchain.NewWithMessage(heimdall.ErrInternal,
+			"failed to execute opa authorizer due to 'nil' subject").
+			WithErrorContext(a)
+	}
+
+	cch := cache.Ctx(ctx.Context())
+	input := map[string]any{
+		"Subject": sub,
+		"Request": opaRequestInput(ctx.Request()),
+		"Outputs": ctx.Outputs(),
+	}
+
+	var (
+		cacheKey string
+		result   any
+		cached   bool
+	)
+
+	if a.ttl > 0 {
+		cacheKey = a.calculateCacheKey(input)
+		if entry, err := cch.Get(ctx.Context(), cacheKey); err == nil {
+			if err = json.Unmarshal(entry, &result); err == nil {
+				logger.Debug().Msg("Reusing policy evaluation result from cache")
+
+				cached = true
+			}
+		}
+	}
+
+	if !cached {
+		var err error
+
+		if result, err = a.evaluate(ctx.Context(), input); err != nil {
+			return err
+		}
+
+		if a.ttl > 0 && len(cacheKey) != 0 {
+			data, _ := json.Marshal(result)
+
+			if err = cch.Set(ctx.Context(), cacheKey, data, a.ttl); err != nil {
+				logger.Warn().Err(err).Msg("Failed to cache policy evaluation result")
+			}
+		}
+	}
+
+	if err := a.verify(result); err != nil {
+		return err
+	}
+
+	ctx.Outputs()[a.id] = result
+
+	return nil
+}
+
+func (a *opaAuthorizer) WithConfig(rawConfig map[string]any) (Authorizer, error) {
+	if len(rawConfig) == 0 {
+		return a, nil
+	}
+
+	type Config struct {
+		Query       string        `mapstructure:"query"`
+		Expressions []Expression  `mapstructure:"expressions" validate:"dive"`
+		CacheTTL    time.Duration `mapstructure:"cache_ttl"`
+	}
+
+	var conf Config
+	if err := decodeConfig(a.app, rawConfig, &conf); err != nil {
+		return nil, errorchain.NewWithMessagef(heimdall.ErrConfiguration,
+			"failed decoding config for opa authorizer '%s'", a.id).CausedBy(err)
+	}
+
+	expressions, err := compileExpressions(conf.Expressions, a.celEnv)
+	if err != nil {
+		return nil, err
+	}
+
+	query := a.query
+	prepared := a.prepared
+
+	if len(conf.Query) != 0 && conf.Query != a.query {
+		query = conf.Query
+
+		if prepared, err = prepareOPAQuery(query, a.policy); err != nil {
+			return nil, errorchain.NewWithMessagef(heimdall.ErrConfiguration,
+				"failed preparing query for opa authorizer '%s'", a.id).CausedBy(err)
+		}
+	}
+
+	return &opaAuthorizer{
+		id:          a.id,
+		app:         a.app,
+		query:       query,
+		policy:      a.policy,
+		prepared:    prepared,
+		expressions: x.IfThenElse(len(expressions) != 0, expressions, a.expressions),
+		ttl:         x.IfThenElse(conf.CacheTTL > 0, conf.CacheTTL, a.ttl),
+		celEnv:      a.celEnv,
+	}, nil
+}
+
+func (a *opaAuthorizer) ID() string { return a.id }
+
+func (a *opaAuthorizer) ContinueOnError() bool { return false }
+
+func (a *opaAuthorizer) evaluate(ctx context.Context, input map[string]any) (any, error) {
+	results, err := a.prepared.Eval(ctx, rego.EvalInput(input))
+	if err != nil {
+		return nil, errorchain.NewWithMessage(heimdall.ErrInternal, "failed to evaluate policy").
+			WithErrorContext(a).
+			CausedBy(err)
+	}
+
+	if len(results) == 0 || len(results[0].Expressions) == 0 {
+		return nil, errorchain.NewWithMessagef(heimdall.ErrAuthorization,
+			"result of query '%s' is undefined", a.query).
+			WithErrorContext(a)
+	}
+
+	return results[0].Expressions[0].Value, nil
+}
+
+func (a *opaAuthorizer) verify(result any) error {
+	if len(a.expressions) != 0 {
+		return a.expressions.eval(map[string]any{"Payload": result}, a)
+	}
+
+	if allowed, ok := result.(bool); !ok || !allowed {
+		return errorchain.NewWithMessagef(heimdall.ErrAuthorization,
+			"query '%s' did not evaluate to true", a.query).
+			WithErrorContext(a)
+	}
+
+	return nil
+}
+
+func (a *opaAuthorizer) calculateCacheKey(input map[string]any) string {
+	const int64BytesCount = 8
+
+	ttlBytes := make([]byte, int64BytesCount)
+
+	//nolint:gosec
+	// no integer overflow during conversion possible
+	binary.LittleEndian.PutUint64(ttlBytes, uint64(a.ttl))
+
+	rawInput, _ := json.Marshal(input)
+
+	hash := sha256.New()
+	hash.Write(stringx.ToBytes(a.id))
+	hash.Write(stringx.ToBytes(a.query))
+	hash.Write(ttlBytes)
+	hash.Write(rawInput)
+
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+func prepareOPAQuery(query string, policy []func(r *rego.Rego)) (rego.PreparedEvalQuery, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), opaBundleLoadTimeout)
+	defer cancel()
+
+	return rego.New(append([]func(r *rego.Rego){rego.Query(query)}, policy...)...).PrepareForEval(ctx)
+}
+
+func loadOPABundle(ep *endpoint.Endpoint) (*bundle.Bundle, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), opaBundleLoadTimeout)
+	defer cancel()
+
+	bundleEndpoint := *ep
+	bundleEndpoint.Method = x.IfThenElse(len(ep.Method) != 0, ep.Method, http.MethodGet)
+
+	data, err := bundleEndpoint.SendRequest(ctx, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	bndl, err := bundle.NewReader(bytes.NewReader(data)).Read()
+	if err != nil {
+		return nil, err
+	}
+
+	return &bndl, nil
+}
+
+func opaRequestInput(req *heimdall.Request) map[string]any {
+	if req == nil {
+		return nil
+	}
+
+	input := map[string]any{
+		"Method":            req.Method,
+		"ClientIPAddresses": req.ClientIPAddresses,
+	}
+
+	if req.RequestFunctions != nil {
+		input["Headers"] = req.Headers()
+	}
+
+	if req.Metadata != nil {
+		input["Metadata"] = req.Metadata
+	}
+
+	if req.URL != nil {
+		input["URL"] = map[string]any{
+			"Scheme":   req.URL.Scheme,
+			"Host":     req.URL.Host,
+			"Path":     req.URL.Path,
+			"Query":    req.URL.Query(),
+			"Captures": req.URL.Captures,
+			"String":   req.URL.String(),
+		}
+	}
+
+	return input
+}
@@ -0,0 +1,493 @@
+// Copyright 2025 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package authorizers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/open-policy-agent/opa/v1/bundle"
+	"github.com/rs/zerolog/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dadrus/heimdall/internal/app"
+	"github.com/dadrus/heimdall/internal/cache"
+	"github.com/dadrus/heimdall/internal/cache/mocks"
+	"github.com/dadrus/heimdall/internal/config"
+	"github.com/dadrus/heimdall/internal/heimdall"
+	heimdallmocks "github.com/dadrus/heimdall/internal/heimdall/mocks"
+	"github.com/dadrus/heimdall/internal/rules/mechanisms/subject"
+	"github.com/dadrus/heimdall/internal/validation"
+	"github.com/dadrus/heimdall/internal/x/testsupport"
+)
+
+const testRegoPolicy = `
+package authz
+
+default allow := false
+
+allow if {
+	input.Subject.ID == data.users[_]
+	input.Request.Method == "GET"
+}
+
+decision := {"allow": allow, "user": input.Subject.ID}
+`
+
+func writeTestPolicy(t *testing.T) (string, string) {
+	t.Helper()
+
+	dir := t.TempDir()
+	modulePath := filepath.Join(dir, "authz.rego")
+	dataPath := filepath.Join(dir, "data.json")
+
+	require.NoError(t, os.WriteFile(modulePath, []byte(testRegoPolicy), 0o600))
+	require.NoError(t, os.WriteFile(dataPath, []byte(`{"users": ["alice"]}`), 0o600))
+
+	return modulePath, dataPath
+}
+
+func TestCreateOPAAuthorizer(t *testing.T) {
+	t.Parallel()
+
+	modulePath, dataPath := writeTestPolicy(t)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		if req.URL.Path != "/bundle.tar.gz" {
+			rw.WriteHeader(http.StatusNotFound)
+
+			return
+		}
+
+		err := bundle.NewWriter(rw).Write(bundle.Bundle{
+			Data: map[string]any{"users": []any{"bob"}},
+			Modules: []bundle.ModuleFile{
+				{URL: "/authz.rego", Path: "/authz.rego", Raw: []byte(testRegoPolicy)},
+			},
+		})
+		require.NoError(t, err)
+	}))
+	t.Cleanup(srv.Close)
+
+	for uc, tc := range map[string]struct {
+		config []byte
+		assert func(t *testing.T, err error, auth *opaAuthorizer)
+	}{
+		"without configuration": {
+			assert: func(t *testing.T, err error, _ *opaAuthorizer) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrConfiguration)
+				assert.Contains(t, err.Error(), "'query' is a required field")
+			},
+		},
+		"without modules and bundle": {
+			config: []byte(`query: data.authz.allow`),
+			assert: func(t *testing.T, err error, _ *opaAuthorizer) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrConfiguration)
+				assert.Contains(t, err.Error(), "'modules' is a required field")
+			},
+		},
+		"with unsupported attributes": {
+			config: []byte(`
+query: data.authz.allow
+modules: [` + modulePath + `]
+foo: bar
+`),
+			assert: func(t *testing.T, err error, _ *opaAuthorizer) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrConfiguration)
+				assert.Contains(t, err.Error(), "failed decoding")
+			},
+		},
+		"with not existing module": {
+			config: []byte(`
+query: data.authz.allow
+modules: [/does/not/exist.rego]
+`),
+			assert: func(t *testing.T, err error, _ *opaAuthorizer) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrConfiguration)
+				assert.Contains(t, err.Error(), "failed preparing query")
+			},
+		},
+		"with malformed query": {
+			config: []byte(`
+query: "data.authz.allow =="
+modules: [` + modulePath + `]
+`),
+			assert: func(t *testing.T, err error, _ *opaAuthorizer) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrConfiguration)
+				assert.Contains(t, err.Error(), "failed preparing query")
+			},
+		},
+		"with not available bundle": {
+			config: []byte(`
+query: data.authz.allow
+bundle: ` + srv.URL + `/foo.tar.gz
+`),
+			assert: func(t *testing.T, err error, _ *opaAuthorizer) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrConfiguration)
+				assert.Contains(t, err.Error(), "failed loading bundle")
+			},
+		},
+		"with modules, data and all other properties": {
+			config: []byte(`
+query: data.authz.decision
+modules: [` + modulePath + `]
+data: [` + dataPath + `]
+cache_ttl: 5s
+expressions:
+  - expression: Payload.allow == true
+`),
+			assert: func(t *testing.T, err error, auth *opaAuthorizer) {
+				t.Helper()
+
+				require.NoError(t, err)
+				assert.Equal(t, "with modules, data and all other properties", auth.ID())
+				assert.Equal(t, "data.authz.decision", auth.query)
+				assert.Len(t, auth.policy, 1)
+				assert.Len(t, auth.expressions, 1)
+				assert.Equal(t, 5*time.Second, auth.ttl)
+				assert.False(t, auth.ContinueOnError())
+			},
+		},
+		"with bundle": {
+			config: []byte(`
+query: data.authz.allow
+bundle: ` + srv.URL + `/bundle.tar.gz
+`),
+			assert: func(t *testing.T, err error, auth *opaAuthorizer) {
+				t.Helper()
+
+				require.NoError(t, err)
+				assert.Len(t, auth.policy, 1)
+				assert.Empty(t, auth.expressions)
+			},
+		},
+	} {
+		t.Run(uc, func(t *testing.T) {
+			// GIVEN
+			conf, err := testsupport.DecodeTestConfig(tc.config)
+			require.NoError(t, err)
+
+			validator, err := validation.NewValidator(
+				validation.WithTagValidator(config.EnforcementSettings{}),
+			)
+			require.NoError(t, err)
+
+			appCtx := app.NewContextMock(t)
+			appCtx.EXPECT().Validator().Maybe().Return(validator)
+			appCtx.EXPECT().Logger().Return(log.Logger)
+
+			// WHEN
+			a, err := newOPAAuthorizer(appCtx, uc, conf)
+
+			// THEN
+			tc.assert(t, err, a)
+		})
+	}
+}
+
+func TestCreateOPAAuthorizerFromPrototype(t *testing.T) {
+	t.Parallel()
+
+	modulePath, dataPath := writeTestPolicy(t)
+
+	prototypeConfig := []byte(`
+query: data.authz.allow
+modules: [` + modulePath + `]
+data: [` + dataPath + `]
+`)
+
+	for uc, tc := range map[string]struct {
+		config []byte
+		assert func(t *testing.T, err error, prototype *opaAuthorizer, configured *opaAuthorizer)
+	}{
+		"no new configuration provided": {
+			assert: func(t *testing.T, err error, prototype *opaAuthorizer, configured *opaAuthorizer) {
+				t.Helper()
+
+				require.NoError(t, err)
+				assert.Equal(t, prototype, configured)
+			},
+		},
+		"with unsupported attributes": {
+			config: []byte(`modules: [foo.rego]`),
+			assert: func(t *testing.T, err error, _ *opaAuthorizer, _ *opaAuthorizer) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrConfiguration)
+			},
+		},
+		"with malformed query": {
+			config: []byte(`query: "data.authz.allow =="`),
+			assert: func(t *testing.T, err error, _ *opaAuthorizer, _ *opaAuthorizer) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrConfiguration)
+				assert.Contains(t, err.Error(), "failed preparing query")
+			},
+		},
+		"with new query, expressions and ttl": {
+			config: []byte(`
+query: data.authz.decision
+cache_ttl: 1m
+expressions:
+  - expression: Payload.allow == true
+`),
+			assert: func(t *testing.T, err error, prototype *opaAuthorizer, configured *opaAuthorizer) {
+				t.Helper()
+
+				require.NoError(t, err)
+				assert.Equal(t, prototype.ID(), configured.ID())
+				assert.Equal(t, "data.authz.decision", configured.query)
+				assert.Equal(t, prototype.policy, configured.policy)
+				assert.Len(t, configured.expressions, 1)
+				assert.Equal(t, time.Minute, configured.ttl)
+			},
+		},
+	} {
+		t.Run(uc, func(t *testing.T) {
+			// GIVEN
+			pc, err := testsupport.DecodeTestConfig(prototypeConfig)
+			require.NoError(t, err)
+
+			conf, err := testsupport.DecodeTestConfig(tc.config)
+			require.NoError(t, err)
+
+			validator, err := validation.NewValidator(
+				validation.WithTagValidator(config.EnforcementSettings{}),
+			)
+			require.NoError(t, err)
+
+			appCtx := app.NewContextMock(t)
+			appCtx.EXPECT().Validator().Maybe().Return(validator)
+			appCtx.EXPECT().Logger().Return(log.Logger)
+
+			prototype, err := newOPAAuthorizer(appCtx, uc, pc)
+			require.NoError(t, err)
+
+			// WHEN
+			auth, err := prototype.WithConfig(conf)
+
+			// THEN
+			var configured *opaAuthorizer
+			if err == nil {
+				var ok bool
+
+				configured, ok = auth.(*opaAuthorizer)
+				require.True(t, ok)
+			}
+
+			tc.assert(t, err, prototype, configured)
+		})
+	}
+}
+
+func TestOPAAuthorizerExecute(t *testing.T) {
+	t.Parallel()
+
+	modulePath, dataPath := writeTestPolicy(t)
+
+	for uc, tc := range map[string]struct {
+		config         []byte
+		subject        *subject.Subject
+		method         string
+		configureCache func(t *testing.T, cch *mocks.CacheMock)
+		assert         func(t *testing.T, err error, outputs map[string]any)
+	}{
+		"without subject": {
+			config: []byte(`query: data.authz.allow`),
+			assert: func(t *testing.T, err error, _ map[string]any) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrInternal)
+				assert.Contains(t, err.Error(), "'nil' subject")
+			},
+		},
+		"denied by boolean query result": {
+			config:  []byte(`query: data.authz.allow`),
+			subject: &subject.Subject{ID: "bob"},
+			method:  http.MethodGet,
+			assert: func(t *testing.T, err error, _ map[string]any) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrAuthorization)
+				assert.Contains(t, err.Error(), "did not evaluate to true")
+
+				var identifier interface{ ID() string }
+				require.ErrorAs(t, err, &identifier)
+				assert.Equal(t, "denied by boolean query result", identifier.ID())
+			},
+		},
+		"denied due to undefined query result": {
+			config:  []byte(`query: data.authz.foo`),
+			subject: &subject.Subject{ID: "alice"},
+			method:  http.MethodGet,
+			assert: func(t *testing.T, err error, _ map[string]any) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrAuthorization)
+				assert.Contains(t, err.Error(), "is undefined")
+			},
+		},
+		"denied by expression": {
+			config: []byte(`
+query: data.authz.decision
+expressions:
+  - expression: Payload.allow == true
+    message: user is not allowed
+`),
+			subject: &subject.Subject{ID: "alice"},
+			method:  http.MethodPost,
+			assert: func(t *testing.T, err error, _ map[string]any) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrAuthorization)
+				assert.Contains(t, err.Error(), "user is not allowed")
+			},
+		},
+		"allowed by boolean query result": {
+			config:  []byte(`query: data.authz.allow`),
+			subject: &subject.Subject{ID: "alice"},
+			method:  http.MethodGet,
+			assert: func(t *testing.T, err error, outputs map[string]any) {
+				t.Helper()
+
+				require.NoError(t, err)
+				assert.Equal(t, true, outputs["allowed by boolean query result"])
+			},
+		},
+		"allowed by expression and result cached": {
+			config: []byte(`
+query: data.authz.decision
+cache_ttl: 1m
+expressions:
+  - expression: Payload.allow == true
+  - expression: Payload.user == "alice"
+`),
+			subject: &subject.Subject{ID: "alice"},
+			method:  http.MethodGet,
+			configureCache: func(t *testing.T, cch *mocks.CacheMock) {
+				t.Helper()
+
+				cch.EXPECT().Get(mock.Anything, mock.Anything).Return(nil, assert.AnError)
+				cch.EXPECT().Set(mock.Anything, mock.Anything, mock.Anything, time.Minute).Return(nil)
+			},
+			assert: func(t *testing.T, err error, outputs map[string]any) {
+				t.Helper()
+
+				require.NoError(t, err)
+				assert.Equal(t, map[string]any{"allow": true, "user": "alice"},
+					outputs["allowed by expression and result cached"])
+			},
+		},
+		"allowed by cached result": {
+			config: []byte(`
+query: data.authz.allow
+cache_ttl: 1m
+`),
+			subject: &subject.Subject{ID: "bob"},
+			method:  http.MethodGet,
+			configureCache: func(t *testing.T, cch *mocks.CacheMock) {
+				t.Helper()
+
+				cch.EXPECT().Get(mock.Anything, mock.Anything).Return([]byte(`true`), nil)
+			},
+			assert: func(t *testing.T, err error, _ map[string]any) {
+				t.Helper()
+
+				require.NoError(t, err)
+			},
+		},
+	} {
+		t.Run(uc, func(t *testing.T) {
+			// GIVEN
+			rawConf, err := testsupport.DecodeTestConfig(tc.config)
+			require.NoError(t, err)
+
+			rawConf["modules"] = []string{modulePath}
+			rawConf["data"] = []string{dataPath}
+
+			validator, err := validation.NewValidator(
+				validation.WithTagValidator(config.EnforcementSettings{}),
+			)
+			require.NoError(t, err)
+
+			appCtx := app.NewContextMock(t)
+			appCtx.EXPECT().Validator().Maybe().Return(validator)
+			appCtx.EXPECT().Logger().Return(log.Logger)
+
+			auth, err := newOPAAuthorizer(appCtx, uc, rawConf)
+			require.NoError(t, err)
+
+			cch := mocks.NewCacheMock(t)
+			if tc.configureCache != nil {
+				tc.configureCache(t, cch)
+			}
+
+			outputs := map[string]any{}
+
+			reqf := heimdallmocks.NewRequestFunctionsMock(t)
+			reqf.EXPECT().Headers().Maybe().Return(map[string]string{"X-Foo": "bar"})
+
+			ctx := heimdallmocks.NewRequestContextMock(t)
+			ctx.EXPECT().Context().Return(cache.WithContext(t.Context(), cch))
+			ctx.EXPECT().Request().Maybe().Return(&heimdall.Request{
+				RequestFunctions:  reqf,
+				Method:            tc.method,
+				URL:               &heimdall.URL{URL: url.URL{Scheme: "http", Host: "foo.bar", Path: "/test"}},
+				ClientIPAddresses: []string{"127.0.0.1"},
+			})
+			ctx.EXPECT().Outputs().Maybe().Return(outputs)
+
+			// WHEN
+			err = auth.Execute(ctx, tc.subject)
+
+			// THEN
+			tc.assert(t, err, outputs)
+		})
+	}
+}
@@ -0,0 +1,197 @@
+// Copyright 2025 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package authorizers
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"time"
+
+	"github.com/rs/zerolog"
+
+	"github.com/dadrus/heimdall/internal/app"
+	"github.com/dadrus/heimdall/internal/cache"
+	"github.com/dadrus/heimdall/internal/heimdall"
+	"github.com/dadrus/heimdall/internal/rules/mechanisms/subject"
+	"github.com/dadrus/heimdall/internal/rules/mechanisms/template"
+	"github.com/dadrus/heimdall/internal/x"
+	"github.com/dadrus/heimdall/internal/x/errorchain"
+	"github.com/dadrus/heimdall/internal/x/stringx"
+)
+
+// by intention. Used only during application bootstrap
+//
+//nolint:gochecknoinits
+func init() {
+	registerTypeFactory(
+		func(app app.Context, id string, typ string, conf map[string]any) (bool, Authorizer, error) {
+			if typ != AuthorizerRateLimit {
+				return false, nil, nil
+			}
+
+			auth, err := newRateLimitAuthorizer(app, id, conf)
+
+			return true, auth, err
+		})
+}
+
+type rateLimitAuthorizer struct {
+	id        string
+	app       app.Context
+	key       template.Template
+	algorithm string
+	limit     int
+	window    time.Duration
+	counter   rateLimitCounter
+}
+
+func newRateLimitAuthorizer(app app.Context, id string, rawConfig map[string]any) (*rateLimitAuthorizer, error) {
+	logger := app.Logger()
+	logger.Info().Str("_id", id).Msg("Creating rate_limit authorizer")
+
+	type Config struct {
+		Key       template.Template `mapstructure:"key"       validate:"required"`
+		Algorithm string            `mapstructure:"algorithm" validate:"omitempty,oneof=token_bucket sliding_window"`
+		Limit     int               `mapstructure:"limit"     validate:"gt=0"`
+		Window    time.Duration     `mapstructure:"window"    validate:"gt=0"`
+	}
+
+	var conf Config
+	if err := decodeConfig(app, rawConfig, &conf); err != nil {
+		return nil, errorchain.NewWithMessagef(heimdall.ErrConfiguration,
+			"failed decoding config for rate_limit authorizer '%s'", id).CausedBy(err)
+	}
+
+	if app.Config().Cache.Type == "noop" {
+		return nil, errorchain.NewWithMessagef(heimdall.ErrConfiguration,
+			"rate_limit authorizer '%s' requires a cache, but the noop cache is configured", id)
+	}
+
+	algorithm := x.IfThenElse(len(conf.Algorithm) != 0, conf.Algorithm, rateLimitAlgorithmTokenBucket)
+
+	return &rateLimitAuthorizer{
+		id:        id,
+		app:       app,
+		key:       conf.Key,
+		algorithm: algorithm,
+		limit:     conf.Limit,
+		window:    conf.Window,
+		counter:   newRateLimitCounter(algorithm, conf.Limit, conf.Window),
+	}, nil
+}
+
+func (a *rateLimitAuthorizer) Execute(ctx heimdall.RequestContext, sub *subject.Subject) error {
+	logger := zerolog.Ctx(ctx.Context())
+	logger.Debug().Str("_id", a.id).Msg("Authorizing using rate_limit authorizer")
+
+	if heimdall.IsRevalidation(ctx.Context()) {
+		// the request has already been accounted for, when the subject has been authorized initially
+		return nil
+	}
+
+	key, err := a.key.Render(map[string]any{
+		"Request": ctx.Request(),
+		"Subject": sub,
+		"Outputs": ctx.Outputs(),
+	})
+	if err != nil {
+		return errorchain.NewWithMessage(heimdall.ErrInternal, "failed to render rate limit key").
+			WithErrorContext(a).
+			CausedBy(err)
+	}
+
+	decision, err := a.counter.take(ctx.Context(), cache.Ctx(ctx.Context()), a.calculateCacheKey(key), time.Now())
+	if err != nil {
+		return errorchain.NewWithMessage(heimdall.ErrCommunication, "failed to update rate limit counter").
+			WithErrorContext(a).
+			CausedBy(err)
+	}
+
+	if !decision.allowed {
+		return errorchain.NewWithMessagef(&heimdall.TooManyRequestsError{
+			Message:    "too many requests",
+			Limit:      a.limit,
+			Remaining:  decision.remaining,
+			Reset:      decision.reset,
+			RetryAfter: decision.retryAfter,
+		}, "rate limit of %d requests per %s exceeded", a.limit, a.window).
+			WithErrorContext(a)
+	}
+
+	return nil
+}
+
+func (a *rateLimitAuthorizer) WithConfig(rawConfig map[string]any) (Authorizer, error) {
+	if len(rawConfig) == 0 {
+		return a, nil
+	}
+
+	type Config struct {
+		Key       template.Template `mapstructure:"key"`
+		Algorithm string            `mapstructure:"algorithm" validate:"omitempty,oneof=token_bucket sliding_window"`
+		Limit     int               `mapstructure:"limit"     validate:"gte=0"`
+		Window    time.Duration     `mapstructure:"window"    validate:"gte=0"`
+	}
+
+	var conf Config
+	if err := decodeConfig(a.app, rawConfig, &conf); err != nil {
+		return nil, errorchain.NewWithMessagef(heimdall.ErrConfiguration,
+			"failed decoding config for rate_limit authorizer '%s'", a.id).CausedBy(err)
+	}
+
+	algorithm := x.IfThenElse(len(conf.Algorithm) != 0, conf.Algorithm, a.algorithm)
+	limit := x.IfThenElse(conf.Limit > 0, conf.Limit, a.limit)
+	window := x.IfThenElse(conf.Window > 0, conf.Window, a.window)
+
+	return &rateLimitAuthorizer{
+		id:        a.id,
+		app:       a.app,
+		key:       x.IfThenElse(conf.Key != nil, conf.Key, a.key),
+		algorithm: algorithm,
+		limit:     limit,
+		window:    window,
+		counter:   newRateLimitCounter(algorithm, limit, window),
+	}, nil
+}
+
+func (a *rateLimitAuthorizer) ID() string { return a.id }
+
+func (a *rateLimitAuthorizer) ContinueOnError() bool { return false }
+
+func (a *rateLimitAuthorizer) calculateCacheKey(key string) string {
+	const int64BytesCount = 8
+
+	limitBytes := make([]byte, int64BytesCount)
+	windowBytes := make([]byte, int64BytesCount)
+
+	//nolint:gosec
+	// no integer overflow during conversion possible
+	binary.LittleEndian.PutUint64(limitBytes, uint64(a.limit))
+	//nolint:gosec
+	// no integer overflow during conversion possible
+	binary.LittleEndian.PutUint64(windowBytes, uint64(a.window))
+
+	hash := sha256.New()
+	hash.Write(stringx.ToBytes(a.id))
+	hash.Write(stringx.ToBytes(a.algorithm))
+	hash.Write(limitBytes)
+	hash.Write(windowBytes)
+	hash.Write(stringx.ToBytes(key))
+
+	return hex.EncodeToString(hash.Sum(nil))
+}
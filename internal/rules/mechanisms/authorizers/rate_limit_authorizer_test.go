@@ -0,0 +1,467 @@
+// Copyright 2025 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package authorizers
+
+import (
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dadrus/heimdall/internal/app"
+	"github.com/dadrus/heimdall/internal/cache"
+	"github.com/dadrus/heimdall/internal/cache/memory"
+	"github.com/dadrus/heimdall/internal/cache/noop"
+	"github.com/dadrus/heimdall/internal/config"
+	"github.com/dadrus/heimdall/internal/heimdall"
+	"github.com/dadrus/heimdall/internal/heimdall/mocks"
+	"github.com/dadrus/heimdall/internal/rules/mechanisms/subject"
+	"github.com/dadrus/heimdall/internal/validation"
+	"github.com/dadrus/heimdall/internal/x/testsupport"
+)
+
+func TestCreateRateLimitAuthorizer(t *testing.T) {
+	t.Parallel()
+
+	for uc, tc := range map[string]struct {
+		config    []byte
+		cacheType string
+		assert    func(t *testing.T, err error, auth *rateLimitAuthorizer)
+	}{
+		"without configuration": {
+			assert: func(t *testing.T, err error, _ *rateLimitAuthorizer) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrConfiguration)
+				assert.Contains(t, err.Error(), "'key' is a required field")
+			},
+		},
+		"without limit": {
+			config: []byte(`
+key: "{{ .Subject.ID }}"
+window: 1m
+`),
+			assert: func(t *testing.T, err error, _ *rateLimitAuthorizer) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrConfiguration)
+				assert.Contains(t, err.Error(), "'limit' must be greater than 0")
+			},
+		},
+		"with unsupported algorithm": {
+			config: []byte(`
+key: "{{ .Subject.ID }}"
+limit: 10
+window: 1m
+algorithm: leaky_bucket
+`),
+			assert: func(t *testing.T, err error, _ *rateLimitAuthorizer) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrConfiguration)
+				assert.Contains(t, err.Error(), "'algorithm' must be one of")
+			},
+		},
+		"with unsupported attributes": {
+			config: []byte(`
+key: "{{ .Subject.ID }}"
+limit: 10
+window: 1m
+foo: bar
+`),
+			assert: func(t *testing.T, err error, _ *rateLimitAuthorizer) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrConfiguration)
+				assert.Contains(t, err.Error(), "failed decoding")
+			},
+		},
+		"with noop cache configured": {
+			config: []byte(`
+key: "{{ .Subject.ID }}"
+limit: 10
+window: 1m
+`),
+			cacheType: "noop",
+			assert: func(t *testing.T, err error, _ *rateLimitAuthorizer) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrConfiguration)
+				assert.Contains(t, err.Error(), "requires a cache")
+			},
+		},
+		"with minimal valid configuration": {
+			config: []byte(`
+key: "{{ .Subject.ID }}"
+limit: 10
+window: 1m
+`),
+			assert: func(t *testing.T, err error, auth *rateLimitAuthorizer) {
+				t.Helper()
+
+				require.NoError(t, err)
+				assert.Equal(t, "with minimal valid configuration", auth.ID())
+				assert.Equal(t, rateLimitAlgorithmTokenBucket, auth.algorithm)
+				assert.Equal(t, 10, auth.limit)
+				assert.Equal(t, time.Minute, auth.window)
+				assert.IsType(t, &tokenBucketCounter{}, auth.counter)
+				assert.False(t, auth.ContinueOnError())
+			},
+		},
+		"with sliding window algorithm": {
+			config: []byte(`
+key: "{{ index .Request.ClientIPAddresses 0 }}"
+limit: 10
+window: 1m
+algorithm: sliding_window
+`),
+			assert: func(t *testing.T, err error, auth *rateLimitAuthorizer) {
+				t.Helper()
+
+				require.NoError(t, err)
+				assert.Equal(t, rateLimitAlgorithmSlidingWindow, auth.algorithm)
+				assert.IsType(t, &slidingWindowCounter{}, auth.counter)
+			},
+		},
+	} {
+		t.Run(uc, func(t *testing.T) {
+			// GIVEN
+			conf, err := testsupport.DecodeTestConfig(tc.config)
+			require.NoError(t, err)
+
+			validator, err := validation.NewValidator()
+			require.NoError(t, err)
+
+			cacheType := tc.cacheType
+			if len(cacheType) == 0 {
+				cacheType = "in-memory"
+			}
+
+			appCtx := app.NewContextMock(t)
+			appCtx.EXPECT().Validator().Maybe().Return(validator)
+			appCtx.EXPECT().Logger().Return(log.Logger)
+			appCtx.EXPECT().Config().Maybe().Return(&config.Configuration{Cache: config.CacheConfig{Type: cacheType}})
+
+			// WHEN
+			a, err := newRateLimitAuthorizer(appCtx, uc, conf)
+
+			// THEN
+			tc.assert(t, err, a)
+		})
+	}
+}
+
+func TestCreateRateLimitAuthorizerFromPrototype(t *testing.T) {
+	t.Parallel()
+
+	for uc, tc := range map[string]struct {
+		prototypeConfig []byte
+		config          []byte
+		assert          func(t *testing.T, err error, prototype *rateLimitAuthorizer, configured *rateLimitAuthorizer)
+	}{
+		"no new configuration provided": {
+			prototypeConfig: []byte(`
+key: "{{ .Subject.ID }}"
+limit: 10
+window: 1m
+`),
+			assert: func(t *testing.T, err error, prototype *rateLimitAuthorizer, configured *rateLimitAuthorizer) {
+				t.Helper()
+
+				require.NoError(t, err)
+				assert.Equal(t, prototype, configured)
+			},
+		},
+		"with invalid configuration": {
+			prototypeConfig: []byte(`
+key: "{{ .Subject.ID }}"
+limit: 10
+window: 1m
+`),
+			config: []byte(`algorithm: foo`),
+			assert: func(t *testing.T, err error, _ *rateLimitAuthorizer, _ *rateLimitAuthorizer) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrConfiguration)
+			},
+		},
+		"with limit and algorithm overridden": {
+			prototypeConfig: []byte(`
+key: "{{ .Subject.ID }}"
+limit: 10
+window: 1m
+`),
+			config: []byte(`
+limit: 100
+algorithm: sliding_window
+`),
+			assert: func(t *testing.T, err error, prototype *rateLimitAuthorizer, configured *rateLimitAuthorizer) {
+				t.Helper()
+
+				require.NoError(t, err)
+				assert.NotEqual(t, prototype, configured)
+				assert.Equal(t, prototype.ID(), configured.ID())
+				assert.Equal(t, prototype.key, configured.key)
+				assert.Equal(t, prototype.window, configured.window)
+				assert.Equal(t, 100, configured.limit)
+				assert.Equal(t, rateLimitAlgorithmSlidingWindow, configured.algorithm)
+				assert.IsType(t, &slidingWindowCounter{}, configured.counter)
+			},
+		},
+		"with key and window overridden": {
+			prototypeConfig: []byte(`
+key: "{{ .Subject.ID }}"
+limit: 10
+window: 1m
+`),
+			config: []byte(`
+key: "{{ .Request.Header \"X-Api-Key\" }}"
+window: 1h
+`),
+			assert: func(t *testing.T, err error, prototype *rateLimitAuthorizer, configured *rateLimitAuthorizer) {
+				t.Helper()
+
+				require.NoError(t, err)
+				assert.NotEqual(t, prototype.key, configured.key)
+				assert.Equal(t, time.Hour, configured.window)
+				assert.Equal(t, prototype.limit, configured.limit)
+				assert.Equal(t, prototype.algorithm, configured.algorithm)
+			},
+		},
+	} {
+		t.Run(uc, func(t *testing.T) {
+			// GIVEN
+			pc, err := testsupport.DecodeTestConfig(tc.prototypeConfig)
+			require.NoError(t, err)
+
+			conf, err := testsupport.DecodeTestConfig(tc.config)
+			require.NoError(t, err)
+
+			validator, err := validation.NewValidator()
+			require.NoError(t, err)
+
+			appCtx := app.NewContextMock(t)
+			appCtx.EXPECT().Validator().Maybe().Return(validator)
+			appCtx.EXPECT().Logger().Return(log.Logger)
+			appCtx.EXPECT().Config().Return(&config.Configuration{Cache: config.CacheConfig{Type: "in-memory"}})
+
+			prototype, err := newRateLimitAuthorizer(appCtx, uc, pc)
+			require.NoError(t, err)
+
+			// WHEN
+			auth, err := prototype.WithConfig(conf)
+
+			// THEN
+			var configured *rateLimitAuthorizer
+			if err == nil {
+				var ok bool
+
+				configured, ok = auth.(*rateLimitAuthorizer)
+				require.True(t, ok)
+			}
+
+			tc.assert(t, err, prototype, configured)
+		})
+	}
+}
+
+func TestRateLimitAuthorizerExecute(t *testing.T) {
+	t.Parallel()
+
+	for uc, tc := range map[string]struct {
+		config   []byte
+		cache    cache.Cache
+		requests int
+		assert   func(t *testing.T, err error)
+	}{
+		"failing key rendering": {
+			config: []byte(`
+key: "{{ .Subject.Foo }}"
+limit: 1
+window: 1h
+`),
+			requests: 1,
+			assert: func(t *testing.T, err error) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrInternal)
+				assert.Contains(t, err.Error(), "failed to render rate limit key")
+
+				var identifier interface{ ID() string }
+				require.ErrorAs(t, err, &identifier)
+				assert.Equal(t, "failing key rendering", identifier.ID())
+			},
+		},
+		"failing counter update": {
+			config: []byte(`
+key: "{{ .Subject.ID }}"
+limit: 1
+window: 1h
+`),
+			cache:    &noop.Cache{},
+			requests: 1,
+			assert: func(t *testing.T, err error) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrCommunication)
+				require.ErrorIs(t, err, noop.ErrCompareAndSwapNotSupported)
+				require.NotErrorIs(t, err, &heimdall.TooManyRequestsError{})
+				assert.Contains(t, err.Error(), "failed to update rate limit counter")
+
+				var identifier interface{ ID() string }
+				require.ErrorAs(t, err, &identifier)
+				assert.Equal(t, "failing counter update", identifier.ID())
+			},
+		},
+		"within limit": {
+			config: []byte(`
+key: "{{ .Subject.ID }}"
+limit: 3
+window: 1h
+`),
+			requests: 3,
+			assert: func(t *testing.T, err error) {
+				t.Helper()
+
+				require.NoError(t, err)
+			},
+		},
+		"limit exceeded": {
+			config: []byte(`
+key: "{{ .Subject.ID }}"
+limit: 2
+window: 1h
+algorithm: sliding_window
+`),
+			requests: 3,
+			assert: func(t *testing.T, err error) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, &heimdall.TooManyRequestsError{})
+				assert.Contains(t, err.Error(), "rate limit of 2 requests per 1h0m0s exceeded")
+
+				var tmrErr *heimdall.TooManyRequestsError
+				require.ErrorAs(t, err, &tmrErr)
+				assert.Equal(t, 2, tmrErr.Limit)
+				assert.Equal(t, 0, tmrErr.Remaining)
+				assert.Positive(t, tmrErr.RetryAfter)
+
+				var identifier interface{ ID() string }
+				require.ErrorAs(t, err, &identifier)
+				assert.Equal(t, "limit exceeded", identifier.ID())
+			},
+		},
+	} {
+		t.Run(uc, func(t *testing.T) {
+			// GIVEN
+			conf, err := testsupport.DecodeTestConfig(tc.config)
+			require.NoError(t, err)
+
+			cch := tc.cache
+			if cch == nil {
+				cch, err = memory.NewCache(nil, nil)
+				require.NoError(t, err)
+			}
+
+			ctx := mocks.NewRequestContextMock(t)
+			ctx.EXPECT().Context().Return(cache.WithContext(t.Context(), cch))
+			ctx.EXPECT().Request().Return(&heimdall.Request{})
+			ctx.EXPECT().Outputs().Return(map[string]any{})
+
+			validator, err := validation.NewValidator()
+			require.NoError(t, err)
+
+			appCtx := app.NewContextMock(t)
+			appCtx.EXPECT().Validator().Maybe().Return(validator)
+			appCtx.EXPECT().Logger().Return(log.Logger)
+			appCtx.EXPECT().Config().Return(&config.Configuration{Cache: config.CacheConfig{Type: "in-memory"}})
+
+			auth, err := newRateLimitAuthorizer(appCtx, uc, conf)
+			require.NoError(t, err)
+
+			sub := &subject.Subject{ID: "foo"}
+
+			// WHEN
+			for range tc.requests - 1 {
+				err = auth.Execute(ctx, sub)
+				require.NoError(t, err)
+			}
+
+			err = auth.Execute(ctx, sub)
+
+			// THEN
+			tc.assert(t, err)
+		})
+	}
+}
+
+func TestRateLimitAuthorizerExecuteOnRevalidation(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	conf, err := testsupport.DecodeTestConfig([]byte(`
+key: "{{ .Subject.ID }}"
+limit: 1
+window: 1h
+`))
+	require.NoError(t, err)
+
+	cch, err := memory.NewCache(nil, nil)
+	require.NoError(t, err)
+
+	validator, err := validation.NewValidator()
+	require.NoError(t, err)
+
+	appCtx := app.NewContextMock(t)
+	appCtx.EXPECT().Validator().Maybe().Return(validator)
+	appCtx.EXPECT().Logger().Return(log.Logger)
+	appCtx.EXPECT().Config().Return(&config.Configuration{Cache: config.CacheConfig{Type: "in-memory"}})
+
+	auth, err := newRateLimitAuthorizer(appCtx, "test", conf)
+	require.NoError(t, err)
+
+	ctx := mocks.NewRequestContextMock(t)
+	ctx.EXPECT().Context().Return(cache.WithContext(t.Context(), cch))
+	ctx.EXPECT().Request().Return(&heimdall.Request{})
+	ctx.EXPECT().Outputs().Return(map[string]any{})
+
+	revalidationCtx := mocks.NewRequestContextMock(t)
+	revalidationCtx.EXPECT().Context().Return(heimdall.WithRevalidation(cache.WithContext(t.Context(), cch)))
+
+	sub := &subject.Subject{ID: "foo"}
+
+	// WHEN
+	err1 := auth.Execute(ctx, sub)
+	err2 := auth.Execute(revalidationCtx, sub)
+	err3 := auth.Execute(revalidationCtx, sub)
+
+	// THEN
+	require.NoError(t, err1)
+	require.NoError(t, err2)
+	require.NoError(t, err3)
+}
@@ -0,0 +1,227 @@
+// Copyright 2025 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package authorizers
+
+import (
+	"context"
+	"math"
+	"time"
+
+	"github.com/goccy/go-json"
+
+	"github.com/dadrus/heimdall/internal/cache"
+)
+
+const (
+	rateLimitAlgorithmTokenBucket   = "token_bucket"
+	rateLimitAlgorithmSlidingWindow = "sliding_window"
+)
+
+type rateLimitDecision struct {
+	allowed    bool
+	remaining  int
+	reset      time.Duration
+	retryAfter time.Duration
+}
+
+// maxRateLimitUpdateAttempts is the number of attempts to update the state of a counter,
+// before the request is rejected, if the state is concurrently updated by other requests.
+const maxRateLimitUpdateAttempts = 16
+
+// rateLimitCounter implements a particular rate limiting algorithm. The state of the counter
+// is kept in the given cache, so that it can be shared between heimdall instances if a
+// distributed cache is used. The state is updated using optimistic locking, so that
+// concurrent requests cannot exceed the limit.
+type rateLimitCounter interface {
+	take(ctx context.Context, cch cache.Cache, key string, now time.Time) (rateLimitDecision, error)
+}
+
+func newRateLimitCounter(algorithm string, limit int, window time.Duration) rateLimitCounter {
+	if algorithm == rateLimitAlgorithmSlidingWindow {
+		return &slidingWindowCounter{limit: limit, window: window}
+	}
+
+	return &tokenBucketCounter{limit: limit, window: window}
+}
+
+type tokenBucketState struct {
+	Tokens  float64 `json:"tokens"`
+	Updated int64   `json:"updated"`
+}
+
+// tokenBucketCounter allows bursts of up to limit requests and refills the bucket
+// continuously with limit tokens per window.
+type tokenBucketCounter struct {
+	limit  int
+	window time.Duration
+}
+
+func (c *tokenBucketCounter) take(
+	ctx context.Context, cch cache.Cache, key string, now time.Time,
+) (rateLimitDecision, error) {
+	return updateCounterState(ctx, cch, key, func(data []byte) (rateLimitDecision, []byte, time.Duration) {
+		var decision rateLimitDecision
+
+		state := tokenBucketState{Tokens: float64(c.limit), Updated: now.UnixNano()}
+
+		if data != nil {
+			var stored tokenBucketState
+
+			if err := json.Unmarshal(data, &stored); err == nil {
+				state = stored
+			}
+		}
+
+		// tokens per nanosecond
+		rate := float64(c.limit) / float64(c.window)
+
+		if elapsed := now.UnixNano() - state.Updated; elapsed > 0 {
+			state.Tokens = math.Min(float64(c.limit), state.Tokens+float64(elapsed)*rate)
+		}
+
+		state.Updated = now.UnixNano()
+
+		if state.Tokens >= 1 {
+			state.Tokens--
+			decision.allowed = true
+		} else {
+			decision.retryAfter = time.Duration((1 - state.Tokens) / rate)
+		}
+
+		decision.remaining = int(math.Floor(state.Tokens))
+		decision.reset = time.Duration((float64(c.limit) - state.Tokens) / rate)
+
+		updated, _ := json.Marshal(state)
+
+		return decision, updated, max(decision.reset, time.Second)
+	})
+}
+
+type slidingWindowState struct {
+	Start    int64 `json:"start"`
+	Previous int   `json:"previous"`
+	Current  int   `json:"current"`
+}
+
+// slidingWindowCounter approximates a sliding window by weighting the count of the
+// previous fixed window with the part of it, still overlapping the sliding one.
+type slidingWindowCounter struct {
+	limit  int
+	window time.Duration
+}
+
+func (c *slidingWindowCounter) take(
+	ctx context.Context, cch cache.Cache, key string, now time.Time,
+) (rateLimitDecision, error) {
+	return updateCounterState(ctx, cch, key, func(data []byte) (rateLimitDecision, []byte, time.Duration) {
+		var decision rateLimitDecision
+
+		start := now.Truncate(c.window)
+		state := slidingWindowState{Start: start.UnixNano()}
+
+		if data != nil {
+			var stored slidingWindowState
+
+			if err := json.Unmarshal(data, &stored); err == nil {
+				switch stored.Start {
+				case state.Start:
+					state = stored
+				case start.Add(-c.window).UnixNano():
+					state.Previous = stored.Current
+				}
+			}
+		}
+
+		elapsed := now.Sub(start)
+		weight := 1 - float64(elapsed)/float64(c.window)
+		estimate := float64(state.Previous)*weight + float64(state.Current)
+
+		if estimate+1 <= float64(c.limit) {
+			state.Current++
+			estimate++
+			decision.allowed = true
+		} else {
+			decision.retryAfter = c.retryAfter(state, elapsed)
+		}
+
+		decision.remaining = max(0, int(math.Floor(float64(c.limit)-estimate)))
+		decision.reset = c.window - elapsed
+
+		updated, _ := json.Marshal(state)
+
+		return decision, updated, 2 * c.window //nolint:mnd
+	})
+}
+
+func (c *slidingWindowCounter) retryAfter(state slidingWindowState, elapsed time.Duration) time.Duration {
+	// if the current window is exhausted, there is nothing to wait for, but the next window
+	if state.Current+1 > c.limit || state.Previous == 0 {
+		return c.window - elapsed
+	}
+
+	// point in time within the current window, at which the weighted count of the
+	// previous window has decreased enough to allow one further request
+	ratio := float64(c.limit-state.Current-1) / float64(state.Previous)
+	allowedAt := time.Duration(float64(c.window) * (1 - ratio))
+
+	return max(allowedAt-elapsed, 0)
+}
+
+// updateCounterState computes the decision and the new state of a counter from its current
+// state stored under the given key and stores the new state if the request is allowed. If the
+// state has been updated concurrently, the computation is repeated with the updated state.
+func updateCounterState(
+	ctx context.Context,
+	cch cache.Cache,
+	key string,
+	compute func(data []byte) (rateLimitDecision, []byte, time.Duration),
+) (rateLimitDecision, error) {
+	var decision rateLimitDecision
+
+	for range maxRateLimitUpdateAttempts {
+		current, err := cch.Get(ctx, key)
+		if err != nil {
+			current = nil
+		}
+
+		var (
+			updated []byte
+			ttl     time.Duration
+		)
+
+		decision, updated, ttl = compute(current)
+		if !decision.allowed {
+			// rejected requests do not consume anything
+			return decision, nil
+		}
+
+		swapped, err := cch.CompareAndSwap(ctx, key, current, updated, ttl)
+		if err != nil {
+			return rateLimitDecision{}, err
+		}
+
+		if swapped {
+			return decision, nil
+		}
+	}
+
+	// fail closed if the state could not be updated
+	decision.allowed = false
+	decision.remaining = 0
+
+	return decision, nil
+}
@@ -0,0 +1,164 @@
+// Copyright 2025 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package authorizers
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dadrus/heimdall/internal/cache/memory"
+)
+
+func TestTokenBucketCounterTake(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	cch, err := memory.NewCache(nil, nil)
+	require.NoError(t, err)
+
+	counter := newRateLimitCounter(rateLimitAlgorithmTokenBucket, 2, 10*time.Second)
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	// WHEN & THEN
+	decision, err := counter.take(t.Context(), cch, "foo", now)
+	require.NoError(t, err)
+	assert.True(t, decision.allowed)
+	assert.Equal(t, 1, decision.remaining)
+
+	decision, err = counter.take(t.Context(), cch, "foo", now)
+	require.NoError(t, err)
+	assert.True(t, decision.allowed)
+	assert.Equal(t, 0, decision.remaining)
+	assert.Equal(t, 10*time.Second, decision.reset)
+
+	decision, err = counter.take(t.Context(), cch, "foo", now.Add(time.Second))
+	require.NoError(t, err)
+	assert.False(t, decision.allowed)
+	assert.Equal(t, 0, decision.remaining)
+	assert.Equal(t, 4*time.Second, decision.retryAfter)
+
+	decision, err = counter.take(t.Context(), cch, "bar", now.Add(time.Second))
+	require.NoError(t, err)
+	assert.True(t, decision.allowed)
+
+	decision, err = counter.take(t.Context(), cch, "foo", now.Add(5*time.Second))
+	require.NoError(t, err)
+	assert.True(t, decision.allowed)
+	assert.Equal(t, 0, decision.remaining)
+}
+
+func TestSlidingWindowCounterTake(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	cch, err := memory.NewCache(nil, nil)
+	require.NoError(t, err)
+
+	counter := newRateLimitCounter(rateLimitAlgorithmSlidingWindow, 2, 10*time.Second)
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	// WHEN & THEN
+	decision, err := counter.take(t.Context(), cch, "foo", start.Add(2*time.Second))
+	require.NoError(t, err)
+	assert.True(t, decision.allowed)
+	assert.Equal(t, 1, decision.remaining)
+	assert.Equal(t, 8*time.Second, decision.reset)
+
+	decision, err = counter.take(t.Context(), cch, "foo", start.Add(3*time.Second))
+	require.NoError(t, err)
+	assert.True(t, decision.allowed)
+	assert.Equal(t, 0, decision.remaining)
+
+	decision, err = counter.take(t.Context(), cch, "foo", start.Add(4*time.Second))
+	require.NoError(t, err)
+	assert.False(t, decision.allowed)
+	assert.Equal(t, 6*time.Second, decision.retryAfter)
+
+	// previous window is weighted with 0.5, so one request is possible
+	decision, err = counter.take(t.Context(), cch, "foo", start.Add(15*time.Second))
+	require.NoError(t, err)
+	assert.True(t, decision.allowed)
+	assert.Equal(t, 0, decision.remaining)
+
+	decision, err = counter.take(t.Context(), cch, "foo", start.Add(16*time.Second))
+	require.NoError(t, err)
+	assert.False(t, decision.allowed)
+	assert.Equal(t, 4*time.Second, decision.retryAfter)
+
+	// windows not adjacent, so nothing is taken over
+	decision, err = counter.take(t.Context(), cch, "foo", start.Add(35*time.Second))
+	require.NoError(t, err)
+	assert.True(t, decision.allowed)
+	assert.Equal(t, 1, decision.remaining)
+}
+
+func TestRateLimitCounterTakeConcurrently(t *testing.T) {
+	t.Parallel()
+
+	const (
+		limit    = 10
+		requests = 200
+	)
+
+	for _, algorithm := range []string{rateLimitAlgorithmTokenBucket, rateLimitAlgorithmSlidingWindow} {
+		t.Run(algorithm, func(t *testing.T) {
+			t.Parallel()
+
+			// GIVEN
+			cch, err := memory.NewCache(nil, nil)
+			require.NoError(t, err)
+
+			counter := newRateLimitCounter(algorithm, limit, time.Minute)
+			now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+			var (
+				admitted atomic.Int32
+				wg       sync.WaitGroup
+				start    = make(chan struct{})
+			)
+
+			// WHEN
+			for range requests {
+				wg.Add(1)
+
+				go func() {
+					defer wg.Done()
+
+					<-start
+
+					decision, err := counter.take(t.Context(), cch, "foo", now)
+					assert.NoError(t, err)
+
+					if decision.allowed {
+						admitted.Add(1)
+					}
+				}()
+			}
+
+			close(start)
+			wg.Wait()
+
+			// THEN
+			assert.Equal(t, int32(limit), admitted.Load())
+		})
+	}
+}
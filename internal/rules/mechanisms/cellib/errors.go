@@ -167,5 +167,7 @@ func (errorsLib) CompileOptions() []cel.EnvOption {
 			ErrorType{types: []error{heimdall.ErrInternal, heimdall.ErrConfiguration}}),
 		cel.Constant("precondition_error", cel.DynType,
 			ErrorType{types: []error{heimdall.ErrArgument}}),
+		cel.Constant("too_many_requests_error", cel.DynType,
+			ErrorType{types: []error{&heimdall.TooManyRequestsError{}}}),
 	}
 }
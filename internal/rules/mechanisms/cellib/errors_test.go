@@ -51,6 +51,7 @@ func TestErrors(t *testing.T) {
 		`type(Error) != precondition_error`,
 		`precondition_error != type(Error)`,
 		`type(Error) != communication_error`,
+		`type(Error) != too_many_requests_error`,
 		`internal_error == internal_error`,
 		`Error.Source == "test"`,
 		`Error == Error`,
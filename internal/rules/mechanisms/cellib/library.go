@@ -44,6 +44,7 @@ func (heimdallLibrary) CompileOptions() []cel.EnvOption {
 		Strings(),
 		Urls(),
 		Requests(),
+		Responses(),
 		Errors(),
 		Networks(),
 		ext.NativeTypes(reflect.TypeOf(&subject.Subject{})),
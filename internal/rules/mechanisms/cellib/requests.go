@@ -83,5 +83,50 @@ func (requestsLib) CompileOptions() []cel.EnvOption {
 				}),
 			),
 		),
+		cel.Function("GraphQL",
+			cel.MemberOverload("request_GraphQL",
+				[]*cel.Type{requestType}, cel.DynType,
+				cel.UnaryBinding(func(lhs ref.Val) ref.Val {
+					// nolint: forcetypeassert
+					req := lhs.Value().(*heimdall.Request)
+
+					op, err := req.GraphQL()
+					if err != nil {
+						return types.WrapErr(err)
+					}
+
+					return types.DefaultTypeAdapter.NativeToValue(graphQLOperationToMap(op))
+				}),
+			),
+		),
+		cel.Function("GraphQLOperations",
+			cel.MemberOverload("request_GraphQLOperations",
+				[]*cel.Type{requestType}, cel.ListType(cel.DynType),
+				cel.UnaryBinding(func(lhs ref.Val) ref.Val {
+					// nolint: forcetypeassert
+					req := lhs.Value().(*heimdall.Request)
+
+					ops, err := req.GraphQLOperations()
+					if err != nil {
+						return types.WrapErr(err)
+					}
+
+					values := make([]any, len(ops))
+					for idx, op := range ops {
+						values[idx] = graphQLOperationToMap(op)
+					}
+
+					return types.DefaultTypeAdapter.NativeToValue(values)
+				}),
+			),
+		),
+	}
+}
+
+func graphQLOperationToMap(op heimdall.GraphQLOperation) map[string]any {
+	return map[string]any{
+		"Name":   op.Name,
+		"Type":   op.Type,
+		"Fields": op.Fields,
 	}
 }
@@ -47,7 +47,10 @@ func TestRequests(t *testing.T) {
 	reqf.EXPECT().Header("bar").Return("baz")
 	reqf.EXPECT().Header("zab").Return("bar;charset=utf-8")
 	reqf.EXPECT().Header("accept").Return("text/html,application/xhtml+xml,application/xml;q=0.9,*/*;q=0.8")
-	reqf.EXPECT().Body().Return(map[string]any{"foo": []any{"bar"}})
+	reqf.EXPECT().Body().Return(map[string]any{
+		"foo":   []any{"bar"},
+		"query": "mutation RemoveUser { removed: deleteUser(id: 1) { id } }",
+	})
 
 	req := &heimdall.Request{
 		RequestFunctions: reqf,
@@ -57,6 +60,9 @@ func TestRequests(t *testing.T) {
 			Captures: map[string]string{"foo": "bar"},
 		},
 		ClientIPAddresses: []string{"127.0.0.1"},
+		Metadata: map[string]any{
+			"route": map[string]any{"heimdall": map[string]any{"tenant": "foo"}},
+		},
 	}
 
 	for _, tc := range []string{
@@ -74,6 +80,11 @@ func TestRequests(t *testing.T) {
 		`["text/html", "application/xml", "application/json"].exists(v, Request.Header("accept").contains(v))`,
 		`Request.ClientIPAddresses in networks("127.0.0.0/24")`,
 		`Request.Body().foo[0] == "bar"`,
+		`Request.Metadata.route.heimdall.tenant == "foo"`,
+		`Request.GraphQL().Type == "mutation"`,
+		`Request.GraphQL().Name == "RemoveUser"`,
+		`"deleteUser" in Request.GraphQL().Fields`,
+		`Request.GraphQLOperations().all(op, op.Type == "mutation")`,
 	} {
 		t.Run(tc, func(t *testing.T) {
 			ast, iss := env.Compile(tc)
@@ -95,3 +106,32 @@ func TestRequests(t *testing.T) {
 		})
 	}
 }
+
+func TestRequestsGraphQLWithUninterpretableRequest(t *testing.T) {
+	t.Parallel()
+
+	env, err := cel.NewEnv(Requests())
+	require.NoError(t, err)
+
+	reqf := mocks.NewRequestFunctionsMock(t)
+	reqf.EXPECT().Body().Return(map[string]any{"query": "query A { a } mutation B { b }"})
+
+	req := &heimdall.Request{RequestFunctions: reqf, Method: http.MethodPost}
+
+	for _, tc := range []string{
+		`Request.GraphQL().Type == "query"`,
+		`Request.GraphQLOperations().all(op, op.Type == "query")`,
+	} {
+		t.Run(tc, func(t *testing.T) {
+			ast, iss := env.Compile(tc)
+			require.NoError(t, iss.Err())
+
+			prg, err := env.Program(ast)
+			require.NoError(t, err)
+
+			_, _, err = prg.Eval(map[string]any{"Request": req})
+			require.Error(t, err)
+			require.ErrorContains(t, err, "no operation name is specified")
+		})
+	}
+}
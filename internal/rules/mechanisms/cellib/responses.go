@@ -0,0 +1,64 @@
+// Copyright 2025 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package cellib
+
+import (
+	"reflect"
+
+	"github.com/google/cel-go/cel"
+	"github.com/google/cel-go/common/types"
+	"github.com/google/cel-go/common/types/ref"
+	"github.com/google/cel-go/common/types/traits"
+	"github.com/google/cel-go/ext"
+
+	"github.com/dadrus/heimdall/internal/heimdall"
+)
+
+func Responses() cel.EnvOption {
+	return cel.Lib(responsesLib{})
+}
+
+type responsesLib struct{}
+
+func (responsesLib) LibraryName() string {
+	return "dadrus.heimdall.responses"
+}
+
+func (responsesLib) ProgramOptions() []cel.ProgramOption {
+	return []cel.ProgramOption{}
+}
+
+func (responsesLib) CompileOptions() []cel.EnvOption {
+	responseType := cel.ObjectType(reflect.TypeOf(heimdall.Response{}).String(), traits.ReceiverType)
+
+	return []cel.EnvOption{
+		ext.NativeTypes(reflect.TypeOf(&heimdall.Response{})),
+		cel.Variable("Response", cel.DynType),
+		cel.Function("Header",
+			cel.MemberOverload("response_Header",
+				[]*cel.Type{responseType, cel.StringType}, cel.StringType,
+				cel.BinaryBinding(func(lhs ref.Val, rhs ref.Val) ref.Val {
+					// nolint: forcetypeassert
+					resp := lhs.Value().(*heimdall.Response)
+
+					// nolint: forcetypeassert
+					return types.String(resp.Header.Get(rhs.Value().(string)))
+				}),
+			),
+		),
+	}
+}
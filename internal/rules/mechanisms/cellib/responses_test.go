@@ -0,0 +1,70 @@
+// Copyright 2025 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package cellib
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/google/cel-go/cel"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dadrus/heimdall/internal/heimdall"
+)
+
+func TestResponses(t *testing.T) {
+	t.Parallel()
+
+	env, err := cel.NewEnv(
+		Responses(),
+	)
+	require.NoError(t, err)
+
+	resp := &heimdall.Response{
+		StatusCode: http.StatusOK,
+		Header: http.Header{
+			"Content-Type": []string{"application/json"},
+			"X-Labels":     []string{"foo", "bar"},
+		},
+	}
+
+	for _, tc := range []string{
+		`Response.StatusCode == 200`,
+		`Response.Header("content-type") == "application/json"`,
+		`Response.Header("X-Labels") == "foo"`,
+		`Response.Header("X-Foo") == ""`,
+	} {
+		t.Run(tc, func(t *testing.T) {
+			ast, iss := env.Compile(tc)
+			if iss != nil {
+				require.NoError(t, iss.Err())
+			}
+
+			ast, iss = env.Check(ast)
+			if iss != nil {
+				require.NoError(t, iss.Err())
+			}
+
+			prg, err := env.Program(ast, cel.EvalOptions(cel.OptOptimize))
+			require.NoError(t, err)
+
+			out, _, err := prg.Eval(map[string]any{"Response": resp})
+			require.NoError(t, err)
+			require.Equal(t, true, out.Value()) //nolint:testifylint
+		})
+	}
+}
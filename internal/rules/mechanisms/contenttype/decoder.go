@@ -29,6 +29,8 @@ type Decoder interface {
 
 func NewDecoder(contentType string) (Decoder, error) {
 	switch {
+	case strings.Contains(contentType, "multipart/form-data"):
+		return newMultipartFormDataDecoder(contentType)
 	case strings.Contains(contentType, "json"):
 		return JSONDecoder{}, nil
 	case strings.Contains(contentType, "application/x-www-form-urlencoded"):
@@ -39,3 +41,19 @@ func NewDecoder(contentType string) (Decoder, error) {
 		return nil, ErrUnsupportedContentType
 	}
 }
+
+// DecodeBody decodes the given body according to the given content type. If the content type
+// is not supported, or the body cannot be decoded, the body is returned as string.
+func DecodeBody(contentType string, body []byte) any {
+	decoder, err := NewDecoder(contentType)
+	if err != nil {
+		return string(body)
+	}
+
+	data, err := decoder.Decode(body)
+	if err != nil {
+		return string(body)
+	}
+
+	return data
+}
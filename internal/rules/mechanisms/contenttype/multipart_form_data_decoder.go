@@ -0,0 +1,87 @@
+// Copyright 2025 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package contenttype
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"mime"
+	"mime/multipart"
+
+	"github.com/dadrus/heimdall/internal/x/stringx"
+)
+
+var ErrMissingBoundary = errors.New("no boundary present")
+
+type MultipartFormDataDecoder struct {
+	Boundary string
+}
+
+func newMultipartFormDataDecoder(contentType string) (Decoder, error) {
+	_, params, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return nil, err
+	}
+
+	boundary, ok := params["boundary"]
+	if !ok || len(boundary) == 0 {
+		return nil, ErrMissingBoundary
+	}
+
+	return MultipartFormDataDecoder{Boundary: boundary}, nil
+}
+
+// Decode returns the values of all named parts. As with url encoded forms, each name is mapped to a list
+// of values. Parts with a content type, which can be decoded by any other decoder, e.g. JSON parts, are
+// represented by their decoded form. All other parts are represented by their raw content.
+func (d MultipartFormDataDecoder) Decode(rawData []byte) (map[string]any, error) {
+	reader := multipart.NewReader(bytes.NewReader(rawData), d.Boundary)
+	result := make(map[string]any)
+
+	for {
+		part, err := reader.NextPart()
+		if errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+
+		name := part.FormName()
+		if len(name) == 0 {
+			continue
+		}
+
+		content, err := io.ReadAll(part)
+		if err != nil {
+			return nil, err
+		}
+
+		var value any = stringx.ToString(content)
+
+		if decoder, err := NewDecoder(part.Header.Get("Content-Type")); err == nil {
+			if decoded, err := decoder.Decode(content); err == nil {
+				value = decoded
+			}
+		}
+
+		values, _ := result[name].([]any)
+		result[name] = append(values, value)
+	}
+
+	return result, nil
+}
@@ -0,0 +1,96 @@
+// Copyright 2025 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package contenttype
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMultipartFormDataDecoderDecode(t *testing.T) {
+	t.Parallel()
+
+	for uc, tc := range map[string]struct {
+		contentType string
+		body        string
+		assert      func(t *testing.T, err error, result map[string]any)
+	}{
+		"without boundary": {
+			contentType: "multipart/form-data",
+			assert: func(t *testing.T, err error, _ map[string]any) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, ErrMissingBoundary)
+			},
+		},
+		"with malformed body": {
+			contentType: "multipart/form-data; boundary=foo",
+			body:        "--foo\r\nContent-Disposition",
+			assert: func(t *testing.T, err error, _ map[string]any) {
+				t.Helper()
+
+				require.Error(t, err)
+			},
+		},
+		"with text, json and unnamed parts": {
+			contentType: "multipart/form-data; boundary=foo",
+			body: "--foo\r\n" +
+				"Content-Disposition: form-data; name=\"token\"\r\n\r\n" +
+				"bar\r\n" +
+				"--foo\r\n" +
+				"Content-Disposition: form-data; name=\"token\"\r\n\r\n" +
+				"baz\r\n" +
+				"--foo\r\n" +
+				"Content-Disposition: form-data; name=\"metadata\"\r\n" +
+				"Content-Type: application/json\r\n\r\n" +
+				"{\"auth\":{\"token\":\"zab\"}}\r\n" +
+				"--foo\r\n" +
+				"Content-Disposition: attachment\r\n\r\n" +
+				"ignored\r\n" +
+				"--foo--\r\n",
+			assert: func(t *testing.T, err error, result map[string]any) {
+				t.Helper()
+
+				require.NoError(t, err)
+				require.Len(t, result, 2)
+				assert.Equal(t, []any{"bar", "baz"}, result["token"])
+				assert.Equal(t, []any{map[string]any{"auth": map[string]any{"token": "zab"}}}, result["metadata"])
+			},
+		},
+	} {
+		t.Run(uc, func(t *testing.T) {
+			t.Parallel()
+
+			// GIVEN
+			decoder, err := NewDecoder(tc.contentType)
+			if err != nil {
+				tc.assert(t, err, nil)
+
+				return
+			}
+
+			// WHEN
+			result, err := decoder.Decode([]byte(tc.body))
+
+			// THEN
+			tc.assert(t, err, result)
+		})
+	}
+}
@@ -23,6 +23,7 @@ import (
 	"github.com/dadrus/heimdall/internal/rules/endpoint"
 	"github.com/dadrus/heimdall/internal/rules/endpoint/authstrategy"
 	"github.com/dadrus/heimdall/internal/rules/mechanisms/template"
+	"github.com/dadrus/heimdall/internal/truststore"
 )
 
 func decodeConfig(app app.Context, input, output any) error {
@@ -33,6 +34,7 @@ func decodeConfig(app app.Context, input, output any) error {
 				endpoint.DecodeEndpointHookFunc(),
 				mapstructure.StringToTimeDurationHookFunc(),
 				template.DecodeTemplateHookFunc(),
+				truststore.DecodeTrustStoreHookFunc(),
 			),
 			Result:      output,
 			ErrorUnused: true,
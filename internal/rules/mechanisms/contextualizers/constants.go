@@ -18,4 +18,6 @@ package contextualizers
 
 const (
 	ContextualizerGeneric = "generic"
+	ContextualizerLDAP    = "ldap"
+	ContextualizerSQL     = "sql"
 )
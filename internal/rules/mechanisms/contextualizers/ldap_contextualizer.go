@@ -0,0 +1,292 @@
+// Copyright 2025 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package contextualizers
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"time"
+
+	ldapv3 "github.com/go-ldap/ldap/v3"
+	"github.com/goccy/go-json"
+	"github.com/rs/zerolog"
+
+	"github.com/dadrus/heimdall/internal/app"
+	"github.com/dadrus/heimdall/internal/cache"
+	"github.com/dadrus/heimdall/internal/heimdall"
+	"github.com/dadrus/heimdall/internal/rules/mechanisms/ldap"
+	"github.com/dadrus/heimdall/internal/rules/mechanisms/subject"
+	"github.com/dadrus/heimdall/internal/x"
+	"github.com/dadrus/heimdall/internal/x/errorchain"
+	"github.com/dadrus/heimdall/internal/x/stringx"
+)
+
+// the size limit used for searches. A limit of 1 would not allow
+// detecting ambiguous search results.
+const ldapSearchSizeLimit = 2
+
+// by intention. Used only during application bootstrap
+//
+//nolint:gochecknoinits
+func init() {
+	registerTypeFactory(
+		func(app app.Context, id string, typ string, conf map[string]any) (bool, Contextualizer, error) {
+			if typ != ContextualizerLDAP {
+				return false, nil, nil
+			}
+
+			eh, err := newLDAPContextualizer(app, id, conf)
+
+			return true, eh, err
+		})
+}
+
+type ldapContextualizerData struct {
+	DN         string              `json:"dn"`
+	Attributes map[string][]string `json:"attributes"`
+	Groups     []string            `json:"groups"`
+}
+
+type ldapContextualizer struct {
+	id              string
+	app             app.Context
+	client          *ldap.Client
+	search          ldap.Search
+	attributes      []string
+	groups          *ldap.Groups
+	ttl             time.Duration
+	continueOnError bool
+}
+
+func newLDAPContextualizer(app app.Context, id string, rawConfig map[string]any) (*ldapContextualizer, error) {
+	logger := app.Logger()
+	logger.Info().Str("_id", id).Msg("Creating ldap contextualizer")
+
+	type Config struct {
+		Server          ldap.Server    `mapstructure:"server"                     validate:"required"`
+		Search          ldap.Search    `mapstructure:"search"                     validate:"required"`
+		Attributes      []string       `mapstructure:"attributes"`
+		Groups          *ldap.Groups   `mapstructure:"groups"`
+		CacheTTL        *time.Duration `mapstructure:"cache_ttl"`
+		ContinueOnError bool           `mapstructure:"continue_pipeline_on_error"`
+	}
+
+	var conf Config
+	if err := decodeConfig(app, rawConfig, &conf); err != nil {
+		return nil, errorchain.NewWithMessagef(heimdall.ErrConfiguration,
+			"failed decoding config for ldap contextualizer '%s'", id).CausedBy(err)
+	}
+
+	client, err := ldap.NewClient(conf.Server)
+	if err != nil {
+		return nil, errorchain.NewWithMessagef(heimdall.ErrConfiguration,
+			"failed creating ldap client for ldap contextualizer '%s'", id).CausedBy(err)
+	}
+
+	if !client.IsSecure() {
+		logger.Warn().Str("_id", id).
+			Msg("No TLS configured for the communication with the ldap server used in ldap contextualizer")
+	}
+
+	ttl := defaultTTL
+	if conf.CacheTTL != nil {
+		ttl = *conf.CacheTTL
+	}
+
+	return &ldapContextualizer{
+		id:              id,
+		app:             app,
+		client:          client,
+		search:          conf.Search,
+		attributes:      conf.Attributes,
+		groups:          conf.Groups,
+		ttl:             ttl,
+		continueOnError: conf.ContinueOnError,
+	}, nil
+}
+
+func (c *ldapContextualizer) Execute(ctx heimdall.RequestContext, sub *subject.Subject) error {
+	logger := zerolog.Ctx(ctx.Context())
+	logger.Debug().Str("_id", c.id).Msg("Updating using ldap contextualizer")
+
+	if sub == nil {
+		return errorchain.NewWithMessage(heimdall.ErrInternal,
+			"failed to execute ldap contextualizer due to 'nil' subject").
+			WithErrorContext(c)
+	}
+
+	// the values of the subject are escaped to prevent ldap injection
+	escaped := &subject.Subject{ID: ldapv3.EscapeFilter(sub.ID)}
+	if attributes, ok := ldap.EscapeFilterValues(sub.Attributes).(map[string]any); ok {
+		escaped.Attributes = attributes
+	}
+
+	req, err := c.search.Request(map[string]any{"Subject": escaped}, c.requestedAttributes())
+	if err != nil {
+		return errorchain.New(heimdall.ErrInternal).WithErrorContext(c).CausedBy(err)
+	}
+
+	req.SizeLimit = ldapSearchSizeLimit
+
+	cch := cache.Ctx(ctx.Context())
+
+	var (
+		cacheKey string
+		data     *ldapContextualizerData
+	)
+
+	if c.ttl > 0 {
+		cacheKey = c.calculateCacheKey(req)
+		if entry, err := cch.Get(ctx.Context(), cacheKey); err == nil {
+			var cd ldapContextualizerData
+
+			if err = json.Unmarshal(entry, &cd); err == nil {
+				logger.Debug().Msg("Reusing contextualizer response from cache")
+
+				data = &cd
+			}
+		}
+	}
+
+	if data == nil {
+		data, err = c.fetchData(req)
+		if err != nil {
+			return err
+		}
+
+		if c.ttl > 0 && len(cacheKey) != 0 {
+			raw, _ := json.Marshal(data)
+
+			if err = cch.Set(ctx.Context(), cacheKey, raw, c.ttl); err != nil {
+				logger.Warn().Err(err).Msg("Failed to cache contextualizer response")
+			}
+		}
+	}
+
+	output := map[string]any{
+		"dn":         data.DN,
+		"attributes": ldap.Entry{DN: data.DN, Attributes: data.Attributes}.AttributeMap(),
+	}
+
+	if c.groups != nil {
+		output["groups"] = ldap.ToAnySlice(data.Groups)
+	}
+
+	ctx.Outputs()[c.id] = output
+
+	return nil
+}
+
+func (c *ldapContextualizer) WithConfig(rawConfig map[string]any) (Contextualizer, error) {
+	if len(rawConfig) == 0 {
+		return c, nil
+	}
+
+	type Config struct {
+		CacheTTL        *time.Duration `mapstructure:"cache_ttl"`
+		ContinueOnError *bool          `mapstructure:"continue_pipeline_on_error"`
+	}
+
+	var conf Config
+	if err := decodeConfig(c.app, rawConfig, &conf); err != nil {
+		return nil, errorchain.NewWithMessagef(heimdall.ErrConfiguration,
+			"failed decoding config for ldap contextualizer '%s'", c.id).CausedBy(err)
+	}
+
+	return &ldapContextualizer{
+		id:         c.id,
+		app:        c.app,
+		client:     c.client,
+		search:     c.search,
+		attributes: c.attributes,
+		groups:     c.groups,
+		ttl: x.IfThenElseExec(conf.CacheTTL != nil,
+			func() time.Duration { return *conf.CacheTTL },
+			func() time.Duration { return c.ttl }),
+		continueOnError: x.IfThenElseExec(conf.ContinueOnError != nil,
+			func() bool { return *conf.ContinueOnError },
+			func() bool { return c.continueOnError }),
+	}, nil
+}
+
+func (c *ldapContextualizer) ID() string { return c.id }
+
+func (c *ldapContextualizer) ContinueOnError() bool { return c.continueOnError }
+
+func (c *ldapContextualizer) fetchData(req ldap.SearchRequest) (*ldapContextualizerData, error) {
+	var data *ldapContextualizerData
+
+	err := c.client.Do(func(conn *ldap.Conn) error {
+		entries, err := conn.Search(req)
+		if err != nil {
+			return err
+		}
+
+		if len(entries) != 1 {
+			return errorchain.NewWithMessagef(heimdall.ErrCommunication,
+				"expected exactly one ldap entry for the subject, got %d", len(entries))
+		}
+
+		data = &ldapContextualizerData{DN: entries[0].DN, Attributes: entries[0].Attributes}
+
+		if c.groups != nil {
+			data.Groups, err = c.groups.MemberOf(conn, entries[0].DN)
+		}
+
+		return err
+	})
+	if err != nil {
+		var chain *errorchain.ErrorChain
+		if errors.As(err, &chain) {
+			return nil, chain.WithErrorContext(c)
+		}
+
+		return nil, errorchain.New(heimdall.ErrInternal).WithErrorContext(c).CausedBy(err)
+	}
+
+	return data, nil
+}
+
+func (c *ldapContextualizer) requestedAttributes() []string {
+	if len(c.attributes) == 0 {
+		return []string{ldap.NoAttributes}
+	}
+
+	return c.attributes
+}
+
+func (c *ldapContextualizer) calculateCacheKey(req ldap.SearchRequest) string {
+	const int64BytesCount = 8
+
+	ttlBytes := make([]byte, int64BytesCount)
+	//nolint:gosec
+	// no integer overflow during conversion possible
+	binary.LittleEndian.PutUint64(ttlBytes, uint64(c.ttl))
+
+	hash := sha256.New()
+	hash.Write(stringx.ToBytes(c.id))
+	hash.Write(stringx.ToBytes(req.BaseDN))
+	hash.Write(stringx.ToBytes(string(req.Scope)))
+	hash.Write(stringx.ToBytes(req.Filter))
+	hash.Write(stringx.ToBytes(strings.Join(req.Attributes, ",")))
+	hash.Write(ttlBytes)
+
+	return hex.EncodeToString(hash.Sum(nil))
+}
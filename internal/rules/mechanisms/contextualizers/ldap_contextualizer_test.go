@@ -0,0 +1,560 @@
+// Copyright 2025 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package contextualizers
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/goccy/go-json"
+	"github.com/rs/zerolog/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dadrus/heimdall/internal/app"
+	"github.com/dadrus/heimdall/internal/cache"
+	"github.com/dadrus/heimdall/internal/cache/mocks"
+	"github.com/dadrus/heimdall/internal/heimdall"
+	heimdallmocks "github.com/dadrus/heimdall/internal/heimdall/mocks"
+	"github.com/dadrus/heimdall/internal/rules/mechanisms/ldap/ldaptest"
+	"github.com/dadrus/heimdall/internal/rules/mechanisms/subject"
+	"github.com/dadrus/heimdall/internal/validation"
+	"github.com/dadrus/heimdall/internal/x"
+	"github.com/dadrus/heimdall/internal/x/testsupport"
+)
+
+func ldapTestEntries() []ldaptest.Entry {
+	return []ldaptest.Entry{
+		{
+			DN:       "cn=service,dc=example,dc=org",
+			Password: "secret",
+		},
+		{
+			DN: "uid=alice,ou=people,dc=example,dc=org",
+			Attributes: map[string][]string{
+				"uid":  {"alice"},
+				"mail": {"alice@example.org"},
+				"cn":   {"Alice"},
+			},
+		},
+		{
+			DN: "uid=bob,ou=people,dc=example,dc=org",
+			Attributes: map[string][]string{
+				"uid":  {"bob"},
+				"mail": {"bob@example.org"},
+			},
+		},
+		{
+			DN: "cn=admins,ou=groups,dc=example,dc=org",
+			Attributes: map[string][]string{
+				"cn":     {"admins"},
+				"member": {"uid=alice,ou=people,dc=example,dc=org"},
+			},
+		},
+	}
+}
+
+// newContextualizerForTest creates a contextualizer using the given constructor and config.
+func newContextualizerForTest[T Contextualizer](
+	t *testing.T,
+	create func(app app.Context, id string, rawConfig map[string]any) (T, error),
+	id string,
+	config []byte,
+) (T, error) {
+	t.Helper()
+
+	conf, err := testsupport.DecodeTestConfig(config)
+	require.NoError(t, err)
+
+	validator, err := validation.NewValidator()
+	require.NoError(t, err)
+
+	appCtx := app.NewContextMock(t)
+	appCtx.EXPECT().Validator().Maybe().Return(validator)
+	appCtx.EXPECT().Logger().Return(log.Logger)
+
+	return create(appCtx, id, conf)
+}
+
+func TestCreateLDAPContextualizer(t *testing.T) {
+	t.Parallel()
+
+	for uc, tc := range map[string]struct {
+		config []byte
+		assert func(t *testing.T, err error, contextualizer *ldapContextualizer)
+	}{
+		"without configuration": {
+			assert: func(t *testing.T, err error, _ *ldapContextualizer) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrConfiguration)
+				assert.Contains(t, err.Error(), "'server' is a required field")
+				assert.Contains(t, err.Error(), "'search' is a required field")
+			},
+		},
+		"with unsupported attributes": {
+			config: []byte(`
+server:
+  url: ldap://localhost:389
+search:
+  base_dn: ou=people,dc=example,dc=org
+  filter: "(uid={{ .Subject.ID }})"
+foo: bar
+`),
+			assert: func(t *testing.T, err error, _ *ldapContextualizer) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrConfiguration)
+				assert.Contains(t, err.Error(), "failed decoding")
+			},
+		},
+		"with search without filter": {
+			config: []byte(`
+server:
+  url: ldap://localhost:389
+search:
+  base_dn: ou=people,dc=example,dc=org
+`),
+			assert: func(t *testing.T, err error, _ *ldapContextualizer) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrConfiguration)
+				assert.Contains(t, err.Error(), "'search'.'filter' is a required field")
+			},
+		},
+		"with unsupported search scope": {
+			config: []byte(`
+server:
+  url: ldap://localhost:389
+search:
+  base_dn: ou=people,dc=example,dc=org
+  filter: "(uid={{ .Subject.ID }})"
+  scope: children
+`),
+			assert: func(t *testing.T, err error, _ *ldapContextualizer) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrConfiguration)
+				assert.Contains(t, err.Error(), "'search'.'scope' must be one of [base one sub]")
+			},
+		},
+		"with ldaps and start_tls": {
+			config: []byte(`
+server:
+  url: ldaps://localhost:636
+  start_tls: true
+search:
+  base_dn: ou=people,dc=example,dc=org
+  filter: "(uid={{ .Subject.ID }})"
+`),
+			assert: func(t *testing.T, err error, _ *ldapContextualizer) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrConfiguration)
+				assert.Contains(t, err.Error(), "failed creating ldap client")
+			},
+		},
+		"with minimal valid configuration": {
+			config: []byte(`
+server:
+  url: ldap://localhost:389
+search:
+  base_dn: ou=people,dc=example,dc=org
+  filter: "(uid={{ .Subject.ID }})"
+`),
+			assert: func(t *testing.T, err error, contextualizer *ldapContextualizer) {
+				t.Helper()
+
+				require.NoError(t, err)
+				require.NotNil(t, contextualizer)
+				assert.Equal(t, "with minimal valid configuration", contextualizer.ID())
+				assert.NotNil(t, contextualizer.client)
+				assert.Equal(t, "ou=people,dc=example,dc=org", contextualizer.search.BaseDN)
+				assert.Empty(t, contextualizer.attributes)
+				assert.Nil(t, contextualizer.groups)
+				assert.Equal(t, defaultTTL, contextualizer.ttl)
+				assert.False(t, contextualizer.ContinueOnError())
+			},
+		},
+		"with full valid configuration": {
+			config: []byte(`
+server:
+  url: ldaps://localhost:636
+  timeout: 5s
+  pool_size: 2
+  bind_dn: cn=service,dc=example,dc=org
+  bind_password: secret
+search:
+  base_dn: ou=people,dc=example,dc=org
+  filter: "(uid={{ .Subject.ID }})"
+  scope: one
+attributes: [ mail, cn ]
+groups:
+  base_dn: ou=groups,dc=example,dc=org
+  filter: "(member={{ .DN }})"
+  attribute: cn
+cache_ttl: 1m
+continue_pipeline_on_error: true
+`),
+			assert: func(t *testing.T, err error, contextualizer *ldapContextualizer) {
+				t.Helper()
+
+				require.NoError(t, err)
+				require.NotNil(t, contextualizer)
+				assert.True(t, contextualizer.client.IsSecure())
+				assert.Equal(t, []string{"mail", "cn"}, contextualizer.attributes)
+				require.NotNil(t, contextualizer.groups)
+				assert.Equal(t, "ou=groups,dc=example,dc=org", contextualizer.groups.BaseDN)
+				assert.Equal(t, 1*time.Minute, contextualizer.ttl)
+				assert.True(t, contextualizer.ContinueOnError())
+			},
+		},
+	} {
+		t.Run(uc, func(t *testing.T) {
+			// WHEN
+			contextualizer, err := newContextualizerForTest(t, newLDAPContextualizer, uc, tc.config)
+
+			// THEN
+			tc.assert(t, err, contextualizer)
+		})
+	}
+}
+
+func TestCreateLDAPContextualizerFromPrototype(t *testing.T) {
+	t.Parallel()
+
+	prototypeConfig := []byte(`
+server:
+  url: ldap://localhost:389
+search:
+  base_dn: ou=people,dc=example,dc=org
+  filter: "(uid={{ .Subject.ID }})"
+attributes: [ mail ]
+`)
+
+	for uc, tc := range map[string]struct {
+		config []byte
+		assert func(t *testing.T, err error, prototype *ldapContextualizer, configured *ldapContextualizer)
+	}{
+		"with empty config": {
+			assert: func(t *testing.T, err error, prototype *ldapContextualizer, configured *ldapContextualizer) {
+				t.Helper()
+
+				require.NoError(t, err)
+				assert.Equal(t, prototype, configured)
+			},
+		},
+		"with unsupported fields": {
+			config: []byte(`attributes: [ cn ]`),
+			assert: func(t *testing.T, err error, _ *ldapContextualizer, _ *ldapContextualizer) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrConfiguration)
+				assert.Contains(t, err.Error(), "failed decoding")
+			},
+		},
+		"with cache ttl and continue_pipeline_on_error reconfigured": {
+			config: []byte(`
+cache_ttl: 1s
+continue_pipeline_on_error: true
+`),
+			assert: func(t *testing.T, err error, prototype *ldapContextualizer, configured *ldapContextualizer) {
+				t.Helper()
+
+				require.NoError(t, err)
+				assert.NotEqual(t, prototype, configured)
+				assert.Equal(t, prototype.ID(), configured.ID())
+				assert.Equal(t, prototype.client, configured.client)
+				assert.Equal(t, prototype.search, configured.search)
+				assert.Equal(t, prototype.attributes, configured.attributes)
+				assert.Equal(t, prototype.groups, configured.groups)
+				assert.Equal(t, defaultTTL, prototype.ttl)
+				assert.Equal(t, 1*time.Second, configured.ttl)
+				assert.False(t, prototype.ContinueOnError())
+				assert.True(t, configured.ContinueOnError())
+			},
+		},
+	} {
+		t.Run(uc, func(t *testing.T) {
+			// GIVEN
+			conf, err := testsupport.DecodeTestConfig(tc.config)
+			require.NoError(t, err)
+
+			prototype, err := newContextualizerForTest(t, newLDAPContextualizer, "contextualizer", prototypeConfig)
+			require.NoError(t, err)
+
+			// WHEN
+			ctxer, err := prototype.WithConfig(conf)
+
+			// THEN
+			var (
+				contextualizer *ldapContextualizer
+				ok             bool
+			)
+
+			if err == nil {
+				contextualizer, ok = ctxer.(*ldapContextualizer)
+				require.True(t, ok)
+			}
+
+			tc.assert(t, err, prototype, contextualizer)
+		})
+	}
+}
+
+func TestLDAPContextualizerExecute(t *testing.T) {
+	t.Parallel()
+
+	srv := ldaptest.NewServer(t, ldaptest.Plain, ldapTestEntries()...)
+
+	config := []byte(`
+server:
+  url: ` + srv.URL + `
+  bind_dn: cn=service,dc=example,dc=org
+  bind_password: secret
+search:
+  base_dn: ou=people,dc=example,dc=org
+  filter: "(uid={{ .Subject.ID }})"
+attributes: [ mail, cn ]
+groups:
+  base_dn: ou=groups,dc=example,dc=org
+  filter: "(member={{ .DN }})"
+`)
+
+	for uc, tc := range map[string]struct {
+		config         []byte
+		subject        *subject.Subject
+		configureCache func(t *testing.T, cch *mocks.CacheMock)
+		assert         func(t *testing.T, err error, searches int, outputs map[string]any)
+	}{
+		"fails due to nil subject": {
+			config: config,
+			assert: func(t *testing.T, err error, searches int, _ map[string]any) {
+				t.Helper()
+
+				assert.Zero(t, searches)
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrInternal)
+				assert.Contains(t, err.Error(), "'nil' subject")
+
+				var identifier interface{ ID() string }
+				require.ErrorAs(t, err, &identifier)
+				assert.Equal(t, "contextualizer", identifier.ID())
+			},
+		},
+		"with successful cache hit": {
+			config:  config,
+			subject: &subject.Subject{ID: "alice"},
+			configureCache: func(t *testing.T, cch *mocks.CacheMock) {
+				t.Helper()
+
+				rawData, err := json.Marshal(&ldapContextualizerData{
+					DN:         "uid=alice,ou=people,dc=example,dc=org",
+					Attributes: map[string][]string{"mail": {"cached@example.org"}},
+					Groups:     []string{"cached"},
+				})
+				require.NoError(t, err)
+
+				cch.EXPECT().Get(mock.Anything, mock.Anything).Return(rawData, nil)
+			},
+			assert: func(t *testing.T, err error, searches int, outputs map[string]any) {
+				t.Helper()
+
+				require.NoError(t, err)
+				assert.Zero(t, searches)
+				assert.Equal(t, map[string]any{
+					"dn":         "uid=alice,ou=people,dc=example,dc=org",
+					"attributes": map[string]any{"mail": []any{"cached@example.org"}},
+					"groups":     []any{"cached"},
+				}, outputs["contextualizer"])
+			},
+		},
+		"with cache miss": {
+			config:  config,
+			subject: &subject.Subject{ID: "alice"},
+			configureCache: func(t *testing.T, cch *mocks.CacheMock) {
+				t.Helper()
+
+				cch.EXPECT().Get(mock.Anything, mock.Anything).Return(nil, errors.New("no cache entry"))
+				cch.EXPECT().Set(mock.Anything, mock.Anything, mock.MatchedBy(func(data []byte) bool {
+					var cd ldapContextualizerData
+
+					return json.Unmarshal(data, &cd) == nil &&
+						cd.DN == "uid=alice,ou=people,dc=example,dc=org" &&
+						len(cd.Groups) == 1 && cd.Groups[0] == "admins"
+				}), defaultTTL).Return(nil)
+			},
+			assert: func(t *testing.T, err error, searches int, outputs map[string]any) {
+				t.Helper()
+
+				require.NoError(t, err)
+				assert.Equal(t, 2, searches)
+				assert.Equal(t, map[string]any{
+					"dn": "uid=alice,ou=people,dc=example,dc=org",
+					"attributes": map[string]any{
+						"mail": []any{"alice@example.org"},
+						"cn":   []any{"Alice"},
+					},
+					"groups": []any{"admins"},
+				}, outputs["contextualizer"])
+			},
+		},
+		"without cache and groups": {
+			config: []byte(`
+server:
+  url: ` + srv.URL + `
+search:
+  base_dn: ou=people,dc=example,dc=org
+  filter: "(uid={{ .Subject.ID }})"
+cache_ttl: 0s
+`),
+			subject: &subject.Subject{ID: "bob"},
+			assert: func(t *testing.T, err error, searches int, outputs map[string]any) {
+				t.Helper()
+
+				require.NoError(t, err)
+				assert.Equal(t, 1, searches)
+				assert.Equal(t, map[string]any{
+					"dn":         "uid=bob,ou=people,dc=example,dc=org",
+					"attributes": map[string]any{},
+				}, outputs["contextualizer"])
+			},
+		},
+		"with subject id trying to inject a filter": {
+			config: []byte(`
+server:
+  url: ` + srv.URL + `
+search:
+  base_dn: ou=people,dc=example,dc=org
+  filter: "(uid={{ .Subject.ID }})"
+cache_ttl: 0s
+`),
+			subject: &subject.Subject{ID: "*"},
+			assert: func(t *testing.T, err error, searches int, _ map[string]any) {
+				t.Helper()
+
+				assert.Equal(t, 1, searches)
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrCommunication)
+				assert.Contains(t, err.Error(), "expected exactly one ldap entry for the subject, got 0")
+
+				var identifier interface{ ID() string }
+				require.ErrorAs(t, err, &identifier)
+				assert.Equal(t, "contextualizer", identifier.ID())
+			},
+		},
+		"with subject attribute trying to inject a filter": {
+			config: []byte(`
+server:
+  url: ` + srv.URL + `
+search:
+  base_dn: ou=people,dc=example,dc=org
+  filter: "(&(uid={{ .Subject.ID }})(mail={{ index .Subject.Attributes.mails 0 }}))"
+cache_ttl: 0s
+`),
+			subject: &subject.Subject{ID: "alice", Attributes: map[string]any{"mails": []any{"*"}}},
+			assert: func(t *testing.T, err error, searches int, _ map[string]any) {
+				t.Helper()
+
+				assert.Equal(t, 1, searches)
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrCommunication)
+				assert.Contains(t, err.Error(), "expected exactly one ldap entry for the subject, got 0")
+			},
+		},
+		"with ambiguous search result": {
+			config: []byte(`
+server:
+  url: ` + srv.URL + `
+search:
+  base_dn: ou=people,dc=example,dc=org
+  filter: "(mail=*{{ .Subject.Attributes.domain }})"
+cache_ttl: 0s
+`),
+			subject: &subject.Subject{ID: "foo", Attributes: map[string]any{"domain": "example.org"}},
+			assert: func(t *testing.T, err error, _ int, _ map[string]any) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrCommunication)
+				assert.Contains(t, err.Error(), "got 2")
+			},
+		},
+		"with invalid service credentials": {
+			config: []byte(`
+server:
+  url: ` + srv.URL + `
+  bind_dn: cn=service,dc=example,dc=org
+  bind_password: wrong
+search:
+  base_dn: ou=people,dc=example,dc=org
+  filter: "(uid={{ .Subject.ID }})"
+cache_ttl: 0s
+`),
+			subject: &subject.Subject{ID: "alice"},
+			assert: func(t *testing.T, err error, _ int, _ map[string]any) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrCommunication)
+				assert.Contains(t, err.Error(), "service bind failed")
+
+				var identifier interface{ ID() string }
+				require.ErrorAs(t, err, &identifier)
+				assert.Equal(t, "contextualizer", identifier.ID())
+			},
+		},
+	} {
+		t.Run(uc, func(t *testing.T) {
+			// GIVEN
+			contextualizer, err := newContextualizerForTest(t, newLDAPContextualizer, "contextualizer", tc.config)
+			require.NoError(t, err)
+
+			configureCache := x.IfThenElse(tc.configureCache != nil,
+				tc.configureCache,
+				func(t *testing.T, _ *mocks.CacheMock) { t.Helper() })
+
+			cch := mocks.NewCacheMock(t)
+			configureCache(t, cch)
+
+			outputs := map[string]any{"foo": "bar"}
+
+			ctx := heimdallmocks.NewRequestContextMock(t)
+			ctx.EXPECT().Context().Return(cache.WithContext(t.Context(), cch))
+			ctx.EXPECT().Outputs().Maybe().Return(outputs)
+
+			searches := srv.Searches()
+
+			// WHEN
+			err = contextualizer.Execute(ctx, tc.subject)
+
+			// THEN
+			tc.assert(t, err, srv.Searches()-searches, outputs)
+		})
+	}
+}
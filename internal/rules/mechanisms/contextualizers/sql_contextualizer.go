@@ -0,0 +1,424 @@
+// Copyright 2025 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package contextualizers
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"time"
+
+	_ "github.com/go-sql-driver/mysql" // registers the mysql driver
+	"github.com/goccy/go-json"
+	_ "github.com/jackc/pgx/v5/stdlib" // registers the postgres driver
+	"github.com/rs/zerolog"
+	_ "modernc.org/sqlite" // registers the sqlite driver
+
+	"github.com/dadrus/heimdall/internal/app"
+	"github.com/dadrus/heimdall/internal/cache"
+	"github.com/dadrus/heimdall/internal/heimdall"
+	"github.com/dadrus/heimdall/internal/rules/mechanisms/subject"
+	"github.com/dadrus/heimdall/internal/rules/mechanisms/template"
+	"github.com/dadrus/heimdall/internal/x"
+	"github.com/dadrus/heimdall/internal/x/errorchain"
+	"github.com/dadrus/heimdall/internal/x/stringx"
+)
+
+const (
+	defaultSQLQueryTimeout = 5 * time.Second
+	defaultSQLMaxRows      = 100
+)
+
+// maps the supported database types to the names of the registered drivers.
+var sqlDrivers = map[string]string{ //nolint:gochecknoglobals
+	"postgres": "pgx",
+	"mysql":    "mysql",
+	"sqlite":   "sqlite",
+}
+
+// by intention. Used only during application bootstrap
+//
+//nolint:gochecknoinits
+func init() {
+	registerTypeFactory(
+		func(app app.Context, id string, typ string, conf map[string]any) (bool, Contextualizer, error) {
+			if typ != ContextualizerSQL {
+				return false, nil, nil
+			}
+
+			eh, err := newSQLContextualizer(app, id, conf)
+
+			return true, eh, err
+		})
+}
+
+type sqlDatabase struct {
+	Driver          string         `mapstructure:"driver"            validate:"required,oneof=postgres mysql sqlite"`
+	DSN             string         `mapstructure:"dsn"               validate:"required"`
+	MaxOpenConns    int            `mapstructure:"max_open_conns"    validate:"gte=0"`
+	MaxIdleConns    int            `mapstructure:"max_idle_conns"    validate:"gte=0"`
+	ConnMaxLifetime *time.Duration `mapstructure:"conn_max_lifetime"`
+	QueryTimeout    *time.Duration `mapstructure:"query_timeout"`
+}
+
+type sqlContextualizerData struct {
+	Rows []map[string]any `json:"rows"`
+}
+
+type sqlContextualizer struct {
+	id              string
+	app             app.Context
+	db              *sql.DB
+	timeout         time.Duration
+	query           string
+	params          []template.Template
+	maxRows         int
+	ttl             time.Duration
+	continueOnError bool
+}
+
+func newSQLContextualizer(app app.Context, id string, rawConfig map[string]any) (*sqlContextualizer, error) {
+	logger := app.Logger()
+	logger.Info().Str("_id", id).Msg("Creating sql contextualizer")
+
+	type Config struct {
+		Database        sqlDatabase         `mapstructure:"database"                   validate:"required"`
+		Query           string              `mapstructure:"query"                      validate:"required"`
+		Params          []template.Template `mapstructure:"params"`
+		MaxRows         int                 `mapstructure:"max_rows"                   validate:"gte=0"`
+		CacheTTL        *time.Duration      `mapstructure:"cache_ttl"`
+		ContinueOnError bool                `mapstructure:"continue_pipeline_on_error"`
+	}
+
+	var conf Config
+	if err := decodeConfig(app, rawConfig, &conf); err != nil {
+		return nil, errorchain.NewWithMessagef(heimdall.ErrConfiguration,
+			"failed decoding config for sql contextualizer '%s'", id).CausedBy(err)
+	}
+
+	// the connection is established lazily on first usage
+	db, err := sql.Open(sqlDrivers[conf.Database.Driver], conf.Database.DSN)
+	if err != nil {
+		return nil, errorchain.NewWithMessagef(heimdall.ErrConfiguration,
+			"failed creating database connection pool for sql contextualizer '%s'", id).CausedBy(err)
+	}
+
+	if conf.Database.MaxOpenConns != 0 {
+		db.SetMaxOpenConns(conf.Database.MaxOpenConns)
+	}
+
+	if conf.Database.MaxIdleConns != 0 {
+		db.SetMaxIdleConns(conf.Database.MaxIdleConns)
+	}
+
+	if conf.Database.ConnMaxLifetime != nil {
+		db.SetConnMaxLifetime(*conf.Database.ConnMaxLifetime)
+	}
+
+	ttl := defaultTTL
+	if conf.CacheTTL != nil {
+		ttl = *conf.CacheTTL
+	}
+
+	return &sqlContextualizer{
+		id:  id,
+		app: app,
+		db:  db,
+		timeout: x.IfThenElseExec(conf.Database.QueryTimeout != nil,
+			func() time.Duration { return *conf.Database.QueryTimeout },
+			func() time.Duration { return defaultSQLQueryTimeout }),
+		query:           conf.Query,
+		params:          conf.Params,
+		maxRows:         x.IfThenElse(conf.MaxRows != 0, conf.MaxRows, defaultSQLMaxRows),
+		ttl:             ttl,
+		continueOnError: conf.ContinueOnError,
+	}, nil
+}
+
+func (c *sqlContextualizer) Execute(ctx heimdall.RequestContext, sub *subject.Subject) error {
+	logger := zerolog.Ctx(ctx.Context())
+	logger.Debug().Str("_id", c.id).Msg("Updating using sql contextualizer")
+
+	if sub == nil {
+		return errorchain.NewWithMessage(heimdall.ErrInternal,
+			"failed to execute sql contextualizer due to 'nil' subject").
+			WithErrorContext(c)
+	}
+
+	params, err := c.renderParams(ctx, sub)
+	if err != nil {
+		return err
+	}
+
+	cch := cache.Ctx(ctx.Context())
+
+	var (
+		cacheKey string
+		data     *sqlContextualizerData
+	)
+
+	if c.ttl > 0 {
+		cacheKey = c.calculateCacheKey(params)
+		if entry, err := cch.Get(ctx.Context(), cacheKey); err == nil {
+			if cd, err := decodeSQLRows(entry); err == nil {
+				logger.Debug().Msg("Reusing contextualizer response from cache")
+
+				data = cd
+			}
+		}
+	}
+
+	if data == nil {
+		raw, err := c.executeQuery(ctx.Context(), params)
+		if err != nil {
+			return err
+		}
+
+		// the rows are always decoded from their JSON representation to have
+		// the same types independent of whether they are taken from the cache or not
+		data, err = decodeSQLRows(raw)
+		if err != nil {
+			return errorchain.NewWithMessage(heimdall.ErrInternal, "failed to decode query result").
+				WithErrorContext(c).
+				CausedBy(err)
+		}
+
+		if c.ttl > 0 && len(cacheKey) != 0 {
+			if err = cch.Set(ctx.Context(), cacheKey, raw, c.ttl); err != nil {
+				logger.Warn().Err(err).Msg("Failed to cache contextualizer response")
+			}
+		}
+	}
+
+	rows := make([]any, len(data.Rows))
+	for idx, row := range data.Rows {
+		rows[idx] = row
+	}
+
+	ctx.Outputs()[c.id] = rows
+
+	return nil
+}
+
+func (c *sqlContextualizer) WithConfig(rawConfig map[string]any) (Contextualizer, error) {
+	if len(rawConfig) == 0 {
+		return c, nil
+	}
+
+	type Config struct {
+		Params          []template.Template `mapstructure:"params"`
+		CacheTTL        *time.Duration      `mapstructure:"cache_ttl"`
+		ContinueOnError *bool               `mapstructure:"continue_pipeline_on_error"`
+	}
+
+	var conf Config
+	if err := decodeConfig(c.app, rawConfig, &conf); err != nil {
+		return nil, errorchain.NewWithMessagef(heimdall.ErrConfiguration,
+			"failed decoding config for sql contextualizer '%s'", c.id).CausedBy(err)
+	}
+
+	return &sqlContextualizer{
+		id:      c.id,
+		app:     c.app,
+		db:      c.db,
+		timeout: c.timeout,
+		query:   c.query,
+		params:  x.IfThenElse(len(conf.Params) != 0, conf.Params, c.params),
+		maxRows: c.maxRows,
+		ttl: x.IfThenElseExec(conf.CacheTTL != nil,
+			func() time.Duration { return *conf.CacheTTL },
+			func() time.Duration { return c.ttl }),
+		continueOnError: x.IfThenElseExec(conf.ContinueOnError != nil,
+			func() bool { return *conf.ContinueOnError },
+			func() bool { return c.continueOnError }),
+	}, nil
+}
+
+func (c *sqlContextualizer) ID() string { return c.id }
+
+func (c *sqlContextualizer) ContinueOnError() bool { return c.continueOnError }
+
+func (c *sqlContextualizer) renderParams(ctx heimdall.RequestContext, sub *subject.Subject) ([]any, error) {
+	values := map[string]any{
+		"Request": ctx.Request(),
+		"Subject": sub,
+		"Outputs": ctx.Outputs(),
+	}
+
+	params := make([]any, len(c.params))
+
+	for idx, tpl := range c.params {
+		value, err := tpl.Render(values)
+		if err != nil {
+			return nil, errorchain.NewWithMessagef(heimdall.ErrInternal,
+				"failed to render query parameter %d", idx+1).
+				WithErrorContext(c).
+				CausedBy(err)
+		}
+
+		params[idx] = value
+	}
+
+	return params, nil
+}
+
+// executeQuery runs the query and returns the resulting rows in their JSON representation.
+func (c *sqlContextualizer) executeQuery(ctx context.Context, params []any) ([]byte, error) {
+	logger := zerolog.Ctx(ctx)
+	logger.Debug().Msg("Querying database")
+
+	qctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	rows, err := c.db.QueryContext(qctx, c.query, params...)
+	if err != nil {
+		return nil, c.queryError(err)
+	}
+
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, c.queryError(err)
+	}
+
+	result := sqlContextualizerData{Rows: []map[string]any{}}
+
+	for rows.Next() {
+		if len(result.Rows) == c.maxRows {
+			return nil, errorchain.NewWithMessagef(heimdall.ErrInternal,
+				"query returned more than the allowed %d rows", c.maxRows).
+				WithErrorContext(c)
+		}
+
+		values := make([]any, len(columns))
+		pointers := make([]any, len(columns))
+
+		for idx := range values {
+			pointers[idx] = &values[idx]
+		}
+
+		if err = rows.Scan(pointers...); err != nil {
+			return nil, c.queryError(err)
+		}
+
+		row := make(map[string]any, len(columns))
+		for idx, column := range columns {
+			row[column] = sqlValue(values[idx])
+		}
+
+		result.Rows = append(result.Rows, row)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, c.queryError(err)
+	}
+
+	raw, err := json.Marshal(result)
+	if err != nil {
+		return nil, errorchain.NewWithMessage(heimdall.ErrInternal, "failed to encode query result").
+			WithErrorContext(c).
+			CausedBy(err)
+	}
+
+	return raw, nil
+}
+
+func (c *sqlContextualizer) queryError(err error) error {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return errorchain.NewWithMessage(heimdall.ErrCommunicationTimeout, "database query timed out").
+			WithErrorContext(c).
+			CausedBy(err)
+	}
+
+	return errorchain.NewWithMessage(heimdall.ErrCommunication, "database query failed").
+		WithErrorContext(c).
+		CausedBy(err)
+}
+
+func (c *sqlContextualizer) calculateCacheKey(params []any) string {
+	const int64BytesCount = 8
+
+	ttlBytes := make([]byte, int64BytesCount)
+	//nolint:gosec
+	// no integer overflow during conversion possible
+	binary.LittleEndian.PutUint64(ttlBytes, uint64(c.ttl))
+
+	lengthBytes := make([]byte, int64BytesCount)
+
+	hash := sha256.New()
+	hash.Write(stringx.ToBytes(c.id))
+	hash.Write(stringx.ToBytes(c.query))
+	hash.Write(ttlBytes)
+
+	for _, param := range params {
+		value, _ := param.(string)
+
+		// the length prefix prevents collisions like ["ab", "c"] vs ["a", "bc"]
+		binary.LittleEndian.PutUint64(lengthBytes, uint64(len(value)))
+		hash.Write(lengthBytes)
+		hash.Write(stringx.ToBytes(value))
+	}
+
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// decodeSQLRows decodes the rows from their JSON representation. Numbers are decoded to int64
+// if possible, and to float64 otherwise, so that e.g. BIGINT values do not lose precision.
+func decodeSQLRows(raw []byte) (*sqlContextualizerData, error) {
+	var data sqlContextualizerData
+
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.UseNumber()
+
+	if err := dec.Decode(&data); err != nil {
+		return nil, err
+	}
+
+	for _, row := range data.Rows {
+		for column, value := range row {
+			number, ok := value.(json.Number)
+			if !ok {
+				continue
+			}
+
+			if intValue, err := number.Int64(); err == nil {
+				row[column] = intValue
+			} else if floatValue, err := number.Float64(); err == nil {
+				row[column] = floatValue
+			}
+		}
+	}
+
+	return &data, nil
+}
+
+// sqlValue converts values returned by the drivers, which do not have a useful JSON representation.
+func sqlValue(value any) any {
+	switch val := value.(type) {
+	case []byte:
+		return string(val)
+	case time.Time:
+		return val.Format(time.RFC3339Nano)
+	default:
+		return val
+	}
+}
@@ -20,4 +20,6 @@ const (
 	ErrorHandlerDefault         = "default"
 	ErrorHandlerRedirect        = "redirect"
 	ErrorHandlerWWWAuthenticate = "www_authenticate"
+	ErrorHandlerOIDC            = "oidc"
+	ErrorHandlerResponse        = "response"
 )
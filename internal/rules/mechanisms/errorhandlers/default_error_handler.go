@@ -21,6 +21,7 @@ import (
 
 	"github.com/dadrus/heimdall/internal/app"
 	"github.com/dadrus/heimdall/internal/heimdall"
+	"github.com/dadrus/heimdall/internal/rules/mechanisms/subject"
 	"github.com/dadrus/heimdall/internal/x/errorchain"
 )
 
@@ -46,7 +47,7 @@ func newDefaultErrorHandler(id string) *defaultErrorHandler {
 	return &defaultErrorHandler{id: id}
 }
 
-func (eh *defaultErrorHandler) Execute(ctx heimdall.RequestContext, causeErr error) error {
+func (eh *defaultErrorHandler) Execute(ctx heimdall.RequestContext, _ *subject.Subject, causeErr error) error {
 	logger := zerolog.Ctx(ctx.Context())
 	logger.Info().Str("_id", eh.id).Msg("Handling error using default error handler")
 
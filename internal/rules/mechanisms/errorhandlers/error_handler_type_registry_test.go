@@ -29,8 +29,8 @@ import (
 func TestCreateErrorHandlerPrototypePrototype(t *testing.T) {
 	t.Parallel()
 
-	// there are 3 error handlers implemented, which should have been registered
-	require.Len(t, errorHandlerTypeFactories, 3)
+	// there are 4 error handlers implemented, which should have been registered
+	require.Len(t, errorHandlerTypeFactories, 5)
 
 	for uc, tc := range map[string]struct {
 		typ    string
@@ -0,0 +1,107 @@
+// Copyright 2025 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package errorhandlers
+
+import (
+	"errors"
+
+	"github.com/rs/zerolog"
+
+	"github.com/dadrus/heimdall/internal/app"
+	"github.com/dadrus/heimdall/internal/heimdall"
+	"github.com/dadrus/heimdall/internal/rules/mechanisms/subject"
+	"github.com/dadrus/heimdall/internal/x/errorchain"
+)
+
+// by intention. Used only during application bootstrap
+//
+//nolint:gochecknoinits
+func init() {
+	registerTypeFactory(
+		func(app app.Context, id string, typ string, conf map[string]any) (bool, ErrorHandler, error) {
+			if typ != ErrorHandlerOIDC {
+				return false, nil, nil
+			}
+
+			eh, err := newOIDCErrorHandler(app, id, conf)
+
+			return true, eh, err
+		})
+}
+
+// loginRedirector is implemented by authenticators able to start a login flow.
+type loginRedirector interface {
+	LoginRedirect(ctx heimdall.RequestContext) (*heimdall.RedirectError, error)
+}
+
+type oidcErrorHandler struct {
+	id string
+}
+
+func newOIDCErrorHandler(app app.Context, id string, rawConfig map[string]any) (*oidcErrorHandler, error) {
+	logger := app.Logger()
+	logger.Info().Str("_id", id).Msg("Creating oidc error handler")
+
+	type Config struct{}
+
+	var conf Config
+	if err := decodeConfig(app.Validator(), ErrorHandlerOIDC, rawConfig, &conf); err != nil {
+		return nil, err
+	}
+
+	return &oidcErrorHandler{id: id}, nil
+}
+
+func (eh *oidcErrorHandler) ID() string { return eh.id }
+
+func (eh *oidcErrorHandler) Execute(ctx heimdall.RequestContext, _ *subject.Subject, causeErr error) error {
+	logger := zerolog.Ctx(ctx.Context())
+	logger.Debug().Str("_id", eh.id).Msg("Handling error using oidc error handler")
+
+	var redirector loginRedirector
+
+	if !errors.Is(causeErr, heimdall.ErrAuthentication) || !errors.As(causeErr, &redirector) {
+		logger.Debug().Str("_id", eh.id).Msg("Error not caused by an oidc authenticator. Passing it through")
+		ctx.SetPipelineError(causeErr)
+
+		return nil
+	}
+
+	redirect, err := redirector.LoginRedirect(ctx)
+	if err != nil {
+		return errorchain.NewWithMessage(heimdall.ErrInternal, "failed to initiate login").CausedBy(err)
+	}
+
+	if redirect == nil {
+		ctx.SetPipelineError(causeErr)
+
+		return nil
+	}
+
+	ctx.SetPipelineError(redirect)
+
+	return nil
+}
+
+func (eh *oidcErrorHandler) WithConfig(conf map[string]any) (ErrorHandler, error) {
+	if len(conf) != 0 {
+		return nil, errorchain.NewWithMessage(heimdall.ErrConfiguration,
+			"reconfiguration of an oidc error handler is not supported")
+	}
+
+	return eh, nil
+}
@@ -0,0 +1,210 @@
+// Copyright 2025 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package errorhandlers
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/rs/zerolog/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dadrus/heimdall/internal/app"
+	"github.com/dadrus/heimdall/internal/config"
+	"github.com/dadrus/heimdall/internal/heimdall"
+	"github.com/dadrus/heimdall/internal/heimdall/mocks"
+	"github.com/dadrus/heimdall/internal/validation"
+	"github.com/dadrus/heimdall/internal/x/errorchain"
+	"github.com/dadrus/heimdall/internal/x/testsupport"
+)
+
+type testLoginRedirector struct {
+	redirect *heimdall.RedirectError
+	err      error
+}
+
+func (r *testLoginRedirector) LoginRedirect(_ heimdall.RequestContext) (*heimdall.RedirectError, error) {
+	return r.redirect, r.err
+}
+
+func TestCreateOIDCErrorHandler(t *testing.T) {
+	t.Parallel()
+
+	for uc, tc := range map[string]struct {
+		config []byte
+		assert func(t *testing.T, err error, eh *oidcErrorHandler)
+	}{
+		"without configuration": {
+			assert: func(t *testing.T, err error, eh *oidcErrorHandler) {
+				t.Helper()
+
+				require.NoError(t, err)
+				assert.Equal(t, "without configuration", eh.ID())
+			},
+		},
+		"with unexpected fields in configuration": {
+			config: []byte(`foo: bar`),
+			assert: func(t *testing.T, err error, _ *oidcErrorHandler) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrConfiguration)
+				require.ErrorContains(t, err, "failed decoding")
+			},
+		},
+	} {
+		t.Run(uc, func(t *testing.T) {
+			// GIVEN
+			conf, err := testsupport.DecodeTestConfig(tc.config)
+			require.NoError(t, err)
+
+			validator, err := validation.NewValidator(
+				validation.WithTagValidator(config.EnforcementSettings{}),
+			)
+			require.NoError(t, err)
+
+			appCtx := app.NewContextMock(t)
+			appCtx.EXPECT().Validator().Maybe().Return(validator)
+			appCtx.EXPECT().Logger().Return(log.Logger)
+
+			// WHEN
+			eh, err := newOIDCErrorHandler(appCtx, uc, conf)
+
+			// THEN
+			tc.assert(t, err, eh)
+		})
+	}
+}
+
+func TestOIDCErrorHandlerWithConfig(t *testing.T) {
+	t.Parallel()
+
+	eh := &oidcErrorHandler{id: "foo"}
+
+	// without config
+	conf, err := eh.WithConfig(nil)
+	require.NoError(t, err)
+	assert.Equal(t, eh, conf)
+
+	// with config
+	_, err = eh.WithConfig(map[string]any{"foo": "bar"})
+	require.Error(t, err)
+	require.ErrorIs(t, err, heimdall.ErrConfiguration)
+}
+
+func TestOIDCErrorHandlerExecute(t *testing.T) {
+	t.Parallel()
+
+	redirect := &heimdall.RedirectError{
+		Message:    "login required",
+		Code:       http.StatusFound,
+		RedirectTo: "https://idp.example.com/authorize",
+		Cookies:    []*http.Cookie{{Name: "state", Value: "foo"}},
+	}
+
+	for uc, tc := range map[string]struct {
+		error            error
+		configureContext func(t *testing.T, ctx *mocks.RequestContextMock, causeErr error)
+		assert           func(t *testing.T, err error)
+	}{
+		"error not caused by authentication": {
+			error: errorchain.New(heimdall.ErrAuthorization).
+				WithErrorContext(&testLoginRedirector{redirect: redirect}),
+			configureContext: func(t *testing.T, ctx *mocks.RequestContextMock, causeErr error) {
+				t.Helper()
+
+				ctx.EXPECT().SetPipelineError(causeErr)
+			},
+			assert: func(t *testing.T, err error) {
+				t.Helper()
+
+				require.NoError(t, err)
+			},
+		},
+		"authentication error not caused by an oidc authenticator": {
+			error: errorchain.New(heimdall.ErrAuthentication),
+			configureContext: func(t *testing.T, ctx *mocks.RequestContextMock, causeErr error) {
+				t.Helper()
+
+				ctx.EXPECT().SetPipelineError(causeErr)
+			},
+			assert: func(t *testing.T, err error) {
+				t.Helper()
+
+				require.NoError(t, err)
+			},
+		},
+		"login redirect cannot be created": {
+			error: errorchain.New(heimdall.ErrAuthentication).
+				WithErrorContext(&testLoginRedirector{err: errors.New("test error")}),
+			configureContext: func(t *testing.T, _ *mocks.RequestContextMock, _ error) { t.Helper() },
+			assert: func(t *testing.T, err error) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrInternal)
+				require.ErrorContains(t, err, "test error")
+			},
+		},
+		"login flow cannot be started for the request": {
+			error: errorchain.New(heimdall.ErrAuthentication).
+				WithErrorContext(&testLoginRedirector{}),
+			configureContext: func(t *testing.T, ctx *mocks.RequestContextMock, causeErr error) {
+				t.Helper()
+
+				ctx.EXPECT().SetPipelineError(causeErr)
+			},
+			assert: func(t *testing.T, err error) {
+				t.Helper()
+
+				require.NoError(t, err)
+			},
+		},
+		"login redirect is created": {
+			error: errorchain.New(heimdall.ErrAuthentication).
+				WithErrorContext(&testLoginRedirector{redirect: redirect}),
+			configureContext: func(t *testing.T, ctx *mocks.RequestContextMock, _ error) {
+				t.Helper()
+
+				ctx.EXPECT().SetPipelineError(redirect)
+			},
+			assert: func(t *testing.T, err error) {
+				t.Helper()
+
+				require.NoError(t, err)
+			},
+		},
+	} {
+		t.Run(uc, func(t *testing.T) {
+			// GIVEN
+			mctx := mocks.NewRequestContextMock(t)
+			mctx.EXPECT().Context().Return(t.Context())
+
+			tc.configureContext(t, mctx, tc.error)
+
+			eh := &oidcErrorHandler{id: "foo"}
+
+			// WHEN
+			err := eh.Execute(mctx, nil, tc.error)
+
+			// THEN
+			tc.assert(t, err)
+		})
+	}
+}
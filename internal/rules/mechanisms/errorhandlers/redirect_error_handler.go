@@ -24,6 +24,7 @@ import (
 
 	"github.com/dadrus/heimdall/internal/app"
 	"github.com/dadrus/heimdall/internal/heimdall"
+	"github.com/dadrus/heimdall/internal/rules/mechanisms/subject"
 	"github.com/dadrus/heimdall/internal/rules/mechanisms/template"
 	"github.com/dadrus/heimdall/internal/x"
 	"github.com/dadrus/heimdall/internal/x/errorchain"
@@ -79,7 +80,7 @@ func newRedirectErrorHandler(app app.Context, id string, rawConfig map[string]an
 
 func (eh *redirectErrorHandler) ID() string { return eh.id }
 
-func (eh *redirectErrorHandler) Execute(ctx heimdall.RequestContext, _ error) error {
+func (eh *redirectErrorHandler) Execute(ctx heimdall.RequestContext, _ *subject.Subject, _ error) error {
 	logger := zerolog.Ctx(ctx.Context())
 	logger.Debug().Str("_id", eh.id).Msg("Handling error using redirect error handler")
 
@@ -331,7 +331,7 @@ code: 300
 			require.NoError(t, err)
 
 			// WHEN
-			execErr := errorHandler.Execute(mctx, tc.error)
+			execErr := errorHandler.Execute(mctx, nil, tc.error)
 
 			// THEN
 			tc.assert(t, execErr)
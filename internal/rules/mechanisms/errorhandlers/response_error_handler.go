@@ -0,0 +1,326 @@
+// Copyright 2025 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package errorhandlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/elnormous/contenttype"
+	"github.com/rs/zerolog"
+
+	"github.com/dadrus/heimdall/internal/app"
+	"github.com/dadrus/heimdall/internal/config"
+	"github.com/dadrus/heimdall/internal/heimdall"
+	"github.com/dadrus/heimdall/internal/rules/mechanisms/subject"
+	"github.com/dadrus/heimdall/internal/rules/mechanisms/template"
+	"github.com/dadrus/heimdall/internal/x"
+	"github.com/dadrus/heimdall/internal/x/errorchain"
+)
+
+const problemDocumentMediaType = "application/problem+json"
+
+//nolint:gochecknoglobals
+var htmlMediaTypes = []contenttype.MediaType{
+	contenttype.NewMediaType("text/html"),
+	contenttype.NewMediaType("application/xhtml+xml"),
+}
+
+// by intention. Used only during application bootstrap
+//
+//nolint:gochecknoinits
+func init() {
+	registerTypeFactory(
+		func(app app.Context, id string, typ string, conf map[string]any) (bool, ErrorHandler, error) {
+			if typ != ErrorHandlerResponse {
+				return false, nil, nil
+			}
+
+			eh, err := newResponseErrorHandler(app, id, conf)
+
+			return true, eh, err
+		})
+}
+
+type bodyRenderer func(values map[string]any) (string, error)
+
+type responseBody struct {
+	mediaType contenttype.MediaType
+	render    bodyRenderer
+}
+
+type responseErrorHandler struct {
+	id      string
+	code    int
+	respond config.RespondConfig
+	headers map[string]template.Template
+	bodies  []responseBody
+}
+
+func newResponseErrorHandler(app app.Context, id string, rawConfig map[string]any) (*responseErrorHandler, error) {
+	logger := app.Logger()
+	logger.Info().Str("_id", id).Msg("Creating response error handler")
+
+	type BodyConfig struct {
+		MediaType string            `mapstructure:"media_type" validate:"required"`
+		Template  template.Template `mapstructure:"template"   validate:"required"`
+	}
+
+	type ProblemConfig struct {
+		Type     template.Template `mapstructure:"type"`
+		Title    template.Template `mapstructure:"title"`
+		Detail   template.Template `mapstructure:"detail"`
+		Instance template.Template `mapstructure:"instance"`
+	}
+
+	type Config struct {
+		Code    int                          `mapstructure:"code"    validate:"omitempty,gte=400,lte=599"`
+		Headers map[string]template.Template `mapstructure:"headers"`
+		Body    []BodyConfig                 `mapstructure:"body"    validate:"dive"`
+		Problem *ProblemConfig               `mapstructure:"problem"`
+	}
+
+	var conf Config
+	if err := decodeConfig(app.Validator(), ErrorHandlerResponse, rawConfig, &conf); err != nil {
+		return nil, err
+	}
+
+	if len(conf.Body) == 0 && conf.Problem == nil && len(conf.Headers) == 0 && conf.Code == 0 {
+		return nil, errorchain.NewWithMessagef(heimdall.ErrConfiguration,
+			"response error handler '%s' requires at least one of 'code', 'headers', 'body' or 'problem'", id)
+	}
+
+	bodies := make([]responseBody, 0, len(conf.Body)+1)
+
+	for idx, body := range conf.Body {
+		mediaType, err := contenttype.ParseMediaType(body.MediaType)
+		if err != nil {
+			return nil, errorchain.NewWithMessagef(heimdall.ErrConfiguration,
+				"failed to parse media type of body entry %d in response error handler '%s'", idx, id).
+				CausedBy(err)
+		}
+
+		tpl := body.Template
+		if isHTMLMediaType(mediaType) {
+			// values, like the request URL, are controlled by the client and must be escaped
+			// to prevent reflected XSS
+			tpl, err = template.NewHTML(body.Template.String())
+			if err != nil {
+				return nil, errorchain.NewWithMessagef(heimdall.ErrConfiguration,
+					"failed to parse template of body entry %d in response error handler '%s'", idx, id).
+					CausedBy(err)
+			}
+		}
+
+		bodies = append(bodies, responseBody{mediaType: mediaType, render: tpl.Render})
+	}
+
+	if conf.Problem != nil {
+		problem := conf.Problem
+		bodies = append(bodies, responseBody{
+			mediaType: contenttype.NewMediaType(problemDocumentMediaType),
+			render: func(values map[string]any) (string, error) {
+				return renderProblemDocument(problem.Type, problem.Title, problem.Detail, problem.Instance, values)
+			},
+		})
+	}
+
+	return &responseErrorHandler{
+		id:      id,
+		code:    conf.Code,
+		respond: app.Config().Serve.Respond,
+		headers: conf.Headers,
+		bodies:  bodies,
+	}, nil
+}
+
+func (eh *responseErrorHandler) ID() string { return eh.id }
+
+func (eh *responseErrorHandler) Execute(ctx heimdall.RequestContext, sub *subject.Subject, causeErr error) error {
+	logger := zerolog.Ctx(ctx.Context())
+	logger.Debug().Str("_id", eh.id).Msg("Handling error using response error handler")
+
+	code := x.IfThenElse(eh.code != 0, eh.code, statusCodeFor(causeErr, &eh.respond))
+	values := map[string]any{
+		"Request": ctx.Request(),
+		"Subject": sub,
+		"Status":  code,
+		"Error":   map[string]any{"Type": errorTypeOf(causeErr)},
+	}
+
+	headers := make(http.Header)
+
+	var tmrErr *heimdall.TooManyRequestsError
+	if errors.As(causeErr, &tmrErr) {
+		for name, vals := range tmrErr.Headers() {
+			headers[name] = vals
+		}
+	}
+
+	for name, tpl := range eh.headers {
+		value, err := tpl.Render(values)
+		if err != nil {
+			return errorchain.NewWithMessagef(heimdall.ErrInternal, "failed to render '%s' header", name).
+				CausedBy(err)
+		}
+
+		headers.Set(name, value)
+	}
+
+	var body []byte
+
+	if len(eh.bodies) != 0 {
+		selected := eh.negotiate(ctx.Request().Header("Accept"))
+
+		content, err := selected.render(values)
+		if err != nil {
+			return errorchain.NewWithMessagef(heimdall.ErrInternal,
+				"failed to render '%s' body", selected.mediaType.String()).CausedBy(err)
+		}
+
+		body = []byte(content)
+
+		headers.Set("Content-Type", selected.mediaType.String())
+		headers.Set("X-Content-Type-Options", "nosniff")
+	}
+
+	ctx.SetPipelineError(&heimdall.ResponseError{
+		Message: causeErr.Error(),
+		Code:    code,
+		Headers: headers,
+		Body:    body,
+	})
+
+	return nil
+}
+
+func (eh *responseErrorHandler) WithConfig(conf map[string]any) (ErrorHandler, error) {
+	if len(conf) != 0 {
+		return nil, errorchain.NewWithMessage(heimdall.ErrConfiguration,
+			"reconfiguration of a response error handler is not supported")
+	}
+
+	return eh, nil
+}
+
+func (eh *responseErrorHandler) negotiate(accept string) responseBody {
+	available := make([]contenttype.MediaType, len(eh.bodies))
+	for idx, body := range eh.bodies {
+		available[idx] = body.mediaType
+	}
+
+	// the first configured body is used if the client did not state any acceptable media type,
+	// or none of the configured ones is acceptable.
+	mediaType, _, err := contenttype.GetAcceptableMediaTypeFromHeader(accept, available)
+	if err != nil {
+		return eh.bodies[0]
+	}
+
+	for _, body := range eh.bodies {
+		if body.mediaType.Equal(mediaType) {
+			return body
+		}
+	}
+
+	return eh.bodies[0]
+}
+
+func renderProblemDocument(typ, title, detail, instance template.Template, values map[string]any) (string, error) {
+	document := map[string]any{"status": values["Status"]}
+
+	for name, tpl := range map[string]template.Template{
+		"type":     typ,
+		"title":    title,
+		"detail":   detail,
+		"instance": instance,
+	} {
+		if tpl == nil {
+			continue
+		}
+
+		value, err := tpl.Render(values)
+		if err != nil {
+			return "", err
+		}
+
+		if len(value) != 0 {
+			document[name] = value
+		}
+	}
+
+	raw, err := json.Marshal(document)
+	if err != nil {
+		return "", err
+	}
+
+	return string(raw), nil
+}
+
+// statusCodeFor returns the status code for the given error the same way, the error handling of
+// heimdall's services does, taking the codes configured in the respond settings into account.
+func statusCodeFor(err error, respond *config.RespondConfig) int {
+	codeOf := func(override config.ResponseOverride, fallback int) int {
+		return x.IfThenElse(override.Code != 0, override.Code, fallback)
+	}
+
+	with := respond.With
+
+	switch {
+	case errors.Is(err, heimdall.ErrAuthentication):
+		return codeOf(with.AuthenticationError, http.StatusUnauthorized)
+	case errors.Is(err, heimdall.ErrAuthorization):
+		return codeOf(with.AuthorizationError, http.StatusForbidden)
+	case errors.Is(err, heimdall.ErrCommunicationTimeout), errors.Is(err, heimdall.ErrCommunication):
+		return codeOf(with.CommunicationError, http.StatusBadGateway)
+	case errors.Is(err, heimdall.ErrArgument):
+		return codeOf(with.ArgumentError, http.StatusBadRequest)
+	case errors.Is(err, heimdall.ErrNoRuleFound):
+		return codeOf(with.NoRuleError, http.StatusNotFound)
+	case errors.Is(err, &heimdall.TooManyRequestsError{}):
+		return codeOf(with.TooManyRequestsError, http.StatusTooManyRequests)
+	default:
+		return codeOf(with.InternalError, http.StatusInternalServerError)
+	}
+}
+
+func errorTypeOf(err error) string {
+	switch {
+	case errors.Is(err, heimdall.ErrAuthentication):
+		return "authentication_error"
+	case errors.Is(err, heimdall.ErrAuthorization):
+		return "authorization_error"
+	case errors.Is(err, heimdall.ErrCommunication), errors.Is(err, heimdall.ErrCommunicationTimeout):
+		return "communication_error"
+	case errors.Is(err, heimdall.ErrArgument):
+		return "precondition_error"
+	case errors.Is(err, heimdall.ErrNoRuleFound):
+		return "no_rule_error"
+	case errors.Is(err, &heimdall.TooManyRequestsError{}):
+		return "too_many_requests_error"
+	default:
+		return "internal_error"
+	}
+}
+
+func isHTMLMediaType(mediaType contenttype.MediaType) bool {
+	return slices.ContainsFunc(htmlMediaTypes, func(htmlType contenttype.MediaType) bool {
+		return strings.EqualFold(htmlType.Type, mediaType.Type) && strings.EqualFold(htmlType.Subtype, mediaType.Subtype)
+	})
+}
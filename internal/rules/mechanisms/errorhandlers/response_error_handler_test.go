@@ -0,0 +1,505 @@
+// Copyright 2025 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package errorhandlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dadrus/heimdall/internal/app"
+	"github.com/dadrus/heimdall/internal/config"
+	"github.com/dadrus/heimdall/internal/heimdall"
+	"github.com/dadrus/heimdall/internal/heimdall/mocks"
+	"github.com/dadrus/heimdall/internal/rules/mechanisms/subject"
+	"github.com/dadrus/heimdall/internal/validation"
+	"github.com/dadrus/heimdall/internal/x/errorchain"
+	"github.com/dadrus/heimdall/internal/x/testsupport"
+)
+
+func TestCreateResponseErrorHandler(t *testing.T) {
+	t.Parallel()
+
+	for uc, tc := range map[string]struct {
+		config []byte
+		assert func(t *testing.T, err error, eh *responseErrorHandler)
+	}{
+		"without configuration": {
+			assert: func(t *testing.T, err error, _ *responseErrorHandler) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrConfiguration)
+				assert.Contains(t, err.Error(), "requires at least one of")
+			},
+		},
+		"with unexpected fields in configuration": {
+			config: []byte(`
+code: 403
+foo: bar
+`),
+			assert: func(t *testing.T, err error, _ *responseErrorHandler) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrConfiguration)
+				assert.Contains(t, err.Error(), "failed decoding")
+			},
+		},
+		"with invalid code": {
+			config: []byte(`code: 302`),
+			assert: func(t *testing.T, err error, _ *responseErrorHandler) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrConfiguration)
+				assert.Contains(t, err.Error(), "'code' must be 400 or greater")
+			},
+		},
+		"with body entry without media type": {
+			config: []byte(`
+body:
+  - template: foo
+`),
+			assert: func(t *testing.T, err error, _ *responseErrorHandler) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrConfiguration)
+				assert.Contains(t, err.Error(), "'media_type' is a required field")
+			},
+		},
+		"with body entry with invalid media type": {
+			config: []byte(`
+body:
+  - media_type: foo
+    template: bar
+`),
+			assert: func(t *testing.T, err error, _ *responseErrorHandler) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrConfiguration)
+				assert.Contains(t, err.Error(), "failed to parse media type")
+			},
+		},
+		"with full configuration": {
+			config: []byte(`
+code: 403
+headers:
+  X-Foo: bar
+body:
+  - media_type: text/html
+    template: <p>{{ .Error.Type }}</p>
+problem:
+  title: Forbidden
+`),
+			assert: func(t *testing.T, err error, eh *responseErrorHandler) {
+				t.Helper()
+
+				require.NoError(t, err)
+				require.NotNil(t, eh)
+				assert.Equal(t, "with full configuration", eh.ID())
+				assert.Equal(t, http.StatusForbidden, eh.code)
+				assert.Len(t, eh.headers, 1)
+				require.Len(t, eh.bodies, 2)
+				assert.Equal(t, "text/html", eh.bodies[0].mediaType.String())
+				assert.Equal(t, "application/problem+json", eh.bodies[1].mediaType.String())
+			},
+		},
+	} {
+		t.Run(uc, func(t *testing.T) {
+			// GIVEN
+			conf, err := testsupport.DecodeTestConfig(tc.config)
+			require.NoError(t, err)
+
+			es := config.EnforcementSettings{}
+			validator, err := validation.NewValidator(
+				validation.WithTagValidator(es),
+				validation.WithErrorTranslator(es),
+			)
+			require.NoError(t, err)
+
+			appCtx := app.NewContextMock(t)
+			appCtx.EXPECT().Validator().Maybe().Return(validator)
+			appCtx.EXPECT().Logger().Return(log.Logger)
+			appCtx.EXPECT().Config().Maybe().Return(&config.Configuration{})
+
+			// WHEN
+			errorHandler, err := newResponseErrorHandler(appCtx, uc, conf)
+
+			// THEN
+			tc.assert(t, err, errorHandler)
+		})
+	}
+}
+
+func TestCreateResponseErrorHandlerFromPrototype(t *testing.T) {
+	t.Parallel()
+
+	for uc, tc := range map[string]struct {
+		config []byte
+		assert func(t *testing.T, err error, prototype *responseErrorHandler, configured ErrorHandler)
+	}{
+		"no new configuration provided": {
+			assert: func(t *testing.T, err error, prototype *responseErrorHandler, configured ErrorHandler) {
+				t.Helper()
+
+				require.NoError(t, err)
+				assert.Equal(t, prototype, configured)
+			},
+		},
+		"with new configuration": {
+			config: []byte(`code: 401`),
+			assert: func(t *testing.T, err error, _ *responseErrorHandler, _ ErrorHandler) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrConfiguration)
+				assert.Contains(t, err.Error(), "not supported")
+			},
+		},
+	} {
+		t.Run(uc, func(t *testing.T) {
+			// GIVEN
+			conf, err := testsupport.DecodeTestConfig(tc.config)
+			require.NoError(t, err)
+
+			appCtx := app.NewContextMock(t)
+			validator, err := validation.NewValidator(
+				validation.WithTagValidator(config.EnforcementSettings{}),
+			)
+			require.NoError(t, err)
+
+			appCtx.EXPECT().Validator().Maybe().Return(validator)
+			appCtx.EXPECT().Logger().Return(log.Logger)
+			appCtx.EXPECT().Config().Maybe().Return(&config.Configuration{})
+
+			prototype, err := newResponseErrorHandler(appCtx, uc, map[string]any{"code": 403})
+			require.NoError(t, err)
+
+			// WHEN
+			errorHandler, err := prototype.WithConfig(conf)
+
+			// THEN
+			tc.assert(t, err, prototype, errorHandler)
+		})
+	}
+}
+
+func TestResponseErrorHandlerExecute(t *testing.T) {
+	t.Parallel()
+
+	requestURL, err := url.Parse("https://foo.bar/baz")
+	require.NoError(t, err)
+
+	for uc, tc := range map[string]struct {
+		config    []byte
+		authzCode int
+		error     error
+		accept    string
+		subject   *subject.Subject
+		assert    func(t *testing.T, err error, respErr *heimdall.ResponseError)
+	}{
+		"with code only": {
+			config: []byte(`code: 418`),
+			error:  errorchain.NewWithMessage(heimdall.ErrAuthentication, "test"),
+			assert: func(t *testing.T, err error, respErr *heimdall.ResponseError) {
+				t.Helper()
+
+				require.NoError(t, err)
+				require.NotNil(t, respErr)
+				assert.Equal(t, http.StatusTeapot, respErr.Code)
+				assert.Empty(t, respErr.Body)
+				assert.Empty(t, respErr.Headers)
+				assert.Contains(t, respErr.Message, "test")
+			},
+		},
+		"with code derived from the error and templated headers": {
+			config: []byte(`
+headers:
+  X-Error-Type: "{{ .Error.Type }}"
+  X-Subject: "{{ .Subject.ID }}"
+`),
+			error:   heimdall.ErrAuthorization,
+			subject: &subject.Subject{ID: "bar"},
+			assert: func(t *testing.T, err error, respErr *heimdall.ResponseError) {
+				t.Helper()
+
+				require.NoError(t, err)
+				require.NotNil(t, respErr)
+				assert.Equal(t, http.StatusForbidden, respErr.Code)
+				assert.Equal(t, "authorization_error", respErr.Headers.Get("X-Error-Type"))
+				assert.Equal(t, "bar", respErr.Headers.Get("X-Subject"))
+				assert.Empty(t, respErr.Body)
+			},
+		},
+		"with code derived from the error taking the respond configuration into account": {
+			config: []byte(`
+headers:
+  X-Error-Type: "{{ .Error.Type }}"
+`),
+			authzCode: http.StatusNotFound,
+			error:     heimdall.ErrAuthorization,
+			assert: func(t *testing.T, err error, respErr *heimdall.ResponseError) {
+				t.Helper()
+
+				require.NoError(t, err)
+				require.NotNil(t, respErr)
+				assert.Equal(t, http.StatusNotFound, respErr.Code)
+				assert.Equal(t, "authorization_error", respErr.Headers.Get("X-Error-Type"))
+			},
+		},
+		"with code derived from a communication timeout error": {
+			config: []byte(`
+headers:
+  X-Error-Type: "{{ .Error.Type }}"
+`),
+			error: errorchain.NewWithMessage(heimdall.ErrCommunicationTimeout, "test"),
+			assert: func(t *testing.T, err error, respErr *heimdall.ResponseError) {
+				t.Helper()
+
+				require.NoError(t, err)
+				require.NotNil(t, respErr)
+				assert.Equal(t, http.StatusBadGateway, respErr.Code)
+			},
+		},
+		"with too many requests error": {
+			config: []byte(`
+headers:
+  X-Foo: bar
+`),
+			error: &heimdall.TooManyRequestsError{Limit: 10, RetryAfter: 2 * time.Second},
+			assert: func(t *testing.T, err error, respErr *heimdall.ResponseError) {
+				t.Helper()
+
+				require.NoError(t, err)
+				require.NotNil(t, respErr)
+				assert.Equal(t, http.StatusTooManyRequests, respErr.Code)
+				assert.Equal(t, "bar", respErr.Headers.Get("X-Foo"))
+				assert.Equal(t, "2", respErr.Headers.Get("Retry-After"))
+				assert.Equal(t, "10", respErr.Headers.Get("RateLimit-Limit"))
+			},
+		},
+		"with header template rendering error": {
+			config: []byte(`
+headers:
+  X-Foo: "{{ len .foobar }}"
+`),
+			error: heimdall.ErrAuthentication,
+			assert: func(t *testing.T, err error, respErr *heimdall.ResponseError) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrInternal)
+				assert.Contains(t, err.Error(), "failed to render 'X-Foo' header")
+				assert.Nil(t, respErr)
+			},
+		},
+		"without accept header the first body is used": {
+			config: []byte(`
+body:
+  - media_type: text/html
+    template: "<p>{{ .Status }} - {{ .Request.URL.Path | html }}</p>"
+problem:
+  title: Forbidden
+`),
+			error: heimdall.ErrAuthentication,
+			assert: func(t *testing.T, err error, respErr *heimdall.ResponseError) {
+				t.Helper()
+
+				require.NoError(t, err)
+				require.NotNil(t, respErr)
+				assert.Equal(t, http.StatusUnauthorized, respErr.Code)
+				assert.Equal(t, "text/html", respErr.Headers.Get("Content-Type"))
+				assert.Equal(t, "nosniff", respErr.Headers.Get("X-Content-Type-Options"))
+				assert.Equal(t, "<p>401 - /baz</p>", string(respErr.Body))
+			},
+		},
+		"values rendered into html bodies are escaped": {
+			config: []byte(`
+body:
+  - media_type: text/html; charset=utf-8
+    template: "<p>Access denied for {{ .Subject.ID }}</p>"
+`),
+			error:   heimdall.ErrAuthorization,
+			subject: &subject.Subject{ID: "<script>alert(1)</script>"},
+			assert: func(t *testing.T, err error, respErr *heimdall.ResponseError) {
+				t.Helper()
+
+				require.NoError(t, err)
+				require.NotNil(t, respErr)
+				assert.Equal(t, "text/html;charset=utf-8", respErr.Headers.Get("Content-Type"))
+				assert.Equal(t, "<p>Access denied for &lt;script&gt;alert(1)&lt;/script&gt;</p>", string(respErr.Body))
+			},
+		},
+		"values rendered into non html bodies are not escaped": {
+			config: []byte(`
+body:
+  - media_type: text/plain
+    template: "Access denied for {{ .Subject.ID }}"
+`),
+			error:   heimdall.ErrAuthorization,
+			subject: &subject.Subject{ID: "<foo>"},
+			assert: func(t *testing.T, err error, respErr *heimdall.ResponseError) {
+				t.Helper()
+
+				require.NoError(t, err)
+				require.NotNil(t, respErr)
+				assert.Equal(t, "Access denied for <foo>", string(respErr.Body))
+			},
+		},
+		"with not matching accept header the first body is used": {
+			config: []byte(`
+body:
+  - media_type: text/plain
+    template: "{{ .Error.Type }}"
+  - media_type: text/html
+    template: "<p>{{ .Error.Type }}</p>"
+`),
+			error:  heimdall.ErrCommunication,
+			accept: "application/xml",
+			assert: func(t *testing.T, err error, respErr *heimdall.ResponseError) {
+				t.Helper()
+
+				require.NoError(t, err)
+				require.NotNil(t, respErr)
+				assert.Equal(t, http.StatusBadGateway, respErr.Code)
+				assert.Equal(t, "text/plain", respErr.Headers.Get("Content-Type"))
+				assert.Equal(t, "communication_error", string(respErr.Body))
+			},
+		},
+		"with accept header selecting the html body": {
+			config: []byte(`
+body:
+  - media_type: text/plain
+    template: "{{ .Error.Type }}"
+  - media_type: text/html
+    template: "<p>{{ .Error.Type }}</p>"
+`),
+			error:  heimdall.ErrArgument,
+			accept: "text/html,text/plain;q=0.5",
+			assert: func(t *testing.T, err error, respErr *heimdall.ResponseError) {
+				t.Helper()
+
+				require.NoError(t, err)
+				require.NotNil(t, respErr)
+				assert.Equal(t, http.StatusBadRequest, respErr.Code)
+				assert.Equal(t, "text/html", respErr.Headers.Get("Content-Type"))
+				assert.Equal(t, "<p>precondition_error</p>", string(respErr.Body))
+			},
+		},
+		"with accept header selecting the problem document": {
+			config: []byte(`
+code: 403
+body:
+  - media_type: text/html
+    template: "<p>{{ .Error.Type }}</p>"
+problem:
+  type: https://errors.example.com/{{ .Error.Type }}
+  title: Access denied
+  detail: "{{ .Subject.ID }} is not allowed to access {{ .Request.URL.Path }}"
+  instance: "{{ .Request.URL.Path }}"
+`),
+			error:   heimdall.ErrAuthorization,
+			subject: &subject.Subject{ID: "foo"},
+			accept:  "application/json, application/problem+json",
+			assert: func(t *testing.T, err error, respErr *heimdall.ResponseError) {
+				t.Helper()
+
+				require.NoError(t, err)
+				require.NotNil(t, respErr)
+				assert.Equal(t, http.StatusForbidden, respErr.Code)
+				assert.Equal(t, "application/problem+json", respErr.Headers.Get("Content-Type"))
+
+				var doc map[string]any
+				require.NoError(t, json.Unmarshal(respErr.Body, &doc))
+				assert.Len(t, doc, 5)
+				assert.Equal(t, "https://errors.example.com/authorization_error", doc["type"])
+				assert.Equal(t, "Access denied", doc["title"])
+				assert.Equal(t, "foo is not allowed to access /baz", doc["detail"])
+				assert.Equal(t, "/baz", doc["instance"])
+				assert.InDelta(t, 403, doc["status"], 0.0)
+			},
+		},
+		"with body template rendering error": {
+			config: []byte(`
+problem:
+  title: "{{ len .foobar }}"
+`),
+			error: heimdall.ErrInternal,
+			assert: func(t *testing.T, err error, respErr *heimdall.ResponseError) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrInternal)
+				assert.Contains(t, err.Error(), "failed to render 'application/problem+json' body")
+				assert.Nil(t, respErr)
+			},
+		},
+	} {
+		t.Run(uc, func(t *testing.T) {
+			// GIVEN
+			conf, err := testsupport.DecodeTestConfig(tc.config)
+			require.NoError(t, err)
+
+			reqf := mocks.NewRequestFunctionsMock(t)
+			reqf.EXPECT().Header("Accept").Maybe().Return(tc.accept)
+
+			var respErr *heimdall.ResponseError
+
+			mctx := mocks.NewRequestContextMock(t)
+			mctx.EXPECT().Context().Return(t.Context())
+			mctx.EXPECT().Request().Return(&heimdall.Request{
+				RequestFunctions: reqf,
+				URL:              &heimdall.URL{URL: *requestURL},
+			})
+			mctx.EXPECT().SetPipelineError(mock.Anything).Run(func(err error) {
+				respErr = err.(*heimdall.ResponseError) // nolint: forcetypeassert
+			}).Maybe()
+
+			validator, err := validation.NewValidator(
+				validation.WithTagValidator(config.EnforcementSettings{}),
+			)
+			require.NoError(t, err)
+
+			respondConf := config.RespondConfig{}
+			respondConf.With.AuthorizationError.Code = tc.authzCode
+
+			appCtx := app.NewContextMock(t)
+			appCtx.EXPECT().Validator().Maybe().Return(validator)
+			appCtx.EXPECT().Logger().Return(log.Logger)
+			appCtx.EXPECT().Config().Return(&config.Configuration{Serve: config.ServeConfig{Respond: respondConf}})
+
+			errorHandler, err := newResponseErrorHandler(appCtx, "foo", conf)
+			require.NoError(t, err)
+
+			// WHEN
+			execErr := errorHandler.Execute(mctx, tc.subject, tc.error)
+
+			// THEN
+			tc.assert(t, execErr, respErr)
+		})
+	}
+}
@@ -17,10 +17,16 @@
 package errorhandlers
 
 import (
+	"errors"
+	"fmt"
+	"strings"
+
 	"github.com/rs/zerolog"
 
 	"github.com/dadrus/heimdall/internal/app"
 	"github.com/dadrus/heimdall/internal/heimdall"
+	"github.com/dadrus/heimdall/internal/rules/mechanisms/oauth2"
+	"github.com/dadrus/heimdall/internal/rules/mechanisms/subject"
 	"github.com/dadrus/heimdall/internal/x"
 )
 
@@ -40,10 +46,15 @@ func init() {
 		})
 }
 
+type dpopChallenge struct {
+	Algorithms []string `mapstructure:"algorithms"`
+}
+
 type wwwAuthenticateErrorHandler struct {
 	id    string
 	app   app.Context
 	realm string
+	dpop  *dpopChallenge
 }
 
 func newWWWAuthenticateErrorHandler(
@@ -53,7 +64,8 @@ func newWWWAuthenticateErrorHandler(
 	logger.Info().Str("_id", id).Msg("Creating www-authenticate error handler")
 
 	type Config struct {
-		Realm string `mapstructure:"realm"`
+		Realm string         `mapstructure:"realm"`
+		DPoP  *dpopChallenge `mapstructure:"dpop"`
 	}
 
 	var conf Config
@@ -65,28 +77,51 @@ func newWWWAuthenticateErrorHandler(
 		id:    id,
 		app:   app,
 		realm: x.IfThenElse(len(conf.Realm) != 0, conf.Realm, "Please authenticate"),
+		dpop:  conf.DPoP,
 	}, nil
 }
 
 func (eh *wwwAuthenticateErrorHandler) ID() string { return eh.id }
 
-func (eh *wwwAuthenticateErrorHandler) Execute(ctx heimdall.RequestContext, _ error) error {
+func (eh *wwwAuthenticateErrorHandler) Execute(ctx heimdall.RequestContext, _ *subject.Subject, causeErr error) error {
 	logger := zerolog.Ctx(ctx.Context())
 	logger.Debug().Str("_id", eh.id).Msg("Handling error using www-authenticate error handler")
 
-	ctx.AddHeaderForUpstream("WWW-Authenticate", "Basic realm="+eh.realm)
+	if eh.dpop != nil {
+		ctx.AddHeaderForUpstream("WWW-Authenticate", eh.dpopChallenge(causeErr))
+	} else {
+		ctx.AddHeaderForUpstream("WWW-Authenticate", "Basic realm="+eh.realm)
+	}
+
 	ctx.SetPipelineError(heimdall.ErrAuthentication)
 
 	return nil
 }
 
+// dpopChallenge creates the challenge for the DPoP authentication scheme as defined in RFC 9449, section 7.1.
+func (eh *wwwAuthenticateErrorHandler) dpopChallenge(causeErr error) string {
+	builder := strings.Builder{}
+	builder.WriteString(fmt.Sprintf(`DPoP realm="%s"`, eh.realm))
+
+	if len(eh.dpop.Algorithms) != 0 {
+		builder.WriteString(fmt.Sprintf(`, algs="%s"`, strings.Join(eh.dpop.Algorithms, " ")))
+	}
+
+	if errors.Is(causeErr, oauth2.ErrInvalidDPoPProof) {
+		builder.WriteString(`, error="invalid_dpop_proof"`)
+	}
+
+	return builder.String()
+}
+
 func (eh *wwwAuthenticateErrorHandler) WithConfig(rawConfig map[string]any) (ErrorHandler, error) {
 	if len(rawConfig) == 0 {
 		return eh, nil
 	}
 
 	type Config struct {
-		Realm string `mapstructure:"realm"`
+		Realm string         `mapstructure:"realm"`
+		DPoP  *dpopChallenge `mapstructure:"dpop"`
 	}
 
 	var (
@@ -102,5 +137,6 @@ func (eh *wwwAuthenticateErrorHandler) WithConfig(rawConfig map[string]any) (Err
 		id:    eh.id,
 		app:   eh.app,
 		realm: conf.Realm,
+		dpop:  x.IfThenElse(conf.DPoP != nil, conf.DPoP, eh.dpop),
 	}, nil
 }
@@ -28,7 +28,9 @@ import (
 	"github.com/dadrus/heimdall/internal/app"
 	"github.com/dadrus/heimdall/internal/heimdall"
 	"github.com/dadrus/heimdall/internal/heimdall/mocks"
+	"github.com/dadrus/heimdall/internal/rules/mechanisms/oauth2"
 	"github.com/dadrus/heimdall/internal/validation"
+	"github.com/dadrus/heimdall/internal/x/errorchain"
 	"github.com/dadrus/heimdall/internal/x/testsupport"
 )
 
@@ -139,6 +141,26 @@ func TestCreateWWWAuthenticateErrorHandlerFromPrototype(t *testing.T) {
 				require.ErrorContains(t, err, "failed decoding")
 			},
 		},
+		"with 'dpop' reconfigured": {
+			prototypeConfig: []byte(`realm: "Foobar"`),
+			config: []byte(`
+realm: "Foobar"
+dpop:
+  algorithms:
+    - ES256
+`),
+			assert: func(t *testing.T, err error, prototype *wwwAuthenticateErrorHandler,
+				configured *wwwAuthenticateErrorHandler,
+			) {
+				t.Helper()
+
+				require.NoError(t, err)
+				assert.Nil(t, prototype.dpop)
+				require.NotNil(t, configured.dpop)
+				assert.Equal(t, []string{"ES256"}, configured.dpop.Algorithms)
+				assert.Equal(t, prototype.realm, configured.realm)
+			},
+		},
 		"with 'realm' reconfigured": {
 			prototypeConfig: []byte(`realm: "Foobar"`),
 			config:          []byte(`realm: "You password please"`),
@@ -242,6 +264,44 @@ func TestWWWAuthenticateErrorHandlerExecute(t *testing.T) {
 			assert: func(t *testing.T, err error) {
 				t.Helper()
 
+				require.NoError(t, err)
+			},
+		},
+		"with dpop challenge": {
+			config: []byte(`
+realm: api
+dpop:
+  algorithms:
+    - ES256
+    - PS256
+`),
+			error: heimdall.ErrAuthentication,
+			configureContext: func(t *testing.T, ctx *mocks.RequestContextMock) {
+				t.Helper()
+
+				ctx.EXPECT().SetPipelineError(heimdall.ErrAuthentication)
+				ctx.EXPECT().AddHeaderForUpstream("WWW-Authenticate", `DPoP realm="api", algs="ES256 PS256"`)
+			},
+			assert: func(t *testing.T, err error) {
+				t.Helper()
+
+				require.NoError(t, err)
+			},
+		},
+		"with dpop challenge for an invalid dpop proof": {
+			config: []byte(`dpop: {}`),
+			error: errorchain.NewWithMessage(heimdall.ErrAuthentication, "DPoP proof verification failed").
+				CausedBy(oauth2.ErrInvalidDPoPProof),
+			configureContext: func(t *testing.T, ctx *mocks.RequestContextMock) {
+				t.Helper()
+
+				ctx.EXPECT().SetPipelineError(heimdall.ErrAuthentication)
+				ctx.EXPECT().AddHeaderForUpstream("WWW-Authenticate",
+					`DPoP realm="Please authenticate", error="invalid_dpop_proof"`)
+			},
+			assert: func(t *testing.T, err error) {
+				t.Helper()
+
 				require.NoError(t, err)
 			},
 		},
@@ -267,7 +327,7 @@ func TestWWWAuthenticateErrorHandlerExecute(t *testing.T) {
 			require.NoError(t, err)
 
 			// WHEN
-			execErr := errorHandler.Execute(mctx, tc.error)
+			execErr := errorHandler.Execute(mctx, nil, tc.error)
 
 			// THEN
 			tc.assert(t, execErr)
@@ -21,5 +21,8 @@ const (
 	FinalizerJwt                     = "jwt"
 	FinalizerHeader                  = "header"
 	FinalizerCookie                  = "cookie"
+	FinalizerQueryParameter          = "query_parameter"
+	FinalizerDynamicMetadata         = "dynamic_metadata"
 	FinalizerOAuth2ClientCredentials = "oauth2_client_credentials" // nolint: gosec
+	FinalizerOAuth2TokenExchange     = "oauth2_token_exchange"     // nolint: gosec
 )
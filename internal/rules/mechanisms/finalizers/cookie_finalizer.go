@@ -17,15 +17,22 @@
 package finalizers
 
 import (
+	"net/http"
+	"time"
+
 	"github.com/rs/zerolog"
 
 	"github.com/dadrus/heimdall/internal/app"
 	"github.com/dadrus/heimdall/internal/heimdall"
 	"github.com/dadrus/heimdall/internal/rules/mechanisms/subject"
 	"github.com/dadrus/heimdall/internal/rules/mechanisms/template"
+	"github.com/dadrus/heimdall/internal/session"
+	"github.com/dadrus/heimdall/internal/x"
 	"github.com/dadrus/heimdall/internal/x/errorchain"
 )
 
+const defaultSessionCookieName = "heimdall_sid"
+
 // by intention. Used only during application bootstrap
 //
 //nolint:gochecknoinits
@@ -42,10 +49,21 @@ func init() {
 		})
 }
 
+type cookieSessionConfig struct {
+	CookieName      string                       `mapstructure:"cookie_name"`
+	Domain          string                       `mapstructure:"domain"`
+	Path            string                       `mapstructure:"path"`
+	SameSite        string                       `mapstructure:"same_site"        validate:"omitempty,oneof=lax strict none"` //nolint:lll
+	RotateAfter     time.Duration                `mapstructure:"rotate_after"`
+	RefreshMaterial map[string]template.Template `mapstructure:"refresh_material"`
+}
+
 type cookieFinalizer struct {
 	id      string
 	app     app.Context
 	cookies map[string]template.Template
+	session *cookieSessionConfig
+	sm      session.Manager
 }
 
 func newCookieFinalizer(app app.Context, id string, rawConfig map[string]any) (*cookieFinalizer, error) {
@@ -53,7 +71,8 @@ func newCookieFinalizer(app app.Context, id string, rawConfig map[string]any) (*
 	logger.Info().Str("_id", id).Msg("Creating cookie finalizer")
 
 	type Config struct {
-		Cookies map[string]template.Template `mapstructure:"cookies" validate:"required,gt=0"`
+		Cookies map[string]template.Template `mapstructure:"cookies" validate:"required_without=Session,omitempty,gt=0"`
+		Session *cookieSessionConfig         `mapstructure:"session"`
 	}
 
 	var conf Config
@@ -62,10 +81,25 @@ func newCookieFinalizer(app app.Context, id string, rawConfig map[string]any) (*
 			"failed decoding config for cookie finalizer '%s'", id).CausedBy(err)
 	}
 
+	var sm session.Manager
+
+	if conf.Session != nil {
+		if sm = app.SessionManager(); sm == nil {
+			return nil, errorchain.NewWithMessagef(heimdall.ErrConfiguration,
+				"cookie finalizer '%s' requires session management to be configured", id)
+		}
+
+		conf.Session.CookieName = x.IfThenElse(len(conf.Session.CookieName) != 0,
+			conf.Session.CookieName, defaultSessionCookieName)
+		conf.Session.Path = x.IfThenElse(len(conf.Session.Path) != 0, conf.Session.Path, "/")
+	}
+
 	return &cookieFinalizer{
 		id:      id,
 		app:     app,
 		cookies: conf.Cookies,
+		session: conf.Session,
+		sm:      sm,
 	}, nil
 }
 
@@ -97,6 +131,78 @@ func (f *cookieFinalizer) Execute(ctx heimdall.RequestContext, sub *subject.Subj
 		ctx.AddCookieForUpstream(name, value)
 	}
 
+	if f.session != nil {
+		return f.handleSession(ctx, sub)
+	}
+
+	return nil
+}
+
+// handleSession creates a session for the given subject, or rotates the existing one, if
+// it is due. In both cases the cookie referencing the session is set in the client.
+func (f *cookieFinalizer) handleSession(ctx heimdall.RequestContext, sub *subject.Subject) error {
+	logger := zerolog.Ctx(ctx.Context())
+
+	var (
+		sess *session.Session
+		err  error
+	)
+
+	if id := ctx.Request().Cookie(f.session.CookieName); len(id) != 0 {
+		// a session of another subject is replaced by a new one
+		if sess, err = f.sm.Load(ctx.Context(), id); err != nil || sess.Subject.ID != sub.ID {
+			sess = nil
+		} else {
+			if f.session.RotateAfter == 0 || time.Since(sess.RotatedAt) < f.session.RotateAfter {
+				return nil
+			}
+
+			logger.Debug().Str("_id", f.id).Msg("Rotating session")
+
+			if sess, err = f.sm.Rotate(ctx.Context(), sess); err != nil {
+				return errorchain.NewWithMessage(heimdall.ErrInternal, "failed to rotate session").
+					WithErrorContext(f).
+					CausedBy(err)
+			}
+		}
+	}
+
+	if sess == nil {
+		logger.Debug().Str("_id", f.id).Msg("Creating session")
+
+		refreshMaterial := make(map[string]string, len(f.session.RefreshMaterial))
+
+		for name, tmpl := range f.session.RefreshMaterial {
+			if refreshMaterial[name], err = tmpl.Render(map[string]any{
+				"Request": ctx.Request(),
+				"Subject": sub,
+				"Outputs": ctx.Outputs(),
+			}); err != nil {
+				return errorchain.
+					NewWithMessagef(heimdall.ErrInternal, "failed to render value for '%s' refresh material", name).
+					WithErrorContext(f).
+					CausedBy(err)
+			}
+		}
+
+		if sess, err = f.sm.Create(ctx.Context(), sub, refreshMaterial); err != nil {
+			return errorchain.NewWithMessage(heimdall.ErrInternal, "failed to create session").
+				WithErrorContext(f).
+				CausedBy(err)
+		}
+	}
+
+	ctx.AddCookieForClient(&http.Cookie{
+		Name:     f.session.CookieName,
+		Value:    sess.ID,
+		Domain:   f.session.Domain,
+		Path:     f.session.Path,
+		Expires:  sess.ExpiresAt,
+		Secure:   ctx.Request().URL.Scheme == "https",
+		HttpOnly: true,
+		SameSite: sameSite(f.session.SameSite),
+	})
+
 	return nil
 }
 
@@ -111,3 +217,14 @@ func (f *cookieFinalizer) WithConfig(config map[string]any) (Finalizer, error) {
 func (f *cookieFinalizer) ID() string { return f.id }
 
 func (f *cookieFinalizer) ContinueOnError() bool { return false }
+
+func sameSite(mode string) http.SameSite {
+	switch mode {
+	case "strict":
+		return http.SameSiteStrictMode
+	case "none":
+		return http.SameSiteNoneMode
+	default:
+		return http.SameSiteLaxMode
+	}
+}
@@ -17,16 +17,22 @@
 package finalizers
 
 import (
+	"net/http"
+	"net/url"
 	"testing"
+	"time"
 
 	"github.com/rs/zerolog/log"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 
 	"github.com/dadrus/heimdall/internal/app"
 	"github.com/dadrus/heimdall/internal/heimdall"
 	"github.com/dadrus/heimdall/internal/heimdall/mocks"
 	"github.com/dadrus/heimdall/internal/rules/mechanisms/subject"
+	"github.com/dadrus/heimdall/internal/session"
+	sessionmocks "github.com/dadrus/heimdall/internal/session/mocks"
 	"github.com/dadrus/heimdall/internal/validation"
 	"github.com/dadrus/heimdall/internal/x"
 	"github.com/dadrus/heimdall/internal/x/testsupport"
@@ -330,3 +336,333 @@ cookies:
 		})
 	}
 }
+
+func TestCreateCookieFinalizerWithSession(t *testing.T) {
+	t.Parallel()
+
+	for uc, tc := range map[string]struct {
+		config       []byte
+		withoutStore bool
+		assert       func(t *testing.T, err error, finalizer *cookieFinalizer)
+	}{
+		"without session management": {
+			config:       []byte(`session: {}`),
+			withoutStore: true,
+			assert: func(t *testing.T, err error, _ *cookieFinalizer) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrConfiguration)
+				assert.Contains(t, err.Error(), "requires session management")
+			},
+		},
+		"with unsupported same site mode": {
+			config: []byte(`
+session:
+  same_site: foo
+`),
+			assert: func(t *testing.T, err error, _ *cookieFinalizer) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrConfiguration)
+				assert.Contains(t, err.Error(), "'same_site' must be one of")
+			},
+		},
+		"with session only using defaults": {
+			config: []byte(`session: {}`),
+			assert: func(t *testing.T, err error, finalizer *cookieFinalizer) {
+				t.Helper()
+
+				require.NoError(t, err)
+				assert.Empty(t, finalizer.cookies)
+				require.NotNil(t, finalizer.session)
+				assert.NotNil(t, finalizer.sm)
+				assert.Equal(t, "heimdall_sid", finalizer.session.CookieName)
+				assert.Equal(t, "/", finalizer.session.Path)
+				assert.Zero(t, finalizer.session.RotateAfter)
+			},
+		},
+		"with cookies and full session configuration": {
+			config: []byte(`
+cookies:
+  foo: bar
+session:
+  cookie_name: sid
+  domain: example.com
+  path: /foo
+  same_site: strict
+  rotate_after: 10m
+  refresh_material:
+    token: "{{ .Outputs.token }}"
+`),
+			assert: func(t *testing.T, err error, finalizer *cookieFinalizer) {
+				t.Helper()
+
+				require.NoError(t, err)
+				assert.Len(t, finalizer.cookies, 1)
+				require.NotNil(t, finalizer.session)
+				assert.Equal(t, "sid", finalizer.session.CookieName)
+				assert.Equal(t, "example.com", finalizer.session.Domain)
+				assert.Equal(t, "/foo", finalizer.session.Path)
+				assert.Equal(t, "strict", finalizer.session.SameSite)
+				assert.Equal(t, 10*time.Minute, finalizer.session.RotateAfter)
+				assert.Len(t, finalizer.session.RefreshMaterial, 1)
+			},
+		},
+	} {
+		t.Run(uc, func(t *testing.T) {
+			// GIVEN
+			conf, err := testsupport.DecodeTestConfig(tc.config)
+			require.NoError(t, err)
+
+			validator, err := validation.NewValidator()
+			require.NoError(t, err)
+
+			appCtx := app.NewContextMock(t)
+			appCtx.EXPECT().Validator().Maybe().Return(validator)
+			appCtx.EXPECT().Logger().Return(log.Logger)
+
+			if tc.withoutStore {
+				appCtx.EXPECT().SessionManager().Return(nil)
+			} else {
+				appCtx.EXPECT().SessionManager().Maybe().Return(sessionmocks.NewManagerMock(t))
+			}
+
+			// WHEN
+			finalizer, err := newCookieFinalizer(appCtx, uc, conf)
+
+			// THEN
+			tc.assert(t, err, finalizer)
+		})
+	}
+}
+
+func TestCookieFinalizerExecuteWithSession(t *testing.T) {
+	t.Parallel()
+
+	sub := &subject.Subject{ID: "foo", Attributes: map[string]any{"bar": "baz"}}
+	expiresAt := time.Now().Add(time.Hour).UTC()
+
+	for uc, tc := range map[string]struct {
+		config []byte
+		cookie string
+		setup  func(t *testing.T, sm *sessionmocks.ManagerMock)
+		assert func(t *testing.T, err error, cookies []*http.Cookie)
+	}{
+		"no session present": {
+			config: []byte(`
+session:
+  same_site: none
+  refresh_material:
+    token: "{{ .Outputs.token }}"
+`),
+			setup: func(t *testing.T, sm *sessionmocks.ManagerMock) {
+				t.Helper()
+
+				sm.EXPECT().Create(mock.Anything, sub, map[string]string{"token": "secret"}).
+					Return(&session.Session{ID: "new", Subject: sub, ExpiresAt: expiresAt}, nil)
+			},
+			assert: func(t *testing.T, err error, cookies []*http.Cookie) {
+				t.Helper()
+
+				require.NoError(t, err)
+				require.Len(t, cookies, 1)
+				assert.Equal(t, "heimdall_sid", cookies[0].Name)
+				assert.Equal(t, "new", cookies[0].Value)
+				assert.Equal(t, "/", cookies[0].Path)
+				assert.Equal(t, expiresAt, cookies[0].Expires)
+				assert.True(t, cookies[0].Secure)
+				assert.True(t, cookies[0].HttpOnly)
+				assert.Equal(t, http.SameSiteNoneMode, cookies[0].SameSite)
+			},
+		},
+		"session not valid anymore": {
+			config: []byte(`session: {}`),
+			cookie: "old",
+			setup: func(t *testing.T, sm *sessionmocks.ManagerMock) {
+				t.Helper()
+
+				sm.EXPECT().Load(mock.Anything, "old").Return(nil, session.ErrNoSession)
+				sm.EXPECT().Create(mock.Anything, sub, map[string]string{}).
+					Return(&session.Session{ID: "new", Subject: sub, ExpiresAt: expiresAt}, nil)
+			},
+			assert: func(t *testing.T, err error, cookies []*http.Cookie) {
+				t.Helper()
+
+				require.NoError(t, err)
+				require.Len(t, cookies, 1)
+				assert.Equal(t, "new", cookies[0].Value)
+				assert.Equal(t, http.SameSiteLaxMode, cookies[0].SameSite)
+			},
+		},
+		"session of another subject": {
+			config: []byte(`session: {}`),
+			cookie: "old",
+			setup: func(t *testing.T, sm *sessionmocks.ManagerMock) {
+				t.Helper()
+
+				sm.EXPECT().Load(mock.Anything, "old").
+					Return(&session.Session{ID: "old", Subject: &subject.Subject{ID: "bar"}}, nil)
+				sm.EXPECT().Create(mock.Anything, sub, map[string]string{}).
+					Return(&session.Session{ID: "new", Subject: sub, ExpiresAt: expiresAt}, nil)
+			},
+			assert: func(t *testing.T, err error, cookies []*http.Cookie) {
+				t.Helper()
+
+				require.NoError(t, err)
+				require.Len(t, cookies, 1)
+				assert.Equal(t, "new", cookies[0].Value)
+			},
+		},
+		"valid session without rotation": {
+			config: []byte(`session: {}`),
+			cookie: "current",
+			setup: func(t *testing.T, sm *sessionmocks.ManagerMock) {
+				t.Helper()
+
+				sm.EXPECT().Load(mock.Anything, "current").Return(&session.Session{
+					ID: "current", Subject: sub, RotatedAt: time.Now().Add(-time.Hour),
+				}, nil)
+			},
+			assert: func(t *testing.T, err error, cookies []*http.Cookie) {
+				t.Helper()
+
+				require.NoError(t, err)
+				assert.Empty(t, cookies)
+			},
+		},
+		"valid session with rotation not due": {
+			config: []byte(`
+session:
+  rotate_after: 10m
+`),
+			cookie: "current",
+			setup: func(t *testing.T, sm *sessionmocks.ManagerMock) {
+				t.Helper()
+
+				sm.EXPECT().Load(mock.Anything, "current").Return(&session.Session{
+					ID: "current", Subject: sub, RotatedAt: time.Now().Add(-time.Minute),
+				}, nil)
+			},
+			assert: func(t *testing.T, err error, cookies []*http.Cookie) {
+				t.Helper()
+
+				require.NoError(t, err)
+				assert.Empty(t, cookies)
+			},
+		},
+		"valid session with rotation due": {
+			config: []byte(`
+session:
+  cookie_name: sid
+  domain: example.com
+  rotate_after: 10m
+`),
+			cookie: "current",
+			setup: func(t *testing.T, sm *sessionmocks.ManagerMock) {
+				t.Helper()
+
+				current := &session.Session{ID: "current", Subject: sub, RotatedAt: time.Now().Add(-time.Hour)}
+
+				sm.EXPECT().Load(mock.Anything, "current").Return(current, nil)
+				sm.EXPECT().Rotate(mock.Anything, current).
+					Return(&session.Session{ID: "rotated", Subject: sub, ExpiresAt: expiresAt}, nil)
+			},
+			assert: func(t *testing.T, err error, cookies []*http.Cookie) {
+				t.Helper()
+
+				require.NoError(t, err)
+				require.Len(t, cookies, 1)
+				assert.Equal(t, "sid", cookies[0].Name)
+				assert.Equal(t, "rotated", cookies[0].Value)
+				assert.Equal(t, "example.com", cookies[0].Domain)
+			},
+		},
+		"session rotation fails": {
+			config: []byte(`
+session:
+  rotate_after: 10m
+`),
+			cookie: "current",
+			setup: func(t *testing.T, sm *sessionmocks.ManagerMock) {
+				t.Helper()
+
+				current := &session.Session{ID: "current", Subject: sub, RotatedAt: time.Now().Add(-time.Hour)}
+
+				sm.EXPECT().Load(mock.Anything, "current").Return(current, nil)
+				sm.EXPECT().Rotate(mock.Anything, current).Return(nil, heimdall.ErrInternal)
+			},
+			assert: func(t *testing.T, err error, cookies []*http.Cookie) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrInternal)
+				assert.Contains(t, err.Error(), "failed to rotate session")
+				assert.Empty(t, cookies)
+			},
+		},
+		"session creation fails": {
+			config: []byte(`session: {}`),
+			setup: func(t *testing.T, sm *sessionmocks.ManagerMock) {
+				t.Helper()
+
+				sm.EXPECT().Create(mock.Anything, sub, map[string]string{}).Return(nil, heimdall.ErrInternal)
+			},
+			assert: func(t *testing.T, err error, cookies []*http.Cookie) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrInternal)
+				assert.Contains(t, err.Error(), "failed to create session")
+
+				var identifier interface{ ID() string }
+				require.ErrorAs(t, err, &identifier)
+				assert.Equal(t, "session creation fails", identifier.ID())
+				assert.Empty(t, cookies)
+			},
+		},
+	} {
+		t.Run(uc, func(t *testing.T) {
+			// GIVEN
+			conf, err := testsupport.DecodeTestConfig(tc.config)
+			require.NoError(t, err)
+
+			validator, err := validation.NewValidator()
+			require.NoError(t, err)
+
+			sm := sessionmocks.NewManagerMock(t)
+			tc.setup(t, sm)
+
+			appCtx := app.NewContextMock(t)
+			appCtx.EXPECT().Validator().Maybe().Return(validator)
+			appCtx.EXPECT().Logger().Return(log.Logger)
+			appCtx.EXPECT().SessionManager().Return(sm)
+
+			var cookies []*http.Cookie
+
+			reqf := mocks.NewRequestFunctionsMock(t)
+			reqf.EXPECT().Cookie(mock.Anything).Return(tc.cookie)
+
+			mctx := mocks.NewRequestContextMock(t)
+			mctx.EXPECT().Context().Return(t.Context())
+			mctx.EXPECT().Request().Return(&heimdall.Request{
+				RequestFunctions: reqf,
+				URL:              &heimdall.URL{URL: url.URL{Scheme: "https", Host: "example.com", Path: "/foo"}},
+			})
+			mctx.EXPECT().Outputs().Maybe().Return(map[string]any{"token": "secret"})
+			mctx.EXPECT().AddCookieForClient(mock.Anything).Run(func(cookie *http.Cookie) {
+				cookies = append(cookies, cookie)
+			}).Maybe()
+
+			finalizer, err := newCookieFinalizer(appCtx, uc, conf)
+			require.NoError(t, err)
+
+			// WHEN
+			err = finalizer.Execute(mctx, sub)
+
+			// THEN
+			tc.assert(t, err, cookies)
+		})
+	}
+}
@@ -29,8 +29,8 @@ import (
 func TestCreateFinalizerPrototype(t *testing.T) {
 	t.Parallel()
 
-	// there are 4 finalizers implemented, which should have been registered
-	require.Len(t, typeFactories, 5)
+	// there are 8 finalizers implemented, which should have been registered
+	require.Len(t, typeFactories, 8)
 
 	for uc, tc := range map[string]struct {
 		typ    string
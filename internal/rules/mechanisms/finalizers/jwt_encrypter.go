@@ -0,0 +1,203 @@
+// Copyright 2025 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package finalizers
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/hex"
+	"time"
+
+	"github.com/go-jose/go-jose/v4"
+	"github.com/goccy/go-json"
+	"github.com/rs/zerolog"
+
+	"github.com/dadrus/heimdall/internal/cache"
+	"github.com/dadrus/heimdall/internal/heimdall"
+	"github.com/dadrus/heimdall/internal/rules/endpoint"
+	"github.com/dadrus/heimdall/internal/x"
+	"github.com/dadrus/heimdall/internal/x/errorchain"
+	"github.com/dadrus/heimdall/internal/x/stringx"
+)
+
+const defaultRecipientKeyCacheTTL = 10 * time.Minute
+
+type EncryptionConfig struct {
+	JWKSEndpoint      *endpoint.Endpoint `mapstructure:"jwks_endpoint"      validate:"required"`
+	KeyID             string             `mapstructure:"key_id"`
+	KeyAlgorithm      string             `mapstructure:"key_algorithm"`
+	ContentEncryption string             `mapstructure:"content_encryption"`
+	CacheTTL          *time.Duration     `mapstructure:"cache_ttl"`
+}
+
+// jwtEncrypter encrypts signed JWTs to a recipient key retrieved from a JWKS endpoint, resulting
+// in nested JWTs as described in RFC 7519, section 5.2.
+type jwtEncrypter struct {
+	ep     *endpoint.Endpoint
+	keyID  string
+	keyAlg jose.KeyAlgorithm
+	enc    jose.ContentEncryption
+	ttl    time.Duration
+}
+
+func newJWTEncrypter(conf *EncryptionConfig) *jwtEncrypter {
+	ep := conf.JWKSEndpoint
+
+	if ep.Headers == nil {
+		ep.Headers = make(map[string]string)
+	}
+
+	if _, ok := ep.Headers["Accept"]; !ok {
+		ep.Headers["Accept"] = "application/json"
+	}
+
+	return &jwtEncrypter{
+		ep:     ep,
+		keyID:  conf.KeyID,
+		keyAlg: jose.KeyAlgorithm(conf.KeyAlgorithm),
+		enc: x.IfThenElse(len(conf.ContentEncryption) != 0,
+			jose.ContentEncryption(conf.ContentEncryption), jose.A256GCM),
+		ttl: x.IfThenElseExec(conf.CacheTTL != nil,
+			func() time.Duration { return *conf.CacheTTL },
+			func() time.Duration { return defaultRecipientKeyCacheTTL }),
+	}
+}
+
+func (e *jwtEncrypter) Hash() []byte {
+	hash := sha256.New()
+	hash.Write(e.ep.Hash())
+	hash.Write(stringx.ToBytes(e.keyID))
+	hash.Write(stringx.ToBytes(string(e.keyAlg)))
+	hash.Write(stringx.ToBytes(string(e.enc)))
+
+	return hash.Sum(nil)
+}
+
+func (e *jwtEncrypter) Encrypt(ctx context.Context, token string) (string, error) {
+	key, err := e.recipientKey(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	keyAlg, err := e.keyAlgorithm(key)
+	if err != nil {
+		return "", err
+	}
+
+	encrypter, err := jose.NewEncrypter(e.enc,
+		jose.Recipient{Algorithm: keyAlg, Key: key.Key, KeyID: key.KeyID},
+		(&jose.EncrypterOptions{}).WithType("JWT").WithContentType("JWT"))
+	if err != nil {
+		return "", errorchain.NewWithMessage(heimdall.ErrInternal, "failed to create JWT encrypter").CausedBy(err)
+	}
+
+	obj, err := encrypter.Encrypt(stringx.ToBytes(token))
+	if err != nil {
+		return "", errorchain.NewWithMessage(heimdall.ErrInternal, "failed to encrypt JWT").CausedBy(err)
+	}
+
+	return obj.CompactSerialize()
+}
+
+func (e *jwtEncrypter) keyAlgorithm(key *jose.JSONWebKey) (jose.KeyAlgorithm, error) {
+	switch {
+	case len(e.keyAlg) != 0:
+		return e.keyAlg, nil
+	case len(key.Algorithm) != 0:
+		return jose.KeyAlgorithm(key.Algorithm), nil
+	}
+
+	switch key.Key.(type) {
+	case *rsa.PublicKey:
+		return jose.RSA_OAEP_256, nil
+	case *ecdsa.PublicKey:
+		return jose.ECDH_ES_A256KW, nil
+	default:
+		return "", errorchain.NewWithMessagef(heimdall.ErrInternal,
+			"unsupported type of recipient key '%s'", key.KeyID)
+	}
+}
+
+func (e *jwtEncrypter) recipientKey(ctx context.Context) (*jose.JSONWebKey, error) {
+	logger := zerolog.Ctx(ctx)
+	cch := cache.Ctx(ctx)
+	cacheKey := hex.EncodeToString(e.Hash())
+
+	if entry, err := cch.Get(ctx, cacheKey); err == nil {
+		var jwk jose.JSONWebKey
+
+		if err = json.Unmarshal(entry, &jwk); err == nil {
+			logger.Debug().Msg("Reusing recipient JWK from cache")
+
+			return &jwk, nil
+		}
+	}
+
+	logger.Debug().Msg("Retrieving recipient JWKS from configured endpoint")
+
+	rawJWKS, err := e.ep.SendRequest(ctx, nil, nil)
+	if err != nil {
+		return nil, errorchain.NewWithMessage(heimdall.ErrInternal, "failed to retrieve recipient JWKS").
+			CausedBy(err)
+	}
+
+	var jwks jose.JSONWebKeySet
+	if err = json.Unmarshal(rawJWKS, &jwks); err != nil {
+		return nil, errorchain.NewWithMessage(heimdall.ErrInternal, "failed to unmarshal received JWKS").
+			CausedBy(err)
+	}
+
+	jwk := e.selectKey(jwks.Keys)
+	if jwk == nil {
+		return nil, errorchain.NewWithMessage(heimdall.ErrInternal,
+			"no suitable encryption key found in the received JWKS")
+	}
+
+	if e.ttl > 0 {
+		data, _ := json.Marshal(jwk)
+
+		if err = cch.Set(ctx, cacheKey, data, e.ttl); err != nil {
+			logger.Warn().Err(err).Msg("Failed to cache recipient JWK")
+		}
+	}
+
+	return jwk, nil
+}
+
+func (e *jwtEncrypter) selectKey(keys []jose.JSONWebKey) *jose.JSONWebKey {
+	for idx := range keys {
+		key := &keys[idx]
+
+		if len(e.keyID) != 0 && key.KeyID != e.keyID {
+			continue
+		}
+
+		if len(key.Use) != 0 && key.Use != "enc" {
+			continue
+		}
+
+		if !key.IsPublic() {
+			continue
+		}
+
+		return key
+	}
+
+	return nil
+}
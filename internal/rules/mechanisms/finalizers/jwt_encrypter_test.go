@@ -0,0 +1,236 @@
+// Copyright 2025 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package finalizers
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-jose/go-jose/v4"
+	"github.com/goccy/go-json"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dadrus/heimdall/internal/cache"
+	"github.com/dadrus/heimdall/internal/cache/mocks"
+	"github.com/dadrus/heimdall/internal/heimdall"
+	"github.com/dadrus/heimdall/internal/rules/endpoint"
+)
+
+func TestJWTEncrypterEncrypt(t *testing.T) {
+	t.Parallel()
+
+	rsaPrivKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	ecPrivKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	sigPrivKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	jwks := jose.JSONWebKeySet{Keys: []jose.JSONWebKey{
+		{KeyID: "sig", Use: "sig", Key: &sigPrivKey.PublicKey},
+		{KeyID: "rsa", Use: "enc", Key: &rsaPrivKey.PublicKey},
+		{KeyID: "ec", Key: &ecPrivKey.PublicKey},
+	}}
+
+	var (
+		endpointCalled bool
+		responseCode   int
+		responseBody   []byte
+	)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		endpointCalled = true
+
+		assert.Equal(t, "application/json", r.Header.Get("Accept"))
+
+		if responseBody == nil {
+			w.WriteHeader(responseCode)
+
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_, err := w.Write(responseBody)
+		assert.NoError(t, err)
+	}))
+	defer srv.Close()
+
+	rawJWKS, err := json.Marshal(jwks)
+	require.NoError(t, err)
+
+	decrypt := func(t *testing.T, token string, key any) (*jose.JSONWebEncryption, string) {
+		t.Helper()
+
+		jwe, err := jose.ParseEncrypted(token,
+			[]jose.KeyAlgorithm{jose.RSA_OAEP, jose.RSA_OAEP_256, jose.ECDH_ES, jose.ECDH_ES_A256KW},
+			[]jose.ContentEncryption{jose.A256GCM, jose.A128CBC_HS256})
+		require.NoError(t, err)
+
+		plaintext, err := jwe.Decrypt(key)
+		require.NoError(t, err)
+
+		return jwe, string(plaintext)
+	}
+
+	for uc, tc := range map[string]struct {
+		config    EncryptionConfig
+		configure func(t *testing.T, cch *mocks.CacheMock)
+		assert    func(t *testing.T, err error, token string)
+	}{
+		"with error while retrieving jwks": {
+			config: EncryptionConfig{JWKSEndpoint: &endpoint.Endpoint{URL: srv.URL}},
+			configure: func(t *testing.T, cch *mocks.CacheMock) {
+				t.Helper()
+
+				responseCode = http.StatusBadGateway
+
+				cch.EXPECT().Get(mock.Anything, mock.Anything).Return(nil, assert.AnError)
+			},
+			assert: func(t *testing.T, err error, _ string) {
+				t.Helper()
+
+				assert.True(t, endpointCalled)
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrInternal)
+				require.ErrorIs(t, err, heimdall.ErrCommunication)
+				require.ErrorContains(t, err, "failed to retrieve recipient JWKS")
+			},
+		},
+		"without suitable key in the jwks": {
+			config: EncryptionConfig{JWKSEndpoint: &endpoint.Endpoint{URL: srv.URL}, KeyID: "sig"},
+			configure: func(t *testing.T, cch *mocks.CacheMock) {
+				t.Helper()
+
+				responseBody = rawJWKS
+
+				cch.EXPECT().Get(mock.Anything, mock.Anything).Return(nil, assert.AnError)
+			},
+			assert: func(t *testing.T, err error, _ string) {
+				t.Helper()
+
+				assert.True(t, endpointCalled)
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrInternal)
+				require.ErrorContains(t, err, "no suitable encryption key")
+			},
+		},
+		"with first suitable key and default algorithms": {
+			config: EncryptionConfig{JWKSEndpoint: &endpoint.Endpoint{URL: srv.URL}},
+			configure: func(t *testing.T, cch *mocks.CacheMock) {
+				t.Helper()
+
+				responseBody = rawJWKS
+
+				cch.EXPECT().Get(mock.Anything, mock.Anything).Return(nil, assert.AnError)
+				cch.EXPECT().Set(mock.Anything, mock.Anything, mock.Anything, 10*time.Minute).Return(nil)
+			},
+			assert: func(t *testing.T, err error, token string) {
+				t.Helper()
+
+				assert.True(t, endpointCalled)
+
+				require.NoError(t, err)
+
+				jwe, plaintext := decrypt(t, token, rsaPrivKey)
+				assert.Equal(t, "header.payload.signature", plaintext)
+				assert.Equal(t, "rsa", jwe.Header.KeyID)
+				assert.Equal(t, string(jose.RSA_OAEP_256), jwe.Header.Algorithm)
+				assert.Equal(t, "JWT", jwe.Header.ExtraHeaders[jose.HeaderContentType])
+			},
+		},
+		"with configured key id and algorithms and disabled cache": {
+			config: EncryptionConfig{
+				JWKSEndpoint:      &endpoint.Endpoint{URL: srv.URL},
+				KeyID:             "ec",
+				KeyAlgorithm:      string(jose.ECDH_ES),
+				ContentEncryption: string(jose.A128CBC_HS256),
+				CacheTTL:          func() *time.Duration { ttl := 0 * time.Second; return &ttl }(),
+			},
+			configure: func(t *testing.T, cch *mocks.CacheMock) {
+				t.Helper()
+
+				responseBody = rawJWKS
+
+				cch.EXPECT().Get(mock.Anything, mock.Anything).Return(nil, assert.AnError)
+			},
+			assert: func(t *testing.T, err error, token string) {
+				t.Helper()
+
+				assert.True(t, endpointCalled)
+
+				require.NoError(t, err)
+
+				jwe, plaintext := decrypt(t, token, ecPrivKey)
+				assert.Equal(t, "header.payload.signature", plaintext)
+				assert.Equal(t, "ec", jwe.Header.KeyID)
+				assert.Equal(t, string(jose.ECDH_ES), jwe.Header.Algorithm)
+			},
+		},
+		"with recipient key from cache": {
+			config: EncryptionConfig{JWKSEndpoint: &endpoint.Endpoint{URL: srv.URL}},
+			configure: func(t *testing.T, cch *mocks.CacheMock) {
+				t.Helper()
+
+				rawKey, err := json.Marshal(jose.JSONWebKey{KeyID: "ec", Key: &ecPrivKey.PublicKey})
+				require.NoError(t, err)
+
+				cch.EXPECT().Get(mock.Anything, mock.Anything).Return(rawKey, nil)
+			},
+			assert: func(t *testing.T, err error, token string) {
+				t.Helper()
+
+				assert.False(t, endpointCalled)
+
+				require.NoError(t, err)
+
+				jwe, plaintext := decrypt(t, token, ecPrivKey)
+				assert.Equal(t, "header.payload.signature", plaintext)
+				assert.Equal(t, string(jose.ECDH_ES_A256KW), jwe.Header.Algorithm)
+			},
+		},
+	} {
+		t.Run(uc, func(t *testing.T) {
+			// GIVEN
+			endpointCalled = false
+			responseCode = http.StatusOK
+			responseBody = nil
+
+			cch := mocks.NewCacheMock(t)
+			tc.configure(t, cch)
+
+			encrypter := newJWTEncrypter(&tc.config)
+
+			// WHEN
+			token, err := encrypter.Encrypt(cache.WithContext(t.Context(), cch), "header.payload.signature")
+
+			// THEN
+			tc.assert(t, err, token)
+		})
+	}
+}
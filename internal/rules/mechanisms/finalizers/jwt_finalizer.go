@@ -67,6 +67,7 @@ type jwtFinalizer struct {
 	headerName   string
 	headerScheme string
 	signer       *jwtSigner
+	encrypter    *jwtEncrypter
 	v            values.Values
 }
 
@@ -100,6 +101,11 @@ func newJWTFinalizer(app app.Context, id string, rawConfig map[string]any) (*jwt
 
 	app.KeyHolderRegistry().AddKeyHolder(signer)
 
+	var encrypter *jwtEncrypter
+	if conf.Signer.Encryption != nil {
+		encrypter = newJWTEncrypter(conf.Signer.Encryption)
+	}
+
 	fin := &jwtFinalizer{
 		id:     id,
 		app:    app,
@@ -113,8 +119,9 @@ func newJWTFinalizer(app app.Context, id string, rawConfig map[string]any) (*jwt
 		headerScheme: x.IfThenElseExec(conf.Header != nil,
 			func() string { return conf.Header.Scheme },
 			func() string { return "Bearer" }),
-		signer: signer,
-		v:      conf.Values,
+		signer:    signer,
+		encrypter: encrypter,
+		v:         conf.Values,
 	}
 
 	app.CertificateObserver().Add(fin)
@@ -191,6 +198,7 @@ func (f *jwtFinalizer) WithConfig(rawConfig map[string]any) (Finalizer, error) {
 		headerName:   f.headerName,
 		headerScheme: f.headerScheme,
 		signer:       f.signer,
+		encrypter:    f.encrypter,
 		v:            f.v.Merge(conf.Values),
 	}, nil
 }
@@ -247,6 +255,15 @@ func (f *jwtFinalizer) generateToken(ctx heimdall.RequestContext, sub *subject.S
 			CausedBy(err)
 	}
 
+	if f.encrypter != nil {
+		if token, err = f.encrypter.Encrypt(ctx.Context(), token); err != nil {
+			return "", errorchain.
+				NewWithMessage(heimdall.ErrInternal, "failed to encrypt token").
+				WithErrorContext(f).
+				CausedBy(err)
+		}
+	}
+
 	return token, nil
 }
 
@@ -261,6 +278,9 @@ func (f *jwtFinalizer) calculateCacheKey(ctx heimdall.RequestContext, sub *subje
 
 	hash := sha256.New()
 	hash.Write(f.signer.Hash())
+	hash.Write(x.IfThenElseExec(f.encrypter != nil,
+		func() []byte { return f.encrypter.Hash() },
+		func() []byte { return []byte{} }))
 	hash.Write(x.IfThenElseExec(f.claims != nil,
 		func() []byte { return f.claims.Hash() },
 		func() []byte { return []byte{} }))
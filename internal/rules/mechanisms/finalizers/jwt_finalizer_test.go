@@ -27,6 +27,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/go-jose/go-jose/v4"
 	"github.com/rs/zerolog/log"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
@@ -35,6 +36,7 @@ import (
 	"github.com/dadrus/heimdall/internal/app"
 	"github.com/dadrus/heimdall/internal/cache"
 	"github.com/dadrus/heimdall/internal/cache/mocks"
+	"github.com/dadrus/heimdall/internal/config"
 	"github.com/dadrus/heimdall/internal/heimdall"
 	heimdallmocks "github.com/dadrus/heimdall/internal/heimdall/mocks"
 	mocks3 "github.com/dadrus/heimdall/internal/keyholder/mocks"
@@ -195,6 +197,67 @@ signer:
 				assert.Empty(t, finalizer.Certificates())
 			},
 		},
+		"with signer using encryption": {
+			config: []byte(`
+signer:
+  key_store:
+    path: ` + pemFile + `
+  encryption:
+    jwks_endpoint: https://foo.bar/jwks
+    key_id: enc
+    key_algorithm: ECDH-ES+A256KW
+    content_encryption: A128GCM
+    cache_ttl: 1h
+`),
+			configureAppContext: func(t *testing.T, ctx *app.ContextMock) {
+				t.Helper()
+
+				wm := mocks2.NewWatcherMock(t)
+				wm.EXPECT().Add(pemFile, mock.Anything).Return(nil)
+
+				khr := mocks3.NewRegistryMock(t)
+				khr.EXPECT().AddKeyHolder(mock.Anything)
+
+				co := mocks4.NewObserverMock(t)
+				co.EXPECT().Add(mock.Anything)
+
+				ctx.EXPECT().Watcher().Return(wm)
+				ctx.EXPECT().KeyHolderRegistry().Return(khr)
+				ctx.EXPECT().CertificateObserver().Return(co)
+			},
+			assert: func(t *testing.T, err error, finalizer *jwtFinalizer) {
+				t.Helper()
+
+				require.NoError(t, err)
+
+				require.NotNil(t, finalizer)
+				require.NotNil(t, finalizer.signer)
+				require.NotNil(t, finalizer.encrypter)
+				assert.Equal(t, "https://foo.bar/jwks", finalizer.encrypter.ep.URL)
+				assert.Equal(t, "application/json", finalizer.encrypter.ep.Headers["Accept"])
+				assert.Equal(t, "enc", finalizer.encrypter.keyID)
+				assert.Equal(t, jose.ECDH_ES_A256KW, finalizer.encrypter.keyAlg)
+				assert.Equal(t, jose.A128GCM, finalizer.encrypter.enc)
+				assert.Equal(t, time.Hour, finalizer.encrypter.ttl)
+			},
+		},
+		"with signer using encryption without jwks endpoint": {
+			config: []byte(`
+signer:
+  key_store:
+    path: ` + pemFile + `
+  encryption:
+    key_id: enc
+`),
+			configureAppContext: func(t *testing.T, _ *app.ContextMock) { t.Helper() },
+			assert: func(t *testing.T, err error, _ *jwtFinalizer) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrConfiguration)
+				require.ErrorContains(t, err, "jwks_endpoint")
+			},
+		},
 		"with too short ttl": {
 			config: []byte(`
 ttl: 5ms
@@ -436,7 +499,9 @@ values:
 			conf, err := testsupport.DecodeTestConfig(tc.config)
 			require.NoError(t, err)
 
-			validator, err := validation.NewValidator()
+			validator, err := validation.NewValidator(
+				validation.WithTagValidator(config.EnforcementSettings{}),
+			)
 			require.NoError(t, err)
 
 			appCtx := app.NewContextMock(t)
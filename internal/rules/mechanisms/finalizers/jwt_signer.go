@@ -44,9 +44,10 @@ type KeyStore struct {
 }
 
 type SignerConfig struct {
-	Name     string   `mapstructure:"name"`
-	KeyStore KeyStore `mapstructure:"key_store" validate:"required"`
-	KeyID    string   `mapstructure:"key_id"`
+	Name       string            `mapstructure:"name"`
+	KeyStore   KeyStore          `mapstructure:"key_store"  validate:"required"`
+	KeyID      string            `mapstructure:"key_id"`
+	Encryption *EncryptionConfig `mapstructure:"encryption"`
 }
 
 type jwtSigner struct {
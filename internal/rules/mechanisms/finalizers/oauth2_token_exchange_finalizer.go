@@ -0,0 +1,292 @@
+// Copyright 2025 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package finalizers
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog"
+
+	"github.com/dadrus/heimdall/internal/app"
+	"github.com/dadrus/heimdall/internal/heimdall"
+	"github.com/dadrus/heimdall/internal/rules/mechanisms/subject"
+	"github.com/dadrus/heimdall/internal/rules/mechanisms/template"
+	"github.com/dadrus/heimdall/internal/rules/oauth2/clientcredentials"
+	"github.com/dadrus/heimdall/internal/rules/oauth2/tokenexchange"
+	"github.com/dadrus/heimdall/internal/x"
+	"github.com/dadrus/heimdall/internal/x/errorchain"
+)
+
+// by intention. Used only during application bootstrap
+//
+//nolint:gochecknoinits
+func init() {
+	registerTypeFactory(
+		func(app app.Context, id string, typ string, conf map[string]any) (bool, Finalizer, error) {
+			if typ != FinalizerOAuth2TokenExchange {
+				return false, nil, nil
+			}
+
+			finalizer, err := newOAuth2TokenExchangeFinalizer(app, id, conf)
+
+			return true, finalizer, err
+		})
+}
+
+type oauth2TokenExchangeFinalizer struct {
+	id                 string
+	app                app.Context
+	cfg                tokenexchange.Config
+	subjectToken       template.Template
+	subjectTokenType   string
+	requestedTokenType string
+	audiences          []template.Template
+	resources          []template.Template
+	scopes             []template.Template
+	headerName         string
+	headerScheme       string
+}
+
+func newOAuth2TokenExchangeFinalizer(
+	app app.Context,
+	id string,
+	rawConfig map[string]any,
+) (*oauth2TokenExchangeFinalizer, error) {
+	logger := app.Logger()
+	logger.Info().Str("_id", id).Msg("Creating oauth2_token_exchange finalizer")
+
+	type HeaderConfig struct {
+		Name   string `mapstructure:"name"   validate:"required"`
+		Scheme string `mapstructure:"scheme"`
+	}
+
+	type Config struct {
+		tokenexchange.Config `mapstructure:",squash"`
+		SubjectToken         template.Template   `mapstructure:"subject_token"`
+		SubjectTokenType     string              `mapstructure:"subject_token_type"`
+		RequestedTokenType   string              `mapstructure:"requested_token_type"`
+		Audiences            []template.Template `mapstructure:"audience"`
+		Resources            []template.Template `mapstructure:"resource"`
+		Scopes               []template.Template `mapstructure:"scopes"`
+		Header               *HeaderConfig       `mapstructure:"header"`
+	}
+
+	var conf Config
+	if err := decodeConfig(app.Validator(), rawConfig, &conf); err != nil {
+		return nil, errorchain.NewWithMessagef(heimdall.ErrConfiguration,
+			"failed decoding config for oauth2_token_exchange finalizer '%s'", id).CausedBy(err)
+	}
+
+	if strings.HasPrefix(conf.TokenURL, "http://") {
+		logger.Warn().Str("_id", id).
+			Msg("No TLS configured for the token_url used in oauth2_token_exchange finalizer")
+	}
+
+	conf.AuthMethod = x.IfThenElse(
+		len(conf.AuthMethod) == 0,
+		clientcredentials.AuthMethodBasicAuth,
+		conf.AuthMethod,
+	)
+
+	return &oauth2TokenExchangeFinalizer{
+		id:           id,
+		app:          app,
+		cfg:          conf.Config,
+		subjectToken: conf.SubjectToken,
+		subjectTokenType: x.IfThenElse(len(conf.SubjectTokenType) != 0,
+			conf.SubjectTokenType, tokenexchange.TokenTypeAccessToken),
+		requestedTokenType: conf.RequestedTokenType,
+		audiences:          conf.Audiences,
+		resources:          conf.Resources,
+		scopes:             conf.Scopes,
+		headerName: x.IfThenElseExec(conf.Header != nil,
+			func() string { return conf.Header.Name },
+			func() string { return "Authorization" }),
+		headerScheme: x.IfThenElseExec(conf.Header != nil,
+			func() string { return conf.Header.Scheme },
+			func() string { return "" }),
+	}, nil
+}
+
+func (f *oauth2TokenExchangeFinalizer) ContinueOnError() bool { return false }
+func (f *oauth2TokenExchangeFinalizer) ID() string            { return f.id }
+
+func (f *oauth2TokenExchangeFinalizer) WithConfig(rawConfig map[string]any) (Finalizer, error) {
+	if len(rawConfig) == 0 {
+		return f, nil
+	}
+
+	type HeaderConfig struct {
+		Name   string `mapstructure:"name"   validate:"required"`
+		Scheme string `mapstructure:"scheme"`
+	}
+
+	type Config struct {
+		SubjectToken       template.Template   `mapstructure:"subject_token"`
+		SubjectTokenType   string              `mapstructure:"subject_token_type"`
+		RequestedTokenType string              `mapstructure:"requested_token_type"`
+		Audiences          []template.Template `mapstructure:"audience"`
+		Resources          []template.Template `mapstructure:"resource"`
+		Scopes             []template.Template `mapstructure:"scopes"`
+		TTL                *time.Duration      `mapstructure:"cache_ttl"`
+		Header             *HeaderConfig       `mapstructure:"header"`
+	}
+
+	var conf Config
+	if err := decodeConfig(f.app.Validator(), rawConfig, &conf); err != nil {
+		return nil, errorchain.NewWithMessagef(heimdall.ErrConfiguration,
+			"failed decoding config for oauth2_token_exchange finalizer '%s'", f.id).CausedBy(err)
+	}
+
+	cfg := f.cfg
+	cfg.TTL = x.IfThenElse(conf.TTL != nil, conf.TTL, cfg.TTL)
+
+	return &oauth2TokenExchangeFinalizer{
+		id:           f.id,
+		app:          f.app,
+		cfg:          cfg,
+		subjectToken: x.IfThenElse(conf.SubjectToken != nil, conf.SubjectToken, f.subjectToken),
+		subjectTokenType: x.IfThenElse(len(conf.SubjectTokenType) != 0,
+			conf.SubjectTokenType, f.subjectTokenType),
+		requestedTokenType: x.IfThenElse(len(conf.RequestedTokenType) != 0,
+			conf.RequestedTokenType, f.requestedTokenType),
+		audiences: x.IfThenElse(conf.Audiences != nil, conf.Audiences, f.audiences),
+		resources: x.IfThenElse(conf.Resources != nil, conf.Resources, f.resources),
+		scopes:    x.IfThenElse(conf.Scopes != nil, conf.Scopes, f.scopes),
+		headerName: x.IfThenElseExec(conf.Header != nil,
+			func() string { return conf.Header.Name },
+			func() string { return f.headerName }),
+		headerScheme: x.IfThenElseExec(conf.Header != nil && len(conf.Header.Scheme) != 0,
+			func() string { return conf.Header.Scheme },
+			func() string { return f.headerScheme }),
+	}, nil
+}
+
+func (f *oauth2TokenExchangeFinalizer) Execute(ctx heimdall.RequestContext, sub *subject.Subject) error {
+	logger := zerolog.Ctx(ctx.Context())
+	logger.Debug().Str("_id", f.id).Msg("Finalizing using oauth2_token_exchange finalizer")
+
+	if sub == nil {
+		return errorchain.
+			NewWithMessage(heimdall.ErrInternal,
+				"failed to execute oauth2_token_exchange finalizer due to 'nil' subject").
+			WithErrorContext(f)
+	}
+
+	values := map[string]any{
+		"Request": ctx.Request(),
+		"Subject": sub,
+		"Outputs": ctx.Outputs(),
+	}
+
+	subjectToken, err := f.renderSubjectToken(ctx, values)
+	if err != nil {
+		return err
+	}
+
+	audiences, err := f.renderAll("audience", f.audiences, values)
+	if err != nil {
+		return err
+	}
+
+	resources, err := f.renderAll("resource", f.resources, values)
+	if err != nil {
+		return err
+	}
+
+	scopes, err := f.renderAll("scopes", f.scopes, values)
+	if err != nil {
+		return err
+	}
+
+	token, err := f.cfg.Exchange(ctx.Context(), &tokenexchange.Request{
+		SubjectToken:       subjectToken,
+		SubjectTokenType:   f.subjectTokenType,
+		RequestedTokenType: f.requestedTokenType,
+		Audiences:          audiences,
+		Resources:          resources,
+		Scopes:             scopes,
+	})
+	if err != nil {
+		return err
+	}
+
+	headerScheme := token.TokenType
+	if len(f.headerScheme) != 0 {
+		headerScheme = f.headerScheme
+	}
+
+	ctx.AddHeaderForUpstream(f.headerName, fmt.Sprintf("%s %s", headerScheme, token.AccessToken))
+
+	return nil
+}
+
+func (f *oauth2TokenExchangeFinalizer) renderSubjectToken(
+	ctx heimdall.RequestContext,
+	values map[string]any,
+) (string, error) {
+	var token string
+
+	if f.subjectToken != nil {
+		value, err := f.subjectToken.Render(values)
+		if err != nil {
+			return "", errorchain.NewWithMessage(heimdall.ErrInternal, "failed to render subject_token").
+				WithErrorContext(f).
+				CausedBy(err)
+		}
+
+		token = value
+	} else {
+		// by default, the bearer token from the original request is exchanged
+		scheme, value, found := strings.Cut(ctx.Request().Header("Authorization"), " ")
+		if found && strings.EqualFold(scheme, "Bearer") {
+			token = strings.TrimSpace(value)
+		}
+	}
+
+	if len(token) == 0 {
+		return "", errorchain.NewWithMessage(heimdall.ErrArgument, "no subject token available for exchange").
+			WithErrorContext(f)
+	}
+
+	return token, nil
+}
+
+func (f *oauth2TokenExchangeFinalizer) renderAll(
+	name string,
+	templates []template.Template,
+	values map[string]any,
+) ([]string, error) {
+	result := make([]string, 0, len(templates))
+
+	for _, tmpl := range templates {
+		value, err := tmpl.Render(values)
+		if err != nil {
+			return nil, errorchain.NewWithMessagef(heimdall.ErrInternal, "failed to render %s", name).
+				WithErrorContext(f).
+				CausedBy(err)
+		}
+
+		if len(value) != 0 {
+			result = append(result, value)
+		}
+	}
+
+	return result, nil
+}
@@ -0,0 +1,514 @@
+// Copyright 2025 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package finalizers
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/goccy/go-json"
+	"github.com/rs/zerolog/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dadrus/heimdall/internal/app"
+	"github.com/dadrus/heimdall/internal/cache"
+	mocks2 "github.com/dadrus/heimdall/internal/cache/mocks"
+	"github.com/dadrus/heimdall/internal/config"
+	"github.com/dadrus/heimdall/internal/heimdall"
+	"github.com/dadrus/heimdall/internal/heimdall/mocks"
+	"github.com/dadrus/heimdall/internal/rules/mechanisms/subject"
+	"github.com/dadrus/heimdall/internal/rules/oauth2/clientcredentials"
+	"github.com/dadrus/heimdall/internal/rules/oauth2/tokenexchange"
+	"github.com/dadrus/heimdall/internal/validation"
+	"github.com/dadrus/heimdall/internal/x/testsupport"
+)
+
+func TestNewTokenExchangeFinalizer(t *testing.T) {
+	t.Parallel()
+
+	for uc, tc := range map[string]struct {
+		enforceTLS bool
+		config     []byte
+		assert     func(t *testing.T, err error, finalizer *oauth2TokenExchangeFinalizer)
+	}{
+		"without configuration": {
+			assert: func(t *testing.T, err error, _ *oauth2TokenExchangeFinalizer) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrConfiguration)
+				assert.Contains(t, err.Error(), "'token_url' is a required field")
+			},
+		},
+		"with unsupported attributes": {
+			config: []byte(`
+token_url: https://foo.bar
+client_id: foo
+client_secret: bar
+foo: bar
+`),
+			assert: func(t *testing.T, err error, _ *oauth2TokenExchangeFinalizer) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrConfiguration)
+				assert.Contains(t, err.Error(), "invalid keys")
+			},
+		},
+		"with enforced but not used TLS": {
+			enforceTLS: true,
+			config: []byte(`
+token_url: http://foo.bar
+client_id: foo
+client_secret: bar
+`),
+			assert: func(t *testing.T, err error, _ *oauth2TokenExchangeFinalizer) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrConfiguration)
+				assert.Contains(t, err.Error(), "'token_url' scheme must be https")
+			},
+		},
+		"with minimal valid config": {
+			enforceTLS: true,
+			config: []byte(`
+token_url: https://foo.bar
+client_id: foo
+client_secret: bar
+`),
+			assert: func(t *testing.T, err error, finalizer *oauth2TokenExchangeFinalizer) {
+				t.Helper()
+
+				require.NoError(t, err)
+				require.NotNil(t, finalizer)
+
+				assert.Equal(t, "fin", finalizer.ID())
+				assert.Equal(t, "https://foo.bar", finalizer.cfg.TokenURL)
+				assert.Equal(t, "foo", finalizer.cfg.ClientID)
+				assert.Equal(t, "bar", finalizer.cfg.ClientSecret)
+				assert.Equal(t, clientcredentials.AuthMethodBasicAuth, finalizer.cfg.AuthMethod)
+				assert.Nil(t, finalizer.cfg.TTL)
+				assert.Nil(t, finalizer.subjectToken)
+				assert.Equal(t, tokenexchange.TokenTypeAccessToken, finalizer.subjectTokenType)
+				assert.Empty(t, finalizer.requestedTokenType)
+				assert.Empty(t, finalizer.audiences)
+				assert.Empty(t, finalizer.resources)
+				assert.Empty(t, finalizer.scopes)
+				assert.Equal(t, "Authorization", finalizer.headerName)
+				assert.Empty(t, finalizer.headerScheme)
+				assert.False(t, finalizer.ContinueOnError())
+			},
+		},
+		"with full valid config": {
+			config: []byte(`
+token_url: https://foo.bar
+client_id: foo
+client_secret: bar
+auth_method: request_body
+cache_ttl: 11s
+subject_token: '{{ .Outputs.token }}'
+subject_token_type: urn:ietf:params:oauth:token-type:jwt
+requested_token_type: urn:ietf:params:oauth:token-type:access_token
+audience:
+  - https://api.example.com
+  - '{{ .Request.URL.Host }}'
+resource:
+  - https://api.example.com/orders
+scopes:
+  - read
+  - write
+header:
+  name: X-My-Header
+  scheme: Bar
+`),
+			assert: func(t *testing.T, err error, finalizer *oauth2TokenExchangeFinalizer) {
+				t.Helper()
+
+				require.NoError(t, err)
+				require.NotNil(t, finalizer)
+
+				assert.Equal(t, clientcredentials.AuthMethodRequestBody, finalizer.cfg.AuthMethod)
+				assert.Equal(t, 11*time.Second, *finalizer.cfg.TTL)
+				assert.NotNil(t, finalizer.subjectToken)
+				assert.Equal(t, tokenexchange.TokenTypeJWT, finalizer.subjectTokenType)
+				assert.Equal(t, tokenexchange.TokenTypeAccessToken, finalizer.requestedTokenType)
+				assert.Len(t, finalizer.audiences, 2)
+				assert.Len(t, finalizer.resources, 1)
+				assert.Len(t, finalizer.scopes, 2)
+				assert.Equal(t, "X-My-Header", finalizer.headerName)
+				assert.Equal(t, "Bar", finalizer.headerScheme)
+			},
+		},
+	} {
+		t.Run(uc, func(t *testing.T) {
+			// GIVEN
+			conf, err := testsupport.DecodeTestConfig(tc.config)
+			require.NoError(t, err)
+
+			es := config.EnforcementSettings{EnforceEgressTLS: tc.enforceTLS}
+			validator, err := validation.NewValidator(
+				validation.WithTagValidator(es),
+				validation.WithErrorTranslator(es),
+			)
+			require.NoError(t, err)
+
+			appCtx := app.NewContextMock(t)
+			appCtx.EXPECT().Validator().Maybe().Return(validator)
+			appCtx.EXPECT().Logger().Return(log.Logger)
+
+			// WHEN
+			finalizer, err := newOAuth2TokenExchangeFinalizer(appCtx, "fin", conf)
+
+			// THEN
+			tc.assert(t, err, finalizer)
+		})
+	}
+}
+
+func TestCreateTokenExchangeFinalizerFromPrototype(t *testing.T) {
+	t.Parallel()
+
+	for uc, tc := range map[string]struct {
+		config []byte
+		assert func(t *testing.T, err error, prototype *oauth2TokenExchangeFinalizer,
+			configured *oauth2TokenExchangeFinalizer)
+	}{
+		"no new configuration provided": {
+			assert: func(t *testing.T, err error, prototype *oauth2TokenExchangeFinalizer,
+				configured *oauth2TokenExchangeFinalizer,
+			) {
+				t.Helper()
+
+				require.NoError(t, err)
+				assert.Equal(t, prototype, configured)
+			},
+		},
+		"unsupported attributes provided": {
+			config: []byte(`token_url: https://bar.foo`),
+			assert: func(t *testing.T, err error, _ *oauth2TokenExchangeFinalizer, _ *oauth2TokenExchangeFinalizer) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrConfiguration)
+				assert.Contains(t, err.Error(), "invalid keys")
+			},
+		},
+		"audience, scopes, ttl and header scheme reconfigured": {
+			config: []byte(`
+audience:
+  - https://other.example.com
+scopes:
+  - admin
+cache_ttl: 1s
+header:
+  name: X-Token
+  scheme: Foo
+`),
+			assert: func(t *testing.T, err error, prototype *oauth2TokenExchangeFinalizer,
+				configured *oauth2TokenExchangeFinalizer,
+			) {
+				t.Helper()
+
+				require.NoError(t, err)
+				assert.NotEqual(t, prototype, configured)
+				assert.Equal(t, prototype.ID(), configured.ID())
+				assert.Equal(t, prototype.cfg.TokenURL, configured.cfg.TokenURL)
+				assert.Equal(t, prototype.cfg.ClientID, configured.cfg.ClientID)
+				assert.Equal(t, prototype.subjectToken, configured.subjectToken)
+				assert.Equal(t, prototype.subjectTokenType, configured.subjectTokenType)
+				assert.Equal(t, prototype.resources, configured.resources)
+				assert.Len(t, configured.audiences, 1)
+				assert.Len(t, configured.scopes, 1)
+				assert.Equal(t, time.Second, *configured.cfg.TTL)
+				assert.Equal(t, 11*time.Second, *prototype.cfg.TTL)
+				assert.Equal(t, "X-Token", configured.headerName)
+				assert.Equal(t, "Foo", configured.headerScheme)
+			},
+		},
+	} {
+		t.Run(uc, func(t *testing.T) {
+			// GIVEN
+			pc, err := testsupport.DecodeTestConfig([]byte(`
+token_url: https://foo.bar
+client_id: foo
+client_secret: bar
+cache_ttl: 11s
+audience:
+  - https://api.example.com
+resource:
+  - https://api.example.com/orders
+`))
+			require.NoError(t, err)
+
+			conf, err := testsupport.DecodeTestConfig(tc.config)
+			require.NoError(t, err)
+
+			validator, err := validation.NewValidator(
+				validation.WithTagValidator(config.EnforcementSettings{}),
+			)
+			require.NoError(t, err)
+
+			appCtx := app.NewContextMock(t)
+			appCtx.EXPECT().Validator().Maybe().Return(validator)
+			appCtx.EXPECT().Logger().Return(log.Logger)
+
+			prototype, err := newOAuth2TokenExchangeFinalizer(appCtx, "fin", pc)
+			require.NoError(t, err)
+
+			// WHEN
+			finalizer, err := prototype.WithConfig(conf)
+
+			// THEN
+			var (
+				ok            bool
+				realFinalizer *oauth2TokenExchangeFinalizer
+			)
+
+			if err == nil {
+				realFinalizer, ok = finalizer.(*oauth2TokenExchangeFinalizer)
+				require.True(t, ok)
+			}
+
+			tc.assert(t, err, prototype, realFinalizer)
+		})
+	}
+}
+
+func TestTokenExchangeFinalizerExecute(t *testing.T) {
+	t.Parallel()
+
+	var (
+		endpointCalled bool
+		assertRequest  func(t *testing.T, req *http.Request)
+		statusCode     int
+	)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		endpointCalled = true
+
+		if err := req.ParseForm(); err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+
+			return
+		}
+
+		assertRequest(t, req)
+
+		rawResp, err := json.Marshal(map[string]any{
+			"access_token":      "exchanged",
+			"issued_token_type": tokenexchange.TokenTypeAccessToken,
+			"token_type":        "Bearer",
+			"expires_in":        300,
+		})
+		require.NoError(t, err)
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Content-Length", strconv.Itoa(len(rawResp)))
+		w.WriteHeader(statusCode)
+		_, err = w.Write(rawResp)
+		assert.NoError(t, err)
+	}))
+	defer srv.Close()
+
+	for uc, tc := range map[string]struct {
+		config         []byte
+		subject        *subject.Subject
+		statusCode     int
+		configureMocks func(t *testing.T, ctx *mocks.RequestContextMock, cch *mocks2.CacheMock)
+		assertRequest  func(t *testing.T, req *http.Request)
+		assert         func(t *testing.T, err error, tokenEndpointCalled bool)
+	}{
+		"without subject": {
+			assert: func(t *testing.T, err error, tokenEndpointCalled bool) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrInternal)
+				assert.Contains(t, err.Error(), "'nil' subject")
+				assert.False(t, tokenEndpointCalled)
+			},
+		},
+		"without bearer token in the request": {
+			subject: &subject.Subject{ID: "foo"},
+			configureMocks: func(t *testing.T, ctx *mocks.RequestContextMock, _ *mocks2.CacheMock) {
+				t.Helper()
+
+				reqf := mocks.NewRequestFunctionsMock(t)
+				reqf.EXPECT().Header("Authorization").Return("Basic Zm9vOmJhcg==")
+
+				ctx.EXPECT().Request().Return(&heimdall.Request{RequestFunctions: reqf})
+				ctx.EXPECT().Outputs().Return(map[string]any{})
+			},
+			assert: func(t *testing.T, err error, tokenEndpointCalled bool) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrArgument)
+				assert.Contains(t, err.Error(), "no subject token")
+				assert.False(t, tokenEndpointCalled)
+
+				var identifier interface{ ID() string }
+				require.ErrorAs(t, err, &identifier)
+				assert.Equal(t, "fin", identifier.ID())
+			},
+		},
+		"reusing exchanged token from cache": {
+			subject: &subject.Subject{ID: "foo"},
+			configureMocks: func(t *testing.T, ctx *mocks.RequestContextMock, cch *mocks2.CacheMock) {
+				t.Helper()
+
+				reqf := mocks.NewRequestFunctionsMock(t)
+				reqf.EXPECT().Header("Authorization").Return("Bearer incoming")
+
+				ctx.EXPECT().Request().Return(&heimdall.Request{RequestFunctions: reqf})
+				ctx.EXPECT().Outputs().Return(map[string]any{})
+
+				rawData, err := json.Marshal(clientcredentials.TokenInfo{AccessToken: "cached", TokenType: "Bearer"})
+				require.NoError(t, err)
+
+				cch.EXPECT().Get(mock.Anything, mock.Anything).Return(rawData, nil)
+				ctx.EXPECT().AddHeaderForUpstream("Authorization", "Bearer cached")
+			},
+			assert: func(t *testing.T, err error, tokenEndpointCalled bool) {
+				t.Helper()
+
+				require.NoError(t, err)
+				assert.False(t, tokenEndpointCalled)
+			},
+		},
+		"token endpoint responds with an error": {
+			subject:    &subject.Subject{ID: "foo"},
+			statusCode: http.StatusServiceUnavailable,
+			configureMocks: func(t *testing.T, ctx *mocks.RequestContextMock, cch *mocks2.CacheMock) {
+				t.Helper()
+
+				reqf := mocks.NewRequestFunctionsMock(t)
+				reqf.EXPECT().Header("Authorization").Return("Bearer incoming")
+
+				ctx.EXPECT().Request().Return(&heimdall.Request{RequestFunctions: reqf})
+				ctx.EXPECT().Outputs().Return(map[string]any{})
+
+				cch.EXPECT().Get(mock.Anything, mock.Anything).Return(nil, errors.New("no cache entry"))
+			},
+			assertRequest: func(t *testing.T, _ *http.Request) { t.Helper() },
+			assert: func(t *testing.T, err error, tokenEndpointCalled bool) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrCommunication)
+				assert.True(t, tokenEndpointCalled)
+			},
+		},
+		"exchanging token using templates": {
+			config: []byte(`
+subject_token: '{{ .Outputs.id_token }}'
+subject_token_type: urn:ietf:params:oauth:token-type:id_token
+audience:
+  - '{{ .Subject.Attributes.tenant }}-api'
+resource:
+  - https://api.example.com
+scopes:
+  - read
+  - '{{ .Subject.Attributes.role }}'
+header:
+  name: X-Token
+  scheme: Foo
+`),
+			subject: &subject.Subject{ID: "foo", Attributes: map[string]any{"tenant": "acme", "role": "admin"}},
+			configureMocks: func(t *testing.T, ctx *mocks.RequestContextMock, cch *mocks2.CacheMock) {
+				t.Helper()
+
+				ctx.EXPECT().Request().Return(&heimdall.Request{})
+				ctx.EXPECT().Outputs().Return(map[string]any{"id_token": "idt"})
+
+				cch.EXPECT().Get(mock.Anything, mock.Anything).Return(nil, errors.New("no cache entry"))
+				cch.EXPECT().Set(mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+				ctx.EXPECT().AddHeaderForUpstream("X-Token", "Foo exchanged")
+			},
+			assertRequest: func(t *testing.T, req *http.Request) {
+				t.Helper()
+
+				assert.Equal(t, tokenexchange.GrantType, req.PostForm.Get("grant_type"))
+				assert.Equal(t, "idt", req.PostForm.Get("subject_token"))
+				assert.Equal(t, tokenexchange.TokenTypeIDToken, req.PostForm.Get("subject_token_type"))
+				assert.Equal(t, []string{"acme-api"}, req.PostForm["audience"])
+				assert.Equal(t, []string{"https://api.example.com"}, req.PostForm["resource"])
+				assert.Equal(t, "read admin", req.PostForm.Get("scope"))
+			},
+			assert: func(t *testing.T, err error, tokenEndpointCalled bool) {
+				t.Helper()
+
+				require.NoError(t, err)
+				assert.True(t, tokenEndpointCalled)
+			},
+		},
+	} {
+		t.Run(uc, func(t *testing.T) {
+			// GIVEN
+			endpointCalled = false
+			assertRequest = tc.assertRequest
+			statusCode = tc.statusCode
+			if statusCode == 0 {
+				statusCode = http.StatusOK
+			}
+
+			rawConf, err := testsupport.DecodeTestConfig(tc.config)
+			require.NoError(t, err)
+
+			if rawConf == nil {
+				rawConf = map[string]any{}
+			}
+
+			rawConf["token_url"] = srv.URL
+			rawConf["client_id"] = "foo"
+			rawConf["client_secret"] = "bar"
+
+			validator, err := validation.NewValidator(
+				validation.WithTagValidator(config.EnforcementSettings{}),
+			)
+			require.NoError(t, err)
+
+			appCtx := app.NewContextMock(t)
+			appCtx.EXPECT().Validator().Maybe().Return(validator)
+			appCtx.EXPECT().Logger().Return(log.Logger)
+
+			finalizer, err := newOAuth2TokenExchangeFinalizer(appCtx, "fin", rawConf)
+			require.NoError(t, err)
+
+			cch := mocks2.NewCacheMock(t)
+			ctx := mocks.NewRequestContextMock(t)
+			ctx.EXPECT().Context().Return(cache.WithContext(t.Context(), cch))
+
+			if tc.configureMocks != nil {
+				tc.configureMocks(t, ctx, cch)
+			}
+
+			// WHEN
+			err = finalizer.Execute(ctx, tc.subject)
+
+			// THEN
+			tc.assert(t, err, endpointCalled)
+		})
+	}
+}
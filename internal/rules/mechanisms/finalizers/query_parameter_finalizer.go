@@ -0,0 +1,58 @@
+// Copyright 2025 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package finalizers
+
+import (
+	"github.com/dadrus/heimdall/internal/app"
+	"github.com/dadrus/heimdall/internal/heimdall"
+	"github.com/dadrus/heimdall/internal/rules/mechanisms/template"
+	"github.com/dadrus/heimdall/internal/validation"
+)
+
+//nolint:gochecknoglobals
+var queryParameterKind = &valuesKind{
+	name: "query parameter",
+	decode: func(validator validation.Validator, rawConfig map[string]any) (map[string]template.Template, error) {
+		type Config struct {
+			Parameters map[string]template.Template `mapstructure:"parameters" validate:"required,gt=0"`
+		}
+
+		var conf Config
+		if err := decodeConfig(validator, rawConfig, &conf); err != nil {
+			return nil, err
+		}
+
+		return conf.Parameters, nil
+	},
+	set: heimdall.RequestContext.AddQueryParameterForUpstream,
+}
+
+// by intention. Used only during application bootstrap
+//
+//nolint:gochecknoinits
+func init() {
+	registerTypeFactory(
+		func(app app.Context, id string, typ string, conf map[string]any) (bool, Finalizer, error) {
+			if typ != FinalizerQueryParameter {
+				return false, nil, nil
+			}
+
+			finalizer, err := newValuesFinalizer(app, id, conf, queryParameterKind)
+
+			return true, finalizer, err
+		})
+}
@@ -0,0 +1,114 @@
+// Copyright 2025 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package finalizers
+
+import (
+	"github.com/rs/zerolog"
+
+	"github.com/dadrus/heimdall/internal/app"
+	"github.com/dadrus/heimdall/internal/heimdall"
+	"github.com/dadrus/heimdall/internal/rules/mechanisms/subject"
+	"github.com/dadrus/heimdall/internal/rules/mechanisms/template"
+	"github.com/dadrus/heimdall/internal/validation"
+	"github.com/dadrus/heimdall/internal/x/errorchain"
+)
+
+// valuesKind describes the values set by a valuesFinalizer.
+type valuesKind struct {
+	// name of the values used in logs and errors, like "dynamic metadata"
+	name string
+	// decode decodes the templates of the values from the configuration of the finalizer
+	decode func(validator validation.Validator, rawConfig map[string]any) (map[string]template.Template, error)
+	// set sets a rendered value in the request context
+	set func(ctx heimdall.RequestContext, name, value string)
+}
+
+// valuesFinalizer renders the configured templates and sets the rendered values using the
+// given kind. It implements the finalizers, which differ only in the kind of values they set.
+type valuesFinalizer struct {
+	id     string
+	app    app.Context
+	kind   *valuesKind
+	values map[string]template.Template
+}
+
+func newValuesFinalizer(
+	app app.Context,
+	id string,
+	rawConfig map[string]any,
+	kind *valuesKind,
+) (*valuesFinalizer, error) {
+	logger := app.Logger()
+	logger.Info().Str("_id", id).Msgf("Creating %s finalizer", kind.name)
+
+	values, err := kind.decode(app.Validator(), rawConfig)
+	if err != nil {
+		return nil, errorchain.NewWithMessagef(heimdall.ErrConfiguration,
+			"failed decoding config for %s finalizer '%s'", kind.name, id).CausedBy(err)
+	}
+
+	return &valuesFinalizer{
+		id:     id,
+		app:    app,
+		kind:   kind,
+		values: values,
+	}, nil
+}
+
+func (f *valuesFinalizer) Execute(ctx heimdall.RequestContext, sub *subject.Subject) error {
+	logger := zerolog.Ctx(ctx.Context())
+	logger.Debug().Str("_id", f.id).Msgf("Finalizing using %s finalizer", f.kind.name)
+
+	if sub == nil {
+		return errorchain.
+			NewWithMessagef(heimdall.ErrInternal,
+				"failed to execute %s finalizer due to 'nil' subject", f.kind.name).
+			WithErrorContext(f)
+	}
+
+	for name, tmpl := range f.values {
+		value, err := tmpl.Render(map[string]any{
+			"Request": ctx.Request(),
+			"Subject": sub,
+			"Outputs": ctx.Outputs(),
+		})
+		if err != nil {
+			return errorchain.
+				NewWithMessagef(heimdall.ErrInternal, "failed to render value for '%s' %s", name, f.kind.name).
+				WithErrorContext(f).
+				CausedBy(err)
+		}
+
+		logger.Debug().Str("_value", value).Msg("Rendered template")
+
+		f.kind.set(ctx, name, value)
+	}
+
+	return nil
+}
+
+func (f *valuesFinalizer) WithConfig(config map[string]any) (Finalizer, error) {
+	if len(config) == 0 {
+		return f, nil
+	}
+
+	return newValuesFinalizer(f.app, f.id, config, f.kind)
+}
+
+func (f *valuesFinalizer) ID() string { return f.id }
+
+func (f *valuesFinalizer) ContinueOnError() bool { return false }
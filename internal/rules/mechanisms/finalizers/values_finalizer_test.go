@@ -0,0 +1,399 @@
+// Copyright 2025 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package finalizers
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/rs/zerolog/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dadrus/heimdall/internal/app"
+	"github.com/dadrus/heimdall/internal/heimdall"
+	"github.com/dadrus/heimdall/internal/heimdall/mocks"
+	"github.com/dadrus/heimdall/internal/rules/mechanisms/subject"
+	"github.com/dadrus/heimdall/internal/validation"
+	"github.com/dadrus/heimdall/internal/x"
+	"github.com/dadrus/heimdall/internal/x/testsupport"
+)
+
+type valuesKindTestCase struct {
+	kind *valuesKind
+	// property holding the values in the configuration
+	property string
+	expSet   func(ctx *mocks.RequestContextMock, name, value string)
+}
+
+func valuesKindTestCases() map[string]valuesKindTestCase {
+	return map[string]valuesKindTestCase{
+		FinalizerDynamicMetadata: {
+			kind:     dynamicMetadataKind,
+			property: "metadata",
+			expSet: func(ctx *mocks.RequestContextMock, name, value string) {
+				ctx.EXPECT().AddDynamicMetadata(name, value)
+			},
+		},
+		FinalizerQueryParameter: {
+			kind:     queryParameterKind,
+			property: "parameters",
+			expSet: func(ctx *mocks.RequestContextMock, name, value string) {
+				ctx.EXPECT().AddQueryParameterForUpstream(name, value)
+			},
+		},
+	}
+}
+
+// valuesConfig replaces the $property placeholder in the given config with the property
+// holding the values of the given kind.
+func valuesConfig(ktc valuesKindTestCase, config string) []byte {
+	return []byte(strings.ReplaceAll(config, "$property", ktc.property))
+}
+
+func TestCreateValuesFinalizer(t *testing.T) {
+	t.Parallel()
+
+	for kind, ktc := range valuesKindTestCases() {
+		for uc, tc := range map[string]struct {
+			config string
+			assert func(t *testing.T, err error, finalizer *valuesFinalizer)
+		}{
+			"without configuration": {
+				assert: func(t *testing.T, err error, _ *valuesFinalizer) {
+					t.Helper()
+
+					require.Error(t, err)
+					require.ErrorIs(t, err, heimdall.ErrConfiguration)
+					assert.Contains(t, err.Error(), "'"+ktc.property+"' is a required field")
+					assert.Contains(t, err.Error(), ktc.kind.name+" finalizer")
+				},
+			},
+			"with empty values configuration": {
+				config: `$property: {}`,
+				assert: func(t *testing.T, err error, _ *valuesFinalizer) {
+					t.Helper()
+
+					require.Error(t, err)
+					require.ErrorIs(t, err, heimdall.ErrConfiguration)
+					assert.Contains(t, err.Error(), "'"+ktc.property+"' must contain more than 0 items")
+				},
+			},
+			"with unsupported attributes": {
+				config: `
+$property:
+  foo: bar
+foo: bar
+`,
+				assert: func(t *testing.T, err error, _ *valuesFinalizer) {
+					t.Helper()
+
+					require.Error(t, err)
+					require.ErrorIs(t, err, heimdall.ErrConfiguration)
+					assert.Contains(t, err.Error(), "failed decoding")
+				},
+			},
+			"with bad template": {
+				config: `
+$property:
+  bar: "{{ .Subject.ID | foobar }}"
+`,
+				assert: func(t *testing.T, err error, finalizer *valuesFinalizer) {
+					t.Helper()
+
+					require.Nil(t, finalizer)
+					require.Error(t, err)
+					require.ErrorIs(t, err, heimdall.ErrConfiguration)
+					assert.Contains(t, err.Error(), "failed decoding")
+				},
+			},
+			"with valid config": {
+				config: `
+$property:
+  foo: bar
+  bar: "{{ .Subject.ID }}"`,
+				assert: func(t *testing.T, err error, finalizer *valuesFinalizer) {
+					t.Helper()
+
+					require.NoError(t, err)
+					assert.Len(t, finalizer.values, 2)
+					assert.Equal(t, "with valid config", finalizer.ID())
+					assert.Equal(t, ktc.kind, finalizer.kind)
+
+					val, err := finalizer.values["foo"].Render(nil)
+					require.NoError(t, err)
+					assert.Equal(t, "bar", val)
+
+					val, err = finalizer.values["bar"].Render(map[string]any{
+						"Subject": &subject.Subject{ID: "baz"},
+					})
+					require.NoError(t, err)
+					assert.Equal(t, "baz", val)
+
+					assert.False(t, finalizer.ContinueOnError())
+				},
+			},
+		} {
+			t.Run(kind+" "+uc, func(t *testing.T) {
+				// GIVEN
+				conf, err := testsupport.DecodeTestConfig(valuesConfig(ktc, tc.config))
+				require.NoError(t, err)
+
+				validator, err := validation.NewValidator()
+				require.NoError(t, err)
+
+				appCtx := app.NewContextMock(t)
+				appCtx.EXPECT().Validator().Maybe().Return(validator)
+				appCtx.EXPECT().Logger().Return(log.Logger)
+
+				// WHEN
+				finalizer, err := newValuesFinalizer(appCtx, uc, conf, ktc.kind)
+
+				// THEN
+				tc.assert(t, err, finalizer)
+			})
+		}
+	}
+}
+
+func TestCreateValuesFinalizerFromPrototype(t *testing.T) {
+	t.Parallel()
+
+	for kind, ktc := range valuesKindTestCases() {
+		for uc, tc := range map[string]struct {
+			prototypeConfig string
+			config          string
+			assert          func(t *testing.T, err error, prototype *valuesFinalizer, configured *valuesFinalizer)
+		}{
+			"no new configuration provided": {
+				prototypeConfig: `
+$property:
+  foo: bar
+`,
+				assert: func(t *testing.T, err error, prototype *valuesFinalizer, configured *valuesFinalizer) {
+					t.Helper()
+
+					require.NoError(t, err)
+					assert.Equal(t, prototype, configured)
+					assert.Equal(t, "no new configuration provided", configured.ID())
+				},
+			},
+			"configuration without values provided": {
+				prototypeConfig: `
+$property:
+  foo: bar
+`,
+				config: ``,
+				assert: func(t *testing.T, err error, prototype *valuesFinalizer, configured *valuesFinalizer) {
+					t.Helper()
+
+					require.NoError(t, err)
+					assert.Equal(t, prototype, configured)
+					assert.Equal(t, "configuration without values provided", configured.ID())
+				},
+			},
+			"new values provided": {
+				prototypeConfig: `
+$property:
+  foo: bar
+`,
+				config: `
+$property:
+  bar: foo
+`,
+				assert: func(t *testing.T, err error, prototype *valuesFinalizer, configured *valuesFinalizer) {
+					t.Helper()
+
+					require.NoError(t, err)
+					assert.NotEqual(t, prototype, configured)
+					require.NotNil(t, configured)
+					assert.NotEmpty(t, configured.values)
+					assert.Equal(t, "new values provided", configured.ID())
+					assert.Equal(t, prototype.ID(), configured.ID())
+					assert.Equal(t, prototype.kind, configured.kind)
+
+					val, err := configured.values["bar"].Render(nil)
+					require.NoError(t, err)
+					assert.Equal(t, "foo", val)
+
+					assert.False(t, prototype.ContinueOnError())
+					assert.False(t, configured.ContinueOnError())
+				},
+			},
+		} {
+			t.Run(kind+" "+uc, func(t *testing.T) {
+				// GIVEN
+				pc, err := testsupport.DecodeTestConfig(valuesConfig(ktc, tc.prototypeConfig))
+				require.NoError(t, err)
+
+				conf, err := testsupport.DecodeTestConfig(valuesConfig(ktc, tc.config))
+				require.NoError(t, err)
+
+				validator, err := validation.NewValidator()
+				require.NoError(t, err)
+
+				appCtx := app.NewContextMock(t)
+				appCtx.EXPECT().Validator().Maybe().Return(validator)
+				appCtx.EXPECT().Logger().Return(log.Logger)
+
+				prototype, err := newValuesFinalizer(appCtx, uc, pc, ktc.kind)
+				require.NoError(t, err)
+
+				// WHEN
+				finalizer, err := prototype.WithConfig(conf)
+
+				// THEN
+				realFinalizer, ok := finalizer.(*valuesFinalizer)
+				require.True(t, ok)
+
+				tc.assert(t, err, prototype, realFinalizer)
+			})
+		}
+	}
+}
+
+func TestValuesFinalizerExecute(t *testing.T) {
+	t.Parallel()
+
+	for kind, ktc := range valuesKindTestCases() {
+		for uc, tc := range map[string]struct {
+			config           string
+			configureContext func(t *testing.T, ctx *mocks.RequestContextMock)
+			createSubject    func(t *testing.T) *subject.Subject
+			assert           func(t *testing.T, err error)
+		}{
+			"with nil subject": {
+				config: `
+$property:
+  foo: bar
+  bar: "{{ .Subject.ID }}"
+`,
+				assert: func(t *testing.T, err error) {
+					t.Helper()
+
+					require.Error(t, err)
+					require.ErrorIs(t, err, heimdall.ErrInternal)
+					assert.Contains(t, err.Error(), "'nil' subject")
+					assert.Contains(t, err.Error(), ktc.kind.name+" finalizer")
+
+					var identifier interface{ ID() string }
+					require.ErrorAs(t, err, &identifier)
+					assert.Equal(t, "with nil subject", identifier.ID())
+				},
+			},
+			"with failing template": {
+				config: `
+$property:
+  foo: "{{ .Subject.Attributes.bar.baz }}"
+`,
+				configureContext: func(t *testing.T, ctx *mocks.RequestContextMock) {
+					t.Helper()
+
+					ctx.EXPECT().Request().Return(&heimdall.Request{})
+					ctx.EXPECT().Outputs().Return(map[string]any{})
+				},
+				createSubject: func(t *testing.T) *subject.Subject {
+					t.Helper()
+
+					return &subject.Subject{ID: "FooBar", Attributes: map[string]any{"bar": "baz"}}
+				},
+				assert: func(t *testing.T, err error) {
+					t.Helper()
+
+					require.Error(t, err)
+					require.ErrorIs(t, err, heimdall.ErrInternal)
+					assert.Contains(t, err.Error(), "failed to render value for 'foo' "+ktc.kind.name)
+				},
+			},
+			"with all preconditions satisfied": {
+				config: `
+$property:
+  foo: "{{ .Subject.Attributes.bar }}"
+  bar: "{{ .Subject.ID }}"
+  baz: bar
+  x_foo: '{{ .Request.Header "X-Foo" }}'
+  x_bar: '{{ .Outputs.foo }}'
+`,
+				configureContext: func(t *testing.T, ctx *mocks.RequestContextMock) {
+					t.Helper()
+
+					reqf := mocks.NewRequestFunctionsMock(t)
+					reqf.EXPECT().Header("X-Foo").Return("Bar")
+
+					ktc.expSet(ctx, "foo", "baz")
+					ktc.expSet(ctx, "bar", "FooBar")
+					ktc.expSet(ctx, "baz", "bar")
+					ktc.expSet(ctx, "x_foo", "Bar")
+					ktc.expSet(ctx, "x_bar", "bar")
+					ctx.EXPECT().Request().Return(&heimdall.Request{RequestFunctions: reqf})
+					ctx.EXPECT().Outputs().Return(map[string]any{"foo": "bar"})
+				},
+				createSubject: func(t *testing.T) *subject.Subject {
+					t.Helper()
+
+					return &subject.Subject{ID: "FooBar", Attributes: map[string]any{"bar": "baz"}}
+				},
+				assert: func(t *testing.T, err error) {
+					t.Helper()
+
+					require.NoError(t, err)
+				},
+			},
+		} {
+			t.Run(kind+" "+uc, func(t *testing.T) {
+				// GIVEN
+				createSubject := x.IfThenElse(tc.createSubject != nil,
+					tc.createSubject,
+					func(t *testing.T) *subject.Subject {
+						t.Helper()
+
+						return nil
+					})
+
+				configureContext := x.IfThenElse(tc.configureContext != nil,
+					tc.configureContext,
+					func(t *testing.T, _ *mocks.RequestContextMock) { t.Helper() })
+
+				conf, err := testsupport.DecodeTestConfig(valuesConfig(ktc, tc.config))
+				require.NoError(t, err)
+
+				mctx := mocks.NewRequestContextMock(t)
+				mctx.EXPECT().Context().Return(t.Context())
+
+				sub := createSubject(t)
+
+				configureContext(t, mctx)
+
+				validator, err := validation.NewValidator()
+				require.NoError(t, err)
+
+				appCtx := app.NewContextMock(t)
+				appCtx.EXPECT().Validator().Maybe().Return(validator)
+				appCtx.EXPECT().Logger().Return(log.Logger)
+
+				finalizer, err := newValuesFinalizer(appCtx, uc, conf, ktc.kind)
+				require.NoError(t, err)
+
+				// WHEN
+				err = finalizer.Execute(mctx, sub)
+
+				// THEN
+				tc.assert(t, err)
+			})
+		}
+	}
+}
@@ -0,0 +1,323 @@
+// Copyright 2025 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package ldap
+
+import (
+	"crypto/tls"
+	"errors"
+	"net"
+	"net/url"
+	"strings"
+
+	ldapv3 "github.com/go-ldap/ldap/v3"
+
+	"github.com/dadrus/heimdall/internal/heimdall"
+	"github.com/dadrus/heimdall/internal/x"
+	"github.com/dadrus/heimdall/internal/x/errorchain"
+)
+
+// NoAttributes can be used in a search request to not retrieve any attributes (RFC 4511, section 4.5.1.8).
+const NoAttributes = "1.1"
+
+var ErrInvalidCredentials = errors.New("invalid credentials")
+
+type Entry struct {
+	DN         string
+	Attributes map[string][]string
+}
+
+// Values returns the values of the given attribute. As attribute names are case-insensitive,
+// so is the lookup.
+func (e Entry) Values(name string) []string {
+	for attr, values := range e.Attributes {
+		if strings.EqualFold(attr, name) {
+			return values
+		}
+	}
+
+	return nil
+}
+
+// AttributeMap returns the attributes in a form, which can be used in
+// subject attributes, or outputs.
+func (e Entry) AttributeMap() map[string]any {
+	result := make(map[string]any, len(e.Attributes))
+
+	for name, values := range e.Attributes {
+		result[name] = ToAnySlice(values)
+	}
+
+	return result
+}
+
+// ToAnySlice converts the given values to be usable in subject attributes, or outputs.
+func ToAnySlice(values []string) []any {
+	result := make([]any, len(values))
+	for idx, value := range values {
+		result[idx] = value
+	}
+
+	return result
+}
+
+type SearchRequest struct {
+	BaseDN     string
+	Scope      Scope
+	Filter     string
+	Attributes []string
+	SizeLimit  int
+}
+
+// Client manages a pool of connections to an LDAP server. Connections handed out by
+// the client are always bound with the configured service account credentials, or
+// anonymously if no credentials are configured, independent of the binds performed
+// on them before they have been returned to the pool.
+type Client struct {
+	url          string
+	secure       bool
+	startTLS     bool
+	tlsConf      *tls.Config
+	dialer       *net.Dialer
+	bindDN       string
+	bindPassword string
+	idle         chan *ldapv3.Conn
+}
+
+func NewClient(conf Server) (*Client, error) {
+	serverURL, err := url.Parse(conf.URL)
+	if err != nil {
+		return nil, errorchain.NewWithMessage(heimdall.ErrConfiguration, "failed to parse ldap server url").
+			CausedBy(err)
+	}
+
+	switch serverURL.Scheme {
+	case "ldap":
+	case "ldaps":
+		if conf.StartTLS {
+			return nil, errorchain.NewWithMessage(heimdall.ErrConfiguration,
+				"start_tls cannot be used together with the ldaps scheme")
+		}
+	default:
+		return nil, errorchain.NewWithMessagef(heimdall.ErrConfiguration,
+			"unsupported ldap server url scheme '%s'", serverURL.Scheme)
+	}
+
+	tlsConf := &tls.Config{
+		ServerName: serverURL.Hostname(),
+		MinVersion: tls.VersionTLS12,
+	}
+
+	if len(conf.TrustStore) != 0 {
+		tlsConf.RootCAs = conf.TrustStore.CertPool()
+	}
+
+	return &Client{
+		url:          conf.URL,
+		secure:       serverURL.Scheme == "ldaps" || conf.StartTLS,
+		startTLS:     conf.StartTLS,
+		tlsConf:      tlsConf,
+		dialer:       &net.Dialer{Timeout: x.IfThenElse(conf.Timeout != 0, conf.Timeout, defaultTimeout)},
+		bindDN:       conf.BindDN,
+		bindPassword: conf.BindPassword,
+		idle:         make(chan *ldapv3.Conn, x.IfThenElse(conf.PoolSize != 0, conf.PoolSize, defaultPoolSize)),
+	}, nil
+}
+
+// IsSecure returns whether the communication with the LDAP server is protected by TLS.
+func (c *Client) IsSecure() bool { return c.secure }
+
+// Do acquires a connection from the pool, or establishes a new one if there is no idle
+// connection available, and passes it to fn. The connection is returned to the pool
+// afterward, unless it is broken or the pool is full.
+func (c *Client) Do(fn func(conn *Conn) error) error {
+	conn, err := c.acquire()
+	if err != nil {
+		return err
+	}
+
+	defer c.release(conn)
+
+	return fn(conn)
+}
+
+func (c *Client) acquire() (*Conn, error) {
+	for {
+		select {
+		case raw := <-c.idle:
+			conn := &Conn{raw: raw}
+			if raw.IsClosing() {
+				continue
+			}
+
+			// the connection might have been closed by the server while being idle,
+			// so failing here is not considered an error, but rather a reason to try
+			// another one
+			if err := conn.bindService(c.bindDN, c.bindPassword); err != nil {
+				_ = raw.Close()
+
+				continue
+			}
+
+			return conn, nil
+		default:
+			conn, err := c.dial()
+			if err != nil {
+				return nil, err
+			}
+
+			if err = conn.bindService(c.bindDN, c.bindPassword); err != nil {
+				_ = conn.raw.Close()
+
+				return nil, err
+			}
+
+			return conn, nil
+		}
+	}
+}
+
+func (c *Client) release(conn *Conn) {
+	if conn.broken || conn.raw.IsClosing() {
+		_ = conn.raw.Close()
+
+		return
+	}
+
+	select {
+	case c.idle <- conn.raw:
+	default:
+		_ = conn.raw.Close()
+	}
+}
+
+func (c *Client) dial() (*Conn, error) {
+	raw, err := ldapv3.DialURL(c.url,
+		ldapv3.DialWithDialer(c.dialer),
+		ldapv3.DialWithTLSConfig(c.tlsConf),
+	)
+	if err != nil {
+		return nil, errorchain.NewWithMessage(heimdall.ErrCommunication, "failed to connect to the ldap server").
+			CausedBy(err)
+	}
+
+	raw.SetTimeout(c.dialer.Timeout)
+
+	if c.startTLS {
+		if err = raw.StartTLS(c.tlsConf.Clone()); err != nil {
+			_ = raw.Close()
+
+			return nil, errorchain.NewWithMessage(heimdall.ErrCommunication, "failed to start tls").
+				CausedBy(err)
+		}
+	}
+
+	return &Conn{raw: raw}, nil
+}
+
+// Conn is a connection to an LDAP server acquired from the Client.
+type Conn struct {
+	raw    *ldapv3.Conn
+	broken bool
+}
+
+// Bind authenticates the connection using the given credentials. ErrInvalidCredentials
+// is returned if the server rejected them. Empty passwords are always rejected, as these
+// would result in an unauthenticated bind (RFC 4513, section 5.1.2), which usually succeeds.
+func (c *Conn) Bind(dn, password string) error {
+	if len(dn) == 0 || len(password) == 0 {
+		return ErrInvalidCredentials
+	}
+
+	if err := c.raw.Bind(dn, password); err != nil {
+		if ldapv3.IsErrorWithCode(err, ldapv3.LDAPResultInvalidCredentials) {
+			return ErrInvalidCredentials
+		}
+
+		return c.wrap(err, "bind failed")
+	}
+
+	return nil
+}
+
+// Search executes the given search request. If the base object does not exist, no entries
+// are returned. If the size limit of the request is exceeded, the entries received so far
+// are returned.
+func (c *Conn) Search(req SearchRequest) ([]Entry, error) {
+	result, err := c.raw.Search(ldapv3.NewSearchRequest(
+		req.BaseDN,
+		req.Scope.value(),
+		ldapv3.NeverDerefAliases,
+		req.SizeLimit,
+		0,
+		false,
+		req.Filter,
+		req.Attributes,
+		nil,
+	))
+	if err != nil {
+		switch {
+		case ldapv3.IsErrorWithCode(err, ldapv3.LDAPResultNoSuchObject):
+			return nil, nil
+		case ldapv3.IsErrorWithCode(err, ldapv3.LDAPResultSizeLimitExceeded) && result != nil:
+		default:
+			return nil, c.wrap(err, "search failed")
+		}
+	}
+
+	entries := make([]Entry, len(result.Entries))
+	for idx, entry := range result.Entries {
+		attributes := make(map[string][]string, len(entry.Attributes))
+		for _, attr := range entry.Attributes {
+			attributes[attr.Name] = attr.Values
+		}
+
+		entries[idx] = Entry{DN: entry.DN, Attributes: attributes}
+	}
+
+	return entries, nil
+}
+
+func (c *Conn) bindService(dn, password string) error {
+	var err error
+
+	if len(dn) != 0 {
+		err = c.raw.Bind(dn, password)
+	} else {
+		err = c.raw.UnauthenticatedBind("")
+	}
+
+	if err != nil {
+		return c.wrap(err, "service bind failed")
+	}
+
+	return nil
+}
+
+func (c *Conn) wrap(err error, message string) error {
+	if ldapv3.IsErrorWithCode(err, ldapv3.ErrorNetwork) {
+		c.broken = true
+
+		return errorchain.NewWithMessage(heimdall.ErrCommunication, message).CausedBy(err)
+	}
+
+	var ldapErr *ldapv3.Error
+	if errors.As(err, &ldapErr) && ldapErr.ResultCode == ldapv3.LDAPResultTimeLimitExceeded {
+		return errorchain.NewWithMessage(heimdall.ErrCommunicationTimeout, message).CausedBy(err)
+	}
+
+	return errorchain.NewWithMessage(heimdall.ErrCommunication, message).CausedBy(err)
+}
@@ -0,0 +1,351 @@
+// Copyright 2025 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package ldap
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dadrus/heimdall/internal/heimdall"
+	"github.com/dadrus/heimdall/internal/rules/mechanisms/ldap/ldaptest"
+)
+
+func testEntries() []ldaptest.Entry {
+	return []ldaptest.Entry{
+		{
+			DN:       "cn=service,dc=example,dc=org",
+			Password: "secret",
+		},
+		{
+			DN:       "uid=alice,ou=people,dc=example,dc=org",
+			Password: "alice-pw",
+			Attributes: map[string][]string{
+				"uid":  {"alice"},
+				"mail": {"alice@example.org"},
+				"cn":   {"Alice"},
+			},
+		},
+		{
+			DN: "cn=admins,ou=groups,dc=example,dc=org",
+			Attributes: map[string][]string{
+				"cn":     {"admins"},
+				"member": {"uid=alice,ou=people,dc=example,dc=org"},
+			},
+		},
+	}
+}
+
+func TestNewClient(t *testing.T) {
+	t.Parallel()
+
+	for uc, tc := range map[string]struct {
+		conf   Server
+		assert func(t *testing.T, err error, client *Client)
+	}{
+		"with unsupported scheme": {
+			conf: Server{URL: "http://foo.bar"},
+			assert: func(t *testing.T, err error, _ *Client) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrConfiguration)
+				assert.Contains(t, err.Error(), "unsupported ldap server url scheme 'http'")
+			},
+		},
+		"with ldaps and start_tls": {
+			conf: Server{URL: "ldaps://foo.bar", StartTLS: true},
+			assert: func(t *testing.T, err error, _ *Client) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrConfiguration)
+				assert.Contains(t, err.Error(), "start_tls cannot be used")
+			},
+		},
+		"with plain ldap": {
+			conf: Server{URL: "ldap://foo.bar"},
+			assert: func(t *testing.T, err error, client *Client) {
+				t.Helper()
+
+				require.NoError(t, err)
+				assert.False(t, client.IsSecure())
+				assert.Equal(t, defaultTimeout, client.dialer.Timeout)
+				assert.Equal(t, defaultPoolSize, cap(client.idle))
+			},
+		},
+		"with ldap and start_tls": {
+			conf: Server{URL: "ldap://foo.bar:389", StartTLS: true, Timeout: time.Second, PoolSize: 2},
+			assert: func(t *testing.T, err error, client *Client) {
+				t.Helper()
+
+				require.NoError(t, err)
+				assert.True(t, client.IsSecure())
+				assert.Equal(t, "foo.bar", client.tlsConf.ServerName)
+				assert.Equal(t, time.Second, client.dialer.Timeout)
+				assert.Equal(t, 2, cap(client.idle))
+			},
+		},
+		"with ldaps": {
+			conf: Server{URL: "ldaps://foo.bar"},
+			assert: func(t *testing.T, err error, client *Client) {
+				t.Helper()
+
+				require.NoError(t, err)
+				assert.True(t, client.IsSecure())
+			},
+		},
+	} {
+		t.Run(uc, func(t *testing.T) {
+			client, err := NewClient(tc.conf)
+
+			tc.assert(t, err, client)
+		})
+	}
+}
+
+func TestClientDo(t *testing.T) {
+	t.Parallel()
+
+	for uc, tc := range map[string]struct {
+		mode   ldaptest.Mode
+		conf   func(srv *ldaptest.Server) Server
+		fn     func(t *testing.T, conn *Conn) error
+		assert func(t *testing.T, err error, srv *ldaptest.Server)
+	}{
+		"server not reachable": {
+			conf: func(_ *ldaptest.Server) Server { return Server{URL: "ldap://127.0.0.1:1"} },
+			fn: func(t *testing.T, _ *Conn) error {
+				t.Helper()
+
+				t.Fatal("should not be called")
+
+				return nil
+			},
+			assert: func(t *testing.T, err error, _ *ldaptest.Server) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrCommunication)
+				assert.Contains(t, err.Error(), "failed to connect")
+			},
+		},
+		"invalid service credentials": {
+			conf: func(srv *ldaptest.Server) Server {
+				return Server{URL: srv.URL, BindDN: "cn=service,dc=example,dc=org", BindPassword: "wrong"}
+			},
+			fn: func(t *testing.T, _ *Conn) error {
+				t.Helper()
+
+				t.Fatal("should not be called")
+
+				return nil
+			},
+			assert: func(t *testing.T, err error, _ *ldaptest.Server) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrCommunication)
+				assert.Contains(t, err.Error(), "service bind failed")
+			},
+		},
+		"bind with invalid and empty credentials": {
+			conf: func(srv *ldaptest.Server) Server { return Server{URL: srv.URL} },
+			fn: func(t *testing.T, conn *Conn) error {
+				t.Helper()
+
+				require.ErrorIs(t, conn.Bind("uid=alice,ou=people,dc=example,dc=org", "wrong"), ErrInvalidCredentials)
+				require.ErrorIs(t, conn.Bind("uid=alice,ou=people,dc=example,dc=org", ""), ErrInvalidCredentials)
+				require.ErrorIs(t, conn.Bind("", "foo"), ErrInvalidCredentials)
+
+				return nil
+			},
+			assert: func(t *testing.T, err error, srv *ldaptest.Server) {
+				t.Helper()
+
+				require.NoError(t, err)
+				// anonymous bind only
+				assert.Equal(t, 1, srv.Binds())
+			},
+		},
+		"search not existing base": {
+			conf: func(srv *ldaptest.Server) Server { return Server{URL: srv.URL} },
+			fn: func(t *testing.T, conn *Conn) error {
+				t.Helper()
+
+				entries, err := conn.Search(SearchRequest{BaseDN: "dc=foo", Filter: "(uid=alice)"})
+				require.NoError(t, err)
+				assert.Empty(t, entries)
+
+				return nil
+			},
+			assert: func(t *testing.T, err error, _ *ldaptest.Server) {
+				t.Helper()
+
+				require.NoError(t, err)
+			},
+		},
+		"search with invalid filter": {
+			conf: func(srv *ldaptest.Server) Server { return Server{URL: srv.URL} },
+			fn: func(t *testing.T, conn *Conn) error {
+				t.Helper()
+
+				_, err := conn.Search(SearchRequest{BaseDN: "dc=example,dc=org", Filter: "(uid=alice"})
+
+				return err
+			},
+			assert: func(t *testing.T, err error, _ *ldaptest.Server) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrCommunication)
+				assert.Contains(t, err.Error(), "search failed")
+			},
+		},
+		"service bind, user bind and search using start_tls": {
+			mode: ldaptest.StartTLS,
+			conf: func(srv *ldaptest.Server) Server {
+				return Server{
+					URL:          srv.URL,
+					StartTLS:     true,
+					TrustStore:   srv.TrustStore,
+					BindDN:       "cn=service,dc=example,dc=org",
+					BindPassword: "secret",
+				}
+			},
+			fn: func(t *testing.T, conn *Conn) error {
+				t.Helper()
+
+				entries, err := conn.Search(SearchRequest{
+					BaseDN:     "ou=people,dc=example,dc=org",
+					Scope:      ScopeOne,
+					Filter:     "(&(objectClass=person)(uid=alice))",
+					Attributes: []string{"mail"},
+				})
+				require.NoError(t, err)
+				assert.Empty(t, entries)
+
+				entries, err = conn.Search(SearchRequest{
+					BaseDN:     "ou=people,dc=example,dc=org",
+					Scope:      ScopeOne,
+					Filter:     "(uid=alice)",
+					Attributes: []string{"mail"},
+				})
+				require.NoError(t, err)
+				require.Len(t, entries, 1)
+				assert.Equal(t, "uid=alice,ou=people,dc=example,dc=org", entries[0].DN)
+				assert.Equal(t, map[string][]string{"mail": {"alice@example.org"}}, entries[0].Attributes)
+
+				return conn.Bind(entries[0].DN, "alice-pw")
+			},
+			assert: func(t *testing.T, err error, srv *ldaptest.Server) {
+				t.Helper()
+
+				require.NoError(t, err)
+				assert.Equal(t, 2, srv.Binds())
+			},
+		},
+		"search using ldaps": {
+			mode: ldaptest.LDAPS,
+			conf: func(srv *ldaptest.Server) Server {
+				return Server{URL: srv.URL, TrustStore: srv.TrustStore}
+			},
+			fn: func(t *testing.T, conn *Conn) error {
+				t.Helper()
+
+				entries, err := conn.Search(SearchRequest{
+					BaseDN: "ou=groups,dc=example,dc=org",
+					Filter: "(member=uid=alice,ou=people,dc=example,dc=org)",
+				})
+				require.NoError(t, err)
+				require.Len(t, entries, 1)
+				assert.Equal(t, []string{"admins"}, entries[0].Attributes["cn"])
+
+				return nil
+			},
+			assert: func(t *testing.T, err error, _ *ldaptest.Server) {
+				t.Helper()
+
+				require.NoError(t, err)
+			},
+		},
+		"ldaps without trusting the server certificate": {
+			mode: ldaptest.LDAPS,
+			conf: func(srv *ldaptest.Server) Server { return Server{URL: srv.URL} },
+			fn: func(t *testing.T, _ *Conn) error {
+				t.Helper()
+
+				t.Fatal("should not be called")
+
+				return nil
+			},
+			assert: func(t *testing.T, err error, _ *ldaptest.Server) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrCommunication)
+			},
+		},
+	} {
+		t.Run(uc, func(t *testing.T) {
+			t.Parallel()
+
+			// GIVEN
+			srv := ldaptest.NewServer(t, tc.mode, testEntries()...)
+
+			client, err := NewClient(tc.conf(srv))
+			require.NoError(t, err)
+
+			// WHEN
+			err = client.Do(func(conn *Conn) error { return tc.fn(t, conn) })
+
+			// THEN
+			tc.assert(t, err, srv)
+		})
+	}
+}
+
+func TestClientReusesPooledConnections(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	srv := ldaptest.NewServer(t, ldaptest.Plain, testEntries()...)
+
+	client, err := NewClient(Server{
+		URL:          srv.URL,
+		BindDN:       "cn=service,dc=example,dc=org",
+		BindPassword: "secret",
+		PoolSize:     1,
+	})
+	require.NoError(t, err)
+
+	// WHEN
+	for range 3 {
+		err = client.Do(func(conn *Conn) error {
+			// binds as the user, which must not leak into the next usage
+			return conn.Bind("uid=alice,ou=people,dc=example,dc=org", "alice-pw")
+		})
+		require.NoError(t, err)
+	}
+
+	// THEN
+	assert.Equal(t, 1, srv.Connections())
+	// service bind and user bind for each usage
+	assert.Equal(t, 6, srv.Binds())
+}
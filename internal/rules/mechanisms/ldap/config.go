@@ -0,0 +1,158 @@
+// Copyright 2025 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package ldap
+
+import (
+	"time"
+
+	ldapv3 "github.com/go-ldap/ldap/v3"
+
+	"github.com/dadrus/heimdall/internal/heimdall"
+	"github.com/dadrus/heimdall/internal/rules/mechanisms/template"
+	"github.com/dadrus/heimdall/internal/truststore"
+	"github.com/dadrus/heimdall/internal/x/errorchain"
+)
+
+const (
+	defaultTimeout  = 10 * time.Second
+	defaultPoolSize = 5
+
+	ScopeBase Scope = "base"
+	ScopeOne  Scope = "one"
+	ScopeSub  Scope = "sub"
+)
+
+type Scope string
+
+func (s Scope) value() int {
+	switch s {
+	case ScopeBase:
+		return ldapv3.ScopeBaseObject
+	case ScopeOne:
+		return ldapv3.ScopeSingleLevel
+	default:
+		return ldapv3.ScopeWholeSubtree
+	}
+}
+
+// Server holds the configuration required to connect to an LDAP server.
+type Server struct {
+	URL          string                `mapstructure:"url"           validate:"required,url"`
+	StartTLS     bool                  `mapstructure:"start_tls"`
+	TrustStore   truststore.TrustStore `mapstructure:"trust_store"`
+	Timeout      time.Duration         `mapstructure:"timeout"`
+	PoolSize     int                   `mapstructure:"pool_size"     validate:"gte=0"`
+	BindDN       string                `mapstructure:"bind_dn"       validate:"required_with=BindPassword"`
+	BindPassword string                `mapstructure:"bind_password" validate:"required_with=BindDN"`
+}
+
+// Search describes an LDAP search. The filter is a template, which is rendered
+// with the values provided by the mechanism making use of it.
+type Search struct {
+	BaseDN string            `mapstructure:"base_dn" validate:"required"`
+	Filter template.Template `mapstructure:"filter"  validate:"required"`
+	Scope  Scope             `mapstructure:"scope"   validate:"omitempty,oneof=base one sub"`
+}
+
+// Groups describes the search for the groups an entry is member of. The Attribute
+// defines which attribute of the found group entries is used as group name.
+type Groups struct {
+	Search    `mapstructure:",squash"`
+	Attribute string `mapstructure:"attribute"`
+}
+
+func (g *Groups) NameAttribute() string {
+	if len(g.Attribute) == 0 {
+		return "cn"
+	}
+
+	return g.Attribute
+}
+
+// Request renders the filter using the given values and returns the resulting search request.
+func (s Search) Request(values map[string]any, attributes []string) (SearchRequest, error) {
+	filter, err := s.Filter.Render(values)
+	if err != nil {
+		return SearchRequest{}, errorchain.NewWithMessage(heimdall.ErrInternal, "failed to render search filter").
+			CausedBy(err)
+	}
+
+	return SearchRequest{
+		BaseDN:     s.BaseDN,
+		Scope:      s.Scope,
+		Filter:     filter,
+		Attributes: attributes,
+	}, nil
+}
+
+// EscapeFilterValues returns a copy of the given value with all strings, including those nested in
+// slices and maps, escaped to be safely usable in a search filter.
+func EscapeFilterValues(value any) any {
+	switch val := value.(type) {
+	case string:
+		return ldapv3.EscapeFilter(val)
+	case []string:
+		result := make([]string, len(val))
+		for idx, entry := range val {
+			result[idx] = ldapv3.EscapeFilter(entry)
+		}
+
+		return result
+	case []any:
+		result := make([]any, len(val))
+		for idx, entry := range val {
+			result[idx] = EscapeFilterValues(entry)
+		}
+
+		return result
+	case map[string]any:
+		result := make(map[string]any, len(val))
+		for key, entry := range val {
+			result[key] = EscapeFilterValues(entry)
+		}
+
+		return result
+	default:
+		return value
+	}
+}
+
+// MemberOf returns the names of the groups the entry identified by the given dn is member of.
+// The filter is rendered with the filter-escaped dn available as DN.
+func (g *Groups) MemberOf(conn *Conn, dn string) ([]string, error) {
+	nameAttribute := g.NameAttribute()
+
+	req, err := g.Request(map[string]any{"DN": ldapv3.EscapeFilter(dn)}, []string{nameAttribute})
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := conn.Search(req)
+	if err != nil {
+		return nil, err
+	}
+
+	names := make([]string, 0, len(entries))
+
+	for _, entry := range entries {
+		if values := entry.Values(nameAttribute); len(values) != 0 {
+			names = append(names, values[0])
+		}
+	}
+
+	return names, nil
+}
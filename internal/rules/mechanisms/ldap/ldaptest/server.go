@@ -0,0 +1,382 @@
+// Copyright 2025 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+// Package ldaptest provides an in-process LDAP server to be used in tests.
+package ldaptest
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	ber "github.com/go-asn1-ber/asn1-ber"
+	ldapv3 "github.com/go-ldap/ldap/v3"
+	"github.com/hashicorp/go-hclog"
+	"github.com/jimlambrt/gldap"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dadrus/heimdall/internal/x"
+	"github.com/dadrus/heimdall/internal/x/testsupport"
+)
+
+type Entry struct {
+	DN         string
+	Password   string
+	Attributes map[string][]string
+}
+
+type Mode int
+
+const (
+	Plain Mode = iota
+	LDAPS
+	StartTLS
+)
+
+type Server struct {
+	URL        string
+	TrustStore []*x509.Certificate
+
+	entries     []Entry
+	tlsConf     *tls.Config
+	binds       atomic.Int64
+	connections sync.Map
+	searches    atomic.Int64
+}
+
+// NewServer starts an LDAP server serving the given entries, which is stopped
+// when the test completes. Entries having a password set can be used to bind.
+// Anonymous binds are always allowed.
+func NewServer(t *testing.T, mode Mode, entries ...Entry) *Server {
+	t.Helper()
+
+	port, err := testsupport.GetFreePort()
+	require.NoError(t, err)
+
+	srv := &Server{entries: entries}
+
+	if mode != Plain {
+		srv.tlsConf, srv.TrustStore = tlsConfig(t)
+	}
+
+	// connections are closed by the server latest after the read timeout, as otherwise stopping
+	// the server would block until all clients, including idle pooled connections, disconnect
+	server, err := gldap.NewServer(
+		gldap.WithLogger(hclog.NewNullLogger()),
+		gldap.WithReadTimeout(time.Second),
+	)
+	require.NoError(t, err)
+
+	mux, err := gldap.NewMux()
+	require.NoError(t, err)
+	require.NoError(t, mux.Bind(srv.handleBind))
+	require.NoError(t, mux.Search(srv.handleSearch))
+	require.NoError(t, mux.ExtendedOperation(srv.handleStartTLS, gldap.ExtendedOperationStartTLS))
+	require.NoError(t, server.Router(mux))
+
+	var opts []gldap.Option
+	if mode == LDAPS {
+		opts = append(opts, gldap.WithTLSConfig(srv.tlsConf))
+	}
+
+	addr := fmt.Sprintf("127.0.0.1:%d", port)
+
+	go func() { _ = server.Run(addr, opts...) }()
+
+	t.Cleanup(func() { _ = server.Stop() })
+
+	require.Eventually(t, server.Ready, 5*time.Second, 10*time.Millisecond)
+
+	srv.URL = fmt.Sprintf("%s://%s", x.IfThenElse(mode == LDAPS, "ldaps", "ldap"), addr)
+
+	return srv
+}
+
+// Binds returns the number of successful binds.
+func (s *Server) Binds() int { return int(s.binds.Load()) }
+
+// Searches returns the number of executed searches.
+func (s *Server) Searches() int { return int(s.searches.Load()) }
+
+// Connections returns the number of distinct connections, which issued any requests.
+func (s *Server) Connections() int {
+	count := 0
+
+	s.connections.Range(func(_, _ any) bool {
+		count++
+
+		return true
+	})
+
+	return count
+}
+
+func (s *Server) handleBind(w *gldap.ResponseWriter, r *gldap.Request) {
+	s.connections.Store(r.ConnectionID(), true)
+
+	resp := r.NewBindResponse(gldap.WithResponseCode(gldap.ResultInvalidCredentials))
+	defer func() { _ = w.Write(resp) }()
+
+	msg, err := r.GetSimpleBindMessage()
+	if err != nil {
+		return
+	}
+
+	if len(msg.UserName) == 0 && len(msg.Password) == 0 {
+		s.binds.Add(1)
+		resp.SetResultCode(gldap.ResultSuccess)
+
+		return
+	}
+
+	for _, entry := range s.entries {
+		if strings.EqualFold(entry.DN, msg.UserName) && len(entry.Password) != 0 &&
+			entry.Password == string(msg.Password) {
+			s.binds.Add(1)
+			resp.SetResultCode(gldap.ResultSuccess)
+
+			return
+		}
+	}
+}
+
+func (s *Server) handleSearch(w *gldap.ResponseWriter, r *gldap.Request) {
+	s.connections.Store(r.ConnectionID(), true)
+	s.searches.Add(1)
+
+	resp := r.NewSearchDoneResponse(gldap.WithResponseCode(gldap.ResultSuccess))
+	defer func() { _ = w.Write(resp) }()
+
+	msg, err := r.GetSearchMessage()
+	if err != nil {
+		resp.SetResultCode(gldap.ResultOperationsError)
+
+		return
+	}
+
+	filter, err := ldapv3.CompileFilter(msg.Filter)
+	if err != nil {
+		resp.SetResultCode(gldap.ResultProtocolError)
+
+		return
+	}
+
+	found := false
+
+	for _, entry := range s.entries {
+		if strings.EqualFold(entry.DN, msg.BaseDN) {
+			found = true
+		}
+
+		if !inScope(entry.DN, msg.BaseDN, msg.Scope) || !matches(filter, entry) {
+			continue
+		}
+
+		_ = w.Write(r.NewSearchResponseEntry(entry.DN,
+			gldap.WithAttributes(selectAttributes(entry.Attributes, msg.Attributes))))
+	}
+
+	if !found && !s.hasDescendants(msg.BaseDN) {
+		resp.SetResultCode(gldap.ResultNoSuchObject)
+	}
+}
+
+func (s *Server) handleStartTLS(w *gldap.ResponseWriter, r *gldap.Request) {
+	if s.tlsConf == nil {
+		_ = w.Write(r.NewExtendedResponse(gldap.WithResponseCode(gldap.ResultUnavailable)))
+
+		return
+	}
+
+	resp := r.NewExtendedResponse(gldap.WithResponseCode(gldap.ResultSuccess))
+	resp.SetResponseName(gldap.ExtendedOperationStartTLS)
+
+	if err := w.Write(resp); err != nil {
+		return
+	}
+
+	_ = r.StartTLS(s.tlsConf)
+}
+
+func (s *Server) hasDescendants(baseDN string) bool {
+	for _, entry := range s.entries {
+		if inScope(entry.DN, baseDN, gldap.WholeSubtree) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func inScope(dn, baseDN string, scope gldap.Scope) bool {
+	dn = strings.ToLower(dn)
+	baseDN = strings.ToLower(baseDN)
+
+	switch scope {
+	case gldap.BaseObject:
+		return dn == baseDN
+	case gldap.SingleLevel:
+		_, parent, _ := strings.Cut(dn, ",")
+
+		return parent == baseDN
+	default:
+		return dn == baseDN || strings.HasSuffix(dn, ","+baseDN)
+	}
+}
+
+func selectAttributes(attributes map[string][]string, requested []string) map[string][]string {
+	if len(requested) == 0 {
+		return attributes
+	}
+
+	result := make(map[string][]string, len(requested))
+
+	for _, name := range requested {
+		for attr, values := range attributes {
+			if strings.EqualFold(attr, name) {
+				result[attr] = values
+			}
+		}
+	}
+
+	return result
+}
+
+func attributeValues(entry Entry, name string) []string {
+	if strings.EqualFold(name, "dn") || strings.EqualFold(name, "distinguishedName") {
+		return []string{entry.DN}
+	}
+
+	for attr, values := range entry.Attributes {
+		if strings.EqualFold(attr, name) {
+			return values
+		}
+	}
+
+	return nil
+}
+
+func matches(filter *ber.Packet, entry Entry) bool { //nolint:cyclop
+	switch filter.Tag {
+	case ldapv3.FilterAnd:
+		for _, child := range filter.Children {
+			if !matches(child, entry) {
+				return false
+			}
+		}
+
+		return true
+	case ldapv3.FilterOr:
+		for _, child := range filter.Children {
+			if matches(child, entry) {
+				return true
+			}
+		}
+
+		return false
+	case ldapv3.FilterNot:
+		return !matches(filter.Children[0], entry)
+	case ldapv3.FilterPresent:
+		name := ber.DecodeString(filter.Data.Bytes())
+
+		// every entry has an object class in a real directory
+		return strings.EqualFold(name, "objectClass") || len(attributeValues(entry, name)) != 0
+	case ldapv3.FilterEqualityMatch:
+		expected := ber.DecodeString(filter.Children[1].Data.Bytes())
+
+		for _, value := range attributeValues(entry, ber.DecodeString(filter.Children[0].Data.Bytes())) {
+			if strings.EqualFold(value, expected) {
+				return true
+			}
+		}
+
+		return false
+	case ldapv3.FilterSubstrings:
+		for _, value := range attributeValues(entry, ber.DecodeString(filter.Children[0].Data.Bytes())) {
+			if matchesSubstrings(strings.ToLower(value), filter.Children[1].Children) {
+				return true
+			}
+		}
+
+		return false
+	default:
+		return false
+	}
+}
+
+func matchesSubstrings(value string, parts []*ber.Packet) bool {
+	for _, part := range parts {
+		substr := strings.ToLower(ber.DecodeString(part.Data.Bytes()))
+
+		switch part.Tag {
+		case ldapv3.FilterSubstringsInitial:
+			if !strings.HasPrefix(value, substr) {
+				return false
+			}
+
+			value = value[len(substr):]
+		case ldapv3.FilterSubstringsFinal:
+			if !strings.HasSuffix(value, substr) {
+				return false
+			}
+
+			value = value[:len(value)-len(substr)]
+		default:
+			idx := strings.Index(value, substr)
+			if idx < 0 {
+				return false
+			}
+
+			value = value[idx+len(substr):]
+		}
+	}
+
+	return true
+}
+
+func tlsConfig(t *testing.T) (*tls.Config, []*x509.Certificate) {
+	t.Helper()
+
+	ca, err := testsupport.NewRootCA("Test Root CA", 24*time.Hour)
+	require.NoError(t, err)
+
+	key, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	require.NoError(t, err)
+
+	cert, err := ca.IssueCertificate(
+		testsupport.WithSubject(pkix.Name{CommonName: "ldap"}),
+		testsupport.WithValidity(time.Now(), 10*time.Hour),
+		testsupport.WithSubjectPubKey(&key.PublicKey, x509.ECDSAWithSHA384),
+		testsupport.WithIPAddresses([]net.IP{net.ParseIP("127.0.0.1")}),
+		testsupport.WithExtendedKeyUsage(x509.ExtKeyUsageServerAuth),
+	)
+	require.NoError(t, err)
+
+	return &tls.Config{
+		Certificates: []tls.Certificate{{Certificate: [][]byte{cert.Raw}, PrivateKey: key, Leaf: cert}},
+		MinVersion:   tls.VersionTLS12,
+	}, []*x509.Certificate{ca.Certificate}
+}
@@ -26,15 +26,17 @@ import (
 	"github.com/dadrus/heimdall/internal/rules/mechanisms/contextualizers"
 	"github.com/dadrus/heimdall/internal/rules/mechanisms/errorhandlers"
 	"github.com/dadrus/heimdall/internal/rules/mechanisms/finalizers"
+	"github.com/dadrus/heimdall/internal/rules/mechanisms/responsehandlers"
 	"github.com/dadrus/heimdall/internal/x/errorchain"
 )
 
 var (
-	ErrAuthenticatorCreation  = errors.New("failed to create authenticator")
-	ErrAuthorizerCreation     = errors.New("failed to create authorizer")
-	ErrFinalizerCreation      = errors.New("failed to create finalizer")
-	ErrContextualizerCreation = errors.New("failed to create contextualizer")
-	ErrErrorHandlerCreation   = errors.New("failed to create error handler")
+	ErrAuthenticatorCreation   = errors.New("failed to create authenticator")
+	ErrAuthorizerCreation      = errors.New("failed to create authorizer")
+	ErrFinalizerCreation       = errors.New("failed to create finalizer")
+	ErrContextualizerCreation  = errors.New("failed to create contextualizer")
+	ErrErrorHandlerCreation    = errors.New("failed to create error handler")
+	ErrResponseHandlerCreation = errors.New("failed to create response handler")
 )
 
 //go:generate mockery --name MechanismFactory --structname MechanismFactoryMock
@@ -45,6 +47,7 @@ type MechanismFactory interface {
 	CreateContextualizer(version, id string, conf config.MechanismConfig) (contextualizers.Contextualizer, error)
 	CreateFinalizer(version, id string, conf config.MechanismConfig) (finalizers.Finalizer, error)
 	CreateErrorHandler(version, id string, conf config.MechanismConfig) (errorhandlers.ErrorHandler, error)
+	CreateResponseHandler(version, id string, conf config.MechanismConfig) (responsehandlers.ResponseHandler, error)
 }
 
 func NewMechanismFactory(app app.Context) (MechanismFactory, error) {
@@ -164,3 +167,23 @@ func (hf *mechanismsFactory) CreateErrorHandler(_, id string, conf config.Mechan
 
 	return prototype, nil
 }
+
+func (hf *mechanismsFactory) CreateResponseHandler(_, id string, conf config.MechanismConfig) (
+	responsehandlers.ResponseHandler, error,
+) {
+	prototype, err := hf.r.ResponseHandler(id)
+	if err != nil {
+		return nil, errorchain.New(ErrResponseHandlerCreation).CausedBy(err)
+	}
+
+	if conf != nil {
+		responseHandler, err := prototype.WithConfig(conf)
+		if err != nil {
+			return nil, errorchain.New(ErrResponseHandlerCreation).CausedBy(err)
+		}
+
+		return responseHandler, nil
+	}
+
+	return prototype, nil
+}
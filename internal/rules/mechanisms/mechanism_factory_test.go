@@ -37,6 +37,8 @@ import (
 	mocks5 "github.com/dadrus/heimdall/internal/rules/mechanisms/errorhandlers/mocks"
 	"github.com/dadrus/heimdall/internal/rules/mechanisms/finalizers"
 	mocks6 "github.com/dadrus/heimdall/internal/rules/mechanisms/finalizers/mocks"
+	"github.com/dadrus/heimdall/internal/rules/mechanisms/responsehandlers"
+	mocks7 "github.com/dadrus/heimdall/internal/rules/mechanisms/responsehandlers/mocks"
 	"github.com/dadrus/heimdall/internal/x"
 )
 
@@ -476,6 +478,93 @@ func TestHandlerFactoryCreateErrorHandler(t *testing.T) {
 	}
 }
 
+func TestHandlerFactoryCreateResponseHandler(t *testing.T) {
+	t.Parallel()
+
+	ID := "foo"
+
+	for uc, tc := range map[string]struct {
+		id            string
+		conf          map[string]any
+		configureMock func(t *testing.T, mRH *mocks7.ResponseHandlerMock)
+		assert        func(t *testing.T, err error, responseHandler responsehandlers.ResponseHandler)
+	}{
+		"no response handler for given id": {
+			id: "bar",
+			assert: func(t *testing.T, err error, _ responsehandlers.ResponseHandler) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, ErrResponseHandlerCreation)
+				assert.Contains(t, err.Error(), "no response handler prototype")
+			},
+		},
+		"with failing creation from prototype": {
+			conf: map[string]any{"foo": "bar"},
+			configureMock: func(t *testing.T, mRH *mocks7.ResponseHandlerMock) {
+				t.Helper()
+
+				mRH.EXPECT().WithConfig(mock.Anything).Return(nil, heimdall.ErrArgument)
+			},
+			assert: func(t *testing.T, err error, _ responsehandlers.ResponseHandler) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, ErrResponseHandlerCreation)
+				assert.Contains(t, err.Error(), heimdall.ErrArgument.Error())
+			},
+		},
+		"successful creation from prototype": {
+			conf: map[string]any{"foo": "bar"},
+			configureMock: func(t *testing.T, mRH *mocks7.ResponseHandlerMock) {
+				t.Helper()
+
+				mRH.EXPECT().WithConfig(mock.Anything).Return(mRH, nil)
+			},
+			assert: func(t *testing.T, err error, responseHandler responsehandlers.ResponseHandler) {
+				t.Helper()
+
+				require.NoError(t, err)
+				assert.NotNil(t, responseHandler)
+			},
+		},
+		"successful creation with empty config": {
+			assert: func(t *testing.T, err error, responseHandler responsehandlers.ResponseHandler) {
+				t.Helper()
+
+				require.NoError(t, err)
+				assert.NotNil(t, responseHandler)
+			},
+		},
+	} {
+		t.Run(uc, func(t *testing.T) {
+			// GIVEN
+			configureMock := x.IfThenElse(tc.configureMock != nil,
+				tc.configureMock,
+				func(t *testing.T, _ *mocks7.ResponseHandlerMock) { t.Helper() })
+
+			mRH := mocks7.NewResponseHandlerMock(t)
+			configureMock(t, mRH)
+
+			factory := &mechanismsFactory{
+				r: &mechanismRepository{
+					responseHandlers: map[string]responsehandlers.ResponseHandler{
+						ID: mRH,
+					},
+				},
+			}
+
+			id := x.IfThenElse(len(tc.id) != 0, tc.id, ID)
+
+			// WHEN
+			responseHandler, err := factory.CreateResponseHandler("test", id, tc.conf)
+
+			// THEN
+			tc.assert(t, err, responseHandler)
+		})
+	}
+}
+
 func TestCreateHandlerFactory(t *testing.T) {
 	t.Parallel()
 
@@ -26,6 +26,7 @@ import (
 	"github.com/dadrus/heimdall/internal/rules/mechanisms/contextualizers"
 	"github.com/dadrus/heimdall/internal/rules/mechanisms/errorhandlers"
 	"github.com/dadrus/heimdall/internal/rules/mechanisms/finalizers"
+	"github.com/dadrus/heimdall/internal/rules/mechanisms/responsehandlers"
 	"github.com/dadrus/heimdall/internal/x/errorchain"
 )
 
@@ -85,12 +86,23 @@ func newMechanismRepository(app app.Context) (*mechanismRepository, error) {
 		return nil, err
 	}
 
+	logger.Debug().Msg("Loading definitions for response handler")
+
+	rhMap, err := createPipelineObjects[responsehandlers.ResponseHandler](
+		app, conf.Prototypes.ResponseHandlers, responsehandlers.CreatePrototype)
+	if err != nil {
+		logger.Error().Err(err).Msg("Failed loading response handler definitions")
+
+		return nil, err
+	}
+
 	return &mechanismRepository{
-		authenticators:  authenticatorMap,
-		authorizers:     authorizerMap,
-		contextualizers: contextualizerMap,
-		finalizers:      finalizerMap,
-		errorHandlers:   ehMap,
+		authenticators:   authenticatorMap,
+		authorizers:      authorizerMap,
+		contextualizers:  contextualizerMap,
+		finalizers:       finalizerMap,
+		errorHandlers:    ehMap,
+		responseHandlers: rhMap,
 	}, nil
 }
 
@@ -117,11 +129,12 @@ func createPipelineObjects[T any](
 }
 
 type mechanismRepository struct {
-	authenticators  map[string]authenticators.Authenticator
-	authorizers     map[string]authorizers.Authorizer
-	contextualizers map[string]contextualizers.Contextualizer
-	finalizers      map[string]finalizers.Finalizer
-	errorHandlers   map[string]errorhandlers.ErrorHandler
+	authenticators   map[string]authenticators.Authenticator
+	authorizers      map[string]authorizers.Authorizer
+	contextualizers  map[string]contextualizers.Contextualizer
+	finalizers       map[string]finalizers.Finalizer
+	errorHandlers    map[string]errorhandlers.ErrorHandler
+	responseHandlers map[string]responsehandlers.ResponseHandler
 }
 
 func (r *mechanismRepository) Authenticator(id string) (authenticators.Authenticator, error) {
@@ -173,3 +186,13 @@ func (r *mechanismRepository) ErrorHandler(id string) (errorhandlers.ErrorHandle
 
 	return errorHandler, nil
 }
+
+func (r *mechanismRepository) ResponseHandler(id string) (responsehandlers.ResponseHandler, error) {
+	responseHandler, ok := r.responseHandlers[id]
+	if !ok {
+		return nil, errorchain.NewWithMessagef(ErrNoSuchPipelineObject,
+			"no response handler prototype for id='%s' found", id)
+	}
+
+	return responseHandler, nil
+}
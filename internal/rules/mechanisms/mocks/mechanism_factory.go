@@ -15,6 +15,8 @@ import (
 	finalizers "github.com/dadrus/heimdall/internal/rules/mechanisms/finalizers"
 
 	mock "github.com/stretchr/testify/mock"
+
+	responsehandlers "github.com/dadrus/heimdall/internal/rules/mechanisms/responsehandlers"
 )
 
 // MechanismFactoryMock is an autogenerated mock type for the MechanismFactory type
@@ -330,6 +332,66 @@ func (_c *MechanismFactoryMock_CreateFinalizer_Call) RunAndReturn(run func(strin
 	return _c
 }
 
+// CreateResponseHandler provides a mock function with given fields: version, id, conf
+func (_m *MechanismFactoryMock) CreateResponseHandler(version string, id string, conf config.MechanismConfig) (responsehandlers.ResponseHandler, error) {
+	ret := _m.Called(version, id, conf)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CreateResponseHandler")
+	}
+
+	var r0 responsehandlers.ResponseHandler
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string, string, config.MechanismConfig) (responsehandlers.ResponseHandler, error)); ok {
+		return rf(version, id, conf)
+	}
+	if rf, ok := ret.Get(0).(func(string, string, config.MechanismConfig) responsehandlers.ResponseHandler); ok {
+		r0 = rf(version, id, conf)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(responsehandlers.ResponseHandler)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(string, string, config.MechanismConfig) error); ok {
+		r1 = rf(version, id, conf)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MechanismFactoryMock_CreateResponseHandler_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'CreateResponseHandler'
+type MechanismFactoryMock_CreateResponseHandler_Call struct {
+	*mock.Call
+}
+
+// CreateResponseHandler is a helper method to define mock.On call
+//   - version string
+//   - id string
+//   - conf config.MechanismConfig
+func (_e *MechanismFactoryMock_Expecter) CreateResponseHandler(version interface{}, id interface{}, conf interface{}) *MechanismFactoryMock_CreateResponseHandler_Call {
+	return &MechanismFactoryMock_CreateResponseHandler_Call{Call: _e.mock.On("CreateResponseHandler", version, id, conf)}
+}
+
+func (_c *MechanismFactoryMock_CreateResponseHandler_Call) Run(run func(version string, id string, conf config.MechanismConfig)) *MechanismFactoryMock_CreateResponseHandler_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string), args[1].(string), args[2].(config.MechanismConfig))
+	})
+	return _c
+}
+
+func (_c *MechanismFactoryMock_CreateResponseHandler_Call) Return(_a0 responsehandlers.ResponseHandler, _a1 error) *MechanismFactoryMock_CreateResponseHandler_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MechanismFactoryMock_CreateResponseHandler_Call) RunAndReturn(run func(string, string, config.MechanismConfig) (responsehandlers.ResponseHandler, error)) *MechanismFactoryMock_CreateResponseHandler_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // NewMechanismFactoryMock creates a new instance of MechanismFactoryMock. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
 // The first argument is typically a *testing.T value.
 func NewMechanismFactoryMock(t interface {
@@ -31,6 +31,8 @@ type Claims struct {
 	NotBefore *NumericDate `json:"nbf,omitempty"`
 	IssuedAt  *NumericDate `json:"iat,omitempty"`
 	ID        string       `json:"jti,omitempty"`
+	// Confirmation holds the key binding information of sender-constrained tokens (RFC 7800)
+	Confirmation *Confirmation `json:"cnf,omitempty"`
 }
 
 func (c Claims) Validate(exp Expectation) error {
@@ -0,0 +1,43 @@
+// Copyright 2025 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package oauth2
+
+// Confirmation represents the confirmation ("cnf") claim of sender-constrained tokens
+// as defined in RFC 7800.
+type Confirmation struct {
+	// JWKThumbprint holds the thumbprint of the key a DPoP-bound token is bound to (RFC 9449)
+	JWKThumbprint string `json:"jkt,omitempty"`
+	// X509Thumbprint holds the SHA-256 thumbprint of the client certificate a certificate-bound
+	// token is bound to (RFC 8705)
+	X509Thumbprint string `json:"x5t#S256,omitempty"`
+}
+
+func (c *Confirmation) JKT() string {
+	if c == nil {
+		return ""
+	}
+
+	return c.JWKThumbprint
+}
+
+func (c *Confirmation) X5T() string {
+	if c == nil {
+		return ""
+	}
+
+	return c.X509Thumbprint
+}
@@ -0,0 +1,230 @@
+// Copyright 2025 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package oauth2
+
+import (
+	"context"
+	"crypto"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/go-jose/go-jose/v4"
+	"github.com/goccy/go-json"
+
+	"github.com/dadrus/heimdall/internal/cache"
+	"github.com/dadrus/heimdall/internal/heimdall"
+	"github.com/dadrus/heimdall/internal/x/errorchain"
+	"github.com/dadrus/heimdall/internal/x/stringx"
+)
+
+const (
+	dpopProofType          = "dpop+jwt"
+	defaultDPoPProofMaxAge = 1 * time.Minute
+)
+
+var ErrInvalidDPoPProof = errors.New("invalid DPoP proof")
+
+// DPoP holds the settings used to verify DPoP proofs as defined in RFC 9449.
+type DPoP struct {
+	Required          bool          `mapstructure:"required"`
+	ProofMaxAge       time.Duration `mapstructure:"proof_max_age"`
+	AllowedAlgorithms []string      `mapstructure:"allowed_algorithms"`
+}
+
+type dpopProofClaims struct {
+	ID              string       `json:"jti"`
+	Method          string       `json:"htm"`
+	URL             string       `json:"htu"`
+	IssuedAt        *NumericDate `json:"iat"`
+	AccessTokenHash string       `json:"ath"`
+}
+
+// algorithms returns the signature algorithms accepted for DPoP proofs.
+func (d *DPoP) algorithms() []string {
+	if len(d.AllowedAlgorithms) != 0 {
+		return d.AllowedAlgorithms
+	}
+
+	return []string{
+		string(jose.ES256), string(jose.ES384), string(jose.ES512), string(jose.EdDSA),
+		string(jose.PS256), string(jose.PS384), string(jose.PS512),
+	}
+}
+
+// VerifyProof verifies the given DPoP proof for the request with the given method and url as well as
+// the access token presented with it. On success, it returns the base64url encoded SHA-256 thumbprint
+// of the key, the proof has been signed with.
+func (d *DPoP) VerifyProof(
+	ctx context.Context, proof, method string, reqURL *url.URL, accessToken string,
+) (string, error) {
+	if len(proof) == 0 {
+		return "", errorchain.NewWithMessage(ErrInvalidDPoPProof, "no DPoP proof present")
+	}
+
+	if strings.Contains(proof, ",") {
+		return "", errorchain.NewWithMessage(ErrInvalidDPoPProof, "multiple DPoP proofs present")
+	}
+
+	algs := d.algorithms()
+	sigAlgs := make([]jose.SignatureAlgorithm, len(algs))
+
+	for idx, alg := range algs {
+		sigAlgs[idx] = jose.SignatureAlgorithm(alg)
+	}
+
+	jws, err := jose.ParseSigned(proof, sigAlgs)
+	if err != nil {
+		return "", errorchain.NewWithMessage(ErrInvalidDPoPProof, "failed to parse DPoP proof").CausedBy(err)
+	}
+
+	header := jws.Signatures[0].Protected
+	if typ, _ := header.ExtraHeaders[jose.HeaderType].(string); typ != dpopProofType {
+		return "", errorchain.NewWithMessagef(ErrInvalidDPoPProof, "unexpected DPoP proof type '%s'", typ)
+	}
+
+	key := header.JSONWebKey
+	if key == nil || !key.Valid() || !key.IsPublic() {
+		return "", errorchain.NewWithMessage(ErrInvalidDPoPProof, "DPoP proof does not contain a valid public key")
+	}
+
+	payload, err := jws.Verify(key)
+	if err != nil {
+		return "", errorchain.NewWithMessage(ErrInvalidDPoPProof, "failed to verify DPoP proof signature").
+			CausedBy(err)
+	}
+
+	var claims dpopProofClaims
+	if err = json.Unmarshal(payload, &claims); err != nil {
+		return "", errorchain.NewWithMessage(ErrInvalidDPoPProof, "failed to deserialize DPoP proof").
+			CausedBy(err)
+	}
+
+	if err = d.verifyClaims(&claims, method, reqURL, accessToken); err != nil {
+		return "", err
+	}
+
+	thumbprint, err := key.Thumbprint(crypto.SHA256)
+	if err != nil {
+		return "", errorchain.NewWithMessage(ErrInvalidDPoPProof, "failed to calculate key thumbprint").
+			CausedBy(err)
+	}
+
+	jkt := base64.RawURLEncoding.EncodeToString(thumbprint)
+
+	if err = d.preventReplay(ctx, jkt, claims.ID); err != nil {
+		return "", err
+	}
+
+	return jkt, nil
+}
+
+func (d *DPoP) verifyClaims(claims *dpopProofClaims, method string, reqURL *url.URL, accessToken string) error {
+	if len(claims.ID) == 0 {
+		return errorchain.NewWithMessage(ErrInvalidDPoPProof, "DPoP proof does not contain a jti claim")
+	}
+
+	if claims.Method != method {
+		return errorchain.NewWithMessage(ErrInvalidDPoPProof, "htm claim does not match the request method")
+	}
+
+	if !matchesHTU(claims.URL, reqURL) {
+		return errorchain.NewWithMessage(ErrInvalidDPoPProof, "htu claim does not match the request url")
+	}
+
+	if claims.IssuedAt == nil {
+		return errorchain.NewWithMessage(ErrInvalidDPoPProof, "DPoP proof does not contain an iat claim")
+	}
+
+	if age := time.Since(claims.IssuedAt.Time()).Abs(); age > d.maxAge() {
+		return errorchain.NewWithMessage(ErrInvalidDPoPProof, "DPoP proof is outside of the accepted time window")
+	}
+
+	hash := sha256.Sum256(stringx.ToBytes(accessToken))
+	if claims.AccessTokenHash != base64.RawURLEncoding.EncodeToString(hash[:]) {
+		return errorchain.NewWithMessage(ErrInvalidDPoPProof, "ath claim does not match the access token")
+	}
+
+	return nil
+}
+
+func (d *DPoP) preventReplay(ctx context.Context, jkt, jti string) error {
+	cch := cache.Ctx(ctx)
+
+	digest := sha256.New()
+	digest.Write(stringx.ToBytes(jkt))
+	digest.Write(stringx.ToBytes(jti))
+
+	key := "dpop:jti:" + hex.EncodeToString(digest.Sum(nil))
+
+	// proofs are accepted within the time window before and after their issuance. The proof id
+	// is stored only if not already present, so concurrent replays cannot succeed.
+	stored, err := cch.CompareAndSwap(ctx, key, nil, []byte{1}, 2*d.maxAge())
+	if err != nil {
+		// fail closed, as replays cannot be detected otherwise
+		return errorchain.NewWithMessage(heimdall.ErrCommunication, "failed to check DPoP proof for replays").
+			CausedBy(err)
+	}
+
+	if !stored {
+		return errorchain.NewWithMessage(ErrInvalidDPoPProof, "DPoP proof has already been used")
+	}
+
+	return nil
+}
+
+func (d *DPoP) maxAge() time.Duration {
+	if d.ProofMaxAge > 0 {
+		return d.ProofMaxAge
+	}
+
+	return defaultDPoPProofMaxAge
+}
+
+func matchesHTU(htu string, reqURL *url.URL) bool {
+	expected, err := url.Parse(htu)
+	if err != nil || reqURL == nil {
+		return false
+	}
+
+	// query and fragment parts are ignored according to RFC 9449, section 4.3
+	return strings.EqualFold(expected.Scheme, reqURL.Scheme) &&
+		strings.EqualFold(normalizedHost(expected), normalizedHost(reqURL)) &&
+		normalizedPath(expected) == normalizedPath(reqURL)
+}
+
+func normalizedHost(u *url.URL) string {
+	port := u.Port()
+	if (port == "443" && strings.EqualFold(u.Scheme, "https")) ||
+		(port == "80" && strings.EqualFold(u.Scheme, "http")) {
+		return u.Hostname()
+	}
+
+	return u.Host
+}
+
+func normalizedPath(u *url.URL) string {
+	if len(u.EscapedPath()) == 0 {
+		return "/"
+	}
+
+	return u.EscapedPath()
+}
@@ -0,0 +1,424 @@
+// Copyright 2025 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package oauth2
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"net/url"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/go-jose/go-jose/v4"
+	"github.com/go-jose/go-jose/v4/jwt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dadrus/heimdall/internal/cache"
+	"github.com/dadrus/heimdall/internal/cache/memory"
+	"github.com/dadrus/heimdall/internal/cache/noop"
+	"github.com/dadrus/heimdall/internal/heimdall"
+)
+
+func createDPoPProof(
+	t *testing.T, key crypto.Signer, alg jose.SignatureAlgorithm, typ string, claims map[string]any,
+) string {
+	t.Helper()
+
+	signer, err := jose.NewSigner(
+		jose.SigningKey{Algorithm: alg, Key: key},
+		(&jose.SignerOptions{EmbedJWK: true}).WithType(jose.ContentType(typ)),
+	)
+	require.NoError(t, err)
+
+	proof, err := jwt.Signed(signer).Claims(claims).Serialize()
+	require.NoError(t, err)
+
+	return proof
+}
+
+func TestDPoPVerifyProof(t *testing.T) {
+	t.Parallel()
+
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	jwk := jose.JSONWebKey{Key: ecKey.Public()}
+	thumbprint, err := jwk.Thumbprint(crypto.SHA256)
+	require.NoError(t, err)
+
+	expectedJKT := base64.RawURLEncoding.EncodeToString(thumbprint)
+
+	accessToken := "foo.bar.baz"
+	hash := sha256.Sum256([]byte(accessToken))
+	ath := base64.RawURLEncoding.EncodeToString(hash[:])
+
+	reqURL, err := url.Parse("https://api.example.com/orders?limit=10")
+	require.NoError(t, err)
+
+	validClaims := func() map[string]any {
+		return map[string]any{
+			"jti": "id-1",
+			"htm": "POST",
+			"htu": "https://api.example.com:443/orders",
+			"iat": time.Now().Unix(),
+			"ath": ath,
+		}
+	}
+
+	for uc, tc := range map[string]struct {
+		conf   DPoP
+		proof  func(t *testing.T) string
+		assert func(t *testing.T, err error, jkt string)
+	}{
+		"no proof": {
+			proof: func(t *testing.T) string {
+				t.Helper()
+
+				return ""
+			},
+			assert: func(t *testing.T, err error, _ string) {
+				t.Helper()
+
+				require.ErrorIs(t, err, ErrInvalidDPoPProof)
+				assert.Contains(t, err.Error(), "no DPoP proof")
+			},
+		},
+		"multiple proofs": {
+			proof: func(t *testing.T) string {
+				t.Helper()
+
+				proof := createDPoPProof(t, ecKey, jose.ES256, dpopProofType, validClaims())
+
+				return proof + "," + proof
+			},
+			assert: func(t *testing.T, err error, _ string) {
+				t.Helper()
+
+				require.ErrorIs(t, err, ErrInvalidDPoPProof)
+				assert.Contains(t, err.Error(), "multiple DPoP proofs")
+			},
+		},
+		"malformed proof": {
+			proof: func(t *testing.T) string {
+				t.Helper()
+
+				return "foo.bar"
+			},
+			assert: func(t *testing.T, err error, _ string) {
+				t.Helper()
+
+				require.ErrorIs(t, err, ErrInvalidDPoPProof)
+				assert.Contains(t, err.Error(), "failed to parse")
+			},
+		},
+		"wrong proof type": {
+			proof: func(t *testing.T) string {
+				t.Helper()
+
+				return createDPoPProof(t, ecKey, jose.ES256, "JWT", validClaims())
+			},
+			assert: func(t *testing.T, err error, _ string) {
+				t.Helper()
+
+				require.ErrorIs(t, err, ErrInvalidDPoPProof)
+				assert.Contains(t, err.Error(), "unexpected DPoP proof type")
+			},
+		},
+		"algorithm not allowed": {
+			conf: DPoP{AllowedAlgorithms: []string{string(jose.PS256)}},
+			proof: func(t *testing.T) string {
+				t.Helper()
+
+				return createDPoPProof(t, ecKey, jose.ES256, dpopProofType, validClaims())
+			},
+			assert: func(t *testing.T, err error, _ string) {
+				t.Helper()
+
+				require.ErrorIs(t, err, ErrInvalidDPoPProof)
+				assert.Contains(t, err.Error(), "failed to parse")
+			},
+		},
+		"method mismatch": {
+			proof: func(t *testing.T) string {
+				t.Helper()
+
+				claims := validClaims()
+				claims["htm"] = "GET"
+
+				return createDPoPProof(t, ecKey, jose.ES256, dpopProofType, claims)
+			},
+			assert: func(t *testing.T, err error, _ string) {
+				t.Helper()
+
+				require.ErrorIs(t, err, ErrInvalidDPoPProof)
+				assert.Contains(t, err.Error(), "htm claim")
+			},
+		},
+		"url mismatch": {
+			proof: func(t *testing.T) string {
+				t.Helper()
+
+				claims := validClaims()
+				claims["htu"] = "https://api.example.com/customers"
+
+				return createDPoPProof(t, ecKey, jose.ES256, dpopProofType, claims)
+			},
+			assert: func(t *testing.T, err error, _ string) {
+				t.Helper()
+
+				require.ErrorIs(t, err, ErrInvalidDPoPProof)
+				assert.Contains(t, err.Error(), "htu claim")
+			},
+		},
+		"proof too old": {
+			proof: func(t *testing.T) string {
+				t.Helper()
+
+				claims := validClaims()
+				claims["iat"] = time.Now().Add(-2 * time.Minute).Unix()
+
+				return createDPoPProof(t, ecKey, jose.ES256, dpopProofType, claims)
+			},
+			assert: func(t *testing.T, err error, _ string) {
+				t.Helper()
+
+				require.ErrorIs(t, err, ErrInvalidDPoPProof)
+				assert.Contains(t, err.Error(), "time window")
+			},
+		},
+		"proof without iat": {
+			proof: func(t *testing.T) string {
+				t.Helper()
+
+				claims := validClaims()
+				delete(claims, "iat")
+
+				return createDPoPProof(t, ecKey, jose.ES256, dpopProofType, claims)
+			},
+			assert: func(t *testing.T, err error, _ string) {
+				t.Helper()
+
+				require.ErrorIs(t, err, ErrInvalidDPoPProof)
+				assert.Contains(t, err.Error(), "iat claim")
+			},
+		},
+		"proof without jti": {
+			proof: func(t *testing.T) string {
+				t.Helper()
+
+				claims := validClaims()
+				delete(claims, "jti")
+
+				return createDPoPProof(t, ecKey, jose.ES256, dpopProofType, claims)
+			},
+			assert: func(t *testing.T, err error, _ string) {
+				t.Helper()
+
+				require.ErrorIs(t, err, ErrInvalidDPoPProof)
+				assert.Contains(t, err.Error(), "jti claim")
+			},
+		},
+		"access token hash mismatch": {
+			proof: func(t *testing.T) string {
+				t.Helper()
+
+				claims := validClaims()
+				claims["ath"] = "foo"
+
+				return createDPoPProof(t, ecKey, jose.ES256, dpopProofType, claims)
+			},
+			assert: func(t *testing.T, err error, _ string) {
+				t.Helper()
+
+				require.ErrorIs(t, err, ErrInvalidDPoPProof)
+				assert.Contains(t, err.Error(), "ath claim")
+			},
+		},
+		"valid proof signed with an ec key": {
+			proof: func(t *testing.T) string {
+				t.Helper()
+
+				return createDPoPProof(t, ecKey, jose.ES256, dpopProofType, validClaims())
+			},
+			assert: func(t *testing.T, err error, jkt string) {
+				t.Helper()
+
+				require.NoError(t, err)
+				assert.Equal(t, expectedJKT, jkt)
+			},
+		},
+		"valid proof signed with an rsa key with larger time window": {
+			conf: DPoP{ProofMaxAge: 5 * time.Minute},
+			proof: func(t *testing.T) string {
+				t.Helper()
+
+				claims := validClaims()
+				claims["iat"] = time.Now().Add(-2 * time.Minute).Unix()
+
+				return createDPoPProof(t, rsaKey, jose.PS256, dpopProofType, claims)
+			},
+			assert: func(t *testing.T, err error, jkt string) {
+				t.Helper()
+
+				require.NoError(t, err)
+				assert.NotEmpty(t, jkt)
+				assert.NotEqual(t, expectedJKT, jkt)
+			},
+		},
+	} {
+		t.Run(uc, func(t *testing.T) {
+			// GIVEN
+			cch, err := memory.NewCache(nil, nil)
+			require.NoError(t, err)
+
+			ctx := cache.WithContext(t.Context(), cch)
+
+			// WHEN
+			jkt, err := tc.conf.VerifyProof(ctx, tc.proof(t), "POST", reqURL, accessToken)
+
+			// THEN
+			tc.assert(t, err, jkt)
+		})
+	}
+}
+
+func TestDPoPVerifyProofPreventsReplay(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	hash := sha256.Sum256([]byte("foo"))
+	proof := createDPoPProof(t, key, jose.ES256, dpopProofType, map[string]any{
+		"jti": "id-1",
+		"htm": "GET",
+		"htu": "http://foo.bar/",
+		"iat": time.Now().Unix(),
+		"ath": base64.RawURLEncoding.EncodeToString(hash[:]),
+	})
+
+	reqURL := &url.URL{Scheme: "http", Host: "foo.bar:80"}
+
+	cch, err := memory.NewCache(nil, nil)
+	require.NoError(t, err)
+
+	ctx := cache.WithContext(t.Context(), cch)
+	conf := DPoP{}
+
+	// WHEN
+	_, err1 := conf.VerifyProof(ctx, proof, "GET", reqURL, "foo")
+	_, err2 := conf.VerifyProof(ctx, proof, "GET", reqURL, "foo")
+
+	// THEN
+	require.NoError(t, err1)
+	require.ErrorIs(t, err2, ErrInvalidDPoPProof)
+	assert.Contains(t, err2.Error(), "already been used")
+}
+
+func TestDPoPVerifyProofFailsOnCacheErrors(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	hash := sha256.Sum256([]byte("foo"))
+	proof := createDPoPProof(t, key, jose.ES256, dpopProofType, map[string]any{
+		"jti": "id-1",
+		"htm": "GET",
+		"htu": "http://foo.bar/",
+		"iat": time.Now().Unix(),
+		"ath": base64.RawURLEncoding.EncodeToString(hash[:]),
+	})
+
+	reqURL := &url.URL{Scheme: "http", Host: "foo.bar:80"}
+	ctx := cache.WithContext(t.Context(), &noop.Cache{})
+	conf := DPoP{}
+
+	// WHEN
+	_, err = conf.VerifyProof(ctx, proof, "GET", reqURL, "foo")
+
+	// THEN
+	require.Error(t, err)
+	require.ErrorIs(t, err, heimdall.ErrCommunication)
+	require.ErrorIs(t, err, noop.ErrCompareAndSwapNotSupported)
+	assert.Contains(t, err.Error(), "failed to check DPoP proof for replays")
+}
+
+func TestDPoPVerifyProofConcurrentReplay(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	hash := sha256.Sum256([]byte("foo"))
+	proof := createDPoPProof(t, key, jose.ES256, dpopProofType, map[string]any{
+		"jti": "id-1",
+		"htm": "GET",
+		"htu": "http://foo.bar/",
+		"iat": time.Now().Unix(),
+		"ath": base64.RawURLEncoding.EncodeToString(hash[:]),
+	})
+
+	reqURL := &url.URL{Scheme: "http", Host: "foo.bar:80"}
+
+	cch, err := memory.NewCache(nil, nil)
+	require.NoError(t, err)
+
+	ctx := cache.WithContext(t.Context(), cch)
+	conf := DPoP{}
+
+	var (
+		accepted atomic.Int32
+		wg       sync.WaitGroup
+		start    = make(chan struct{})
+	)
+
+	// WHEN
+	for range 50 {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			<-start
+
+			if _, err := conf.VerifyProof(ctx, proof, "GET", reqURL, "foo"); err == nil {
+				accepted.Add(1)
+			}
+		}()
+	}
+
+	close(start)
+	wg.Wait()
+
+	// THEN
+	assert.Equal(t, int32(1), accepted.Load())
+}
@@ -40,6 +40,9 @@ func TestMetadataEndpointGet(t *testing.T) {
 		Issuer                             string   `json:"issuer"`
 		JWKSEndpointURL                    string   `json:"jwks_uri"`
 		IntrospectionEndpointURL           string   `json:"introspection_endpoint"`
+		TokenEndpointURL                   string   `json:"token_endpoint,omitempty"`
+		AuthorizationEndpointURL           string   `json:"authorization_endpoint,omitempty"`
+		EndSessionEndpointURL              string   `json:"end_session_endpoint,omitempty"`
 		TokenEndpointAuthSigningAlgorithms []string `json:"token_endpoint_auth_signing_alg_values_supported"`
 	}
 
@@ -220,6 +223,74 @@ func TestMetadataEndpointGet(t *testing.T) {
 				require.ErrorContains(t, err, "introspection_endpoint contains a template")
 			},
 		},
+		"server's response contains token_endpoint with template": {
+			buildURL: func(t *testing.T, baseURL string) string {
+				t.Helper()
+
+				return baseURL
+			},
+			checkRequest: func(t *testing.T, _ *http.Request) { t.Helper() },
+			createResponse: func(t *testing.T, rw http.ResponseWriter) {
+				t.Helper()
+
+				rw.Header().Set("Content-Type", "application/json")
+
+				err := json.NewEncoder(rw).Encode(metadata{
+					Issuer:           "heimdall.test",
+					JWKSEndpointURL:  "https://foo.bar/jwks",
+					TokenEndpointURL: "https://foo.bar/{{ .Foo }}/token",
+				})
+				require.NoError(t, err)
+			},
+			assert: func(t *testing.T, endpointCalled bool, err error, _ ServerMetadata) {
+				t.Helper()
+
+				require.True(t, endpointCalled)
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrConfiguration)
+				require.ErrorContains(t, err, "token_endpoint contains a template")
+			},
+		},
+		"valid server response with openid connect endpoints": {
+			buildURL: func(t *testing.T, baseURL string) string {
+				t.Helper()
+
+				return baseURL
+			},
+			checkRequest: func(t *testing.T, _ *http.Request) { t.Helper() },
+			createResponse: func(t *testing.T, rw http.ResponseWriter) {
+				t.Helper()
+
+				rw.Header().Set("Content-Type", "application/json")
+
+				err := json.NewEncoder(rw).Encode(metadata{
+					Issuer:                   srv.URL,
+					JWKSEndpointURL:          "https://foo.bar/jwks",
+					TokenEndpointURL:         "https://foo.bar/token",
+					AuthorizationEndpointURL: "https://foo.bar/authorize",
+					EndSessionEndpointURL:    "https://foo.bar/logout",
+				})
+				require.NoError(t, err)
+			},
+			assert: func(t *testing.T, endpointCalled bool, err error, sm ServerMetadata) {
+				t.Helper()
+
+				require.True(t, endpointCalled)
+				require.NoError(t, err)
+
+				assert.Equal(t, "https://foo.bar/authorize", sm.AuthorizationEndpoint)
+				assert.Equal(t, "https://foo.bar/logout", sm.EndSessionEndpoint)
+				assert.Nil(t, sm.IntrospectionEndpoint)
+				assert.Equal(t, endpoint.Endpoint{
+					URL:    "https://foo.bar/token",
+					Method: http.MethodPost,
+					Headers: map[string]string{
+						"Content-Type": "application/x-www-form-urlencoded",
+						"Accept":       "application/json",
+					},
+				}, *sm.TokenEndpoint)
+			},
+		},
 		"valid server response for templated URL": {
 			args: map[string]any{"Foo": "bar"},
 			buildURL: func(t *testing.T, baseURL string) string {
@@ -29,6 +29,9 @@ type ServerMetadata struct {
 	Issuer                string
 	JWKSEndpoint          *endpoint.Endpoint
 	IntrospectionEndpoint *endpoint.Endpoint
+	TokenEndpoint         *endpoint.Endpoint
+	AuthorizationEndpoint string
+	EndSessionEndpoint    string
 }
 
 func (sm ServerMetadata) verify(usedMetadataURL string) error {
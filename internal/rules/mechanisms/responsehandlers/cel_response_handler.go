@@ -0,0 +1,137 @@
+// Copyright 2025 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package responsehandlers
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/google/cel-go/cel"
+	"github.com/rs/zerolog"
+
+	"github.com/dadrus/heimdall/internal/app"
+	"github.com/dadrus/heimdall/internal/heimdall"
+	"github.com/dadrus/heimdall/internal/rules/mechanisms/cellib"
+	"github.com/dadrus/heimdall/internal/rules/mechanisms/subject"
+	"github.com/dadrus/heimdall/internal/x"
+	"github.com/dadrus/heimdall/internal/x/errorchain"
+)
+
+// by intention. Used only during application bootstrap
+//
+//nolint:gochecknoinits
+func init() {
+	registerTypeFactory(
+		func(app app.Context, id string, typ string, conf map[string]any) (bool, ResponseHandler, error) {
+			if typ != ResponseHandlerCEL {
+				return false, nil, nil
+			}
+
+			handler, err := newCELResponseHandler(app, id, conf)
+
+			return true, handler, err
+		})
+}
+
+type Expression struct {
+	Value   string `mapstructure:"expression" validate:"required"`
+	Message string `mapstructure:"message"`
+}
+
+type celResponseHandler struct {
+	id          string
+	app         app.Context
+	expressions []*cellib.CompiledExpression
+}
+
+func newCELResponseHandler(app app.Context, id string, rawConfig map[string]any) (*celResponseHandler, error) {
+	logger := app.Logger()
+	logger.Info().Str("_id", id).Msg("Creating cel response handler")
+
+	type Config struct {
+		Expressions []Expression `mapstructure:"expressions" validate:"required,gt=0,dive"`
+	}
+
+	var conf Config
+	if err := decodeConfig(app.Validator(), rawConfig, &conf); err != nil {
+		return nil, errorchain.NewWithMessagef(heimdall.ErrConfiguration,
+			"failed decoding config for cel response handler '%s'", id).CausedBy(err)
+	}
+
+	env, err := cel.NewEnv(cellib.Library())
+	if err != nil {
+		return nil, errorchain.NewWithMessage(heimdall.ErrInternal,
+			"failed creating CEL environment").CausedBy(err)
+	}
+
+	expressions := make([]*cellib.CompiledExpression, len(conf.Expressions))
+
+	for i, expression := range conf.Expressions {
+		exp, err := cellib.CompileExpression(
+			env,
+			expression.Value,
+			x.IfThenElse(len(expression.Message) != 0, expression.Message, fmt.Sprintf("expression %d failed", i+1)),
+		)
+		if err != nil {
+			return nil, errorchain.NewWithMessagef(heimdall.ErrConfiguration,
+				"failed to compile expression %d (%s)", i+1, expression.Value).CausedBy(err)
+		}
+
+		expressions[i] = exp
+	}
+
+	return &celResponseHandler{id: id, app: app, expressions: expressions}, nil
+}
+
+func (h *celResponseHandler) Execute(
+	ctx heimdall.RequestContext,
+	sub *subject.Subject,
+	resp *heimdall.Response,
+) error {
+	logger := zerolog.Ctx(ctx.Context())
+	logger.Debug().Str("_id", h.id).Msg("Authorizing response using cel response handler")
+
+	obj := map[string]any{
+		"Subject":  sub,
+		"Request":  ctx.Request(),
+		"Outputs":  ctx.Outputs(),
+		"Response": resp,
+	}
+
+	for i, expression := range h.expressions {
+		if err := expression.Eval(obj); err != nil {
+			if errors.Is(err, &cellib.EvalError{}) {
+				return errorchain.New(heimdall.ErrAuthorization).CausedBy(err).WithErrorContext(h)
+			}
+
+			return errorchain.NewWithMessagef(heimdall.ErrInternal, "failed evaluating expression %d", i+1).
+				CausedBy(err).WithErrorContext(h)
+		}
+	}
+
+	return nil
+}
+
+func (h *celResponseHandler) WithConfig(rawConfig map[string]any) (ResponseHandler, error) {
+	if len(rawConfig) == 0 {
+		return h, nil
+	}
+
+	return newCELResponseHandler(h.app, h.id, rawConfig)
+}
+
+func (h *celResponseHandler) ID() string { return h.id }
@@ -0,0 +1,271 @@
+// Copyright 2025 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package responsehandlers
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/rs/zerolog/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dadrus/heimdall/internal/app"
+	"github.com/dadrus/heimdall/internal/heimdall"
+	"github.com/dadrus/heimdall/internal/heimdall/mocks"
+	"github.com/dadrus/heimdall/internal/rules/mechanisms/subject"
+	"github.com/dadrus/heimdall/internal/validation"
+	"github.com/dadrus/heimdall/internal/x/testsupport"
+)
+
+func TestCreateCELResponseHandler(t *testing.T) {
+	t.Parallel()
+
+	for uc, tc := range map[string]struct {
+		config []byte
+		assert func(t *testing.T, err error, handler *celResponseHandler)
+	}{
+		"without configuration": {
+			assert: func(t *testing.T, err error, _ *celResponseHandler) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrConfiguration)
+				assert.Contains(t, err.Error(), "'expressions' is a required field")
+			},
+		},
+		"without expressions": {
+			config: []byte(`expressions: []`),
+			assert: func(t *testing.T, err error, _ *celResponseHandler) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrConfiguration)
+				assert.Contains(t, err.Error(), "'expressions' must contain more than 0 items")
+			},
+		},
+		"with malformed expressions": {
+			config: []byte(`
+expressions:
+  - expression: "foo()"
+`),
+			assert: func(t *testing.T, err error, _ *celResponseHandler) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrConfiguration)
+				assert.Contains(t, err.Error(), "failed to compile expression 1")
+			},
+		},
+		"with expression list without expression value": {
+			config: []byte(`
+expressions:
+  - message: foo
+`),
+			assert: func(t *testing.T, err error, _ *celResponseHandler) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrConfiguration)
+				assert.Contains(t, err.Error(), "'expressions'[0].'expression' is a required field")
+			},
+		},
+		"with valid expression": {
+			config: []byte(`
+expressions:
+  - expression: "Response.StatusCode == 200"
+`),
+			assert: func(t *testing.T, err error, handler *celResponseHandler) {
+				t.Helper()
+
+				require.NoError(t, err)
+				assert.Equal(t, "with valid expression", handler.ID())
+				assert.Len(t, handler.expressions, 1)
+			},
+		},
+	} {
+		t.Run(uc, func(t *testing.T) {
+			// GIVEN
+			conf, err := testsupport.DecodeTestConfig(tc.config)
+			require.NoError(t, err)
+
+			validator, err := validation.NewValidator()
+			require.NoError(t, err)
+
+			appCtx := app.NewContextMock(t)
+			appCtx.EXPECT().Validator().Maybe().Return(validator)
+			appCtx.EXPECT().Logger().Return(log.Logger)
+
+			// WHEN
+			handler, err := newCELResponseHandler(appCtx, uc, conf)
+
+			// THEN
+			tc.assert(t, err, handler)
+		})
+	}
+}
+
+func TestCreateCELResponseHandlerFromPrototype(t *testing.T) {
+	t.Parallel()
+
+	for uc, tc := range map[string]struct {
+		config []byte
+		assert func(t *testing.T, err error, prototype *celResponseHandler, configured ResponseHandler)
+	}{
+		"without new configuration": {
+			assert: func(t *testing.T, err error, prototype *celResponseHandler, configured ResponseHandler) {
+				t.Helper()
+
+				require.NoError(t, err)
+				assert.Equal(t, prototype, configured)
+			},
+		},
+		"with new expressions": {
+			config: []byte(`
+expressions:
+  - expression: "Response.StatusCode != 500"
+`),
+			assert: func(t *testing.T, err error, prototype *celResponseHandler, configured ResponseHandler) {
+				t.Helper()
+
+				require.NoError(t, err)
+
+				handler, ok := configured.(*celResponseHandler)
+				require.True(t, ok)
+				assert.Equal(t, prototype.ID(), handler.ID())
+				assert.NotEqual(t, prototype.expressions, handler.expressions)
+			},
+		},
+	} {
+		t.Run(uc, func(t *testing.T) {
+			// GIVEN
+			conf, err := testsupport.DecodeTestConfig(tc.config)
+			require.NoError(t, err)
+
+			validator, err := validation.NewValidator()
+			require.NoError(t, err)
+
+			appCtx := app.NewContextMock(t)
+			appCtx.EXPECT().Validator().Maybe().Return(validator)
+			appCtx.EXPECT().Logger().Return(log.Logger)
+
+			prototype, err := newCELResponseHandler(appCtx, uc, map[string]any{
+				"expressions": []map[string]any{{"expression": "Response.StatusCode == 200"}},
+			})
+			require.NoError(t, err)
+
+			// WHEN
+			handler, err := prototype.WithConfig(conf)
+
+			// THEN
+			tc.assert(t, err, prototype, handler)
+		})
+	}
+}
+
+func TestCELResponseHandlerExecute(t *testing.T) {
+	t.Parallel()
+
+	for uc, tc := range map[string]struct {
+		config []byte
+		label  string
+		assert func(t *testing.T, err error)
+	}{
+		"denied by expression": {
+			label: "confidential",
+			config: []byte(`
+expressions:
+  - expression: Response.Header("X-Label") in Subject.Attributes.labels
+    message: label not visible to subject
+`),
+			assert: func(t *testing.T, err error) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrAuthorization)
+				assert.Contains(t, err.Error(), "label not visible to subject")
+
+				var identifier interface{ ID() string }
+				require.ErrorAs(t, err, &identifier)
+				assert.Equal(t, "denied by expression", identifier.ID())
+			},
+		},
+		"failed evaluating expression": {
+			label: "public",
+			config: []byte(`
+expressions:
+  - expression: Subject.Attributes.foo == "bar"
+`),
+			assert: func(t *testing.T, err error) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrInternal)
+				assert.Contains(t, err.Error(), "failed evaluating expression 1")
+			},
+		},
+		"expressions can use response, subject, request and outputs properties": {
+			label: "internal",
+			config: []byte(`
+expressions:
+  - expression: Response.StatusCode == 200
+  - expression: Response.Header("X-Label") in Subject.Attributes.labels
+  - expression: Response.Header("X-Missing") == ""
+  - expression: Request.Method == "GET"
+  - expression: Outputs.foo == "bar"
+`),
+			assert: func(t *testing.T, err error) {
+				t.Helper()
+
+				require.NoError(t, err)
+			},
+		},
+	} {
+		t.Run(uc, func(t *testing.T) {
+			// GIVEN
+			conf, err := testsupport.DecodeTestConfig(tc.config)
+			require.NoError(t, err)
+
+			validator, err := validation.NewValidator()
+			require.NoError(t, err)
+
+			appCtx := app.NewContextMock(t)
+			appCtx.EXPECT().Validator().Maybe().Return(validator)
+			appCtx.EXPECT().Logger().Return(log.Logger)
+
+			ctx := mocks.NewRequestContextMock(t)
+			ctx.EXPECT().Context().Return(t.Context())
+			ctx.EXPECT().Request().Return(&heimdall.Request{Method: http.MethodGet})
+			ctx.EXPECT().Outputs().Return(map[string]any{"foo": "bar"})
+
+			handler, err := newCELResponseHandler(appCtx, uc, conf)
+			require.NoError(t, err)
+
+			sub := &subject.Subject{ID: "foo", Attributes: map[string]any{"labels": []string{"public", "internal"}}}
+			resp := &heimdall.Response{
+				StatusCode: http.StatusOK,
+				Header:     http.Header{"X-Label": {tc.label}},
+			}
+
+			// WHEN
+			err = handler.Execute(ctx, sub, resp)
+
+			// THEN
+			tc.assert(t, err)
+		})
+	}
+}
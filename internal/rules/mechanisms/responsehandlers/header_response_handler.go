@@ -0,0 +1,164 @@
+// Copyright 2025 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package responsehandlers
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/rs/zerolog"
+
+	"github.com/dadrus/heimdall/internal/app"
+	"github.com/dadrus/heimdall/internal/heimdall"
+	"github.com/dadrus/heimdall/internal/rules/mechanisms/subject"
+	"github.com/dadrus/heimdall/internal/rules/mechanisms/template"
+	"github.com/dadrus/heimdall/internal/x/errorchain"
+)
+
+// by intention. Used only during application bootstrap
+//
+//nolint:gochecknoinits
+func init() {
+	registerTypeFactory(
+		func(app app.Context, id string, typ string, conf map[string]any) (bool, ResponseHandler, error) {
+			if typ != ResponseHandlerHeader {
+				return false, nil, nil
+			}
+
+			handler, err := newHeaderResponseHandler(app, id, conf)
+
+			return true, handler, err
+		})
+}
+
+type headerResponseHandler struct {
+	id     string
+	app    app.Context
+	remove []string
+	rename map[string]string
+	add    map[string]template.Template
+}
+
+func newHeaderResponseHandler(app app.Context, id string, rawConfig map[string]any) (*headerResponseHandler, error) {
+	logger := app.Logger()
+	logger.Info().Str("_id", id).Msg("Creating header response handler")
+
+	type Config struct {
+		Remove []string                     `mapstructure:"remove" validate:"omitempty,dive,required"`
+		Rename map[string]string            `mapstructure:"rename" validate:"omitempty,dive,keys,required,endkeys,required"` //nolint:lll
+		Add    map[string]template.Template `mapstructure:"add"    validate:"omitempty,dive,keys,required,endkeys"`
+	}
+
+	var conf Config
+	if err := decodeConfig(app.Validator(), rawConfig, &conf); err != nil {
+		return nil, errorchain.NewWithMessagef(heimdall.ErrConfiguration,
+			"failed decoding config for header response handler '%s'", id).CausedBy(err)
+	}
+
+	if len(conf.Remove) == 0 && len(conf.Rename) == 0 && len(conf.Add) == 0 {
+		return nil, errorchain.NewWithMessagef(heimdall.ErrConfiguration,
+			"header response handler '%s' requires at least one of 'remove', 'rename' or 'add' to be configured", id)
+	}
+
+	return &headerResponseHandler{
+		id:     id,
+		app:    app,
+		remove: conf.Remove,
+		rename: conf.Rename,
+		add:    conf.Add,
+	}, nil
+}
+
+func (h *headerResponseHandler) Execute(
+	ctx heimdall.RequestContext,
+	sub *subject.Subject,
+	resp *heimdall.Response,
+) error {
+	logger := zerolog.Ctx(ctx.Context())
+	logger.Debug().Str("_id", h.id).Msg("Handling response using header response handler")
+
+	for _, name := range h.remove {
+		removeHeader(resp.Header, name)
+	}
+
+	for from, to := range h.rename {
+		values := resp.Header.Values(from)
+		if len(values) == 0 {
+			continue
+		}
+
+		resp.Header.Del(from)
+		resp.Header.Del(to)
+
+		for _, value := range values {
+			resp.Header.Add(to, value)
+		}
+	}
+
+	for name, tmpl := range h.add {
+		value, err := tmpl.Render(map[string]any{
+			"Request":  ctx.Request(),
+			"Subject":  sub,
+			"Outputs":  ctx.Outputs(),
+			"Response": resp,
+		})
+		if err != nil {
+			return errorchain.
+				NewWithMessagef(heimdall.ErrInternal, "failed to render value for '%s' header", name).
+				WithErrorContext(h).
+				CausedBy(err)
+		}
+
+		// Split the rendered value into multiple values if newline-separated
+		for _, v := range strings.Split(value, "\n") {
+			if len(v) != 0 {
+				resp.Header.Add(name, v)
+			}
+		}
+	}
+
+	return nil
+}
+
+func (h *headerResponseHandler) WithConfig(rawConfig map[string]any) (ResponseHandler, error) {
+	if len(rawConfig) == 0 {
+		return h, nil
+	}
+
+	return newHeaderResponseHandler(h.app, h.id, rawConfig)
+}
+
+func (h *headerResponseHandler) ID() string { return h.id }
+
+// removeHeader removes the header with the given name. If the name ends with *, all
+// headers starting with the given prefix are removed.
+func removeHeader(header http.Header, name string) {
+	prefix, isPattern := strings.CutSuffix(name, "*")
+	if !isPattern {
+		header.Del(name)
+
+		return
+	}
+
+	prefix = strings.ToLower(prefix)
+
+	for key := range header {
+		if strings.HasPrefix(strings.ToLower(key), prefix) {
+			delete(header, key)
+		}
+	}
+}
@@ -0,0 +1,349 @@
+// Copyright 2025 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package responsehandlers
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/rs/zerolog/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dadrus/heimdall/internal/app"
+	"github.com/dadrus/heimdall/internal/heimdall"
+	"github.com/dadrus/heimdall/internal/heimdall/mocks"
+	"github.com/dadrus/heimdall/internal/rules/mechanisms/subject"
+	"github.com/dadrus/heimdall/internal/validation"
+	"github.com/dadrus/heimdall/internal/x"
+	"github.com/dadrus/heimdall/internal/x/testsupport"
+)
+
+func TestCreateHeaderResponseHandler(t *testing.T) {
+	t.Parallel()
+
+	for uc, tc := range map[string]struct {
+		config []byte
+		assert func(t *testing.T, err error, handler *headerResponseHandler)
+	}{
+		"without configuration": {
+			assert: func(t *testing.T, err error, _ *headerResponseHandler) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrConfiguration)
+				assert.Contains(t, err.Error(), "requires at least one of")
+			},
+		},
+		"with empty header name to remove": {
+			config: []byte(`remove: [ "" ]`),
+			assert: func(t *testing.T, err error, _ *headerResponseHandler) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrConfiguration)
+				assert.Contains(t, err.Error(), "'remove'[0] is a required field")
+			},
+		},
+		"with empty target header name to rename to": {
+			config: []byte(`
+rename:
+  X-Foo: ""
+`),
+			assert: func(t *testing.T, err error, _ *headerResponseHandler) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrConfiguration)
+				assert.Contains(t, err.Error(), "is a required field")
+			},
+		},
+		"with unsupported attributes": {
+			config: []byte(`
+remove: [ X-Foo ]
+foo: bar
+`),
+			assert: func(t *testing.T, err error, _ *headerResponseHandler) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrConfiguration)
+				assert.Contains(t, err.Error(), "failed decoding")
+			},
+		},
+		"with bad template": {
+			config: []byte(`
+add:
+  X-Bar: "{{ .Subject.ID | foobar }}"
+`),
+			assert: func(t *testing.T, err error, _ *headerResponseHandler) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrConfiguration)
+				assert.Contains(t, err.Error(), "failed decoding")
+			},
+		},
+		"with valid config": {
+			config: []byte(`
+remove: [ X-Internal-* ]
+rename:
+  X-Foo: X-Bar
+add:
+  X-Subject: "{{ .Subject.ID }}"
+`),
+			assert: func(t *testing.T, err error, handler *headerResponseHandler) {
+				t.Helper()
+
+				require.NoError(t, err)
+				assert.Equal(t, "with valid config", handler.ID())
+				assert.Equal(t, []string{"X-Internal-*"}, handler.remove)
+				assert.Equal(t, map[string]string{"X-Foo": "X-Bar"}, handler.rename)
+				assert.Len(t, handler.add, 1)
+
+				val, err := handler.add["X-Subject"].Render(map[string]any{
+					"Subject": &subject.Subject{ID: "baz"},
+				})
+				require.NoError(t, err)
+				assert.Equal(t, "baz", val)
+			},
+		},
+	} {
+		t.Run(uc, func(t *testing.T) {
+			// GIVEN
+			conf, err := testsupport.DecodeTestConfig(tc.config)
+			require.NoError(t, err)
+
+			validator, err := validation.NewValidator()
+			require.NoError(t, err)
+
+			appCtx := app.NewContextMock(t)
+			appCtx.EXPECT().Validator().Maybe().Return(validator)
+			appCtx.EXPECT().Logger().Return(log.Logger)
+
+			// WHEN
+			handler, err := newHeaderResponseHandler(appCtx, uc, conf)
+
+			// THEN
+			tc.assert(t, err, handler)
+		})
+	}
+}
+
+func TestCreateHeaderResponseHandlerFromPrototype(t *testing.T) {
+	t.Parallel()
+
+	for uc, tc := range map[string]struct {
+		config []byte
+		assert func(t *testing.T, err error, prototype *headerResponseHandler, configured ResponseHandler)
+	}{
+		"without new configuration": {
+			assert: func(t *testing.T, err error, prototype *headerResponseHandler, configured ResponseHandler) {
+				t.Helper()
+
+				require.NoError(t, err)
+				assert.Equal(t, prototype, configured)
+			},
+		},
+		"with new configuration": {
+			config: []byte(`remove: [ X-Bar ]`),
+			assert: func(t *testing.T, err error, prototype *headerResponseHandler, configured ResponseHandler) {
+				t.Helper()
+
+				require.NoError(t, err)
+				assert.NotEqual(t, prototype, configured)
+
+				handler, ok := configured.(*headerResponseHandler)
+				require.True(t, ok)
+				assert.Equal(t, prototype.ID(), handler.ID())
+				assert.Equal(t, []string{"X-Bar"}, handler.remove)
+			},
+		},
+		"with invalid new configuration": {
+			config: []byte(`foo: bar`),
+			assert: func(t *testing.T, err error, _ *headerResponseHandler, _ ResponseHandler) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrConfiguration)
+			},
+		},
+	} {
+		t.Run(uc, func(t *testing.T) {
+			// GIVEN
+			conf, err := testsupport.DecodeTestConfig(tc.config)
+			require.NoError(t, err)
+
+			validator, err := validation.NewValidator()
+			require.NoError(t, err)
+
+			appCtx := app.NewContextMock(t)
+			appCtx.EXPECT().Validator().Maybe().Return(validator)
+			appCtx.EXPECT().Logger().Return(log.Logger)
+
+			prototype, err := newHeaderResponseHandler(appCtx, uc, map[string]any{"remove": []string{"X-Foo"}})
+			require.NoError(t, err)
+
+			// WHEN
+			handler, err := prototype.WithConfig(conf)
+
+			// THEN
+			tc.assert(t, err, prototype, handler)
+		})
+	}
+}
+
+func TestHeaderResponseHandlerExecute(t *testing.T) {
+	t.Parallel()
+
+	for uc, tc := range map[string]struct {
+		config           []byte
+		header           http.Header
+		configureContext func(t *testing.T, ctx *mocks.RequestContextMock)
+		assert           func(t *testing.T, err error, header http.Header)
+	}{
+		"removes headers by name and prefix": {
+			config: []byte(`remove: [ X-Internal-*, Server ]`),
+			header: http.Header{
+				"X-Internal-Id":    {"1"},
+				"X-Internal-Trace": {"2"},
+				"Server":           {"foo"},
+				"Content-Type":     {"text/plain"},
+			},
+			assert: func(t *testing.T, err error, header http.Header) {
+				t.Helper()
+
+				require.NoError(t, err)
+				assert.Equal(t, http.Header{"Content-Type": {"text/plain"}}, header)
+			},
+		},
+		"renames headers": {
+			config: []byte(`
+rename:
+  X-Upstream-User: X-User
+  X-Not-Present: X-Whatever
+`),
+			header: http.Header{
+				"X-Upstream-User": {"foo", "bar"},
+				"X-User":          {"baz"},
+			},
+			assert: func(t *testing.T, err error, header http.Header) {
+				t.Helper()
+
+				require.NoError(t, err)
+				assert.Equal(t, http.Header{"X-User": {"foo", "bar"}}, header)
+			},
+		},
+		"template rendering error": {
+			config: []byte(`
+add:
+  X-Baz: '{{ .Request.Foo "X-Foo" }}'
+`),
+			header: http.Header{},
+			configureContext: func(t *testing.T, ctx *mocks.RequestContextMock) {
+				t.Helper()
+
+				reqf := mocks.NewRequestFunctionsMock(t)
+
+				ctx.EXPECT().Request().Return(&heimdall.Request{RequestFunctions: reqf})
+				ctx.EXPECT().Outputs().Return(map[string]any{})
+			},
+			assert: func(t *testing.T, err error, _ http.Header) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrInternal)
+				assert.Contains(t, err.Error(), "failed to render value for 'X-Baz' header")
+
+				var identifier interface{ ID() string }
+				require.ErrorAs(t, err, &identifier)
+				assert.Equal(t, "template rendering error", identifier.ID())
+			},
+		},
+		"removes, renames and adds headers": {
+			config: []byte(`
+remove: [ X-Secret ]
+rename:
+  X-Foo: X-Bar
+add:
+  X-Subject: "{{ .Subject.ID }}"
+  X-Status: "{{ .Response.StatusCode }}"
+  X-Source: '{{ .Response.Header.Get "X-Bar" }}'
+  X-Groups: |
+    {{- range .Subject.Attributes.groups }}
+    {{ . }}
+    {{- end }}
+`),
+			header: http.Header{
+				"X-Secret": {"foo"},
+				"X-Foo":    {"bar"},
+			},
+			configureContext: func(t *testing.T, ctx *mocks.RequestContextMock) {
+				t.Helper()
+
+				ctx.EXPECT().Request().Return(&heimdall.Request{})
+				ctx.EXPECT().Outputs().Return(map[string]any{})
+			},
+			assert: func(t *testing.T, err error, header http.Header) {
+				t.Helper()
+
+				require.NoError(t, err)
+				assert.Empty(t, header.Values("X-Secret"))
+				assert.Empty(t, header.Values("X-Foo"))
+				assert.Equal(t, []string{"bar"}, header.Values("X-Bar"))
+				assert.Equal(t, []string{"bar"}, header.Values("X-Source"))
+				assert.Equal(t, []string{"foo"}, header.Values("X-Subject"))
+				assert.Equal(t, []string{"200"}, header.Values("X-Status"))
+				assert.Equal(t, []string{"group1", "group2"}, header.Values("X-Groups"))
+			},
+		},
+	} {
+		t.Run(uc, func(t *testing.T) {
+			// GIVEN
+			configureContext := x.IfThenElse(tc.configureContext != nil,
+				tc.configureContext,
+				func(t *testing.T, _ *mocks.RequestContextMock) { t.Helper() })
+
+			conf, err := testsupport.DecodeTestConfig(tc.config)
+			require.NoError(t, err)
+
+			ctx := mocks.NewRequestContextMock(t)
+			ctx.EXPECT().Context().Return(t.Context())
+
+			configureContext(t, ctx)
+
+			validator, err := validation.NewValidator()
+			require.NoError(t, err)
+
+			appCtx := app.NewContextMock(t)
+			appCtx.EXPECT().Validator().Maybe().Return(validator)
+			appCtx.EXPECT().Logger().Return(log.Logger)
+
+			handler, err := newHeaderResponseHandler(appCtx, uc, conf)
+			require.NoError(t, err)
+
+			sub := &subject.Subject{ID: "foo", Attributes: map[string]any{"groups": []string{"group1", "group2"}}}
+			resp := &heimdall.Response{StatusCode: http.StatusOK, Header: tc.header}
+
+			// WHEN
+			err = handler.Execute(ctx, sub, resp)
+
+			// THEN
+			tc.assert(t, err, resp.Header)
+		})
+	}
+}
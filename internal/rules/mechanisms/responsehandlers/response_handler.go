@@ -0,0 +1,32 @@
+// Copyright 2025 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package responsehandlers
+
+import (
+	"github.com/dadrus/heimdall/internal/heimdall"
+	"github.com/dadrus/heimdall/internal/rules/mechanisms/subject"
+)
+
+//go:generate mockery --name ResponseHandler --structname ResponseHandlerMock
+
+// ResponseHandler is executed on the response of the upstream service in proxy mode and
+// can inspect and modify it before it is sent to the client.
+type ResponseHandler interface {
+	ID() string
+	Execute(ctx heimdall.RequestContext, sub *subject.Subject, resp *heimdall.Response) error
+	WithConfig(config map[string]any) (ResponseHandler, error)
+}
@@ -0,0 +1,77 @@
+// Copyright 2025 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package responsehandlers
+
+import (
+	"testing"
+
+	"github.com/rs/zerolog/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dadrus/heimdall/internal/app"
+	"github.com/dadrus/heimdall/internal/validation"
+)
+
+func TestCreateResponseHandlerPrototype(t *testing.T) {
+	t.Parallel()
+
+	// there are 3 response handlers implemented, which should have been registered
+	require.Len(t, typeFactories, 3)
+
+	for uc, tc := range map[string]struct {
+		typ    string
+		config map[string]any
+		assert func(t *testing.T, err error, handler ResponseHandler)
+	}{
+		"using known type": {
+			typ:    ResponseHandlerStatusCode,
+			config: map[string]any{"to": 404},
+			assert: func(t *testing.T, err error, handler ResponseHandler) {
+				t.Helper()
+
+				require.NoError(t, err)
+				assert.IsType(t, &statusCodeResponseHandler{}, handler)
+			},
+		},
+		"using unknown type": {
+			typ: "foo",
+			assert: func(t *testing.T, err error, _ ResponseHandler) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, ErrUnsupportedType)
+			},
+		},
+	} {
+		t.Run(uc, func(t *testing.T) {
+			// GIVEN
+			validator, err := validation.NewValidator()
+			require.NoError(t, err)
+
+			appCtx := app.NewContextMock(t)
+			appCtx.EXPECT().Logger().Maybe().Return(log.Logger)
+			appCtx.EXPECT().Validator().Maybe().Return(validator)
+
+			// WHEN
+			handler, err := CreatePrototype(appCtx, "foo", tc.typ, tc.config)
+
+			// THEN
+			tc.assert(t, err, handler)
+		})
+	}
+}
@@ -0,0 +1,103 @@
+// Copyright 2025 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package responsehandlers
+
+import (
+	"slices"
+
+	"github.com/rs/zerolog"
+
+	"github.com/dadrus/heimdall/internal/app"
+	"github.com/dadrus/heimdall/internal/heimdall"
+	"github.com/dadrus/heimdall/internal/rules/mechanisms/subject"
+	"github.com/dadrus/heimdall/internal/x/errorchain"
+)
+
+// by intention. Used only during application bootstrap
+//
+//nolint:gochecknoinits
+func init() {
+	registerTypeFactory(
+		func(app app.Context, id string, typ string, conf map[string]any) (bool, ResponseHandler, error) {
+			if typ != ResponseHandlerStatusCode {
+				return false, nil, nil
+			}
+
+			handler, err := newStatusCodeResponseHandler(app, id, conf)
+
+			return true, handler, err
+		})
+}
+
+type statusCodeResponseHandler struct {
+	id   string
+	app  app.Context
+	from []int
+	to   int
+}
+
+func newStatusCodeResponseHandler(
+	app app.Context,
+	id string,
+	rawConfig map[string]any,
+) (*statusCodeResponseHandler, error) {
+	logger := app.Logger()
+	logger.Info().Str("_id", id).Msg("Creating status_code response handler")
+
+	type Config struct {
+		From []int `mapstructure:"from" validate:"omitempty,dive,gte=100,lte=599"`
+		To   int   `mapstructure:"to"   validate:"required,gte=100,lte=599"`
+	}
+
+	var conf Config
+	if err := decodeConfig(app.Validator(), rawConfig, &conf); err != nil {
+		return nil, errorchain.NewWithMessagef(heimdall.ErrConfiguration,
+			"failed decoding config for status_code response handler '%s'", id).CausedBy(err)
+	}
+
+	return &statusCodeResponseHandler{
+		id:   id,
+		app:  app,
+		from: conf.From,
+		to:   conf.To,
+	}, nil
+}
+
+func (h *statusCodeResponseHandler) Execute(
+	ctx heimdall.RequestContext,
+	_ *subject.Subject,
+	resp *heimdall.Response,
+) error {
+	logger := zerolog.Ctx(ctx.Context())
+	logger.Debug().Str("_id", h.id).Msg("Handling response using status_code response handler")
+
+	if len(h.from) == 0 || slices.Contains(h.from, resp.StatusCode) {
+		resp.StatusCode = h.to
+	}
+
+	return nil
+}
+
+func (h *statusCodeResponseHandler) WithConfig(rawConfig map[string]any) (ResponseHandler, error) {
+	if len(rawConfig) == 0 {
+		return h, nil
+	}
+
+	return newStatusCodeResponseHandler(h.app, h.id, rawConfig)
+}
+
+func (h *statusCodeResponseHandler) ID() string { return h.id }
@@ -0,0 +1,235 @@
+// Copyright 2025 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package responsehandlers
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/rs/zerolog/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dadrus/heimdall/internal/app"
+	"github.com/dadrus/heimdall/internal/heimdall"
+	"github.com/dadrus/heimdall/internal/heimdall/mocks"
+	"github.com/dadrus/heimdall/internal/validation"
+	"github.com/dadrus/heimdall/internal/x/testsupport"
+)
+
+func TestCreateStatusCodeResponseHandler(t *testing.T) {
+	t.Parallel()
+
+	for uc, tc := range map[string]struct {
+		config []byte
+		assert func(t *testing.T, err error, handler *statusCodeResponseHandler)
+	}{
+		"without configuration": {
+			assert: func(t *testing.T, err error, _ *statusCodeResponseHandler) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrConfiguration)
+				assert.Contains(t, err.Error(), "'to' is a required field")
+			},
+		},
+		"with invalid target status code": {
+			config: []byte(`to: 700`),
+			assert: func(t *testing.T, err error, _ *statusCodeResponseHandler) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrConfiguration)
+				assert.Contains(t, err.Error(), "'to' must be 599 or less")
+			},
+		},
+		"with invalid source status code": {
+			config: []byte(`
+from: [ 50 ]
+to: 404
+`),
+			assert: func(t *testing.T, err error, _ *statusCodeResponseHandler) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrConfiguration)
+				assert.Contains(t, err.Error(), "'from'[0] must be 100 or greater")
+			},
+		},
+		"with unsupported attributes": {
+			config: []byte(`
+to: 404
+foo: bar
+`),
+			assert: func(t *testing.T, err error, _ *statusCodeResponseHandler) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrConfiguration)
+				assert.Contains(t, err.Error(), "failed decoding")
+			},
+		},
+		"with valid config": {
+			config: []byte(`
+from: [ 401, 403 ]
+to: 404
+`),
+			assert: func(t *testing.T, err error, handler *statusCodeResponseHandler) {
+				t.Helper()
+
+				require.NoError(t, err)
+				assert.Equal(t, "with valid config", handler.ID())
+				assert.Equal(t, []int{401, 403}, handler.from)
+				assert.Equal(t, 404, handler.to)
+			},
+		},
+	} {
+		t.Run(uc, func(t *testing.T) {
+			// GIVEN
+			conf, err := testsupport.DecodeTestConfig(tc.config)
+			require.NoError(t, err)
+
+			validator, err := validation.NewValidator()
+			require.NoError(t, err)
+
+			appCtx := app.NewContextMock(t)
+			appCtx.EXPECT().Validator().Maybe().Return(validator)
+			appCtx.EXPECT().Logger().Return(log.Logger)
+
+			// WHEN
+			handler, err := newStatusCodeResponseHandler(appCtx, uc, conf)
+
+			// THEN
+			tc.assert(t, err, handler)
+		})
+	}
+}
+
+func TestCreateStatusCodeResponseHandlerFromPrototype(t *testing.T) {
+	t.Parallel()
+
+	for uc, tc := range map[string]struct {
+		config []byte
+		assert func(t *testing.T, err error, prototype *statusCodeResponseHandler, configured ResponseHandler)
+	}{
+		"without new configuration": {
+			assert: func(t *testing.T, err error, prototype *statusCodeResponseHandler, configured ResponseHandler) {
+				t.Helper()
+
+				require.NoError(t, err)
+				assert.Equal(t, prototype, configured)
+			},
+		},
+		"with new configuration": {
+			config: []byte(`to: 403`),
+			assert: func(t *testing.T, err error, prototype *statusCodeResponseHandler, configured ResponseHandler) {
+				t.Helper()
+
+				require.NoError(t, err)
+				assert.NotEqual(t, prototype, configured)
+
+				handler, ok := configured.(*statusCodeResponseHandler)
+				require.True(t, ok)
+				assert.Equal(t, prototype.ID(), handler.ID())
+				assert.Empty(t, handler.from)
+				assert.Equal(t, 403, handler.to)
+			},
+		},
+		"with invalid new configuration": {
+			config: []byte(`to: foo`),
+			assert: func(t *testing.T, err error, _ *statusCodeResponseHandler, _ ResponseHandler) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrConfiguration)
+			},
+		},
+	} {
+		t.Run(uc, func(t *testing.T) {
+			// GIVEN
+			conf, err := testsupport.DecodeTestConfig(tc.config)
+			require.NoError(t, err)
+
+			validator, err := validation.NewValidator()
+			require.NoError(t, err)
+
+			appCtx := app.NewContextMock(t)
+			appCtx.EXPECT().Validator().Maybe().Return(validator)
+			appCtx.EXPECT().Logger().Return(log.Logger)
+
+			prototype, err := newStatusCodeResponseHandler(appCtx, uc, map[string]any{"from": []int{401}, "to": 404})
+			require.NoError(t, err)
+
+			// WHEN
+			handler, err := prototype.WithConfig(conf)
+
+			// THEN
+			tc.assert(t, err, prototype, handler)
+		})
+	}
+}
+
+func TestStatusCodeResponseHandlerExecute(t *testing.T) {
+	t.Parallel()
+
+	for uc, tc := range map[string]struct {
+		config   map[string]any
+		status   int
+		expected int
+	}{
+		"rewrites any status code": {
+			config:   map[string]any{"to": http.StatusNotFound},
+			status:   http.StatusOK,
+			expected: http.StatusNotFound,
+		},
+		"rewrites matching status code": {
+			config:   map[string]any{"from": []int{http.StatusUnauthorized, http.StatusForbidden}, "to": http.StatusNotFound},
+			status:   http.StatusForbidden,
+			expected: http.StatusNotFound,
+		},
+		"does not rewrite not matching status code": {
+			config:   map[string]any{"from": []int{http.StatusUnauthorized, http.StatusForbidden}, "to": http.StatusNotFound},
+			status:   http.StatusOK,
+			expected: http.StatusOK,
+		},
+	} {
+		t.Run(uc, func(t *testing.T) {
+			// GIVEN
+			validator, err := validation.NewValidator()
+			require.NoError(t, err)
+
+			appCtx := app.NewContextMock(t)
+			appCtx.EXPECT().Validator().Maybe().Return(validator)
+			appCtx.EXPECT().Logger().Return(log.Logger)
+
+			ctx := mocks.NewRequestContextMock(t)
+			ctx.EXPECT().Context().Return(t.Context())
+
+			handler, err := newStatusCodeResponseHandler(appCtx, uc, tc.config)
+			require.NoError(t, err)
+
+			resp := &heimdall.Response{StatusCode: tc.status, Header: http.Header{}}
+
+			// WHEN
+			err = handler.Execute(ctx, nil, resp)
+
+			// THEN
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, resp.StatusCode)
+		})
+	}
+}
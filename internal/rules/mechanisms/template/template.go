@@ -21,11 +21,14 @@ import (
 	"crypto/sha256"
 	"errors"
 	"fmt"
+	htmltemplate "html/template"
+	"io"
 	"net/url"
 	"reflect"
 	"text/template"
 
 	"github.com/Masterminds/sprig/v3"
+	"github.com/go-ldap/ldap/v3"
 
 	"github.com/dadrus/heimdall/internal/heimdall"
 	"github.com/dadrus/heimdall/internal/x/errorchain"
@@ -44,32 +47,54 @@ type templateImpl struct { //nolint:recvcheck
 	// recvcheck disabled by intention, as otherwise validations, which require Stringer implementation,
 	// but receive a value (not a pointer) do not work
 
-	t    *template.Template
+	t    executor
 	orig string
 	hash []byte
 }
 
+type executor interface {
+	Execute(wr io.Writer, data any) error
+}
+
 func New(val string) (Template, error) {
-	funcMap := sprig.TxtFuncMap()
-	delete(funcMap, "env")
-	delete(funcMap, "expandenv")
-
-	tmpl, err := template.New("Heimdall").
-		Funcs(funcMap).
-		Funcs(template.FuncMap{
-			"urlenc":  urlEncode,
-			"atIndex": atIndex,
-		}).
-		Parse(val)
+	tmpl, err := template.New("Heimdall").Funcs(funcMap()).Parse(val)
+	if err != nil {
+		return nil, errorchain.NewWithMessage(heimdall.ErrConfiguration, "failed to parse template").
+			CausedBy(err)
+	}
+
+	return newTemplate(tmpl, val), nil
+}
+
+// NewHTML creates a template, which escapes all rendered values contextually, so the result
+// can be safely used as HTML document.
+func NewHTML(val string) (Template, error) {
+	tmpl, err := htmltemplate.New("Heimdall").Funcs(funcMap()).Parse(val)
 	if err != nil {
 		return nil, errorchain.NewWithMessage(heimdall.ErrConfiguration, "failed to parse template").
 			CausedBy(err)
 	}
 
+	return newTemplate(tmpl, val), nil
+}
+
+func newTemplate(tmpl executor, val string) *templateImpl {
 	hash := sha256.New()
 	hash.Write(stringx.ToBytes(val))
 
-	return &templateImpl{t: tmpl, orig: val, hash: hash.Sum(nil)}, nil
+	return &templateImpl{t: tmpl, orig: val, hash: hash.Sum(nil)}
+}
+
+func funcMap() template.FuncMap {
+	funcs := sprig.TxtFuncMap()
+	delete(funcs, "env")
+	delete(funcs, "expandenv")
+
+	funcs["urlenc"] = urlEncode
+	funcs["ldapenc"] = ldapEncode
+	funcs["atIndex"] = atIndex
+
+	return funcs
 }
 
 func (t *templateImpl) Render(values map[string]any) (string, error) {
@@ -98,6 +123,18 @@ func urlEncode(value any) string {
 	}
 }
 
+// ldapEncode escapes the given value to be safely used in LDAP search filters (RFC 4515).
+func ldapEncode(value any) string {
+	switch t := value.(type) {
+	case string:
+		return ldap.EscapeFilter(t)
+	case fmt.Stringer:
+		return ldap.EscapeFilter(t.String())
+	default:
+		return ""
+	}
+}
+
 func atIndex(pos int, list interface{}) (interface{}, error) {
 	tp := reflect.TypeOf(list).Kind()
 	switch tp {
@@ -17,6 +17,7 @@
 package template_test
 
 import (
+	"fmt"
 	"net/http"
 	"net/url"
 	"testing"
@@ -37,6 +38,7 @@ func TestTemplateRender(t *testing.T) {
 	reqf := mocks.NewRequestFunctionsMock(t)
 	reqf.EXPECT().Header("X-My-Header").Return("my-value")
 	reqf.EXPECT().Cookie("session_cookie").Return("session-value")
+	reqf.EXPECT().Body().Return(map[string]any{"query": "query GetUser { user { id } }"})
 
 	ctx := mocks.NewRequestContextMock(t)
 	ctx.EXPECT().Request().Return(&heimdall.Request{
@@ -68,6 +70,7 @@ func TestTemplateRender(t *testing.T) {
 "my_cookie": {{ .Request.Cookie "session_cookie" | quote }},
 "my_query_param": {{ index .Request.URL.Query.my_query_param 0 | quote }},
 "ips": {{ range $i, $el := .Request.ClientIPAddresses -}}{{ if $i }} {{ end }}{{ quote $el }}{{ end }},
+"graphql_operation": {{ quote .Request.GraphQL.Name }},
 "values": [{{ quote .Values.key1 }}, {{ quote .Values.key2 }}]
 }`)
 	require.NoError(t, err)
@@ -93,10 +96,62 @@ func TestTemplateRender(t *testing.T) {
 "my_cookie": "session-value",
 "my_query_param": "query_value",
 "ips": "192.168.1.1",
+"graphql_operation": "GetUser",
 "values": ["foo", "bar"]
 }`, res)
 }
 
+func TestHTMLTemplateRender(t *testing.T) {
+	t.Parallel()
+
+	for uc, tc := range map[string]struct {
+		template string
+		values   map[string]any
+		expected string
+	}{
+		"values are escaped": {
+			template: `<p>Not allowed: {{ .Request.URL.Path }}</p>`,
+			values: map[string]any{
+				"Request": &heimdall.Request{URL: &heimdall.URL{URL: url.URL{Path: "/<script>alert(1)</script>"}}},
+			},
+			expected: `<p>Not allowed: /&lt;script&gt;alert(1)&lt;/script&gt;</p>`,
+		},
+		"values are escaped according to their context": {
+			template: `<a href="/login?return_to={{ .Location }}">Login</a>`,
+			values:   map[string]any{"Location": `" onclick="alert(1)`},
+			expected: `<a href="/login?return_to=%22%20onclick%3d%22alert%281%29">Login</a>`,
+		},
+		"template functions are available": {
+			template: `<p>{{ upper .Name | urlenc }}</p>`,
+			values:   map[string]any{"Name": "foo bar"},
+			expected: `<p>FOO&#43;BAR</p>`,
+		},
+	} {
+		t.Run(uc, func(t *testing.T) {
+			// GIVEN
+			tpl, err := template.NewHTML(tc.template)
+			require.NoError(t, err)
+
+			// WHEN
+			res, err := tpl.Render(tc.values)
+
+			// THEN
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, res)
+			assert.Equal(t, tc.template, tpl.String())
+		})
+	}
+}
+
+func TestNewHTMLTemplateWithMalformedTemplate(t *testing.T) {
+	t.Parallel()
+
+	_, err := template.NewHTML("{{ .Foo ")
+
+	require.Error(t, err)
+	require.ErrorIs(t, err, heimdall.ErrConfiguration)
+}
+
 func TestAtIndex(t *testing.T) {
 	t.Parallel()
 
@@ -136,3 +191,26 @@ func TestAtIndex(t *testing.T) {
 		})
 	}
 }
+
+func TestLDAPEncode(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		val any
+		res string
+	}{
+		{val: "alice", res: "alice"},
+		{val: "*)(uid=*", res: `\2a\29\28uid=\2a`},
+		{val: `foo\bar`, res: `foo\5cbar`},
+		{val: 1, res: ""},
+	} {
+		t.Run(fmt.Sprintf("%v", tc.val), func(t *testing.T) {
+			tmpl, err := template.New("{{ ldapenc .Value }}")
+			require.NoError(t, err)
+
+			res, err := tmpl.Render(map[string]any{"Value": tc.val})
+			require.NoError(t, err)
+			assert.Equal(t, tc.res, res)
+		})
+	}
+}
@@ -79,6 +79,64 @@ func (_c *ExecutionConditionMock_CanExecuteOnError_Call) RunAndReturn(run func(h
 	return _c
 }
 
+// CanExecuteOnResponse provides a mock function with given fields: ctx, sub, resp
+func (_m *ExecutionConditionMock) CanExecuteOnResponse(ctx heimdall.RequestContext, sub *subject.Subject, resp *heimdall.Response) (bool, error) {
+	ret := _m.Called(ctx, sub, resp)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CanExecuteOnResponse")
+	}
+
+	var r0 bool
+	var r1 error
+	if rf, ok := ret.Get(0).(func(heimdall.RequestContext, *subject.Subject, *heimdall.Response) (bool, error)); ok {
+		return rf(ctx, sub, resp)
+	}
+	if rf, ok := ret.Get(0).(func(heimdall.RequestContext, *subject.Subject, *heimdall.Response) bool); ok {
+		r0 = rf(ctx, sub, resp)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	if rf, ok := ret.Get(1).(func(heimdall.RequestContext, *subject.Subject, *heimdall.Response) error); ok {
+		r1 = rf(ctx, sub, resp)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ExecutionConditionMock_CanExecuteOnResponse_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'CanExecuteOnResponse'
+type ExecutionConditionMock_CanExecuteOnResponse_Call struct {
+	*mock.Call
+}
+
+// CanExecuteOnResponse is a helper method to define mock.On call
+//   - ctx heimdall.RequestContext
+//   - sub *subject.Subject
+//   - resp *heimdall.Response
+func (_e *ExecutionConditionMock_Expecter) CanExecuteOnResponse(ctx interface{}, sub interface{}, resp interface{}) *ExecutionConditionMock_CanExecuteOnResponse_Call {
+	return &ExecutionConditionMock_CanExecuteOnResponse_Call{Call: _e.mock.On("CanExecuteOnResponse", ctx, sub, resp)}
+}
+
+func (_c *ExecutionConditionMock_CanExecuteOnResponse_Call) Run(run func(ctx heimdall.RequestContext, sub *subject.Subject, resp *heimdall.Response)) *ExecutionConditionMock_CanExecuteOnResponse_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(heimdall.RequestContext), args[1].(*subject.Subject), args[2].(*heimdall.Response))
+	})
+	return _c
+}
+
+func (_c *ExecutionConditionMock_CanExecuteOnResponse_Call) Return(_a0 bool, _a1 error) *ExecutionConditionMock_CanExecuteOnResponse_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *ExecutionConditionMock_CanExecuteOnResponse_Call) RunAndReturn(run func(heimdall.RequestContext, *subject.Subject, *heimdall.Response) (bool, error)) *ExecutionConditionMock_CanExecuteOnResponse_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // CanExecuteOnSubject provides a mock function with given fields: ctx, sub
 func (_m *ExecutionConditionMock) CanExecuteOnSubject(ctx heimdall.RequestContext, sub *subject.Subject) (bool, error) {
 	ret := _m.Called(ctx, sub)
@@ -0,0 +1,333 @@
+// Copyright 2025 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package tokenexchange
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/goccy/go-json"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dadrus/heimdall/internal/cache"
+	"github.com/dadrus/heimdall/internal/cache/mocks"
+	"github.com/dadrus/heimdall/internal/heimdall"
+	"github.com/dadrus/heimdall/internal/rules/oauth2/clientcredentials"
+)
+
+func TestTokenExchange(t *testing.T) {
+	t.Parallel()
+
+	type (
+		RequestAsserter func(t *testing.T, req *http.Request)
+		ResponseBuilder func(t *testing.T) (any, int)
+	)
+
+	var (
+		endpointCalled bool
+		assertRequest  RequestAsserter
+		buildResponse  ResponseBuilder
+	)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		endpointCalled = true
+
+		if req.Method != http.MethodPost {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+
+			return
+		}
+
+		if err := req.ParseForm(); err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+
+			return
+		}
+
+		assertRequest(t, req)
+
+		resp, code := buildResponse(t)
+
+		rawResp, err := json.MarshalContext(req.Context(), resp)
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Content-Length", strconv.Itoa(len(rawResp)))
+
+		w.WriteHeader(code)
+		_, err = w.Write(rawResp)
+		assert.NoError(t, err)
+	}))
+	defer srv.Close()
+
+	ttl := 1 * time.Minute
+	disabled := time.Duration(0)
+
+	for uc, tc := range map[string]struct {
+		cfg            *Config
+		req            *Request
+		configureMocks func(t *testing.T, cch *mocks.CacheMock)
+		assertRequest  RequestAsserter
+		buildResponse  ResponseBuilder
+		assert         func(t *testing.T, err error, tokenEndpointCalled bool, token *clientcredentials.TokenInfo)
+	}{
+		"reusing response from cache": {
+			cfg: &Config{},
+			req: &Request{SubjectToken: "foo", SubjectTokenType: TokenTypeAccessToken},
+			configureMocks: func(t *testing.T, cch *mocks.CacheMock) {
+				t.Helper()
+
+				rawData, err := json.Marshal(&clientcredentials.TokenInfo{TokenType: "Bearer", AccessToken: "foobar"})
+				require.NoError(t, err)
+
+				cch.EXPECT().Get(mock.Anything, mock.Anything).Return(rawData, nil)
+			},
+			assert: func(t *testing.T, err error, tokenEndpointCalled bool, token *clientcredentials.TokenInfo) {
+				t.Helper()
+
+				require.NoError(t, err)
+				assert.False(t, tokenEndpointCalled)
+				assert.Equal(t, "Bearer", token.TokenType)
+				assert.Equal(t, "foobar", token.AccessToken)
+			},
+		},
+		"minimal request with expires_in in the response": {
+			cfg: &Config{TokenURL: srv.URL, ClientID: "bar", ClientSecret: "foo"},
+			req: &Request{SubjectToken: "foo", SubjectTokenType: TokenTypeAccessToken},
+			configureMocks: func(t *testing.T, cch *mocks.CacheMock) {
+				t.Helper()
+
+				cch.EXPECT().Get(mock.Anything, mock.Anything).Return(nil, errors.New("no cache entry"))
+				cch.EXPECT().Set(mock.Anything, mock.Anything, mock.Anything,
+					mock.MatchedBy(func(ttl time.Duration) bool {
+						return ttl.Round(time.Second) == 5*time.Minute-5*time.Second
+					}),
+				).Return(nil)
+			},
+			assertRequest: func(t *testing.T, req *http.Request) {
+				t.Helper()
+
+				clientID, clientSecret, ok := req.BasicAuth()
+				require.True(t, ok)
+				assert.Equal(t, "bar", clientID)
+				assert.Equal(t, "foo", clientSecret)
+
+				assert.Equal(t, "application/x-www-form-urlencoded", req.Header.Get("Content-Type"))
+				assert.Equal(t, GrantType, req.PostForm.Get("grant_type"))
+				assert.Equal(t, "foo", req.PostForm.Get("subject_token"))
+				assert.Equal(t, TokenTypeAccessToken, req.PostForm.Get("subject_token_type"))
+				assert.NotContains(t, req.PostForm, "requested_token_type")
+				assert.NotContains(t, req.PostForm, "audience")
+				assert.NotContains(t, req.PostForm, "resource")
+				assert.NotContains(t, req.PostForm, "scope")
+			},
+			buildResponse: func(t *testing.T) (any, int) {
+				t.Helper()
+
+				return map[string]any{
+					"access_token":      "barfoo",
+					"issued_token_type": TokenTypeAccessToken,
+					"token_type":        "bearer",
+					"expires_in":        300,
+				}, http.StatusOK
+			},
+			assert: func(t *testing.T, err error, tokenEndpointCalled bool, token *clientcredentials.TokenInfo) {
+				t.Helper()
+
+				require.NoError(t, err)
+				assert.True(t, tokenEndpointCalled)
+				assert.Equal(t, "Bearer", token.TokenType)
+				assert.Equal(t, "barfoo", token.AccessToken)
+			},
+		},
+		"full request with request body authentication and configured ttl": {
+			cfg: &Config{
+				TokenURL:     srv.URL,
+				ClientID:     "bar",
+				ClientSecret: "foo",
+				AuthMethod:   clientcredentials.AuthMethodRequestBody,
+				TTL:          &ttl,
+			},
+			req: &Request{
+				SubjectToken:       "foo",
+				SubjectTokenType:   TokenTypeJWT,
+				RequestedTokenType: TokenTypeAccessToken,
+				Audiences:          []string{"aud1", "aud2"},
+				Resources:          []string{"https://api.example.com"},
+				Scopes:             []string{"read", "write"},
+			},
+			configureMocks: func(t *testing.T, cch *mocks.CacheMock) {
+				t.Helper()
+
+				cch.EXPECT().Get(mock.Anything, mock.Anything).Return(nil, errors.New("no cache entry"))
+				cch.EXPECT().Set(mock.Anything, mock.Anything, mock.Anything, ttl).Return(nil)
+			},
+			assertRequest: func(t *testing.T, req *http.Request) {
+				t.Helper()
+
+				_, _, ok := req.BasicAuth()
+				assert.False(t, ok)
+				assert.Equal(t, "bar", req.PostForm.Get("client_id"))
+				assert.Equal(t, "foo", req.PostForm.Get("client_secret"))
+				assert.Equal(t, GrantType, req.PostForm.Get("grant_type"))
+				assert.Equal(t, TokenTypeJWT, req.PostForm.Get("subject_token_type"))
+				assert.Equal(t, TokenTypeAccessToken, req.PostForm.Get("requested_token_type"))
+				assert.Equal(t, []string{"aud1", "aud2"}, req.PostForm["audience"])
+				assert.Equal(t, []string{"https://api.example.com"}, req.PostForm["resource"])
+				assert.Equal(t, "read write", req.PostForm.Get("scope"))
+			},
+			buildResponse: func(t *testing.T) (any, int) {
+				t.Helper()
+
+				return map[string]any{"access_token": "barfoo", "token_type": "N_A"}, http.StatusOK
+			},
+			assert: func(t *testing.T, err error, tokenEndpointCalled bool, token *clientcredentials.TokenInfo) {
+				t.Helper()
+
+				require.NoError(t, err)
+				assert.True(t, tokenEndpointCalled)
+				assert.Equal(t, "N_A", token.TokenType)
+				assert.Equal(t, "barfoo", token.AccessToken)
+			},
+		},
+		"cache disabled": {
+			cfg: &Config{TokenURL: srv.URL, ClientID: "bar", ClientSecret: "foo", TTL: &disabled},
+			req: &Request{SubjectToken: "foo", SubjectTokenType: TokenTypeAccessToken},
+			assertRequest: func(t *testing.T, _ *http.Request) {
+				t.Helper()
+			},
+			buildResponse: func(t *testing.T) (any, int) {
+				t.Helper()
+
+				return map[string]any{"access_token": "barfoo", "token_type": "Bearer", "expires_in": 300}, http.StatusOK
+			},
+			assert: func(t *testing.T, err error, tokenEndpointCalled bool, token *clientcredentials.TokenInfo) {
+				t.Helper()
+
+				require.NoError(t, err)
+				assert.True(t, tokenEndpointCalled)
+				assert.Equal(t, "barfoo", token.AccessToken)
+			},
+		},
+		"error response from the token endpoint": {
+			cfg: &Config{TokenURL: srv.URL, ClientID: "bar", ClientSecret: "foo"},
+			req: &Request{SubjectToken: "foo", SubjectTokenType: TokenTypeAccessToken},
+			configureMocks: func(t *testing.T, cch *mocks.CacheMock) {
+				t.Helper()
+
+				cch.EXPECT().Get(mock.Anything, mock.Anything).Return(nil, errors.New("no cache entry"))
+			},
+			assertRequest: func(t *testing.T, _ *http.Request) {
+				t.Helper()
+			},
+			buildResponse: func(t *testing.T) (any, int) {
+				t.Helper()
+
+				return map[string]any{
+					"error":             "invalid_target",
+					"error_description": "audience not allowed",
+				}, http.StatusBadRequest
+			},
+			assert: func(t *testing.T, err error, tokenEndpointCalled bool, _ *clientcredentials.TokenInfo) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrCommunication)
+				assert.Contains(t, err.Error(), "invalid_target")
+				assert.Contains(t, err.Error(), "audience not allowed")
+				assert.True(t, tokenEndpointCalled)
+			},
+		},
+		"unexpected response code from the token endpoint": {
+			cfg: &Config{TokenURL: srv.URL, ClientID: "bar", ClientSecret: "foo"},
+			req: &Request{SubjectToken: "foo", SubjectTokenType: TokenTypeAccessToken},
+			configureMocks: func(t *testing.T, cch *mocks.CacheMock) {
+				t.Helper()
+
+				cch.EXPECT().Get(mock.Anything, mock.Anything).Return(nil, errors.New("no cache entry"))
+			},
+			assertRequest: func(t *testing.T, _ *http.Request) {
+				t.Helper()
+			},
+			buildResponse: func(t *testing.T) (any, int) {
+				t.Helper()
+
+				return map[string]any{}, http.StatusUnauthorized
+			},
+			assert: func(t *testing.T, err error, tokenEndpointCalled bool, _ *clientcredentials.TokenInfo) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrCommunication)
+				assert.Contains(t, err.Error(), "unexpected response code")
+				assert.True(t, tokenEndpointCalled)
+			},
+		},
+	} {
+		t.Run(uc, func(t *testing.T) {
+			// GIVEN
+			endpointCalled = false
+			assertRequest = tc.assertRequest
+			buildResponse = tc.buildResponse
+
+			cch := mocks.NewCacheMock(t)
+			if tc.configureMocks != nil {
+				tc.configureMocks(t, cch)
+			}
+
+			ctx := cache.WithContext(t.Context(), cch)
+
+			// WHEN
+			token, err := tc.cfg.Exchange(ctx, tc.req)
+
+			// THEN
+			tc.assert(t, err, endpointCalled, token)
+		})
+	}
+}
+
+func TestTokenExchangeCacheKey(t *testing.T) {
+	t.Parallel()
+
+	cfg := &Config{TokenURL: "https://foo.bar", ClientID: "foo", ClientSecret: "bar"}
+
+	key1 := cfg.calculateCacheKey(&Request{SubjectToken: "foo", Audiences: []string{"bar"}})
+	key2 := cfg.calculateCacheKey(&Request{SubjectToken: "foo", Audiences: []string{"baz"}})
+	key3 := cfg.calculateCacheKey(&Request{SubjectToken: "bar", Audiences: []string{"bar"}})
+	key4 := cfg.calculateCacheKey(&Request{SubjectToken: "foo", Audiences: []string{"bar"}})
+	key5 := cfg.calculateCacheKey(&Request{SubjectToken: "foo", Audiences: []string{"ba", "r"}})
+	key6 := cfg.calculateCacheKey(&Request{SubjectToken: "foo", Audiences: []string{"bar"}, Scopes: []string{""}})
+	key7 := (&Config{TokenURL: "https://foo.bar", ClientID: "foo", ClientSecret: "baz"}).
+		calculateCacheKey(&Request{SubjectToken: "foo", Audiences: []string{"bar"}})
+
+	assert.NotEqual(t, key1, key2)
+	assert.NotEqual(t, key1, key3)
+	assert.Equal(t, key1, key4)
+	assert.NotEqual(t, key1, key5)
+	assert.NotEqual(t, key1, key6)
+	assert.NotEqual(t, key1, key7)
+}
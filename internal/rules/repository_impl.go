@@ -153,6 +153,8 @@ func (r *repository) UpdateRuleSet(_ context.Context, srcID string, rules []rule
 	r.index = tmp
 	r.rulesTreeMutex.Unlock()
 
+	releaseRules(toBeDeleted)
+
 	return nil
 }
 
@@ -178,9 +180,21 @@ func (r *repository) DeleteRuleSet(_ context.Context, srcID string) error {
 	r.index = tmp
 	r.rulesTreeMutex.Unlock()
 
+	releaseRules(applicable)
+
 	return nil
 }
 
+func releaseRules(rules []rule.Rule) {
+	type releaser interface{ release() }
+
+	for _, rul := range rules {
+		if rel, ok := rul.(releaser); ok {
+			rel.release()
+		}
+	}
+}
+
 func (r *repository) addRulesTo(tree *radixtree.Tree[rule.Route], rules []rule.Rule) error {
 	for _, rul := range rules {
 		for _, route := range rul.Routes() {
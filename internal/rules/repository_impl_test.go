@@ -102,16 +102,16 @@ func TestRepositoryRemoveRuleSet(t *testing.T) {
 	// GIVEN
 	repo := newRepository(&ruleFactory{}).(*repository) //nolint: forcetypeassert
 
-	rule1 := &ruleImpl{id: "1", srcID: "1"}
+	rule1 := &ruleImpl{id: "1", srcID: "1", released: make(chan struct{})}
 	rule1.routes = append(rule1.routes, &routeImpl{rule: rule1, path: "/foo/1"})
 
-	rule2 := &ruleImpl{id: "2", srcID: "1"}
+	rule2 := &ruleImpl{id: "2", srcID: "1", released: make(chan struct{})}
 	rule2.routes = append(rule2.routes, &routeImpl{rule: rule2, path: "/foo/2"})
 
-	rule3 := &ruleImpl{id: "3", srcID: "1"}
+	rule3 := &ruleImpl{id: "3", srcID: "1", released: make(chan struct{})}
 	rule3.routes = append(rule3.routes, &routeImpl{rule: rule3, path: "/foo/4"})
 
-	rule4 := &ruleImpl{id: "4", srcID: "1"}
+	rule4 := &ruleImpl{id: "4", srcID: "1", released: make(chan struct{})}
 	rule4.routes = append(rule4.routes, &routeImpl{rule: rule4, path: "/foo/4"})
 
 	rules := []rule.Rule{rule1, rule2, rule3, rule4}
@@ -127,6 +127,10 @@ func TestRepositoryRemoveRuleSet(t *testing.T) {
 	require.NoError(t, err)
 	assert.Empty(t, repo.knownRules)
 	assert.True(t, repo.index.Empty())
+
+	for _, rul := range rules {
+		assert.True(t, isReleased(rul))
+	}
 }
 
 func TestRepositoryRemoveRulesFromDifferentRuleSets(t *testing.T) {
@@ -215,17 +219,17 @@ func TestRepositoryUpdateRuleSet(t *testing.T) {
 	// GIVEN
 	repo := newRepository(&ruleFactory{}).(*repository) //nolint: forcetypeassert
 
-	rule1 := &ruleImpl{id: "1", srcID: "1", hash: []byte{1}}
+	rule1 := &ruleImpl{id: "1", srcID: "1", hash: []byte{1}, released: make(chan struct{})}
 	rule1.routes = append(rule1.routes, &routeImpl{rule: rule1, path: "/bar/1"})
 	rule1.routes = append(rule1.routes, &routeImpl{rule: rule1, path: "/bar/1a"})
 
-	rule2 := &ruleImpl{id: "2", srcID: "1", hash: []byte{1}}
+	rule2 := &ruleImpl{id: "2", srcID: "1", hash: []byte{1}, released: make(chan struct{})}
 	rule2.routes = append(rule2.routes, &routeImpl{rule: rule2, path: "/bar/2"})
 
-	rule3 := &ruleImpl{id: "3", srcID: "1", hash: []byte{1}}
+	rule3 := &ruleImpl{id: "3", srcID: "1", hash: []byte{1}, released: make(chan struct{})}
 	rule3.routes = append(rule3.routes, &routeImpl{rule: rule3, path: "/bar/3"})
 
-	rule4 := &ruleImpl{id: "4", srcID: "1", hash: []byte{1}}
+	rule4 := &ruleImpl{id: "4", srcID: "1", hash: []byte{1}, released: make(chan struct{})}
 	rule4.routes = append(rule4.routes, &routeImpl{rule: rule4, path: "/bar/4"})
 
 	initialRules := []rule.Rule{rule1, rule2, rule3, rule4}
@@ -233,12 +237,12 @@ func TestRepositoryUpdateRuleSet(t *testing.T) {
 	require.NoError(t, repo.AddRuleSet(t.Context(), "1", initialRules))
 
 	// rule 1 changed: /bar/1a gone, /bar/1b added
-	rule1 = &ruleImpl{id: "1", srcID: "1", hash: []byte{2}}
+	rule1 = &ruleImpl{id: "1", srcID: "1", hash: []byte{2}, released: make(chan struct{})}
 	rule1.routes = append(rule1.routes, &routeImpl{rule: rule1, path: "/bar/1"})
 	rule1.routes = append(rule1.routes, &routeImpl{rule: rule1, path: "/bar/1b"})
 	// rule with id 2 is deleted
 	// rule 3 changed: /bar/2 gone, /foo/3 and /foo/4 added
-	rule3 = &ruleImpl{id: "3", srcID: "1", hash: []byte{2}}
+	rule3 = &ruleImpl{id: "3", srcID: "1", hash: []byte{2}, released: make(chan struct{})}
 	rule3.routes = append(rule3.routes, &routeImpl{rule: rule3, path: "/foo/3"})
 	rule3.routes = append(rule3.routes, &routeImpl{rule: rule3, path: "/foo/4"})
 	// rule 4 same as before
@@ -273,6 +277,23 @@ func TestRepositoryUpdateRuleSet(t *testing.T) {
 
 	_, err = repo.index.Find("/bar/4", radixtree.LookupMatcherFunc[rule.Route](func(_ rule.Route, _, _ []string) bool { return true }))
 	require.NoError(t, err)
+
+	// replaced and deleted rules are released
+	assert.True(t, isReleased(initialRules[0]))
+	assert.True(t, isReleased(initialRules[1]))
+	assert.True(t, isReleased(initialRules[2]))
+	assert.False(t, isReleased(initialRules[3]))
+	assert.False(t, isReleased(rule1))
+	assert.False(t, isReleased(rule3))
+}
+
+func isReleased(rul rule.Rule) bool {
+	select {
+	case <-rul.(*ruleImpl).released: //nolint: forcetypeassert
+		return true
+	default:
+		return false
+	}
 }
 
 func TestRepositoryFindRule(t *testing.T) {
@@ -0,0 +1,254 @@
+// Copyright 2025 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package rules
+
+import (
+	"mime"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/dadrus/heimdall/internal/heimdall"
+	"github.com/dadrus/heimdall/internal/rules/config"
+	"github.com/dadrus/heimdall/internal/x/errorchain"
+)
+
+// requestConstraint is evaluated before the execute pipeline of a rule and is expected to
+// rely on request metadata only. That way, there is no need to read the request body. The
+// only exception is the bodySizeConstraint if the size of the body is not known upfront.
+type requestConstraint interface {
+	verify(request *heimdall.Request) error
+}
+
+type requestConstraints []requestConstraint
+
+func (c requestConstraints) verify(request *heimdall.Request) error {
+	for _, constraint := range c {
+		if err := constraint.verify(request); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// bodySizer is implemented by the request functions, which have access to the actual
+// request body.
+type bodySizer interface {
+	BodySize(limit int64) (int64, error)
+}
+
+type bodySizeConstraint int64
+
+func (c bodySizeConstraint) verify(request *heimdall.Request) error {
+	size, present, err := contentLength(request)
+	if err != nil {
+		return err
+	}
+
+	if present {
+		if size > int64(c) {
+			return errorchain.NewWithMessagef(heimdall.ErrArgument,
+				"request body size of %d bytes exceeds the allowed maximum of %d bytes", size, c)
+		}
+
+		return nil
+	}
+
+	sizer, ok := request.RequestFunctions.(bodySizer)
+	if !ok {
+		// the body is not available, so its size can only be assessed if the request
+		// does not have one
+		if len(request.Header("Transfer-Encoding")) != 0 {
+			return errorchain.NewWithMessage(heimdall.ErrArgument,
+				"request body size cannot be determined")
+		}
+
+		return nil
+	}
+
+	size, err = sizer.BodySize(int64(c))
+	if err != nil {
+		return errorchain.NewWithMessage(heimdall.ErrArgument, "failed reading request body").CausedBy(err)
+	}
+
+	if size > int64(c) {
+		return errorchain.NewWithMessagef(heimdall.ErrArgument,
+			"request body size exceeds the allowed maximum of %d bytes", c)
+	}
+
+	return nil
+}
+
+type contentTypeConstraint []string
+
+func (c contentTypeConstraint) verify(request *heimdall.Request) error {
+	value := request.Header("Content-Type")
+	if len(value) == 0 {
+		size, present, err := contentLength(request)
+		if err != nil {
+			return err
+		}
+
+		// a request without a body does not need to specify its content type
+		if !present || size == 0 {
+			return nil
+		}
+
+		return errorchain.NewWithMessage(heimdall.ErrArgument, "request has a body, but no Content-Type header")
+	}
+
+	mediaType, _, err := mime.ParseMediaType(value)
+	if err != nil {
+		return errorchain.NewWithMessagef(heimdall.ErrArgument, "malformed Content-Type header '%s'", value).
+			CausedBy(err)
+	}
+
+	wildcard := mediaType[:strings.Index(mediaType, "/")+1] + "*"
+	if !slices.Contains(c, mediaType) && !slices.Contains(c, wildcard) {
+		return errorchain.NewWithMessagef(heimdall.ErrArgument, "content type '%s' is not allowed", mediaType)
+	}
+
+	return nil
+}
+
+type requiredHeadersConstraint []string
+
+func (c requiredHeadersConstraint) verify(request *heimdall.Request) error {
+	for _, name := range c {
+		if len(request.Header(name)) == 0 {
+			return errorchain.NewWithMessagef(heimdall.ErrArgument, "required header '%s' is missing", name)
+		}
+	}
+
+	return nil
+}
+
+type queryParameterConstraint struct {
+	name     string
+	required bool
+	matcher  typedMatcher
+}
+
+func (c *queryParameterConstraint) verify(request *heimdall.Request) error {
+	values, present := request.URL.Query()[c.name]
+	if !present {
+		if c.required {
+			return errorchain.NewWithMessagef(heimdall.ErrArgument,
+				"required query parameter '%s' is missing", c.name)
+		}
+
+		return nil
+	}
+
+	if c.matcher == nil {
+		return nil
+	}
+
+	for _, value := range values {
+		if !c.matcher.match(value) {
+			return errorchain.NewWithMessagef(heimdall.ErrArgument,
+				"value '%s' of query parameter '%s' is not allowed", value, c.name)
+		}
+	}
+
+	return nil
+}
+
+func contentLength(request *heimdall.Request) (int64, bool, error) {
+	value := request.Header("Content-Length")
+	if len(value) == 0 {
+		return 0, false, nil
+	}
+
+	size, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || size < 0 {
+		return 0, false, errorchain.NewWithMessagef(heimdall.ErrArgument,
+			"malformed Content-Length header '%s'", value)
+	}
+
+	return size, true, nil
+}
+
+func createRequestConstraints(conf *config.Constraints) (requestConstraints, error) {
+	if conf == nil {
+		return nil, nil
+	}
+
+	var constraints requestConstraints
+
+	if conf.MaxBodySize != 0 {
+		//nolint:gosec
+		// no integer overflow during conversion possible for any reasonable body size
+		constraints = append(constraints, bodySizeConstraint(conf.MaxBodySize))
+	}
+
+	if len(conf.ContentTypes) != 0 {
+		contentTypes := make(contentTypeConstraint, len(conf.ContentTypes))
+
+		for idx, contentType := range conf.ContentTypes {
+			if !strings.Contains(contentType, "/") {
+				return nil, errorchain.NewWithMessagef(heimdall.ErrConfiguration,
+					"invalid content type '%s' at index %d", contentType, idx)
+			}
+
+			contentTypes[idx] = strings.ToLower(contentType)
+		}
+
+		constraints = append(constraints, contentTypes)
+	}
+
+	if len(conf.RequiredHeaders) != 0 {
+		constraints = append(constraints, requiredHeadersConstraint(conf.RequiredHeaders))
+	}
+
+	for idx, param := range conf.QueryParams {
+		var (
+			tm  typedMatcher
+			err error
+		)
+
+		switch param.Type {
+		case "":
+			if len(param.Value) != 0 {
+				tm = newExactMatcher(param.Value)
+			}
+		case "exact":
+			tm = newExactMatcher(param.Value)
+		case "glob":
+			tm, err = newGlobMatcher(param.Value, '/')
+		case "regex":
+			tm, err = newRegexMatcher(param.Value)
+		default:
+			return nil, errorchain.NewWithMessagef(heimdall.ErrConfiguration,
+				"unsupported query parameter expression type '%s' for parameter '%s' at index %d",
+				param.Type, param.Name, idx)
+		}
+
+		if err != nil {
+			return nil, errorchain.NewWithMessagef(heimdall.ErrConfiguration,
+				"failed to compile query parameter expression for parameter '%s' at index %d",
+				param.Name, idx).
+				CausedBy(err)
+		}
+
+		constraints = append(constraints,
+			&queryParameterConstraint{name: param.Name, required: param.Required, matcher: tm})
+	}
+
+	return constraints, nil
+}
@@ -0,0 +1,281 @@
+// Copyright 2025 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package rules
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dadrus/heimdall/internal/handler/requestcontext"
+	"github.com/dadrus/heimdall/internal/heimdall"
+	"github.com/dadrus/heimdall/internal/heimdall/mocks"
+	"github.com/dadrus/heimdall/internal/rules/config"
+)
+
+func TestCreateRequestConstraints(t *testing.T) {
+	t.Parallel()
+
+	for uc, tc := range map[string]struct {
+		conf   *config.Constraints
+		assert func(t *testing.T, err error, constraints requestConstraints)
+	}{
+		"no constraints configured": {
+			assert: func(t *testing.T, err error, constraints requestConstraints) {
+				t.Helper()
+
+				require.NoError(t, err)
+				assert.Empty(t, constraints)
+			},
+		},
+		"invalid content type": {
+			conf: &config.Constraints{ContentTypes: []string{"application/json", "json"}},
+			assert: func(t *testing.T, err error, _ requestConstraints) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrConfiguration)
+				require.ErrorContains(t, err, "invalid content type 'json' at index 1")
+			},
+		},
+		"unsupported query parameter expression type": {
+			conf: &config.Constraints{QueryParams: []config.QueryParameterConstraint{
+				{Name: "foo", Type: "bar", Value: "baz"},
+			}},
+			assert: func(t *testing.T, err error, _ requestConstraints) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrConfiguration)
+				require.ErrorContains(t, err, "unsupported query parameter expression type 'bar'")
+			},
+		},
+		"malformed query parameter regular expression": {
+			conf: &config.Constraints{QueryParams: []config.QueryParameterConstraint{
+				{Name: "foo", Type: "regex", Value: "(bar"},
+			}},
+			assert: func(t *testing.T, err error, _ requestConstraints) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrConfiguration)
+				require.ErrorContains(t, err, "failed to compile query parameter expression")
+			},
+		},
+		"all constraints configured": {
+			conf: &config.Constraints{
+				MaxBodySize:     1024,
+				ContentTypes:    []string{"Application/JSON"},
+				RequiredHeaders: []string{"X-Foo"},
+				QueryParams: []config.QueryParameterConstraint{
+					{Name: "foo", Required: true},
+					{Name: "bar", Value: "baz"},
+					{Name: "baz", Type: "glob", Value: "*.json"},
+				},
+			},
+			assert: func(t *testing.T, err error, constraints requestConstraints) {
+				t.Helper()
+
+				require.NoError(t, err)
+				require.Len(t, constraints, 6)
+				assert.Equal(t, bodySizeConstraint(1024), constraints[0])
+				assert.Equal(t, contentTypeConstraint{"application/json"}, constraints[1])
+				assert.Equal(t, requiredHeadersConstraint{"X-Foo"}, constraints[2])
+
+				qpc := constraints[3].(*queryParameterConstraint) // nolint: forcetypeassert
+				assert.Equal(t, "foo", qpc.name)
+				assert.True(t, qpc.required)
+				assert.Nil(t, qpc.matcher)
+
+				qpc = constraints[4].(*queryParameterConstraint) // nolint: forcetypeassert
+				assert.IsType(t, &exactMatcher{}, qpc.matcher)
+				assert.False(t, qpc.required)
+
+				qpc = constraints[5].(*queryParameterConstraint) // nolint: forcetypeassert
+				assert.IsType(t, &globMatcher{}, qpc.matcher)
+			},
+		},
+	} {
+		t.Run(uc, func(t *testing.T) {
+			// WHEN
+			constraints, err := createRequestConstraints(tc.conf)
+
+			// THEN
+			tc.assert(t, err, constraints)
+		})
+	}
+}
+
+func TestRequestConstraintsVerify(t *testing.T) {
+	t.Parallel()
+
+	constraints, err := createRequestConstraints(&config.Constraints{
+		MaxBodySize:     10,
+		ContentTypes:    []string{"application/json", "text/*"},
+		RequiredHeaders: []string{"X-Foo"},
+		QueryParams: []config.QueryParameterConstraint{
+			{Name: "page", Required: true, Type: "regex", Value: "^[0-9]+$"},
+			{Name: "sort", Type: "exact", Value: "asc"},
+		},
+	})
+	require.NoError(t, err)
+
+	for uc, tc := range map[string]struct {
+		headers map[string]string
+		query   string
+		err     string
+	}{
+		"all constraints satisfied": {
+			headers: map[string]string{"X-Foo": "bar", "Content-Type": "application/json", "Content-Length": "10"},
+			query:   "page=1&sort=asc",
+		},
+		"all constraints satisfied without a body": {
+			headers: map[string]string{"X-Foo": "bar"},
+			query:   "page=1",
+		},
+		"content type matched by a wildcard": {
+			headers: map[string]string{"X-Foo": "bar", "Content-Type": "text/plain; charset=utf-8", "Content-Length": "2"},
+			query:   "page=1",
+		},
+		"body too large": {
+			headers: map[string]string{"X-Foo": "bar", "Content-Type": "application/json", "Content-Length": "11"},
+			query:   "page=1",
+			err:     "request body size of 11 bytes exceeds the allowed maximum of 10 bytes",
+		},
+		"chunked body of unknown size": {
+			headers: map[string]string{"X-Foo": "bar", "Content-Type": "application/json", "Transfer-Encoding": "chunked"},
+			query:   "page=1",
+			err:     "request body size cannot be determined",
+		},
+		"malformed content length": {
+			headers: map[string]string{"X-Foo": "bar", "Content-Length": "foo"},
+			query:   "page=1",
+			err:     "malformed Content-Length header 'foo'",
+		},
+		"content type not allowed": {
+			headers: map[string]string{"X-Foo": "bar", "Content-Type": "application/xml", "Content-Length": "2"},
+			query:   "page=1",
+			err:     "content type 'application/xml' is not allowed",
+		},
+		"malformed content type": {
+			headers: map[string]string{"X-Foo": "bar", "Content-Type": "application/", "Content-Length": "2"},
+			query:   "page=1",
+			err:     "malformed Content-Type header 'application/'",
+		},
+		"body without content type": {
+			headers: map[string]string{"X-Foo": "bar", "Content-Length": "2"},
+			query:   "page=1",
+			err:     "request has a body, but no Content-Type header",
+		},
+		"required header missing": {
+			query: "page=1",
+			err:   "required header 'X-Foo' is missing",
+		},
+		"required query parameter missing": {
+			headers: map[string]string{"X-Foo": "bar"},
+			query:   "sort=asc",
+			err:     "required query parameter 'page' is missing",
+		},
+		"query parameter value not allowed": {
+			headers: map[string]string{"X-Foo": "bar"},
+			query:   "page=1&page=foo",
+			err:     "value 'foo' of query parameter 'page' is not allowed",
+		},
+		"optional query parameter value not allowed": {
+			headers: map[string]string{"X-Foo": "bar"},
+			query:   "page=1&sort=desc",
+			err:     "value 'desc' of query parameter 'sort' is not allowed",
+		},
+	} {
+		t.Run(uc, func(t *testing.T) {
+			// GIVEN
+			reqf := mocks.NewRequestFunctionsMock(t)
+			reqf.EXPECT().Header(mock.Anything).RunAndReturn(func(name string) string {
+				return tc.headers[name]
+			}).Maybe()
+
+			req := &heimdall.Request{
+				RequestFunctions: reqf,
+				URL:              &heimdall.URL{URL: url.URL{Path: "/foo", RawQuery: tc.query}},
+			}
+
+			// WHEN
+			err := constraints.verify(req)
+
+			// THEN
+			if len(tc.err) == 0 {
+				require.NoError(t, err)
+
+				return
+			}
+
+			require.Error(t, err)
+			require.ErrorIs(t, err, heimdall.ErrArgument)
+			require.ErrorContains(t, err, tc.err)
+		})
+	}
+}
+
+func TestBodySizeConstraintVerifyWithChunkedBody(t *testing.T) {
+	t.Parallel()
+
+	for uc, tc := range map[string]struct {
+		body string
+		err  string
+	}{
+		"body within the limit": {body: "0123456789"},
+		"body exceeding the limit": {
+			body: "0123456789a",
+			err:  "request body size exceeds the allowed maximum of 10 bytes",
+		},
+	} {
+		t.Run(uc, func(t *testing.T) {
+			// GIVEN
+			req := httptest.NewRequest(http.MethodPost, "/foo", io.NopCloser(strings.NewReader(tc.body)))
+			req.ContentLength = -1
+			req.TransferEncoding = []string{"chunked"}
+
+			ctx := requestcontext.New(req)
+
+			// WHEN
+			err := bodySizeConstraint(10).verify(ctx.Request())
+
+			// THEN
+			if len(tc.err) != 0 {
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrArgument)
+				require.ErrorContains(t, err, tc.err)
+
+				return
+			}
+
+			require.NoError(t, err)
+
+			// the body is still available in full
+			data, err := io.ReadAll(req.Body)
+			require.NoError(t, err)
+			assert.Equal(t, tc.body, string(data))
+		})
+	}
+}
@@ -30,10 +30,11 @@ import (
 )
 
 var (
-	ErrRequestSchemeMismatch = errors.New("request scheme mismatch")
-	ErrRequestMethodMismatch = errors.New("request method mismatch")
-	ErrRequestHostMismatch   = errors.New("request host mismatch")
-	ErrRequestPathMismatch   = errors.New("request path mismatch")
+	ErrRequestSchemeMismatch  = errors.New("request scheme mismatch")
+	ErrRequestMethodMismatch  = errors.New("request method mismatch")
+	ErrRequestHostMismatch    = errors.New("request host mismatch")
+	ErrRequestPathMismatch    = errors.New("request path mismatch")
+	ErrRequestGraphQLMismatch = errors.New("request graphql operation mismatch")
 )
 
 type RouteMatcher interface {
@@ -140,6 +141,63 @@ func (m *pathParamMatcher) Matches(request *heimdall.Request, keys, values []str
 	return nil
 }
 
+// graphQLMatcher matches GraphQL requests, with all operations satisfying the configured
+// conditions. To fail closed, requests, which cannot be interpreted, or batches with only some
+// operations satisfying the conditions, are matched as well, but rejected by the verify
+// method, which is used as a request constraint of the rule.
+type graphQLMatcher struct {
+	types []string
+	names []string
+}
+
+func (m *graphQLMatcher) Matches(request *heimdall.Request, _, _ []string) error {
+	ops, err := request.GraphQLOperations()
+	if err != nil {
+		return nil
+	}
+
+	if len(ops) == 0 {
+		return errorchain.NewWithMessage(ErrRequestGraphQLMismatch, "request is not a graphql request")
+	}
+
+	if !slices.ContainsFunc(ops, m.matches) {
+		return errorchain.NewWithMessagef(ErrRequestGraphQLMismatch,
+			"%s operation '%s' is not expected", ops[0].Type, ops[0].Name)
+	}
+
+	return nil
+}
+
+func (m *graphQLMatcher) verify(request *heimdall.Request) error {
+	ops, err := request.GraphQLOperations()
+	if err != nil {
+		return errorchain.NewWithMessage(heimdall.ErrArgument, "failed to interpret graphql request").
+			CausedBy(err)
+	}
+
+	for _, op := range ops {
+		if !m.matches(op) {
+			return errorchain.NewWithMessagef(heimdall.ErrArgument,
+				"%s operation '%s' is not allowed by the rule", op.Type, op.Name)
+		}
+	}
+
+	return nil
+}
+
+func (m *graphQLMatcher) matches(op heimdall.GraphQLOperation) bool {
+	return (len(m.types) == 0 || slices.Contains(m.types, op.Type)) &&
+		(len(m.names) == 0 || slices.Contains(m.names, op.Name))
+}
+
+func createGraphQLMatcher(conf *config.GraphQLMatcher) *graphQLMatcher {
+	if conf == nil {
+		return nil
+	}
+
+	return &graphQLMatcher{types: conf.OperationTypes, names: conf.OperationNames}
+}
+
 func createMethodMatcher(methods []string) (methodMatcher, error) {
 	if len(methods) == 0 {
 		return methodMatcher{}, nil
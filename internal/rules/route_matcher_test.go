@@ -25,6 +25,7 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/dadrus/heimdall/internal/heimdall"
+	"github.com/dadrus/heimdall/internal/heimdall/mocks"
 	"github.com/dadrus/heimdall/internal/rules/config"
 )
 
@@ -354,6 +355,139 @@ func TestMethodMatcherMatches(t *testing.T) {
 	}
 }
 
+func TestCreateGraphQLMatcher(t *testing.T) {
+	t.Parallel()
+
+	for uc, tc := range map[string]struct {
+		conf   *config.GraphQLMatcher
+		assert func(t *testing.T, matcher *graphQLMatcher)
+	}{
+		"no graphql constraints": {
+			assert: func(t *testing.T, matcher *graphQLMatcher) {
+				t.Helper()
+
+				assert.Nil(t, matcher)
+			},
+		},
+		"with graphql constraints": {
+			conf: &config.GraphQLMatcher{
+				OperationTypes: []string{"query"},
+				OperationNames: []string{"GetUser"},
+			},
+			assert: func(t *testing.T, matcher *graphQLMatcher) {
+				t.Helper()
+
+				require.NotNil(t, matcher)
+				assert.Equal(t, []string{"query"}, matcher.types)
+				assert.Equal(t, []string{"GetUser"}, matcher.names)
+			},
+		},
+	} {
+		t.Run(uc, func(t *testing.T) {
+			tc.assert(t, createGraphQLMatcher(tc.conf))
+		})
+	}
+}
+
+func TestGraphQLMatcherMatchesAndVerify(t *testing.T) {
+	t.Parallel()
+
+	for uc, tc := range map[string]struct {
+		matcher   *graphQLMatcher
+		ct        string
+		body      any
+		matches   bool
+		verifyErr string
+	}{
+		"not a graphql request": {
+			matcher: &graphQLMatcher{},
+			body:    map[string]any{"foo": "bar"},
+		},
+		"matches any graphql operation": {
+			matcher: &graphQLMatcher{},
+			body:    map[string]any{"query": "{ user { id } }"},
+			matches: true,
+		},
+		"matches operation type and name": {
+			matcher: &graphQLMatcher{types: []string{"query", "subscription"}, names: []string{"GetUser"}},
+			body:    map[string]any{"query": "query GetUser { user { id } }"},
+			matches: true,
+		},
+		"operation type does not match": {
+			matcher: &graphQLMatcher{types: []string{"query"}},
+			body:    map[string]any{"query": "mutation DeleteUser { deleteUser(id: 1) { id } }"},
+		},
+		"operation name does not match": {
+			matcher: &graphQLMatcher{names: []string{"GetUser"}},
+			body: map[string]any{
+				"query":         "query GetUser { user { id } } query ListUsers { users { id } }",
+				"operationName": "ListUsers",
+			},
+		},
+		"batch with all operations matching": {
+			matcher: &graphQLMatcher{types: []string{"query"}},
+			ct:      "application/json",
+			body:    `[{"query": "query A { a }"}, {"query": "{ b }"}]`,
+			matches: true,
+		},
+		"batch with no operation matching": {
+			matcher: &graphQLMatcher{types: []string{"query"}},
+			ct:      "application/json",
+			body:    `[{"query": "mutation A { a }"}, {"query": "mutation B { b }"}]`,
+		},
+		"batch with some operations matching is matched, but rejected": {
+			matcher:   &graphQLMatcher{types: []string{"query"}},
+			ct:        "application/json",
+			body:      `[{"query": "query A { a }"}, {"query": "mutation B { b }"}]`,
+			matches:   true,
+			verifyErr: "mutation operation 'B' is not allowed by the rule",
+		},
+		"multiple operations without operation name are matched, but rejected": {
+			matcher:   &graphQLMatcher{types: []string{"query"}},
+			body:      map[string]any{"query": "query A { a } mutation B { b }"},
+			matches:   true,
+			verifyErr: "failed to interpret graphql request",
+		},
+		"malformed json body is matched, but rejected": {
+			matcher:   &graphQLMatcher{types: []string{"mutation"}},
+			ct:        "application/json",
+			body:      `{"query": "mutation { a }"`,
+			matches:   true,
+			verifyErr: "failed to interpret graphql request",
+		},
+	} {
+		t.Run(uc, func(t *testing.T) {
+			reqf := mocks.NewRequestFunctionsMock(t)
+			reqf.EXPECT().Body().Return(tc.body).Once()
+			reqf.EXPECT().Header("Content-Type").Return(tc.ct).Maybe()
+
+			req := &heimdall.Request{RequestFunctions: reqf, Method: http.MethodPost}
+
+			err := tc.matcher.Matches(req, nil, nil)
+
+			if !tc.matches {
+				require.Error(t, err)
+				require.ErrorIs(t, err, ErrRequestGraphQLMismatch)
+
+				return
+			}
+
+			require.NoError(t, err)
+
+			err = tc.matcher.verify(req)
+			if len(tc.verifyErr) == 0 {
+				require.NoError(t, err)
+
+				return
+			}
+
+			require.Error(t, err)
+			require.ErrorIs(t, err, heimdall.ErrArgument)
+			require.ErrorContains(t, err, tc.verifyErr)
+		})
+	}
+}
+
 func TestHostMatcherMatches(t *testing.T) {
 	t.Parallel()
 
@@ -17,7 +17,11 @@
 package rule
 
 import (
+	"crypto/tls"
 	"net/url"
+
+	"github.com/dadrus/heimdall/internal/heimdall"
+	"github.com/dadrus/heimdall/internal/rules/config"
 )
 
 //go:generate mockery --name Backend --structname BackendMock
@@ -25,4 +29,35 @@ import (
 type Backend interface {
 	URL() *url.URL
 	ForwardHostHeader() bool
+	// HandleResponse executes the response pipeline of the rule on the response of the upstream
+	// service. It is used in proxy mode only.
+	HandleResponse(resp *heimdall.Response) error
+	// Upgrade returns the settings for connections upgraded to another protocol, like WebSocket,
+	// or nil if these are not configured. In the latter case, any upgrade accepted by the upstream
+	// service is allowed and the upgraded connection is not supervised. It is used in proxy mode only.
+	Upgrade() *config.Upgrade
+	// Revalidate executes the authenticators and authorizers of the rule again on the given request
+	// context to verify the subject of a long living upgraded connection. Contextualizers, finalizers
+	// and the error pipeline are not executed. It is used in proxy mode only.
+	Revalidate(ctx heimdall.RequestContext) error
+	// Targets returns the instances of the upstream service the request can be forwarded to, or nil
+	// if only a single host is configured. It is used in proxy mode only.
+	Targets() []config.Target
+	// LoadBalancing returns the settings controlling the selection of a target, or nil if these are
+	// not configured. It is used in proxy mode only.
+	LoadBalancing() *config.LoadBalancing
+	// HashKey returns the rendered key used by the consistent_hash load balancing strategy, or an
+	// empty string if no key is configured. It is used in proxy mode only.
+	HashKey() (string, error)
+	// Transport returns the settings for the communication with the upstream service, or nil if
+	// the defaults shall be used. It is used in proxy mode only.
+	Transport() *config.Transport
+	// TLSConfig returns the TLS configuration for the communication with the upstream service,
+	// created from the tls settings of the transport while loading the rule, or nil if these are
+	// not configured. It is used in proxy mode only.
+	TLSConfig() *tls.Config
+	// Released returns a channel, which is closed as soon as the rule has been removed from the
+	// repository, e.g. because it has been updated or deleted. State bound to the rule, like the
+	// health checks of its targets, shall be released then. It is used in proxy mode only.
+	Released() <-chan struct{}
 }
@@ -3,8 +3,14 @@
 package mocks
 
 import (
+	config "github.com/dadrus/heimdall/internal/rules/config"
+
+	heimdall "github.com/dadrus/heimdall/internal/heimdall"
+
 	mock "github.com/stretchr/testify/mock"
 
+	tls "crypto/tls"
+
 	url "net/url"
 )
 
@@ -66,6 +72,388 @@ func (_c *BackendMock_ForwardHostHeader_Call) RunAndReturn(run func() bool) *Bac
 	return _c
 }
 
+// HandleResponse provides a mock function with given fields: resp
+func (_m *BackendMock) HandleResponse(resp *heimdall.Response) error {
+	ret := _m.Called(resp)
+
+	if len(ret) == 0 {
+		panic("no return value specified for HandleResponse")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(*heimdall.Response) error); ok {
+		r0 = rf(resp)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// BackendMock_HandleResponse_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'HandleResponse'
+type BackendMock_HandleResponse_Call struct {
+	*mock.Call
+}
+
+// HandleResponse is a helper method to define mock.On call
+//   - resp *heimdall.Response
+func (_e *BackendMock_Expecter) HandleResponse(resp interface{}) *BackendMock_HandleResponse_Call {
+	return &BackendMock_HandleResponse_Call{Call: _e.mock.On("HandleResponse", resp)}
+}
+
+func (_c *BackendMock_HandleResponse_Call) Run(run func(resp *heimdall.Response)) *BackendMock_HandleResponse_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(*heimdall.Response))
+	})
+	return _c
+}
+
+func (_c *BackendMock_HandleResponse_Call) Return(_a0 error) *BackendMock_HandleResponse_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *BackendMock_HandleResponse_Call) RunAndReturn(run func(*heimdall.Response) error) *BackendMock_HandleResponse_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// HashKey provides a mock function with no fields
+func (_m *BackendMock) HashKey() (string, error) {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for HashKey")
+	}
+
+	var r0 string
+	var r1 error
+	if rf, ok := ret.Get(0).(func() (string, error)); ok {
+		return rf()
+	}
+	if rf, ok := ret.Get(0).(func() string); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+
+	if rf, ok := ret.Get(1).(func() error); ok {
+		r1 = rf()
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// BackendMock_HashKey_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'HashKey'
+type BackendMock_HashKey_Call struct {
+	*mock.Call
+}
+
+// HashKey is a helper method to define mock.On call
+func (_e *BackendMock_Expecter) HashKey() *BackendMock_HashKey_Call {
+	return &BackendMock_HashKey_Call{Call: _e.mock.On("HashKey")}
+}
+
+func (_c *BackendMock_HashKey_Call) Run(run func()) *BackendMock_HashKey_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run()
+	})
+	return _c
+}
+
+func (_c *BackendMock_HashKey_Call) Return(_a0 string, _a1 error) *BackendMock_HashKey_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *BackendMock_HashKey_Call) RunAndReturn(run func() (string, error)) *BackendMock_HashKey_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// LoadBalancing provides a mock function with no fields
+func (_m *BackendMock) LoadBalancing() *config.LoadBalancing {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for LoadBalancing")
+	}
+
+	var r0 *config.LoadBalancing
+	if rf, ok := ret.Get(0).(func() *config.LoadBalancing); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*config.LoadBalancing)
+		}
+	}
+
+	return r0
+}
+
+// BackendMock_LoadBalancing_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'LoadBalancing'
+type BackendMock_LoadBalancing_Call struct {
+	*mock.Call
+}
+
+// LoadBalancing is a helper method to define mock.On call
+func (_e *BackendMock_Expecter) LoadBalancing() *BackendMock_LoadBalancing_Call {
+	return &BackendMock_LoadBalancing_Call{Call: _e.mock.On("LoadBalancing")}
+}
+
+func (_c *BackendMock_LoadBalancing_Call) Run(run func()) *BackendMock_LoadBalancing_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run()
+	})
+	return _c
+}
+
+func (_c *BackendMock_LoadBalancing_Call) Return(_a0 *config.LoadBalancing) *BackendMock_LoadBalancing_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *BackendMock_LoadBalancing_Call) RunAndReturn(run func() *config.LoadBalancing) *BackendMock_LoadBalancing_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Released provides a mock function with no fields
+func (_m *BackendMock) Released() <-chan struct{} {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for Released")
+	}
+
+	var r0 <-chan struct{}
+	if rf, ok := ret.Get(0).(func() <-chan struct{}); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(<-chan struct{})
+		}
+	}
+
+	return r0
+}
+
+// BackendMock_Released_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Released'
+type BackendMock_Released_Call struct {
+	*mock.Call
+}
+
+// Released is a helper method to define mock.On call
+func (_e *BackendMock_Expecter) Released() *BackendMock_Released_Call {
+	return &BackendMock_Released_Call{Call: _e.mock.On("Released")}
+}
+
+func (_c *BackendMock_Released_Call) Run(run func()) *BackendMock_Released_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run()
+	})
+	return _c
+}
+
+func (_c *BackendMock_Released_Call) Return(_a0 <-chan struct{}) *BackendMock_Released_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *BackendMock_Released_Call) RunAndReturn(run func() <-chan struct{}) *BackendMock_Released_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Revalidate provides a mock function with given fields: ctx
+func (_m *BackendMock) Revalidate(ctx heimdall.RequestContext) error {
+	ret := _m.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Revalidate")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(heimdall.RequestContext) error); ok {
+		r0 = rf(ctx)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// BackendMock_Revalidate_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Revalidate'
+type BackendMock_Revalidate_Call struct {
+	*mock.Call
+}
+
+// Revalidate is a helper method to define mock.On call
+//   - ctx heimdall.RequestContext
+func (_e *BackendMock_Expecter) Revalidate(ctx interface{}) *BackendMock_Revalidate_Call {
+	return &BackendMock_Revalidate_Call{Call: _e.mock.On("Revalidate", ctx)}
+}
+
+func (_c *BackendMock_Revalidate_Call) Run(run func(ctx heimdall.RequestContext)) *BackendMock_Revalidate_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(heimdall.RequestContext))
+	})
+	return _c
+}
+
+func (_c *BackendMock_Revalidate_Call) Return(_a0 error) *BackendMock_Revalidate_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *BackendMock_Revalidate_Call) RunAndReturn(run func(heimdall.RequestContext) error) *BackendMock_Revalidate_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// TLSConfig provides a mock function with no fields
+func (_m *BackendMock) TLSConfig() *tls.Config {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for TLSConfig")
+	}
+
+	var r0 *tls.Config
+	if rf, ok := ret.Get(0).(func() *tls.Config); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*tls.Config)
+		}
+	}
+
+	return r0
+}
+
+// BackendMock_TLSConfig_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'TLSConfig'
+type BackendMock_TLSConfig_Call struct {
+	*mock.Call
+}
+
+// TLSConfig is a helper method to define mock.On call
+func (_e *BackendMock_Expecter) TLSConfig() *BackendMock_TLSConfig_Call {
+	return &BackendMock_TLSConfig_Call{Call: _e.mock.On("TLSConfig")}
+}
+
+func (_c *BackendMock_TLSConfig_Call) Run(run func()) *BackendMock_TLSConfig_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run()
+	})
+	return _c
+}
+
+func (_c *BackendMock_TLSConfig_Call) Return(_a0 *tls.Config) *BackendMock_TLSConfig_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *BackendMock_TLSConfig_Call) RunAndReturn(run func() *tls.Config) *BackendMock_TLSConfig_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Targets provides a mock function with no fields
+func (_m *BackendMock) Targets() []config.Target {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for Targets")
+	}
+
+	var r0 []config.Target
+	if rf, ok := ret.Get(0).(func() []config.Target); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]config.Target)
+		}
+	}
+
+	return r0
+}
+
+// BackendMock_Targets_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Targets'
+type BackendMock_Targets_Call struct {
+	*mock.Call
+}
+
+// Targets is a helper method to define mock.On call
+func (_e *BackendMock_Expecter) Targets() *BackendMock_Targets_Call {
+	return &BackendMock_Targets_Call{Call: _e.mock.On("Targets")}
+}
+
+func (_c *BackendMock_Targets_Call) Run(run func()) *BackendMock_Targets_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run()
+	})
+	return _c
+}
+
+func (_c *BackendMock_Targets_Call) Return(_a0 []config.Target) *BackendMock_Targets_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *BackendMock_Targets_Call) RunAndReturn(run func() []config.Target) *BackendMock_Targets_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Transport provides a mock function with no fields
+func (_m *BackendMock) Transport() *config.Transport {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for Transport")
+	}
+
+	var r0 *config.Transport
+	if rf, ok := ret.Get(0).(func() *config.Transport); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*config.Transport)
+		}
+	}
+
+	return r0
+}
+
+// BackendMock_Transport_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Transport'
+type BackendMock_Transport_Call struct {
+	*mock.Call
+}
+
+// Transport is a helper method to define mock.On call
+func (_e *BackendMock_Expecter) Transport() *BackendMock_Transport_Call {
+	return &BackendMock_Transport_Call{Call: _e.mock.On("Transport")}
+}
+
+func (_c *BackendMock_Transport_Call) Run(run func()) *BackendMock_Transport_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run()
+	})
+	return _c
+}
+
+func (_c *BackendMock_Transport_Call) Return(_a0 *config.Transport) *BackendMock_Transport_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *BackendMock_Transport_Call) RunAndReturn(run func() *config.Transport) *BackendMock_Transport_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // URL provides a mock function with no fields
 func (_m *BackendMock) URL() *url.URL {
 	ret := _m.Called()
@@ -113,6 +501,53 @@ func (_c *BackendMock_URL_Call) RunAndReturn(run func() *url.URL) *BackendMock_U
 	return _c
 }
 
+// Upgrade provides a mock function with no fields
+func (_m *BackendMock) Upgrade() *config.Upgrade {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for Upgrade")
+	}
+
+	var r0 *config.Upgrade
+	if rf, ok := ret.Get(0).(func() *config.Upgrade); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*config.Upgrade)
+		}
+	}
+
+	return r0
+}
+
+// BackendMock_Upgrade_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Upgrade'
+type BackendMock_Upgrade_Call struct {
+	*mock.Call
+}
+
+// Upgrade is a helper method to define mock.On call
+func (_e *BackendMock_Expecter) Upgrade() *BackendMock_Upgrade_Call {
+	return &BackendMock_Upgrade_Call{Call: _e.mock.On("Upgrade")}
+}
+
+func (_c *BackendMock_Upgrade_Call) Run(run func()) *BackendMock_Upgrade_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run()
+	})
+	return _c
+}
+
+func (_c *BackendMock_Upgrade_Call) Return(_a0 *config.Upgrade) *BackendMock_Upgrade_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *BackendMock_Upgrade_Call) RunAndReturn(run func() *config.Upgrade) *BackendMock_Upgrade_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // NewBackendMock creates a new instance of BackendMock. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
 // The first argument is typically a *testing.T value.
 func NewBackendMock(t interface {
@@ -19,13 +19,16 @@ package rules
 import (
 	"errors"
 	"fmt"
+	"slices"
 
 	"github.com/rs/zerolog"
+	"go.opentelemetry.io/otel"
 
 	"github.com/dadrus/heimdall/internal/config"
 	"github.com/dadrus/heimdall/internal/heimdall"
 	config2 "github.com/dadrus/heimdall/internal/rules/config"
 	"github.com/dadrus/heimdall/internal/rules/mechanisms"
+	"github.com/dadrus/heimdall/internal/rules/mechanisms/template"
 	"github.com/dadrus/heimdall/internal/rules/rule"
 	"github.com/dadrus/heimdall/internal/x"
 	"github.com/dadrus/heimdall/internal/x/errorchain"
@@ -46,6 +49,8 @@ func NewRuleFactory(
 		secureDefaultRule: bool(sdr),
 		logger:            logger,
 		mode:              mode,
+		upstreamTLSPaths:  conf.Serve.UpstreamTLS.AllowedPaths,
+		shadowSlots:       make(chan struct{}, maxConcurrentShadowEvaluations),
 	}
 
 	if err := rf.initWithDefaultRule(conf.Default, logger); err != nil {
@@ -65,6 +70,9 @@ type ruleFactory struct {
 	secureDefaultRule   bool
 	mode                config.OperationMode
 	defaultBacktracking bool
+	upstreamTLSPaths    []string
+	// shared by the shadow pipelines of all rules to limit the number of concurrent evaluations
+	shadowSlots chan struct{}
 }
 
 func (f *ruleFactory) DefaultRule() rule.Rule { return f.defaultRule }
@@ -81,7 +89,7 @@ func (f *ruleFactory) CreateRule(version, srcID string, ruleConfig config2.Rule)
 		config2.EncodedSlashesOff,
 	)
 
-	authenticators, subHandlers, finalizers, err := f.createExecutePipeline(version, ruleConfig.Execute)
+	authenticators, subHandlers, authorizers, finalizers, err := f.createExecutePipeline(version, ruleConfig.Execute)
 	if err != nil {
 		return nil, err
 	}
@@ -91,10 +99,21 @@ func (f *ruleFactory) CreateRule(version, srcID string, ruleConfig config2.Rule)
 		return nil, err
 	}
 
+	responseHandlers, err := f.createOnResponsePipeline(version, ruleConfig.ResponseHandler)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(responseHandlers) != 0 && f.mode != config.ProxyMode {
+		f.logger.Warn().Str("_src", srcID).Str("_id", ruleConfig.ID).
+			Msg("Rule defines an on_response pipeline, which is used in proxy mode only")
+	}
+
 	var allowsBacktracking bool
 
 	if f.defaultRule != nil {
 		authenticators = x.IfThenElse(len(authenticators) != 0, authenticators, f.defaultRule.sc)
+		authorizers = x.IfThenElse(len(subHandlers) != 0, authorizers, f.defaultRule.az)
 		subHandlers = x.IfThenElse(len(subHandlers) != 0, subHandlers, f.defaultRule.sh)
 		finalizers = x.IfThenElse(len(finalizers) != 0, finalizers, f.defaultRule.fi)
 		errorHandlers = x.IfThenElse(len(errorHandlers) != 0, errorHandlers, f.defaultRule.eh)
@@ -107,6 +126,26 @@ func (f *ruleFactory) CreateRule(version, srcID string, ruleConfig config2.Rule)
 		return nil, errorchain.NewWithMessage(heimdall.ErrConfiguration, "no authenticator defined")
 	}
 
+	constraints, err := createRequestConstraints(ruleConfig.Constraints)
+	if err != nil {
+		return nil, err
+	}
+
+	hashKey, err := createHashKey(ruleConfig.Backend)
+	if err != nil {
+		return nil, err
+	}
+
+	shadow, err := f.createShadowPipeline(version, srcID, ruleConfig.ID, ruleConfig.Shadow)
+	if err != nil {
+		return nil, err
+	}
+
+	upstreamTLS, err := createUpstreamTLSConfig(ruleConfig.Backend, f.upstreamTLSPaths)
+	if err != nil {
+		return nil, err
+	}
+
 	hash, err := ruleConfig.Hash()
 	if err != nil {
 		return nil, err
@@ -118,11 +157,18 @@ func (f *ruleFactory) CreateRule(version, srcID string, ruleConfig config2.Rule)
 		slashesHandling:    slashesHandling,
 		allowsBacktracking: allowsBacktracking,
 		backend:            ruleConfig.Backend,
+		constraints:        constraints,
+		hashKey:            hashKey,
 		hash:               hash,
 		sc:                 authenticators,
 		sh:                 subHandlers,
+		az:                 authorizers,
 		fi:                 finalizers,
 		eh:                 errorHandlers,
+		rh:                 responseHandlers,
+		shadow:             shadow,
+		upstreamTLS:        upstreamTLS,
+		released:           make(chan struct{}),
 	}
 
 	mm, err := createMethodMatcher(ruleConfig.Matcher.Methods)
@@ -136,6 +182,14 @@ func (f *ruleFactory) CreateRule(version, srcID string, ruleConfig config2.Rule)
 	}
 
 	sm := schemeMatcher(ruleConfig.Matcher.Scheme)
+	matchers := andMatcher{sm, mm, hm}
+
+	if gm := createGraphQLMatcher(ruleConfig.Matcher.GraphQL); gm != nil {
+		// requests, which cannot be interpreted, are matched by the graphql matcher
+		// and rejected by it as a constraint of this rule
+		matchers = append(matchers, gm)
+		rul.constraints = append(rul.constraints, gm)
+	}
 
 	for _, rc := range ruleConfig.Matcher.Routes {
 		ppm, err := createPathParamsMatcher(rc.PathParams, slashesHandling)
@@ -149,21 +203,26 @@ func (f *ruleFactory) CreateRule(version, srcID string, ruleConfig config2.Rule)
 			&routeImpl{
 				rule:    rul,
 				path:    rc.Path,
-				matcher: andMatcher{sm, mm, hm, ppm},
+				matcher: append(slices.Clip(matchers), ppm),
 			})
 	}
 
 	return rul, nil
 }
 
+// createExecutePipeline creates the mechanisms of the given execute pipeline. Apart from the authenticators,
+// the subject handlers (authorizers and contextualizers in the defined order) and the finalizers, the
+// authorizers are returned separately, as only these are used to revalidate a subject.
+//
 //nolint:funlen,gocognit,cyclop
 func (f *ruleFactory) createExecutePipeline(
 	version string,
 	pipeline []config.MechanismConfig,
-) (compositeSubjectCreator, compositeSubjectHandler, compositeSubjectHandler, error) {
+) (compositeSubjectCreator, compositeSubjectHandler, compositeSubjectHandler, compositeSubjectHandler, error) {
 	var (
 		authenticators  compositeSubjectCreator
 		subjectHandlers compositeSubjectHandler
+		authorizers     compositeSubjectHandler
 		finalizers      compositeSubjectHandler
 	)
 
@@ -191,7 +250,7 @@ func (f *ruleFactory) createExecutePipeline(
 		id, found := pipelineStep["authenticator"]
 		if found {
 			if len(subjectHandlers) != 0 || len(finalizers) != 0 {
-				return nil, nil, nil, errorchain.NewWithMessage(heimdall.ErrConfiguration,
+				return nil, nil, nil, nil, errorchain.NewWithMessage(heimdall.ErrConfiguration,
 					"an authenticator is defined after some other non authenticator type")
 			}
 
@@ -201,7 +260,7 @@ func (f *ruleFactory) createExecutePipeline(
 				getConfig(pipelineStep["config"]),
 			)
 			if err != nil {
-				return nil, nil, nil, err
+				return nil, nil, nil, nil, err
 			}
 
 			authenticators = append(authenticators, authenticator)
@@ -212,9 +271,10 @@ func (f *ruleFactory) createExecutePipeline(
 		handler, err := createHandler(version, "authorizer", pipelineStep, authorizersCheck,
 			f.hf.CreateAuthorizer)
 		if err != nil && !errors.Is(err, errHandlerNotFound) {
-			return nil, nil, nil, err
+			return nil, nil, nil, nil, err
 		} else if handler != nil {
 			subjectHandlers = append(subjectHandlers, handler)
+			authorizers = append(authorizers, handler)
 
 			continue
 		}
@@ -222,7 +282,7 @@ func (f *ruleFactory) createExecutePipeline(
 		handler, err = createHandler(version, "contextualizer", pipelineStep, contextualizersCheck,
 			f.hf.CreateContextualizer)
 		if err != nil && !errors.Is(err, errHandlerNotFound) {
-			return nil, nil, nil, err
+			return nil, nil, nil, nil, err
 		} else if handler != nil {
 			subjectHandlers = append(subjectHandlers, handler)
 
@@ -232,18 +292,18 @@ func (f *ruleFactory) createExecutePipeline(
 		handler, err = createHandler(version, "finalizer", pipelineStep, finalizersCheck,
 			f.hf.CreateFinalizer)
 		if err != nil && !errors.Is(err, errHandlerNotFound) {
-			return nil, nil, nil, err
+			return nil, nil, nil, nil, err
 		} else if handler != nil {
 			finalizers = append(finalizers, handler)
 
 			continue
 		}
 
-		return nil, nil, nil, errorchain.NewWithMessage(heimdall.ErrConfiguration,
+		return nil, nil, nil, nil, errorchain.NewWithMessage(heimdall.ErrConfiguration,
 			"unsupported configuration in execute")
 	}
 
-	return authenticators, subjectHandlers, finalizers, nil
+	return authenticators, subjectHandlers, authorizers, finalizers, nil
 }
 
 func (f *ruleFactory) createOnErrorPipeline(
@@ -277,6 +337,64 @@ func (f *ruleFactory) createOnErrorPipeline(
 	return errorHandlers, nil
 }
 
+func (f *ruleFactory) createOnResponsePipeline(
+	version string,
+	rhConfigs []config.MechanismConfig,
+) (compositeResponseHandler, error) {
+	var responseHandlers compositeResponseHandler
+
+	for _, rhStep := range rhConfigs {
+		id, found := rhStep["response_handler"]
+		if !found {
+			return nil, errorchain.NewWithMessage(heimdall.ErrConfiguration,
+				"unsupported configuration in response handler")
+		}
+
+		condition, err := getExecutionCondition(rhStep["if"])
+		if err != nil {
+			return nil, err
+		}
+
+		handler, err := f.hf.CreateResponseHandler(version, id.(string), getConfig(rhStep["config"])) //nolint: forcetypeassert
+		if err != nil {
+			return nil, err
+		}
+
+		responseHandlers = append(responseHandlers, &conditionalResponseHandler{h: handler, c: condition})
+	}
+
+	return responseHandlers, nil
+}
+
+func (f *ruleFactory) createShadowPipeline(
+	version, srcID, ruleID string,
+	conf *config2.Shadow,
+) (*shadowPipeline, error) {
+	if conf == nil {
+		return nil, nil //nolint:nilnil
+	}
+
+	authenticators, subHandlers, _, finalizers, err := f.createExecutePipeline(version, conf.Execute)
+	if err != nil {
+		return nil, errorchain.NewWithMessage(heimdall.ErrConfiguration,
+			"failed creating shadow pipeline").CausedBy(err)
+	}
+
+	if len(finalizers) != 0 {
+		return nil, errorchain.NewWithMessage(heimdall.ErrConfiguration,
+			"shadow pipeline must not contain finalizers")
+	}
+
+	shadow, err := newShadowPipeline(ruleID, srcID, conf.Timeout, authenticators, subHandlers, f.shadowSlots,
+		otel.GetTracerProvider(), otel.GetMeterProvider())
+	if err != nil {
+		return nil, errorchain.NewWithMessage(heimdall.ErrInternal,
+			"failed creating shadow pipeline instrumentation").CausedBy(err)
+	}
+
+	return shadow, nil
+}
+
 func (f *ruleFactory) initWithDefaultRule(ruleConfig *config.DefaultRule, logger zerolog.Logger) error {
 	if ruleConfig == nil {
 		logger.Info().Msg("No default rule configured")
@@ -288,7 +406,7 @@ func (f *ruleFactory) initWithDefaultRule(ruleConfig *config.DefaultRule, logger
 
 	logger.Info().Msg("Loading default rule")
 
-	authenticators, subHandlers, finalizers, err := f.createExecutePipeline(
+	authenticators, subHandlers, authorizers, finalizers, err := f.createExecutePipeline(
 		config2.CurrentRuleSetVersion,
 		ruleConfig.Execute,
 	)
@@ -323,6 +441,7 @@ func (f *ruleFactory) initWithDefaultRule(ruleConfig *config.DefaultRule, logger
 		isDefault:       true,
 		sc:              authenticators,
 		sh:              subHandlers,
+		az:              authorizers,
 		fi:              finalizers,
 		eh:              errorHandlers,
 	}
@@ -397,3 +516,17 @@ func getExecutionCondition(conf any) (executionCondition, error) {
 
 	return newCelExecutionCondition(expression)
 }
+
+func createHashKey(backend *config2.Backend) (template.Template, error) {
+	if backend == nil || backend.LoadBalancing == nil || len(backend.LoadBalancing.HashKey) == 0 {
+		return nil, nil //nolint:nilnil
+	}
+
+	tpl, err := template.New(backend.LoadBalancing.HashKey)
+	if err != nil {
+		return nil, errorchain.NewWithMessage(heimdall.ErrConfiguration,
+			"failed to parse hash_key of the load balancing configuration").CausedBy(err)
+	}
+
+	return tpl, nil
+}
@@ -19,7 +19,9 @@ package rules
 import (
 	"errors"
 	"net/url"
+	"os"
 	"testing"
+	"time"
 
 	"github.com/rs/zerolog/log"
 	"github.com/stretchr/testify/assert"
@@ -35,6 +37,7 @@ import (
 	mocks6 "github.com/dadrus/heimdall/internal/rules/mechanisms/errorhandlers/mocks"
 	mocks7 "github.com/dadrus/heimdall/internal/rules/mechanisms/finalizers/mocks"
 	mocks3 "github.com/dadrus/heimdall/internal/rules/mechanisms/mocks"
+	mocks8 "github.com/dadrus/heimdall/internal/rules/mechanisms/responsehandlers/mocks"
 	"github.com/dadrus/heimdall/internal/rules/mocks"
 	"github.com/dadrus/heimdall/internal/x"
 )
@@ -390,6 +393,7 @@ func TestRuleFactoryNew(t *testing.T) {
 				assert.Equal(t, config2.EncodedSlashesOff, defRule.slashesHandling)
 				assert.Len(t, defRule.sc, 1)
 				assert.Len(t, defRule.sh, 2)
+				assert.Len(t, defRule.az, 1)
 				assert.Len(t, defRule.fi, 1)
 				assert.Len(t, defRule.eh, 2)
 			},
@@ -532,6 +536,58 @@ func TestRuleFactoryCreateRule(t *testing.T) {
 				require.ErrorContains(t, err, "failed to compile host matching expression")
 			},
 		},
+		"with error while creating request constraints": {
+			config: config2.Rule{
+				ID:      "foobar",
+				Matcher: config2.Matcher{Routes: []config2.Route{{Path: "/foo/bar"}}},
+				Constraints: &config2.Constraints{
+					QueryParams: []config2.QueryParameterConstraint{{Name: "foo", Type: "regex", Value: "?>?<*??"}},
+				},
+				Execute: []config.MechanismConfig{
+					{"authenticator": "foo"},
+				},
+			},
+			configureMocks: func(t *testing.T, mhf *mocks3.MechanismFactoryMock) {
+				t.Helper()
+
+				mhf.EXPECT().CreateAuthenticator("test", "foo", mock.Anything).Return(&mocks2.AuthenticatorMock{}, nil)
+			},
+			assert: func(t *testing.T, err error, _ *ruleImpl) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrConfiguration)
+				require.ErrorContains(t, err, "failed to compile query parameter expression")
+			},
+		},
+		"with error while creating load balancing hash key": {
+			config: config2.Rule{
+				ID:      "foobar",
+				Matcher: config2.Matcher{Routes: []config2.Route{{Path: "/foo/bar"}}},
+				Backend: &config2.Backend{
+					Targets: []config2.Target{{Host: "foo:8080"}, {Host: "bar:8080"}},
+					LoadBalancing: &config2.LoadBalancing{
+						Strategy: config2.LoadBalancingConsistentHash,
+						HashKey:  "{{ .Subject.ID ",
+					},
+				},
+				Execute: []config.MechanismConfig{
+					{"authenticator": "foo"},
+				},
+			},
+			configureMocks: func(t *testing.T, mhf *mocks3.MechanismFactoryMock) {
+				t.Helper()
+
+				mhf.EXPECT().CreateAuthenticator("test", "foo", mock.Anything).Return(&mocks2.AuthenticatorMock{}, nil)
+			},
+			assert: func(t *testing.T, err error, _ *ruleImpl) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrConfiguration)
+				require.ErrorContains(t, err, "failed to parse hash_key")
+			},
+		},
 		"with error while creating execute pipeline": {
 			config: config2.Rule{
 				ID:      "foobar",
@@ -570,6 +626,39 @@ func TestRuleFactoryCreateRule(t *testing.T) {
 				assert.ErrorContains(t, err, "test error")
 			},
 		},
+		"with unsupported step in on_response pipeline": {
+			config: config2.Rule{
+				ID:              "foobar",
+				Matcher:         config2.Matcher{Routes: []config2.Route{{Path: "/foo/bar"}}},
+				ResponseHandler: []config.MechanismConfig{{"finalizer": "foo"}},
+			},
+			assert: func(t *testing.T, err error, _ *ruleImpl) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrConfiguration)
+				assert.Contains(t, err.Error(), "unsupported configuration in response handler")
+			},
+		},
+		"with error while creating on_response pipeline": {
+			config: config2.Rule{
+				ID:              "foobar",
+				Matcher:         config2.Matcher{Routes: []config2.Route{{Path: "/foo/bar"}}},
+				ResponseHandler: []config.MechanismConfig{{"response_handler": "foo"}},
+			},
+			configureMocks: func(t *testing.T, mhf *mocks3.MechanismFactoryMock) {
+				t.Helper()
+
+				mhf.EXPECT().CreateResponseHandler("test", "foo", mock.Anything).
+					Return(nil, errors.New("test error"))
+			},
+			assert: func(t *testing.T, err error, _ *ruleImpl) {
+				t.Helper()
+
+				require.Error(t, err)
+				assert.ErrorContains(t, err, "test error")
+			},
+		},
 		"without default rule and without any execute configuration": {
 			config: config2.Rule{
 				ID:      "foobar",
@@ -658,6 +747,7 @@ func TestRuleFactoryCreateRule(t *testing.T) {
 			defaultRule: &ruleImpl{
 				sc: compositeSubjectCreator{&mocks.SubjectCreatorMock{}},
 				sh: compositeSubjectHandler{&mocks.SubjectHandlerMock{}},
+				az: compositeSubjectHandler{&mocks.SubjectHandlerMock{}},
 				fi: compositeSubjectHandler{&mocks.SubjectHandlerMock{}},
 				eh: compositeErrorHandler{&mocks.ErrorHandlerMock{}},
 			},
@@ -675,6 +765,7 @@ func TestRuleFactoryCreateRule(t *testing.T) {
 				assert.Equal(t, "/foo/bar", rul.Routes()[0].Path())
 				assert.Len(t, rul.sc, 1)
 				assert.Len(t, rul.sh, 1)
+				assert.Len(t, rul.az, 1)
 				assert.Len(t, rul.fi, 1)
 				assert.Len(t, rul.eh, 1)
 			},
@@ -697,6 +788,7 @@ func TestRuleFactoryCreateRule(t *testing.T) {
 					Scheme:              "https",
 					Methods:             []string{"BAR", "BAZ"},
 					Hosts:               []config2.HostMatcher{{Type: "glob", Value: "**.example.com"}},
+					GraphQL:             &config2.GraphQLMatcher{OperationTypes: []string{"query"}},
 				},
 				EncodedSlashesHandling: config2.EncodedSlashesOnNoDecode,
 				Execute: []config.MechanismConfig{
@@ -712,6 +804,7 @@ func TestRuleFactoryCreateRule(t *testing.T) {
 			defaultRule: &ruleImpl{
 				sc: compositeSubjectCreator{&mocks.SubjectCreatorMock{}},
 				sh: compositeSubjectHandler{&mocks.SubjectHandlerMock{}},
+				az: compositeSubjectHandler{&mocks.SubjectHandlerMock{}},
 				fi: compositeSubjectHandler{&mocks.SubjectHandlerMock{}},
 				eh: compositeErrorHandler{&mocks.ErrorHandlerMock{}},
 			},
@@ -745,6 +838,10 @@ func TestRuleFactoryCreateRule(t *testing.T) {
 				assert.Equal(t, rul, rul.Routes()[1].Rule())
 				assert.Equal(t, "/bar/:resource", rul.Routes()[1].Path())
 
+				// graphql matcher is used as a constraint as well
+				require.Len(t, rul.constraints, 1)
+				assert.IsType(t, &graphQLMatcher{}, rul.constraints[0])
+
 				// nil checks above mean the responses from the mockHandlerFactory are used
 				// and not the values from the default rule
 				require.Len(t, rul.sc, 1)
@@ -752,6 +849,8 @@ func TestRuleFactoryCreateRule(t *testing.T) {
 				require.Len(t, rul.sh, 2)
 				assert.NotNil(t, rul.sh[0])
 				assert.NotNil(t, rul.sh[1])
+				require.Len(t, rul.az, 1)
+				assert.Equal(t, rul.sh[1], rul.az[0])
 				require.Len(t, rul.fi, 1)
 				assert.NotNil(t, rul.fi[0])
 				require.Len(t, rul.eh, 1)
@@ -800,6 +899,7 @@ func TestRuleFactoryCreateRule(t *testing.T) {
 			defaultRule: &ruleImpl{
 				sc: compositeSubjectCreator{&mocks.SubjectCreatorMock{}},
 				sh: compositeSubjectHandler{&mocks.SubjectHandlerMock{}},
+				az: compositeSubjectHandler{&mocks.SubjectHandlerMock{}},
 				fi: compositeSubjectHandler{&mocks.SubjectHandlerMock{}},
 				eh: compositeErrorHandler{&mocks.ErrorHandlerMock{}},
 			},
@@ -846,6 +946,8 @@ func TestRuleFactoryCreateRule(t *testing.T) {
 				require.Len(t, rul.sh, 2)
 				assert.NotNil(t, rul.sh[0])
 				assert.NotNil(t, rul.sh[1])
+				require.Len(t, rul.az, 1)
+				assert.Equal(t, rul.sh[1], rul.az[0])
 				require.Len(t, rul.fi, 1)
 				assert.NotNil(t, rul.fi[0])
 				require.Len(t, rul.eh, 1)
@@ -1054,6 +1156,174 @@ func TestRuleFactoryCreateRule(t *testing.T) {
 				require.Len(t, rul.eh, 2)
 			},
 		},
+		"with conditional execution for response handler in proxy mode": {
+			opMode: config.ProxyMode,
+			config: config2.Rule{
+				ID:      "foobar",
+				Backend: &config2.Backend{Host: "foo.bar"},
+				Matcher: config2.Matcher{Routes: []config2.Route{{Path: "/foo/bar"}}},
+				Execute: []config.MechanismConfig{
+					{"authenticator": "foo"},
+				},
+				ResponseHandler: []config.MechanismConfig{
+					{"response_handler": "foo"},
+					{"response_handler": "bar", "if": "Response.StatusCode == 200", "config": map[string]any{"to": 204}},
+				},
+			},
+			configureMocks: func(t *testing.T, mhf *mocks3.MechanismFactoryMock) {
+				t.Helper()
+
+				mhf.EXPECT().CreateAuthenticator("test", "foo", mock.Anything).
+					Return(&mocks2.AuthenticatorMock{}, nil)
+				mhf.EXPECT().CreateResponseHandler("test", "foo", mock.Anything).
+					Return(&mocks8.ResponseHandlerMock{}, nil)
+				mhf.EXPECT().CreateResponseHandler("test", "bar", config.MechanismConfig{"to": 204}).
+					Return(&mocks8.ResponseHandlerMock{}, nil)
+			},
+			assert: func(t *testing.T, err error, rul *ruleImpl) {
+				t.Helper()
+
+				require.NoError(t, err)
+				require.NotNil(t, rul)
+
+				require.Len(t, rul.rh, 2)
+
+				rh, ok := rul.rh[0].(*conditionalResponseHandler)
+				require.True(t, ok)
+				assert.IsType(t, defaultExecutionCondition{}, rh.c)
+
+				rh, ok = rul.rh[1].(*conditionalResponseHandler)
+				require.True(t, ok)
+				assert.IsType(t, &celExecutionCondition{}, rh.c)
+			},
+		},
+		"with shadow pipeline": {
+			config: config2.Rule{
+				ID:      "foobar",
+				Matcher: config2.Matcher{Routes: []config2.Route{{Path: "/foo/bar"}}},
+				Execute: []config.MechanismConfig{
+					{"authenticator": "foo"},
+					{"authorizer": "bar"},
+				},
+				Shadow: &config2.Shadow{
+					Execute: []config.MechanismConfig{
+						{"authorizer": "baz"},
+					},
+					Timeout: 2 * time.Second,
+				},
+			},
+			configureMocks: func(t *testing.T, mhf *mocks3.MechanismFactoryMock) {
+				t.Helper()
+
+				mhf.EXPECT().CreateAuthenticator("test", "foo", mock.Anything).
+					Return(&mocks2.AuthenticatorMock{}, nil)
+				mhf.EXPECT().CreateAuthorizer("test", "bar", mock.Anything).
+					Return(&mocks4.AuthorizerMock{}, nil)
+				mhf.EXPECT().CreateAuthorizer("test", "baz", mock.Anything).
+					Return(&mocks4.AuthorizerMock{}, nil)
+			},
+			assert: func(t *testing.T, err error, rul *ruleImpl) {
+				t.Helper()
+
+				require.NoError(t, err)
+				require.NotNil(t, rul)
+
+				assert.Len(t, rul.sh, 1)
+				require.NotNil(t, rul.shadow)
+				assert.Equal(t, "foobar", rul.shadow.ruleID)
+				assert.Equal(t, "test", rul.shadow.srcID)
+				assert.Empty(t, rul.shadow.sc)
+				assert.Len(t, rul.shadow.sh, 1)
+				assert.Equal(t, 2*time.Second, rul.shadow.timeout)
+			},
+		},
+		"with finalizer in shadow pipeline": {
+			config: config2.Rule{
+				ID:      "foobar",
+				Matcher: config2.Matcher{Routes: []config2.Route{{Path: "/foo/bar"}}},
+				Execute: []config.MechanismConfig{
+					{"authenticator": "foo"},
+				},
+				Shadow: &config2.Shadow{
+					Execute: []config.MechanismConfig{
+						{"finalizer": "bar"},
+					},
+				},
+			},
+			configureMocks: func(t *testing.T, mhf *mocks3.MechanismFactoryMock) {
+				t.Helper()
+
+				mhf.EXPECT().CreateAuthenticator("test", "foo", mock.Anything).
+					Return(&mocks2.AuthenticatorMock{}, nil)
+				mhf.EXPECT().CreateFinalizer("test", "bar", mock.Anything).
+					Return(&mocks7.FinalizerMock{}, nil)
+			},
+			assert: func(t *testing.T, err error, _ *ruleImpl) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrConfiguration)
+				require.ErrorContains(t, err, "shadow pipeline must not contain finalizers")
+			},
+		},
+		"with upstream tls key material not located in an allowed path": {
+			opMode: config.ProxyMode,
+			config: config2.Rule{
+				ID:      "foobar",
+				Matcher: config2.Matcher{Routes: []config2.Route{{Path: "/foo/bar"}}},
+				Backend: &config2.Backend{
+					Host: "foo.bar",
+					Transport: &config2.Transport{
+						TLS: &config2.UpstreamTLS{TrustStore: &config2.TrustStore{Path: os.TempDir()}},
+					},
+				},
+				Execute: []config.MechanismConfig{
+					{"authenticator": "foo"},
+				},
+			},
+			configureMocks: func(t *testing.T, mhf *mocks3.MechanismFactoryMock) {
+				t.Helper()
+
+				mhf.EXPECT().CreateAuthenticator("test", "foo", mock.Anything).
+					Return(&mocks2.AuthenticatorMock{}, nil)
+			},
+			assert: func(t *testing.T, err error, _ *ruleImpl) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrConfiguration)
+				require.ErrorContains(t, err, "not located in any of the allowed paths")
+			},
+		},
+		"with error while creating shadow pipeline": {
+			config: config2.Rule{
+				ID:      "foobar",
+				Matcher: config2.Matcher{Routes: []config2.Route{{Path: "/foo/bar"}}},
+				Execute: []config.MechanismConfig{
+					{"authenticator": "foo"},
+				},
+				Shadow: &config2.Shadow{
+					Execute: []config.MechanismConfig{
+						{"authorizer": "bar"},
+					},
+				},
+			},
+			configureMocks: func(t *testing.T, mhf *mocks3.MechanismFactoryMock) {
+				t.Helper()
+
+				mhf.EXPECT().CreateAuthenticator("test", "foo", mock.Anything).
+					Return(&mocks2.AuthenticatorMock{}, nil)
+				mhf.EXPECT().CreateAuthorizer("test", "bar", mock.Anything).
+					Return(nil, errors.New("test error"))
+			},
+			assert: func(t *testing.T, err error, _ *ruleImpl) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrConfiguration)
+				require.ErrorContains(t, err, "failed creating shadow pipeline")
+			},
+		},
 	} {
 		t.Run(uc, func(t *testing.T) {
 			// GIVEN
@@ -18,13 +18,19 @@ package rules
 
 import (
 	"bytes"
+	"context"
+	"crypto/tls"
+	"maps"
 	"net/url"
 	"strings"
+	"sync"
 
 	"github.com/rs/zerolog"
 
 	"github.com/dadrus/heimdall/internal/heimdall"
 	"github.com/dadrus/heimdall/internal/rules/config"
+	"github.com/dadrus/heimdall/internal/rules/mechanisms/subject"
+	"github.com/dadrus/heimdall/internal/rules/mechanisms/template"
 	"github.com/dadrus/heimdall/internal/rules/rule"
 	"github.com/dadrus/heimdall/internal/x/errorchain"
 )
@@ -38,10 +44,18 @@ type ruleImpl struct {
 	routes             []rule.Route
 	slashesHandling    config.EncodedSlashesHandling
 	backend            *config.Backend
+	constraints        requestConstraints
+	hashKey            template.Template
 	sc                 compositeSubjectCreator
 	sh                 compositeSubjectHandler
+	az                 compositeSubjectHandler
 	fi                 compositeSubjectHandler
 	eh                 compositeErrorHandler
+	rh                 compositeResponseHandler
+	shadow             *shadowPipeline
+	upstreamTLS        *tls.Config
+	released           chan struct{}
+	releaseOnce        sync.Once
 }
 
 func (r *ruleImpl) Execute(ctx heimdall.RequestContext) (rule.Backend, error) {
@@ -72,33 +86,117 @@ func (r *ruleImpl) Execute(ctx heimdall.RequestContext) (rule.Backend, error) {
 		captures[k] = unescape(v, r.slashesHandling)
 	}
 
+	sub, err := r.executePipeline(ctx)
+
+	if r.shadow != nil {
+		r.shadow.Execute(ctx, sub, err)
+	}
+
+	if err != nil {
+		return nil, r.eh.Execute(ctx, sub, err)
+	}
+
+	return r.createBackend(ctx, sub), nil
+}
+
+// executePipeline executes the execute pipeline of the rule. The returned subject is set
+// as soon as the authentication succeeded, even if a subsequent step failed.
+func (r *ruleImpl) executePipeline(ctx heimdall.RequestContext) (*subject.Subject, error) {
+	if err := r.constraints.verify(ctx.Request()); err != nil {
+		return nil, err
+	}
+
 	// authenticators
 	sub, err := r.sc.Execute(ctx)
 	if err != nil {
-		return nil, r.eh.Execute(ctx, err)
+		return nil, err
 	}
 
 	// authorizers & contextualizer
 	if err = r.sh.Execute(ctx, sub); err != nil {
-		return nil, r.eh.Execute(ctx, err)
+		return sub, err
 	}
 
 	// finalizers
 	if err = r.fi.Execute(ctx, sub); err != nil {
-		return nil, r.eh.Execute(ctx, err)
+		return sub, err
+	}
+
+	return sub, nil
+}
+
+// revalidate verifies the subject, the execute pipeline has been executed for before, is still valid.
+// To avoid repeating side effects, only the constraints, the authenticators and the authorizers are
+// executed. Contextualizers and finalizers are skipped.
+func (r *ruleImpl) revalidate(ctx heimdall.RequestContext) error {
+	if err := r.constraints.verify(ctx.Request()); err != nil {
+		return err
+	}
+
+	sub, err := r.sc.Execute(ctx)
+	if err != nil {
+		return err
 	}
 
-	return r.createBackend(request), nil
+	return r.az.Execute(ctx, sub)
 }
 
-func (r *ruleImpl) createBackend(request *heimdall.Request) rule.Backend {
+// release signals the release of the rule, which happens as soon as it has been removed from the
+// repository. Resources bound to the rule, like the health checks of its upstream targets, are
+// stopped that way.
+func (r *ruleImpl) release() {
+	if r.released != nil {
+		r.releaseOnce.Do(func() { close(r.released) })
+	}
+}
+
+func (r *ruleImpl) createBackend(ctx heimdall.RequestContext, sub *subject.Subject) rule.Backend {
 	var upstream rule.Backend
 
 	if r.backend != nil {
+		captures := ctx.Request().URL.Captures
+
 		upstream = backend{
-			targetURL: r.backend.CreateURL(&request.URL.URL),
+			targetURL: r.backend.CreateURL(&ctx.Request().URL.URL),
 			forwardHostHeader: r.backend.ForwardHostHeader == nil ||
 				(r.backend.ForwardHostHeader != nil && *r.backend.ForwardHostHeader),
+			targets:       r.backend.Targets,
+			loadBalancing: r.backend.LoadBalancing,
+			upgrade:       r.backend.Upgrade,
+			transport:     r.backend.Transport,
+			tlsConfig:     r.upstreamTLS,
+			released:      r.released,
+			hashKey: func() (string, error) {
+				if r.hashKey == nil {
+					return "", nil
+				}
+
+				return r.hashKey.Render(map[string]any{
+					"Request": ctx.Request(),
+					"Subject": sub,
+					"Outputs": ctx.Outputs(),
+				})
+			},
+			revalidate: func(vctx heimdall.RequestContext) error {
+				// captures are set while matching the rule and are already unescaped
+				vctx.Request().URL.Captures = maps.Clone(captures)
+
+				return r.revalidate(&revalidationContext{
+					RequestContext: vctx,
+					ctx:            heimdall.WithRevalidation(vctx.Context()),
+				})
+			},
+			handleResponse: func(resp *heimdall.Response) error {
+				if len(r.rh) == 0 {
+					return nil
+				}
+
+				if err := r.rh.Execute(ctx, sub, resp); err != nil {
+					return r.eh.Execute(ctx, sub, err)
+				}
+
+				return nil
+			},
 		}
 	}
 
@@ -156,12 +254,48 @@ func (r *routeImpl) Rule() rule.Rule { return r.rule }
 type backend struct {
 	targetURL         *url.URL
 	forwardHostHeader bool
+	targets           []config.Target
+	loadBalancing     *config.LoadBalancing
+	upgrade           *config.Upgrade
+	transport         *config.Transport
+	tlsConfig         *tls.Config
+	released          <-chan struct{}
+	hashKey           func() (string, error)
+	revalidate        func(ctx heimdall.RequestContext) error
+	handleResponse    func(resp *heimdall.Response) error
 }
 
 func (b backend) URL() *url.URL { return b.targetURL }
 
 func (b backend) ForwardHostHeader() bool { return b.forwardHostHeader }
 
+func (b backend) HandleResponse(resp *heimdall.Response) error { return b.handleResponse(resp) }
+
+func (b backend) Upgrade() *config.Upgrade { return b.upgrade }
+
+func (b backend) Revalidate(ctx heimdall.RequestContext) error { return b.revalidate(ctx) }
+
+func (b backend) Targets() []config.Target { return b.targets }
+
+func (b backend) LoadBalancing() *config.LoadBalancing { return b.loadBalancing }
+
+func (b backend) HashKey() (string, error) { return b.hashKey() }
+
+func (b backend) Transport() *config.Transport { return b.transport }
+
+func (b backend) TLSConfig() *tls.Config { return b.tlsConfig }
+
+func (b backend) Released() <-chan struct{} { return b.released }
+
+// revalidationContext marks the pipeline execution as revalidation for the executed mechanisms.
+type revalidationContext struct {
+	heimdall.RequestContext
+
+	ctx context.Context
+}
+
+func (c *revalidationContext) Context() context.Context { return c.ctx }
+
 func unescape(value string, handling config.EncodedSlashesHandling) string {
 	if handling == config.EncodedSlashesOn {
 		unescaped, _ := url.PathUnescape(value)
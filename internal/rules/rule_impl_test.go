@@ -20,14 +20,20 @@ import (
 	"errors"
 	"net/url"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
+	metricnoop "go.opentelemetry.io/otel/metric/noop"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
 
 	"github.com/dadrus/heimdall/internal/heimdall"
 	heimdallmocks "github.com/dadrus/heimdall/internal/heimdall/mocks"
 	"github.com/dadrus/heimdall/internal/rules/config"
 	"github.com/dadrus/heimdall/internal/rules/mechanisms/subject"
+	"github.com/dadrus/heimdall/internal/rules/mechanisms/template"
 	"github.com/dadrus/heimdall/internal/rules/mocks"
 	"github.com/dadrus/heimdall/internal/rules/rule"
 	"github.com/dadrus/heimdall/internal/x"
@@ -42,6 +48,7 @@ func TestRuleExecute(t *testing.T) {
 	for uc, tc := range map[string]struct {
 		backend        *config.Backend
 		slashHandling  config.EncodedSlashesHandling
+		constraints    requestConstraints
 		configureMocks func(
 			t *testing.T,
 			ctx *heimdallmocks.RequestContextMock,
@@ -64,7 +71,31 @@ func TestRuleExecute(t *testing.T) {
 				testErr := errors.New("test error")
 
 				authenticator.EXPECT().Execute(ctx).Return(nil, testErr)
-				errHandler.EXPECT().Execute(ctx, testErr).Return(nil)
+				errHandler.EXPECT().Execute(ctx, (*subject.Subject)(nil), testErr).Return(nil)
+			},
+			assert: func(t *testing.T, err error, backend rule.Backend, _ map[string]string) {
+				t.Helper()
+
+				require.NoError(t, err)
+				assert.Nil(t, backend)
+			},
+		},
+		"request violates constraints, but error handler succeeds": {
+			constraints: requestConstraints{requiredHeadersConstraint{"X-Foo"}},
+			configureMocks: func(t *testing.T, ctx *heimdallmocks.RequestContextMock, _ *mocks.SubjectCreatorMock,
+				_ *mocks.SubjectHandlerMock, _ *mocks.SubjectHandlerMock,
+				errHandler *mocks.ErrorHandlerMock,
+			) {
+				t.Helper()
+
+				reqf := heimdallmocks.NewRequestFunctionsMock(t)
+				reqf.EXPECT().Header("X-Foo").Return("")
+
+				ctx.EXPECT().Request().Return(&heimdall.Request{RequestFunctions: reqf, URL: &heimdall.URL{}})
+
+				errHandler.EXPECT().Execute(ctx, (*subject.Subject)(nil), mock.MatchedBy(func(err error) bool {
+					return errors.Is(err, heimdall.ErrArgument)
+				})).Return(nil)
 			},
 			assert: func(t *testing.T, err error, backend rule.Backend, _ map[string]string) {
 				t.Helper()
@@ -85,7 +116,7 @@ func TestRuleExecute(t *testing.T) {
 				testErr := errors.New("test error")
 
 				authenticator.EXPECT().Execute(ctx).Return(nil, testErr)
-				errHandler.EXPECT().Execute(ctx, testErr).Return(errors.New("some error"))
+				errHandler.EXPECT().Execute(ctx, (*subject.Subject)(nil), testErr).Return(errors.New("some error"))
 			},
 			assert: func(t *testing.T, err error, backend rule.Backend, _ map[string]string) {
 				t.Helper()
@@ -111,7 +142,7 @@ func TestRuleExecute(t *testing.T) {
 				authenticator.EXPECT().Execute(ctx).Return(sub, nil)
 				authorizer.EXPECT().Execute(ctx, sub).Return(testErr)
 				authorizer.EXPECT().ContinueOnError().Return(false)
-				errHandler.EXPECT().Execute(ctx, testErr).Return(nil)
+				errHandler.EXPECT().Execute(ctx, sub, testErr).Return(nil)
 			},
 			assert: func(t *testing.T, err error, backend rule.Backend, _ map[string]string) {
 				t.Helper()
@@ -136,7 +167,7 @@ func TestRuleExecute(t *testing.T) {
 				authenticator.EXPECT().Execute(ctx).Return(sub, nil)
 				authorizer.EXPECT().Execute(ctx, sub).Return(testErr)
 				authorizer.EXPECT().ContinueOnError().Return(false)
-				errHandler.EXPECT().Execute(ctx, testErr).Return(errors.New("some error"))
+				errHandler.EXPECT().Execute(ctx, sub, testErr).Return(errors.New("some error"))
 			},
 			assert: func(t *testing.T, err error, backend rule.Backend, _ map[string]string) {
 				t.Helper()
@@ -163,7 +194,7 @@ func TestRuleExecute(t *testing.T) {
 				authorizer.EXPECT().Execute(ctx, sub).Return(nil)
 				finalizer.EXPECT().Execute(ctx, sub).Return(testErr)
 				finalizer.EXPECT().ContinueOnError().Return(false)
-				errHandler.EXPECT().Execute(ctx, testErr).Return(nil)
+				errHandler.EXPECT().Execute(ctx, sub, testErr).Return(nil)
 			},
 			assert: func(t *testing.T, err error, backend rule.Backend, _ map[string]string) {
 				t.Helper()
@@ -189,7 +220,7 @@ func TestRuleExecute(t *testing.T) {
 				authorizer.EXPECT().Execute(ctx, sub).Return(nil)
 				finalizer.EXPECT().Execute(ctx, sub).Return(testErr)
 				finalizer.EXPECT().ContinueOnError().Return(false)
-				errHandler.EXPECT().Execute(ctx, testErr).Return(errors.New("some error"))
+				errHandler.EXPECT().Execute(ctx, sub, testErr).Return(errors.New("some error"))
 			},
 			assert: func(t *testing.T, err error, backend rule.Backend, _ map[string]string) {
 				t.Helper()
@@ -444,6 +475,7 @@ func TestRuleExecute(t *testing.T) {
 			rul := &ruleImpl{
 				backend:         tc.backend,
 				slashesHandling: x.IfThenElse(len(tc.slashHandling) != 0, tc.slashHandling, config.EncodedSlashesOff),
+				constraints:     tc.constraints,
 				sc:              compositeSubjectCreator{authenticator},
 				sh:              compositeSubjectHandler{authorizer},
 				fi:              compositeSubjectHandler{finalizer},
@@ -460,3 +492,382 @@ func TestRuleExecute(t *testing.T) {
 		})
 	}
 }
+
+func TestRuleExecuteWithShadowPipeline(t *testing.T) {
+	t.Parallel()
+
+	for uc, tc := range map[string]struct {
+		activeErr error
+		shadowErr error
+		assert    func(t *testing.T, err error, backend rule.Backend)
+	}{
+		"shadow pipeline denies request allowed by the execute pipeline": {
+			shadowErr: errors.New("shadow error"),
+			assert: func(t *testing.T, err error, backend rule.Backend) {
+				t.Helper()
+
+				require.NoError(t, err)
+				require.NotNil(t, backend)
+			},
+		},
+		"shadow pipeline allows request denied by the execute pipeline": {
+			activeErr: errors.New("active error"),
+			assert: func(t *testing.T, err error, backend rule.Backend) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorContains(t, err, "active error")
+				assert.Nil(t, backend)
+			},
+		},
+	} {
+		t.Run(uc, func(t *testing.T) {
+			// GIVEN
+			ctx := heimdallmocks.NewRequestContextMock(t)
+			ctx.EXPECT().Context().Return(t.Context())
+			ctx.EXPECT().Outputs().Return(map[string]any{})
+
+			targetURL, _ := url.Parse("http://foo.local/api/v1/foo")
+			ctx.EXPECT().Request().Return(&heimdall.Request{URL: &heimdall.URL{URL: *targetURL}})
+
+			sub := &subject.Subject{ID: "Foo"}
+
+			authenticator := mocks.NewSubjectCreatorMock(t)
+			authenticator.EXPECT().Execute(ctx).Return(sub, nil)
+
+			authorizer := mocks.NewSubjectHandlerMock(t)
+			authorizer.EXPECT().Execute(ctx, sub).Return(tc.activeErr)
+
+			if tc.activeErr != nil {
+				authorizer.EXPECT().ContinueOnError().Return(false)
+			}
+
+			shadowAuthorizer := mocks.NewSubjectHandlerMock(t)
+			shadowAuthorizer.EXPECT().Execute(mock.Anything, mock.MatchedBy(func(other *subject.Subject) bool {
+				return other != sub && other.ID == sub.ID
+			})).Return(tc.shadowErr)
+
+			if tc.shadowErr != nil {
+				shadowAuthorizer.EXPECT().ContinueOnError().Return(false)
+			}
+
+			errHandler := mocks.NewErrorHandlerMock(t)
+
+			if tc.activeErr != nil {
+				errHandler.EXPECT().Execute(ctx, sub, tc.activeErr).Return(tc.activeErr)
+			}
+
+			recorder := tracetest.NewSpanRecorder()
+
+			shadow, err := newShadowPipeline("foo", "bar", 0, nil,
+				compositeSubjectHandler{shadowAuthorizer}, make(chan struct{}, 1),
+				sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)), metricnoop.NewMeterProvider())
+			require.NoError(t, err)
+
+			rul := &ruleImpl{
+				backend:         &config.Backend{Host: "foo.bar"},
+				slashesHandling: config.EncodedSlashesOff,
+				sc:              compositeSubjectCreator{authenticator},
+				sh:              compositeSubjectHandler{authorizer},
+				eh:              compositeErrorHandler{errHandler},
+				shadow:          shadow,
+			}
+
+			// WHEN
+			upstream, err := rul.Execute(ctx)
+
+			// THEN
+			tc.assert(t, err, upstream)
+
+			require.Eventually(t, func() bool { return len(recorder.Ended()) == 1 }, time.Second, 10*time.Millisecond)
+		})
+	}
+}
+
+func TestRuleBackendHandleResponse(t *testing.T) {
+	t.Parallel()
+
+	for uc, tc := range map[string]struct {
+		withHandler    bool
+		configureMocks func(
+			t *testing.T,
+			ctx *heimdallmocks.RequestContextMock,
+			handler *mocks.ResponseHandlerMock,
+			errHandler *mocks.ErrorHandlerMock,
+			sub *subject.Subject,
+			resp *heimdall.Response,
+		)
+		assert func(t *testing.T, err error, resp *heimdall.Response)
+	}{
+		"without response handlers": {
+			assert: func(t *testing.T, err error, resp *heimdall.Response) {
+				t.Helper()
+
+				require.NoError(t, err)
+				assert.Equal(t, 200, resp.StatusCode)
+			},
+		},
+		"response handler succeeds": {
+			withHandler: true,
+			configureMocks: func(t *testing.T, ctx *heimdallmocks.RequestContextMock,
+				handler *mocks.ResponseHandlerMock, _ *mocks.ErrorHandlerMock,
+				sub *subject.Subject, resp *heimdall.Response,
+			) {
+				t.Helper()
+
+				handler.EXPECT().Execute(ctx, sub, resp).
+					RunAndReturn(func(_ heimdall.RequestContext, _ *subject.Subject, resp *heimdall.Response) error {
+						resp.StatusCode = 404
+
+						return nil
+					})
+			},
+			assert: func(t *testing.T, err error, resp *heimdall.Response) {
+				t.Helper()
+
+				require.NoError(t, err)
+				assert.Equal(t, 404, resp.StatusCode)
+			},
+		},
+		"response handler fails, but error handler succeeds": {
+			withHandler: true,
+			configureMocks: func(t *testing.T, ctx *heimdallmocks.RequestContextMock,
+				handler *mocks.ResponseHandlerMock, errHandler *mocks.ErrorHandlerMock,
+				sub *subject.Subject, resp *heimdall.Response,
+			) {
+				t.Helper()
+
+				testErr := errors.New("test error")
+
+				handler.EXPECT().Execute(ctx, sub, resp).Return(testErr)
+				errHandler.EXPECT().Execute(ctx, sub, testErr).Return(nil)
+			},
+			assert: func(t *testing.T, err error, _ *heimdall.Response) {
+				t.Helper()
+
+				require.NoError(t, err)
+			},
+		},
+		"response handler fails and error handler fails": {
+			withHandler: true,
+			configureMocks: func(t *testing.T, ctx *heimdallmocks.RequestContextMock,
+				handler *mocks.ResponseHandlerMock, errHandler *mocks.ErrorHandlerMock,
+				sub *subject.Subject, resp *heimdall.Response,
+			) {
+				t.Helper()
+
+				testErr := errors.New("test error")
+
+				handler.EXPECT().Execute(ctx, sub, resp).Return(testErr)
+				errHandler.EXPECT().Execute(ctx, sub, testErr).Return(testErr)
+			},
+			assert: func(t *testing.T, err error, _ *heimdall.Response) {
+				t.Helper()
+
+				require.Error(t, err)
+				assert.Equal(t, "test error", err.Error())
+			},
+		},
+	} {
+		t.Run(uc, func(t *testing.T) {
+			// GIVEN
+			configureMocks := x.IfThenElse(tc.configureMocks != nil,
+				tc.configureMocks,
+				func(t *testing.T, _ *heimdallmocks.RequestContextMock, _ *mocks.ResponseHandlerMock,
+					_ *mocks.ErrorHandlerMock, _ *subject.Subject, _ *heimdall.Response,
+				) {
+					t.Helper()
+				})
+
+			targetURL, _ := url.Parse("http://foo.local/api/v1/foo")
+			sub := &subject.Subject{ID: "Foo"}
+			resp := &heimdall.Response{StatusCode: 200}
+
+			ctx := heimdallmocks.NewRequestContextMock(t)
+			ctx.EXPECT().Request().Return(&heimdall.Request{URL: &heimdall.URL{URL: *targetURL}})
+
+			handler := mocks.NewResponseHandlerMock(t)
+			errHandler := mocks.NewErrorHandlerMock(t)
+
+			rul := &ruleImpl{
+				backend: &config.Backend{Host: "foo.bar"},
+				eh:      compositeErrorHandler{errHandler},
+			}
+
+			if tc.withHandler {
+				ctx.EXPECT().Context().Return(t.Context())
+
+				rul.rh = compositeResponseHandler{handler}
+			}
+
+			configureMocks(t, ctx, handler, errHandler, sub, resp)
+
+			upstream := rul.createBackend(ctx, sub)
+
+			// WHEN
+			err := upstream.HandleResponse(resp)
+
+			// THEN
+			tc.assert(t, err, resp)
+		})
+	}
+}
+
+func TestRuleBackendRevalidate(t *testing.T) {
+	t.Parallel()
+
+	revalidation := mock.MatchedBy(func(ctx heimdall.RequestContext) bool {
+		return heimdall.IsRevalidation(ctx.Context())
+	})
+
+	for uc, tc := range map[string]struct {
+		configureMocks func(
+			t *testing.T,
+			authenticator *mocks.SubjectCreatorMock,
+			authorizer *mocks.SubjectHandlerMock,
+		)
+		assert func(t *testing.T, err error)
+	}{
+		"authentication fails": {
+			configureMocks: func(t *testing.T, authenticator *mocks.SubjectCreatorMock, _ *mocks.SubjectHandlerMock) {
+				t.Helper()
+
+				authenticator.EXPECT().Execute(revalidation).Return(nil, heimdall.ErrAuthentication)
+			},
+			assert: func(t *testing.T, err error) {
+				t.Helper()
+
+				require.ErrorIs(t, err, heimdall.ErrAuthentication)
+			},
+		},
+		"authorization fails": {
+			configureMocks: func(t *testing.T, authenticator *mocks.SubjectCreatorMock, authorizer *mocks.SubjectHandlerMock) {
+				t.Helper()
+
+				sub := &subject.Subject{ID: "Foo"}
+
+				authenticator.EXPECT().Execute(revalidation).Return(sub, nil)
+				authorizer.EXPECT().Execute(revalidation, sub).Return(heimdall.ErrAuthorization)
+				authorizer.EXPECT().ContinueOnError().Return(false)
+			},
+			assert: func(t *testing.T, err error) {
+				t.Helper()
+
+				require.ErrorIs(t, err, heimdall.ErrAuthorization)
+			},
+		},
+		"subject is still valid": {
+			configureMocks: func(t *testing.T, authenticator *mocks.SubjectCreatorMock, authorizer *mocks.SubjectHandlerMock) {
+				t.Helper()
+
+				sub := &subject.Subject{ID: "Foo"}
+
+				authenticator.EXPECT().Execute(revalidation).Return(sub, nil)
+				authorizer.EXPECT().Execute(revalidation, sub).Return(nil)
+			},
+			assert: func(t *testing.T, err error) {
+				t.Helper()
+
+				require.NoError(t, err)
+			},
+		},
+	} {
+		t.Run(uc, func(t *testing.T) {
+			// GIVEN
+			targetURL, _ := url.Parse("http://foo.local/api/v1/foo%2Fbar")
+			captures := map[string]string{"id": "foo/bar"}
+
+			ctx := heimdallmocks.NewRequestContextMock(t)
+			ctx.EXPECT().Request().Return(&heimdall.Request{URL: &heimdall.URL{URL: *targetURL, Captures: captures}})
+
+			revalidationReq := &heimdall.Request{URL: &heimdall.URL{URL: *targetURL}}
+			revalidationCtx := heimdallmocks.NewRequestContextMock(t)
+			revalidationCtx.EXPECT().Request().Return(revalidationReq)
+			revalidationCtx.EXPECT().Context().Return(t.Context())
+
+			authenticator := mocks.NewSubjectCreatorMock(t)
+			authorizer := mocks.NewSubjectHandlerMock(t)
+
+			// neither contextualizers, nor finalizers, nor error handlers must be executed on revalidation
+			contextualizer := mocks.NewSubjectHandlerMock(t)
+			finalizer := mocks.NewSubjectHandlerMock(t)
+			errHandler := mocks.NewErrorHandlerMock(t)
+
+			tc.configureMocks(t, authenticator, authorizer)
+
+			rul := &ruleImpl{
+				backend: &config.Backend{
+					Host:    "foo.bar",
+					Upgrade: &config.Upgrade{Protocols: []string{config.UpgradeProtocolWebSocket}},
+				},
+				sc: compositeSubjectCreator{authenticator},
+				sh: compositeSubjectHandler{contextualizer, authorizer},
+				az: compositeSubjectHandler{authorizer},
+				fi: compositeSubjectHandler{finalizer},
+				eh: compositeErrorHandler{errHandler},
+			}
+
+			upstream := rul.createBackend(ctx, &subject.Subject{ID: "Foo"})
+
+			// WHEN
+			err := upstream.Revalidate(revalidationCtx)
+
+			// THEN
+			tc.assert(t, err)
+			assert.Equal(t, rul.backend.Upgrade, upstream.Upgrade())
+			assert.Equal(t, captures, revalidationReq.URL.Captures)
+		})
+	}
+}
+
+func TestRuleBackendHashKey(t *testing.T) {
+	t.Parallel()
+
+	for uc, tc := range map[string]struct {
+		hashKey  string
+		expected string
+	}{
+		"without hash key": {},
+		"with hash key": {
+			hashKey:  "{{ .Subject.ID }}-{{ .Request.URL.Captures.id }}",
+			expected: "Foo-bar",
+		},
+	} {
+		t.Run(uc, func(t *testing.T) {
+			// GIVEN
+			targetURL, _ := url.Parse("http://foo.local/api/v1/bar")
+
+			ctx := heimdallmocks.NewRequestContextMock(t)
+			ctx.EXPECT().Request().Return(&heimdall.Request{
+				URL: &heimdall.URL{URL: *targetURL, Captures: map[string]string{"id": "bar"}},
+			})
+			ctx.EXPECT().Outputs().Return(map[string]any{}).Maybe()
+
+			rul := &ruleImpl{
+				backend: &config.Backend{
+					Targets:       []config.Target{{Host: "foo:8080"}, {Host: "bar:8080", Weight: 2}},
+					LoadBalancing: &config.LoadBalancing{Strategy: config.LoadBalancingConsistentHash},
+				},
+			}
+
+			if len(tc.hashKey) != 0 {
+				tpl, err := template.New(tc.hashKey)
+				require.NoError(t, err)
+
+				rul.hashKey = tpl
+			}
+
+			upstream := rul.createBackend(ctx, &subject.Subject{ID: "Foo"})
+
+			// WHEN
+			key, err := upstream.HashKey()
+
+			// THEN
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, key)
+			assert.Equal(t, rul.backend.Targets, upstream.Targets())
+			assert.Equal(t, rul.backend.LoadBalancing, upstream.LoadBalancing())
+			assert.Empty(t, upstream.URL().Host)
+		})
+	}
+}
@@ -0,0 +1,347 @@
+// Copyright 2025 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package rules
+
+import (
+	"context"
+	"crypto/x509"
+	"maps"
+	"net/http"
+	"net/textproto"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/dadrus/heimdall/internal/accesscontext"
+	"github.com/dadrus/heimdall/internal/cache"
+	"github.com/dadrus/heimdall/internal/heimdall"
+	"github.com/dadrus/heimdall/internal/rules/mechanisms/contenttype"
+	"github.com/dadrus/heimdall/internal/rules/mechanisms/subject"
+	"github.com/dadrus/heimdall/version"
+)
+
+const (
+	instrumentationName = "github.com/dadrus/heimdall/internal/rules"
+
+	decisionAllow = "allow"
+	decisionDeny  = "deny"
+
+	ruleIDAttrKey         = attribute.Key("rule.id")
+	ruleSourceAttrKey     = attribute.Key("rule.source")
+	activeDecisionAttrKey = attribute.Key("decision.active")
+	shadowDecisionAttrKey = attribute.Key("decision.shadow")
+
+	defaultShadowPipelineTimeout = 5 * time.Second
+	shadowCacheKeyPrefix         = "shadow:"
+	// maxConcurrentShadowEvaluations limits the number of shadow pipeline evaluations running in
+	// the background across all rules. Evaluations exceeding it are dropped.
+	maxConcurrentShadowEvaluations = 128
+)
+
+// shadowPipeline evaluates a candidate pipeline alongside the execute pipeline of a rule.
+// Its decision is only reported via logs, spans and metrics and never affects the
+// handling of the request.
+type shadowPipeline struct {
+	ruleID      string
+	srcID       string
+	timeout     time.Duration
+	sc          compositeSubjectCreator
+	sh          compositeSubjectHandler
+	slots       chan struct{}
+	tracer      trace.Tracer
+	divergences metric.Int64Counter
+	dropped     metric.Int64Counter
+}
+
+func newShadowPipeline(
+	ruleID, srcID string,
+	timeout time.Duration,
+	sc compositeSubjectCreator,
+	sh compositeSubjectHandler,
+	slots chan struct{},
+	tp trace.TracerProvider,
+	mp metric.MeterProvider,
+) (*shadowPipeline, error) {
+	meter := mp.Meter(instrumentationName, metric.WithInstrumentationVersion(version.Version))
+
+	divergences, err := meter.Int64Counter(
+		"rule.shadow.divergences",
+		metric.WithDescription("Number of requests, the shadow pipeline decided differently about"),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	dropped, err := meter.Int64Counter(
+		"rule.shadow.dropped",
+		metric.WithDescription("Number of requests, the shadow pipeline has not been evaluated for "+
+			"as too many evaluations were in progress"),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if timeout == 0 {
+		timeout = defaultShadowPipelineTimeout
+	}
+
+	return &shadowPipeline{
+		ruleID:      ruleID,
+		srcID:       srcID,
+		timeout:     timeout,
+		sc:          sc,
+		sh:          sh,
+		slots:       slots,
+		tracer:      tp.Tracer(instrumentationName, trace.WithInstrumentationVersion(version.Version)),
+		divergences: divergences,
+		dropped:     dropped,
+	}, nil
+}
+
+// Execute starts the evaluation of the shadow pipeline and reports its decision compared to
+// the decision of the execute pipeline, represented by activeErr. sub is the subject created
+// by the execute pipeline, if any, and is used if the shadow pipeline does not define own
+// authenticators. The evaluation happens in the background on a snapshot of the request, so
+// it neither adds to the latency of the request, nor outlives the configured timeout. If too
+// many evaluations are already in progress, the evaluation is dropped and counted instead.
+func (p *shadowPipeline) Execute(ctx heimdall.RequestContext, sub *subject.Subject, activeErr error) {
+	select {
+	case p.slots <- struct{}{}:
+	default:
+		p.dropped.Add(ctx.Context(), 1, metric.WithAttributes(
+			ruleIDAttrKey.String(p.ruleID),
+			ruleSourceAttrKey.String(p.srcID),
+		))
+
+		zerolog.Ctx(ctx.Context()).Debug().
+			Str("_src", p.srcID).
+			Str("_id", p.ruleID).
+			Msg("Shadow pipeline evaluation dropped as too many evaluations are in progress")
+
+		return
+	}
+
+	// detach from the request, which might be finished before the shadow pipeline is, but
+	// keep the values, like the logger, and isolate cache entries from the live ones
+	detached := context.WithoutCancel(ctx.Context())
+	detached = cache.WithContext(detached, &shadowCache{Cache: cache.Ctx(detached)})
+
+	if sub != nil {
+		sub = &subject.Subject{ID: sub.ID, Attributes: maps.Clone(sub.Attributes)}
+	}
+
+	sctx := &shadowRequestContext{
+		ctx:     detached,
+		req:     snapshotRequest(ctx.Request()),
+		outputs: maps.Clone(ctx.Outputs()),
+	}
+
+	go func() {
+		defer func() { <-p.slots }()
+
+		p.run(sctx, sub, activeErr)
+	}()
+}
+
+func (p *shadowPipeline) run(sctx *shadowRequestContext, sub *subject.Subject, activeErr error) {
+	ctx, cancel := context.WithTimeout(sctx.ctx, p.timeout)
+	defer cancel()
+
+	ctx, span := p.tracer.Start(ctx, "Shadow Pipeline",
+		trace.WithAttributes(ruleIDAttrKey.String(p.ruleID), ruleSourceAttrKey.String(p.srcID)))
+	defer span.End()
+
+	sctx.ctx = accesscontext.New(ctx)
+
+	shadowErr := p.evaluate(sctx, sub, activeErr)
+	activeDecision := decisionOf(activeErr)
+	shadowDecision := decisionOf(shadowErr)
+	divergent := activeDecision != shadowDecision
+
+	span.SetAttributes(
+		activeDecisionAttrKey.String(activeDecision),
+		shadowDecisionAttrKey.String(shadowDecision),
+		attribute.Bool("decision.divergent", divergent),
+	)
+
+	logger := zerolog.Ctx(ctx)
+	event := logger.Debug()
+
+	if divergent {
+		event = logger.Warn()
+
+		p.divergences.Add(ctx, 1, metric.WithAttributes(
+			ruleIDAttrKey.String(p.ruleID),
+			ruleSourceAttrKey.String(p.srcID),
+			activeDecisionAttrKey.String(activeDecision),
+			shadowDecisionAttrKey.String(shadowDecision),
+		))
+	}
+
+	event.
+		Str("_src", p.srcID).
+		Str("_id", p.ruleID).
+		Str("_active_decision", activeDecision).
+		Str("_shadow_decision", shadowDecision).
+		Bool("_divergent", divergent).
+		AnErr("_shadow_error", shadowErr).
+		Msg("Shadow pipeline evaluated")
+}
+
+func (p *shadowPipeline) evaluate(ctx heimdall.RequestContext, sub *subject.Subject, activeErr error) error {
+	var err error
+
+	if len(p.sc) != 0 {
+		sub, err = p.sc.Execute(ctx)
+		if err != nil {
+			return err
+		}
+	} else if sub == nil {
+		// authentication failed in the execute pipeline
+		return activeErr
+	}
+
+	return p.sh.Execute(ctx, sub)
+}
+
+func decisionOf(err error) string {
+	if err != nil {
+		return decisionDeny
+	}
+
+	return decisionAllow
+}
+
+// shadowRequestContext isolates the mechanisms of the shadow pipeline from the actual request
+// handling. Outputs are written to a copy and headers, cookies, query parameters, metadata
+// and errors are discarded.
+type shadowRequestContext struct {
+	ctx     context.Context //nolint:containedctx
+	req     *heimdall.Request
+	outputs map[string]any
+}
+
+func (c *shadowRequestContext) Context() context.Context                 { return c.ctx }
+func (c *shadowRequestContext) Request() *heimdall.Request               { return c.req }
+func (c *shadowRequestContext) Outputs() map[string]any                  { return c.outputs }
+func (c *shadowRequestContext) AddHeaderForUpstream(_, _ string)         {}
+func (c *shadowRequestContext) AddCookieForUpstream(_, _ string)         {}
+func (c *shadowRequestContext) AddQueryParameterForUpstream(_, _ string) {}
+func (c *shadowRequestContext) AddDynamicMetadata(_, _ string)           {}
+func (c *shadowRequestContext) AddCookieForClient(_ *http.Cookie)        {}
+func (c *shadowRequestContext) SetPipelineError(_ error)                 {}
+
+// rawBodyProvider is implemented by request functions able to provide the body as is.
+type rawBodyProvider interface {
+	RawBody() []byte
+}
+
+// snapshotRequest copies the given request, so it can be used after the actual request has
+// been handled. The body cannot be read afterwards, as it might already be streamed to the
+// upstream service at that time. For that reason its bytes are taken here, which is why the
+// snapshot is created for admitted evaluations only. Decoding of the body is however deferred
+// until a mechanism of the shadow pipeline makes use of it.
+func snapshotRequest(req *heimdall.Request) *heimdall.Request {
+	snapshot := *req
+
+	reqURL := *req.URL
+	reqURL.Captures = maps.Clone(req.URL.Captures)
+
+	snapshot.URL = &reqURL
+	snapshot.ClientIPAddresses = append([]string(nil), req.ClientIPAddresses...)
+	snapshot.Metadata = maps.Clone(req.Metadata)
+
+	if req.RequestFunctions != nil {
+		var body func() any
+
+		if rbp, ok := req.RequestFunctions.(rawBodyProvider); ok {
+			raw := rbp.RawBody()
+			contentType := req.Header("Content-Type")
+			body = sync.OnceValue(func() any {
+				if raw == nil {
+					return ""
+				}
+
+				return contenttype.DecodeBody(contentType, raw)
+			})
+		} else {
+			decoded := req.Body()
+			body = func() any { return decoded }
+		}
+
+		snapshot.RequestFunctions = &requestFunctionsSnapshot{
+			headers:      maps.Clone(req.Headers()),
+			body:         body,
+			certificates: req.ClientCertificates(),
+		}
+	}
+
+	return &snapshot
+}
+
+type requestFunctionsSnapshot struct {
+	headers      map[string]string
+	body         func() any
+	certificates []*x509.Certificate
+}
+
+func (r *requestFunctionsSnapshot) Header(name string) string {
+	return r.headers[textproto.CanonicalMIMEHeaderKey(name)]
+}
+
+func (r *requestFunctionsSnapshot) Cookie(name string) string {
+	cookies, err := http.ParseCookie(r.headers["Cookie"])
+	if err != nil {
+		return ""
+	}
+
+	for _, cookie := range cookies {
+		if cookie.Name == name {
+			return cookie.Value
+		}
+	}
+
+	return ""
+}
+
+func (r *requestFunctionsSnapshot) Headers() map[string]string              { return r.headers }
+func (r *requestFunctionsSnapshot) Body() any                               { return r.body() }
+func (r *requestFunctionsSnapshot) ClientCertificates() []*x509.Certificate { return r.certificates }
+
+// shadowCache prefixes all keys, so the mechanisms of the shadow pipeline, like a rate limiting
+// authorizer, neither see nor affect the entries of the execute pipeline.
+type shadowCache struct {
+	cache.Cache
+}
+
+func (c *shadowCache) Get(ctx context.Context, key string) ([]byte, error) {
+	return c.Cache.Get(ctx, shadowCacheKeyPrefix+key)
+}
+
+func (c *shadowCache) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	return c.Cache.Set(ctx, shadowCacheKeyPrefix+key, value, ttl)
+}
+
+func (c *shadowCache) CompareAndSwap(
+	ctx context.Context, key string, old, value []byte, ttl time.Duration,
+) (bool, error) {
+	return c.Cache.CompareAndSwap(ctx, shadowCacheKeyPrefix+key, old, value, ttl)
+}
@@ -0,0 +1,412 @@
+// Copyright 2025 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package rules
+
+import (
+	"context"
+	"errors"
+	"maps"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+
+	"github.com/dadrus/heimdall/internal/cache"
+	"github.com/dadrus/heimdall/internal/cache/memory"
+	"github.com/dadrus/heimdall/internal/heimdall"
+	heimdallmocks "github.com/dadrus/heimdall/internal/heimdall/mocks"
+	"github.com/dadrus/heimdall/internal/rules/mechanisms/subject"
+	"github.com/dadrus/heimdall/internal/rules/mocks"
+)
+
+func TestShadowPipelineExecute(t *testing.T) {
+	t.Parallel()
+
+	testErr := errors.New("test error")
+
+	for uc, tc := range map[string]struct {
+		withAuthenticator bool
+		activeSubject     *subject.Subject
+		activeErr         error
+		configureMocks    func(t *testing.T, authenticator *mocks.SubjectCreatorMock, authorizer *mocks.SubjectHandlerMock)
+		expActive         string
+		expShadow         string
+	}{
+		"shadow pipeline denies request allowed by the execute pipeline": {
+			activeSubject: &subject.Subject{ID: "foo", Attributes: map[string]any{"bar": "baz"}},
+			configureMocks: func(t *testing.T, _ *mocks.SubjectCreatorMock, authorizer *mocks.SubjectHandlerMock) {
+				t.Helper()
+
+				authorizer.EXPECT().ContinueOnError().Return(false)
+				authorizer.EXPECT().Execute(mock.Anything, mock.Anything).Return(testErr)
+			},
+			expActive: decisionAllow,
+			expShadow: decisionDeny,
+		},
+		"shadow pipeline allows request allowed by the execute pipeline": {
+			activeSubject: &subject.Subject{ID: "foo", Attributes: map[string]any{"bar": "baz"}},
+			configureMocks: func(t *testing.T, _ *mocks.SubjectCreatorMock, authorizer *mocks.SubjectHandlerMock) {
+				t.Helper()
+
+				authorizer.EXPECT().Execute(mock.Anything, mock.Anything).
+					Run(func(ctx heimdall.RequestContext, sub *subject.Subject) {
+						// must not affect the actual request handling
+						ctx.AddHeaderForUpstream("X-Foo", "bar")
+						ctx.Outputs()["shadow"] = true
+						sub.Attributes["bar"] = "changed"
+					}).
+					Return(nil)
+			},
+			expActive: decisionAllow,
+			expShadow: decisionAllow,
+		},
+		"authentication failed in the execute pipeline": {
+			activeErr: testErr,
+			configureMocks: func(t *testing.T, _ *mocks.SubjectCreatorMock, _ *mocks.SubjectHandlerMock) {
+				t.Helper()
+			},
+			expActive: decisionDeny,
+			expShadow: decisionDeny,
+		},
+		"shadow pipeline with own authenticator allows request denied by the execute pipeline": {
+			withAuthenticator: true,
+			activeErr:         testErr,
+			configureMocks: func(t *testing.T, authenticator *mocks.SubjectCreatorMock, authorizer *mocks.SubjectHandlerMock) {
+				t.Helper()
+
+				sub := &subject.Subject{ID: "bar"}
+
+				authenticator.EXPECT().Execute(mock.Anything).Return(sub, nil)
+				authorizer.EXPECT().Execute(mock.Anything, sub).Return(nil)
+			},
+			expActive: decisionDeny,
+			expShadow: decisionAllow,
+		},
+	} {
+		t.Run(uc, func(t *testing.T) {
+			// GIVEN
+			reader := sdkmetric.NewManualReader()
+			recorder := tracetest.NewSpanRecorder()
+
+			authenticator := mocks.NewSubjectCreatorMock(t)
+			authorizer := mocks.NewSubjectHandlerMock(t)
+			tc.configureMocks(t, authenticator, authorizer)
+
+			var sc compositeSubjectCreator
+			if tc.withAuthenticator {
+				sc = compositeSubjectCreator{authenticator}
+			}
+
+			shadow, err := newShadowPipeline("test-rule", "test-src", 0, sc, compositeSubjectHandler{authorizer},
+				make(chan struct{}, 1),
+				sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)),
+				sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader)),
+			)
+			require.NoError(t, err)
+
+			outputs := map[string]any{"foo": "bar"}
+
+			ctx := heimdallmocks.NewRequestContextMock(t)
+			ctx.EXPECT().Context().Return(t.Context())
+			ctx.EXPECT().Outputs().Return(outputs)
+			ctx.EXPECT().Request().Return(&heimdall.Request{URL: &heimdall.URL{}})
+
+			var activeAttributes map[string]any
+			if tc.activeSubject != nil {
+				activeAttributes = maps.Clone(tc.activeSubject.Attributes)
+			}
+
+			// WHEN
+			shadow.Execute(ctx, tc.activeSubject, tc.activeErr)
+
+			// THEN
+			require.Eventually(t, func() bool { return len(recorder.Ended()) == 1 }, time.Second, 10*time.Millisecond)
+
+			assert.Equal(t, map[string]any{"foo": "bar"}, outputs)
+
+			if tc.activeSubject != nil {
+				assert.Equal(t, activeAttributes, tc.activeSubject.Attributes)
+			}
+
+			spans := recorder.Ended()
+			require.Len(t, spans, 1)
+			assert.Equal(t, "Shadow Pipeline", spans[0].Name())
+			assert.Contains(t, spans[0].Attributes(), activeDecisionAttrKey.String(tc.expActive))
+			assert.Contains(t, spans[0].Attributes(), shadowDecisionAttrKey.String(tc.expShadow))
+			assert.Contains(t, spans[0].Attributes(), attribute.Bool("decision.divergent", tc.expActive != tc.expShadow))
+
+			var rm metricdata.ResourceMetrics
+
+			require.NoError(t, reader.Collect(t.Context(), &rm))
+
+			if tc.expActive == tc.expShadow {
+				assert.Empty(t, rm.ScopeMetrics)
+
+				return
+			}
+
+			require.Len(t, rm.ScopeMetrics, 1)
+			require.Len(t, rm.ScopeMetrics[0].Metrics, 1)
+
+			metrics := rm.ScopeMetrics[0].Metrics[0]
+			assert.Equal(t, "rule.shadow.divergences", metrics.Name)
+
+			data := metrics.Data.(metricdata.Sum[int64]) //nolint: forcetypeassert
+			require.Len(t, data.DataPoints, 1)
+			assert.Equal(t, int64(1), data.DataPoints[0].Value)
+
+			value, ok := data.DataPoints[0].Attributes.Value(shadowDecisionAttrKey)
+			require.True(t, ok)
+			assert.Equal(t, tc.expShadow, value.AsString())
+
+			value, ok = data.DataPoints[0].Attributes.Value(ruleIDAttrKey)
+			require.True(t, ok)
+			assert.Equal(t, "test-rule", value.AsString())
+		})
+	}
+}
+
+func TestShadowPipelineExecuteDoesNotBlockTheRequest(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	recorder := tracetest.NewSpanRecorder()
+	release := make(chan struct{})
+
+	authorizer := mocks.NewSubjectHandlerMock(t)
+	authorizer.EXPECT().ContinueOnError().Return(false)
+	authorizer.EXPECT().Execute(mock.Anything, mock.Anything).
+		RunAndReturn(func(ctx heimdall.RequestContext, _ *subject.Subject) error {
+			<-release
+
+			_, hasDeadline := ctx.Context().Deadline()
+			assert.True(t, hasDeadline)
+
+			<-ctx.Context().Done()
+
+			return ctx.Context().Err()
+		})
+
+	shadow, err := newShadowPipeline("test-rule", "test-src", 50*time.Millisecond, nil,
+		compositeSubjectHandler{authorizer},
+		make(chan struct{}, 1),
+		sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)),
+		sdkmetric.NewMeterProvider(),
+	)
+	require.NoError(t, err)
+
+	reqCtx, cancel := context.WithCancel(t.Context())
+
+	ctx := heimdallmocks.NewRequestContextMock(t)
+	ctx.EXPECT().Context().Return(reqCtx)
+	ctx.EXPECT().Outputs().Return(map[string]any{})
+	ctx.EXPECT().Request().Return(&heimdall.Request{URL: &heimdall.URL{}})
+
+	// WHEN
+	shadow.Execute(ctx, &subject.Subject{ID: "foo"}, nil)
+
+	// THEN
+	// the request has been handled and its context canceled, while the shadow pipeline still runs
+	cancel()
+	close(release)
+
+	require.Eventually(t, func() bool { return len(recorder.Ended()) == 1 }, time.Second, 10*time.Millisecond)
+	assert.Contains(t, recorder.Ended()[0].Attributes(), shadowDecisionAttrKey.String(decisionDeny))
+}
+
+func TestShadowPipelineExecuteDropsEvaluationsIfSaturated(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	reader := sdkmetric.NewManualReader()
+	recorder := tracetest.NewSpanRecorder()
+
+	// no mechanism is expected to be called
+	authorizer := mocks.NewSubjectHandlerMock(t)
+
+	slots := make(chan struct{}, 1)
+	slots <- struct{}{}
+
+	shadow, err := newShadowPipeline("test-rule", "test-src", 0, nil,
+		compositeSubjectHandler{authorizer},
+		slots,
+		sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)),
+		sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader)),
+	)
+	require.NoError(t, err)
+
+	// the request is not snapshotted, so Request and Outputs are not expected to be called
+	ctx := heimdallmocks.NewRequestContextMock(t)
+	ctx.EXPECT().Context().Return(t.Context())
+
+	// WHEN
+	shadow.Execute(ctx, &subject.Subject{ID: "foo"}, nil)
+
+	// THEN
+	assert.Len(t, slots, 1)
+	assert.Empty(t, recorder.Ended())
+
+	var rm metricdata.ResourceMetrics
+
+	require.NoError(t, reader.Collect(t.Context(), &rm))
+	require.Len(t, rm.ScopeMetrics, 1)
+	require.Len(t, rm.ScopeMetrics[0].Metrics, 1)
+
+	metrics := rm.ScopeMetrics[0].Metrics[0]
+	assert.Equal(t, "rule.shadow.dropped", metrics.Name)
+
+	data := metrics.Data.(metricdata.Sum[int64]) //nolint: forcetypeassert
+	require.Len(t, data.DataPoints, 1)
+	assert.Equal(t, int64(1), data.DataPoints[0].Value)
+
+	value, ok := data.DataPoints[0].Attributes.Value(ruleIDAttrKey)
+	require.True(t, ok)
+	assert.Equal(t, "test-rule", value.AsString())
+}
+
+func TestShadowPipelineExecuteIsolatesCacheEntries(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	recorder := tracetest.NewSpanRecorder()
+
+	cch, err := memory.NewCache(nil, nil)
+	require.NoError(t, err)
+
+	authorizer := mocks.NewSubjectHandlerMock(t)
+	authorizer.EXPECT().Execute(mock.Anything, mock.Anything).
+		RunAndReturn(func(ctx heimdall.RequestContext, _ *subject.Subject) error {
+			shadowCch := cache.Ctx(ctx.Context())
+
+			// the entry of the execute pipeline is not visible
+			_, err := shadowCch.Get(ctx.Context(), "counter")
+			assert.Error(t, err) //nolint:testifylint
+
+			stored, err := shadowCch.CompareAndSwap(ctx.Context(), "counter", nil, []byte("shadow"), time.Minute)
+			assert.NoError(t, err) //nolint:testifylint
+			assert.True(t, stored)
+
+			return shadowCch.Set(ctx.Context(), "other", []byte("shadow"), time.Minute)
+		})
+
+	shadow, err := newShadowPipeline("test-rule", "test-src", 0, nil,
+		compositeSubjectHandler{authorizer},
+		make(chan struct{}, 1),
+		sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)),
+		sdkmetric.NewMeterProvider(),
+	)
+	require.NoError(t, err)
+
+	require.NoError(t, cch.Set(t.Context(), "counter", []byte("live"), time.Minute))
+
+	ctx := heimdallmocks.NewRequestContextMock(t)
+	ctx.EXPECT().Context().Return(cache.WithContext(t.Context(), cch))
+	ctx.EXPECT().Outputs().Return(map[string]any{})
+	ctx.EXPECT().Request().Return(&heimdall.Request{URL: &heimdall.URL{}})
+
+	// WHEN
+	shadow.Execute(ctx, &subject.Subject{ID: "foo"}, nil)
+
+	// THEN
+	require.Eventually(t, func() bool { return len(recorder.Ended()) == 1 }, time.Second, 10*time.Millisecond)
+
+	value, err := cch.Get(t.Context(), "counter")
+	require.NoError(t, err)
+	assert.Equal(t, []byte("live"), value)
+
+	value, err = cch.Get(t.Context(), "other")
+	require.Error(t, err)
+	assert.Nil(t, value)
+
+	value, err = cch.Get(t.Context(), "shadow:counter")
+	require.NoError(t, err)
+	assert.Equal(t, []byte("shadow"), value)
+}
+
+func TestSnapshotRequest(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	reqf := heimdallmocks.NewRequestFunctionsMock(t)
+	reqf.EXPECT().Headers().Return(map[string]string{"X-Foo": "bar", "Cookie": "foo=bar; baz=zab"})
+	reqf.EXPECT().Body().Return(map[string]any{"foo": "bar"})
+	reqf.EXPECT().ClientCertificates().Return(nil)
+
+	req := &heimdall.Request{
+		RequestFunctions:  reqf,
+		Method:            "POST",
+		URL:               &heimdall.URL{Captures: map[string]string{"foo": "bar"}},
+		ClientIPAddresses: []string{"127.0.0.1"},
+		Metadata:          map[string]any{"foo": "bar"},
+	}
+
+	// WHEN
+	snapshot := snapshotRequest(req)
+
+	// THEN
+	req.URL.Captures["foo"] = "baz"
+	req.ClientIPAddresses[0] = "10.0.0.1"
+	req.Metadata["foo"] = "baz"
+
+	assert.Equal(t, "POST", snapshot.Method)
+	assert.Equal(t, map[string]string{"foo": "bar"}, snapshot.URL.Captures)
+	assert.Equal(t, []string{"127.0.0.1"}, snapshot.ClientIPAddresses)
+	assert.Equal(t, map[string]any{"foo": "bar"}, snapshot.Metadata)
+	assert.Equal(t, "bar", snapshot.Header("x-foo"))
+	assert.Equal(t, "zab", snapshot.Cookie("baz"))
+	assert.Empty(t, snapshot.Cookie("bar"))
+	assert.Equal(t, map[string]any{"foo": "bar"}, snapshot.Body())
+	assert.Nil(t, snapshot.ClientCertificates())
+}
+
+func TestSnapshotRequestDecodesRawBodyLazily(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	reqf := &rawBodyRequestFunctions{
+		RequestFunctionsMock: heimdallmocks.NewRequestFunctionsMock(t),
+		raw:                  []byte(`{"foo":"bar"}`),
+	}
+	reqf.EXPECT().Headers().Return(map[string]string{"Content-Type": "application/json"})
+	reqf.EXPECT().Header("Content-Type").Return("application/json")
+	reqf.EXPECT().ClientCertificates().Return(nil)
+
+	req := &heimdall.Request{RequestFunctions: reqf, URL: &heimdall.URL{}}
+
+	// WHEN
+	snapshot := snapshotRequest(req)
+
+	// THEN
+	// Body of the actual request is never used, as the raw body is decoded on first use only
+	assert.Equal(t, map[string]any{"foo": "bar"}, snapshot.Body())
+	assert.Equal(t, map[string]any{"foo": "bar"}, snapshot.Body())
+}
+
+type rawBodyRequestFunctions struct {
+	*heimdallmocks.RequestFunctionsMock
+
+	raw []byte
+}
+
+func (r *rawBodyRequestFunctions) RawBody() []byte { return r.raw }
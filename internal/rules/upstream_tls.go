@@ -0,0 +1,114 @@
+// Copyright 2025 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package rules
+
+import (
+	"crypto/tls"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/dadrus/heimdall/internal/config"
+	"github.com/dadrus/heimdall/internal/heimdall"
+	config2 "github.com/dadrus/heimdall/internal/rules/config"
+	"github.com/dadrus/heimdall/internal/truststore"
+	"github.com/dadrus/heimdall/internal/x/errorchain"
+	"github.com/dadrus/heimdall/internal/x/tlsx"
+)
+
+// createUpstreamTLSConfig creates the TLS configuration for the communication with the upstream
+// service, so that errors in the referenced key material are detected while loading the rule.
+// The key and trust stores must be located in one of the allowed paths.
+func createUpstreamTLSConfig(backend *config2.Backend, allowedPaths []string) (*tls.Config, error) {
+	if backend == nil || backend.Transport == nil || backend.Transport.TLS == nil {
+		return nil, nil //nolint:nilnil
+	}
+
+	conf := backend.Transport.TLS
+	tlsConf := &config.TLS{KeyID: conf.KeyID, MinVersion: tls.VersionTLS12}
+
+	if conf.KeyStore != nil {
+		if err := verifyKeyMaterialPath(conf.KeyStore.Path, allowedPaths); err != nil {
+			return nil, err
+		}
+
+		tlsConf.KeyStore = config.KeyStore{Path: conf.KeyStore.Path, Password: conf.KeyStore.Password}
+	}
+
+	tlsCfg, err := tlsx.ToTLSConfig(tlsConf, tlsx.WithClientAuthentication(conf.KeyStore != nil))
+	if err != nil {
+		return nil, errorchain.NewWithMessage(heimdall.ErrConfiguration,
+			"failed to create tls configuration for upstream communication").CausedBy(err)
+	}
+
+	if conf.TrustStore != nil {
+		if err = verifyKeyMaterialPath(conf.TrustStore.Path, allowedPaths); err != nil {
+			return nil, err
+		}
+
+		ts, err := truststore.NewTrustStoreFromPEMFile(conf.TrustStore.Path, true)
+		if err != nil {
+			return nil, errorchain.NewWithMessage(heimdall.ErrConfiguration,
+				"failed loading trust store for upstream communication").CausedBy(err)
+		}
+
+		tlsCfg.RootCAs = ts.CertPool()
+	}
+
+	return tlsCfg, nil
+}
+
+func verifyKeyMaterialPath(path string, allowedPaths []string) error {
+	// symbolic links are resolved to prevent references to files outside the allowed paths
+	resolved, err := filepath.EvalSymlinks(path)
+	if err != nil {
+		return errorchain.NewWithMessagef(heimdall.ErrConfiguration,
+			"failed resolving upstream tls key material path %s", path).CausedBy(err)
+	}
+
+	resolved, err = filepath.Abs(resolved)
+	if err != nil {
+		return errorchain.NewWithMessagef(heimdall.ErrConfiguration,
+			"failed resolving upstream tls key material path %s", path).CausedBy(err)
+	}
+
+	if !slices.ContainsFunc(allowedPaths, func(allowed string) bool { return isWithin(resolved, allowed) }) {
+		return errorchain.NewWithMessagef(heimdall.ErrConfiguration,
+			"upstream tls key material %s is not located in any of the allowed paths", path)
+	}
+
+	return nil
+}
+
+func isWithin(path, allowed string) bool {
+	allowed, err := filepath.EvalSymlinks(allowed)
+	if err != nil {
+		return false
+	}
+
+	allowed, err = filepath.Abs(allowed)
+	if err != nil {
+		return false
+	}
+
+	rel, err := filepath.Rel(allowed, path)
+	if err != nil {
+		return false
+	}
+
+	return rel == "." || (rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)))
+}
@@ -0,0 +1,270 @@
+// Copyright 2025 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package rules
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dadrus/heimdall/internal/heimdall"
+	config2 "github.com/dadrus/heimdall/internal/rules/config"
+	"github.com/dadrus/heimdall/internal/x/pkix/pemx"
+	"github.com/dadrus/heimdall/internal/x/testsupport"
+)
+
+func writePEMFile(t *testing.T, path string, opts ...pemx.EntryOption) {
+	t.Helper()
+
+	pemBytes, err := pemx.BuildPEM(opts...)
+	require.NoError(t, err)
+
+	require.NoError(t, os.WriteFile(path, pemBytes, 0o600))
+}
+
+func newSelfSignedCertificate(
+	t *testing.T,
+	cn string,
+	usage x509.ExtKeyUsage,
+) (*ecdsa.PrivateKey, *x509.Certificate) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	require.NoError(t, err)
+
+	cert, err := testsupport.NewCertificateBuilder(
+		testsupport.WithSerialNumber(big.NewInt(1)),
+		testsupport.WithValidity(time.Now(), 10*time.Hour),
+		testsupport.WithSubject(pkix.Name{CommonName: cn, Organization: []string{"Test"}, Country: []string{"EU"}}),
+		testsupport.WithSubjectPubKey(&key.PublicKey, x509.ECDSAWithSHA384),
+		testsupport.WithSignaturePrivKey(key),
+		testsupport.WithKeyUsage(x509.KeyUsageDigitalSignature),
+		testsupport.WithExtendedKeyUsage(usage),
+		testsupport.WithGeneratedSubjectKeyID(),
+		testsupport.WithIPAddresses([]net.IP{net.ParseIP("127.0.0.1")}),
+		testsupport.WithSelfSigned(),
+	).Build()
+	require.NoError(t, err)
+
+	return key, cert
+}
+
+func TestCreateUpstreamTLSConfig(t *testing.T) {
+	t.Parallel()
+
+	testDir := t.TempDir()
+
+	serverKey, serverCert := newSelfSignedCertificate(t, "server", x509.ExtKeyUsageServerAuth)
+	clientKey, clientCert := newSelfSignedCertificate(t, "client", x509.ExtKeyUsageClientAuth)
+
+	trustStorePath := filepath.Join(testDir, "truststore.pem")
+	keyStorePath := filepath.Join(testDir, "keystore.pem")
+
+	writePEMFile(t, trustStorePath, pemx.WithX509Certificate(serverCert))
+	writePEMFile(t, keyStorePath, pemx.WithECDSAPrivateKey(clientKey), pemx.WithX509Certificate(clientCert))
+
+	clientCAs := x509.NewCertPool()
+	clientCAs.AddCert(clientCert)
+
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		if len(req.TLS.PeerCertificates) == 0 || req.TLS.PeerCertificates[0].Subject.CommonName != "client" {
+			rw.WriteHeader(http.StatusForbidden)
+
+			return
+		}
+
+		rw.WriteHeader(http.StatusOK)
+	}))
+	srv.TLS = &tls.Config{
+		Certificates: []tls.Certificate{{Certificate: [][]byte{serverCert.Raw}, PrivateKey: serverKey}},
+		ClientCAs:    clientCAs,
+		ClientAuth:   tls.VerifyClientCertIfGiven,
+		MinVersion:   tls.VersionTLS12,
+	}
+	srv.StartTLS()
+	defer srv.Close()
+
+	for uc, tc := range map[string]struct {
+		conf   *config2.UpstreamTLS
+		assert func(t *testing.T, err error, resp *http.Response)
+	}{
+		"server not trusted": {
+			conf: &config2.UpstreamTLS{},
+			assert: func(t *testing.T, err error, _ *http.Response) {
+				t.Helper()
+
+				var certErr *tls.CertificateVerificationError
+				require.ErrorAs(t, err, &certErr)
+			},
+		},
+		"server trusted, but no client certificate": {
+			conf: &config2.UpstreamTLS{TrustStore: &config2.TrustStore{Path: trustStorePath}},
+			assert: func(t *testing.T, err error, resp *http.Response) {
+				t.Helper()
+
+				require.NoError(t, err)
+				assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+			},
+		},
+		"mutual tls": {
+			conf: &config2.UpstreamTLS{
+				TrustStore: &config2.TrustStore{Path: trustStorePath},
+				KeyStore:   &config2.KeyStore{Path: keyStorePath},
+			},
+			assert: func(t *testing.T, err error, resp *http.Response) {
+				t.Helper()
+
+				require.NoError(t, err)
+				assert.Equal(t, http.StatusOK, resp.StatusCode)
+			},
+		},
+	} {
+		t.Run(uc, func(t *testing.T) {
+			// GIVEN
+			tlsCfg, err := createUpstreamTLSConfig(
+				&config2.Backend{Transport: &config2.Transport{TLS: tc.conf}}, []string{testDir})
+			require.NoError(t, err)
+
+			rt := &http.Transport{TLSClientConfig: tlsCfg}
+
+			req, err := http.NewRequestWithContext(t.Context(), http.MethodGet, srv.URL, nil)
+			require.NoError(t, err)
+
+			// WHEN
+			resp, err := rt.RoundTrip(req)
+			if err == nil {
+				defer resp.Body.Close()
+			}
+
+			// THEN
+			tc.assert(t, err, resp)
+		})
+	}
+}
+
+func TestCreateUpstreamTLSConfigWithInvalidSettings(t *testing.T) {
+	t.Parallel()
+
+	testDir := t.TempDir()
+	otherDir := t.TempDir()
+
+	key, cert := newSelfSignedCertificate(t, "client", x509.ExtKeyUsageClientAuth)
+
+	keyStorePath := filepath.Join(testDir, "keystore.pem")
+	otherKeyStorePath := filepath.Join(otherDir, "keystore.pem")
+	linkPath := filepath.Join(testDir, "link.pem")
+
+	writePEMFile(t, keyStorePath, pemx.WithECDSAPrivateKey(key), pemx.WithX509Certificate(cert))
+	writePEMFile(t, otherKeyStorePath, pemx.WithECDSAPrivateKey(key), pemx.WithX509Certificate(cert))
+	require.NoError(t, os.Symlink(otherKeyStorePath, linkPath))
+
+	for uc, tc := range map[string]struct {
+		conf         *config2.UpstreamTLS
+		allowedPaths []string
+		msg          string
+	}{
+		"missing key store": {
+			conf:         &config2.UpstreamTLS{KeyStore: &config2.KeyStore{Path: filepath.Join(testDir, "missing.pem")}},
+			allowedPaths: []string{testDir},
+			msg:          "failed resolving upstream tls key material path",
+		},
+		"missing trust store": {
+			conf:         &config2.UpstreamTLS{TrustStore: &config2.TrustStore{Path: filepath.Join(testDir, "missing.pem")}},
+			allowedPaths: []string{testDir},
+			msg:          "failed resolving upstream tls key material path",
+		},
+		"malformed trust store": {
+			conf:         &config2.UpstreamTLS{TrustStore: &config2.TrustStore{Path: keyStorePath}},
+			allowedPaths: []string{testDir},
+			msg:          "failed loading trust store",
+		},
+		"key store with unknown key id": {
+			conf:         &config2.UpstreamTLS{KeyStore: &config2.KeyStore{Path: keyStorePath}, KeyID: "foo"},
+			allowedPaths: []string{testDir},
+			msg:          "failed to create tls configuration",
+		},
+		"key material without allowed paths": {
+			conf: &config2.UpstreamTLS{KeyStore: &config2.KeyStore{Path: keyStorePath}},
+			msg:  "not located in any of the allowed paths",
+		},
+		"key store outside of the allowed paths": {
+			conf:         &config2.UpstreamTLS{KeyStore: &config2.KeyStore{Path: otherKeyStorePath}},
+			allowedPaths: []string{testDir},
+			msg:          "not located in any of the allowed paths",
+		},
+		"trust store outside of the allowed paths": {
+			conf:         &config2.UpstreamTLS{TrustStore: &config2.TrustStore{Path: otherKeyStorePath}},
+			allowedPaths: []string{testDir},
+			msg:          "not located in any of the allowed paths",
+		},
+		"key store path escaping the allowed paths": {
+			conf: &config2.UpstreamTLS{
+				KeyStore: &config2.KeyStore{Path: testDir + "/../" + filepath.Base(otherDir) + "/keystore.pem"},
+			},
+			allowedPaths: []string{testDir},
+			msg:          "not located in any of the allowed paths",
+		},
+		"key store linked from outside of the allowed paths": {
+			conf:         &config2.UpstreamTLS{KeyStore: &config2.KeyStore{Path: linkPath}},
+			allowedPaths: []string{testDir},
+			msg:          "not located in any of the allowed paths",
+		},
+	} {
+		t.Run(uc, func(t *testing.T) {
+			// WHEN
+			_, err := createUpstreamTLSConfig(
+				&config2.Backend{Transport: &config2.Transport{TLS: tc.conf}}, tc.allowedPaths)
+
+			// THEN
+			require.ErrorIs(t, err, heimdall.ErrConfiguration)
+			require.ErrorContains(t, err, tc.msg)
+		})
+	}
+}
+
+func TestCreateUpstreamTLSConfigWithoutTLSSettings(t *testing.T) {
+	t.Parallel()
+
+	for uc, backend := range map[string]*config2.Backend{
+		"without backend":   nil,
+		"without transport": {},
+		"without tls":       {Transport: &config2.Transport{}},
+	} {
+		t.Run(uc, func(t *testing.T) {
+			// WHEN
+			tlsCfg, err := createUpstreamTLSConfig(backend, nil)
+
+			// THEN
+			require.NoError(t, err)
+			assert.Nil(t, tlsCfg)
+		})
+	}
+}
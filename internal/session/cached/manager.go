@@ -0,0 +1,338 @@
+// Copyright 2025 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package cached
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/go-jose/go-jose/v4"
+	"github.com/goccy/go-json"
+	"github.com/rs/zerolog"
+
+	"github.com/dadrus/heimdall/internal/cache"
+	"github.com/dadrus/heimdall/internal/config"
+	"github.com/dadrus/heimdall/internal/heimdall"
+	"github.com/dadrus/heimdall/internal/keystore"
+	"github.com/dadrus/heimdall/internal/rules/mechanisms/subject"
+	"github.com/dadrus/heimdall/internal/session"
+	"github.com/dadrus/heimdall/internal/watcher"
+	"github.com/dadrus/heimdall/internal/x"
+	"github.com/dadrus/heimdall/internal/x/errorchain"
+	"github.com/dadrus/heimdall/internal/x/stringx"
+)
+
+const (
+	defaultLifespan = 24 * time.Hour
+	// rotationGracePeriod defines how long a rotated session is still usable to
+	// not break requests sent concurrently by the client with the previous id.
+	rotationGracePeriod = 10 * time.Second
+	sessionIDLength     = 32
+)
+
+type manager struct {
+	path     string
+	password string
+	keyID    string
+	lifespan time.Duration
+
+	mut sync.RWMutex
+	ks  keystore.KeyStore
+	key *keystore.Entry
+}
+
+// NewManager creates a session manager, which keeps the sessions encrypted in the cache
+// available in the context. The encryption key is taken from the configured key store.
+func NewManager(conf *config.SessionConfig, fw watcher.Watcher) (session.Manager, error) {
+	mgr := &manager{
+		path:     conf.KeyStore.Path,
+		password: conf.KeyStore.Password,
+		keyID:    conf.KeyID,
+		lifespan: x.IfThenElse(conf.Lifespan > 0, conf.Lifespan, defaultLifespan),
+	}
+
+	if err := mgr.load(); err != nil {
+		return nil, err
+	}
+
+	if err := fw.Add(mgr.path, mgr); err != nil {
+		return nil, errorchain.NewWithMessage(heimdall.ErrInternal,
+			"failed registering session manager for updates").CausedBy(err)
+	}
+
+	return mgr, nil
+}
+
+func (m *manager) OnChanged(logger zerolog.Logger) {
+	if err := m.load(); err != nil {
+		logger.Warn().Err(err).
+			Str("_file", m.path).
+			Msg("Session key store reload failed")
+	} else {
+		logger.Info().
+			Str("_file", m.path).
+			Msg("Session key store reloaded")
+	}
+}
+
+func (m *manager) load() error {
+	ks, err := keystore.NewKeyStoreFromPEMFile(m.path, m.password)
+	if err != nil {
+		return errorchain.NewWithMessage(heimdall.ErrConfiguration, "failed loading session keystore").
+			CausedBy(err)
+	}
+
+	var kse *keystore.Entry
+
+	if len(m.keyID) == 0 {
+		kse, err = ks.Entries()[0], nil
+	} else {
+		kse, err = ks.GetKey(m.keyID)
+	}
+
+	if err != nil {
+		return errorchain.NewWithMessage(heimdall.ErrConfiguration,
+			"failed retrieving session key from key store").CausedBy(err)
+	}
+
+	m.mut.Lock()
+	defer m.mut.Unlock()
+
+	m.ks = ks
+	m.key = kse
+
+	return nil
+}
+
+func (m *manager) Create(
+	ctx context.Context,
+	sub *subject.Subject,
+	refreshMaterial map[string]string,
+) (*session.Session, error) {
+	now := time.Now().UTC()
+	sess := &session.Session{
+		ID:              randomID(),
+		Subject:         sub,
+		RefreshMaterial: refreshMaterial,
+		CreatedAt:       now,
+		RotatedAt:       now,
+		ExpiresAt:       now.Add(m.lifespan),
+	}
+
+	if err := m.store(ctx, sess, m.lifespan); err != nil {
+		return nil, err
+	}
+
+	return sess, nil
+}
+
+func (m *manager) Load(ctx context.Context, id string) (*session.Session, error) {
+	cch := cache.Ctx(ctx)
+
+	data, err := cch.Get(ctx, cacheKey(id))
+	if err != nil || len(data) == 0 {
+		return nil, session.ErrNoSession
+	}
+
+	sess, err := m.open(data)
+	if err != nil {
+		zerolog.Ctx(ctx).Warn().Err(err).Msg("Failed to decrypt session")
+
+		return nil, session.ErrNoSession
+	}
+
+	if !time.Now().Before(sess.ExpiresAt) {
+		return nil, session.ErrNoSession
+	}
+
+	// sessions created before the revocation of all sessions of the subject are not valid anymore
+	if data, err = cch.Get(ctx, subjectKey(sess.Subject.ID)); err == nil && len(data) != 0 {
+		revokedAt, err := strconv.ParseInt(stringx.ToString(data), 10, 64)
+		if err == nil && !sess.CreatedAt.After(time.Unix(0, revokedAt)) {
+			return nil, session.ErrNoSession
+		}
+	}
+
+	sess.ID = id
+
+	return sess, nil
+}
+
+func (m *manager) Rotate(ctx context.Context, sess *session.Session) (*session.Session, error) {
+	ttl := time.Until(sess.ExpiresAt)
+	if ttl <= 0 {
+		return nil, session.ErrNoSession
+	}
+
+	rotated := *sess
+	rotated.ID = randomID()
+	rotated.RotatedAt = time.Now().UTC()
+
+	if err := m.store(ctx, &rotated, ttl); err != nil {
+		return nil, err
+	}
+
+	if err := m.store(ctx, sess, min(ttl, rotationGracePeriod)); err != nil {
+		return nil, err
+	}
+
+	return &rotated, nil
+}
+
+func (m *manager) Update(ctx context.Context, sess *session.Session) error {
+	ttl := time.Until(sess.ExpiresAt)
+	if ttl <= 0 {
+		return session.ErrNoSession
+	}
+
+	// a revoked session must not be brought back to life
+	if _, err := m.Load(ctx, sess.ID); err != nil {
+		return err
+	}
+
+	return m.store(ctx, sess, ttl)
+}
+
+func (m *manager) Revoke(ctx context.Context, id string) error {
+	// the cache does not support deletion. So the entry is overwritten instead
+	if err := cache.Ctx(ctx).Set(ctx, cacheKey(id), []byte{}, time.Second); err != nil {
+		return errorchain.NewWithMessage(heimdall.ErrInternal, "failed revoking session").CausedBy(err)
+	}
+
+	return nil
+}
+
+func (m *manager) RevokeAll(ctx context.Context, subjectID string) error {
+	// there is no need to keep the marker longer than a session can live
+	revokedAt := strconv.FormatInt(time.Now().UnixNano(), 10)
+	if err := cache.Ctx(ctx).Set(ctx, subjectKey(subjectID), stringx.ToBytes(revokedAt), m.lifespan); err != nil {
+		return errorchain.NewWithMessage(heimdall.ErrInternal, "failed revoking sessions").CausedBy(err)
+	}
+
+	return nil
+}
+
+func (m *manager) store(ctx context.Context, sess *session.Session, ttl time.Duration) error {
+	data, err := m.seal(sess)
+	if err != nil {
+		return err
+	}
+
+	if err = cache.Ctx(ctx).Set(ctx, cacheKey(sess.ID), data, ttl); err != nil {
+		return errorchain.NewWithMessage(heimdall.ErrInternal, "failed storing session").CausedBy(err)
+	}
+
+	return nil
+}
+
+func (m *manager) seal(sess *session.Session) ([]byte, error) {
+	m.mut.RLock()
+	key := m.key
+	m.mut.RUnlock()
+
+	encrypter, err := jose.NewEncrypter(jose.A256GCM,
+		jose.Recipient{
+			Algorithm: x.IfThenElse(key.Alg == keystore.AlgRSA, jose.RSA_OAEP_256, jose.ECDH_ES_A256KW),
+			Key:       key.PrivateKey.Public(),
+			KeyID:     key.KeyID,
+		}, nil)
+	if err != nil {
+		return nil, errorchain.NewWithMessage(heimdall.ErrInternal, "failed creating session encrypter").
+			CausedBy(err)
+	}
+
+	raw, err := json.Marshal(sess)
+	if err != nil {
+		return nil, errorchain.NewWithMessage(heimdall.ErrInternal, "failed marshalling session").
+			CausedBy(err)
+	}
+
+	obj, err := encrypter.Encrypt(raw)
+	if err != nil {
+		return nil, errorchain.NewWithMessage(heimdall.ErrInternal, "failed encrypting session").
+			CausedBy(err)
+	}
+
+	token, err := obj.CompactSerialize()
+	if err != nil {
+		return nil, errorchain.NewWithMessage(heimdall.ErrInternal, "failed serializing session").
+			CausedBy(err)
+	}
+
+	return stringx.ToBytes(token), nil
+}
+
+func (m *manager) open(data []byte) (*session.Session, error) {
+	obj, err := jose.ParseEncrypted(stringx.ToString(data),
+		[]jose.KeyAlgorithm{jose.RSA_OAEP_256, jose.ECDH_ES_A256KW},
+		[]jose.ContentEncryption{jose.A256GCM},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	m.mut.RLock()
+	ks := m.ks
+	m.mut.RUnlock()
+
+	// the key used for encryption might not be the current one after a key store update
+	entry, err := ks.GetKey(obj.Header.KeyID)
+	if err != nil {
+		return nil, err
+	}
+
+	raw, err := obj.Decrypt(entry.PrivateKey)
+	if err != nil {
+		return nil, err
+	}
+
+	var sess session.Session
+	if err = json.Unmarshal(raw, &sess); err != nil {
+		return nil, err
+	}
+
+	if sess.Subject == nil {
+		return nil, session.ErrNoSession
+	}
+
+	return &sess, nil
+}
+
+func cacheKey(id string) string {
+	hash := sha256.Sum256(stringx.ToBytes(id))
+
+	return "session:" + hex.EncodeToString(hash[:])
+}
+
+func subjectKey(subjectID string) string {
+	hash := sha256.Sum256(stringx.ToBytes(subjectID))
+
+	return "session:subject:" + hex.EncodeToString(hash[:])
+}
+
+func randomID() string {
+	buf := make([]byte, sessionIDLength)
+	_, _ = rand.Read(buf)
+
+	return base64.RawURLEncoding.EncodeToString(buf)
+}
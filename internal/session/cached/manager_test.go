@@ -0,0 +1,391 @@
+// Copyright 2025 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package cached
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dadrus/heimdall/internal/cache"
+	"github.com/dadrus/heimdall/internal/cache/memory"
+	"github.com/dadrus/heimdall/internal/config"
+	"github.com/dadrus/heimdall/internal/heimdall"
+	"github.com/dadrus/heimdall/internal/rules/mechanisms/subject"
+	"github.com/dadrus/heimdall/internal/session"
+	"github.com/dadrus/heimdall/internal/watcher"
+	"github.com/dadrus/heimdall/internal/x/pkix/pemx"
+)
+
+func writeKeyStore(t *testing.T) string {
+	t.Helper()
+
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	pemBytes, err := pemx.BuildPEM(
+		pemx.WithECDSAPrivateKey(ecKey, pemx.WithHeader("X-Key-ID", "ec")),
+		pemx.WithRSAPrivateKey(rsaKey, pemx.WithHeader("X-Key-ID", "rsa")),
+	)
+	require.NoError(t, err)
+
+	path := filepath.Join(t.TempDir(), "keystore.pem")
+	require.NoError(t, os.WriteFile(path, pemBytes, 0o600))
+
+	return path
+}
+
+func newTestContext(t *testing.T) context.Context {
+	t.Helper()
+
+	cch, err := memory.NewCache(nil, nil)
+	require.NoError(t, err)
+
+	return cache.WithContext(log.Logger.WithContext(t.Context()), cch)
+}
+
+func TestNewManager(t *testing.T) {
+	t.Parallel()
+
+	path := writeKeyStore(t)
+
+	for uc, tc := range map[string]struct {
+		conf   *config.SessionConfig
+		assert func(t *testing.T, err error, mgr *manager)
+	}{
+		"not existing key store": {
+			conf: &config.SessionConfig{KeyStore: config.KeyStore{Path: "/does/not/exist.pem"}},
+			assert: func(t *testing.T, err error, _ *manager) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrConfiguration)
+				require.ErrorContains(t, err, "failed loading session keystore")
+			},
+		},
+		"not existing key": {
+			conf: &config.SessionConfig{KeyStore: config.KeyStore{Path: path}, KeyID: "foo"},
+			assert: func(t *testing.T, err error, _ *manager) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrConfiguration)
+				require.ErrorContains(t, err, "failed retrieving session key")
+			},
+		},
+		"with defaults": {
+			conf: &config.SessionConfig{KeyStore: config.KeyStore{Path: path}},
+			assert: func(t *testing.T, err error, mgr *manager) {
+				t.Helper()
+
+				require.NoError(t, err)
+				assert.Equal(t, defaultLifespan, mgr.lifespan)
+				assert.Equal(t, "ec", mgr.key.KeyID)
+			},
+		},
+		"with key id and lifespan": {
+			conf: &config.SessionConfig{KeyStore: config.KeyStore{Path: path}, KeyID: "rsa", Lifespan: time.Hour},
+			assert: func(t *testing.T, err error, mgr *manager) {
+				t.Helper()
+
+				require.NoError(t, err)
+				assert.Equal(t, time.Hour, mgr.lifespan)
+				assert.Equal(t, "rsa", mgr.key.KeyID)
+			},
+		},
+	} {
+		t.Run(uc, func(t *testing.T) {
+			// WHEN
+			mgr, err := NewManager(tc.conf, &watcher.NoopWatcher{})
+
+			// THEN
+			impl, _ := mgr.(*manager)
+			tc.assert(t, err, impl)
+		})
+	}
+}
+
+func TestManagerSessionLifecycle(t *testing.T) {
+	t.Parallel()
+
+	path := writeKeyStore(t)
+
+	for _, keyID := range []string{"ec", "rsa"} {
+		t.Run(keyID, func(t *testing.T) {
+			// GIVEN
+			ctx := newTestContext(t)
+			sub := &subject.Subject{ID: "foo", Attributes: map[string]any{"bar": "baz"}}
+
+			mgr, err := NewManager(
+				&config.SessionConfig{KeyStore: config.KeyStore{Path: path}, KeyID: keyID},
+				&watcher.NoopWatcher{},
+			)
+			require.NoError(t, err)
+
+			// WHEN
+			created, err := mgr.Create(ctx, sub, map[string]string{"refresh_token": "secret"})
+			require.NoError(t, err)
+
+			loaded, err := mgr.Load(ctx, created.ID)
+			require.NoError(t, err)
+
+			rotated, err := mgr.Rotate(ctx, loaded)
+			require.NoError(t, err)
+
+			// THEN
+			assert.Equal(t, created.ID, loaded.ID)
+			assert.Equal(t, sub, loaded.Subject)
+			assert.Equal(t, map[string]string{"refresh_token": "secret"}, loaded.RefreshMaterial)
+			assert.WithinDuration(t, created.CreatedAt.Add(defaultLifespan), loaded.ExpiresAt, time.Second)
+
+			assert.NotEqual(t, created.ID, rotated.ID)
+			assert.Equal(t, loaded.CreatedAt, rotated.CreatedAt)
+			assert.Equal(t, loaded.ExpiresAt, rotated.ExpiresAt)
+			assert.True(t, rotated.RotatedAt.After(loaded.RotatedAt))
+
+			loaded, err = mgr.Load(ctx, rotated.ID)
+			require.NoError(t, err)
+			assert.Equal(t, sub, loaded.Subject)
+
+			// the previous session is still usable during the grace period
+			_, err = mgr.Load(ctx, created.ID)
+			require.NoError(t, err)
+
+			require.NoError(t, mgr.Revoke(ctx, rotated.ID))
+
+			_, err = mgr.Load(ctx, rotated.ID)
+			require.ErrorIs(t, err, session.ErrNoSession)
+		})
+	}
+}
+
+func TestManagerUpdateSession(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	ctx := newTestContext(t)
+
+	mgr, err := NewManager(
+		&config.SessionConfig{KeyStore: config.KeyStore{Path: writeKeyStore(t)}},
+		&watcher.NoopWatcher{},
+	)
+	require.NoError(t, err)
+
+	sess, err := mgr.Create(ctx, &subject.Subject{ID: "foo"}, map[string]string{"token": "foo"})
+	require.NoError(t, err)
+
+	// WHEN
+	sess.RefreshMaterial["token"] = "bar"
+	err = mgr.Update(ctx, sess)
+
+	// THEN
+	require.NoError(t, err)
+
+	loaded, err := mgr.Load(ctx, sess.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "bar", loaded.RefreshMaterial["token"])
+
+	// WHEN
+	require.NoError(t, mgr.Revoke(ctx, sess.ID))
+	err = mgr.Update(ctx, sess)
+
+	// THEN
+	require.ErrorIs(t, err, session.ErrNoSession)
+
+	_, err = mgr.Load(ctx, sess.ID)
+	require.ErrorIs(t, err, session.ErrNoSession)
+
+	// WHEN
+	other, err := mgr.Create(ctx, &subject.Subject{ID: "bar"}, nil)
+	require.NoError(t, err)
+
+	require.NoError(t, mgr.RevokeAll(ctx, "bar"))
+	err = mgr.Update(ctx, other)
+
+	// THEN
+	require.ErrorIs(t, err, session.ErrNoSession)
+}
+
+func TestManagerRevokeAllSessionsOfSubject(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	ctx := newTestContext(t)
+
+	mgr, err := NewManager(
+		&config.SessionConfig{KeyStore: config.KeyStore{Path: writeKeyStore(t)}},
+		&watcher.NoopWatcher{},
+	)
+	require.NoError(t, err)
+
+	sess1, err := mgr.Create(ctx, &subject.Subject{ID: "foo"}, nil)
+	require.NoError(t, err)
+
+	sess2, err := mgr.Create(ctx, &subject.Subject{ID: "foo"}, nil)
+	require.NoError(t, err)
+
+	sess3, err := mgr.Create(ctx, &subject.Subject{ID: "bar"}, nil)
+	require.NoError(t, err)
+
+	// WHEN
+	err = mgr.RevokeAll(ctx, "foo")
+
+	// THEN
+	require.NoError(t, err)
+
+	_, err = mgr.Load(ctx, sess1.ID)
+	require.ErrorIs(t, err, session.ErrNoSession)
+
+	_, err = mgr.Load(ctx, sess2.ID)
+	require.ErrorIs(t, err, session.ErrNoSession)
+
+	_, err = mgr.Load(ctx, sess3.ID)
+	require.NoError(t, err)
+
+	// sessions created after the revocation are not affected
+	sess4, err := mgr.Create(ctx, &subject.Subject{ID: "foo"}, nil)
+	require.NoError(t, err)
+
+	_, err = mgr.Load(ctx, sess4.ID)
+	require.NoError(t, err)
+}
+
+func TestManagerLoadSession(t *testing.T) {
+	t.Parallel()
+
+	path := writeKeyStore(t)
+
+	for uc, tc := range map[string]struct {
+		setup func(t *testing.T, ctx context.Context) string
+	}{
+		"unknown session": {
+			setup: func(t *testing.T, _ context.Context) string {
+				t.Helper()
+
+				return "foo"
+			},
+		},
+		"expired session": {
+			setup: func(t *testing.T, ctx context.Context) string {
+				t.Helper()
+
+				mgr, err := NewManager(
+					&config.SessionConfig{KeyStore: config.KeyStore{Path: path}, Lifespan: time.Millisecond},
+					&watcher.NoopWatcher{},
+				)
+				require.NoError(t, err)
+
+				impl := mgr.(*manager)
+				sess, err := impl.Create(ctx, &subject.Subject{ID: "foo"}, nil)
+				require.NoError(t, err)
+
+				// keep the entry in the cache to make sure the expiry is verified
+				require.NoError(t, impl.store(ctx, sess, time.Minute))
+
+				time.Sleep(5 * time.Millisecond)
+
+				return sess.ID
+			},
+		},
+		"session encrypted with an unknown key": {
+			setup: func(t *testing.T, ctx context.Context) string {
+				t.Helper()
+
+				mgr, err := NewManager(
+					&config.SessionConfig{KeyStore: config.KeyStore{Path: writeKeyStore(t)}},
+					&watcher.NoopWatcher{},
+				)
+				require.NoError(t, err)
+
+				sess, err := mgr.Create(ctx, &subject.Subject{ID: "foo"}, nil)
+				require.NoError(t, err)
+
+				return sess.ID
+			},
+		},
+		"malformed cache entry": {
+			setup: func(t *testing.T, ctx context.Context) string {
+				t.Helper()
+
+				require.NoError(t, cache.Ctx(ctx).Set(ctx, cacheKey("foo"), []byte("foo"), time.Minute))
+
+				return "foo"
+			},
+		},
+	} {
+		t.Run(uc, func(t *testing.T) {
+			// GIVEN
+			ctx := newTestContext(t)
+			id := tc.setup(t, ctx)
+
+			mgr, err := NewManager(
+				&config.SessionConfig{KeyStore: config.KeyStore{Path: path}},
+				&watcher.NoopWatcher{},
+			)
+			require.NoError(t, err)
+
+			// WHEN
+			sess, err := mgr.Load(ctx, id)
+
+			// THEN
+			require.ErrorIs(t, err, session.ErrNoSession)
+			assert.Nil(t, sess)
+		})
+	}
+}
+
+func TestManagerKeyStoreReload(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	ctx := newTestContext(t)
+	path := writeKeyStore(t)
+
+	mgr, err := NewManager(
+		&config.SessionConfig{KeyStore: config.KeyStore{Path: path}, KeyID: "ec"},
+		&watcher.NoopWatcher{},
+	)
+	require.NoError(t, err)
+
+	sess, err := mgr.Create(ctx, &subject.Subject{ID: "foo"}, nil)
+	require.NoError(t, err)
+
+	// WHEN
+	impl := mgr.(*manager)
+	impl.keyID = "rsa"
+	impl.OnChanged(log.Logger)
+
+	// THEN
+	assert.Equal(t, "rsa", impl.key.KeyID)
+
+	// sessions encrypted with the previous key can still be used
+	_, err = mgr.Load(ctx, sess.ID)
+	require.NoError(t, err)
+}
@@ -0,0 +1,50 @@
+// Copyright 2025 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package module
+
+import (
+	"github.com/rs/zerolog"
+	"go.uber.org/fx"
+
+	"github.com/dadrus/heimdall/internal/config"
+	"github.com/dadrus/heimdall/internal/session"
+	"github.com/dadrus/heimdall/internal/session/cached"
+	"github.com/dadrus/heimdall/internal/watcher"
+)
+
+var Module = fx.Options( // nolint: gochecknoglobals
+	fx.Provide(newManager),
+)
+
+func newManager(conf *config.Configuration, fw watcher.Watcher, logger zerolog.Logger) (session.Manager, error) {
+	if conf.Session == nil {
+		logger.Info().Msg("Session management is not configured")
+
+		return nil, nil //nolint:nilnil
+	}
+
+	mgr, err := cached.NewManager(conf.Session, fw)
+	if err != nil {
+		logger.Error().Err(err).Msg("Failed creating session manager")
+
+		return nil, err
+	}
+
+	logger.Info().Msg("Session management configured")
+
+	return mgr, nil
+}
@@ -0,0 +1,59 @@
+// Copyright 2025 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package session
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/dadrus/heimdall/internal/rules/mechanisms/subject"
+)
+
+var ErrNoSession = errors.New("no session")
+
+// Session represents a server-side session of a subject. It is kept encrypted in the
+// configured cache and referenced by an opaque id, usually sent by the client in a cookie.
+type Session struct {
+	ID string `json:"-"`
+	// Subject holds the subject, the session has been created for.
+	Subject *subject.Subject `json:"subject"`
+	// RefreshMaterial holds the data required to renew the session, like refresh tokens.
+	RefreshMaterial map[string]string `json:"refresh_material,omitempty"`
+	CreatedAt       time.Time         `json:"created_at"`
+	RotatedAt       time.Time         `json:"rotated_at"`
+	ExpiresAt       time.Time         `json:"expires_at"`
+}
+
+//go:generate mockery --name Manager --structname ManagerMock
+
+type Manager interface {
+	// Create creates and stores a new session for the given subject.
+	Create(ctx context.Context, sub *subject.Subject, refreshMaterial map[string]string) (*Session, error)
+	// Load returns the session with the given id. If there is no such session, or it is
+	// expired or has been revoked, ErrNoSession is returned.
+	Load(ctx context.Context, id string) (*Session, error)
+	// Update stores the given, modified session under its id. If there is no such session
+	// anymore, e.g. because it has been revoked in the meantime, ErrNoSession is returned.
+	Update(ctx context.Context, sess *Session) error
+	// Rotate stores the given session under a new id and revokes the previous one.
+	Rotate(ctx context.Context, sess *Session) (*Session, error)
+	// Revoke revokes the session with the given id.
+	Revoke(ctx context.Context, id string) error
+	// RevokeAll revokes all sessions of the subject with the given id.
+	RevokeAll(ctx context.Context, subjectID string) error
+}
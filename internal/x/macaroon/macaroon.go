@@ -0,0 +1,389 @@
+// Copyright 2025 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+// Package macaroon implements parsing and verification of macaroons in the V1 and V2 binary
+// serialization formats used by libmacaroons and its ports.
+package macaroon
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+const (
+	keyGeneratorSecret = "macaroons-key-generator"
+
+	version2 = 2
+
+	fieldEOS        = 0
+	fieldLocation   = 1
+	fieldIdentifier = 2
+	fieldVID        = 4
+	fieldSignature  = 6
+
+	v1PacketHeaderSize = 4
+)
+
+var (
+	ErrMalformedMacaroon    = errors.New("malformed macaroon")
+	ErrInvalidSignature     = errors.New("invalid macaroon signature")
+	ErrUnsupportedCaveat    = errors.New("unsupported caveat")
+	ErrCaveatNotSatisfied   = errors.New("caveat not satisfied")
+	errUnexpectedField      = errors.New("unexpected field")
+	errUnexpectedEndOfInput = errors.New("unexpected end of input")
+)
+
+// Caveat represents a caveat of a macaroon. Third-party caveats have a verification id set.
+type Caveat struct {
+	ID             []byte
+	VerificationID []byte
+	Location       string
+}
+
+// IsFirstParty returns true if the caveat is a first-party caveat.
+func (c Caveat) IsFirstParty() bool { return len(c.VerificationID) == 0 }
+
+// Macaroon is a parsed macaroon.
+type Macaroon struct {
+	location  string
+	id        []byte
+	caveats   []Caveat
+	signature []byte
+}
+
+// New creates a new macaroon, signed with the given root key.
+func New(rootKey, id []byte, location string) *Macaroon {
+	return &Macaroon{
+		location:  location,
+		id:        append([]byte{}, id...),
+		signature: keyedHash(deriveKey(rootKey), id),
+	}
+}
+
+// Parse decodes a base64 (standard or url encoding, padded or not) encoded macaroon in V1 or V2 binary format.
+func Parse(token string) (*Macaroon, error) {
+	data, err := decodeBase64(token)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrMalformedMacaroon, err)
+	}
+
+	if len(data) == 0 {
+		return nil, ErrMalformedMacaroon
+	}
+
+	var mac *Macaroon
+
+	if data[0] == version2 {
+		mac, err = parseV2(data[1:])
+	} else {
+		mac, err = parseV1(data)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrMalformedMacaroon, err)
+	}
+
+	return mac, nil
+}
+
+// Location returns the location hint of the macaroon.
+func (m *Macaroon) Location() string { return m.location }
+
+// ID returns the identifier of the macaroon.
+func (m *Macaroon) ID() []byte { return m.id }
+
+// Caveats returns the caveats of the macaroon.
+func (m *Macaroon) Caveats() []Caveat { return m.caveats }
+
+// AddFirstPartyCaveat adds a first-party caveat to the macaroon and updates its signature.
+func (m *Macaroon) AddFirstPartyCaveat(caveat string) {
+	m.caveats = append(m.caveats, Caveat{ID: []byte(caveat)})
+	m.signature = keyedHash(m.signature, []byte(caveat))
+}
+
+// Verify verifies the signature of the macaroon using the given root key and calls the check function
+// for each caveat. Only first-party caveats are supported.
+func (m *Macaroon) Verify(rootKey []byte, check func(caveat string) error) error {
+	sig := keyedHash(deriveKey(rootKey), m.id)
+
+	for _, caveat := range m.caveats {
+		if !caveat.IsFirstParty() {
+			return fmt.Errorf("%w: third-party caveats are not supported", ErrUnsupportedCaveat)
+		}
+
+		sig = keyedHash(sig, caveat.ID)
+	}
+
+	if !hmac.Equal(sig, m.signature) {
+		return ErrInvalidSignature
+	}
+
+	for _, caveat := range m.caveats {
+		if err := check(string(caveat.ID)); err != nil {
+			return fmt.Errorf("%w: %w", ErrCaveatNotSatisfied, err)
+		}
+	}
+
+	return nil
+}
+
+// MarshalBinary encodes the macaroon using the V2 binary format.
+func (m *Macaroon) MarshalBinary() ([]byte, error) {
+	data := []byte{version2}
+
+	if len(m.location) != 0 {
+		data = appendField(data, fieldLocation, []byte(m.location))
+	}
+
+	data = appendField(data, fieldIdentifier, m.id)
+	data = append(data, fieldEOS)
+
+	for _, caveat := range m.caveats {
+		if len(caveat.Location) != 0 {
+			data = appendField(data, fieldLocation, []byte(caveat.Location))
+		}
+
+		data = appendField(data, fieldIdentifier, caveat.ID)
+
+		if len(caveat.VerificationID) != 0 {
+			data = appendField(data, fieldVID, caveat.VerificationID)
+		}
+
+		data = append(data, fieldEOS)
+	}
+
+	data = append(data, fieldEOS)
+	data = appendField(data, fieldSignature, m.signature)
+
+	return data, nil
+}
+
+// String returns the base64url encoded V2 binary representation of the macaroon.
+func (m *Macaroon) String() string {
+	data, _ := m.MarshalBinary()
+
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func deriveKey(rootKey []byte) []byte {
+	return keyedHash([]byte(keyGeneratorSecret), rootKey)
+}
+
+func keyedHash(key, data []byte) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write(data)
+
+	return mac.Sum(nil)
+}
+
+func decodeBase64(value string) ([]byte, error) {
+	value = strings.TrimRight(value, "=")
+
+	if strings.ContainsAny(value, "+/") {
+		return base64.RawStdEncoding.DecodeString(value)
+	}
+
+	return base64.RawURLEncoding.DecodeString(value)
+}
+
+func appendField(data []byte, fieldType byte, value []byte) []byte {
+	data = append(data, fieldType)
+	data = binary.AppendUvarint(data, uint64(len(value)))
+
+	return append(data, value...)
+}
+
+type fieldReader struct {
+	data []byte
+}
+
+func (r *fieldReader) next() (byte, []byte, error) {
+	if len(r.data) == 0 {
+		return 0, nil, errUnexpectedEndOfInput
+	}
+
+	fieldType := r.data[0]
+	r.data = r.data[1:]
+
+	if fieldType == fieldEOS {
+		return fieldType, nil, nil
+	}
+
+	size, n := binary.Uvarint(r.data)
+	if n <= 0 || size > uint64(len(r.data)-n) {
+		return 0, nil, errUnexpectedEndOfInput
+	}
+
+	value := r.data[n : n+int(size)]
+	r.data = r.data[n+int(size):]
+
+	return fieldType, value, nil
+}
+
+// section reads the fields up to the next EOS marker.
+func (r *fieldReader) section() (map[byte][]byte, error) {
+	fields := make(map[byte][]byte)
+	last := -1
+
+	for {
+		fieldType, value, err := r.next()
+		if err != nil {
+			return nil, err
+		}
+
+		if fieldType == fieldEOS {
+			return fields, nil
+		}
+
+		// fields must appear in ascending order and only once
+		if int(fieldType) <= last {
+			return nil, fmt.Errorf("%w: %d", errUnexpectedField, fieldType)
+		}
+
+		last = int(fieldType)
+		fields[fieldType] = value
+	}
+}
+
+func parseV2(data []byte) (*Macaroon, error) {
+	reader := &fieldReader{data: data}
+
+	header, err := reader.section()
+	if err != nil {
+		return nil, err
+	}
+
+	id, ok := header[fieldIdentifier]
+	if !ok || len(header) > 2 || (len(header) == 2 && header[fieldLocation] == nil) { //nolint:mnd
+		return nil, errUnexpectedField
+	}
+
+	mac := &Macaroon{location: string(header[fieldLocation]), id: id}
+
+	for {
+		if len(reader.data) == 0 {
+			return nil, errUnexpectedEndOfInput
+		}
+
+		if reader.data[0] == fieldEOS {
+			reader.data = reader.data[1:]
+
+			break
+		}
+
+		fields, err := reader.section()
+		if err != nil {
+			return nil, err
+		}
+
+		cid, ok := fields[fieldIdentifier]
+		if !ok {
+			return nil, errUnexpectedField
+		}
+
+		for fieldType := range fields {
+			if fieldType != fieldLocation && fieldType != fieldIdentifier && fieldType != fieldVID {
+				return nil, fmt.Errorf("%w: %d", errUnexpectedField, fieldType)
+			}
+		}
+
+		mac.caveats = append(mac.caveats, Caveat{
+			ID:             cid,
+			VerificationID: fields[fieldVID],
+			Location:       string(fields[fieldLocation]),
+		})
+	}
+
+	fieldType, sig, err := reader.next()
+	if err != nil {
+		return nil, err
+	}
+
+	if fieldType != fieldSignature || len(sig) != sha256.Size || len(reader.data) != 0 {
+		return nil, errUnexpectedField
+	}
+
+	mac.signature = sig
+
+	return mac, nil
+}
+
+func parseV1(data []byte) (*Macaroon, error) { //nolint:cyclop
+	mac := &Macaroon{}
+
+	for len(data) != 0 {
+		if len(data) < v1PacketHeaderSize {
+			return nil, errUnexpectedEndOfInput
+		}
+
+		var size [2]byte
+		if _, err := hex.Decode(size[:], data[:v1PacketHeaderSize]); err != nil {
+			return nil, err
+		}
+
+		packetSize := int(binary.BigEndian.Uint16(size[:]))
+		if packetSize <= v1PacketHeaderSize+1 || packetSize > len(data) || data[packetSize-1] != '\n' {
+			return nil, errUnexpectedEndOfInput
+		}
+
+		key, value, found := bytes.Cut(data[v1PacketHeaderSize:packetSize-1], []byte(" "))
+		if !found {
+			return nil, errUnexpectedField
+		}
+
+		data = data[packetSize:]
+
+		switch string(key) {
+		case "location":
+			mac.location = string(value)
+		case "identifier":
+			mac.id = value
+		case "cid":
+			mac.caveats = append(mac.caveats, Caveat{ID: value})
+		case "vid", "cl":
+			if len(mac.caveats) == 0 {
+				return nil, fmt.Errorf("%w: %s", errUnexpectedField, key)
+			}
+
+			if string(key) == "vid" {
+				mac.caveats[len(mac.caveats)-1].VerificationID = value
+			} else {
+				mac.caveats[len(mac.caveats)-1].Location = string(value)
+			}
+		case "signature":
+			if len(data) != 0 || len(value) != sha256.Size {
+				return nil, fmt.Errorf("%w: %s", errUnexpectedField, key)
+			}
+
+			mac.signature = value
+		default:
+			return nil, fmt.Errorf("%w: %s", errUnexpectedField, key)
+		}
+	}
+
+	if mac.id == nil || mac.signature == nil {
+		return nil, errUnexpectedEndOfInput
+	}
+
+	return mac, nil
+}
@@ -0,0 +1,177 @@
+// Copyright 2025 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package macaroon
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testRootKey = "this is our super secret key; only we should know it"
+	// V1 serialized macaroon taken from the libmacaroons documentation
+	testV1Macaroon = "MDAxY2xvY2F0aW9uIGh0dHA6Ly9teWJhbmsvCjAwMjZpZGVudGlmaWVyIHdlIHVzZWQgb3VyIHNlY3JldCBrZXkKMDAy" +
+		"ZnNpZ25hdHVyZSDj2eApCFJsTAA5rhURQRXZf91ovyujebNCqvD2F9BVLwo"
+)
+
+func TestSignatureMatchesLibmacaroons(t *testing.T) {
+	t.Parallel()
+
+	mac := New([]byte(testRootKey), []byte("we used our secret key"), "http://mybank/")
+	assert.Equal(t, "e3d9e02908526c4c0039ae15114115d97fdd68bf2ba379b342aaf0f617d0552f",
+		hex.EncodeToString(mac.signature))
+
+	mac.AddFirstPartyCaveat("account = 3735928559")
+	assert.Equal(t, "1efe4763f290dbce0c1d08477367e11f4eee456a64933cf662d79772dbb82128",
+		hex.EncodeToString(mac.signature))
+}
+
+func TestParseAndVerify(t *testing.T) {
+	t.Parallel()
+
+	mac := New([]byte(testRootKey), []byte("foo"), "http://heimdall.local")
+	mac.AddFirstPartyCaveat("account = 3735928559")
+	mac.AddFirstPartyCaveat("time < 2100-01-01T00:00:00Z")
+
+	data, err := mac.MarshalBinary()
+	require.NoError(t, err)
+
+	thirdParty := New([]byte(testRootKey), []byte("foo"), "")
+	thirdParty.caveats = append(thirdParty.caveats, Caveat{ID: []byte("bar"), VerificationID: []byte("baz")})
+
+	acceptAll := func(string) error { return nil }
+
+	for uc, tc := range map[string]struct {
+		token   string
+		rootKey string
+		check   func(caveat string) error
+		assert  func(t *testing.T, err error, mac *Macaroon)
+	}{
+		"not base64 encoded": {
+			token: "foo*bar",
+			assert: func(t *testing.T, err error, _ *Macaroon) {
+				t.Helper()
+
+				require.ErrorIs(t, err, ErrMalformedMacaroon)
+			},
+		},
+		"empty": {
+			token: "",
+			assert: func(t *testing.T, err error, _ *Macaroon) {
+				t.Helper()
+
+				require.ErrorIs(t, err, ErrMalformedMacaroon)
+			},
+		},
+		"truncated V2 macaroon": {
+			token: base64.RawURLEncoding.EncodeToString(data[:len(data)-10]),
+			assert: func(t *testing.T, err error, _ *Macaroon) {
+				t.Helper()
+
+				require.ErrorIs(t, err, ErrMalformedMacaroon)
+			},
+		},
+		"V1 macaroon with unknown packet": {
+			token: base64.RawURLEncoding.EncodeToString([]byte("000cfoo bar\n")),
+			assert: func(t *testing.T, err error, _ *Macaroon) {
+				t.Helper()
+
+				require.ErrorIs(t, err, ErrMalformedMacaroon)
+			},
+		},
+		"V1 macaroon verified with wrong root key": {
+			token:   testV1Macaroon,
+			rootKey: "foo",
+			check:   acceptAll,
+			assert: func(t *testing.T, err error, _ *Macaroon) {
+				t.Helper()
+
+				require.ErrorIs(t, err, ErrInvalidSignature)
+			},
+		},
+		"valid V1 macaroon": {
+			token:   testV1Macaroon,
+			rootKey: testRootKey,
+			check:   acceptAll,
+			assert: func(t *testing.T, err error, mac *Macaroon) {
+				t.Helper()
+
+				require.NoError(t, err)
+				assert.Equal(t, "http://mybank/", mac.Location())
+				assert.Equal(t, []byte("we used our secret key"), mac.ID())
+				assert.Empty(t, mac.Caveats())
+			},
+		},
+		"V2 macaroon with third-party caveat": {
+			token:   thirdParty.String(),
+			rootKey: testRootKey,
+			check:   acceptAll,
+			assert: func(t *testing.T, err error, _ *Macaroon) {
+				t.Helper()
+
+				require.ErrorIs(t, err, ErrUnsupportedCaveat)
+			},
+		},
+		"V2 macaroon with unsatisfied caveat": {
+			token:   base64.StdEncoding.EncodeToString(data),
+			rootKey: testRootKey,
+			check: func(caveat string) error {
+				if caveat == "account = 3735928559" {
+					return errors.New("test error")
+				}
+
+				return nil
+			},
+			assert: func(t *testing.T, err error, _ *Macaroon) {
+				t.Helper()
+
+				require.ErrorIs(t, err, ErrCaveatNotSatisfied)
+				require.ErrorContains(t, err, "test error")
+			},
+		},
+		"valid V2 macaroon": {
+			token:   mac.String(),
+			rootKey: testRootKey,
+			check:   acceptAll,
+			assert: func(t *testing.T, err error, parsed *Macaroon) {
+				t.Helper()
+
+				require.NoError(t, err)
+				assert.Equal(t, "http://heimdall.local", parsed.Location())
+				assert.Equal(t, []byte("foo"), parsed.ID())
+				require.Len(t, parsed.Caveats(), 2)
+				assert.Equal(t, []byte("account = 3735928559"), parsed.Caveats()[0].ID)
+				assert.True(t, parsed.Caveats()[0].IsFirstParty())
+				assert.Equal(t, []byte("time < 2100-01-01T00:00:00Z"), parsed.Caveats()[1].ID)
+			},
+		},
+	} {
+		t.Run(uc, func(t *testing.T) {
+			parsed, err := Parse(tc.token)
+			if err == nil {
+				err = parsed.Verify([]byte(tc.rootKey), tc.check)
+			}
+
+			tc.assert(t, err, parsed)
+		})
+	}
+}
@@ -0,0 +1,129 @@
+// Copyright 2025 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+// Package paseto implements the verification of v4.public PASETO tokens as specified in
+// https://github.com/paseto-standard/paseto-spec.
+package paseto
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"math"
+	"strings"
+)
+
+const (
+	// HeaderV4Public is the header of tokens using the v4.public purpose.
+	HeaderV4Public = "v4.public."
+
+	le64Size = 8
+)
+
+var (
+	ErrMalformedToken   = errors.New("malformed token")
+	ErrUnsupportedToken = errors.New("unsupported token version or purpose")
+	ErrInvalidSignature = errors.New("invalid token signature")
+	ErrInvalidKey       = errors.New("invalid verification key")
+)
+
+// Token is a parsed, but not yet verified v4.public token.
+type Token struct {
+	message   []byte
+	signature []byte
+	footer    []byte
+}
+
+// Parse parses a v4.public token without verifying it.
+func Parse(token string) (*Token, error) {
+	if !strings.HasPrefix(token, HeaderV4Public) {
+		return nil, ErrUnsupportedToken
+	}
+
+	parts := strings.Split(token[len(HeaderV4Public):], ".")
+	if len(parts) > 2 { //nolint:mnd
+		return nil, ErrMalformedToken
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil || len(payload) < ed25519.SignatureSize {
+		return nil, ErrMalformedToken
+	}
+
+	var footer []byte
+
+	if len(parts) == 2 { //nolint:mnd
+		if footer, err = base64.RawURLEncoding.DecodeString(parts[1]); err != nil {
+			return nil, ErrMalformedToken
+		}
+	}
+
+	split := len(payload) - ed25519.SignatureSize
+
+	return &Token{
+		message:   payload[:split],
+		signature: payload[split:],
+		footer:    footer,
+	}, nil
+}
+
+// Footer returns the (unauthenticated before verification) footer of the token.
+func (t *Token) Footer() []byte { return t.footer }
+
+// Verify verifies the signature of the token using the given key and the optional implicit assertion
+// and returns the message on success.
+func (t *Token) Verify(key ed25519.PublicKey, implicit []byte) ([]byte, error) {
+	if len(key) != ed25519.PublicKeySize {
+		return nil, ErrInvalidKey
+	}
+
+	if !ed25519.Verify(key, pae([]byte(HeaderV4Public), t.message, t.footer, implicit), t.signature) {
+		return nil, ErrInvalidSignature
+	}
+
+	return t.message, nil
+}
+
+// Sign creates a v4.public token. It is the counterpart of Verify and mainly used for testing purposes.
+func Sign(key ed25519.PrivateKey, message, footer, implicit []byte) string {
+	sig := ed25519.Sign(key, pae([]byte(HeaderV4Public), message, footer, implicit))
+
+	token := HeaderV4Public + base64.RawURLEncoding.EncodeToString(append(append([]byte{}, message...), sig...))
+	if len(footer) != 0 {
+		token += "." + base64.RawURLEncoding.EncodeToString(footer)
+	}
+
+	return token
+}
+
+// pae implements the pre-authentication encoding.
+func pae(pieces ...[]byte) []byte {
+	size := le64Size
+	for _, piece := range pieces {
+		size += le64Size + len(piece)
+	}
+
+	out := make([]byte, 0, size)
+	out = binary.LittleEndian.AppendUint64(out, uint64(len(pieces))&math.MaxInt64)
+
+	for _, piece := range pieces {
+		out = binary.LittleEndian.AppendUint64(out, uint64(len(piece))&math.MaxInt64)
+		out = append(out, piece...)
+	}
+
+	return out
+}
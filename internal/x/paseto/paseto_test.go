@@ -0,0 +1,166 @@
+// Copyright 2025 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package paseto
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/hex"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVerifyWithOfficialTestVector(t *testing.T) {
+	t.Parallel()
+
+	// test vector 4-S-1 from the PASETO specification
+	pubKey, err := hex.DecodeString("1eb9dbbbbc047c03fd70604e0071f0987e16b28b757225c11f00415d0e20b1a2")
+	require.NoError(t, err)
+
+	token := "v4.public.eyJkYXRhIjoidGhpcyBpcyBhIHNpZ25lZCBtZXNzYWdlIiwiZXhwIjoiMjAyMi0wMS0wMVQwMDowMDowMCswMDowMCJ9" +
+		"bg_XBBzds8lTZShVlwwKSgeKpLT3yukTw6JUz3W4h_ExsQV-P0V54zemZDcAxFaSeef1QlXEFtkqxT1ciiQEDA"
+
+	tok, err := Parse(token)
+	require.NoError(t, err)
+	assert.Empty(t, tok.Footer())
+
+	msg, err := tok.Verify(pubKey, nil)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"data":"this is a signed message","exp":"2022-01-01T00:00:00+00:00"}`, string(msg))
+}
+
+func TestParseAndVerify(t *testing.T) {
+	t.Parallel()
+
+	pubKey, privKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	otherPubKey, _, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	message := []byte(`{"sub":"foo"}`)
+	footer := []byte(`{"kid":"bar"}`)
+
+	for uc, tc := range map[string]struct {
+		token    string
+		key      ed25519.PublicKey
+		implicit []byte
+		assert   func(t *testing.T, err error, tok *Token, msg []byte)
+	}{
+		"unsupported version": {
+			token: "v2.public.foo",
+			assert: func(t *testing.T, err error, _ *Token, _ []byte) {
+				t.Helper()
+
+				require.ErrorIs(t, err, ErrUnsupportedToken)
+			},
+		},
+		"unsupported purpose": {
+			token: "v4.local.foo",
+			assert: func(t *testing.T, err error, _ *Token, _ []byte) {
+				t.Helper()
+
+				require.ErrorIs(t, err, ErrUnsupportedToken)
+			},
+		},
+		"too many parts": {
+			token: "v4.public.foo.bar.baz",
+			assert: func(t *testing.T, err error, _ *Token, _ []byte) {
+				t.Helper()
+
+				require.ErrorIs(t, err, ErrMalformedToken)
+			},
+		},
+		"payload not base64url encoded": {
+			token: "v4.public.foo+bar",
+			assert: func(t *testing.T, err error, _ *Token, _ []byte) {
+				t.Helper()
+
+				require.ErrorIs(t, err, ErrMalformedToken)
+			},
+		},
+		"payload too short": {
+			token: "v4.public.Zm9v",
+			assert: func(t *testing.T, err error, _ *Token, _ []byte) {
+				t.Helper()
+
+				require.ErrorIs(t, err, ErrMalformedToken)
+			},
+		},
+		"footer not base64url encoded": {
+			token: Sign(privKey, message, nil, nil) + ".foo+bar",
+			assert: func(t *testing.T, err error, _ *Token, _ []byte) {
+				t.Helper()
+
+				require.ErrorIs(t, err, ErrMalformedToken)
+			},
+		},
+		"invalid key": {
+			token: Sign(privKey, message, nil, nil),
+			key:   ed25519.PublicKey("foo"),
+			assert: func(t *testing.T, err error, _ *Token, _ []byte) {
+				t.Helper()
+
+				require.ErrorIs(t, err, ErrInvalidKey)
+			},
+		},
+		"signed with other key": {
+			token: Sign(privKey, message, nil, nil),
+			key:   otherPubKey,
+			assert: func(t *testing.T, err error, _ *Token, _ []byte) {
+				t.Helper()
+
+				require.ErrorIs(t, err, ErrInvalidSignature)
+			},
+		},
+		"implicit assertion mismatch": {
+			token:    Sign(privKey, message, nil, []byte("foo")),
+			key:      pubKey,
+			implicit: []byte("bar"),
+			assert: func(t *testing.T, err error, _ *Token, _ []byte) {
+				t.Helper()
+
+				require.ErrorIs(t, err, ErrInvalidSignature)
+			},
+		},
+		"valid token with footer and implicit assertion": {
+			token:    Sign(privKey, message, footer, []byte("foo")),
+			key:      pubKey,
+			implicit: []byte("foo"),
+			assert: func(t *testing.T, err error, tok *Token, msg []byte) {
+				t.Helper()
+
+				require.NoError(t, err)
+				assert.Equal(t, footer, tok.Footer())
+				assert.Equal(t, message, msg)
+			},
+		},
+	} {
+		t.Run(uc, func(t *testing.T) {
+			tok, err := Parse(tc.token)
+
+			var msg []byte
+			if err == nil {
+				msg, err = tok.Verify(tc.key, tc.implicit)
+			}
+
+			tc.assert(t, err, tok, msg)
+		})
+	}
+}
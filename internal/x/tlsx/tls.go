@@ -20,7 +20,10 @@ import (
 	"crypto/tls"
 
 	"github.com/dadrus/heimdall/internal/config"
+	"github.com/dadrus/heimdall/internal/heimdall"
+	"github.com/dadrus/heimdall/internal/truststore"
 	"github.com/dadrus/heimdall/internal/x"
+	"github.com/dadrus/heimdall/internal/x/errorchain"
 )
 
 func ToTLSConfig(tlsCfg *config.TLS, opts ...Option) (*tls.Config, error) {
@@ -61,6 +64,20 @@ func ToTLSConfig(tlsCfg *config.TLS, opts ...Option) (*tls.Config, error) {
 		),
 	}
 
+	if args.serverAuthRequired && tlsCfg.ClientAuth != nil {
+		ts, err := truststore.NewTrustStoreFromPEMFile(tlsCfg.ClientAuth.TrustStore.Path, true)
+		if err != nil {
+			return nil, errorchain.NewWithMessage(heimdall.ErrConfiguration,
+				"failed loading trust store for client authentication").CausedBy(err)
+		}
+
+		cfg.ClientCAs = ts.CertPool()
+		cfg.ClientAuth = x.IfThenElse(tlsCfg.ClientAuth.Required,
+			tls.RequireAndVerifyClientCert,
+			tls.VerifyClientCertIfGiven,
+		)
+	}
+
 	if cfg.MinVersion != tls.VersionTLS13 {
 		cfg.CipherSuites = tlsCfg.CipherSuites.OrDefault()
 	}
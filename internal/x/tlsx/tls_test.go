@@ -79,6 +79,15 @@ func TestToTLSConfig(t *testing.T) {
 	_, err = pemFile.Write(pemBytes)
 	require.NoError(t, err)
 
+	caPEMBytes, err := pemx.BuildPEM(pemx.WithX509Certificate(cert))
+	require.NoError(t, err)
+
+	caFile, err := os.Create(filepath.Join(testDir, "ca.pem"))
+	require.NoError(t, err)
+
+	_, err = caFile.Write(caPEMBytes)
+	require.NoError(t, err)
+
 	for uc, tc := range map[string]struct {
 		conf       func(t *testing.T, wm *mocks.WatcherMock, co *mocks2.ObserverMock) config.TLS
 		serverAuth bool
@@ -329,6 +338,103 @@ func TestToTLSConfig(t *testing.T) {
 				assert.Contains(t, conf.NextProtos, "http/1.1")
 			},
 		},
+		"fails due to not existent trust store for TLS client certificate verification": {
+			serverAuth: true,
+			conf: func(t *testing.T, wm *mocks.WatcherMock, co *mocks2.ObserverMock) config.TLS {
+				t.Helper()
+
+				wm.EXPECT().Add(mock.Anything, mock.Anything).Return(nil)
+				co.EXPECT().Add(mock.Anything)
+
+				return config.TLS{
+					KeyStore:   config.KeyStore{Path: pemFile.Name()},
+					ClientAuth: &config.ClientAuth{TrustStore: config.TrustStore{Path: "/no/such/file"}},
+				}
+			},
+			assert: func(t *testing.T, err error, _ *tls.Config) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrConfiguration)
+				require.ErrorContains(t, err, "failed loading trust store")
+			},
+		},
+		"successful with optional TLS client certificate verification": {
+			serverAuth: true,
+			conf: func(t *testing.T, wm *mocks.WatcherMock, co *mocks2.ObserverMock) config.TLS {
+				t.Helper()
+
+				wm.EXPECT().Add(mock.Anything, mock.Anything).Return(nil)
+				co.EXPECT().Add(mock.Anything)
+
+				return config.TLS{
+					KeyStore:   config.KeyStore{Path: pemFile.Name()},
+					ClientAuth: &config.ClientAuth{TrustStore: config.TrustStore{Path: caFile.Name()}},
+				}
+			},
+			assert: func(t *testing.T, err error, conf *tls.Config) {
+				t.Helper()
+
+				require.NoError(t, err)
+				require.NotNil(t, conf)
+
+				assert.Equal(t, tls.VerifyClientCertIfGiven, conf.ClientAuth)
+				require.NotNil(t, conf.ClientCAs)
+
+				pool := x509.NewCertPool()
+				pool.AddCert(cert)
+				assert.True(t, pool.Equal(conf.ClientCAs))
+			},
+		},
+		"successful with required TLS client certificate verification": {
+			serverAuth: true,
+			conf: func(t *testing.T, wm *mocks.WatcherMock, co *mocks2.ObserverMock) config.TLS {
+				t.Helper()
+
+				wm.EXPECT().Add(mock.Anything, mock.Anything).Return(nil)
+				co.EXPECT().Add(mock.Anything)
+
+				return config.TLS{
+					KeyStore: config.KeyStore{Path: pemFile.Name()},
+					ClientAuth: &config.ClientAuth{
+						Required:   true,
+						TrustStore: config.TrustStore{Path: caFile.Name()},
+					},
+				}
+			},
+			assert: func(t *testing.T, err error, conf *tls.Config) {
+				t.Helper()
+
+				require.NoError(t, err)
+				require.NotNil(t, conf)
+
+				assert.Equal(t, tls.RequireAndVerifyClientCert, conf.ClientAuth)
+				require.NotNil(t, conf.ClientCAs)
+			},
+		},
+		"client certificate verification settings are ignored if no server auth is required": {
+			clientAuth: true,
+			conf: func(t *testing.T, wm *mocks.WatcherMock, co *mocks2.ObserverMock) config.TLS {
+				t.Helper()
+
+				wm.EXPECT().Add(mock.Anything, mock.Anything).Return(nil)
+				co.EXPECT().Add(mock.Anything)
+
+				return config.TLS{
+					KeyStore:   config.KeyStore{Path: pemFile.Name()},
+					ClientAuth: &config.ClientAuth{TrustStore: config.TrustStore{Path: "/no/such/file"}},
+				}
+			},
+			assert: func(t *testing.T, err error, conf *tls.Config) {
+				t.Helper()
+
+				require.NoError(t, err)
+				require.NotNil(t, conf)
+
+				assert.Equal(t, tls.NoClientCert, conf.ClientAuth)
+				assert.Nil(t, conf.ClientCAs)
+			},
+		},
 	} {
 		t.Run(uc, func(t *testing.T) {
 			// WHEN